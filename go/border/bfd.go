@@ -0,0 +1,145 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file manages the BFD sessions on the external interfaces.
+
+package main
+
+import (
+	"sync"
+
+	"github.com/scionproto/scion/go/border/bfd"
+	"github.com/scionproto/scion/go/border/brconf"
+	"github.com/scionproto/scion/go/border/ifstate"
+	"github.com/scionproto/scion/go/border/internal/metrics"
+	"github.com/scionproto/scion/go/border/rcmn"
+	"github.com/scionproto/scion/go/border/rctx"
+	"github.com/scionproto/scion/go/border/rpkt"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// isBFD indicates whether the packet is a BFD control packet that was received
+// on an external interface.
+func (r *Router) isBFD(rp *rpkt.RtrPkt) bool {
+	return r.bfdSessions != nil && rp.DirFrom == rcmn.DirExternal &&
+		bfd.IsControlPacket(rp.Raw)
+}
+
+// bfdSessions holds the BFD sessions of the external interfaces, keyed by the
+// interface ID.
+type bfdSessions struct {
+	cfg brconf.BFD
+	// ifDownQ is a channel for reporting interfaces that went down to the
+	// control plane.
+	ifDownQ  chan common.IFIDType
+	mtx      sync.Mutex
+	sessions map[common.IFIDType]*bfd.Session
+}
+
+func newBFDSessions(cfg brconf.BFD, ifDownQ chan common.IFIDType) *bfdSessions {
+	return &bfdSessions{
+		cfg:      cfg,
+		ifDownQ:  ifDownQ,
+		sessions: make(map[common.IFIDType]*bfd.Session),
+	}
+}
+
+// update starts sessions for the interfaces that were added in the context and
+// stops the sessions of the interfaces that were removed.
+func (b *bfdSessions) update(ctx *rctx.Ctx) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	for ifid, s := range b.sessions {
+		if _, ok := ctx.Conf.BR.IFs[ifid]; !ok {
+			s.Close()
+			delete(b.sessions, ifid)
+		}
+	}
+	for ifid, intf := range ctx.Conf.BR.IFs {
+		if _, ok := b.sessions[ifid]; ok {
+			continue
+		}
+		l := metrics.IntfLabels{Intf: metrics.IntfToLabel(ifid), NeighIA: intf.IA.String()}
+		s := bfd.NewSession(b.cfg.SessionConfig(), extSender(ifid),
+			b.stateChangeFunc(ifid, intf.IA, l), log.New("bfd", ifid))
+		b.sessions[ifid] = s
+		metrics.BFD.State(l).Set(float64(s.State()))
+		go func() {
+			defer log.HandlePanic()
+			s.Run()
+		}()
+	}
+}
+
+// deliver hands a received BFD control packet to the session of the interface.
+func (b *bfdSessions) deliver(ifid common.IFIDType, raw common.RawBytes) {
+	b.mtx.Lock()
+	s, ok := b.sessions[ifid]
+	b.mtx.Unlock()
+	if !ok {
+		return
+	}
+	pkt, err := bfd.ParseControlPacket(raw)
+	if err != nil {
+		log.Debug("Dropping invalid BFD control packet", "ifid", ifid, "err", err)
+		metrics.BFD.RecvErrors(bfdLabels(ifid)).Inc()
+		return
+	}
+	s.Deliver(pkt)
+}
+
+// stateChangeFunc returns the state change callback for the session of the
+// interface. If the session goes down, the interface is marked as inactive
+// and reported to the control plane, which revokes the interface.
+func (b *bfdSessions) stateChangeFunc(ifid common.IFIDType, ia addr.IA,
+	l metrics.IntfLabels) func(bfd.State, bfd.State, bfd.Diagnostic) {
+
+	return func(old, new bfd.State, diag bfd.Diagnostic) {
+		metrics.BFD.State(l).Set(float64(new))
+		metrics.BFD.StateChanges(l).Inc()
+		if old != bfd.Up || new != bfd.Down {
+			return
+		}
+		log.Info("BFD detected link failure", "ifid", ifid, "diag", diag)
+		ifstate.Deactivate(ifid, ia)
+		select {
+		case b.ifDownQ <- ifid:
+		default:
+			log.Debug("Dropping interface down notification", "ifid", ifid)
+		}
+	}
+}
+
+func bfdLabels(ifid common.IFIDType) metrics.IntfLabels {
+	l := metrics.IntfLabels{Intf: metrics.IntfToLabel(ifid)}
+	if intf, ok := rctx.Get().Conf.BR.IFs[ifid]; ok {
+		l.NeighIA = intf.IA.String()
+	}
+	return l
+}
+
+// extSender sends BFD control packets on the socket of an external interface.
+type extSender common.IFIDType
+
+func (s extSender) Send(raw common.RawBytes) error {
+	sock, ok := rctx.Get().ExtSockOut[common.IFIDType(s)]
+	if !ok {
+		return serrors.New("no socket for interface", "ifid", common.IFIDType(s))
+	}
+	_, err := sock.Conn.Write(raw)
+	return err
+}
@@ -0,0 +1,223 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bfd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+const (
+	// Version is the BFD protocol version.
+	Version = 1
+	// PacketLen is the length of a BFD control packet without authentication
+	// section.
+	PacketLen = 24
+	// MaxInterval is the largest interval that can be encoded in a control
+	// packet.
+	MaxInterval = time.Duration(^uint32(0)) * time.Microsecond
+)
+
+const (
+	flagPoll       = 0x20
+	flagFinal      = 0x10
+	flagAuth       = 0x04
+	flagMultipoint = 0x01
+)
+
+// State is the state of a BFD session.
+type State uint8
+
+// The BFD session states, as defined in RFC 5880 section 4.1.
+const (
+	AdminDown State = iota
+	Down
+	Init
+	Up
+)
+
+func (s State) String() string {
+	switch s {
+	case AdminDown:
+		return "AdminDown"
+	case Down:
+		return "Down"
+	case Init:
+		return "Init"
+	case Up:
+		return "Up"
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", uint8(s))
+}
+
+// Diagnostic is the reason of the last state change of a BFD session.
+type Diagnostic uint8
+
+// The BFD diagnostic codes, as defined in RFC 5880 section 4.1.
+const (
+	DiagNone Diagnostic = iota
+	DiagControlDetectionTimeExpired
+	DiagEchoFunctionFailed
+	DiagNeighborSignaledDown
+	DiagForwardingPlaneReset
+	DiagPathDown
+	DiagConcatenatedPathDown
+	DiagAdministrativelyDown
+	DiagReverseConcatenatedPathDown
+)
+
+func (d Diagnostic) String() string {
+	switch d {
+	case DiagNone:
+		return "None"
+	case DiagControlDetectionTimeExpired:
+		return "ControlDetectionTimeExpired"
+	case DiagEchoFunctionFailed:
+		return "EchoFunctionFailed"
+	case DiagNeighborSignaledDown:
+		return "NeighborSignaledDown"
+	case DiagForwardingPlaneReset:
+		return "ForwardingPlaneReset"
+	case DiagPathDown:
+		return "PathDown"
+	case DiagConcatenatedPathDown:
+		return "ConcatenatedPathDown"
+	case DiagAdministrativelyDown:
+		return "AdministrativelyDown"
+	case DiagReverseConcatenatedPathDown:
+		return "ReverseConcatenatedPathDown"
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", uint8(d))
+}
+
+// ControlPacket is a BFD control packet. Authentication is not supported.
+type ControlPacket struct {
+	Diag       Diagnostic
+	State      State
+	Poll       bool
+	Final      bool
+	DetectMult uint8
+	// MyDiscriminator is the discriminator of the sending session.
+	MyDiscriminator uint32
+	// YourDiscriminator is the discriminator of the receiving session, or 0
+	// if it is not known yet.
+	YourDiscriminator uint32
+	// DesiredMinTxInterval is the minimum interval the sender wants to use
+	// when transmitting control packets.
+	DesiredMinTxInterval time.Duration
+	// RequiredMinRxInterval is the minimum interval between received control
+	// packets that the sender supports.
+	RequiredMinRxInterval time.Duration
+	// RequiredMinEchoRxInterval is the minimum interval between received echo
+	// packets that the sender supports. It is always 0, as the echo function
+	// is not supported.
+	RequiredMinEchoRxInterval time.Duration
+}
+
+// IsControlPacket indicates whether raw looks like a BFD control packet. It
+// only inspects the version field, which allows to tell BFD apart from SCION
+// packets, whose version field is 0.
+func IsControlPacket(raw []byte) bool {
+	return len(raw) > 0 && raw[0]>>5 == Version
+}
+
+// ParseControlPacket parses a BFD control packet and validates it according to
+// RFC 5880 section 6.8.6. Packets with an authentication section are rejected.
+func ParseControlPacket(raw []byte) (*ControlPacket, error) {
+	if len(raw) < PacketLen {
+		return nil, serrors.New("packet too short", "len", len(raw))
+	}
+	if v := raw[0] >> 5; v != Version {
+		return nil, serrors.New("unsupported version", "version", v)
+	}
+	length := int(raw[3])
+	if length < PacketLen || length > len(raw) {
+		return nil, serrors.New("invalid length", "length", length, "actual", len(raw))
+	}
+	flags := raw[1] & 0x3f
+	if flags&flagAuth != 0 {
+		return nil, serrors.New("authentication not supported")
+	}
+	if flags&flagMultipoint != 0 {
+		return nil, serrors.New("multipoint bit set")
+	}
+	p := &ControlPacket{
+		Diag:                      Diagnostic(raw[0] & 0x1f),
+		State:                     State(raw[1] >> 6),
+		Poll:                      flags&flagPoll != 0,
+		Final:                     flags&flagFinal != 0,
+		DetectMult:                raw[2],
+		MyDiscriminator:           binary.BigEndian.Uint32(raw[4:]),
+		YourDiscriminator:         binary.BigEndian.Uint32(raw[8:]),
+		DesiredMinTxInterval:      fromMicros(raw[12:]),
+		RequiredMinRxInterval:     fromMicros(raw[16:]),
+		RequiredMinEchoRxInterval: fromMicros(raw[20:]),
+	}
+	if p.DetectMult == 0 {
+		return nil, serrors.New("detect multiplier is zero")
+	}
+	if p.MyDiscriminator == 0 {
+		return nil, serrors.New("my discriminator is zero")
+	}
+	if p.YourDiscriminator == 0 && p.State != Down && p.State != AdminDown {
+		return nil, serrors.New("your discriminator is zero", "state", p.State)
+	}
+	if p.Poll && p.Final {
+		return nil, serrors.New("poll and final bit set")
+	}
+	return p, nil
+}
+
+// Pack serializes the control packet.
+func (p *ControlPacket) Pack() common.RawBytes {
+	raw := make(common.RawBytes, PacketLen)
+	raw[0] = Version<<5 | uint8(p.Diag)&0x1f
+	raw[1] = uint8(p.State) << 6
+	if p.Poll {
+		raw[1] |= flagPoll
+	}
+	if p.Final {
+		raw[1] |= flagFinal
+	}
+	raw[2] = p.DetectMult
+	raw[3] = PacketLen
+	binary.BigEndian.PutUint32(raw[4:], p.MyDiscriminator)
+	binary.BigEndian.PutUint32(raw[8:], p.YourDiscriminator)
+	binary.BigEndian.PutUint32(raw[12:], toMicros(p.DesiredMinTxInterval))
+	binary.BigEndian.PutUint32(raw[16:], toMicros(p.RequiredMinRxInterval))
+	binary.BigEndian.PutUint32(raw[20:], toMicros(p.RequiredMinEchoRxInterval))
+	return raw
+}
+
+func (p *ControlPacket) String() string {
+	return fmt.Sprintf("State: %s Diag: %s Poll: %t Final: %t DetectMult: %d My: %d Your: %d "+
+		"DesiredMinTx: %s RequiredMinRx: %s", p.State, p.Diag, p.Poll, p.Final, p.DetectMult,
+		p.MyDiscriminator, p.YourDiscriminator, p.DesiredMinTxInterval, p.RequiredMinRxInterval)
+}
+
+func fromMicros(b []byte) time.Duration {
+	return time.Duration(binary.BigEndian.Uint32(b)) * time.Microsecond
+}
+
+func toMicros(d time.Duration) uint32 {
+	if d > MaxInterval {
+		d = MaxInterval
+	}
+	return uint32(d / time.Microsecond)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bfd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/border/bfd"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestControlPacket(t *testing.T) {
+	pkt := &bfd.ControlPacket{
+		Diag:                  bfd.DiagNeighborSignaledDown,
+		State:                 bfd.Init,
+		Poll:                  true,
+		DetectMult:            3,
+		MyDiscriminator:       0x01020304,
+		YourDiscriminator:     0x05060708,
+		DesiredMinTxInterval:  time.Second,
+		RequiredMinRxInterval: 200 * time.Millisecond,
+	}
+	raw := pkt.Pack()
+	expected := xtest.MustParseHexString("23a00318" + "01020304" + "05060708" +
+		"000f4240" + "00030d40" + "00000000")
+	assert.Equal(t, expected, []byte(raw))
+	assert.True(t, bfd.IsControlPacket(raw))
+	parsed, err := bfd.ParseControlPacket(raw)
+	require.NoError(t, err)
+	assert.Equal(t, pkt, parsed)
+}
+
+func TestParseControlPacket(t *testing.T) {
+	valid := func() []byte {
+		pkt := &bfd.ControlPacket{
+			State:             bfd.Up,
+			DetectMult:        3,
+			MyDiscriminator:   1,
+			YourDiscriminator: 2,
+		}
+		return pkt.Pack()
+	}
+	tests := map[string]struct {
+		Modify    func(raw []byte) []byte
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Modify:    func(raw []byte) []byte { return raw },
+			Assertion: assert.NoError,
+		},
+		"too short": {
+			Modify:    func(raw []byte) []byte { return raw[:bfd.PacketLen-1] },
+			Assertion: assert.Error,
+		},
+		"wrong version": {
+			Modify: func(raw []byte) []byte {
+				raw[0] = 2 << 5
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+		"length exceeds packet": {
+			Modify: func(raw []byte) []byte {
+				raw[3] = bfd.PacketLen + 1
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+		"authentication": {
+			Modify: func(raw []byte) []byte {
+				raw[1] |= 0x04
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+		"multipoint": {
+			Modify: func(raw []byte) []byte {
+				raw[1] |= 0x01
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+		"zero detect mult": {
+			Modify: func(raw []byte) []byte {
+				raw[2] = 0
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+		"zero my discriminator": {
+			Modify: func(raw []byte) []byte {
+				copy(raw[4:8], []byte{0, 0, 0, 0})
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+		"zero your discriminator in state up": {
+			Modify: func(raw []byte) []byte {
+				copy(raw[8:12], []byte{0, 0, 0, 0})
+				return raw
+			},
+			Assertion: assert.Error,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := bfd.ParseControlPacket(test.Modify(valid()))
+			test.Assertion(t, err)
+		})
+	}
+}
+
+func TestIsControlPacket(t *testing.T) {
+	assert.False(t, bfd.IsControlPacket(nil))
+	// The first byte of a SCION packet contains version 0.
+	assert.False(t, bfd.IsControlPacket([]byte{0x00, 0x01}))
+	assert.True(t, bfd.IsControlPacket([]byte{0x20}))
+}
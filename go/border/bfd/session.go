@@ -0,0 +1,346 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bfd implements Bidirectional Forwarding Detection (BFD) sessions
+// following the semantics of RFC 5880.
+//
+// Only the asynchronous mode is supported. Demand mode, the echo function and
+// authentication are not implemented. The session does not initiate poll
+// sequences, but it answers polls of the remote system.
+package bfd
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+)
+
+const (
+	// DefaultInterval is the default for the desired minimum transmission
+	// interval and the required minimum reception interval.
+	DefaultInterval = 200 * time.Millisecond
+	// DefaultDetectMult is the default detection time multiplier.
+	DefaultDetectMult = 3
+
+	// msgQueueLen is the number of received packets that are buffered for a
+	// session.
+	msgQueueLen = 16
+)
+
+// slowTxInterval is the minimum transmission interval while the session is not
+// up, see RFC 5880 section 6.8.3.
+var slowTxInterval = time.Second
+
+// Sender sends control packets to the remote system of a session.
+type Sender interface {
+	Send(raw common.RawBytes) error
+}
+
+// Config is the configuration of a session.
+type Config struct {
+	// DesiredMinTxInterval is the minimum interval at which the session wants
+	// to send control packets once it is up.
+	DesiredMinTxInterval time.Duration
+	// RequiredMinRxInterval is the minimum interval between received control
+	// packets that the session supports.
+	RequiredMinRxInterval time.Duration
+	// DetectMult is the detection time multiplier.
+	DetectMult uint8
+}
+
+// Session is a BFD session with a single remote system. The session runs in
+// asynchronous mode; it periodically sends control packets and declares the
+// remote system down if no control packet arrives within the detection time.
+type Session struct {
+	cfg           Config
+	sender        Sender
+	onStateChange func(old, new State, diag Diagnostic)
+	logger        log.Logger
+
+	msgs      chan *ControlPacket
+	closeChan chan struct{}
+	doneChan  chan struct{}
+	closeOnce sync.Once
+
+	mu          sync.Mutex
+	state       State
+	localDiag   Diagnostic
+	localDiscr  uint32
+	remoteDiscr uint32
+	remoteState State
+	// remoteMinRxInterval is the last received required minimum reception
+	// interval of the remote system.
+	remoteMinRxInterval time.Duration
+	// remoteMinTxInterval is the last received desired minimum transmission
+	// interval of the remote system.
+	remoteMinTxInterval time.Duration
+	remoteDetectMult    uint8
+}
+
+// NewSession creates a new session that sends its control packets with the
+// sender. The state change callback is optional; it is invoked from the
+// goroutine executing Run and must not block.
+func NewSession(cfg Config, sender Sender, onStateChange func(old, new State,
+	diag Diagnostic), logger log.Logger) *Session {
+
+	if logger == nil {
+		logger = log.Root()
+	}
+	return &Session{
+		cfg:           cfg,
+		sender:        sender,
+		onStateChange: onStateChange,
+		logger:        logger,
+		msgs:          make(chan *ControlPacket, msgQueueLen),
+		closeChan:     make(chan struct{}),
+		doneChan:      make(chan struct{}),
+		state:         Down,
+		localDiscr:    newDiscriminator(),
+		remoteState:   Down,
+		// Until we hear from the remote system, assume it can receive at
+		// any rate, see RFC 5880 section 6.8.1.
+		remoteMinRxInterval: time.Microsecond,
+	}
+}
+
+// Run runs the session until Close is called.
+func (s *Session) Run() {
+	defer close(s.doneChan)
+	txTimer := time.NewTimer(0)
+	defer txTimer.Stop()
+	detectTimer := time.NewTimer(0)
+	stopTimer(detectTimer)
+	defer detectTimer.Stop()
+	for {
+		select {
+		case <-s.closeChan:
+			s.setState(AdminDown, DiagAdministrativelyDown)
+			s.send(false)
+			return
+		case <-txTimer.C:
+			s.sendPeriodic()
+			txTimer.Reset(s.jitter(s.txInterval()))
+		case <-detectTimer.C:
+			s.detectionTimeExpired()
+		case pkt := <-s.msgs:
+			if !s.handlePacket(pkt) {
+				continue
+			}
+			if pkt.Poll {
+				s.send(true)
+			}
+			stopTimer(detectTimer)
+			detectTimer.Reset(s.detectionTime())
+		}
+	}
+}
+
+// Close administratively shuts the session down and waits for Run to return.
+// Close must only be called if Run has been started.
+func (s *Session) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closeChan)
+	})
+	<-s.doneChan
+}
+
+// Deliver hands a received control packet to the session. If the session is
+// not able to keep up, the packet is dropped.
+func (s *Session) Deliver(pkt *ControlPacket) {
+	select {
+	case s.msgs <- pkt:
+	default:
+		s.logger.Debug("Dropping BFD control packet, queue full")
+	}
+}
+
+// State returns the current state of the session.
+func (s *Session) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// handlePacket processes a received control packet according to RFC 5880
+// section 6.8.6. The return value indicates whether the packet was accepted.
+func (s *Session) handlePacket(pkt *ControlPacket) bool {
+	s.mu.Lock()
+	if pkt.YourDiscriminator != 0 && pkt.YourDiscriminator != s.localDiscr {
+		s.mu.Unlock()
+		return false
+	}
+	s.remoteDiscr = pkt.MyDiscriminator
+	s.remoteState = pkt.State
+	s.remoteMinRxInterval = pkt.RequiredMinRxInterval
+	s.remoteMinTxInterval = pkt.DesiredMinTxInterval
+	s.remoteDetectMult = pkt.DetectMult
+	state := s.state
+	s.mu.Unlock()
+
+	if state == AdminDown {
+		return false
+	}
+	switch {
+	case pkt.State == AdminDown:
+		if state != Down {
+			s.setState(Down, DiagNeighborSignaledDown)
+		}
+	case state == Down:
+		switch pkt.State {
+		case Down:
+			s.setState(Init, DiagNone)
+		case Init:
+			s.setState(Up, DiagNone)
+		}
+	case state == Init:
+		if pkt.State == Init || pkt.State == Up {
+			s.setState(Up, DiagNone)
+		}
+	case state == Up:
+		if pkt.State == Down {
+			s.setState(Down, DiagNeighborSignaledDown)
+		}
+	}
+	return true
+}
+
+// detectionTimeExpired is called if no control packet was received within the
+// detection time.
+func (s *Session) detectionTimeExpired() {
+	s.mu.Lock()
+	state := s.state
+	s.mu.Unlock()
+	if state != Init && state != Up {
+		return
+	}
+	s.setState(Down, DiagControlDetectionTimeExpired)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remoteDiscr = 0
+	s.remoteState = Down
+}
+
+func (s *Session) setState(state State, diag Diagnostic) {
+	s.mu.Lock()
+	old := s.state
+	s.state = state
+	if old != state {
+		s.localDiag = diag
+	}
+	s.mu.Unlock()
+	if old == state {
+		return
+	}
+	s.logger.Info("BFD session state changed", "old", old, "new", state, "diag", diag)
+	if s.onStateChange != nil {
+		s.onStateChange(old, state, diag)
+	}
+}
+
+// sendPeriodic sends a periodic control packet, unless the remote system has
+// requested to not receive any, see RFC 5880 section 6.8.7.
+func (s *Session) sendPeriodic() {
+	s.mu.Lock()
+	silent := s.remoteDiscr != 0 && s.remoteMinRxInterval == 0
+	s.mu.Unlock()
+	if silent {
+		return
+	}
+	s.send(false)
+}
+
+func (s *Session) send(final bool) {
+	if err := s.sender.Send(s.packet(final).Pack()); err != nil {
+		s.logger.Debug("Failed to send BFD control packet", "err", err)
+	}
+}
+
+func (s *Session) packet(final bool) *ControlPacket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &ControlPacket{
+		Diag:                  s.localDiag,
+		State:                 s.state,
+		Final:                 final,
+		DetectMult:            s.cfg.DetectMult,
+		MyDiscriminator:       s.localDiscr,
+		YourDiscriminator:     s.remoteDiscr,
+		DesiredMinTxInterval:  s.desiredMinTxInterval(),
+		RequiredMinRxInterval: s.cfg.RequiredMinRxInterval,
+	}
+}
+
+// desiredMinTxInterval returns the advertised desired minimum transmission
+// interval. The caller must hold the lock.
+func (s *Session) desiredMinTxInterval() time.Duration {
+	if s.state != Up && s.cfg.DesiredMinTxInterval < slowTxInterval {
+		return slowTxInterval
+	}
+	return s.cfg.DesiredMinTxInterval
+}
+
+// txInterval returns the interval at which control packets are sent. It is the
+// larger of the desired minimum transmission interval and the minimum reception
+// interval of the remote system.
+func (s *Session) txInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(s.desiredMinTxInterval(), s.remoteMinRxInterval)
+}
+
+// detectionTime returns the time after which the remote system is declared
+// down, if no control packet is received.
+func (s *Session) detectionTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Duration(s.remoteDetectMult) *
+		max(s.cfg.RequiredMinRxInterval, s.remoteMinTxInterval)
+}
+
+// jitter reduces the interval by a random amount of up to 25%, or 10% to 25%
+// if the detection multiplier is 1, see RFC 5880 section 6.8.7.
+func (s *Session) jitter(d time.Duration) time.Duration {
+	lower, upper := 75, 100
+	if s.cfg.DetectMult == 1 {
+		upper = 90
+	}
+	return d * time.Duration(lower+rand.Intn(upper-lower+1)) / 100
+}
+
+func newDiscriminator() uint32 {
+	for {
+		if d := rand.Uint32(); d != 0 {
+			return d
+		}
+	}
+}
+
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
+
+func max(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bfd
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func TestSessionHandlePacket(t *testing.T) {
+	tests := map[string]struct {
+		Local        State
+		Remote       State
+		Expected     State
+		ExpectedDiag Diagnostic
+	}{
+		"down, remote down": {
+			Local:    Down,
+			Remote:   Down,
+			Expected: Init,
+		},
+		"down, remote init": {
+			Local:    Down,
+			Remote:   Init,
+			Expected: Up,
+		},
+		"down, remote up": {
+			Local:    Down,
+			Remote:   Up,
+			Expected: Down,
+		},
+		"init, remote init": {
+			Local:    Init,
+			Remote:   Init,
+			Expected: Up,
+		},
+		"init, remote up": {
+			Local:    Init,
+			Remote:   Up,
+			Expected: Up,
+		},
+		"init, remote admin down": {
+			Local:        Init,
+			Remote:       AdminDown,
+			Expected:     Down,
+			ExpectedDiag: DiagNeighborSignaledDown,
+		},
+		"up, remote down": {
+			Local:        Up,
+			Remote:       Down,
+			Expected:     Down,
+			ExpectedDiag: DiagNeighborSignaledDown,
+		},
+		"up, remote up": {
+			Local:    Up,
+			Remote:   Up,
+			Expected: Up,
+		},
+		"admin down, remote up": {
+			Local:    AdminDown,
+			Remote:   Up,
+			Expected: AdminDown,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewSession(Config{}, nil, nil, nil)
+			s.state = test.Local
+			pkt := &ControlPacket{
+				State:           test.Remote,
+				DetectMult:      3,
+				MyDiscriminator: 42,
+			}
+			s.handlePacket(pkt)
+			assert.Equal(t, test.Expected, s.State())
+			assert.Equal(t, test.ExpectedDiag, s.localDiag)
+		})
+	}
+	t.Run("wrong discriminator", func(t *testing.T) {
+		s := NewSession(Config{}, nil, nil, nil)
+		pkt := &ControlPacket{
+			State:             Down,
+			DetectMult:        3,
+			MyDiscriminator:   42,
+			YourDiscriminator: s.localDiscr + 1,
+		}
+		assert.False(t, s.handlePacket(pkt))
+		assert.Equal(t, Down, s.State())
+	})
+}
+
+func TestSessionTimers(t *testing.T) {
+	s := NewSession(Config{
+		DesiredMinTxInterval:  100 * time.Millisecond,
+		RequiredMinRxInterval: 300 * time.Millisecond,
+		DetectMult:            3,
+	}, nil, nil, nil)
+	s.handlePacket(&ControlPacket{
+		State:                 Init,
+		DetectMult:            5,
+		MyDiscriminator:       42,
+		DesiredMinTxInterval:  200 * time.Millisecond,
+		RequiredMinRxInterval: 50 * time.Millisecond,
+	})
+	assert.Equal(t, Up, s.State())
+	assert.Equal(t, 100*time.Millisecond, s.txInterval())
+	assert.Equal(t, 5*300*time.Millisecond, s.detectionTime())
+
+	s.state = Down
+	assert.Equal(t, slowTxInterval, s.txInterval(), "slow transmission while not up")
+	for i := 0; i < 100; i++ {
+		d := s.jitter(time.Second)
+		assert.True(t, d >= 750*time.Millisecond && d <= time.Second, d)
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	defer func(d time.Duration) { slowTxInterval = d }(slowTxInterval)
+	slowTxInterval = 10 * time.Millisecond
+
+	cfg := Config{
+		DesiredMinTxInterval:  10 * time.Millisecond,
+		RequiredMinRxInterval: 10 * time.Millisecond,
+		DetectMult:            3,
+	}
+	a2b, b2a := &chanSender{}, &chanSender{}
+	var mu sync.Mutex
+	var bStates []State
+	a := NewSession(cfg, a2b, nil, nil)
+	b := NewSession(cfg, b2a, func(_, new State, _ Diagnostic) {
+		mu.Lock()
+		defer mu.Unlock()
+		bStates = append(bStates, new)
+	}, nil)
+	a2b.dst, b2a.dst = b, a
+	go a.Run()
+	go b.Run()
+	defer b.Close()
+
+	assert.Eventually(t, func() bool {
+		return a.State() == Up && b.State() == Up
+	}, time.Second, 5*time.Millisecond)
+
+	a2b.Drop(true)
+	assert.Eventually(t, func() bool {
+		return b.State() == Down
+	}, time.Second, 5*time.Millisecond)
+	a2b.Drop(false)
+	assert.Eventually(t, func() bool {
+		return a.State() == Up && b.State() == Up
+	}, time.Second, 5*time.Millisecond)
+
+	a.Close()
+	assert.Equal(t, AdminDown, a.State())
+	assert.Eventually(t, func() bool {
+		return b.State() == Down
+	}, time.Second, 5*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	var ups int
+	for _, state := range bStates {
+		if state == Up {
+			ups++
+		}
+	}
+	assert.Equal(t, 2, ups)
+	assert.Equal(t, Down, bStates[len(bStates)-1])
+}
+
+// chanSender delivers the packets directly to the destination session.
+type chanSender struct {
+	dst  *Session
+	mu   sync.Mutex
+	drop bool
+}
+
+func (s *chanSender) Drop(drop bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drop = drop
+}
+
+func (s *chanSender) Send(raw common.RawBytes) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.drop {
+		return nil
+	}
+	pkt, err := ParseControlPacket(raw)
+	if err != nil {
+		return err
+	}
+	s.dst.Deliver(pkt)
+	return nil
+}
@@ -27,6 +27,21 @@ import (
 )
 
 func ExpectedPackets(desc string, to string, pkts ...*DevTaggedLayers) int {
+	// Serialize all expected packets so that we generate proper length values, checksums, etc.
+	return expectPackets(desc, to, toGoPackets(pkts...), isIgnoredPkt)
+}
+
+// isIgnoredPkt reports whether the packet matches one of the ignored packets.
+func isIgnoredPkt(devIdx int, pkt gopacket.Packet) bool {
+	_, err := checkPkt(IgnoredPkts, devIdx, pkt)
+	return err == nil
+}
+
+// expectPackets waits for the expected packets until the timeout expires. Packets for which
+// ignore returns true are skipped.
+func expectPackets(desc string, to string, expPkts []*DevPkt,
+	ignore func(int, gopacket.Packet) bool) int {
+
 	var errors int
 	// Given that the number of interfaces changes depending on the BR configuration,
 	// we use a dynamic select/switch case approach, where each interface has an equivalent
@@ -39,8 +54,6 @@ func ExpectedPackets(desc string, to string, pkts ...*DevTaggedLayers) int {
 	timerCh := time.After(timeout)
 	// Add timeout channel as the last select case.
 	cases[timerIdx] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timerCh)}
-	// Serialize all expected packets so that we generate proper length values, checksums, etc.
-	expPkts := toGoPackets(pkts...)
 	var errStr []string
 	for {
 		idx, pktV, ok := reflect.Select(cases)
@@ -57,7 +70,7 @@ func ExpectedPackets(desc string, to string, pkts ...*DevTaggedLayers) int {
 		}
 		// Packet received
 		pkt := pktV.Interface().(gopacket.Packet)
-		if _, e := checkPkt(IgnoredPkts, idx, pkt); e == nil {
+		if ignore(idx, pkt) {
 			// Packet is to be ignored
 			continue
 		}
@@ -30,6 +30,7 @@ import (
 	"github.com/scionproto/scion/go/border/braccept/layers"
 	"github.com/scionproto/scion/go/border/braccept/shared"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/slayers"
 )
 
 const (
@@ -103,9 +104,12 @@ func realMain() int {
 	caps.Clear(capability.CAPS)
 	caps.Apply(capability.CAPS)
 
-	registerScionPorts()
-
-	IgnorePkts()
+	if testName == "br_v2" {
+		registerScionPorts(slayers.LayerTypeSCION)
+	} else {
+		registerScionPorts(layers.LayerTypeScion)
+		IgnorePkts()
+	}
 
 	var failures int
 	log.Info("Acceptance tests:", "testName", testName)
@@ -124,6 +128,8 @@ func realMain() int {
 		failures += br_core_coreIf()
 	case "br_core_childIf":
 		failures += br_core_childIf()
+	case "br_v2":
+		failures += br_v2()
 	default:
 		log.Error("Wrong BR acceptance test name", "testName", testName)
 		return 1
@@ -143,14 +149,15 @@ func checkFlags() error {
 }
 
 // registerScionPorts basically register the following UDP ports in gopacket such as SCION is the
-// next layer. In other words, map the following ports to expect SCION as the payload.
-func registerScionPorts() {
+// next layer. In other words, map the following ports to expect SCION as the payload. The layer
+// type selects the SCION header version.
+func registerScionPorts(scionLayer gopacket.LayerType) {
 	// Bind ports to SCION layer
-	golayers.RegisterUDPPortLayerType(golayers.UDPPort(30041), layers.LayerTypeScion)
+	golayers.RegisterUDPPortLayerType(golayers.UDPPort(30041), scionLayer)
 	for i := 30000; i < 30010; i += 1 {
-		golayers.RegisterUDPPortLayerType(golayers.UDPPort(i), layers.LayerTypeScion)
+		golayers.RegisterUDPPortLayerType(golayers.UDPPort(i), scionLayer)
 	}
 	for i := 50000; i < 50010; i += 1 {
-		golayers.RegisterUDPPortLayerType(golayers.UDPPort(i), layers.LayerTypeScion)
+		golayers.RegisterUDPPortLayerType(golayers.UDPPort(i), scionLayer)
 	}
 }
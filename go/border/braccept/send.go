@@ -31,3 +31,14 @@ func SendPackets(pkts ...*DevTaggedLayers) {
 		devInfo.Handle.WritePacketData(raw)
 	}
 }
+
+// SendPacketsV2 sends the packets on their devices as they are.
+func SendPacketsV2(pkts ...*DevPkt) {
+	for _, pkt := range pkts {
+		devInfo, ok := shared.DevByName[pkt.Dev]
+		if !ok {
+			panic(fmt.Errorf("No device information for: %s\n", pkt.Dev))
+		}
+		devInfo.Handle.WritePacketData(pkt.Pkt.Data())
+	}
+}
@@ -0,0 +1,493 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+	golayers "github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/border/braccept/shared"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+// The tests in this file are run against a border router that forwards packets with the SCION
+// header v2. They use the br_multi topology, the router under test is brA.
+
+const (
+	hostMACV2   = "f0:0d:ca:fe:be:ef"
+	brMACV2     = "f0:0d:ca:fe:00:01"
+	brAddrV2    = "192.168.0.11:30001"
+	localIAV2   = "1-ff00:0:1"
+	localHostV2 = "192.168.0.11"
+)
+
+func br_v2() int {
+	var failures int
+
+	failures += child_to_internal_host_v2()
+	failures += internal_host_to_child_v2()
+	failures += child_to_parent_v2()
+	failures += parent_to_child_v2()
+	failures += child_to_internal_parent_v2()
+	failures += xover_child_to_child_v2()
+	failures += child_to_peer_v2()
+
+	failures += scmp_bad_mac_v2()
+	failures += scmp_expired_hop_v2()
+
+	return failures
+}
+
+func child_to_internal_host_v2() int {
+	up := newSegmentV2(false, false, shared.TsNow32,
+		path.HopField{ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	s0 := scionV2("1-ff00:0:4", "172.16.4.1", localIAV2, "192.168.0.51",
+		pathV2(1, []segmentV2{up}, 1))
+	pkt0 := fromExternalV2(141).packet(udpV2(s0)...)
+
+	// The SegID is updated at ingress.
+	s1 := scionV2("1-ff00:0:4", "172.16.4.1", localIAV2, "192.168.0.51",
+		pathV2(1, []segmentV2{up}, 0))
+	pkt1 := toInternalV2("192.168.0.51:30041").expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 child to internal/host", defaultTimeout, pkt1)
+}
+
+func internal_host_to_child_v2() int {
+	down := newSegmentV2(true, false, shared.TsNow32,
+		path.HopField{ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	s0 := scionV2(localIAV2, "192.168.0.51", "1-ff00:0:4", "172.16.4.1",
+		pathV2(0, []segmentV2{down}, 0))
+	pkt0 := fromInternalV2("192.168.0.51:30041").packet(udpV2(s0)...)
+
+	// The SegID is updated at egress.
+	s1 := scionV2(localIAV2, "192.168.0.51", "1-ff00:0:4", "172.16.4.1",
+		pathV2(1, []segmentV2{down}, 1))
+	pkt1 := toExternalV2(141).expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 internal/host to child", defaultTimeout, pkt1)
+}
+
+func child_to_parent_v2() int {
+	up := newSegmentV2(false, false, shared.TsNow32,
+		path.HopField{ConsEgress: 311, ExpTime: 63},
+		path.HopField{ConsIngress: 131, ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	s0 := scionV2("1-ff00:0:4", "172.16.4.1", "2-ff00:0:3", "172.16.3.1",
+		pathV2(1, []segmentV2{up}, 2))
+	pkt0 := fromExternalV2(141).packet(udpV2(s0)...)
+
+	s1 := scionV2("1-ff00:0:4", "172.16.4.1", "2-ff00:0:3", "172.16.3.1",
+		pathV2(2, []segmentV2{up}, 1))
+	pkt1 := toExternalV2(131).expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 child to parent", defaultTimeout, pkt1)
+}
+
+func parent_to_child_v2() int {
+	down := newSegmentV2(true, false, shared.TsNow32,
+		path.HopField{ConsEgress: 311, ExpTime: 63},
+		path.HopField{ConsIngress: 131, ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	s0 := scionV2("2-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "172.16.4.1",
+		pathV2(1, []segmentV2{down}, 1))
+	pkt0 := fromExternalV2(131).packet(udpV2(s0)...)
+
+	s1 := scionV2("2-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "172.16.4.1",
+		pathV2(2, []segmentV2{down}, 2))
+	pkt1 := toExternalV2(141).expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 parent to child", defaultTimeout, pkt1)
+}
+
+func child_to_internal_parent_v2() int {
+	up := newSegmentV2(false, false, shared.TsNow32,
+		path.HopField{ConsEgress: 911, ExpTime: 63},
+		path.HopField{ConsIngress: 191, ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	s0 := scionV2("1-ff00:0:4", "172.16.4.1", "1-ff00:0:9", "172.16.9.1",
+		pathV2(1, []segmentV2{up}, 2))
+	pkt0 := fromExternalV2(141).packet(udpV2(s0)...)
+
+	// The packet is forwarded to brD, which owns the egress interface. The SegID is updated, but
+	// the path is not incremented.
+	s1 := scionV2("1-ff00:0:4", "172.16.4.1", "1-ff00:0:9", "172.16.9.1",
+		pathV2(1, []segmentV2{up}, 1))
+	pkt1 := toInternalV2("192.168.0.14:30004").expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 child to internal/parent", defaultTimeout, pkt1)
+}
+
+func xover_child_to_child_v2() int {
+	up := newSegmentV2(false, false, shared.TsNow32,
+		path.HopField{ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	down := newSegmentV2(true, false, shared.TsNow32,
+		path.HopField{ConsEgress: 151, ExpTime: 63},
+		path.HopField{ConsIngress: 511, ExpTime: 63},
+	)
+	s0 := scionV2("1-ff00:0:4", "172.16.4.1", "1-ff00:0:5", "172.16.5.1",
+		pathV2(1, []segmentV2{up, down}, 1, 0))
+	pkt0 := fromExternalV2(141).packet(udpV2(s0)...)
+
+	s1 := scionV2("1-ff00:0:4", "172.16.4.1", "1-ff00:0:5", "172.16.5.1",
+		pathV2(3, []segmentV2{up, down}, 0, 1))
+	pkt1 := toExternalV2(151).expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 xover child to child", defaultTimeout, pkt1)
+}
+
+func child_to_peer_v2() int {
+	up := newSegmentV2(false, true, shared.TsNow32,
+		path.HopField{ConsIngress: 121, ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	down := newSegmentV2(true, true, shared.TsNow32,
+		path.HopField{ConsIngress: 211, ExpTime: 63},
+	)
+	s0 := scionV2("1-ff00:0:4", "172.16.4.1", "1-ff00:0:2", "172.16.2.1",
+		pathV2(1, []segmentV2{up, down}, 1, 0))
+	pkt0 := fromExternalV2(141).packet(udpV2(s0)...)
+
+	// The SegIDs are not updated at the peering hop fields, the segment change happens at the
+	// egress router.
+	s1 := scionV2("1-ff00:0:4", "172.16.4.1", "1-ff00:0:2", "172.16.2.1",
+		pathV2(2, []segmentV2{up, down}, 1, 0))
+	pkt1 := toExternalV2(121).expected(udpV2(s1)...)
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2("v2 child to peer", defaultTimeout, pkt1)
+}
+
+func scmp_bad_mac_v2() int {
+	down := newSegmentV2(true, false, shared.TsNow32,
+		path.HopField{ConsEgress: 311, ExpTime: 63},
+		path.HopField{ConsIngress: 131, ConsEgress: 141, ExpTime: 63},
+		path.HopField{ConsIngress: 411, ExpTime: 63},
+	)
+	down.hops[1].Mac = []byte{0, 1, 2, 3, 4, 5}
+	return scmpParameterProblemV2("v2 scmp bad mac", down, slayers.SCMPCodeInvalidHopFieldMAC)
+}
+
+func scmp_expired_hop_v2() int {
+	// An ExpTime of 0 expires after 337.5 seconds.
+	down := newSegmentV2(true, false, shared.TsNow32-3600,
+		path.HopField{ConsEgress: 311, ExpTime: 0},
+		path.HopField{ConsIngress: 131, ConsEgress: 141, ExpTime: 0},
+		path.HopField{ConsIngress: 411, ExpTime: 0},
+	)
+	return scmpParameterProblemV2("v2 scmp expired hop field", down, slayers.SCMPCodePathExpired)
+}
+
+// scmpParameterProblemV2 sends a packet from the parent along the down segment and checks that
+// the router replies with a parameter problem that points to the current hop field.
+func scmpParameterProblemV2(desc string, down segmentV2, code uint8) int {
+	s0 := scionV2("2-ff00:0:3", "172.16.3.1", "1-ff00:0:4", "172.16.4.1",
+		pathV2(1, []segmentV2{down}, 1))
+	pkt0 := fromExternalV2(131).packet(udpV2(s0)...)
+
+	// The reply takes the reversed path, which the router already processed.
+	rev := pathV2(1, []segmentV2{down}, 1)
+	if err := rev.Reverse(); err != nil {
+		panic(err)
+	}
+	rev.PathMeta.CurrHF++
+	s1 := scionV2(localIAV2, localHostV2, "2-ff00:0:3", "172.16.3.1", rev)
+	s1.NextHdr = common.L4SCMP
+	scmp := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem, code),
+	}
+	if err := scmp.SetNetworkLayerForChecksum(s1); err != nil {
+		panic(err)
+	}
+	// The current hop field is at offset 60: common header (12), address header (24), path meta
+	// header (4), info field (8) and first hop field (12).
+	quote := pkt0.Pkt.Layer(golayers.LayerTypeUDP).LayerPayload()
+	pkt1 := toExternalV2(131).expected(s1, scmp, &slayers.SCMPParameterProblem{Pointer: 60},
+		gopacket.Payload(quote))
+
+	SendPacketsV2(pkt0)
+
+	return ExpectedPacketsV2(desc, defaultTimeout, pkt1)
+}
+
+// ExpectedPacketsV2 waits for the SCION header v2 packets. Packets that the router originates
+// towards the local AS, e.g., interface state requests, are ignored.
+func ExpectedPacketsV2(desc string, to string, pkts ...*DevPkt) int {
+	return expectPackets(desc, to, pkts, isControlPktV2)
+}
+
+// isControlPktV2 reports whether the packet is a SCION/UDP packet that the router sends to the
+// local AS.
+func isControlPktV2(devIdx int, pkt gopacket.Packet) bool {
+	if shared.DevList[devIdx].ContDev != "veth_int" {
+		return false
+	}
+	s, ok := pkt.Layer(slayers.LayerTypeSCION).(*slayers.SCION)
+	if !ok || s.NextHdr != common.L4UDP || !s.SrcIA.Equal(xtest.MustParseIA(localIAV2)) {
+		return false
+	}
+	src, err := s.SrcAddr()
+	if err != nil {
+		return false
+	}
+	ip, ok := src.(*net.IPAddr)
+	return ok && ip.IP.Equal(net.ParseIP(localHostV2))
+}
+
+// segmentV2 is a path segment with the hop fields in construction direction.
+type segmentV2 struct {
+	consDir   bool
+	peer      bool
+	timestamp uint32
+	hops      []path.HopField
+	// segIDs contains the SegID that the MAC of the hop field at the same index is computed
+	// with, followed by the SegID after the last hop field.
+	segIDs []uint16
+}
+
+// newSegmentV2 creates a segment from the hop fields in construction direction and computes
+// their MACs. The SegID is not chained with the MAC of a peering hop field, which is the first
+// hop field of a peering segment.
+func newSegmentV2(consDir, peer bool, ts uint32, hops ...path.HopField) segmentV2 {
+	s := segmentV2{
+		consDir:   consDir,
+		peer:      peer,
+		timestamp: ts,
+		hops:      hops,
+		segIDs:    []uint16{0x1234},
+	}
+	for i := range s.hops {
+		s.hops[i].Mac = path.MAC(shared.HashMac, s.info(i), &s.hops[i])
+		segID := s.segIDs[i]
+		if !(peer && i == 0) {
+			segID ^= uint16(s.hops[i].Mac[0])<<8 | uint16(s.hops[i].Mac[1])
+		}
+		s.segIDs = append(s.segIDs, segID)
+	}
+	return s
+}
+
+// info returns the info field of the segment with the SegID at index i of the SegIDs.
+func (s segmentV2) info(i int) *path.InfoField {
+	return &path.InfoField{
+		ConsDir:   s.consDir,
+		Peer:      s.peer,
+		SegID:     s.segIDs[i],
+		Timestamp: s.timestamp,
+	}
+}
+
+// hopFields returns copies of the hop fields in the direction of travel.
+func (s segmentV2) hopFields() []*path.HopField {
+	hops := make([]*path.HopField, len(s.hops))
+	for i := range s.hops {
+		hop := s.hops[i]
+		if s.consDir {
+			hops[i] = &hop
+		} else {
+			hops[len(hops)-1-i] = &hop
+		}
+	}
+	return hops
+}
+
+// pathV2 creates a path that is at the hop field currHF. The info field of each segment carries
+// the SegID with the corresponding index in segIDs.
+func pathV2(currHF uint8, segs []segmentV2, segIDs ...int) *scion.Decoded {
+	p := &scion.Decoded{}
+	for i, seg := range segs {
+		p.InfoFields = append(p.InfoFields, seg.info(segIDs[i]))
+		p.HopFields = append(p.HopFields, seg.hopFields()...)
+		p.PathMeta.SegLen[i] = uint8(len(seg.hops))
+		if int(currHF) >= p.NumHops && int(currHF) < len(p.HopFields) {
+			p.PathMeta.CurrINF = uint8(i)
+		}
+		p.NumHops = len(p.HopFields)
+	}
+	p.NumINF = len(segs)
+	p.PathMeta.CurrHF = currHF
+	return p
+}
+
+// scionV2 creates the SCION header of a packet on the path.
+func scionV2(srcIA, src, dstIA, dst string, p *scion.Decoded) *slayers.SCION {
+	s := &slayers.SCION{
+		FlowID:   1,
+		PathType: slayers.PathTypeSCION,
+		SrcIA:    xtest.MustParseIA(srcIA),
+		DstIA:    xtest.MustParseIA(dstIA),
+		Path:     p,
+	}
+	if err := s.SetSrcAddr(&net.IPAddr{IP: net.ParseIP(src)}); err != nil {
+		panic(err)
+	}
+	if err := s.SetDstAddr(&net.IPAddr{IP: net.ParseIP(dst)}); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// udpV2 returns the layers of a SCION/UDP packet with the SCION header.
+func udpV2(s *slayers.SCION) []gopacket.SerializableLayer {
+	s.NextHdr = common.L4UDP
+	udp := &slayers.UDP{}
+	udp.SrcPort, udp.DstPort = 40111, 40222
+	if err := udp.SetNetworkLayerForChecksum(s); err != nil {
+		panic(err)
+	}
+	return []gopacket.SerializableLayer{s, udp, gopacket.Payload("braccept v2")}
+}
+
+// underlayV2 is the underlay of a packet on a braccept device.
+type underlayV2 struct {
+	Dev    string
+	SrcMAC string
+	DstMAC string
+	Src    string
+	Dst    string
+}
+
+// fromExternalV2 returns the underlay of packets from the neighbor on the interface.
+func fromExternalV2(ifid int) underlayV2 {
+	n := ifid / 10
+	return underlayV2{
+		Dev:    fmt.Sprintf("veth_%d", ifid),
+		SrcMAC: hostMACV2,
+		DstMAC: fmt.Sprintf("f0:0d:ca:fe:00:%d", n),
+		Src:    fmt.Sprintf("192.168.%d.3:40000", n),
+		Dst:    fmt.Sprintf("192.168.%d.2:50000", n),
+	}
+}
+
+// toExternalV2 returns the underlay of packets to the neighbor on the interface.
+func toExternalV2(ifid int) underlayV2 {
+	return fromExternalV2(ifid).reverse()
+}
+
+// fromInternalV2 returns the underlay of packets from the host in the local AS.
+func fromInternalV2(src string) underlayV2 {
+	return underlayV2{
+		Dev:    "veth_int",
+		SrcMAC: hostMACV2,
+		DstMAC: brMACV2,
+		Src:    src,
+		Dst:    brAddrV2,
+	}
+}
+
+// toInternalV2 returns the underlay of packets to the host in the local AS.
+func toInternalV2(dst string) underlayV2 {
+	return fromInternalV2(dst).reverse()
+}
+
+func (u underlayV2) reverse() underlayV2 {
+	return underlayV2{
+		Dev:    u.Dev,
+		SrcMAC: u.DstMAC,
+		DstMAC: u.SrcMAC,
+		Src:    u.Dst,
+		Dst:    u.Src,
+	}
+}
+
+// packet serializes the layers on top of the underlay.
+func (u underlayV2) packet(l ...gopacket.SerializableLayer) *DevPkt {
+	srcMAC, err := net.ParseMAC(u.SrcMAC)
+	if err != nil {
+		panic(err)
+	}
+	dstMAC, err := net.ParseMAC(u.DstMAC)
+	if err != nil {
+		panic(err)
+	}
+	src, err := net.ResolveUDPAddr("udp4", u.Src)
+	if err != nil {
+		panic(err)
+	}
+	dst, err := net.ResolveUDPAddr("udp4", u.Dst)
+	if err != nil {
+		panic(err)
+	}
+	eth := &golayers.Ethernet{
+		SrcMAC:       srcMAC,
+		DstMAC:       dstMAC,
+		EthernetType: golayers.EthernetTypeIPv4,
+	}
+	ip := &golayers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		Flags:    golayers.IPv4DontFragment,
+		Protocol: golayers.IPProtocolUDP,
+		SrcIP:    src.IP,
+		DstIP:    dst.IP,
+	}
+	udp := &golayers.UDP{
+		SrcPort: golayers.UDPPort(src.Port),
+		DstPort: golayers.UDPPort(dst.Port),
+	}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		panic(err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err = gopacket.SerializeLayers(buf, opts, append([]gopacket.SerializableLayer{eth, ip, udp},
+		l...)...)
+	if err != nil {
+		panic(err)
+	}
+	return &DevPkt{
+		Dev: u.Dev,
+		Pkt: gopacket.NewPacket(buf.Bytes(), golayers.LayerTypeEthernet, gopacket.Default),
+	}
+}
+
+// expected is like packet, but the IPv4 ID and checksum, which the router's kernel fills in,
+// are ignored when comparing the packet.
+func (u underlayV2) expected(l ...gopacket.SerializableLayer) *DevPkt {
+	pkt := u.packet(l...)
+	ip := pkt.Pkt.Layer(golayers.LayerTypeIPv4).(*golayers.IPv4)
+	ip.Id, ip.Checksum = 0, 0
+	return pkt
+}
@@ -17,11 +17,15 @@ package brconf
 import (
 	"io"
 	"strings"
+	"time"
 
+	"github.com/scionproto/scion/go/border/bfd"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/config"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/util"
 )
 
 var _ config.Config = (*Config)(nil)
@@ -76,26 +80,107 @@ type BR struct {
 	// RollbackFailAction indicates the action that should be taken
 	// if the rollback fails.
 	RollbackFailAction FailAction `toml:"rollback_fail_action,omitempty"`
+	// SCMPAuth enables the DRKey authentication of the SCMP errors that the
+	// router originates.
+	SCMPAuth bool `toml:"scmp_auth,omitempty"`
+	// DRKeyEpochDuration is the duration of the DRKey epochs. It must match
+	// the DRKey configuration of the control service.
+	DRKeyEpochDuration util.DurWrap `toml:"drkey_epoch_duration,omitempty"`
+	// BFD is the configuration of the BFD sessions on the external interfaces.
+	BFD BFD `toml:"bfd,omitempty"`
 }
 
 func (cfg *BR) InitDefaults() {
 	if cfg.RollbackFailAction != FailActionContinue {
 		cfg.RollbackFailAction = FailActionFatal
 	}
+	if cfg.DRKeyEpochDuration.Duration == 0 {
+		cfg.DRKeyEpochDuration.Duration = drkey.DefaultEpochDuration
+	}
+	config.InitAll(&cfg.BFD)
 }
 
 func (cfg *BR) Validate() error {
-	return cfg.RollbackFailAction.Validate()
+	if cfg.DRKeyEpochDuration.Duration < time.Minute {
+		return common.NewBasicError("drkey_epoch_duration must be at least 1m", nil,
+			"actual", cfg.DRKeyEpochDuration)
+	}
+	if err := cfg.RollbackFailAction.Validate(); err != nil {
+		return err
+	}
+	return config.ValidateAll(&cfg.BFD)
 }
 
-func (cfg *BR) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
+func (cfg *BR) Sample(dst io.Writer, path config.Path, ctx config.CtxMap) {
 	config.WriteString(dst, brSample)
+	config.WriteSample(dst, path, ctx, &cfg.BFD)
 }
 
 func (cfg *BR) ConfigName() string {
 	return "br"
 }
 
+var _ config.Config = (*BFD)(nil)
+
+// BFD contains the configuration of the BFD sessions that the router runs on
+// its external interfaces.
+type BFD struct {
+	// Enabled enables BFD on all external interfaces. The neighboring routers
+	// must have BFD enabled as well.
+	Enabled bool `toml:"enabled,omitempty"`
+	// DesiredMinTxInterval is the minimum interval at which control packets
+	// are sent.
+	DesiredMinTxInterval util.DurWrap `toml:"desired_min_tx_interval,omitempty"`
+	// RequiredMinRxInterval is the minimum interval between received control
+	// packets that the router supports.
+	RequiredMinRxInterval util.DurWrap `toml:"required_min_rx_interval,omitempty"`
+	// DetectMult is the detection time multiplier. The link is declared down
+	// if no control packet is received during DetectMult times the
+	// negotiated transmission interval of the neighbor.
+	DetectMult uint8 `toml:"detect_mult,omitempty"`
+}
+
+func (cfg *BFD) InitDefaults() {
+	if cfg.DesiredMinTxInterval.Duration == 0 {
+		cfg.DesiredMinTxInterval.Duration = bfd.DefaultInterval
+	}
+	if cfg.RequiredMinRxInterval.Duration == 0 {
+		cfg.RequiredMinRxInterval.Duration = bfd.DefaultInterval
+	}
+	if cfg.DetectMult == 0 {
+		cfg.DetectMult = bfd.DefaultDetectMult
+	}
+}
+
+func (cfg *BFD) Validate() error {
+	if cfg.DesiredMinTxInterval.Duration > bfd.MaxInterval {
+		return common.NewBasicError("desired_min_tx_interval too large", nil,
+			"max", bfd.MaxInterval, "actual", cfg.DesiredMinTxInterval)
+	}
+	if cfg.RequiredMinRxInterval.Duration > bfd.MaxInterval {
+		return common.NewBasicError("required_min_rx_interval too large", nil,
+			"max", bfd.MaxInterval, "actual", cfg.RequiredMinRxInterval)
+	}
+	return nil
+}
+
+func (cfg *BFD) Sample(dst io.Writer, _ config.Path, _ config.CtxMap) {
+	config.WriteString(dst, bfdSample)
+}
+
+func (cfg *BFD) ConfigName() string {
+	return "bfd"
+}
+
+// SessionConfig returns the configuration of the BFD sessions.
+func (cfg *BFD) SessionConfig() bfd.Config {
+	return bfd.Config{
+		DesiredMinTxInterval:  cfg.DesiredMinTxInterval.Duration,
+		RequiredMinRxInterval: cfg.RequiredMinRxInterval.Duration,
+		DetectMult:            cfg.DetectMult,
+	}
+}
+
 type FailAction string
 
 const (
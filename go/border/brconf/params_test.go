@@ -21,6 +21,8 @@ import (
 	"github.com/pelletier/go-toml"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/scionproto/scion/go/border/bfd"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/env/envtest"
 	"github.com/scionproto/scion/go/lib/log/logtest"
 )
@@ -53,4 +55,10 @@ func CheckTestConfig(t *testing.T, cfg *Config, id string) {
 
 func CheckTestBRConfig(t *testing.T, cfg *BR) {
 	assert.Equal(t, FailActionFatal, cfg.RollbackFailAction)
+	assert.False(t, cfg.SCMPAuth)
+	assert.Equal(t, drkey.DefaultEpochDuration, cfg.DRKeyEpochDuration.Duration)
+	assert.False(t, cfg.BFD.Enabled)
+	assert.Equal(t, bfd.DefaultInterval, cfg.BFD.DesiredMinTxInterval.Duration)
+	assert.Equal(t, bfd.DefaultInterval, cfg.BFD.RequiredMinRxInterval.Duration)
+	assert.EqualValues(t, bfd.DefaultDetectMult, cfg.BFD.DetectMult)
 }
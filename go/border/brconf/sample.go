@@ -20,4 +20,29 @@ const brSample = `
 # Action that should be taken when an error occurs during a context rollback.
 # (fatal | continue) (default fatal)
 rollback_fail_action = "fatal"
+
+# Authenticate the SCMP errors originated by the router with DRKey. (default false)
+scmp_auth = false
+
+# The duration of the DRKey epochs. Must match the epoch duration configured in
+# the control service. (default 24h)
+drkey_epoch_duration = "24h"
+`
+
+const bfdSample = `
+# Run BFD sessions on all external interfaces. The neighboring routers must
+# have BFD enabled as well. (default false)
+enabled = false
+
+# The minimum interval at which BFD control packets are sent. (default 200ms)
+desired_min_tx_interval = "200ms"
+
+# The minimum interval between received BFD control packets that the router
+# supports. (default 200ms)
+required_min_rx_interval = "200ms"
+
+# The detection time multiplier. A link is declared down if no control packet
+# is received during detect_mult times the transmission interval of the
+# neighbor. (default 3)
+detect_mult = 3
 `
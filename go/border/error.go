@@ -73,6 +73,11 @@ func (r *Router) PacketError() {
 // metadata attached to the error object, then an SCMP error response is
 // generated and sent.
 func (r *Router) doPktError(rp *rpkt.RtrPkt, perr error) {
+	var serrV2 *rpkt.SCMPErrorV2
+	if errors.As(perr, &serrV2) {
+		r.doPktErrorV2(rp, serrV2)
+		return
+	}
 	var serr *scmp.Error
 	isSCMPErr := errors.As(perr, &serr)
 	if !isSCMPErr || rp.DirFrom == rcmn.DirSelf || rp.SCMPError {
@@ -116,6 +121,21 @@ func (r *Router) doPktError(rp *rpkt.RtrPkt, perr error) {
 	reply.Route()
 }
 
+// doPktErrorV2 sends an SCMP error message for a packet with the SCION header
+// v2 to the source of the packet.
+func (r *Router) doPktErrorV2(rp *rpkt.RtrPkt, serr *rpkt.SCMPErrorV2) {
+	if rp.DirFrom == rcmn.DirSelf || rp.SCMPError {
+		// Packet is from self, or packet is already an SCMP error, so no reply.
+		return
+	}
+	reply, err := rp.CreateSCMPErrorReplyV2(serr)
+	if err != nil {
+		rp.Error("Error creating SCMP response", "err", err)
+		return
+	}
+	reply.Route()
+}
+
 // createSCMPErrorReply generates an SCMP error reply to the supplied packet.
 func (r *Router) createSCMPErrorReply(rp *rpkt.RtrPkt, ct scmp.ClassType,
 	info scmp.Info) (*rpkt.RtrPkt, error) {
@@ -149,5 +169,10 @@ func (r *Router) createSCMPErrorReply(rp *rpkt.RtrPkt, ct scmp.ClassType,
 	}
 	sp.Pld = scmp.PldFromQuotes(ct, info, rp.L4Type, rp.GetRaw)
 	sp.L4 = scmp.NewHdr(ct, sp.Pld.Len())
+	if r.scmpAuth != nil {
+		if err := r.authSCMP(sp); err != nil {
+			return nil, common.NewBasicError("Unable to authenticate SCMP error", err)
+		}
+	}
 	return rp.CreateReply(sp)
 }
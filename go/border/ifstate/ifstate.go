@@ -137,6 +137,25 @@ func Process(ifStates *path_mgmt.IFStateInfos) {
 	metrics.Control.ReceivedIFStateInfo(cl).Inc()
 }
 
+// Deactivate marks the interface as inactive. It is used if the router itself
+// detects that the link is down, before the beacon service has revoked the
+// interface. Inactive interfaces are left untouched, such that an existing
+// revocation is kept. The state is overwritten by the next update of the beacon
+// service.
+func Deactivate(ifID common.IFIDType, ia addr.IA) {
+	s, ok := states.Load(ifID)
+	if !ok {
+		states.Store(ifID, &state{info: unsafe.Pointer(NewInfo(ifID, ia, false, nil, nil))})
+		return
+	}
+	oldInfo := (*Info)(atomic.LoadPointer(&s.info))
+	if !oldInfo.Active {
+		return
+	}
+	log.Info("IFState: intf deactivated", "ifid", ifID)
+	UpdateIfNew(ifID, oldInfo, NewInfo(ifID, ia, false, nil, nil))
+}
+
 // LoadState returns the state info for a given interface ID or nil.
 // The bool result indicates whether the state was found in the map.
 func LoadState(ifID common.IFIDType) (*Info, bool) {
@@ -0,0 +1,57 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/lib/prom"
+)
+
+type bfd struct {
+	state        *prometheus.GaugeVec
+	stateChanges *prometheus.CounterVec
+	recvErrors   *prometheus.CounterVec
+}
+
+func newBFD() bfd {
+	sub := "bfd"
+	return bfd{
+		state: prom.NewGaugeVecWithLabels(Namespace, sub,
+			"state", "BFD session state (0: AdminDown, 1: Down, 2: Init, 3: Up).",
+			IntfLabels{}),
+		stateChanges: prom.NewCounterVecWithLabels(Namespace, sub,
+			"state_changes_total", "Total number of BFD session state changes.",
+			IntfLabels{}),
+		recvErrors: prom.NewCounterVecWithLabels(Namespace, sub,
+			"recv_errors_total", "Total number of invalid BFD control packets received.",
+			IntfLabels{}),
+	}
+}
+
+// State returns the gauge for the given label set.
+func (b *bfd) State(l IntfLabels) prometheus.Gauge {
+	return b.state.WithLabelValues(l.Values()...)
+}
+
+// StateChanges returns the counter for the given label set.
+func (b *bfd) StateChanges(l IntfLabels) prometheus.Counter {
+	return b.stateChanges.WithLabelValues(l.Values()...)
+}
+
+// RecvErrors returns the counter for the given label set.
+func (b *bfd) RecvErrors(l IntfLabels) prometheus.Counter {
+	return b.recvErrors.WithLabelValues(l.Values()...)
+}
@@ -64,6 +64,7 @@ type control struct {
 	processErrors       *prometheus.CounterVec
 	receivedIFStateInfo *prometheus.CounterVec
 	sentIFStateReq      *prometheus.CounterVec
+	sentIFStateInfos    *prometheus.CounterVec
 	ifstate             *prometheus.GaugeVec
 	ifstateTick         prometheus.Counter
 	readRevInfos        *prometheus.CounterVec
@@ -83,6 +84,9 @@ func newControl() control {
 		sentIFStateReq: prom.NewCounterVecWithLabels(Namespace, sub,
 			"sent_ifstatereq_total", "Total number of sent ifstate requests.",
 			ControlLabels{}),
+		sentIFStateInfos: prom.NewCounterVecWithLabels(Namespace, sub,
+			"sent_ifstateinfos_total", "Total number of sent ifstate infos.",
+			ControlLabels{}),
 		ifstate: prom.NewGaugeVecWithLabels(Namespace, sub,
 			"interface_active", "Interface is active.", IntfLabels{}),
 		ifstateTick: prom.NewCounter(Namespace, sub,
@@ -114,6 +118,11 @@ func (c *control) SentIFStateReq(l ControlLabels) prometheus.Counter {
 	return c.sentIFStateReq.WithLabelValues(l.Values()...)
 }
 
+// SentIFStateInfos returns the counter for the given label set.
+func (c *control) SentIFStateInfos(l ControlLabels) prometheus.Counter {
+	return c.sentIFStateInfos.WithLabelValues(l.Values()...)
+}
+
 // IFState returns the gauge for the given label set.
 func (c *control) IFState(l IntfLabels) prometheus.Gauge {
 	return c.ifstate.WithLabelValues(l.Values()...)
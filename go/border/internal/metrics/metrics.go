@@ -49,6 +49,7 @@ var (
 	Output  = newOutput()
 	Process = newProcess()
 	Control = newControl()
+	BFD     = newBFD()
 )
 
 type IntfLabels struct {
@@ -29,6 +29,7 @@ import (
 	"github.com/scionproto/scion/go/border/rctx"
 	"github.com/scionproto/scion/go/border/rpkt"
 	"github.com/scionproto/scion/go/lib/assert"
+	"github.com/scionproto/scion/go/lib/capture"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/fatal"
 	"github.com/scionproto/scion/go/lib/log"
@@ -113,6 +114,7 @@ Top:
 		if assert.On {
 			assert.Must(pktsRead > 0, "Pktsread must be non-zero")
 		}
+		capturing := capture.Active()
 		// Loop over all read packets and set their metadata
 		for i := 0; i < pktsRead; i++ {
 			rp := pkts[i].(*rpkt.RtrPkt)
@@ -136,6 +138,9 @@ Top:
 			src := meta.Src
 			rp.Ingress.Src = src
 			rp.Ingress.IfID = s.Ifid
+			if capturing {
+				capture.Record(s.Label, capture.Inbound, rp.Raw, src, dst, rp.TimeIn)
+			}
 			inputBytes.Add(float64(msg.N))
 			inputPktSize.Observe(float64(msg.N))
 		}
@@ -254,9 +259,18 @@ func (r *Router) posixOutput(s *rctx.Sock, _, stopped chan struct{}) {
 		}
 		t = time.Since(start).Seconds()
 		bytes = 0
+		capturing := capture.Active()
 		for i := 0; i < pktsWritten; i++ {
-			rp := epkts[i].(*rpkt.EgressRtrPkt).Rp
+			erp := epkts[i].(*rpkt.EgressRtrPkt)
+			rp := erp.Rp
 			msg := &msgs[i]
+			if capturing {
+				pktDst := dst
+				if pktDst == nil {
+					pktDst = erp.Dst
+				}
+				capture.Record(s.Label, capture.Outbound, rp.Raw, src, pktDst, start)
+			}
 			if msg.N != len(rp.Raw) {
 				rp.Error("Unable to write full packet", "len", len(rp.Raw), "written", msg.N)
 			}
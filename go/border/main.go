@@ -26,10 +26,12 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/user"
+	"syscall"
 
 	"github.com/scionproto/scion/go/border/brconf"
 	"github.com/scionproto/scion/go/border/ifstate"
 	"github.com/scionproto/scion/go/lib/assert"
+	"github.com/scionproto/scion/go/lib/capture"
 	"github.com/scionproto/scion/go/lib/common"
 	libconfig "github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/env"
@@ -75,6 +77,7 @@ func realMain() int {
 		"config":   service.NewConfigHandler(cfg),
 		"status":   statusHandler,
 		"topology": itopo.TopologyHandler,
+		"capture":  capture.NewHandler(cfg.Features.HeaderV2),
 	}
 	if err := statusPages.Register(http.DefaultServeMux, cfg.General.ID); err != nil {
 		log.Error("registering status pages", "err", err)
@@ -85,6 +88,8 @@ func realMain() int {
 		log.Error("Setup failed", "err", err)
 		return 1
 	}
+	capture.NotifySignal(syscall.SIGUSR1, os.TempDir(), cfg.General.ID,
+		capture.Config{HeaderV2: cfg.Features.HeaderV2})
 	if err := checkPerms(); err != nil {
 		log.Error("Permissions checks failed", "err", err)
 		return 1
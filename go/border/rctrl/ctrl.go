@@ -44,7 +44,9 @@ var (
 	logger   log.Logger
 )
 
-func Control(sRevInfoQ chan rpkt.RawSRevCallbackArgs, dispatcherReconnect bool) {
+func Control(sRevInfoQ chan rpkt.RawSRevCallbackArgs, ifDownQ chan common.IFIDType,
+	dispatcherReconnect, headerV2 bool) {
+
 	var err error
 	logger = log.New("Part", "Control")
 	ctx := rctx.Get()
@@ -57,6 +59,7 @@ func Control(sRevInfoQ chan rpkt.RawSRevCallbackArgs, dispatcherReconnect bool)
 		LocalIA: ia,
 		Dispatcher: &snet.DefaultPacketDispatcherService{
 			Dispatcher: dispatcherService,
+			Version2:   headerV2,
 		},
 	}
 	ctrlAddr := ctx.Conf.BR.CtrlAddrs
@@ -73,6 +76,10 @@ func Control(sRevInfoQ chan rpkt.RawSRevCallbackArgs, dispatcherReconnect bool)
 		defer log.HandlePanic()
 		revInfoFwd(sRevInfoQ)
 	}()
+	go func() {
+		defer log.HandlePanic()
+		ifDownFwd(ifDownQ)
+	}()
 	processCtrl()
 }
 
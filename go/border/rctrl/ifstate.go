@@ -95,3 +95,62 @@ func genIFStateReq() error {
 	}
 	return errors.ToError()
 }
+
+// ifDownFwd takes the IDs of interfaces that the router detected to be down,
+// and reports them to the local Beacon Service (BS), such that the interfaces
+// are revoked without waiting for the keepalive timeout.
+func ifDownFwd(ifDownQ chan common.IFIDType) {
+	// Run forever.
+	for ifid := range ifDownQ {
+		if err := genIFDownInfo(ifid); err != nil {
+			logger.Error("Failed to report interface down", "ifid", ifid, "err", err)
+		}
+	}
+}
+
+// genIFDownInfo sends an Interface State info that marks the interface as
+// inactive to the local beacon services.
+func genIFDownInfo(ifid common.IFIDType) error {
+	cl := metrics.ControlLabels{
+		Result: metrics.ErrProcess,
+	}
+	infos := &path_mgmt.IFStateInfos{
+		Infos: []*path_mgmt.IFStateInfo{{IfID: ifid, Active: false}},
+	}
+	cpld, err := ctrl.NewPathMgmtPld(infos, nil, nil)
+	if err != nil {
+		metrics.Control.SentIFStateInfos(cl).Inc()
+		return common.NewBasicError("Generating IFStateInfos Ctrl payload", err)
+	}
+	scpld, err := cpld.SignedPld(context.TODO(), infra.NullSigner)
+	if err != nil {
+		metrics.Control.SentIFStateInfos(cl).Inc()
+		return common.NewBasicError("Generating IFStateInfos signed Ctrl payload", err)
+	}
+	pld, err := scpld.PackPld()
+	if err != nil {
+		metrics.Control.SentIFStateInfos(cl).Inc()
+		return common.NewBasicError("Writing IFStateInfos signed Ctrl payload", err)
+	}
+	bsAddrs, err := rctx.Get().ResolveSVCMulti(addr.SvcBS)
+	if err != nil {
+		cl.Result = metrics.ErrResolveSVC
+		metrics.Control.SentIFStateInfos(cl).Inc()
+		return common.NewBasicError("Resolving SVC BS multicast", err)
+	}
+
+	var errors common.MultiError
+	for _, a := range bsAddrs {
+		dst := &snet.SVCAddr{IA: ia, NextHop: a, SVC: addr.SvcBS.Multicast()}
+		if _, err := snetConn.WriteTo(pld, dst); err != nil {
+			cl.Result = metrics.ErrWrite
+			metrics.Control.SentIFStateInfos(cl).Inc()
+			errors = append(errors, common.NewBasicError("Writing IFStateInfos", err, "dst", dst))
+			continue
+		}
+		logger.Debug("Sent IFStateInfos", "dst", dst, "underlayDst", a, "ifid", ifid)
+		cl.Result = metrics.Success
+		metrics.Control.SentIFStateInfos(cl).Inc()
+	}
+	return errors.ToError()
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/assert"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/lib/scmp"
@@ -49,9 +50,17 @@ type Router struct {
 	sRevInfoQ chan rpkt.RawSRevCallbackArgs
 	// pktErrorQ is a channel for handling packet errors
 	pktErrorQ chan pktErrorArgs
+	// ifDownQ is a channel for reporting interfaces that BFD detected to be down.
+	ifDownQ chan common.IFIDType
 	// setCtxMtx serializes modifications to the router context. Topology updates
 	// can be caused by a SIGHUP reload.
 	setCtxMtx sync.Mutex
+	// scmpAuth holds the DRKey secret values used to authenticate SCMP errors.
+	// If nil, SCMP errors are not authenticated.
+	scmpAuth *drkey.SecretValueStore
+	// bfdSessions holds the BFD sessions of the external interfaces. If nil,
+	// BFD is disabled.
+	bfdSessions *bfdSessions
 }
 
 func NewRouter(id, confDir string) (*Router, error) {
@@ -59,6 +68,10 @@ func NewRouter(id, confDir string) (*Router, error) {
 	if err := r.setup(); err != nil {
 		return nil, err
 	}
+	if cfg.BR.SCMPAuth {
+		r.scmpAuth = drkey.NewSecretValueStore(rctx.Get().Conf.MasterKeys.Key0,
+			cfg.BR.DRKeyEpochDuration.Duration)
+	}
 	return r, nil
 }
 
@@ -71,7 +84,8 @@ func (r *Router) Start() {
 	}()
 	go func() {
 		defer log.HandlePanic()
-		rctrl.Control(r.sRevInfoQ, cfg.General.ReconnectToDispatcher)
+		rctrl.Control(r.sRevInfoQ, r.ifDownQ, cfg.General.ReconnectToDispatcher,
+			cfg.Features.HeaderV2)
 	}()
 }
 
@@ -102,7 +116,14 @@ func (r *Router) handleSock(s *rctx.Sock, stop, stopped chan struct{}) {
 		}
 		for i := 0; i < n; i++ {
 			rp := pkts[i].(*rpkt.RtrPkt)
-			r.processPacket(rp)
+			switch {
+			case r.isBFD(rp):
+				r.bfdSessions.deliver(rp.Ingress.IfID, rp.Raw)
+			case cfg.Features.HeaderV2:
+				r.processPacketV2(rp)
+			default:
+				r.processPacket(rp)
+			}
 			rp.Release()
 			pkts[i] = nil
 		}
@@ -186,3 +207,32 @@ func (r *Router) processPacket(rp *rpkt.RtrPkt) {
 		}
 	}
 }
+
+// processPacketV2 is the counterpart of processPacket for packets with the
+// SCION header v2. It parses the packet, validates and updates its path, and
+// routes it.
+func (r *Router) processPacketV2(rp *rpkt.RtrPkt) {
+	l := metrics.ProcessLabels{
+		IntfIn:  metrics.IntfToLabel(rp.Ingress.IfID),
+		IntfOut: metrics.Drop,
+	}
+	rp.Id = log.NewDebugID().String()
+	rp.Logger = log.New("rpkt", rp.Id)
+	if err := rp.ParseV2(); err != nil {
+		r.handlePktError(rp, err, "Error parsing packet")
+		l.Result = metrics.ErrParse
+		metrics.Process.Pkts(l).Inc()
+		return
+	}
+	if err := rp.ProcessV2(); err != nil {
+		r.handlePktError(rp, err, "Error processing packet")
+		l.Result = metrics.ErrProcess
+		metrics.Process.Pkts(l).Inc()
+		return
+	}
+	if err := rp.Route(); err != nil {
+		r.handlePktError(rp, err, "Error routing packet")
+		l.Result = metrics.ErrRoute
+		metrics.Process.Pkts(l).Inc()
+	}
+}
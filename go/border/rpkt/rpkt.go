@@ -119,6 +119,8 @@ type RtrPkt struct {
 	// SCMPError flags if the packet is an SCMP Error packet, in which case it should never trigger
 	// an error response packet. (PARSE, if SCMP extension header is present)
 	SCMPError bool
+	// v2 is the SCION header v2 state of the packet. (PARSE/PROCESS, only for header v2 packets)
+	v2 v2State
 	// Logger is used to log messages associated with a packet. The Id field is automatically
 	// included in the output.
 	log.Logger
@@ -238,6 +240,7 @@ func (rp *RtrPkt) Reset() {
 	rp.pld = nil
 	rp.hooks = hooks{}
 	rp.SCMPError = false
+	rp.v2 = v2State{}
 	rp.Logger = nil
 	rp.Ctx = nil
 	rp.refCnt = 1
@@ -0,0 +1,481 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file handles forwarding of packets with the SCION header v2.
+
+package rpkt
+
+import (
+	"hash"
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+
+	"github.com/scionproto/scion/go/border/ifstate"
+	"github.com/scionproto/scion/go/border/rcmn"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/slayers/path/onehop"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+// v2State contains the state of a packet with the SCION header v2. The SCION
+// layer and the path reference the raw buffer of the packet, such that updates
+// of the path are directly reflected in the packet.
+type v2State struct {
+	// scion is the decoded SCION layer. (PARSE)
+	scion slayers.SCION
+	// path is the SCION path, if the packet has one. (PROCESS)
+	path *scion.Raw
+	// infoF is the current info field. (PROCESS)
+	infoF *path.InfoField
+	// hopF is the current hop field. (PROCESS)
+	hopF *path.HopField
+	// peering indicates that the current hop field is a peering hop field. (PROCESS)
+	peering bool
+	// segIDUpdated indicates that the SegID was updated at ingress. (PROCESS)
+	segIDUpdated bool
+	// xover indicates that the router switched the path segment. (PROCESS)
+	xover bool
+}
+
+// SCMPErrorV2 is the cause of a processing error of a header v2 packet that is
+// reported to the source of the packet with an SCMP error message.
+type SCMPErrorV2 struct {
+	// TypeCode is the type and code of the SCMP error message.
+	TypeCode slayers.SCMPTypeCode
+	// Info is the type specific part of the SCMP error message.
+	Info gopacket.SerializableLayer
+}
+
+func (e *SCMPErrorV2) Error() string {
+	return e.TypeCode.String()
+}
+
+func newParameterProblem(code uint8, pointer uint16) *SCMPErrorV2 {
+	return &SCMPErrorV2{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem, code),
+		Info:     &slayers.SCMPParameterProblem{Pointer: pointer},
+	}
+}
+
+// ParseV2 parses the SCION header v2 of the packet.
+func (rp *RtrPkt) ParseV2() error {
+	s := &rp.v2.scion
+	if err := s.DecodeFromBytes(rp.Raw, gopacket.NilDecodeFeedback); err != nil {
+		return err
+	}
+	if s.NextHdr == common.L4SCMP && len(s.Payload) > 0 {
+		rp.SCMPError = !slayers.CreateSCMPTypeCode(s.Payload[0], 0).InfoMsg()
+	}
+	if int(s.PayloadLen) != len(s.Payload) {
+		// The payload length field is at offset 6 of the common header.
+		return serrors.WrapStr("Invalid payload length",
+			newParameterProblem(slayers.SCMPCodeInvalidPacketSize, 6),
+			"expected", s.PayloadLen, "actual", len(s.Payload))
+	}
+	return nil
+}
+
+// ProcessV2 validates and updates the path of a parsed SCION header v2 packet
+// and determines where the packet is forwarded to. The packet is then sent
+// with Route.
+func (rp *RtrPkt) ProcessV2() error {
+	switch p := rp.v2.scion.Path.(type) {
+	case *scion.Raw:
+		return rp.processSCIONV2(p)
+	case *onehop.Path:
+		return rp.processOHPV2(p)
+	default:
+		// The path type field is at offset 8 of the common header.
+		return serrors.WrapStr("Unsupported path type",
+			newParameterProblem(slayers.SCMPCodeUnknownPathType, 8),
+			"type", rp.v2.scion.PathType)
+	}
+}
+
+func (rp *RtrPkt) processSCIONV2(p *scion.Raw) error {
+	rp.v2.path = p
+	if p.NumINF == 0 {
+		return serrors.WrapStr("Empty path",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.pathPointerV2()))
+	}
+	if err := rp.loadCurrentV2(); err != nil {
+		return err
+	}
+	peering, err := rp.isPeeringV2()
+	if err != nil {
+		return err
+	}
+	rp.v2.peering = peering
+	if err := rp.validateHopExpiryV2(); err != nil {
+		return err
+	}
+	if err := rp.validateIngressIDV2(); err != nil {
+		return err
+	}
+	if err := rp.updateIngressSegIDV2(); err != nil {
+		return err
+	}
+	if err := rp.verifyMACV2(); err != nil {
+		return err
+	}
+	if rp.v2.scion.DstIA.Equal(rp.Ctx.Conf.IA) {
+		return rp.routeLocalV2()
+	}
+	// A segment change at a peering hop field happens at the egress router.
+	// Every other segment change happens at the ingress router.
+	if p.IsXover() && !rp.v2.peering {
+		if err := rp.xoverV2(); err != nil {
+			return err
+		}
+	}
+	egress := rp.egressIFIDV2()
+	if err := rp.validateEgressIDV2(egress); err != nil {
+		return err
+	}
+	if sock, ok := rp.Ctx.ExtSockOut[egress]; ok {
+		if state, ok := ifstate.LoadState(egress); ok && !state.Active {
+			return serrors.WrapStr("Egress interface down", &SCMPErrorV2{
+				TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeExternalInterfaceDown, 0),
+				Info: &slayers.SCMPExternalInterfaceDown{
+					IA:   rp.Ctx.Conf.IA,
+					IfID: uint64(egress),
+				},
+			}, "ifid", egress)
+		}
+		if err := rp.processEgressV2(); err != nil {
+			return err
+		}
+		rp.Egress = append(rp.Egress, EgressPair{S: sock})
+		return nil
+	}
+	// The egress interface is owned by another router of the local AS.
+	intf := rp.Ctx.Conf.Topo.IFInfoMap()[egress]
+	rp.Egress = append(rp.Egress, EgressPair{S: rp.Ctx.LocSockOut, Dst: intf.InternalAddr})
+	return nil
+}
+
+// loadCurrentV2 loads the current info and hop field of the path.
+func (rp *RtrPkt) loadCurrentV2() error {
+	var err error
+	if rp.v2.infoF, err = rp.v2.path.GetCurrentInfoField(); err != nil {
+		return serrors.WrapStr("Invalid current info field",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.pathPointerV2()), "err", err)
+	}
+	if rp.v2.hopF, err = rp.v2.path.GetCurrentHopField(); err != nil {
+		return serrors.WrapStr("Invalid current hop field",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.pathPointerV2()), "err", err)
+	}
+	return nil
+}
+
+// isPeeringV2 determines whether the current hop field is a peering hop
+// field. On a peering path, the peering hop fields are the last hop field of
+// the first segment and the first hop field of the second segment.
+func (rp *RtrPkt) isPeeringV2() (bool, error) {
+	if !rp.v2.infoF.Peer {
+		return false, nil
+	}
+	meta := rp.v2.path.PathMeta
+	if meta.SegLen[0] == 0 || meta.SegLen[1] == 0 || meta.SegLen[2] != 0 {
+		return false, serrors.WrapStr("Invalid peering path",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.pathPointerV2()),
+			"seg_len", meta.SegLen)
+	}
+	return meta.CurrHF == meta.SegLen[0]-1 || meta.CurrHF == meta.SegLen[0], nil
+}
+
+func (rp *RtrPkt) validateHopExpiryV2() error {
+	expiry := util.SecsToTime(rp.v2.infoF.Timestamp).Add(
+		spath.ExpTimeType(rp.v2.hopF.ExpTime).ToDuration())
+	if time.Now().After(expiry) {
+		return serrors.WrapStr("Hop field expired",
+			newParameterProblem(slayers.SCMPCodePathExpired, rp.hopPointerV2()),
+			"expiry", expiry)
+	}
+	return nil
+}
+
+// validateIngressIDV2 checks that the packet arrived on the interface that is
+// the ingress interface of the current hop field in the direction of travel.
+func (rp *RtrPkt) validateIngressIDV2() error {
+	if rp.DirFrom != rcmn.DirExternal {
+		return nil
+	}
+	ingress := rp.v2.hopF.ConsIngress
+	if !rp.v2.infoF.ConsDir {
+		ingress = rp.v2.hopF.ConsEgress
+	}
+	if common.IFIDType(ingress) != rp.Ingress.IfID {
+		return serrors.WrapStr("Ingress interface mismatch",
+			newParameterProblem(slayers.SCMPCodeUnknownHopFieldInterface, rp.hopPointerV2()),
+			"expected", rp.Ingress.IfID, "actual", ingress)
+	}
+	return nil
+}
+
+// updateIngressSegIDV2 updates the SegID when a packet against construction
+// direction enters the AS. The SegID is then the one the MAC of the current
+// hop field was computed with. The SegID is not updated at a peering hop field.
+func (rp *RtrPkt) updateIngressSegIDV2() error {
+	if rp.v2.infoF.ConsDir || rp.DirFrom != rcmn.DirExternal || rp.v2.peering {
+		return nil
+	}
+	rp.v2.infoF.UpdateSegID(rp.v2.hopF.Mac)
+	rp.v2.segIDUpdated = true
+	return rp.v2.path.SetInfoField(rp.v2.infoF, int(rp.v2.path.PathMeta.CurrINF))
+}
+
+func (rp *RtrPkt) verifyMACV2() error {
+	mac := rp.Ctx.HFMacPool.Get().(hash.Hash)
+	defer rp.Ctx.HFMacPool.Put(mac)
+	if err := path.VerifyMAC(mac, rp.v2.infoF, rp.v2.hopF); err != nil {
+		return serrors.WrapStr("Invalid hop field MAC",
+			newParameterProblem(slayers.SCMPCodeInvalidHopFieldMAC, rp.hopPointerV2()),
+			"err", err)
+	}
+	return nil
+}
+
+// xoverV2 switches to the next path segment and verifies its first hop field.
+func (rp *RtrPkt) xoverV2() error {
+	rp.v2.xover = true
+	if err := rp.incPathV2(); err != nil {
+		return err
+	}
+	if err := rp.loadCurrentV2(); err != nil {
+		return err
+	}
+	if err := rp.validateHopExpiryV2(); err != nil {
+		return err
+	}
+	return rp.verifyMACV2()
+}
+
+// egressIFIDV2 returns the egress interface of the current hop field in the
+// direction of travel.
+func (rp *RtrPkt) egressIFIDV2() common.IFIDType {
+	if rp.v2.infoF.ConsDir {
+		return common.IFIDType(rp.v2.hopF.ConsEgress)
+	}
+	return common.IFIDType(rp.v2.hopF.ConsIngress)
+}
+
+// validateEgressIDV2 checks that the egress interface exists and that the
+// combination of ingress and egress link types is allowed.
+func (rp *RtrPkt) validateEgressIDV2(egress common.IFIDType) error {
+	egressIntf, ok := rp.Ctx.Conf.Topo.IFInfoMap()[egress]
+	if !ok {
+		return serrors.WrapStr("Unknown egress interface",
+			newParameterProblem(slayers.SCMPCodeUnknownHopFieldInterface, rp.hopPointerV2()),
+			"ifid", egress)
+	}
+	if rp.DirFrom != rcmn.DirExternal {
+		if rp.v2.xover {
+			return serrors.WrapStr("Segment change on packet from local AS",
+				newParameterProblem(slayers.SCMPCodeInvalidSegmentChange, rp.hopPointerV2()))
+		}
+		if _, ok := rp.Ctx.ExtSockOut[egress]; !ok {
+			return serrors.WrapStr("Egress interface not owned by this router",
+				newParameterProblem(slayers.SCMPCodeUnknownHopFieldInterface,
+					rp.hopPointerV2()), "ifid", egress)
+		}
+		return nil
+	}
+	ingressLink := rp.Ctx.Conf.BR.IFs[rp.Ingress.IfID].LinkType
+	egressLink := egressIntf.LinkType
+	if !rp.v2.xover {
+		switch {
+		case ingressLink == topology.Core && egressLink == topology.Core,
+			ingressLink == topology.Child && egressLink == topology.Parent,
+			ingressLink == topology.Parent && egressLink == topology.Child,
+			ingressLink == topology.Child && egressLink == topology.Peer,
+			ingressLink == topology.Peer && egressLink == topology.Child:
+			return nil
+		}
+		return serrors.WrapStr("Invalid link combination",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.hopPointerV2()),
+			"ingress", ingressLink, "egress", egressLink)
+	}
+	switch {
+	case ingressLink == topology.Core && egressLink == topology.Child,
+		ingressLink == topology.Child && egressLink == topology.Core,
+		ingressLink == topology.Child && egressLink == topology.Child:
+		return nil
+	}
+	return serrors.WrapStr("Invalid segment change",
+		newParameterProblem(slayers.SCMPCodeInvalidSegmentChange, rp.hopPointerV2()),
+		"ingress", ingressLink, "egress", egressLink)
+}
+
+// processEgressV2 updates the path of a packet that leaves the AS.
+func (rp *RtrPkt) processEgressV2() error {
+	if rp.v2.infoF.ConsDir && !rp.v2.peering {
+		rp.v2.infoF.UpdateSegID(rp.v2.hopF.Mac)
+		err := rp.v2.path.SetInfoField(rp.v2.infoF, int(rp.v2.path.PathMeta.CurrINF))
+		if err != nil {
+			return err
+		}
+	}
+	return rp.incPathV2()
+}
+
+// incPathV2 increments the path and writes the updated path meta header to
+// the packet.
+func (rp *RtrPkt) incPathV2() error {
+	p := rp.v2.path
+	if err := p.IncPath(); err != nil {
+		return serrors.WrapStr("Incrementing path",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.pathPointerV2()), "err", err)
+	}
+	return p.PathMeta.SerializeTo(p.Raw[:scion.MetaLen])
+}
+
+// routeLocalV2 forwards a packet to its destination in the local AS.
+func (rp *RtrPkt) routeLocalV2() error {
+	if rp.DirFrom != rcmn.DirExternal {
+		return serrors.WrapStr("Packet from local AS to local AS",
+			newParameterProblem(slayers.SCMPCodeNonLocalDelivery, rp.dstAddrPointerV2()))
+	}
+	p := rp.v2.path
+	if int(p.PathMeta.CurrHF) != p.NumHops-1 {
+		return serrors.WrapStr("Local destination before end of path",
+			newParameterProblem(slayers.SCMPCodeInvalidPath, rp.hopPointerV2()))
+	}
+	return rp.egressLocalV2()
+}
+
+// egressLocalV2 sets the egress of a packet to the destination host in the
+// local AS. Packets to service addresses are sent to the service instance(s).
+func (rp *RtrPkt) egressLocalV2() error {
+	dst, err := rp.v2.scion.DstAddr()
+	if err != nil {
+		return serrors.WrapStr("Invalid destination address",
+			newParameterProblem(slayers.SCMPCodeInvalidDestinationAddress,
+				rp.dstAddrPointerV2()), "err", err)
+	}
+	switch a := dst.(type) {
+	case *net.IPAddr:
+		// Copy the IP, it references the buffer of the packet.
+		ip := append(net.IP(nil), a.IP...)
+		rp.Egress = append(rp.Egress, EgressPair{
+			S:   rp.Ctx.LocSockOut,
+			Dst: &net.UDPAddr{IP: ip, Port: topology.EndhostPort},
+		})
+		return nil
+	case addr.HostSVC:
+		addrs, err := rp.Ctx.ResolveSVC(a)
+		if err != nil {
+			return serrors.WrapStr("Resolving service address", &SCMPErrorV2{
+				TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeDestinationUnreachable,
+					slayers.SCMPCodeNoRoute),
+				Info: &slayers.SCMPDestinationUnreachable{},
+			}, "svc", a, "err", err)
+		}
+		for _, dst := range addrs {
+			rp.Egress = append(rp.Egress, EgressPair{S: rp.Ctx.LocSockOut, Dst: dst})
+		}
+		return nil
+	default:
+		return serrors.WrapStr("Unsupported destination address",
+			newParameterProblem(slayers.SCMPCodeInvalidDestinationAddress,
+				rp.dstAddrPointerV2()), "addr", dst)
+	}
+}
+
+// processOHPV2 processes a packet with a one-hop path. The path is created by
+// the beacon service of the first AS, and completed by the ingress router of
+// the second AS.
+func (rp *RtrPkt) processOHPV2(p *onehop.Path) error {
+	s := &rp.v2.scion
+	if !p.Info.ConsDir {
+		return serrors.New("One-hop path against construction direction")
+	}
+	mac := rp.Ctx.HFMacPool.Get().(hash.Hash)
+	defer rp.Ctx.HFMacPool.Put(mac)
+	if rp.DirFrom != rcmn.DirExternal {
+		// The one-hop path leaves the local AS.
+		egress := common.IFIDType(p.FirstHop.ConsEgress)
+		sock, ok := rp.Ctx.ExtSockOut[egress]
+		if !ok {
+			return serrors.New("One-hop path egress interface not owned by this router",
+				"ifid", egress)
+		}
+		if !s.SrcIA.Equal(rp.Ctx.Conf.IA) {
+			return serrors.New("One-hop path from remote source", "src", s.SrcIA)
+		}
+		if neighbor := rp.Ctx.Conf.BR.IFs[egress].IA; !s.DstIA.Equal(neighbor) {
+			return serrors.New("One-hop path destination is not the neighbor",
+				"dst", s.DstIA, "neighbor", neighbor)
+		}
+		if err := path.VerifyMAC(mac, &p.Info, &p.FirstHop); err != nil {
+			return serrors.WrapStr("Invalid one-hop path MAC", err)
+		}
+		p.Info.UpdateSegID(p.FirstHop.Mac)
+		if err := rp.serializePathV2(); err != nil {
+			return err
+		}
+		rp.Egress = append(rp.Egress, EgressPair{S: sock})
+		return nil
+	}
+	// The one-hop path enters the local AS.
+	if !s.DstIA.Equal(rp.Ctx.Conf.IA) {
+		return serrors.New("One-hop path to remote destination", "dst", s.DstIA)
+	}
+	if neighbor := rp.Ctx.Conf.BR.IFs[rp.Ingress.IfID].IA; !s.SrcIA.Equal(neighbor) {
+		return serrors.New("One-hop path source is not the neighbor",
+			"src", s.SrcIA, "neighbor", neighbor)
+	}
+	p.SecondHop = path.HopField{
+		ConsIngress: uint16(rp.Ingress.IfID),
+		ExpTime:     p.FirstHop.ExpTime,
+	}
+	p.SecondHop.Mac = path.MAC(mac, &p.Info, &p.SecondHop)
+	if err := rp.serializePathV2(); err != nil {
+		return err
+	}
+	return rp.egressLocalV2()
+}
+
+// serializePathV2 writes the path of the SCION layer to the packet.
+func (rp *RtrPkt) serializePathV2() error {
+	offset := rp.pathPointerV2()
+	return rp.v2.scion.Path.SerializeTo(rp.Raw[offset : int(offset)+rp.v2.scion.Path.Len()])
+}
+
+// pathPointerV2 returns the offset of the path in the packet.
+func (rp *RtrPkt) pathPointerV2() uint16 {
+	return uint16(slayers.CmnHdrLen + rp.v2.scion.AddrHdrLen())
+}
+
+// hopPointerV2 returns the offset of the current hop field in the packet.
+func (rp *RtrPkt) hopPointerV2() uint16 {
+	p := rp.v2.path
+	return rp.pathPointerV2() + uint16(scion.MetaLen+path.InfoLen*p.NumINF+
+		path.HopLen*int(p.PathMeta.CurrHF))
+}
+
+// dstAddrPointerV2 returns the offset of the destination host address in the
+// packet. It follows the destination and source ISD-AS in the address header.
+func (rp *RtrPkt) dstAddrPointerV2() uint16 {
+	return uint16(slayers.CmnHdrLen + 2*addr.IABytes)
+}
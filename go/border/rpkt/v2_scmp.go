@@ -0,0 +1,150 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file handles the creation of SCMP error messages for packets with the
+// SCION header v2.
+
+package rpkt
+
+import (
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+
+	"github.com/scionproto/scion/go/border/rcmn"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+)
+
+// maxSCMPPacketLenV2 is the maximum length of an SCMP error message. The
+// quoted packet is truncated to fit.
+const maxSCMPPacketLenV2 = 1232
+
+// CreateSCMPErrorReplyV2 creates an SCMP error message for the packet, which
+// is sent back to the source of the packet on the interface it was received
+// on. The message quotes the packet.
+func (rp *RtrPkt) CreateSCMPErrorReplyV2(serr *SCMPErrorV2) (*RtrPkt, error) {
+	var s slayers.SCION
+	if err := s.DecodeFromBytes(rp.Raw, gopacket.NilDecodeFeedback); err != nil {
+		return nil, serrors.WrapStr("decoding packet", err)
+	}
+	raw, ok := s.Path.(*scion.Raw)
+	if !ok {
+		return nil, serrors.New("unsupported path type", "type", s.PathType)
+	}
+	revPath, err := rp.replyPathV2(raw)
+	if err != nil {
+		return nil, err
+	}
+	src, err := s.SrcAddr()
+	if err != nil {
+		return nil, serrors.WrapStr("extracting source address", err)
+	}
+	reply := slayers.SCION{
+		Version:      s.Version,
+		TrafficClass: s.TrafficClass,
+		FlowID:       s.FlowID,
+		NextHdr:      common.L4SCMP,
+		PathType:     slayers.PathTypeSCION,
+		DstIA:        s.SrcIA,
+		SrcIA:        rp.Ctx.Conf.IA,
+		Path:         revPath,
+	}
+	if err := reply.SetDstAddr(src); err != nil {
+		return nil, serrors.WrapStr("setting destination address", err)
+	}
+	err = reply.SetSrcAddr(&net.IPAddr{IP: rp.Ctx.Conf.BR.InternalAddr.IP})
+	if err != nil {
+		return nil, serrors.WrapStr("setting source address", err)
+	}
+	scmpLayer := &slayers.SCMP{TypeCode: serr.TypeCode}
+	if err := scmpLayer.SetNetworkLayerForChecksum(&reply); err != nil {
+		return nil, err
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{ComputeChecksums: true, FixLengths: true}
+	// The quote is serialized first, to determine how much of the packet fits.
+	if err := serr.Info.SerializeTo(buf, opts); err != nil {
+		return nil, serrors.WrapStr("serializing SCMP info", err)
+	}
+	hdrLen := slayers.CmnHdrLen + reply.AddrHdrLen() + revPath.Len() + 4 + len(buf.Bytes())
+	quote := rp.Raw
+	if maxQuote := maxSCMPPacketLenV2 - hdrLen; len(quote) > maxQuote {
+		quote = quote[:maxQuote]
+	}
+	buf.Clear()
+	err = gopacket.SerializeLayers(buf, opts, &reply, scmpLayer, serr.Info,
+		gopacket.Payload(quote))
+	if err != nil {
+		return nil, serrors.WrapStr("serializing SCMP error", err)
+	}
+	r := NewRtrPkt()
+	r.Raw = r.Raw[:copy(r.Raw, buf.Bytes())]
+	r.Ctx = rp.Ctx
+	r.TimeIn = time.Now()
+	r.Id = log.NewDebugID().String()
+	r.Logger = log.New("rpkt", r.Id)
+	r.DirFrom = rcmn.DirSelf
+	r.Ingress.IfLabel = rp.Ingress.IfLabel
+	if rp.DirFrom == rcmn.DirExternal {
+		r.Egress = append(r.Egress, EgressPair{S: rp.Ctx.ExtSockOut[rp.Ingress.IfID]})
+	} else {
+		r.Egress = append(r.Egress, EgressPair{S: rp.Ctx.LocSockOut, Dst: rp.Ingress.Src})
+	}
+	return r, nil
+}
+
+// replyPathV2 returns the path for a reply to the packet. The path is the
+// reversed path of the packet, processed as if the reply had been received by
+// this router and leaves the AS on the interface the packet was received on.
+func (rp *RtrPkt) replyPathV2(raw *scion.Raw) (*scion.Decoded, error) {
+	// Copy the path, the buffer of the packet is quoted in the reply.
+	var p scion.Decoded
+	b := make([]byte, raw.Len())
+	if err := raw.SerializeTo(b); err != nil {
+		return nil, err
+	}
+	if err := p.DecodeFromBytes(b); err != nil {
+		return nil, serrors.WrapStr("decoding path", err)
+	}
+	if p.NumINF == 0 {
+		return &p, nil
+	}
+	if err := p.Reverse(); err != nil {
+		return nil, serrors.WrapStr("reversing path", err)
+	}
+	// Revert a segment change that this router did while processing the packet.
+	if rp.v2.xover {
+		if err := p.IncPath(); err != nil {
+			return nil, serrors.WrapStr("reverting segment change", err)
+		}
+	}
+	if rp.DirFrom != rcmn.DirExternal {
+		return &p, nil
+	}
+	// Leaving the AS in construction direction is the inverse of entering it
+	// against construction direction. If the SegID was updated at ingress, the
+	// update is reverted.
+	if rp.v2.segIDUpdated {
+		p.InfoFields[p.PathMeta.CurrINF].UpdateSegID(p.HopFields[p.PathMeta.CurrHF].Mac)
+	}
+	if err := p.IncPath(); err != nil {
+		return nil, serrors.WrapStr("incrementing path", err)
+	}
+	return &p, nil
+}
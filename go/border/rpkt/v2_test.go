@@ -0,0 +1,432 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpkt
+
+import (
+	"errors"
+	"hash"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/border/brconf"
+	"github.com/scionproto/scion/go/border/rcmn"
+	"github.com/scionproto/scion/go/border/rctx"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/keyconf"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	localIA  = xtest.MustParseIA("1-ff00:0:1")
+	localHst = &net.IPAddr{IP: net.ParseIP("192.168.0.51")}
+	childIA  = xtest.MustParseIA("1-ff00:0:4")
+	childHst = &net.IPAddr{IP: net.ParseIP("172.16.4.1")}
+	child2IA = xtest.MustParseIA("1-ff00:0:5")
+	parentIA = xtest.MustParseIA("2-ff00:0:3")
+	peerIA   = xtest.MustParseIA("1-ff00:0:2")
+)
+
+// newTestCtxV2 creates a router context for the router brA. The router has the
+// interfaces 121 (peer), 131 (parent), 141 (child) and 151 (child). The
+// interface 171 (peer) is on the router brB.
+func newTestCtxV2(t *testing.T) *rctx.Ctx {
+	rw := topology.NewRWTopology()
+	rw.IA = localIA
+	ifs := []topology.IFInfo{
+		{ID: 121, BRName: "brA", IA: peerIA, LinkType: topology.Peer},
+		{ID: 131, BRName: "brA", IA: parentIA, LinkType: topology.Parent},
+		{ID: 141, BRName: "brA", IA: childIA, LinkType: topology.Child},
+		{ID: 151, BRName: "brA", IA: child2IA, LinkType: topology.Child},
+		{ID: 171, BRName: "brB", IA: xtest.MustParseIA("2-ff00:0:7"), LinkType: topology.Peer},
+	}
+	brA := topology.BRInfo{
+		Name:         "brA",
+		InternalAddr: &net.UDPAddr{IP: net.ParseIP("192.168.0.11"), Port: 30001},
+		IFs:          make(map[common.IFIDType]*topology.IFInfo),
+	}
+	ctx := rctx.New(&brconf.BRConf{
+		IA:         localIA,
+		BR:         &brA,
+		MasterKeys: keyconf.Master{Key0: []byte("0123456789abcdef")},
+	})
+	for i := range ifs {
+		intf := ifs[i]
+		if intf.BRName == "brA" {
+			intf.InternalAddr = brA.InternalAddr
+			brA.IFs[intf.ID] = &intf
+			brA.IFIDs = append(brA.IFIDs, intf.ID)
+			ctx.ExtSockOut[intf.ID] = &rctx.Sock{Ifid: intf.ID}
+		} else {
+			intf.InternalAddr = &net.UDPAddr{IP: net.ParseIP("192.168.0.12"), Port: 30002}
+		}
+		rw.IFInfoMap[intf.ID] = intf
+	}
+	rw.BR["brA"] = brA
+	ctx.Conf.Topo = topology.FromRWTopology(rw)
+	ctx.LocSockOut = &rctx.Sock{}
+	require.NoError(t, ctx.InitMacPool())
+	return ctx
+}
+
+// testSegment is a path segment with the hop fields in the direction of travel.
+type testSegment struct {
+	info path.InfoField
+	hops []path.HopField
+}
+
+// newTestSegment creates a segment from the hop fields in construction
+// direction. The MACs are chained along construction direction, the SegID is
+// not updated with the MAC of a peering hop field. The SegID of the segment is
+// the one that the packet carries when it arrives at the hop field with index
+// curr in the direction of travel.
+func newTestSegment(mac hash.Hash, consDir, peer bool, curr int,
+	hops ...path.HopField) testSegment {
+
+	info := path.InfoField{
+		ConsDir:   consDir,
+		Peer:      peer,
+		Timestamp: uint32(time.Now().Unix()),
+	}
+	betas := make([]uint16, len(hops))
+	beta := uint16(0x1234)
+	for i := range hops {
+		betas[i] = beta
+		info.SegID = beta
+		hops[i].Mac = path.MAC(mac, &info, &hops[i])
+		if !(peer && i == 0) {
+			beta ^= uint16(hops[i].Mac[0])<<8 | uint16(hops[i].Mac[1])
+		}
+	}
+	if consDir {
+		info.SegID = betas[curr]
+		return testSegment{info: info, hops: hops}
+	}
+	cons := len(hops) - 1 - curr
+	if cons+1 < len(hops) {
+		cons++
+	}
+	info.SegID = betas[cons]
+	for i, j := 0, len(hops)-1; i < j; i, j = i+1, j-1 {
+		hops[i], hops[j] = hops[j], hops[i]
+	}
+	return testSegment{info: info, hops: hops}
+}
+
+// newTestPacketV2 creates a UDP packet with a path that is at the hop field
+// with index currHF. The packet is received on the interface ingress, or from
+// the local AS if ingress is 0.
+func newTestPacketV2(t *testing.T, ctx *rctx.Ctx, ingress common.IFIDType, src, dst addrV2,
+	currHF uint8, segs ...testSegment) *RtrPkt {
+
+	p := &scion.Decoded{}
+	for i, seg := range segs {
+		info := seg.info
+		p.InfoFields = append(p.InfoFields, &info)
+		for j := range seg.hops {
+			p.HopFields = append(p.HopFields, &seg.hops[j])
+		}
+		p.PathMeta.SegLen[i] = uint8(len(seg.hops))
+	}
+	p.NumINF = len(segs)
+	p.NumHops = len(p.HopFields)
+	p.PathMeta.CurrHF = currHF
+	for left := uint8(0); currHF >= left+p.PathMeta.SegLen[p.PathMeta.CurrINF]; {
+		left += p.PathMeta.SegLen[p.PathMeta.CurrINF]
+		p.PathMeta.CurrINF++
+	}
+	s := &slayers.SCION{
+		NextHdr:  common.L4UDP,
+		PathType: slayers.PathTypeSCION,
+		SrcIA:    src.IA,
+		DstIA:    dst.IA,
+		Path:     p,
+	}
+	require.NoError(t, s.SetSrcAddr(src.Host))
+	require.NoError(t, s.SetDstAddr(dst.Host))
+	udp := &slayers.UDP{}
+	udp.SrcPort, udp.DstPort = 40111, 40222
+	require.NoError(t, udp.SetNetworkLayerForChecksum(s))
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{ComputeChecksums: true, FixLengths: true}
+	require.NoError(t, gopacket.SerializeLayers(buf, opts, s, udp,
+		gopacket.Payload("hello")))
+
+	rp := NewRtrPkt()
+	rp.Raw = rp.Raw[:copy(rp.Raw, buf.Bytes())]
+	rp.Ctx = ctx
+	rp.DirFrom = rcmn.DirLocal
+	rp.Ingress.Src = &net.UDPAddr{IP: net.ParseIP("192.168.0.51"), Port: 30041}
+	if ingress != 0 {
+		rp.DirFrom = rcmn.DirExternal
+		rp.Ingress.IfID = ingress
+	}
+	return rp
+}
+
+// addrV2 is the address of a host.
+type addrV2 struct {
+	IA   addr.IA
+	Host net.Addr
+}
+
+// decodePathV2 decodes the path of the packet.
+func decodePathV2(t *testing.T, rp *RtrPkt) *scion.Decoded {
+	var s slayers.SCION
+	require.NoError(t, s.DecodeFromBytes(rp.Raw, gopacket.NilDecodeFeedback))
+	raw, ok := s.Path.(*scion.Raw)
+	require.True(t, ok)
+	p, err := raw.ToDecoded()
+	require.NoError(t, err)
+	return p
+}
+
+func TestProcessV2(t *testing.T) {
+	ctx := newTestCtxV2(t)
+	mac := ctx.HFMacPool.Get().(hash.Hash)
+	local := addrV2{IA: localIA, Host: localHst}
+	child := addrV2{IA: childIA, Host: childHst}
+	remote := addrV2{IA: parentIA, Host: childHst}
+	peer := addrV2{IA: peerIA, Host: childHst}
+	up := func(curr int, hops ...path.HopField) testSegment {
+		return newTestSegment(mac, false, false, curr, hops...)
+	}
+	down := func(curr int, hops ...path.HopField) testSegment {
+		return newTestSegment(mac, true, false, curr, hops...)
+	}
+
+	testCases := map[string]struct {
+		Packet  func() *RtrPkt
+		Egress  []EgressPair
+		CurrINF uint8
+		CurrHF  uint8
+		SCMP    slayers.SCMPTypeCode
+	}{
+		"child to local host": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 141, child, local, 1,
+					up(1, path.HopField{ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{
+				S:   ctx.LocSockOut,
+				Dst: &net.UDPAddr{IP: net.IP{192, 168, 0, 51}, Port: 30041},
+			}},
+			CurrHF: 1,
+		},
+		"local host to child": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 0, local, child, 0,
+					down(0, path.HopField{ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{S: ctx.ExtSockOut[141]}},
+			CurrHF: 1,
+		},
+		"child to parent": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 141, child, remote, 1,
+					up(1, path.HopField{ConsEgress: 311, ExpTime: 63},
+						path.HopField{ConsIngress: 131, ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{S: ctx.ExtSockOut[131]}},
+			CurrHF: 2,
+		},
+		"parent to child": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 131, remote, child, 1,
+					down(1, path.HopField{ConsEgress: 311, ExpTime: 63},
+						path.HopField{ConsIngress: 131, ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{S: ctx.ExtSockOut[141]}},
+			CurrHF: 2,
+		},
+		"xover child to child": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 141, child, addrV2{IA: child2IA, Host: childHst},
+					1,
+					up(1, path.HopField{ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}),
+					down(0, path.HopField{ConsEgress: 151, ExpTime: 63},
+						path.HopField{ConsIngress: 511, ExpTime: 63}))
+			},
+			Egress:  []EgressPair{{S: ctx.ExtSockOut[151]}},
+			CurrINF: 1,
+			CurrHF:  3,
+		},
+		"child to peer": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 141, child, peer, 1,
+					newTestSegment(mac, false, true, 1,
+						path.HopField{ConsIngress: 121, ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}),
+					newTestSegment(mac, true, true, 0,
+						path.HopField{ConsIngress: 211, ConsEgress: 0, ExpTime: 63}))
+			},
+			Egress:  []EgressPair{{S: ctx.ExtSockOut[121]}},
+			CurrINF: 1,
+			CurrHF:  2,
+		},
+		"child to remote peer": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 141, child, peer, 1,
+					newTestSegment(mac, false, true, 1,
+						path.HopField{ConsIngress: 171, ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}),
+					newTestSegment(mac, true, true, 0,
+						path.HopField{ConsIngress: 711, ConsEgress: 0, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{
+				S:   ctx.LocSockOut,
+				Dst: &net.UDPAddr{IP: net.ParseIP("192.168.0.12"), Port: 30002},
+			}},
+			CurrHF: 1,
+		},
+		"bad MAC": {
+			Packet: func() *RtrPkt {
+				seg := up(1, path.HopField{ConsEgress: 141, ExpTime: 63},
+					path.HopField{ConsIngress: 411, ExpTime: 63})
+				seg.hops[1].Mac = []byte{1, 2, 3, 4, 5, 6}
+				return newTestPacketV2(t, ctx, 141, child, local, 1, seg)
+			},
+			SCMP: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				slayers.SCMPCodeInvalidHopFieldMAC),
+		},
+		"expired hop field": {
+			Packet: func() *RtrPkt {
+				seg := up(1, path.HopField{ConsEgress: 141},
+					path.HopField{ConsIngress: 411})
+				seg.info.Timestamp -= 3600
+				return newTestPacketV2(t, ctx, 141, child, local, 1, seg)
+			},
+			SCMP: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				slayers.SCMPCodePathExpired),
+		},
+		"wrong ingress interface": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 151, child, local, 1,
+					up(1, path.HopField{ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}))
+			},
+			SCMP: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				slayers.SCMPCodeUnknownHopFieldInterface),
+		},
+		"xover parent to parent": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 131, remote, remote, 1,
+					up(1, path.HopField{ConsEgress: 131, ExpTime: 63},
+						path.HopField{ConsIngress: 311, ExpTime: 63}),
+					down(0, path.HopField{ConsEgress: 131, ExpTime: 63},
+						path.HopField{ConsIngress: 311, ExpTime: 63}))
+			},
+			SCMP: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+				slayers.SCMPCodeInvalidSegmentChange),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rp := tc.Packet()
+			require.NoError(t, rp.ParseV2())
+			err := rp.ProcessV2()
+			if tc.SCMP != 0 {
+				var serr *SCMPErrorV2
+				require.True(t, errors.As(err, &serr), "err: %v", err)
+				assert.Equal(t, tc.SCMP, serr.TypeCode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.Egress, rp.Egress)
+			p := decodePathV2(t, rp)
+			assert.Equal(t, tc.CurrINF, p.PathMeta.CurrINF)
+			assert.Equal(t, tc.CurrHF, p.PathMeta.CurrHF)
+		})
+	}
+}
+
+func TestCreateSCMPErrorReplyV2(t *testing.T) {
+	ctx := newTestCtxV2(t)
+	mac := ctx.HFMacPool.Get().(hash.Hash)
+	remote := addrV2{IA: parentIA, Host: childHst}
+	testCases := map[string]struct {
+		Packet func() *RtrPkt
+		Egress []EgressPair
+		CurrHF uint8
+	}{
+		"segment change": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 131, remote, remote, 1,
+					newTestSegment(mac, false, false, 1,
+						path.HopField{ConsEgress: 131, ExpTime: 63},
+						path.HopField{ConsIngress: 311, ExpTime: 63}),
+					newTestSegment(mac, true, false, 0,
+						path.HopField{ConsEgress: 131, ExpTime: 63},
+						path.HopField{ConsIngress: 311, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{S: ctx.ExtSockOut[131]}},
+			CurrHF: 3,
+		},
+		"wrong ingress interface": {
+			Packet: func() *RtrPkt {
+				return newTestPacketV2(t, ctx, 131, remote, remote, 1,
+					newTestSegment(mac, false, false, 1,
+						path.HopField{ConsEgress: 141, ExpTime: 63},
+						path.HopField{ConsIngress: 411, ExpTime: 63}),
+					newTestSegment(mac, true, false, 0,
+						path.HopField{ConsEgress: 131, ExpTime: 63},
+						path.HopField{ConsIngress: 311, ExpTime: 63}))
+			},
+			Egress: []EgressPair{{S: ctx.ExtSockOut[131]}},
+			CurrHF: 3,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rp := tc.Packet()
+			require.NoError(t, rp.ParseV2())
+			var serr *SCMPErrorV2
+			require.True(t, errors.As(rp.ProcessV2(), &serr))
+
+			reply, err := rp.CreateSCMPErrorReplyV2(serr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Egress, reply.Egress)
+			var s slayers.SCION
+			var scmp slayers.SCMP
+			require.NoError(t, s.DecodeFromBytes(reply.Raw, gopacket.NilDecodeFeedback))
+			require.NoError(t, scmp.DecodeFromBytes(s.Payload, gopacket.NilDecodeFeedback))
+			assert.Equal(t, remote.IA, s.DstIA)
+			assert.Equal(t, localIA, s.SrcIA)
+			assert.Equal(t, serr.TypeCode, scmp.TypeCode)
+			// The parameter problem info is followed by the quoted packet.
+			assert.Equal(t, []byte(rp.Raw), scmp.Payload[4:])
+
+			// The reply path must be valid at the next hop.
+			p := decodePathV2(t, reply)
+			assert.Equal(t, tc.CurrHF, p.PathMeta.CurrHF)
+			info := p.InfoFields[p.PathMeta.CurrINF]
+			assert.NoError(t, path.VerifyMAC(mac, info, p.HopFields[p.PathMeta.CurrHF]))
+		})
+	}
+}
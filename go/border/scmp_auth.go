@@ -0,0 +1,77 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the DRKey authentication of the SCMP errors originated
+// by the router.
+
+package main
+
+import (
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
+)
+
+// authSCMP adds the SCMPAuthDRKey extension to the SCMP error sp. The
+// authenticator is computed with the AS-to-host key from the local AS to the
+// destination host of the error. The epoch of the key is determined by the
+// timestamp of the SCMP header, such that the destination can fetch the same
+// key from its control service.
+func (r *Router) authSCMP(sp *spkt.ScnPkt) error {
+	hdr, ok := sp.L4.(*scmp.Hdr)
+	if !ok {
+		return common.NewBasicError("Not an SCMP header", nil, "type", common.TypeOf(sp.L4))
+	}
+	sv, err := r.scmpAuth.Get(hdr.Time())
+	if err != nil {
+		return err
+	}
+	lvl1, err := drkey.DeriveLvl1(sp.SrcIA, sp.DstIA, sv)
+	if err != nil {
+		return err
+	}
+	lvl2, err := drkey.DeriveLvl2(drkey.Lvl2Meta{
+		KeyType:  drkey.AS2Host,
+		Protocol: drkey.ProtocolSCMP,
+		SrcIA:    sp.SrcIA,
+		DstIA:    sp.DstIA,
+		DstHost:  sp.DstHost,
+	}, lvl1)
+	if err != nil {
+		return err
+	}
+	mac, err := scmp_auth.ComputeDRKeyMAC(common.RawBytes(lvl2.Key), sp.DstIA, sp.SrcIA,
+		sp.DstHost, sp.SrcHost, hdr, sp.Pld)
+	if err != nil {
+		return err
+	}
+	extn := scmp_auth.NewDRKeyExtn()
+	if err := extn.SetDirection(scmp_auth.AsToHost); err != nil {
+		return err
+	}
+	if err := extn.SetMAC(mac); err != nil {
+		return err
+	}
+	// Replace any packet security extension of the offending packet.
+	e2e := make([]common.Extension, 0, len(sp.E2EExt)+1)
+	for _, e := range sp.E2EExt {
+		if e.Type() != common.ExtnSCIONPacketSecurityType {
+			e2e = append(e2e, e)
+		}
+	}
+	sp.E2EExt = append(e2e, extn)
+	return nil
+}
@@ -58,6 +58,10 @@ func (r *Router) setup() error {
 	}, "free_pkts")
 	r.sRevInfoQ = make(chan rpkt.RawSRevCallbackArgs, 16)
 	r.pktErrorQ = make(chan pktErrorArgs, 16)
+	r.ifDownQ = make(chan common.IFIDType, 16)
+	if cfg.BR.BFD.Enabled {
+		r.bfdSessions = newBFDSessions(cfg.BR.BFD, r.ifDownQ)
+	}
 
 	// Configure the rpkt package with the callbacks it needs.
 	rpkt.Init(r.RawSRevCallback)
@@ -179,6 +183,9 @@ func (r *Router) setupNewContext(ctx *rctx.Ctx, tx *itopo.Transaction) error {
 	}
 	rctx.Set(ctx)
 	startSocks(ctx)
+	if r.bfdSessions != nil {
+		r.bfdSessions.update(ctx)
+	}
 	// Tear down sockets for removed interfaces
 	r.teardownNet(ctx, oldCtx, sockConf)
 	return nil
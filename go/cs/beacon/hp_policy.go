@@ -17,6 +17,7 @@ package beacon
 import (
 	"encoding/json"
 	"io/ioutil"
+	"sort"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -25,6 +26,10 @@ import (
 	"github.com/scionproto/scion/go/lib/util"
 )
 
+// HPDefaultActionRegister is the default action that registers segments from
+// interfaces without policy as public up- and down-segments.
+const HPDefaultActionRegister = "register"
+
 // HPGroup holds a hidden path group
 type HPGroup struct {
 	GroupCfgPath string `yaml:"CfgFilePath"`
@@ -77,6 +82,29 @@ func (hp *HPRegistration) Validate() error {
 	return nil
 }
 
+// DownRegistration returns whether a down-segment that enters the local AS
+// through the interface is registered publicly, and the hidden path groups it
+// is registered with. The groups are sorted by their ID. Segments from
+// interfaces without policy are only registered publicly if the default action
+// is to register them.
+func (hp *HPRegistration) DownRegistration(ifid common.IFIDType) (bool, []*hiddenpath.Group) {
+	p, ok := hp.HPPolicies.Policies[ifid]
+	if !ok {
+		return hp.HPPolicies.DefaultAction == HPDefaultActionRegister, nil
+	}
+	var groups []*hiddenpath.Group
+	for id, regPolicy := range p.Hidden {
+		if g, ok := hp.HPGroups[id]; ok && regPolicy.RegDown {
+			groups = append(groups, &g.Group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Id.String() < groups[j].Id.String()
+	})
+	public := p.Public.RegDown && (len(groups) == 0 || hp.HPPolicies.HiddenAndPublic)
+	return public, groups
+}
+
 // ParseHPRegYaml parses the registration policies in yaml format and performs validation.
 // Hidden path groups pointed to by config file paths are loaded.
 func ParseHPRegYaml(b common.RawBytes) (*HPRegistration, error) {
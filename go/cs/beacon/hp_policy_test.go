@@ -105,3 +105,54 @@ func TestHPRegistrationFromYaml(t *testing.T) {
 			`HPGroup key="ff00:0:110-69b5" loaded="ffaa:0:222-abcd"`)
 	})
 }
+
+func TestHPRegistrationDownRegistration(t *testing.T) {
+	r, err := beacon.LoadHPRegFromYaml("testdata/hp_policy.yml")
+	require.NoError(t, err)
+	tests := map[string]struct {
+		IfID            common.IFIDType
+		HiddenAndPublic bool
+		DefaultAction   string
+		ExpectedPublic  bool
+		ExpectedGroups  []hiddenpath.GroupId
+	}{
+		"hidden and public": {
+			IfID:            2,
+			HiddenAndPublic: true,
+			ExpectedPublic:  true,
+			ExpectedGroups:  []hiddenpath.GroupId{id69b5, idabcd},
+		},
+		"hidden only": {
+			IfID:           2,
+			ExpectedPublic: false,
+			ExpectedGroups: []hiddenpath.GroupId{id69b5, idabcd},
+		},
+		"public only": {
+			IfID:           3,
+			ExpectedPublic: true,
+		},
+		"default register": {
+			IfID:           4,
+			DefaultAction:  beacon.HPDefaultActionRegister,
+			ExpectedPublic: true,
+		},
+		"default reject": {
+			IfID:           4,
+			DefaultAction:  "reject",
+			ExpectedPublic: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r.HPPolicies.HiddenAndPublic = test.HiddenAndPublic
+			r.HPPolicies.DefaultAction = test.DefaultAction
+			public, groups := r.DownRegistration(test.IfID)
+			assert.Equal(t, test.ExpectedPublic, public)
+			var ids []hiddenpath.GroupId
+			for _, g := range groups {
+				ids = append(ids, g.Id)
+			}
+			assert.Equal(t, test.ExpectedGroups, ids)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/messenger"
 )
@@ -38,3 +39,17 @@ func (rpc RPC) RegisterSegment(ctx context.Context, meta seg.Meta, remote net.Ad
 	}
 	return rpc.Messenger.SendSegReg(ctx, req, remote, messenger.NextId())
 }
+
+// RegisterHiddenSegment registers a segment of the hidden path group with the
+// remote hidden path service.
+func (rpc RPC) RegisterHiddenSegment(ctx context.Context, group hiddenpath.GroupId,
+	meta seg.Meta, remote net.Addr) error {
+
+	req := &path_mgmt.HPSegReg{
+		HPSegRecs: &path_mgmt.HPSegRecs{
+			GroupId: group.ToMsg(),
+			Recs:    []*seg.Meta{&meta},
+		},
+	}
+	return rpc.Messenger.SendHPSegReg(ctx, req, remote, messenger.NextId())
+}
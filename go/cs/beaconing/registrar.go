@@ -27,9 +27,12 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
 	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/snet/addrutil"
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/proto"
@@ -51,11 +54,31 @@ type RPC interface {
 	RegisterSegment(ctx context.Context, meta seg.Meta, remote net.Addr) error
 }
 
+// HiddenRPC registers the path segment with the remote hidden path service.
+type HiddenRPC interface {
+	RegisterHiddenSegment(ctx context.Context, group hiddenpath.GroupId, meta seg.Meta,
+		remote net.Addr) error
+}
+
+// HiddenPathRegistration configures the registration of down segments with
+// hidden path services.
+type HiddenPathRegistration struct {
+	// Policy decides per ingress interface whether a down segment is
+	// registered publicly, and with which hidden path groups.
+	Policy *beacon.HPRegistration
+	// RPC registers the segments with the hidden path services.
+	RPC HiddenRPC
+	// Router provides the paths to hidden path registries in remote ASes.
+	Router snet.Router
+}
+
 var _ periodic.Task = (*Registrar)(nil)
 
 // Registrar is used to periodically register path segments with the appropriate
 // path servers. Core and Up segments are registered with the local path server.
-// Down segments are registered at the core.
+// Down segments are registered at the core. If hidden path registration is
+// configured, down segments are additionally registered with the registries of
+// the hidden path groups the policy assigns them to.
 type Registrar struct {
 	Extender Extender
 	Provider SegmentProvider
@@ -66,6 +89,9 @@ type Registrar struct {
 	Signer   ctrl.Signer
 	Intfs    *ifstate.Interfaces
 	Type     proto.PathSegType
+	// HiddenPaths is optional. If it is nil, all down segments are registered
+	// publicly.
+	HiddenPaths *HiddenPathRegistration
 
 	// tick is mutable.
 	Tick     Tick
@@ -131,17 +157,26 @@ func (r *Registrar) registerRemote(ctx context.Context, segments <-chan beacon.B
 				"beacon", bOrErr.Beacon, "err", err)
 			continue
 		}
-		expected++
-		s := remoteRegistrar{
-			segType: r.Type,
-			rpc:     r.RPC,
-			pather:  r.Pather,
-			summary: s,
-			wg:      &wg,
+		public, groups := true, []*hiddenpath.Group(nil)
+		if r.HiddenPaths != nil {
+			public, groups = r.HiddenPaths.Policy.DownRegistration(bOrErr.Beacon.InIfId)
 		}
+		if public {
+			expected++
+			rr := remoteRegistrar{
+				segType: r.Type,
+				rpc:     r.RPC,
+				pather:  r.Pather,
+				summary: s,
+				wg:      &wg,
+			}
 
-		// Avoid head-of-line blocking when sending message to slow servers.
-		s.start(ctx, bOrErr.Beacon)
+			// Avoid head-of-line blocking when sending message to slow servers.
+			rr.start(ctx, bOrErr.Beacon)
+		}
+		for _, group := range groups {
+			expected += r.registerHidden(ctx, bOrErr.Beacon, group, s, &wg)
+		}
 	}
 	wg.Wait()
 	if expected > 0 && s.count <= 0 {
@@ -154,6 +189,34 @@ func (r *Registrar) registerRemote(ctx context.Context, segments <-chan beacon.B
 	return nil
 }
 
+// registerHidden starts the registration of the beacon with all registries of
+// the hidden path group. It returns the number of registrations started.
+func (r *Registrar) registerHidden(ctx context.Context, bseg beacon.Beacon,
+	group *hiddenpath.Group, s *summary, wg *sync.WaitGroup) int {
+
+	logger := log.FromCtx(ctx)
+	labels := metrics.HiddenRegistrarLabels{Group: group.Id.String(), SegType: r.Type.String()}
+	if group.Owner != r.IA && !group.HasWriter(r.IA) {
+		metrics.Registrar.HiddenRegistrations(labels.WithResult(metrics.ErrInternal)).Inc()
+		logger.Error("[beaconing.Registrar] Local AS is not a writer of hidden path group",
+			"group", group.Id)
+		return 0
+	}
+	hr := hiddenRegistrar{
+		segType: r.Type,
+		localIA: r.IA,
+		group:   group.Id,
+		rpc:     r.HiddenPaths.RPC,
+		router:  r.HiddenPaths.Router,
+		summary: s,
+		wg:      wg,
+	}
+	for _, registry := range group.Registries {
+		hr.start(ctx, bseg, registry)
+	}
+	return len(group.Registries)
+}
+
 func (r *Registrar) registerLocal(ctx context.Context, segments <-chan beacon.BeaconOrErr,
 	peers []common.IFIDType) error {
 
@@ -259,6 +322,73 @@ func (r *remoteRegistrar) startSendSegReg(ctx context.Context, bseg beacon.Beaco
 	}()
 }
 
+// hiddenRegistrar registers segments with the registries of one hidden path
+// group.
+type hiddenRegistrar struct {
+	segType proto.PathSegType
+	localIA addr.IA
+	group   hiddenpath.GroupId
+	rpc     HiddenRPC
+	router  snet.Router
+	summary *summary
+	wg      *sync.WaitGroup
+}
+
+// start starts a goroutine that registers the beacon with the hidden path
+// service in the registry AS.
+func (r *hiddenRegistrar) start(ctx context.Context, bseg beacon.Beacon, registry addr.IA) {
+	r.wg.Add(1)
+	go func() {
+		defer log.HandlePanic()
+		defer r.wg.Done()
+		logger := log.FromCtx(ctx)
+		labels := metrics.HiddenRegistrarLabels{Group: r.group.String(),
+			SegType: r.segType.String()}
+		remote, err := r.resolve(ctx, registry)
+		if err != nil {
+			logger.Error("[beaconing.Registrar] Unable to find path to hidden path registry",
+				"group", r.group, "registry", registry, "err", err)
+			metrics.Registrar.HiddenRegistrations(labels.WithResult(metrics.ErrNoPath)).Inc()
+			return
+		}
+		reg := seg.Meta{Type: r.segType, Segment: bseg.Segment}
+		if err := r.rpc.RegisterHiddenSegment(ctx, r.group, reg, remote); err != nil {
+			logger.Error("[beaconing.Registrar] Unable to register hidden segment",
+				"group", r.group, "addr", remote, "err", err)
+			metrics.Registrar.HiddenRegistrations(labels.WithResult(metrics.ErrSend)).Inc()
+			return
+		}
+		r.summary.AddSrc(bseg.Segment.FirstIA())
+		r.summary.Inc()
+		metrics.Registrar.HiddenRegistrations(labels.WithResult(metrics.Success)).Inc()
+		logger.Debug("[beaconing.Registrar] Successfully registered hidden segment",
+			"group", r.group, "addr", remote, "seg", bseg.Segment)
+	}()
+}
+
+// resolve returns the address of the hidden path service in the registry AS.
+func (r *hiddenRegistrar) resolve(ctx context.Context, registry addr.IA) (net.Addr, error) {
+	if registry.Equal(r.localIA) {
+		return &snet.SVCAddr{IA: registry, SVC: addr.SvcHPS}, nil
+	}
+	if r.router == nil {
+		return nil, serrors.New("no router configured")
+	}
+	path, err := r.router.Route(ctx, registry)
+	if err != nil {
+		return nil, err
+	}
+	if path == nil {
+		return nil, serrors.New("no path found")
+	}
+	return &snet.SVCAddr{
+		IA:      registry,
+		Path:    path.Path(),
+		NextHop: path.UnderlayNextHop(),
+		SVC:     addr.SvcHPS,
+	}, nil
+}
+
 func updateMetricsFromStat(s seghandler.SegStats, b map[string]beacon.Beacon, segType string) {
 	for _, id := range s.InsertedSegs {
 		metrics.Registrar.Beacons(metrics.RegistrarLabels{
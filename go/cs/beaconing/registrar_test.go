@@ -39,12 +39,15 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
 	"github.com/scionproto/scion/go/lib/infra/modules/itopo/itopotest"
 	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
 	"github.com/scionproto/scion/go/lib/scrypto"
 	"github.com/scionproto/scion/go/lib/scrypto/cppki"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+	"github.com/scionproto/scion/go/lib/xtest"
 	"github.com/scionproto/scion/go/lib/xtest/graph"
 	"github.com/scionproto/scion/go/pkg/trust"
 	"github.com/scionproto/scion/go/proto"
@@ -372,3 +375,108 @@ func testSigner(t *testing.T, priv crypto.Signer, ia addr.IA) ctrl.Signer {
 		Expiration:   time.Now().Add(time.Hour),
 	}
 }
+
+func TestRegistrarRunHidden(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	topoProvider := itopotest.TopoProviderFromFile(t, topoNonCore)
+	intfs := ifstate.NewInterfaces(topoProvider.Get().IFInfoMap(), ifstate.Config{})
+	for _, intf := range intfs.All() {
+		intf.Activate(42)
+	}
+	localIA := topoProvider.Get().IA()
+	remoteIA := xtest.MustParseIA("1-ff00:0:112")
+	writable := hiddenpath.Group{
+		Id:         hiddenpath.GroupId{OwnerAS: localIA.A, Suffix: 1},
+		Owner:      localIA,
+		Writers:    []addr.IA{localIA},
+		Registries: []addr.IA{localIA, remoteIA},
+	}
+	foreign := hiddenpath.Group{
+		Id:         hiddenpath.GroupId{OwnerAS: remoteIA.A, Suffix: 2},
+		Owner:      remoteIA,
+		Writers:    []addr.IA{remoteIA},
+		Registries: []addr.IA{remoteIA},
+	}
+	policy := &beacon.HPRegistration{
+		HPPolicies: beacon.HPPolicies{
+			HiddenAndPublic: true,
+			Policies: map[common.IFIDType]beacon.HPPolicy{
+				graph.If_111_B_120_X: {
+					Public: beacon.RegPolicy{RegDown: true},
+					Hidden: map[hiddenpath.GroupId]beacon.RegPolicy{
+						writable.Id: {RegDown: true},
+						foreign.Id:  {RegDown: true},
+					},
+				},
+			},
+		},
+		HPGroups: map[hiddenpath.GroupId]*beacon.HPGroup{
+			writable.Id: {Group: writable},
+			foreign.Id:  {Group: foreign},
+		},
+	}
+
+	segProvider := mock_beaconing.NewMockSegmentProvider(mctrl)
+	extender := mock_beaconing.NewMockExtender(mctrl)
+	rpc := mock_beaconing.NewMockRPC(mctrl)
+	hiddenRPC := mock_beaconing.NewMockHiddenRPC(mctrl)
+	router := mock_snet.NewMockRouter(mctrl)
+	path := mock_snet.NewMockPath(mctrl)
+	r := Registrar{
+		Extender: extender,
+		IA:       localIA,
+		Intfs:    intfs,
+		Tick:     NewTick(time.Hour),
+		Provider: segProvider,
+		Pather:   addrutil.LegacyPather{TopoProvider: topoProvider},
+		Type:     proto.PathSegType_down,
+		RPC:      rpc,
+		HiddenPaths: &HiddenPathRegistration{
+			Policy: policy,
+			RPC:    hiddenRPC,
+			Router: router,
+		},
+	}
+	g := graph.NewDefaultGraph(mctrl)
+	segProvider.EXPECT().SegmentsToRegister(gomock.Any(), proto.PathSegType_down).DoAndReturn(
+		func(_, _ interface{}) (<-chan beacon.BeaconOrErr, error) {
+			// The extender is mocked, use the segment that is already
+			// terminated in the local AS.
+			res := make(chan beacon.BeaconOrErr, 1)
+			res <- beacon.BeaconOrErr{
+				Beacon: beacon.Beacon{
+					InIfId:  graph.If_111_B_120_X,
+					Segment: g.Beacon([]common.IFIDType{graph.If_120_X_111_B}),
+				},
+			}
+			close(res)
+			return res, nil
+		})
+	extender.EXPECT().Extend(gomock.Any(), gomock.Any(), graph.If_111_B_120_X,
+		common.IFIDType(0), gomock.Any())
+	rpc.EXPECT().RegisterSegment(gomock.Any(), gomock.Any(), gomock.Any())
+
+	nextHop := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}
+	router.EXPECT().Route(gomock.Any(), remoteIA).Return(path, nil)
+	path.EXPECT().Path().Return(nil)
+	path.EXPECT().UnderlayNextHop().Return(nextHop)
+
+	var mu sync.Mutex
+	var registries []*snet.SVCAddr
+	hiddenRPC.EXPECT().RegisterHiddenSegment(gomock.Any(), writable.Id, gomock.Any(),
+		gomock.Any()).Times(2).DoAndReturn(
+		func(_ context.Context, _ hiddenpath.GroupId, meta seg.Meta, remote net.Addr) error {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, proto.PathSegType_down, meta.Type)
+			registries = append(registries, remote.(*snet.SVCAddr))
+			return nil
+		},
+	)
+	r.Run(context.Background())
+	assert.ElementsMatch(t, []*snet.SVCAddr{
+		{IA: localIA, SVC: addr.SvcHPS},
+		{IA: remoteIA, NextHop: nextHop, SVC: addr.SvcHPS},
+	}, registries)
+}
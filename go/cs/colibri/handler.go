@@ -0,0 +1,576 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package colibri implements the COLIBRI service running in the control service.
+//
+// COLIBRI messages travel hop by hop along the reservation path. Every AS processes the message
+// with its reservation store and then forwards it to the control service of the next AS. Requests
+// travel in the direction of the reservation, and the last AS answers them with a response that
+// travels back to the source of the reservation.
+package colibri
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/cs/reservation/e2e"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservationstorage"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// Sender sends COLIBRI messages to the control service of another AS.
+type Sender interface {
+	SendColibriRequest(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+		a net.Addr, id uint64) error
+	SendColibriResponse(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+		a net.Addr, id uint64) error
+}
+
+// Resolver resolves the address of the control service of a neighboring AS.
+type Resolver interface {
+	// Resolve returns the address of the control service in AS ia, reachable through the local
+	// interface ifid.
+	Resolve(ia addr.IA, ifid common.IFIDType) (net.Addr, error)
+}
+
+// Handler handles COLIBRI requests and responses.
+type Handler struct {
+	// IA is the ISD-AS of the local AS.
+	IA addr.IA
+	// Store is the reservation store that processes the messages.
+	Store reservationstorage.Store
+	// Sender forwards the messages to the next AS.
+	Sender Sender
+	// Resolver resolves the address of the next AS.
+	Resolver Resolver
+}
+
+// Handle handles a COLIBRI request or response. The message is acknowledged once processed
+// locally, and then forwarded to the next AS, if any.
+func (h *Handler) Handle(request *infra.Request) *infra.HandlerResult {
+	ctx := request.Context()
+	logger := log.FromCtx(ctx)
+	msg, ok := request.Message.(*colibri_mgmt.ColibriRequestPayload)
+	if !ok {
+		logger.Error("[colibri.Handler] wrong message type, expected ColibriRequestPayload",
+			"msg", request.Message, "type", common.TypeOf(request.Message))
+		return infra.MetricsErrInternal
+	}
+	rw, ok := infra.ResponseWriterFromContext(ctx)
+	if !ok {
+		logger.Error("[colibri.Handler] Unable to service request, no ResponseWriter found")
+		return infra.MetricsErrInternal
+	}
+	sendAck := messenger.SendAckHelper(ctx, rw)
+	hbh, err := NewHopByHopFromCtrlMsg(msg.HopByHop)
+	if err != nil {
+		logger.Error("[colibri.Handler] Failed to parse message", "err", err)
+		sendAck(proto.Ack_ErrCode_reject, messenger.AckRejectFailedToParse)
+		return infra.MetricsErrInvalid
+	}
+	if !hbh.Current().IA.Equal(h.IA) {
+		logger.Error("[colibri.Handler] Message not meant for this AS",
+			"expected", hbh.Current().IA, "local", h.IA)
+		sendAck(proto.Ack_ErrCode_reject, "message not meant for this AS")
+		return infra.MetricsErrInvalid
+	}
+	var next *colibri_mgmt.ColibriRequestPayload
+	switch msg.Which {
+	case proto.ColibriRequestPayload_Which_request:
+		next, err = h.ProcessRequest(ctx, msg, hbh, peerPath(request.Peer))
+	case proto.ColibriRequestPayload_Which_response:
+		next, err = h.processResponse(ctx, msg, hbh)
+	default:
+		err = serrors.New("unsupported COLIBRI message", "which", msg.Which)
+	}
+	if err != nil {
+		logger.Error("[colibri.Handler] Failed to process message", "err", err)
+		sendAck(proto.Ack_ErrCode_reject, err.Error())
+		return infra.MetricsErrInvalid
+	}
+	sendAck(proto.Ack_ErrCode_ok, "")
+	if next == nil {
+		return infra.MetricsResultOk
+	}
+	if err := h.Forward(ctx, next); err != nil {
+		logger.Error("[colibri.Handler] Failed to forward message", "err", err)
+		return infra.MetricsErrInternal
+	}
+	return infra.MetricsResultOk
+}
+
+// ProcessRequest processes a COLIBRI request in the local AS. It returns the message that has to
+// be forwarded next: the request itself if the AS is not the last one in the reservation path,
+// or the response to the request otherwise. A request not admitted in this AS is answered with
+// a failure response. The path is the one the request arrived with, or nil if the request
+// originates in this AS.
+func (h *Handler) ProcessRequest(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+	hbh *HopByHop, path *spath.Path) (*colibri_mgmt.ColibriRequestPayload, error) {
+
+	req := msg.Request
+	if req == nil {
+		return nil, serrors.New("missing COLIBRI request")
+	}
+	if path == nil {
+		path = &spath.Path{}
+	}
+	ts := time.Unix(int64(msg.Timestamp), 0)
+	switch req.Which {
+	case proto.Request_Which_segmentSetup,
+		proto.Request_Which_segmentRenewal,
+		proto.Request_Which_segmentTelesSetup,
+		proto.Request_Which_segmentTelesRenewal:
+
+		return h.processSegmentSetup(ctx, msg, hbh, path)
+	case proto.Request_Which_segmentTeardown:
+		if err := checkSegmentID(hbh); err != nil {
+			return nil, err
+		}
+		err := h.Store.TearDownSegmentReservation(ctx, hbh.ID, hbh.InfoField.Idx)
+		if err != nil {
+			return failureResponse(msg, hbh, &colibri_mgmt.Response{
+				Which:           proto.Response_Which_segmentTeardown,
+				SegmentTeardown: &colibri_mgmt.SegmentTeardownRes{ErrorCode: 1},
+			}, err)
+		}
+		return forwardOrRespond(msg, hbh, &colibri_mgmt.Response{
+			Which:           proto.Response_Which_segmentTeardown,
+			SegmentTeardown: &colibri_mgmt.SegmentTeardownRes{},
+		}), nil
+	case proto.Request_Which_segmentIndexConfirmation:
+		if err := checkSegmentID(hbh); err != nil {
+			return nil, err
+		}
+		conf := req.SegmentIndexConfirmation
+		if conf == nil {
+			return nil, serrors.New("missing index confirmation")
+		}
+		var err error
+		switch conf.State {
+		case proto.ReservationIndexState_pending:
+			err = h.Store.ConfirmSegmentReservation(ctx, hbh.ID,
+				reservation.IndexNumber(conf.Index))
+		case proto.ReservationIndexState_active:
+			err = h.Store.ActivateSegmentReservation(ctx, hbh.ID,
+				reservation.IndexNumber(conf.Index))
+		default:
+			return nil, serrors.New("unsupported index state in confirmation", "state", conf.State)
+		}
+		res := &colibri_mgmt.Response{
+			Which:                    proto.Response_Which_segmentIndexConfirmation,
+			SegmentIndexConfirmation: conf,
+		}
+		if err != nil {
+			return failureResponse(msg, hbh, res, err)
+		}
+		return forwardOrRespond(msg, hbh, res), nil
+	case proto.Request_Which_segmentCleanup:
+		cleanup := req.SegmentCleanup
+		if cleanup == nil || cleanup.ID == nil {
+			return nil, serrors.New("missing segment cleanup")
+		}
+		id, err := reservation.SegmentIDFromRawBuffers(cleanup.ID.ASID, cleanup.ID.Suffix)
+		if err != nil {
+			return nil, serrors.WrapStr("parsing segment reservation ID", err)
+		}
+		err = h.Store.CleanupSegmentReservation(ctx, *id, reservation.IndexNumber(cleanup.Index))
+		res := &colibri_mgmt.Response{
+			Which:          proto.Response_Which_segmentCleanup,
+			SegmentCleanup: cleanup,
+		}
+		if err != nil {
+			return failureResponse(msg, hbh, res, err)
+		}
+		return forwardOrRespond(msg, hbh, res), nil
+	case proto.Request_Which_e2eSetup, proto.Request_Which_e2eRenewal:
+		return h.processE2ESetup(ctx, msg, hbh, ts, path)
+	case proto.Request_Which_e2eCleanup:
+		cleanup := req.E2ECleanup
+		if cleanup == nil || cleanup.ReservationID == nil {
+			return nil, serrors.New("missing e2e cleanup")
+		}
+		id, err := reservation.E2EIDFromRawBuffers(cleanup.ReservationID.ASID,
+			cleanup.ReservationID.Suffix)
+		if err != nil {
+			return nil, serrors.WrapStr("parsing e2e reservation ID", err)
+		}
+		err = h.Store.CleanupE2EReservation(ctx, *id, hbh.InfoField.Idx)
+		res := &colibri_mgmt.Response{
+			Which:      proto.Response_Which_e2eCleanup,
+			E2ECleanup: cleanup,
+		}
+		if err != nil {
+			return failureResponse(msg, hbh, res, err)
+		}
+		return forwardOrRespond(msg, hbh, res), nil
+	default:
+		return nil, serrors.New("unsupported COLIBRI request", "which", req.Which)
+	}
+}
+
+func (h *Handler) processSegmentSetup(ctx context.Context,
+	msg *colibri_mgmt.ColibriRequestPayload, hbh *HopByHop,
+	path *spath.Path) (*colibri_mgmt.ColibriRequestPayload, error) {
+
+	if err := checkSegmentID(hbh); err != nil {
+		return nil, err
+	}
+	setup, err := segmentSetup(msg.Request)
+	if err != nil {
+		return nil, err
+	}
+	req := segment.NewSetupReq(&segment.Request{
+		ID:        hbh.ID,
+		Timestamp: time.Unix(int64(msg.Timestamp), 0),
+		Ingress:   hbh.Current().Ingress,
+		Egress:    hbh.Current().Egress,
+	}, setup)
+	req.Metadata.Path = *path
+	req.InfoField = hbh.InfoField
+	if err := h.Store.AdmitSegmentReservation(ctx, req); err != nil {
+		log.FromCtx(ctx).Info("[colibri.Handler] Segment reservation not admitted",
+			"id", hbh.ID, "idx", hbh.InfoField.Idx, "err", err)
+		return failureResponse(msg, hbh, segmentSetupResponse(msg.Request.Which,
+			&colibri_mgmt.SegmentSetupRes{
+				Which:   proto.SegmentSetupResData_Which_failure,
+				Failure: req.ToCtrlMsg(),
+			}), err)
+	}
+	if !hbh.IsLast() {
+		fwd := *msg
+		fwd.Request = withSegmentSetup(msg.Request, req.ToCtrlMsg())
+		fwd.HopByHop = hbh.ToCtrlMsg(hbh.Step + 1)
+		return &fwd, nil
+	}
+	// the reservation is granted with the minimum allocated bandwidth along the path.
+	tok := reservation.Token{InfoField: hbh.InfoField}
+	tok.BWCls = reservation.BWCls(req.MaxBW)
+	for _, bead := range req.AllocTrail {
+		if reservation.BWCls(bead.AllocBW) < tok.BWCls {
+			tok.BWCls = reservation.BWCls(bead.AllocBW)
+		}
+	}
+	return response(msg, hbh, segmentSetupResponse(msg.Request.Which,
+		&colibri_mgmt.SegmentSetupRes{
+			Which: proto.SegmentSetupResData_Which_token,
+			Token: tok.ToRaw(),
+		}), true), nil
+}
+
+func (h *Handler) processE2ESetup(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+	hbh *HopByHop, ts time.Time, path *spath.Path) (*colibri_mgmt.ColibriRequestPayload, error) {
+
+	setup := msg.Request.E2ESetup
+	which := proto.Response_Which_e2eSetup
+	if msg.Request.Which == proto.Request_Which_e2eRenewal {
+		setup = msg.Request.E2ERenewal
+		which = proto.Response_Which_e2eRenewal
+	}
+	if setup == nil {
+		return nil, serrors.New("missing e2e setup")
+	}
+	req, err := e2e.NewRequestFromCtrlMsg(setup, ts, path)
+	if err != nil {
+		return nil, serrors.WrapStr("parsing e2e setup", err)
+	}
+	res := &colibri_mgmt.Response{Which: which}
+	if _, ok := req.(*e2e.FailureSetupReq); ok {
+		// the setup already failed, there is nothing to admit in this AS.
+		setE2ESetup(res, setup)
+		return failureResponse(msg, hbh, res, serrors.New("e2e setup already failed",
+			"id", setup.ReservationID))
+	}
+	if success, ok := req.(*e2e.SuccessSetupReq); ok {
+		success.Ingress = hbh.Current().Ingress
+		success.Egress = hbh.Current().Egress
+	}
+	if err := h.Store.AdmitE2EReservation(ctx, req); err != nil {
+		log.FromCtx(ctx).Info("[colibri.Handler] E2E reservation not admitted",
+			"id", setup.ReservationID, "err", err)
+		inf := make([]byte, reservation.InfoFieldLen)
+		hbh.InfoField.Read(inf)
+		setE2ESetup(res, &colibri_mgmt.E2ESetup{
+			ReservationID: setup.ReservationID,
+			Which:         proto.E2ESetupData_Which_failure,
+			Failure: &colibri_mgmt.E2ESetupFailure{
+				ErrorCode: 1,
+				InfoField: inf,
+			},
+		})
+		return failureResponse(msg, hbh, res, err)
+	}
+	setE2ESetup(res, setup)
+	return forwardOrRespond(msg, hbh, res), nil
+}
+
+// processResponse processes a COLIBRI response in the local AS. Indices of failed setups are
+// removed if they were admitted here. It returns the response to be forwarded to the previous
+// AS. If this AS is the source of the reservation, the index of an accepted segment setup is
+// confirmed, and the confirmation request is returned instead.
+func (h *Handler) processResponse(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+	hbh *HopByHop) (*colibri_mgmt.ColibriRequestPayload, error) {
+
+	res := msg.Response
+	if res == nil {
+		return nil, serrors.New("missing COLIBRI response")
+	}
+	// only the ASes before the failing one admitted the request.
+	if !res.Accepted && int(res.FailedHop) > hbh.Step {
+		var err error
+		switch {
+		case isSegmentSetup(res.Which):
+			err = h.Store.CleanupSegmentReservation(ctx, hbh.ID, hbh.InfoField.Idx)
+		case res.Which == proto.Response_Which_e2eSetup ||
+			res.Which == proto.Response_Which_e2eRenewal:
+			setup := res.E2ESetup
+			if res.Which == proto.Response_Which_e2eRenewal {
+				setup = res.E2ERenewal
+			}
+			if setup == nil || setup.ReservationID == nil {
+				return nil, serrors.New("missing e2e setup")
+			}
+			var id *reservation.E2EID
+			id, err = reservation.E2EIDFromRawBuffers(setup.ReservationID.ASID,
+				setup.ReservationID.Suffix)
+			if err == nil {
+				err = h.Store.CleanupE2EReservation(ctx, *id, hbh.InfoField.Idx)
+			}
+		}
+		if err != nil {
+			log.FromCtx(ctx).Info("[colibri.Handler] Failed to clean up failed reservation",
+				"err", err)
+		}
+	}
+	if hbh.IsFirst() {
+		log.FromCtx(ctx).Debug("[colibri.Handler] COLIBRI request finished",
+			"which", res.Which, "accepted", res.Accepted, "failed_hop", res.FailedHop)
+		if res.Accepted && isSegmentSetup(res.Which) {
+			return h.confirmIndex(ctx, msg, hbh)
+		}
+		return nil, nil
+	}
+	fwd := *msg
+	fwd.HopByHop = hbh.ToCtrlMsg(hbh.Step - 1)
+	return &fwd, nil
+}
+
+// confirmIndex confirms the index of the reservation in the source AS, and returns the index
+// confirmation request for the rest of the path.
+func (h *Handler) confirmIndex(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+	hbh *HopByHop) (*colibri_mgmt.ColibriRequestPayload, error) {
+
+	if err := h.Store.ConfirmSegmentReservation(ctx, hbh.ID, hbh.InfoField.Idx); err != nil {
+		return nil, serrors.WrapStr("confirming index", err, "id", hbh.ID,
+			"idx", hbh.InfoField.Idx)
+	}
+	return &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: msg.Timestamp,
+		Which:     proto.ColibriRequestPayload_Which_request,
+		Request: &colibri_mgmt.Request{
+			Which: proto.Request_Which_segmentIndexConfirmation,
+			SegmentIndexConfirmation: &colibri_mgmt.SegmentIndexConfirmation{
+				Index: uint8(hbh.InfoField.Idx),
+				State: proto.ReservationIndexState_pending,
+			},
+		},
+		HopByHop: hbh.ToCtrlMsg(hbh.Step + 1),
+	}, nil
+}
+
+// Forward sends the message to the next AS. Requests are sent to the next AS in the reservation
+// path, responses to the previous one.
+func (h *Handler) Forward(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload) error {
+	hbh, err := NewHopByHopFromCtrlMsg(msg.HopByHop)
+	if err != nil {
+		return err
+	}
+	// the next step is the one the message is sent to.
+	var ifid common.IFIDType
+	send := h.Sender.SendColibriRequest
+	switch msg.Which {
+	case proto.ColibriRequestPayload_Which_request:
+		if hbh.IsFirst() {
+			return serrors.New("cannot forward request to the source AS")
+		}
+		ifid = hbh.Path[hbh.Step-1].Egress
+	case proto.ColibriRequestPayload_Which_response:
+		if hbh.IsLast() {
+			return serrors.New("cannot forward response to the destination AS")
+		}
+		ifid = hbh.Path[hbh.Step+1].Ingress
+		send = h.Sender.SendColibriResponse
+	default:
+		return serrors.New("unsupported COLIBRI message", "which", msg.Which)
+	}
+	a, err := h.Resolver.Resolve(hbh.Current().IA, ifid)
+	if err != nil {
+		return serrors.WrapStr("resolving next AS", err, "ia", hbh.Current().IA, "ifid", ifid)
+	}
+	return send(ctx, msg, a, messenger.NextId())
+}
+
+// forwardOrRespond returns the request to be forwarded to the next AS, or the successful
+// response if this AS is the last one.
+func forwardOrRespond(msg *colibri_mgmt.ColibriRequestPayload, hbh *HopByHop,
+	res *colibri_mgmt.Response) *colibri_mgmt.ColibriRequestPayload {
+
+	if !hbh.IsLast() {
+		fwd := *msg
+		fwd.HopByHop = hbh.ToCtrlMsg(hbh.Step + 1)
+		return &fwd
+	}
+	return response(msg, hbh, res, true)
+}
+
+// failureResponse returns the response indicating that this AS failed the request with err.
+// If this AS is the source of the reservation, there is no one to respond to and err is returned.
+func failureResponse(msg *colibri_mgmt.ColibriRequestPayload, hbh *HopByHop,
+	res *colibri_mgmt.Response, err error) (*colibri_mgmt.ColibriRequestPayload, error) {
+
+	if hbh.IsFirst() {
+		return nil, err
+	}
+	res.FailedHop = uint8(hbh.Step)
+	return response(msg, hbh, res, false), nil
+}
+
+// response wraps res to be sent to the previous AS.
+func response(msg *colibri_mgmt.ColibriRequestPayload, hbh *HopByHop,
+	res *colibri_mgmt.Response, accepted bool) *colibri_mgmt.ColibriRequestPayload {
+
+	if hbh.IsFirst() {
+		return nil
+	}
+	res.Accepted = accepted
+	return &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: msg.Timestamp,
+		Which:     proto.ColibriRequestPayload_Which_response,
+		Response:  res,
+		HopByHop:  hbh.ToCtrlMsg(hbh.Step - 1),
+	}
+}
+
+func checkSegmentID(hbh *HopByHop) error {
+	if hbh.ID.ASID == 0 {
+		return serrors.New("missing segment reservation ID")
+	}
+	return nil
+}
+
+func isSegmentSetup(which proto.Response_Which) bool {
+	switch which {
+	case proto.Response_Which_segmentSetup,
+		proto.Response_Which_segmentRenewal,
+		proto.Response_Which_segmentTelesSetup,
+		proto.Response_Which_segmentTelesRenewal:
+
+		return true
+	}
+	return false
+}
+
+// segmentSetup returns the setup contained in any kind of segment setup request.
+func segmentSetup(req *colibri_mgmt.Request) (*colibri_mgmt.SegmentSetup, error) {
+	var setup *colibri_mgmt.SegmentSetup
+	switch req.Which {
+	case proto.Request_Which_segmentSetup:
+		setup = req.SegmentSetup
+	case proto.Request_Which_segmentRenewal:
+		setup = req.SegmentRenewal
+	case proto.Request_Which_segmentTelesSetup:
+		if req.SegmentTelesSetup != nil {
+			setup = req.SegmentTelesSetup.Setup
+		}
+	case proto.Request_Which_segmentTelesRenewal:
+		if req.SegmentTelesRenewal != nil {
+			setup = req.SegmentTelesRenewal.Setup
+		}
+	}
+	if setup == nil {
+		return nil, serrors.New("missing segment setup", "which", req.Which)
+	}
+	return setup, nil
+}
+
+// withSegmentSetup returns a copy of the segment setup request, containing setup.
+func withSegmentSetup(req *colibri_mgmt.Request,
+	setup *colibri_mgmt.SegmentSetup) *colibri_mgmt.Request {
+
+	r := *req
+	switch r.Which {
+	case proto.Request_Which_segmentSetup:
+		r.SegmentSetup = setup
+	case proto.Request_Which_segmentRenewal:
+		r.SegmentRenewal = setup
+	case proto.Request_Which_segmentTelesSetup:
+		teles := *r.SegmentTelesSetup
+		teles.Setup = setup
+		r.SegmentTelesSetup = &teles
+	case proto.Request_Which_segmentTelesRenewal:
+		teles := *r.SegmentTelesRenewal
+		teles.Setup = setup
+		r.SegmentTelesRenewal = &teles
+	}
+	return &r
+}
+
+// segmentSetupResponse returns the response to a segment setup request of the given kind.
+func segmentSetupResponse(which proto.Request_Which,
+	setupRes *colibri_mgmt.SegmentSetupRes) *colibri_mgmt.Response {
+
+	res := &colibri_mgmt.Response{}
+	switch which {
+	case proto.Request_Which_segmentSetup:
+		res.Which = proto.Response_Which_segmentSetup
+		res.SegmentSetup = setupRes
+	case proto.Request_Which_segmentRenewal:
+		res.Which = proto.Response_Which_segmentRenewal
+		res.SegmentRenewal = setupRes
+	case proto.Request_Which_segmentTelesSetup:
+		res.Which = proto.Response_Which_segmentTelesSetup
+		res.SegmentTelesSetup = setupRes
+	case proto.Request_Which_segmentTelesRenewal:
+		res.Which = proto.Response_Which_segmentTelesRenewal
+		res.SegmentTelesRenewal = setupRes
+	}
+	return res
+}
+
+func setE2ESetup(res *colibri_mgmt.Response, setup *colibri_mgmt.E2ESetup) {
+	if res.Which == proto.Response_Which_e2eRenewal {
+		res.E2ERenewal = setup
+	} else {
+		res.E2ESetup = setup
+	}
+}
+
+// peerPath returns the path the message arrived with, or an empty one if unknown.
+func peerPath(peer net.Addr) *spath.Path {
+	if p, ok := peer.(*snet.UDPAddr); ok && p.Path != nil {
+		return p.Path
+	}
+	return &spath.Path{}
+}
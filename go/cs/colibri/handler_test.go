@@ -0,0 +1,442 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colibri_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/colibri"
+	"github.com/scionproto/scion/go/cs/colibri/mock_colibri"
+	"github.com/scionproto/scion/go/cs/reservation/conf"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservation/segment/admission"
+	"github.com/scionproto/scion/go/cs/reservation/segmenttest"
+	"github.com/scionproto/scion/go/cs/reservation/sqlite"
+	"github.com/scionproto/scion/go/cs/reservationstore"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/ack"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/mock_infra"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/lib/xtest/matchers"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var (
+	ia1 = xtest.MustParseIA("1-ff00:0:1")
+	ia2 = xtest.MustParseIA("1-ff00:0:2")
+	ia3 = xtest.MustParseIA("1-ff00:0:3")
+)
+
+func TestHandlerSegmentSetup(t *testing.T) {
+	nextAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}
+	testCases := map[string]struct {
+		IA       string
+		Path     segment.Path
+		Step     uint8
+		MinBW    uint8
+		Forward  func(*mock_colibri.MockResolver, *mock_colibri.MockSender)
+		Ack      ack.Ack
+		Result   *infra.HandlerResult
+		Admitted bool
+	}{
+		"transit forwards the request": {
+			IA: "1-ff00:0:2",
+			Path: segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 2,
+				1, "1-ff00:0:3", 0),
+			Step:  1,
+			MinBW: 1,
+			Forward: func(r *mock_colibri.MockResolver, s *mock_colibri.MockSender) {
+				r.EXPECT().Resolve(ia3, common.IFIDType(2)).Return(nextAddr, nil)
+				s.EXPECT().SendColibriRequest(gomock.Any(), gomock.Any(), nextAddr,
+					gomock.Any()).DoAndReturn(func(_ context.Context,
+					msg *colibri_mgmt.ColibriRequestPayload, _ net.Addr, _ uint64) error {
+
+					require.Equal(t, proto.ColibriRequestPayload_Which_request, msg.Which)
+					require.Equal(t, uint8(2), msg.HopByHop.CurrentStep)
+					require.Len(t, msg.Request.SegmentSetup.AllocationTrail, 1)
+					return nil
+				})
+			},
+			Ack:      ack.Ack{Err: proto.Ack_ErrCode_ok},
+			Result:   infra.MetricsResultOk,
+			Admitted: true,
+		},
+		"last AS responds": {
+			IA:    "1-ff00:0:2",
+			Path:  segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 0),
+			Step:  1,
+			MinBW: 1,
+			Forward: func(r *mock_colibri.MockResolver, s *mock_colibri.MockSender) {
+				r.EXPECT().Resolve(ia1, common.IFIDType(1)).Return(nextAddr, nil)
+				s.EXPECT().SendColibriResponse(gomock.Any(), gomock.Any(), nextAddr,
+					gomock.Any()).DoAndReturn(func(_ context.Context,
+					msg *colibri_mgmt.ColibriRequestPayload, _ net.Addr, _ uint64) error {
+
+					require.Equal(t, proto.ColibriRequestPayload_Which_response, msg.Which)
+					require.Equal(t, uint8(0), msg.HopByHop.CurrentStep)
+					require.True(t, msg.Response.Accepted)
+					tok, err := reservation.TokenFromRaw(msg.Response.SegmentSetup.Token)
+					require.NoError(t, err)
+					require.NotZero(t, tok.BWCls)
+					return nil
+				})
+			},
+			Ack:      ack.Ack{Err: proto.Ack_ErrCode_ok},
+			Result:   infra.MetricsResultOk,
+			Admitted: true,
+		},
+		"not admitted responds with failure": {
+			IA: "1-ff00:0:2",
+			Path: segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 2,
+				1, "1-ff00:0:3", 0),
+			Step:  1,
+			MinBW: 13,
+			Forward: func(r *mock_colibri.MockResolver, s *mock_colibri.MockSender) {
+				r.EXPECT().Resolve(ia1, common.IFIDType(1)).Return(nextAddr, nil)
+				s.EXPECT().SendColibriResponse(gomock.Any(), gomock.Any(), nextAddr,
+					gomock.Any()).DoAndReturn(func(_ context.Context,
+					msg *colibri_mgmt.ColibriRequestPayload, _ net.Addr, _ uint64) error {
+
+					require.False(t, msg.Response.Accepted)
+					require.Equal(t, uint8(1), msg.Response.FailedHop)
+					require.Equal(t, proto.SegmentSetupResData_Which_failure,
+						msg.Response.SegmentSetup.Which)
+					return nil
+				})
+			},
+			Ack:    ack.Ack{Err: proto.Ack_ErrCode_ok},
+			Result: infra.MetricsResultOk,
+		},
+		"message for another AS": {
+			IA:      "1-ff00:0:3",
+			Path:    segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 0),
+			Step:    1,
+			MinBW:   1,
+			Forward: func(*mock_colibri.MockResolver, *mock_colibri.MockSender) {},
+			Ack: ack.Ack{
+				Err:     proto.Ack_ErrCode_reject,
+				ErrDesc: "message not meant for this AS",
+			},
+			Result: infra.MetricsErrInvalid,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			mctrl := gomock.NewController(t)
+			defer mctrl.Finish()
+			ctx := context.Background()
+			db, h := newHandler(t, mctrl, xtest.MustParseIA(tc.IA))
+			tc.Forward(h.Resolver.(*mock_colibri.MockResolver),
+				h.Sender.(*mock_colibri.MockSender))
+
+			msg := newSetupMsg(t, tc.Path, tc.Step, tc.MinBW)
+			res := h.Handle(newRequest(mctrl, msg, tc.Ack))
+			require.Equal(t, tc.Result, res)
+
+			rsv, err := db.GetSegmentRsvFromID(ctx, segID(t))
+			require.NoError(t, err)
+			if tc.Admitted {
+				require.NotNil(t, rsv)
+				require.Len(t, rsv.Indices, 1)
+			} else {
+				require.Nil(t, rsv)
+			}
+		})
+	}
+}
+
+func TestHandlerFailedResponseCleansUp(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	ctx := context.Background()
+	db, h := newHandler(t, mctrl, ia2)
+	path := segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 2,
+		1, "1-ff00:0:3", 0)
+	setup := newSetupMsg(t, path, 1, 1)
+	req, err := colibri.NewHopByHopFromCtrlMsg(setup.HopByHop)
+	require.NoError(t, err)
+	_, err = h.ProcessRequest(ctx, setup, req, nil)
+	require.NoError(t, err)
+	rsv, err := db.GetSegmentRsvFromID(ctx, segID(t))
+	require.NoError(t, err)
+	require.NotNil(t, rsv)
+
+	nextAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}
+	h.Resolver.(*mock_colibri.MockResolver).EXPECT().Resolve(ia1, common.IFIDType(1)).
+		Return(nextAddr, nil)
+	h.Sender.(*mock_colibri.MockSender).EXPECT().SendColibriResponse(gomock.Any(),
+		gomock.Any(), nextAddr, gomock.Any())
+	msg := &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: setup.Timestamp,
+		Which:     proto.ColibriRequestPayload_Which_response,
+		Response: &colibri_mgmt.Response{
+			Which: proto.Response_Which_segmentSetup,
+			SegmentSetup: &colibri_mgmt.SegmentSetupRes{
+				Which:   proto.SegmentSetupResData_Which_failure,
+				Failure: setup.Request.SegmentSetup,
+			},
+			FailedHop: 2,
+		},
+		HopByHop: req.ToCtrlMsg(1),
+	}
+	res := h.Handle(newRequest(mctrl, msg, ack.Ack{Err: proto.Ack_ErrCode_ok}))
+	require.Equal(t, infra.MetricsResultOk, res)
+	rsv, err = db.GetSegmentRsvFromID(ctx, segID(t))
+	require.NoError(t, err)
+	require.Nil(t, rsv)
+}
+
+func TestHandlerAcceptedResponseConfirms(t *testing.T) {
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	ctx := context.Background()
+	db, h := newHandler(t, mctrl, ia1)
+	rsv := newSourceRsv(t, db, h, "1-ff00:0:2", time.Now().Add(time.Hour))
+	index := rsv.Indices[0]
+
+	nextAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}
+	h.Resolver.(*mock_colibri.MockResolver).EXPECT().Resolve(ia2, common.IFIDType(1)).
+		Return(nextAddr, nil)
+	h.Sender.(*mock_colibri.MockSender).EXPECT().SendColibriRequest(gomock.Any(),
+		gomock.Any(), nextAddr, gomock.Any()).DoAndReturn(
+		func(_ context.Context, msg *colibri_mgmt.ColibriRequestPayload, _ net.Addr,
+			_ uint64) error {
+
+			require.Equal(t, proto.Request_Which_segmentIndexConfirmation, msg.Request.Which)
+			require.Equal(t, &colibri_mgmt.SegmentIndexConfirmation{
+				Index: uint8(index.Idx),
+				State: proto.ReservationIndexState_pending,
+			}, msg.Request.SegmentIndexConfirmation)
+			require.Equal(t, uint8(1), msg.HopByHop.CurrentStep)
+			return nil
+		})
+	hbh := &colibri.HopByHop{
+		ID:        rsv.ID,
+		InfoField: index.Token.InfoField,
+		Path:      rsv.Path,
+	}
+	msg := &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: uint32(time.Now().Unix()),
+		Which:     proto.ColibriRequestPayload_Which_response,
+		Response: &colibri_mgmt.Response{
+			Which: proto.Response_Which_segmentSetup,
+			SegmentSetup: &colibri_mgmt.SegmentSetupRes{
+				Which: proto.SegmentSetupResData_Which_token,
+				Token: index.Token.ToRaw(),
+			},
+			Accepted: true,
+		},
+		HopByHop: hbh.ToCtrlMsg(0),
+	}
+	res := h.Handle(newRequest(mctrl, msg, ack.Ack{Err: proto.Ack_ErrCode_ok}))
+	require.Equal(t, infra.MetricsResultOk, res)
+	rsv, err := db.GetSegmentRsvFromID(ctx, &rsv.ID)
+	require.NoError(t, err)
+	require.Equal(t, segment.IndexPending, rsv.Indices[0].State())
+}
+
+func TestHandlerE2EFailedSetup(t *testing.T) {
+	nextAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}
+	testCases := map[string]struct {
+		Path segment.Path
+		Step uint8
+	}{
+		"transit": {
+			Path: segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 2,
+				1, "1-ff00:0:3", 0),
+			Step: 1,
+		},
+		"last AS": {
+			Path: segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 0),
+			Step: 1,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			mctrl := gomock.NewController(t)
+			defer mctrl.Finish()
+			_, h := newHandler(t, mctrl, ia2)
+			h.Resolver.(*mock_colibri.MockResolver).EXPECT().Resolve(ia1, common.IFIDType(1)).
+				Return(nextAddr, nil)
+			h.Sender.(*mock_colibri.MockSender).EXPECT().SendColibriResponse(gomock.Any(),
+				gomock.Any(), nextAddr, gomock.Any()).DoAndReturn(
+				func(_ context.Context, msg *colibri_mgmt.ColibriRequestPayload, _ net.Addr,
+					_ uint64) error {
+
+					require.Equal(t, proto.ColibriRequestPayload_Which_response, msg.Which)
+					require.False(t, msg.Response.Accepted)
+					require.Equal(t, tc.Step, msg.Response.FailedHop)
+					require.Equal(t, proto.E2ESetupData_Which_failure,
+						msg.Response.E2ESetup.Which)
+					return nil
+				})
+
+			msg := newE2EFailureMsg(t, tc.Path, tc.Step)
+			res := h.Handle(newRequest(mctrl, msg, ack.Ack{Err: proto.Ack_ErrCode_ok}))
+			require.Equal(t, infra.MetricsResultOk, res)
+		})
+	}
+}
+
+func newHandler(t *testing.T, mctrl *gomock.Controller,
+	ia addr.IA) (*sqlite.Backend, *colibri.Handler) {
+
+	t.Helper()
+	db, err := sqlite.New("file::memory:")
+	require.NoError(t, err)
+	caps, err := conf.NewCapacities(map[common.IFIDType]map[common.IFIDType]uint64{
+		0: {1: 1000},
+		1: {0: 1000, 2: 1000},
+	})
+	require.NoError(t, err)
+	return db, &colibri.Handler{
+		IA:       ia,
+		Store:    reservationstore.NewStore(db, &admission.NTube{Caps: caps}),
+		Sender:   mock_colibri.NewMockSender(mctrl),
+		Resolver: mock_colibri.NewMockResolver(mctrl),
+	}
+}
+
+func newRequest(mctrl *gomock.Controller, msg *colibri_mgmt.ColibriRequestPayload,
+	a ack.Ack) *infra.Request {
+
+	rw := mock_infra.NewMockResponseWriter(mctrl)
+	rw.EXPECT().SendAckReply(gomock.Any(), &matchers.AckMsg{Ack: a})
+	ctx := infra.NewContextWithResponseWriter(context.Background(), rw)
+	return infra.NewRequest(ctx, msg, nil, nil, 0)
+}
+
+func newSetupMsg(t *testing.T, path segment.Path, step,
+	minBW uint8) *colibri_mgmt.ColibriRequestPayload {
+
+	t.Helper()
+	hbh := &colibri.HopByHop{
+		ID: *segID(t),
+		InfoField: reservation.InfoField{
+			ExpirationTick: reservation.TickFromTime(time.Now().Add(time.Hour)),
+			PathType:       reservation.UpPath,
+		},
+		Path: path,
+	}
+	return &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: uint32(time.Now().Unix()),
+		Which:     proto.ColibriRequestPayload_Which_request,
+		Request: &colibri_mgmt.Request{
+			Which: proto.Request_Which_segmentSetup,
+			SegmentSetup: &colibri_mgmt.SegmentSetup{
+				MinBW: minBW,
+				MaxBW: 13,
+			},
+		},
+		HopByHop: hbh.ToCtrlMsg(int(step)),
+	}
+}
+
+func newE2EFailureMsg(t *testing.T, path segment.Path,
+	step uint8) *colibri_mgmt.ColibriRequestPayload {
+
+	t.Helper()
+	hbh := &colibri.HopByHop{
+		InfoField: reservation.InfoField{
+			ExpirationTick: reservation.TickFromTime(time.Now().Add(time.Hour)),
+			PathType:       reservation.E2EPath,
+		},
+		Path: path,
+	}
+	inf := make([]byte, reservation.InfoFieldLen)
+	_, err := hbh.InfoField.Read(inf)
+	require.NoError(t, err)
+	id := make([]byte, reservation.E2EIDLen)
+	_, err = e2eID(t).Read(id)
+	require.NoError(t, err)
+	return &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: uint32(time.Now().Unix()),
+		Which:     proto.ColibriRequestPayload_Which_request,
+		Request: &colibri_mgmt.Request{
+			Which: proto.Request_Which_e2eSetup,
+			E2ESetup: &colibri_mgmt.E2ESetup{
+				ReservationID: &colibri_mgmt.E2EReservationID{
+					ASID:   id[:6],
+					Suffix: id[6:],
+				},
+				Which: proto.E2ESetupData_Which_failure,
+				Failure: &colibri_mgmt.E2ESetupFailure{
+					ErrorCode: 1,
+					InfoField: inf,
+				},
+			},
+		},
+		HopByHop: hbh.ToCtrlMsg(int(step)),
+	}
+}
+
+func e2eID(t *testing.T) *reservation.E2EID {
+	t.Helper()
+	id, err := reservation.NewE2EID(xtest.MustParseAS("ff00:0:1"),
+		xtest.MustParseHexString("beefcafebeefcafebeef"))
+	require.NoError(t, err)
+	return id
+}
+
+func segID(t *testing.T) *reservation.SegmentID {
+	t.Helper()
+	id, err := reservation.NewSegmentID(xtest.MustParseAS("ff00:0:1"),
+		xtest.MustParseHexString("00000001"))
+	require.NoError(t, err)
+	return id
+}
+
+// newSourceRsv creates a reservation from 1-ff00:0:1 to dst, with a temporary index expiring at
+// expiration.
+func newSourceRsv(t *testing.T, db *sqlite.Backend, h *colibri.Handler, dst string,
+	expiration time.Time) *segment.Reservation {
+
+	t.Helper()
+	ctx := context.Background()
+	rsv := segment.NewReservation()
+	rsv.ID.ASID = xtest.MustParseAS("ff00:0:1")
+	rsv.Egress = 1
+	rsv.Path = segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, dst, 0)
+	err := db.NewSegmentRsv(ctx, rsv)
+	require.NoError(t, err)
+	err = h.Store.AdmitSegmentReservation(ctx, &segment.SetupReq{
+		Request: segment.Request{
+			ID:     rsv.ID,
+			Egress: 1,
+		},
+		InfoField: reservation.InfoField{
+			ExpirationTick: reservation.TickFromTime(expiration),
+			PathType:       reservation.UpPath,
+		},
+		MinBW: 1,
+		MaxBW: 13,
+	})
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &rsv.ID)
+	require.NoError(t, err)
+	return rsv
+}
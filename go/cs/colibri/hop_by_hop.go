@@ -0,0 +1,101 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colibri
+
+import (
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// HopByHop contains the parsed hop by hop fields of a COLIBRI message.
+type HopByHop struct {
+	// ID is the segment reservation the message refers to. It is zero for e2e messages.
+	ID reservation.SegmentID
+	// InfoField is the info field of the reservation index the message refers to.
+	InfoField reservation.InfoField
+	// Path is the reservation path, in reservation order.
+	Path segment.Path
+	// Step is the index in Path of the AS processing the message.
+	Step int
+}
+
+// NewHopByHopFromCtrlMsg parses the hop by hop fields of a COLIBRI message.
+func NewHopByHopFromCtrlMsg(msg *colibri_mgmt.HopByHopFields) (*HopByHop, error) {
+	if msg == nil {
+		return nil, serrors.New("missing hop by hop fields")
+	}
+	h := &HopByHop{
+		Step: int(msg.CurrentStep),
+	}
+	if msg.SegmentID != nil {
+		id, err := reservation.SegmentIDFromRawBuffers(msg.SegmentID.ASID, msg.SegmentID.Suffix)
+		if err != nil {
+			return nil, serrors.WrapStr("parsing segment reservation ID", err)
+		}
+		h.ID = *id
+	}
+	inf, err := reservation.InfoFieldFromRaw(msg.InfoField)
+	if err != nil {
+		return nil, serrors.WrapStr("parsing info field", err)
+	}
+	h.InfoField = *inf
+	if h.Path, err = segment.NewPathFromRaw(msg.Path); err != nil {
+		return nil, serrors.WrapStr("parsing reservation path", err)
+	}
+	if err := h.Path.Validate(); err != nil {
+		return nil, serrors.WrapStr("invalid reservation path", err)
+	}
+	if h.Step >= len(h.Path) {
+		return nil, serrors.New("current step outside of reservation path", "step", h.Step,
+			"path_len", len(h.Path))
+	}
+	return h, nil
+}
+
+// Current returns the step in the path of the AS processing the message.
+func (h *HopByHop) Current() segment.PathStepWithIA {
+	return h.Path[h.Step]
+}
+
+// IsFirst returns true if the AS processing the message is the source of the reservation.
+func (h *HopByHop) IsFirst() bool {
+	return h.Step == 0
+}
+
+// IsLast returns true if the AS processing the message is the destination of the reservation.
+func (h *HopByHop) IsLast() bool {
+	return h.Step == len(h.Path)-1
+}
+
+// ToCtrlMsg serializes the hop by hop fields, with the current step set to step.
+func (h *HopByHop) ToCtrlMsg(step int) *colibri_mgmt.HopByHopFields {
+	msg := &colibri_mgmt.HopByHopFields{
+		InfoField:   make([]byte, reservation.InfoFieldLen),
+		Path:        h.Path.ToRaw(),
+		CurrentStep: uint8(step),
+	}
+	h.InfoField.Read(msg.InfoField)
+	if h.ID.ASID != 0 {
+		id := make([]byte, reservation.SegmentIDLen)
+		h.ID.Read(id)
+		msg.SegmentID = &colibri_mgmt.SegmentReservationID{
+			ASID:   id[:6],
+			Suffix: id[6:],
+		}
+	}
+	return msg
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colibri
+
+import (
+	"context"
+	"time"
+
+	"github.com/scionproto/scion/go/cs/metrics"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservationstorage/backend"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ periodic.Task = (*Renewer)(nil)
+
+// Renewer is a periodic task that maintains the segment reservations initiated in this AS.
+// On every run it removes the expired indices, activates the newest confirmed index of each
+// reservation, and requests a new index for the reservations about to expire.
+type Renewer struct {
+	// IA is the ISD-AS of the local AS.
+	IA addr.IA
+	// DB is the reservation database.
+	DB backend.DB
+	// Handler processes the requests locally and forwards them along the reservation path.
+	Handler *Handler
+	// IndexValidity is the validity of the requested indices.
+	IndexValidity time.Duration
+	// RenewalLead is how long before the expiration of the newest index a new one is requested.
+	RenewalLead time.Duration
+}
+
+// Name returns the tasks name.
+func (r *Renewer) Name() string {
+	return "colibri_renewer"
+}
+
+// Run renews, activates and cleans up the reservations.
+func (r *Renewer) Run(ctx context.Context) {
+	logger := log.FromCtx(ctx)
+	now := time.Now()
+	n, err := r.DB.DeleteExpiredIndices(ctx, now)
+	if err != nil {
+		logger.Error("[colibri.Renewer] Failed to delete expired indices", "err", err)
+		metrics.Colibri.Cleanups(metrics.ColibriLabels{Result: metrics.ErrDB}).Inc()
+	} else {
+		metrics.Colibri.Cleanups(metrics.ColibriLabels{Result: metrics.Success}).Inc()
+		metrics.Colibri.ExpiredIndices().Add(float64(n))
+	}
+	rsvs, err := r.DB.GetSegmentRsvsFromSrcDstIA(ctx, r.IA, addr.IA{})
+	if err != nil {
+		logger.Error("[colibri.Renewer] Failed to get segment reservations", "err", err)
+		return
+	}
+	for _, rsv := range rsvs {
+		if index := newestPending(rsv); index != nil {
+			result, err := r.activate(ctx, rsv, index, now)
+			if err != nil {
+				logger.Info("[colibri.Renewer] Failed to activate index", "id", rsv.ID,
+					"idx", index.Idx, "err", err)
+			}
+			metrics.Colibri.Activations(metrics.ColibriLabels{Result: result}).Inc()
+		}
+		if r.needsRenewal(rsv, now) {
+			result, err := r.renew(ctx, rsv, now)
+			if err != nil {
+				logger.Info("[colibri.Renewer] Failed to renew reservation", "id", rsv.ID,
+					"err", err)
+			}
+			metrics.Colibri.Renewals(metrics.ColibriLabels{Result: result}).Inc()
+		}
+	}
+}
+
+// needsRenewal returns true if the newest index of the reservation expires within the renewal
+// lead. Reservations without indices are not renewed.
+func (r *Renewer) needsRenewal(rsv *segment.Reservation, now time.Time) bool {
+	if len(rsv.Indices) == 0 {
+		return false
+	}
+	return !rsv.Indices[len(rsv.Indices)-1].Expiration.After(now.Add(r.RenewalLead))
+}
+
+// activate activates the index in this AS and along the reservation path.
+func (r *Renewer) activate(ctx context.Context, rsv *segment.Reservation, index *segment.Index,
+	now time.Time) (string, error) {
+
+	return r.send(ctx, rsv, infoField(index), now, &colibri_mgmt.Request{
+		Which: proto.Request_Which_segmentIndexConfirmation,
+		SegmentIndexConfirmation: &colibri_mgmt.SegmentIndexConfirmation{
+			Index: uint8(index.Idx),
+			State: proto.ReservationIndexState_active,
+		},
+	})
+}
+
+// renew requests a new index for the reservation, with the same bandwidth as the newest one.
+func (r *Renewer) renew(ctx context.Context, rsv *segment.Reservation,
+	now time.Time) (string, error) {
+
+	last := &rsv.Indices[len(rsv.Indices)-1]
+	inf := infoField(last)
+	inf.Idx = last.Idx.Add(1)
+	inf.ExpirationTick = reservation.TickFromTime(now.Add(r.IndexValidity))
+	setup := &segment.SetupReq{
+		MinBW:     uint8(last.MinBW),
+		MaxBW:     uint8(last.MaxBW),
+		SplitCls:  uint8(rsv.TrafficSplit),
+		PathProps: rsv.PathEndProps,
+	}
+	return r.send(ctx, rsv, inf, now, &colibri_mgmt.Request{
+		Which:          proto.Request_Which_segmentRenewal,
+		SegmentRenewal: setup.ToCtrlMsg(),
+	})
+}
+
+// send processes the request in this AS, as the first step of the reservation path, and
+// forwards it to the next AS. It returns the result used in the metrics.
+func (r *Renewer) send(ctx context.Context, rsv *segment.Reservation,
+	inf reservation.InfoField, now time.Time, req *colibri_mgmt.Request) (string, error) {
+
+	hbh := &HopByHop{
+		ID:        rsv.ID,
+		InfoField: inf,
+		Path:      rsv.Path,
+	}
+	msg := &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: uint32(now.Unix()),
+		Which:     proto.ColibriRequestPayload_Which_request,
+		Request:   req,
+		HopByHop:  hbh.ToCtrlMsg(0),
+	}
+	next, err := r.Handler.ProcessRequest(ctx, msg, hbh, nil)
+	if err != nil {
+		return metrics.ErrProcess, err
+	}
+	if next == nil {
+		return metrics.ErrInternal, serrors.New("nothing to forward")
+	}
+	if err := r.Handler.Forward(ctx, next); err != nil {
+		return metrics.ErrSend, err
+	}
+	return metrics.Success, nil
+}
+
+// newestPending returns the newest confirmed index of the reservation that is not yet active,
+// or nil if there is none.
+func newestPending(rsv *segment.Reservation) *segment.Index {
+	for i := len(rsv.Indices) - 1; i >= 0; i-- {
+		if rsv.Indices[i].State() == segment.IndexPending {
+			return &rsv.Indices[i]
+		}
+	}
+	return nil
+}
+
+// infoField returns the info field of the index. Indices created in this AS always have a token.
+func infoField(index *segment.Index) reservation.InfoField {
+	if index.Token != nil {
+		return index.Token.InfoField
+	}
+	return reservation.InfoField{
+		ExpirationTick: reservation.TickFromTime(index.Expiration),
+		BWCls:          index.AllocBW,
+		Idx:            index.Idx,
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colibri_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/colibri"
+	"github.com/scionproto/scion/go/cs/colibri/mock_colibri"
+	"github.com/scionproto/scion/go/cs/metrics"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestRenewer(t *testing.T) {
+	metrics.InitColibriMetrics()
+	mctrl := gomock.NewController(t)
+	defer mctrl.Finish()
+	ctx := context.Background()
+	db, h := newHandler(t, mctrl, ia1)
+	rsv := newSourceRsv(t, db, h, "1-ff00:0:2", time.Now().Add(30*time.Second))
+	expired := newSourceRsv(t, db, h, "1-ff00:0:3", time.Now().Add(30*time.Second))
+	expired.Indices[0].Expiration = time.Now().Add(-time.Second)
+	err := db.PersistSegmentRsv(ctx, expired)
+	require.NoError(t, err)
+
+	renewer := &colibri.Renewer{
+		IA:            ia1,
+		DB:            db,
+		Handler:       h,
+		IndexValidity: 5 * time.Minute,
+		RenewalLead:   time.Minute,
+	}
+	resolver := h.Resolver.(*mock_colibri.MockResolver)
+	sender := h.Sender.(*mock_colibri.MockSender)
+	nextAddr := &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30041}
+
+	// the index expires within the renewal lead: a new one is requested.
+	resolver.EXPECT().Resolve(ia2, common.IFIDType(1)).Return(nextAddr, nil)
+	sender.EXPECT().SendColibriRequest(gomock.Any(), gomock.Any(), nextAddr, gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+			_ net.Addr, _ uint64) error {
+
+			require.Equal(t, proto.Request_Which_segmentRenewal, msg.Request.Which)
+			require.Equal(t, uint8(1), msg.HopByHop.CurrentStep)
+			hbh, err := colibri.NewHopByHopFromCtrlMsg(msg.HopByHop)
+			require.NoError(t, err)
+			require.Equal(t, rsv.ID, hbh.ID)
+			require.Equal(t, reservation.IndexNumber(1), hbh.InfoField.Idx)
+			return nil
+		})
+	renewer.Run(ctx)
+	got, err := db.GetSegmentRsvFromID(ctx, &rsv.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Indices, 2)
+	got, err = db.GetSegmentRsvFromID(ctx, &expired.ID)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	// once confirmed, the new index is activated. It does not need renewal yet.
+	err = h.Store.ConfirmSegmentReservation(ctx, rsv.ID, 1)
+	require.NoError(t, err)
+	resolver.EXPECT().Resolve(ia2, common.IFIDType(1)).Return(nextAddr, nil)
+	sender.EXPECT().SendColibriRequest(gomock.Any(), gomock.Any(), nextAddr, gomock.Any()).
+		DoAndReturn(func(_ context.Context, msg *colibri_mgmt.ColibriRequestPayload,
+			_ net.Addr, _ uint64) error {
+
+			require.Equal(t, &colibri_mgmt.SegmentIndexConfirmation{
+				Index: 1,
+				State: proto.ReservationIndexState_active,
+			}, msg.Request.SegmentIndexConfirmation)
+			return nil
+		})
+	renewer.Run(ctx)
+	got, err = db.GetSegmentRsvFromID(ctx, &rsv.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Indices, 1)
+	require.Equal(t, segment.IndexActive, got.ActiveIndex().State())
+	require.Equal(t, reservation.IndexNumber(1), got.ActiveIndex().Idx)
+
+	// nothing to do.
+	renewer.Run(ctx)
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colibri
+
+import (
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/cs/onehop"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/topology"
+)
+
+// OneHopResolver resolves the control service of a neighboring AS to an SVC address reached
+// through a one-hop path on the local interface.
+type OneHopResolver struct {
+	// Sender creates the one-hop paths.
+	Sender *onehop.Sender
+	// TopoProvider provides the local interfaces.
+	TopoProvider topology.Provider
+}
+
+// Resolve returns the address of the control service in AS ia, reachable through the local
+// interface ifid.
+func (r *OneHopResolver) Resolve(ia addr.IA, ifid common.IFIDType) (net.Addr, error) {
+	intf, ok := r.TopoProvider.Get().IFInfoMap()[ifid]
+	if !ok {
+		return nil, serrors.New("unknown interface", "ifid", ifid)
+	}
+	if !intf.IA.Equal(ia) {
+		return nil, serrors.New("interface does not lead to AS", "ifid", ifid,
+			"expected", ia, "actual", intf.IA)
+	}
+	path, err := r.Sender.CreatePath(ifid, time.Now())
+	if err != nil {
+		return nil, serrors.WrapStr("creating one-hop path", err, "ifid", ifid)
+	}
+	return &snet.SVCAddr{
+		IA:      ia,
+		Path:    (*spath.Path)(path),
+		NextHop: intf.InternalAddr,
+		SVC:     addr.SvcCS,
+	}, nil
+}
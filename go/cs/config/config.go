@@ -22,6 +22,7 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/serrors"
@@ -56,6 +57,14 @@ const (
 	DefaultQueryInterval = 5 * time.Minute
 	// DefaultMaxASValidity is the default validity period for renewed AS certificates.
 	DefaultMaxASValidity = 3 * 24 * time.Hour
+	// DefaultColibriIndexValidity is the default validity of the COLIBRI segment reservation
+	// indices requested by this AS.
+	DefaultColibriIndexValidity = 5 * time.Minute
+	// DefaultColibriRenewalLead is the default time before the expiration of a COLIBRI segment
+	// reservation when it is renewed.
+	DefaultColibriRenewalLead = time.Minute
+	// DefaultDRKeyEpochDuration is the default duration of the DRKey epochs.
+	DefaultDRKeyEpochDuration = drkey.DefaultEpochDuration
 )
 
 // Error values
@@ -81,6 +90,8 @@ type Config struct {
 	BS        BSConfig         `toml:"beaconing,omitempty"`
 	PS        PSConfig         `toml:"path,omitempty"`
 	CA        CA               `toml:"ca,omitempty"`
+	Colibri   ColibriConfig    `toml:"colibri,omitempty"`
+	DRKey     DRKeyConfig      `toml:"drkey,omitempty"`
 }
 
 // InitDefaults initializes the default values for all parts of the config.
@@ -98,6 +109,8 @@ func (cfg *Config) InitDefaults() {
 		&cfg.BS,
 		&cfg.PS,
 		&cfg.CA,
+		&cfg.Colibri,
+		&cfg.DRKey,
 	)
 }
 
@@ -115,6 +128,8 @@ func (cfg *Config) Validate() error {
 		&cfg.BS,
 		&cfg.PS,
 		&cfg.CA,
+		&cfg.Colibri,
+		&cfg.DRKey,
 	)
 }
 
@@ -158,6 +173,8 @@ func (cfg *Config) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
 		&cfg.BS,
 		&cfg.PS,
 		&cfg.CA,
+		&cfg.Colibri,
+		&cfg.DRKey,
 	)
 }
 
@@ -332,3 +349,92 @@ func (cfg *CA) Sample(dst io.Writer, _ config.Path, _ config.CtxMap) {
 func (cfg *CA) ConfigName() string {
 	return "ca"
 }
+
+var _ config.Config = (*ColibriConfig)(nil)
+
+// ColibriConfig is the configuration of the COLIBRI service.
+type ColibriConfig struct {
+	// Capacities contains the file path of the capacity matrix of the interfaces. If this is the
+	// empty string, the COLIBRI service is disabled.
+	Capacities string `toml:"capacities,omitempty"`
+	// IndexValidity is the validity of the segment reservation indices requested by this AS.
+	IndexValidity util.DurWrap `toml:"index_validity,omitempty"`
+	// RenewalLead specifies how long before the expiration of a segment reservation started in
+	// this AS a new index is requested.
+	RenewalLead util.DurWrap `toml:"renewal_lead,omitempty"`
+	// ReservationDB is the database storing the reservations.
+	ReservationDB storage.DBConfig `toml:"reservation_db,omitempty"`
+}
+
+func (cfg *ColibriConfig) InitDefaults() {
+	initDurWrap(&cfg.IndexValidity, DefaultColibriIndexValidity)
+	initDurWrap(&cfg.RenewalLead, DefaultColibriRenewalLead)
+	config.InitAll(&cfg.ReservationDB)
+}
+
+func (cfg *ColibriConfig) Validate() error {
+	if cfg.RenewalLead.Duration >= cfg.IndexValidity.Duration {
+		return serrors.New("renewal_lead must be smaller than index_validity")
+	}
+	return config.ValidateAll(&cfg.ReservationDB)
+}
+
+func (cfg *ColibriConfig) Sample(dst io.Writer, path config.Path, ctx config.CtxMap) {
+	config.WriteString(dst, colibriSample)
+	config.WriteSample(dst, path, ctx,
+		config.OverrideName(
+			config.FormatData(
+				&cfg.ReservationDB,
+				storage.SetID(storage.SampleReservationDB, ctx[config.ID]).Connection,
+			),
+			"reservation_db",
+		),
+	)
+}
+
+func (cfg *ColibriConfig) ConfigName() string {
+	return "colibri"
+}
+
+var _ config.Config = (*DRKeyConfig)(nil)
+
+// DRKeyConfig is the configuration of the DRKey service.
+type DRKeyConfig struct {
+	// Enabled enables the DRKey service.
+	Enabled bool `toml:"enabled,omitempty"`
+	// EpochDuration is the duration of the epochs of the secret values. It
+	// must be the same for all entities deriving keys of this AS, i.e., also
+	// for the border routers.
+	EpochDuration util.DurWrap `toml:"epoch_duration,omitempty"`
+	// Lvl1DB is the database storing the level-1 keys fetched from remote ASes.
+	Lvl1DB storage.DBConfig `toml:"lvl1_db,omitempty"`
+}
+
+func (cfg *DRKeyConfig) InitDefaults() {
+	initDurWrap(&cfg.EpochDuration, DefaultDRKeyEpochDuration)
+	config.InitAll(&cfg.Lvl1DB)
+}
+
+func (cfg *DRKeyConfig) Validate() error {
+	if cfg.EpochDuration.Duration < time.Minute {
+		return serrors.New("epoch_duration must be at least 1m")
+	}
+	return config.ValidateAll(&cfg.Lvl1DB)
+}
+
+func (cfg *DRKeyConfig) Sample(dst io.Writer, path config.Path, ctx config.CtxMap) {
+	config.WriteString(dst, drkeySample)
+	config.WriteSample(dst, path, ctx,
+		config.OverrideName(
+			config.FormatData(
+				&cfg.Lvl1DB,
+				storage.SetID(storage.SampleDRKeyLvl1DB, ctx[config.ID]).Connection,
+			),
+			"lvl1_db",
+		),
+	)
+}
+
+func (cfg *DRKeyConfig) ConfigName() string {
+	return "drkey"
+}
@@ -82,6 +82,8 @@ func CheckTestConfig(t *testing.T, cfg *Config, id string) {
 	CheckTestBSConfig(t, &cfg.BS)
 	CheckTestPSConfig(t, &cfg.PS, id)
 	CheckTestCA(t, &cfg.CA, id)
+	CheckTestColibri(t, &cfg.Colibri, id)
+	CheckTestDRKey(t, &cfg.DRKey, id)
 }
 
 func CheckTestBSConfig(t *testing.T, cfg *BSConfig) {
@@ -114,3 +116,16 @@ func InitTestCA(cfg *CA) {}
 func CheckTestCA(t *testing.T, cfg *CA, id string) {
 	assert.Equal(t, DefaultMaxASValidity, cfg.MaxASValidity.Duration)
 }
+
+func CheckTestColibri(t *testing.T, cfg *ColibriConfig, id string) {
+	assert.Empty(t, cfg.Capacities)
+	assert.Equal(t, DefaultColibriIndexValidity, cfg.IndexValidity.Duration)
+	assert.Equal(t, DefaultColibriRenewalLead, cfg.RenewalLead.Duration)
+	storagetest.CheckTestReservationDBConfig(t, &cfg.ReservationDB, id)
+}
+
+func CheckTestDRKey(t *testing.T, cfg *DRKeyConfig, id string) {
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, DefaultDRKeyEpochDuration, cfg.EpochDuration.Duration)
+	storagetest.CheckTestDRKeyLvl1DBConfig(t, &cfg.Lvl1DB, id)
+}
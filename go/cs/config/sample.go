@@ -30,3 +30,27 @@ const caSample = `
 # loaded that satisfies the condition. (default 3d)
 max_as_validity = "3d"
 `
+
+const colibriSample = `
+# The file containing the capacity matrix of the interfaces available to COLIBRI
+# segment reservations, in kbps. If empty, the COLIBRI service is disabled.
+# (default "")
+capacities = ""
+
+# The validity of the segment reservation indices requested by this AS.
+# (default 5m)
+index_validity = "5m"
+
+# How long before the expiration of a segment reservation started in this AS a
+# new index is requested. (default 1m)
+renewal_lead = "1m"
+`
+
+const drkeySample = `
+# Enables the DRKey service. (default false)
+enabled = false
+
+# The duration of the epochs of the DRKey secret values. It must match the
+# duration configured for the border routers of this AS. (default 24h)
+epoch_duration = "24h"
+`
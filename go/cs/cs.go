@@ -24,12 +24,17 @@ import (
 
 	"github.com/scionproto/scion/go/cs/beacon"
 	"github.com/scionproto/scion/go/cs/beaconing"
+	"github.com/scionproto/scion/go/cs/colibri"
 	"github.com/scionproto/scion/go/cs/config"
 	"github.com/scionproto/scion/go/cs/handlers"
 	"github.com/scionproto/scion/go/cs/ifstate"
 	"github.com/scionproto/scion/go/cs/keepalive"
 	"github.com/scionproto/scion/go/cs/metrics"
 	"github.com/scionproto/scion/go/cs/onehop"
+	"github.com/scionproto/scion/go/cs/reservation/conf"
+	"github.com/scionproto/scion/go/cs/reservation/segment/admission"
+	"github.com/scionproto/scion/go/cs/reservationstorage/backend"
+	"github.com/scionproto/scion/go/cs/reservationstore"
 	"github.com/scionproto/scion/go/cs/revocation"
 	"github.com/scionproto/scion/go/cs/segreq"
 	"github.com/scionproto/scion/go/lib/addr"
@@ -44,6 +49,7 @@ import (
 	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/periodic"
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
@@ -51,6 +57,7 @@ import (
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/pkg/command"
 	"github.com/scionproto/scion/go/pkg/cs"
+	"github.com/scionproto/scion/go/pkg/cs/drkey"
 	cstrust "github.com/scionproto/scion/go/pkg/cs/trust"
 	trusthandler "github.com/scionproto/scion/go/pkg/cs/trust/handler"
 	"github.com/scionproto/scion/go/pkg/storage"
@@ -190,7 +197,8 @@ func run(file string) error {
 		Verifier:     verifier,
 		HeaderV2:     cfg.Features.HeaderV2,
 	}
-	cs.SetTrustRouter(&provider, segreq.NewRouter(fetcherCfg))
+	segRouter := segreq.NewRouter(fetcherCfg)
+	cs.SetTrustRouter(&provider, segRouter)
 
 	// Register trust material related handlers.
 	trcHandler := trusthandler.TRCReq{Provider: provider, IA: topo.IA()}
@@ -234,6 +242,7 @@ func run(file string) error {
 	// header v2. Disable with https://github.com/Anapaya/scion/issues/3337.
 	if !cfg.Features.HeaderV2 || true {
 		msgr.AddHandler(infra.IfStateReq, ifstate.NewHandler(intfs))
+		msgr.AddHandler(infra.IfStateInfos, ifstate.NewInfoHandler(topo.IA(), intfs))
 		msgr.AddHandler(infra.IfId, keepalive.NewHandler(topo.IA(), intfs,
 			keepalive.StateChangeTasks{
 				RevDropper: beaconStore,
@@ -313,10 +322,84 @@ func run(file string) error {
 	if err != nil {
 		return err
 	}
+	if cfg.Colibri.Capacities != "" {
+		metrics.InitColibriMetrics()
+		colibriDB, err := storage.NewReservationStorage(cfg.Colibri.ReservationDB)
+		if err != nil {
+			return serrors.WrapStr("initializing reservation storage", err)
+		}
+		defer colibriDB.Close()
+		colibriHandler, err := loadColibriHandler(topo.IA(), cfg, colibriDB, msgr,
+			&onehop.Sender{
+				Conn:     ohpConn,
+				IA:       topo.IA(),
+				MAC:      macGen(),
+				Addr:     nc.Public,
+				HeaderV2: cfg.Features.HeaderV2,
+			},
+		)
+		if err != nil {
+			return serrors.WrapStr("initializing COLIBRI service", err)
+		}
+		msgr.AddHandler(infra.ColibriRequest, colibriHandler)
+		msgr.AddHandler(infra.ColibriResponse, colibriHandler)
+		renewer := periodic.Start(
+			&colibri.Renewer{
+				IA:            topo.IA(),
+				DB:            colibriDB,
+				Handler:       colibriHandler,
+				IndexValidity: cfg.Colibri.IndexValidity.Duration,
+				RenewalLead:   cfg.Colibri.RenewalLead.Duration,
+			},
+			5*time.Second,
+			5*time.Second,
+		)
+		defer renewer.Kill()
+	}
+	if cfg.DRKey.Enabled {
+		lvl1DB, err := storage.NewDRKeyLvl1Storage(cfg.DRKey.Lvl1DB)
+		if err != nil {
+			return serrors.WrapStr("initializing DRKey level 1 storage", err)
+		}
+		defer lvl1DB.Close()
+		svStore, err := cs.NewDRKeySecretValueStore(cfg.General.ConfigDir,
+			cfg.DRKey.EpochDuration.Duration)
+		if err != nil {
+			return serrors.WrapStr("initializing DRKey secret values", err)
+		}
+		drkeyStore := &drkey.ServiceStore{
+			LocalIA:      topo.IA(),
+			SecretValues: svStore,
+			DB:           lvl1DB,
+			Fetcher: drkey.Fetcher{
+				LocalIA:   topo.IA(),
+				RPC:       msgr,
+				Router:    segRouter,
+				SignerGen: signer.SignerGen,
+				Verifier:  verifier,
+			},
+		}
+		cs.MultiRegister(infra.DRKeyRequest,
+			drkey.Handler{
+				Store:    drkeyStore,
+				Signer:   signer,
+				Verifier: verifier,
+				Chains:   provider,
+			},
+			msgr, tcpMsgr,
+		)
+		lvl1Cleaner := periodic.Start(drkey.NewLvl1Cleaner(lvl1DB),
+			5*time.Minute, 5*time.Minute)
+		defer lvl1Cleaner.Kill()
+	}
 	staticInfo, err := beaconing.ParseStaticInfoCfg(cfg.General.StaticInfoConfig())
 	if err != nil {
 		log.Info("Failed to read static info", "err", err)
 	}
+	hpRegistration, err := cs.LoadHiddenPathRegistration(cfg.BS.Policies)
+	if err != nil {
+		return err
+	}
 	tasks, err := cs.StartTasks(cs.TasksConfig{
 		Public:      nc.Public,
 		Intfs:       intfs,
@@ -343,8 +426,12 @@ func run(file string) error {
 		OriginationInterval:  cfg.BS.OriginationInterval.Duration,
 		PropagationInterval:  cfg.BS.PropagationInterval.Duration,
 		RegistrationInterval: cfg.BS.RegistrationInterval.Duration,
-		AllowIsdLoop:         isdLoopAllowed,
-		HeaderV2:             cfg.Features.HeaderV2,
+
+		HiddenPathRegistration: hpRegistration,
+		HiddenPathRouter:       segRouter,
+
+		AllowIsdLoop: isdLoopAllowed,
+		HeaderV2:     cfg.Features.HeaderV2,
 	})
 	if err != nil {
 		serrors.WrapStr("starting periodic tasks", err)
@@ -444,3 +531,21 @@ func loadBeaconStore(core bool, ia addr.IA, cfg config.Config) (cs.Store, bool,
 	store, err := beacon.NewBeaconStore(policies, db)
 	return store, *policies.Prop.Filter.AllowIsdLoop, err
 }
+
+func loadColibriHandler(ia addr.IA, cfg config.Config, db backend.DB, msgr infra.Messenger,
+	sender *onehop.Sender) (*colibri.Handler, error) {
+
+	caps, err := conf.LoadCapacities(cfg.Colibri.Capacities)
+	if err != nil {
+		return nil, err
+	}
+	return &colibri.Handler{
+		IA:     ia,
+		Store:  reservationstore.NewStore(db, &admission.NTube{Caps: caps}),
+		Sender: msgr,
+		Resolver: &colibri.OneHopResolver{
+			Sender:       sender,
+			TopoProvider: itopo.Provider(),
+		},
+	}, nil
+}
@@ -15,10 +15,15 @@
 package ifstate
 
 import (
+	"net"
+
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/topology"
 )
 
 type handler struct {
@@ -81,3 +86,77 @@ func infoFromInterface(ifid common.IFIDType, intf *Interface) *path_mgmt.IFState
 		SRevInfo: intf.Revocation(),
 	}
 }
+
+type infoHandler struct {
+	ia      addr.IA
+	intfs   *Interfaces
+	request *infra.Request
+}
+
+// NewInfoHandler creates a handler for interface state infos sent by the
+// border routers. Interfaces that are reported inactive are expired, such that
+// they are revoked by the next run of the revoker. Active interfaces are
+// ignored, as interfaces are only activated by keepalives. Infos are only
+// accepted from the control address of the border router that owns the
+// interface in the local AS.
+func NewInfoHandler(ia addr.IA, intfs *Interfaces) infra.Handler {
+	f := func(r *infra.Request) *infra.HandlerResult {
+		handler := &infoHandler{
+			ia:      ia,
+			intfs:   intfs,
+			request: r,
+		}
+		return handler.Handle()
+	}
+	return infra.HandlerFunc(f)
+}
+
+func (h *infoHandler) Handle() *infra.HandlerResult {
+	logger := log.FromCtx(h.request.Context())
+	infos, ok := h.request.Message.(*path_mgmt.IFStateInfos)
+	if !ok {
+		logger.Error("[IfStateInfoHandler] Wrong message type",
+			"type", common.TypeOf(h.request.Message))
+		return infra.MetricsErrInternal
+	}
+	peer, ok := h.request.Peer.(*snet.UDPAddr)
+	if !ok {
+		logger.Info("[IfStateInfoHandler] Invalid peer address type, expected *snet.UDPAddr",
+			"peer", h.request.Peer, "type", common.TypeOf(h.request.Peer))
+		return infra.MetricsErrInvalid
+	}
+	if !peer.IA.Equal(h.ia) || !peer.Path.IsEmpty() || peer.Host == nil {
+		logger.Info("[IfStateInfoHandler] Dropping infos from outside the local AS",
+			"peer", peer)
+		return infra.MetricsErrInvalid
+	}
+	logger.Debug("[IfStateInfoHandler] Received", "ifStateInfos", infos, "peer", peer)
+	for _, info := range infos.Infos {
+		if info.Active {
+			continue
+		}
+		intf := h.intfs.Get(info.IfID)
+		if intf == nil {
+			logger.Info("[IfStateInfoHandler] Unknown interface", "ifid", info.IfID)
+			continue
+		}
+		if !ownedBy(intf.TopoInfo(), peer.Host) {
+			logger.Info("[IfStateInfoHandler] Dropping info from router not owning interface",
+				"ifid", info.IfID, "peer", peer)
+			continue
+		}
+		logger.Info("[IfStateInfoHandler] Interface reported down", "ifid", info.IfID)
+		intf.Expire()
+	}
+	return infra.MetricsResultOk
+}
+
+// ownedBy returns whether src is the control address of the border router
+// that owns the interface.
+func ownedBy(info topology.IFInfo, src *net.UDPAddr) bool {
+	if info.CtrlAddrs == nil || info.CtrlAddrs.SCIONAddress == nil {
+		return false
+	}
+	ctrl := info.CtrlAddrs.SCIONAddress
+	return ctrl.IP.Equal(src.IP) && ctrl.Port == src.Port
+}
@@ -16,6 +16,7 @@ package ifstate
 
 import (
 	"context"
+	"net"
 	"sort"
 	"testing"
 
@@ -23,10 +24,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/mock_infra"
 	"github.com/scionproto/scion/go/lib/infra/modules/itopo/itopotest"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath"
 	"github.com/scionproto/scion/go/lib/topology"
 	"github.com/scionproto/scion/go/lib/xtest"
 )
@@ -126,3 +130,78 @@ func interfaces(t *testing.T, topoProvider topology.Provider,
 	}
 	return intfs
 }
+
+func TestInfoHandler(t *testing.T) {
+	topoProvider := itopotest.TopoProviderFromFile(t, "testdata/topology.json")
+	ia := topoProvider.Get().IA()
+	// br1-ff00_0_111-1 owns interfaces 101 and 104, br1-ff00_0_111-3 owns 102.
+	br1 := &net.UDPAddr{IP: net.ParseIP("127.0.0.81"), Port: 31029}
+	br3 := &net.UDPAddr{IP: net.ParseIP("127.0.0.83"), Port: 31033}
+
+	infos := &path_mgmt.IFStateInfos{
+		Infos: []*path_mgmt.IFStateInfo{
+			{IfID: 101, Active: false},
+			{IfID: 102, Active: false},
+			{IfID: 104, Active: true},
+			{IfID: 999, Active: false},
+		},
+	}
+	tests := map[string]struct {
+		Peer     net.Addr
+		Result   *infra.HandlerResult
+		Expired  []common.IFIDType
+		Retained []common.IFIDType
+	}{
+		"owning router": {
+			Peer:     &snet.UDPAddr{IA: ia, Host: br1},
+			Result:   infra.MetricsResultOk,
+			Expired:  []common.IFIDType{101},
+			Retained: []common.IFIDType{102, 104},
+		},
+		"other router": {
+			Peer:     &snet.UDPAddr{IA: ia, Host: br3},
+			Result:   infra.MetricsResultOk,
+			Expired:  []common.IFIDType{102},
+			Retained: []common.IFIDType{101, 104},
+		},
+		"wrong port": {
+			Peer:     &snet.UDPAddr{IA: ia, Host: &net.UDPAddr{IP: br1.IP, Port: 40000}},
+			Result:   infra.MetricsResultOk,
+			Retained: []common.IFIDType{101, 102, 104},
+		},
+		"remote IA": {
+			Peer:     &snet.UDPAddr{IA: xtest.MustParseIA("1-ff00:0:110"), Host: br1},
+			Result:   infra.MetricsErrInvalid,
+			Retained: []common.IFIDType{101, 102, 104},
+		},
+		"non-empty path": {
+			Peer: &snet.UDPAddr{
+				IA:   ia,
+				Host: br1,
+				Path: spath.New(common.RawBytes{0x01, 0x02, 0x03}),
+			},
+			Result:   infra.MetricsErrInvalid,
+			Retained: []common.IFIDType{101, 102, 104},
+		},
+		"TCP peer": {
+			Peer:     &net.TCPAddr{IP: br1.IP, Port: br1.Port},
+			Result:   infra.MetricsErrInvalid,
+			Retained: []common.IFIDType{101, 102, 104},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			intfs := NewInterfaces(topoProvider.Get().IFInfoMap(), Config{})
+			activateAll(intfs)
+			h := NewInfoHandler(ia, intfs)
+			req := infra.NewRequest(context.Background(), infos, nil, tc.Peer, 0)
+			assert.Equal(t, tc.Result, h.Handle(req))
+			for _, ifid := range tc.Expired {
+				assert.True(t, intfs.Get(ifid).Revoke(), "ifid %d", ifid)
+			}
+			for _, ifid := range tc.Retained {
+				assert.False(t, intfs.Get(ifid).Revoke(), "ifid %d", ifid)
+			}
+		})
+	}
+}
@@ -152,6 +152,15 @@ func (intf *Interface) Activate(remote common.IFIDType) State {
 	return prev
 }
 
+// Expire marks the interface as expired, such that the next call to Revoke
+// revokes it. It is used if the border router detects a link failure before
+// the keepalive timeout is reached.
+func (intf *Interface) Expire() {
+	intf.mu.Lock()
+	defer intf.mu.Unlock()
+	intf.lastActivate = time.Time{}
+}
+
 // Revoke checks whether the interface has not been activated for a certain
 // amount of time. If that is the case and the current state is active, the
 // state changes to Revoked. The times for last beacon origination and
@@ -0,0 +1,75 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/lib/prom"
+)
+
+// ColibriLabels define the labels attached to COLIBRI reservation metrics.
+type ColibriLabels struct {
+	Result string
+}
+
+// Labels returns the list of labels.
+func (l ColibriLabels) Labels() []string {
+	return []string{prom.LabelResult}
+}
+
+// Values returns the label values in the order defined by Labels.
+func (l ColibriLabels) Values() []string {
+	return []string{l.Result}
+}
+
+type colibri struct {
+	renewals, activations, cleanups *prometheus.CounterVec
+	expiredIndices                  prometheus.Counter
+}
+
+func newColibri() colibri {
+	ns, sub := CSNamespace, "colibri"
+	return colibri{
+		renewals: prom.NewCounterVecWithLabels(ns, sub, "segment_renewals_total",
+			"Total number of segment reservation renewals initiated.", ColibriLabels{}),
+		activations: prom.NewCounterVecWithLabels(ns, sub, "segment_activations_total",
+			"Total number of segment reservation index activations.", ColibriLabels{}),
+		cleanups: prom.NewCounterVecWithLabels(ns, sub, "expiry_cleanups_total",
+			"Total number of expired index clean ups.", ColibriLabels{}),
+		expiredIndices: prom.NewCounter(ns, sub, "expired_indices_total",
+			"Total number of expired reservation indices removed."),
+	}
+}
+
+// Renewals returns the segment reservation renewal counter.
+func (e *colibri) Renewals(l ColibriLabels) prometheus.Counter {
+	return e.renewals.WithLabelValues(l.Values()...)
+}
+
+// Activations returns the index activation counter.
+func (e *colibri) Activations(l ColibriLabels) prometheus.Counter {
+	return e.activations.WithLabelValues(l.Values()...)
+}
+
+// Cleanups returns the expired indices clean up counter.
+func (e *colibri) Cleanups(l ColibriLabels) prometheus.Counter {
+	return e.cleanups.WithLabelValues(l.Values()...)
+}
+
+// ExpiredIndices returns the counter of removed expired indices.
+func (e *colibri) ExpiredIndices() prometheus.Counter {
+	return e.expiredIndices
+}
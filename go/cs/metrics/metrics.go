@@ -95,6 +95,8 @@ const (
 var (
 	// Beaconing is the single-instance struct to get prometheus metrics or counters.
 	Beaconing beaconing
+	// Colibri is the single-instance struct to get COLIBRI prometheus counters.
+	Colibri colibri
 	// Ifstate is the single-instance struct to get prometheus metrics or counters.
 	Ifstate ifstate
 	// Keepalive is the single-instance struct to get keepalive prometheus counters.
@@ -136,6 +138,11 @@ func InitPSMetrics() {
 	PSRevocation = psNewRevocation()
 }
 
+// InitColibriMetrics initializes the metrics used by the COLIBRI service.
+func InitColibriMetrics() {
+	Colibri = newColibri()
+}
+
 // Revocation sources
 const (
 	RevSrcNotification = "notification"
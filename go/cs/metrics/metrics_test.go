@@ -32,8 +32,10 @@ func TestLabels(t *testing.T) {
 		metrics.BeaconingLabels{},
 		metrics.PropagatorLabels{},
 		metrics.RegistrarLabels{},
+		metrics.HiddenRegistrarLabels{},
 		metrics.TypeOnlyLabel{},
 		metrics.OriginatorLabels{},
+		metrics.ColibriLabels{},
 	}
 	for _, test := range tests {
 		promtest.CheckLabelsStruct(t, test)
@@ -39,6 +39,30 @@ func (l RegistrarLabels) Values() []string {
 	return []string{l.StartIA.String(), l.InIfID.String(), l.SegType, l.Result}
 }
 
+// HiddenRegistrarLabels define the labels attached to hidden path segment
+// registration metrics.
+type HiddenRegistrarLabels struct {
+	Group   string
+	Result  string
+	SegType string
+}
+
+// Labels returns the name of the labels in correct order.
+func (l HiddenRegistrarLabels) Labels() []string {
+	return []string{"group", "seg_type", prom.LabelResult}
+}
+
+// Values returns the values of the label in correct order.
+func (l HiddenRegistrarLabels) Values() []string {
+	return []string{l.Group, l.SegType, l.Result}
+}
+
+// WithResult returns the label set with the modfied result.
+func (l HiddenRegistrarLabels) WithResult(result string) HiddenRegistrarLabels {
+	l.Result = result
+	return l
+}
+
 // TypeOnlyLabel is used by clients to pass in a safe way labels
 // values to prometheus metric types (e.g. counter).
 type TypeOnlyLabel struct {
@@ -57,6 +81,7 @@ func (l TypeOnlyLabel) Values() []string {
 
 type registrar struct {
 	registeredBeacons, runtime, internalErrors *prometheus.CounterVec
+	hiddenRegistrations                        *prometheus.CounterVec
 }
 
 func newRegistrar() registrar {
@@ -69,6 +94,10 @@ func newRegistrar() registrar {
 			"Registrar total time spent on every periodic run", TypeOnlyLabel{"up"}),
 		internalErrors: prom.NewCounterVecWithLabels(ns, sub, "registrar_errors_total",
 			"Registrar total internal errors", TypeOnlyLabel{"up"}),
+		hiddenRegistrations: prom.NewCounterVecWithLabels(ns, sub,
+			"hidden_registrations_total",
+			"Number of segment registrations with hidden path services, per hidden path group",
+			HiddenRegistrarLabels{}),
 	}
 }
 
@@ -85,3 +114,7 @@ func (e *registrar) InternalErrorsWithType(s string) prometheus.Counter {
 	l := TypeOnlyLabel{SegType: s}
 	return e.internalErrors.WithLabelValues(l.Values()...)
 }
+
+func (e *registrar) HiddenRegistrations(l HiddenRegistrarLabels) prometheus.Counter {
+	return e.hiddenRegistrations.WithLabelValues(l.Values()...)
+}
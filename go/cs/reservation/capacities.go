@@ -0,0 +1,34 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reservation
+
+import (
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+// Capacities describes what a capacity description must offer. All values are in kbps.
+// The interface ID 0 represents this AS itself, i.e. reservations starting or ending here.
+type Capacities interface {
+	// IngressInterfaces returns the interfaces that have capacity to some egress interface.
+	IngressInterfaces() []common.IFIDType
+	// EgressInterfaces returns the interfaces that have capacity from some ingress interface.
+	EgressInterfaces() []common.IFIDType
+	// Capacity returns the capacity between the ingress and egress interfaces.
+	Capacity(from, to common.IFIDType) uint64
+	// CapacityIngress returns the total capacity of the ingress interface.
+	CapacityIngress(ingress common.IFIDType) uint64
+	// CapacityEgress returns the total capacity of the egress interface.
+	CapacityEgress(egress common.IFIDType) uint64
+}
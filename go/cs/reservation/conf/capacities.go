@@ -0,0 +1,130 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conf contains the configuration of the COLIBRI service, such as the capacity
+// matrix used by the admission algorithm.
+package conf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	base "github.com/scionproto/scion/go/cs/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// Capacities is the capacity matrix of this AS. It contains the capacity in kbps
+// available for COLIBRI between each ingress and each egress interface.
+// The interface ID 0 represents this AS.
+//
+// The JSON representation is an object keyed by ingress interface ID, whose values are
+// objects keyed by egress interface ID with the capacity as value, e.g.:
+//  {"0": {"1": 1000, "2": 1000}, "1": {"0": 1000, "2": 500}}
+type Capacities struct {
+	ingressInterfaces []common.IFIDType
+	egressInterfaces  []common.IFIDType
+	capIn             map[common.IFIDType]uint64
+	capEg             map[common.IFIDType]uint64
+	in2eg             map[common.IFIDType]map[common.IFIDType]uint64
+}
+
+var _ base.Capacities = (*Capacities)(nil)
+
+// NewCapacities creates and validates a capacity matrix from a map of ingress to egress
+// capacities.
+func NewCapacities(in2eg map[common.IFIDType]map[common.IFIDType]uint64) (*Capacities, error) {
+	c := &Capacities{
+		capIn: make(map[common.IFIDType]uint64),
+		capEg: make(map[common.IFIDType]uint64),
+		in2eg: make(map[common.IFIDType]map[common.IFIDType]uint64),
+	}
+	for in, egs := range in2eg {
+		for eg, capacity := range egs {
+			if in == eg && capacity != 0 {
+				return nil, serrors.New("capacity from an interface to itself must be zero",
+					"ifid", in, "capacity", capacity)
+			}
+			if capacity == 0 {
+				continue
+			}
+			if _, ok := c.in2eg[in]; !ok {
+				c.in2eg[in] = make(map[common.IFIDType]uint64)
+			}
+			c.in2eg[in][eg] = capacity
+			c.capIn[in] += capacity
+			c.capEg[eg] += capacity
+		}
+	}
+	c.ingressInterfaces = sortedKeys(c.capIn)
+	c.egressInterfaces = sortedKeys(c.capEg)
+	return c, nil
+}
+
+// LoadCapacities reads the capacity matrix from a JSON file.
+func LoadCapacities(path string) (*Capacities, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, serrors.WrapStr("unable to read capacities file", err, "path", path)
+	}
+	c := &Capacities{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, serrors.WrapStr("unable to parse capacities", err, "path", path)
+	}
+	return c, nil
+}
+
+// UnmarshalJSON parses and validates the capacity matrix.
+func (c *Capacities) UnmarshalJSON(b []byte) error {
+	var in2eg map[common.IFIDType]map[common.IFIDType]uint64
+	if err := json.Unmarshal(b, &in2eg); err != nil {
+		return err
+	}
+	parsed, err := NewCapacities(in2eg)
+	if err != nil {
+		return err
+	}
+	*c = *parsed
+	return nil
+}
+
+// MarshalJSON serializes the capacity matrix.
+func (c *Capacities) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.in2eg)
+}
+
+// IngressInterfaces returns the ingress interfaces with some capacity, sorted by ID.
+func (c *Capacities) IngressInterfaces() []common.IFIDType { return c.ingressInterfaces }
+
+// EgressInterfaces returns the egress interfaces with some capacity, sorted by ID.
+func (c *Capacities) EgressInterfaces() []common.IFIDType { return c.egressInterfaces }
+
+// Capacity returns the capacity from the ingress to the egress interface.
+func (c *Capacities) Capacity(from, to common.IFIDType) uint64 { return c.in2eg[from][to] }
+
+// CapacityIngress returns the sum of capacities from the ingress interface to any egress.
+func (c *Capacities) CapacityIngress(ingress common.IFIDType) uint64 { return c.capIn[ingress] }
+
+// CapacityEgress returns the sum of capacities from any ingress to the egress interface.
+func (c *Capacities) CapacityEgress(egress common.IFIDType) uint64 { return c.capEg[egress] }
+
+func sortedKeys(m map[common.IFIDType]uint64) []common.IFIDType {
+	keys := make([]common.IFIDType, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
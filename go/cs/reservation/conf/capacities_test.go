@@ -0,0 +1,75 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conf_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/reservation/conf"
+	"github.com/scionproto/scion/go/lib/common"
+)
+
+func TestLoadCapacities(t *testing.T) {
+	c, err := conf.LoadCapacities("testdata/capacities.json")
+	require.NoError(t, err)
+	require.Equal(t, []common.IFIDType{0, 1, 2}, c.IngressInterfaces())
+	require.Equal(t, []common.IFIDType{0, 1, 2}, c.EgressInterfaces())
+	require.Equal(t, uint64(500), c.Capacity(1, 2))
+	require.Equal(t, uint64(0), c.Capacity(2, 1))
+	require.Equal(t, uint64(0), c.Capacity(3, 1))
+	require.Equal(t, uint64(3000), c.CapacityIngress(0))
+	require.Equal(t, uint64(1500), c.CapacityIngress(1))
+	require.Equal(t, uint64(2000), c.CapacityEgress(0))
+	require.Equal(t, uint64(2500), c.CapacityEgress(2))
+
+	_, err = conf.LoadCapacities("testdata/nonexistent.json")
+	require.Error(t, err)
+}
+
+func TestCapacitiesJSON(t *testing.T) {
+	testCases := map[string]struct {
+		JSON    string
+		Invalid bool
+	}{
+		"valid": {
+			JSON: `{"0":{"1":10},"1":{"0":10}}`,
+		},
+		"self loop": {
+			JSON:    `{"1":{"1":10}}`,
+			Invalid: true,
+		},
+		"bad interface": {
+			JSON:    `{"a":{"1":10}}`,
+			Invalid: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := &conf.Capacities{}
+			err := json.Unmarshal([]byte(tc.JSON), c)
+			if tc.Invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			raw, err := json.Marshal(c)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.JSON, string(raw))
+		})
+	}
+}
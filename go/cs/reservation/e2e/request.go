@@ -19,6 +19,7 @@ import (
 
 	base "github.com/scionproto/scion/go/cs/reservation"
 	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/spath"
@@ -37,6 +38,8 @@ type SetupReq interface {
 type BaseSetupReq struct {
 	Metadata    base.RequestMetadata // information about the request (forwarding path)
 	ID          reservation.E2EID    // the ID this request refers to
+	Ingress     common.IFIDType      // the interface the reservation enters this AS
+	Egress      common.IFIDType      // the interface the reservation leaves this AS
 	timestamp   time.Time            // the mandatory timestamp
 	reservation *Reservation         // nil if no reservation yet
 }
@@ -81,3 +81,28 @@ func (r *Reservation) RemoveIndex(idx reservation.IndexNumber) error {
 	r.Indices = r.Indices[sliceIndex+1:]
 	return nil
 }
+
+// CleanupIndex removes the index and all indices created after it, so that the remaining
+// indices stay consecutive.
+func (r *Reservation) CleanupIndex(idx reservation.IndexNumber) error {
+	sliceIndex, err := base.FindIndex(r.Indices, idx)
+	if err != nil {
+		return err
+	}
+	r.Indices = r.Indices[:sliceIndex]
+	return nil
+}
+
+// MaxBlockedBW returns the maximum bandwidth in kbps that any of the indices has allocated.
+func (r *Reservation) MaxBlockedBW() uint64 {
+	if len(r.Indices) == 0 {
+		return 0
+	}
+	var max reservation.BWCls
+	for _, index := range r.Indices {
+		if index.AllocBW > max {
+			max = index.AllocBW
+		}
+	}
+	return max.ToKbps()
+}
@@ -80,6 +80,31 @@ func TestRemoveIndex(t *testing.T) {
 	require.Len(t, r.Indices, 0)
 }
 
+func TestCleanupIndex(t *testing.T) {
+	r := newReservation()
+	expTime := util.SecsToTime(1)
+	idx, _ := r.NewIndex(expTime)
+	idx2, _ := r.NewIndex(expTime)
+	r.NewIndex(expTime)
+	err := r.CleanupIndex(idx2)
+	require.NoError(t, err)
+	require.Len(t, r.Indices, 1)
+	require.Equal(t, idx, r.Indices[0].Idx)
+	err = r.CleanupIndex(idx2)
+	require.Error(t, err)
+}
+
+func TestMaxBlockedBW(t *testing.T) {
+	r := newReservation()
+	require.Equal(t, uint64(0), r.MaxBlockedBW())
+	expTime := util.SecsToTime(1)
+	r.NewIndex(expTime)
+	r.NewIndex(expTime)
+	r.Indices[0].AllocBW = 5
+	r.Indices[1].AllocBW = 3
+	require.Equal(t, reservation.BWCls(5).ToKbps(), r.MaxBlockedBW())
+}
+
 func newSegmentReservation(asidPath ...string) *segment.Reservation {
 	if len(asidPath) < 2 {
 		panic("at least source and destination in the path")
@@ -544,6 +544,12 @@ func testGetE2ERsvFromID(ctx context.Context, t *testing.T, db backend.DB) {
 		require.NoError(t, err)
 		require.Equal(t, r, rsv)
 	}
+	// not found
+	ID := reservation.E2EID{ASID: xtest.MustParseAS("ff00:0:1")}
+	binary.BigEndian.PutUint32(ID.Suffix[:], uint32(101))
+	rsv, err := db.GetE2ERsvFromID(ctx, &ID)
+	require.NoError(t, err)
+	require.Nil(t, rsv)
 	// with 8 indices starting at index number 14
 	r := newTestE2EReservation(t)
 	r.Indices = e2e.Indices{}
@@ -556,9 +562,9 @@ func testGetE2ERsvFromID(ctx context.Context, t *testing.T, db backend.DB) {
 		_, err := r.NewIndex(util.SecsToTime(i / 2))
 		require.NoError(t, err)
 	}
-	err := db.PersistE2ERsv(ctx, r)
+	err = db.PersistE2ERsv(ctx, r)
 	require.NoError(t, err)
-	rsv, err := db.GetE2ERsvFromID(ctx, &r.ID)
+	rsv, err = db.GetE2ERsvFromID(ctx, &r.ID)
 	require.NoError(t, err)
 	require.Equal(t, r, rsv)
 	// 16 indices
@@ -0,0 +1,35 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission contains the algorithms that decide whether a segment reservation
+// request is accepted in this AS, and with how much bandwidth.
+package admission
+
+import (
+	"context"
+
+	base "github.com/scionproto/scion/go/cs/reservation"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservationstorage/backend"
+)
+
+// Admitter specifies what an admission entity has to implement to govern the segment admission.
+type Admitter interface {
+	// AdmitRsv decides if the request is admitted. The allocation bead of this AS is always
+	// appended to the allocation trail of the request, also when the request is not admitted.
+	// An error is returned if the request cannot be admitted.
+	AdmitRsv(ctx context.Context, x backend.TransitOnly, req *segment.SetupReq) error
+	// Capacities returns the capacity matrix used by this admitter.
+	Capacities() base.Capacities
+}
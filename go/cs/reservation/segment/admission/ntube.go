@@ -0,0 +1,265 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"context"
+	"math"
+
+	base "github.com/scionproto/scion/go/cs/reservation"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservationstorage/backend"
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// DefaultDelta is the default fraction of the free bandwidth that a single request can get.
+const DefaultDelta = 1.0
+
+// NTube implements the N-Tube admission algorithm. Every decision depends only on the
+// capacities of the AS and on the reservations already present in the DB, thus no extra
+// state is kept between requests.
+//
+// The bandwidth granted to a request is the minimum of the available and the ideal bandwidth.
+// The available bandwidth is the free capacity at the ingress and egress interfaces,
+// scaled by Delta. The ideal bandwidth is the fair share of the egress capacity: it is split
+// first between the ingress interfaces (tube ratio) and then between the source ASes in the
+// same ingress-egress pair (link ratio), proportionally to their capped demands.
+type NTube struct {
+	Caps base.Capacities
+	// Delta is the fraction of the free bandwidth a request can obtain, in (0,1].
+	Delta float64
+}
+
+var _ Admitter = (*NTube)(nil)
+
+// Capacities returns the capacity matrix.
+func (a *NTube) Capacities() base.Capacities {
+	return a.Caps
+}
+
+// AdmitRsv admits a segment reservation request, appending the allocation bead of this AS.
+func (a *NTube) AdmitRsv(ctx context.Context, x backend.TransitOnly,
+	req *segment.SetupReq) error {
+
+	if req.MinBW > req.MaxBW {
+		return serrors.New("invalid request, min_bw greater than max_bw",
+			"min_bw", req.MinBW, "max_bw", req.MaxBW)
+	}
+	if a.Caps.Capacity(req.Ingress, req.Egress) == 0 {
+		req.AllocTrail = append(req.AllocTrail, reservation.AllocationBead{})
+		return serrors.New("no capacity between interfaces",
+			"ingress", req.Ingress, "egress", req.Egress)
+	}
+	rsvs, err := a.otherReservations(ctx, x, req)
+	if err != nil {
+		return serrors.WrapStr("cannot obtain reservations for admission", err)
+	}
+	avail := a.availableBW(rsvs, req)
+	ideal := a.idealBW(rsvs, req)
+	maxAlloc := reservation.BWClsFromBW(minBW(avail, ideal))
+	if maxAlloc > reservation.BWCls(req.MaxBW) {
+		maxAlloc = reservation.BWCls(req.MaxBW)
+	}
+	req.AllocTrail = append(req.AllocTrail, reservation.AllocationBead{
+		AllocBW: uint8(maxAlloc),
+		MaxBW:   uint8(reservation.BWClsFromBW(avail)),
+	})
+	if maxAlloc < reservation.BWCls(req.MinBW) {
+		return serrors.New("admission denied", "max_alloc", maxAlloc, "min_bw", req.MinBW,
+			"available_kbps", avail, "ideal_kbps", ideal)
+	}
+	return nil
+}
+
+// otherReservations returns all reservations traversing this AS, excluding the one
+// in the request.
+func (a *NTube) otherReservations(ctx context.Context, x backend.TransitOnly,
+	req *segment.SetupReq) ([]*segment.Reservation, error) {
+
+	ingresses := a.Caps.IngressInterfaces()
+	rsvs := make([]*segment.Reservation, 0)
+	for i := range ingresses {
+		list, err := x.GetSegmentRsvsFromIFPair(ctx, &ingresses[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, rsv := range list {
+			if rsv.ID != req.ID {
+				rsvs = append(rsvs, rsv)
+			}
+		}
+	}
+	return rsvs, nil
+}
+
+// availableBW returns the bandwidth that is not blocked by any other reservation at both
+// the ingress and egress interfaces of the request, multiplied by Delta.
+func (a *NTube) availableBW(rsvs []*segment.Reservation, req *segment.SetupReq) uint64 {
+	var blockedIn, blockedEg uint64
+	for _, rsv := range rsvs {
+		if rsv.Ingress == req.Ingress {
+			blockedIn += rsv.MaxBlockedBW()
+		}
+		if rsv.Egress == req.Egress {
+			blockedEg += rsv.MaxBlockedBW()
+		}
+	}
+	freeIn := subBW(a.Caps.CapacityIngress(req.Ingress), blockedIn)
+	freeEg := subBW(a.Caps.CapacityEgress(req.Egress), blockedEg)
+	return uint64(float64(minBW(freeIn, freeEg)) * a.delta())
+}
+
+// idealBW returns the fair share of the egress capacity for this request.
+func (a *NTube) idealBW(rsvs []*segment.Reservation, req *segment.SetupReq) uint64 {
+	dems := a.computeDemands(rsvs, req)
+	egCap := float64(a.Caps.CapacityEgress(req.Egress))
+	return uint64(egCap * dems.tubeRatio(a.Caps, req) * dems.linkRatio(rsvs, req))
+}
+
+func (a *NTube) delta() float64 {
+	if a.Delta <= 0 || a.Delta > 1 {
+		return DefaultDelta
+	}
+	return a.Delta
+}
+
+type ifPair struct {
+	in common.IFIDType
+	eg common.IFIDType
+}
+
+// demands contains the demands of every source AS per interface pair, already capped to the
+// capacities of the interfaces, and the derived scaling factors.
+type demands struct {
+	perPair    map[addr.AS]map[ifPair]uint64
+	inScalFctr map[addr.AS]map[common.IFIDType]float64
+	egScalFctr map[addr.AS]map[common.IFIDType]float64
+}
+
+// computeDemands computes the demands and scaling factors, adding the request's demand.
+func (a *NTube) computeDemands(rsvs []*segment.Reservation, req *segment.SetupReq) *demands {
+	d := &demands{
+		perPair:    make(map[addr.AS]map[ifPair]uint64),
+		inScalFctr: make(map[addr.AS]map[common.IFIDType]float64),
+		egScalFctr: make(map[addr.AS]map[common.IFIDType]float64),
+	}
+	add := func(src addr.AS, in, eg common.IFIDType, dem uint64) {
+		dem = minBW(dem, minBW(a.Caps.CapacityIngress(in), a.Caps.CapacityEgress(eg)))
+		if _, ok := d.perPair[src]; !ok {
+			d.perPair[src] = make(map[ifPair]uint64)
+		}
+		d.perPair[src][ifPair{in: in, eg: eg}] += dem
+	}
+	for _, rsv := range rsvs {
+		add(rsv.ID.ASID, rsv.Ingress, rsv.Egress, rsv.MaxRequestedBW())
+	}
+	add(req.ID.ASID, req.Ingress, req.Egress, reservation.BWCls(req.MaxBW).ToKbps())
+
+	for src, pairs := range d.perPair {
+		demIn := make(map[common.IFIDType]uint64)
+		demEg := make(map[common.IFIDType]uint64)
+		for pair, dem := range pairs {
+			demIn[pair.in] += dem
+			demEg[pair.eg] += dem
+		}
+		d.inScalFctr[src] = make(map[common.IFIDType]float64)
+		d.egScalFctr[src] = make(map[common.IFIDType]float64)
+		for in, dem := range demIn {
+			d.inScalFctr[src][in] = scalingFactor(a.Caps.CapacityIngress(in), dem)
+		}
+		for eg, dem := range demEg {
+			d.egScalFctr[src][eg] = scalingFactor(a.Caps.CapacityEgress(eg), dem)
+		}
+	}
+	return d
+}
+
+// transitDemand returns the sum of the adjusted demands of all sources for the pair.
+func (d *demands) transitDemand(pair ifPair) uint64 {
+	var sum float64
+	for src, pairs := range d.perPair {
+		dem, ok := pairs[pair]
+		if !ok {
+			continue
+		}
+		scal := math.Min(d.inScalFctr[src][pair.in], d.egScalFctr[src][pair.eg])
+		sum += scal * float64(dem)
+	}
+	return uint64(sum)
+}
+
+// tubeRatio is the proportion of the egress capacity that corresponds to the ingress
+// interface of the request.
+func (d *demands) tubeRatio(caps base.Capacities, req *segment.SetupReq) float64 {
+	numerator := minBW(caps.CapacityIngress(req.Ingress),
+		d.transitDemand(ifPair{in: req.Ingress, eg: req.Egress}))
+	var denominator uint64
+	for _, in := range caps.IngressInterfaces() {
+		denominator += minBW(caps.CapacityIngress(in),
+			d.transitDemand(ifPair{in: in, eg: req.Egress}))
+	}
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// linkRatio is the proportion of the tube (ingress-egress pair) that corresponds to the
+// request, given the bandwidth already allocated to each source in the tube.
+func (d *demands) linkRatio(rsvs []*segment.Reservation, req *segment.SetupReq) float64 {
+	prevBW := req.PrevBW()
+	srcAlloc := map[addr.AS]uint64{
+		req.ID.ASID: prevBW,
+	}
+	for _, rsv := range rsvs {
+		if rsv.Ingress == req.Ingress && rsv.Egress == req.Egress {
+			srcAlloc[rsv.ID.ASID] += rsv.MaxBlockedBW()
+		}
+	}
+	numerator := d.egScalFctr[req.ID.ASID][req.Egress] * float64(prevBW)
+	var denominator float64
+	for src, alloc := range srcAlloc {
+		denominator += d.egScalFctr[src][req.Egress] * float64(alloc)
+	}
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// scalingFactor returns the proportion of the demand that fits in the capacity.
+func scalingFactor(capacity, demand uint64) float64 {
+	if demand == 0 {
+		return 1
+	}
+	return float64(minBW(capacity, demand)) / float64(demand)
+}
+
+func minBW(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func subBW(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
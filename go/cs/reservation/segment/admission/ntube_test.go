@@ -0,0 +1,208 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/reservation/conf"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservation/segment/admission"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestNTubeAdmitRsv(t *testing.T) {
+	testCases := map[string]struct {
+		Delta    float64
+		Existing []*segment.Reservation
+		Ingress  common.IFIDType
+		Egress   common.IFIDType
+		MinBW    uint8
+		MaxBW    uint8
+		Trail    []reservation.AllocationBead
+		Admitted bool
+		Bead     reservation.AllocationBead
+	}{
+		"empty": {
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    1,
+			MaxBW:    13,
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 12, MaxBW: 12},
+		},
+		"limited by max bw": {
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    1,
+			MaxBW:    5,
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 5, MaxBW: 12},
+		},
+		"delta": {
+			Delta:    0.5,
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    1,
+			MaxBW:    13,
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 10, MaxBW: 10},
+		},
+		"no capacity": {
+			Ingress:  2,
+			Egress:   1,
+			MinBW:    1,
+			MaxBW:    13,
+			Admitted: false,
+			Bead:     reservation.AllocationBead{},
+		},
+		"same ingress used": {
+			Existing: []*segment.Reservation{newRsv(t, "ff00:0:2", "00000001", 1, 2, 12, 13)},
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    9,
+			MaxBW:    13,
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 9, MaxBW: 9},
+		},
+		"same ingress used denied": {
+			Existing: []*segment.Reservation{newRsv(t, "ff00:0:2", "00000001", 1, 2, 12, 13)},
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    10,
+			MaxBW:    13,
+			Admitted: false,
+			Bead:     reservation.AllocationBead{AllocBW: 9, MaxBW: 9},
+		},
+		"renewal ignores own reservation": {
+			Existing: []*segment.Reservation{newRsv(t, "ff00:0:1", "00000001", 1, 2, 12, 13)},
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    12,
+			MaxBW:    13,
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 12, MaxBW: 12},
+		},
+		"fair share between ingress interfaces": {
+			Existing: []*segment.Reservation{newRsv(t, "ff00:0:2", "00000001", 3, 2, 5, 13)},
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    1,
+			MaxBW:    13,
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 12, MaxBW: 12},
+		},
+		"previous ASes allocated less": {
+			Ingress:  1,
+			Egress:   2,
+			MinBW:    1,
+			MaxBW:    13,
+			Trail:    []reservation.AllocationBead{{AllocBW: 7, MaxBW: 13}},
+			Admitted: true,
+			Bead:     reservation.AllocationBead{AllocBW: 12, MaxBW: 12},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			caps, err := conf.NewCapacities(map[common.IFIDType]map[common.IFIDType]uint64{
+				1: {2: 1000},
+				3: {2: 1000},
+			})
+			require.NoError(t, err)
+			a := &admission.NTube{Caps: caps, Delta: tc.Delta}
+			req := newRequest(t, tc.Ingress, tc.Egress, tc.MinBW, tc.MaxBW)
+			req.AllocTrail = tc.Trail
+			err = a.AdmitRsv(context.Background(), &fakeDB{rsvs: tc.Existing}, req)
+			if tc.Admitted {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+			require.Len(t, req.AllocTrail, len(tc.Trail)+1)
+			require.Equal(t, tc.Bead, req.AllocTrail[len(req.AllocTrail)-1])
+		})
+	}
+}
+
+func TestNTubeInvalidRequest(t *testing.T) {
+	caps, err := conf.NewCapacities(map[common.IFIDType]map[common.IFIDType]uint64{
+		1: {2: 1000},
+	})
+	require.NoError(t, err)
+	a := &admission.NTube{Caps: caps}
+	req := newRequest(t, 1, 2, 10, 5)
+	err = a.AdmitRsv(context.Background(), &fakeDB{}, req)
+	require.Error(t, err)
+	require.Empty(t, req.AllocTrail)
+}
+
+type fakeDB struct {
+	rsvs []*segment.Reservation
+}
+
+func (db *fakeDB) GetSegmentRsvsFromIFPair(_ context.Context, ingress, egress *common.IFIDType) (
+	[]*segment.Reservation, error) {
+
+	var rsvs []*segment.Reservation
+	for _, rsv := range db.rsvs {
+		if (ingress == nil || *ingress == rsv.Ingress) && (egress == nil || *egress == rsv.Egress) {
+			rsvs = append(rsvs, rsv)
+		}
+	}
+	return rsvs, nil
+}
+
+func newID(t *testing.T, as, suffix string) reservation.SegmentID {
+	id, err := reservation.NewSegmentID(xtest.MustParseAS(as), xtest.MustParseHexString(suffix))
+	require.NoError(t, err)
+	return *id
+}
+
+func newRequest(t *testing.T, ingress, egress common.IFIDType,
+	minBW, maxBW uint8) *segment.SetupReq {
+
+	return &segment.SetupReq{
+		Request: segment.Request{
+			ID:      newID(t, "ff00:0:1", "00000001"),
+			Ingress: ingress,
+			Egress:  egress,
+		},
+		MinBW: minBW,
+		MaxBW: maxBW,
+	}
+}
+
+func newRsv(t *testing.T, as, suffix string, ingress, egress common.IFIDType,
+	allocBW, maxBW reservation.BWCls) *segment.Reservation {
+
+	rsv := segment.NewReservation()
+	rsv.ID = newID(t, as, suffix)
+	rsv.Ingress = ingress
+	rsv.Egress = egress
+	_, err := rsv.NewIndexFromToken(&reservation.Token{
+		InfoField: reservation.InfoField{
+			ExpirationTick: reservation.TickFromTime(time.Now().Add(time.Minute)),
+			BWCls:          allocBW,
+		},
+	}, 1, maxBW)
+	require.NoError(t, err)
+	return rsv
+}
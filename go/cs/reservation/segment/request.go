@@ -77,6 +77,7 @@ func NewRequest(ts time.Time, ID *colibri_mgmt.SegmentReservationID,
 // This same type is used for renewal of the segment reservation.
 type SetupReq struct {
 	Request
+	InfoField  reservation.InfoField // from the hop by hop extension, not the ctrl message
 	MinBW      uint8
 	MaxBW      uint8
 	SplitCls   uint8
@@ -93,6 +94,11 @@ func NewSetupReqFromCtrlMsg(setup *colibri_mgmt.SegmentSetup, ts time.Time,
 	if err != nil {
 		return nil, serrors.WrapStr("cannot construct segment setup request", err)
 	}
+	return NewSetupReq(r, setup), nil
+}
+
+// NewSetupReq constructs a SetupReq for an existing Request from the setup control message.
+func NewSetupReq(r *Request, setup *colibri_mgmt.SegmentSetup) *SetupReq {
 	s := SetupReq{
 		Request:    *r,
 		MinBW:      setup.MinBW,
@@ -108,7 +114,19 @@ func NewSetupReqFromCtrlMsg(setup *colibri_mgmt.SegmentSetup, ts time.Time,
 			MaxBW:   ab.MaxBW,
 		}
 	}
-	return &s, nil
+	return &s
+}
+
+// PrevBW returns the bandwidth in kbps granted to this request by the previous ASes in the path,
+// i.e. the minimum of the allocation trail and the maximum requested bandwidth.
+func (r *SetupReq) PrevBW() uint64 {
+	bw := reservation.BWCls(r.MaxBW)
+	for _, bead := range r.AllocTrail {
+		if reservation.BWCls(bead.AllocBW) < bw {
+			bw = reservation.BWCls(bead.AllocBW)
+		}
+	}
+	return bw.ToKbps()
 }
 
 // ToCtrlMsg creates a new segment setup control message filled with the information from here.
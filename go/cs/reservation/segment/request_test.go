@@ -56,6 +56,13 @@ func TestRequestToCtrlMsg(t *testing.T) {
 	require.Equal(t, ctrlMsg, anotherCtrlMsg)
 }
 
+func TestSetupReqPrevBW(t *testing.T) {
+	r := segment.SetupReq{MaxBW: 10}
+	require.Equal(t, reservation.BWCls(10).ToKbps(), r.PrevBW())
+	r.AllocTrail = []reservation.AllocationBead{{AllocBW: 8, MaxBW: 12}, {AllocBW: 9, MaxBW: 9}}
+	require.Equal(t, reservation.BWCls(8).ToKbps(), r.PrevBW())
+}
+
 func TestNewTelesRequestFromCtrlMsg(t *testing.T) {
 	ctrlMsg := newTelesSetup()
 	ts := util.SecsToTime(1)
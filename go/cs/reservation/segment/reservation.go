@@ -206,3 +206,46 @@ func (r *Reservation) RemoveIndex(idx reservation.IndexNumber) error {
 	}
 	return nil
 }
+
+// CleanupIndex removes a non active index, and all indices created after it, so that the
+// remaining indices stay consecutive.
+func (r *Reservation) CleanupIndex(idx reservation.IndexNumber) error {
+	sliceIndex, err := base.FindIndex(r.Indices, idx)
+	if err != nil {
+		return err
+	}
+	if sliceIndex <= r.activeIndex {
+		return serrors.New("cannot clean up an active or already activated index",
+			"index_number", idx)
+	}
+	r.Indices = r.Indices[:sliceIndex]
+	return nil
+}
+
+// MaxBlockedBW returns the maximum bandwidth in kbps that any of the indices has allocated.
+func (r *Reservation) MaxBlockedBW() uint64 {
+	if len(r.Indices) == 0 {
+		return 0
+	}
+	var max reservation.BWCls
+	for _, index := range r.Indices {
+		if index.AllocBW > max {
+			max = index.AllocBW
+		}
+	}
+	return max.ToKbps()
+}
+
+// MaxRequestedBW returns the maximum bandwidth in kbps that any of the indices has requested.
+func (r *Reservation) MaxRequestedBW() uint64 {
+	if len(r.Indices) == 0 {
+		return 0
+	}
+	var max reservation.BWCls
+	for _, index := range r.Indices {
+		if index.MaxBW > max {
+			max = index.MaxBW
+		}
+	}
+	return max.ToKbps()
+}
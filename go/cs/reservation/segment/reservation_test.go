@@ -226,3 +226,42 @@ func TestRemoveIndex(t *testing.T) {
 	err = r.Validate()
 	require.NoError(t, err)
 }
+
+func TestCleanupIndex(t *testing.T) {
+	r := segmenttest.NewReservation()
+	expTime := util.SecsToTime(1)
+	idx, _ := r.NewIndexAtSource(expTime, 0, 0, 0, 0, reservation.CorePath)
+	idx2, _ := r.NewIndexAtSource(expTime, 0, 0, 0, 0, reservation.CorePath)
+	idx3, _ := r.NewIndexAtSource(expTime, 0, 0, 0, 0, reservation.CorePath)
+	err := r.SetIndexConfirmed(idx)
+	require.NoError(t, err)
+	err = r.SetIndexActive(idx)
+	require.NoError(t, err)
+	err = r.CleanupIndex(idx)
+	require.Error(t, err)
+	err = r.CleanupIndex(idx3)
+	require.NoError(t, err)
+	require.Len(t, r.Indices, 2)
+	err = r.Validate()
+	require.NoError(t, err)
+	// cleaning an index also cleans the newer ones
+	r.NewIndexAtSource(expTime, 0, 0, 0, 0, reservation.CorePath)
+	require.Len(t, r.Indices, 3)
+	err = r.CleanupIndex(idx2)
+	require.NoError(t, err)
+	require.Len(t, r.Indices, 1)
+	require.Equal(t, idx, r.ActiveIndex().Idx)
+	err = r.CleanupIndex(idx2)
+	require.Error(t, err)
+}
+
+func TestMaxBW(t *testing.T) {
+	r := segmenttest.NewReservation()
+	require.Equal(t, uint64(0), r.MaxBlockedBW())
+	require.Equal(t, uint64(0), r.MaxRequestedBW())
+	expTime := util.SecsToTime(1)
+	r.NewIndexAtSource(expTime, 1, 5, 3, 0, reservation.CorePath)
+	r.NewIndexAtSource(expTime, 1, 7, 1, 0, reservation.CorePath)
+	require.Equal(t, reservation.BWCls(3).ToKbps(), r.MaxBlockedBW())
+	require.Equal(t, reservation.BWCls(7).ToKbps(), r.MaxRequestedBW())
+}
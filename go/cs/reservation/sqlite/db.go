@@ -571,7 +571,10 @@ func getE2ERsvFromID(ctx context.Context, x *sql.Tx, ID *reservation.E2EID) (
 	var rowID int
 	const query = `SELECT ROWID FROM e2e_reservation WHERE reservation_id = ?`
 	err := x.QueryRowContext(ctx, query, ID.ToRaw()).Scan(&rowID)
-	if err != nil {
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
 		return nil, err
 	}
 	// read indices
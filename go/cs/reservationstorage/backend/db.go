@@ -67,7 +67,8 @@ type ReserverAndTransit interface {
 	// Used on schedule.
 	DeleteExpiredIndices(ctx context.Context, now time.Time) (int, error)
 
-	// GetE2ERsvFromID finds the end to end resevation given its ID.
+	// GetE2ERsvFromID finds the end to end resevation given its ID. It returns nil if the
+	// reservation does not exist.
 	GetE2ERsvFromID(ctx context.Context, ID *reservation.E2EID) (*e2e.Reservation, error)
 	// GetE2ERsvsOnSegRsv returns the e2e reservations running on top of a given segment one.
 	GetE2ERsvsOnSegRsv(ctx context.Context, ID *reservation.SegmentID) ([]*e2e.Reservation, error)
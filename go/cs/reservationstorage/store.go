@@ -24,8 +24,11 @@ import (
 
 // Store is the interface to interact with the reservation store.
 type Store interface {
-	AdmitSegmentReservation(ctx context.Context, req sgt.SetupReq) error
+	// AdmitSegmentReservation decides if the segment setup or renewal request is admitted,
+	// and stores the new index if so. The allocation trail of the request is extended.
+	AdmitSegmentReservation(ctx context.Context, req *sgt.SetupReq) error
 	ConfirmSegmentReservation(ctx context.Context, id rsv.SegmentID, idx rsv.IndexNumber) error
+	ActivateSegmentReservation(ctx context.Context, id rsv.SegmentID, idx rsv.IndexNumber) error
 	CleanupSegmentReservation(ctx context.Context, id rsv.SegmentID, idx rsv.IndexNumber) error
 	TearDownSegmentReservation(ctx context.Context, id rsv.SegmentID, idx rsv.IndexNumber) error
 	AdmitE2EReservation(ctx context.Context, req e2e.SetupReq) error
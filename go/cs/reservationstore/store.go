@@ -0,0 +1,319 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reservationstore contains the reservation store of the COLIBRI service.
+package reservationstore
+
+import (
+	"context"
+
+	"github.com/scionproto/scion/go/cs/reservation/e2e"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservation/segment/admission"
+	"github.com/scionproto/scion/go/cs/reservationstorage"
+	"github.com/scionproto/scion/go/cs/reservationstorage/backend"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// Store is the reservation store. Reservations are kept in a backend.DB, and the admission of
+// segment reservations is decided by an admission.Admitter.
+type Store struct {
+	db       backend.DB
+	admitter admission.Admitter
+}
+
+var _ reservationstorage.Store = (*Store)(nil)
+
+// NewStore creates a new reservation store.
+func NewStore(db backend.DB, admitter admission.Admitter) *Store {
+	return &Store{
+		db:       db,
+		admitter: admitter,
+	}
+}
+
+// AdmitSegmentReservation receives a setup/renewal request to admit a segment reservation.
+// If the request is admitted, a new temporary index is added to the reservation, with the
+// index number and expiration from the info field of the request. The reservation is
+// created if it did not exist. Reservations that start in this AS must exist beforehand.
+func (s *Store) AdmitSegmentReservation(ctx context.Context, req *segment.SetupReq) error {
+	return s.doInTx(ctx, func(tx backend.Transaction) error {
+		rsv, err := tx.GetSegmentRsvFromID(ctx, &req.ID)
+		if err != nil {
+			return serrors.WrapStr("cannot obtain segment reservation", err, "id", req.ID)
+		}
+		if rsv == nil {
+			if req.Ingress == 0 {
+				return serrors.New("unknown segment reservation starting in this AS",
+					"id", req.ID)
+			}
+			rsv = segment.NewReservation()
+			rsv.ID = req.ID
+			rsv.Ingress = req.Ingress
+			rsv.Egress = req.Egress
+			rsv.PathEndProps = req.PathProps
+			rsv.TrafficSplit = reservation.SplitCls(req.SplitCls)
+		} else if rsv.Ingress != req.Ingress || rsv.Egress != req.Egress {
+			return serrors.New("request interfaces differ from the reservation ones",
+				"id", req.ID, "rsv_ingress", rsv.Ingress, "rsv_egress", rsv.Egress,
+				"req_ingress", req.Ingress, "req_egress", req.Egress)
+		}
+		req.Reservation = rsv
+		if err := s.admitter.AdmitRsv(ctx, tx, req); err != nil {
+			return serrors.WrapStr("segment reservation not admitted", err, "id", req.ID)
+		}
+		allocBW := reservation.BWCls(req.AllocTrail[len(req.AllocTrail)-1].AllocBW)
+		minBW, maxBW := reservation.BWCls(req.MinBW), reservation.BWCls(req.MaxBW)
+		if len(rsv.Path) > 0 {
+			// this AS originates the reservation, and thus creates the token.
+			idx, err := rsv.NewIndexAtSource(req.InfoField.ExpirationTick.ToTime(),
+				minBW, maxBW, allocBW, req.InfoField.RLC, req.InfoField.PathType)
+			if err != nil {
+				return serrors.WrapStr("cannot create new index", err, "id", req.ID)
+			}
+			if idx != req.InfoField.Idx {
+				return serrors.New("unexpected index number in request", "id", req.ID,
+					"expected", idx, "actual", req.InfoField.Idx)
+			}
+		} else {
+			tok := &reservation.Token{InfoField: req.InfoField}
+			tok.BWCls = allocBW
+			if _, err := rsv.NewIndexFromToken(tok, minBW, maxBW); err != nil {
+				return serrors.WrapStr("cannot create new index", err, "id", req.ID)
+			}
+		}
+		return tx.PersistSegmentRsv(ctx, rsv)
+	})
+}
+
+// ConfirmSegmentReservation changes the state of an index from temporary to confirmed.
+func (s *Store) ConfirmSegmentReservation(ctx context.Context, id reservation.SegmentID,
+	idx reservation.IndexNumber) error {
+
+	return s.modifySegmentRsv(ctx, id, func(rsv *segment.Reservation) (bool, error) {
+		return true, rsv.SetIndexConfirmed(idx)
+	})
+}
+
+// ActivateSegmentReservation changes the state of a confirmed index to active. All the indices
+// prior to it are removed.
+func (s *Store) ActivateSegmentReservation(ctx context.Context, id reservation.SegmentID,
+	idx reservation.IndexNumber) error {
+
+	return s.modifySegmentRsv(ctx, id, func(rsv *segment.Reservation) (bool, error) {
+		return true, rsv.SetIndexActive(idx)
+	})
+}
+
+// CleanupSegmentReservation deletes an index from a segment reservation. The reservation
+// is removed if it is left without indices.
+func (s *Store) CleanupSegmentReservation(ctx context.Context, id reservation.SegmentID,
+	idx reservation.IndexNumber) error {
+
+	return s.modifySegmentRsv(ctx, id, func(rsv *segment.Reservation) (bool, error) {
+		if err := rsv.CleanupIndex(idx); err != nil {
+			return false, err
+		}
+		return len(rsv.Indices) > 0, nil
+	})
+}
+
+// TearDownSegmentReservation removes a whole segment reservation.
+func (s *Store) TearDownSegmentReservation(ctx context.Context, id reservation.SegmentID,
+	idx reservation.IndexNumber) error {
+
+	return s.modifySegmentRsv(ctx, id, func(rsv *segment.Reservation) (bool, error) {
+		if _, err := rsv.Index(idx); err != nil {
+			return false, err
+		}
+		return false, nil
+	})
+}
+
+// AdmitE2EReservation will attempt to admit an e2e reservation. The first setup of a
+// reservation creates it, stitched on the segment reservations that the request traverses in
+// this AS. A new index is admitted only if all segment reservations have enough allocated
+// bandwidth left for it. Failed requests are not admitted nor stored, and return an error.
+func (s *Store) AdmitE2EReservation(ctx context.Context, req e2e.SetupReq) error {
+	var success *e2e.SuccessSetupReq
+	switch r := req.(type) {
+	case *e2e.SuccessSetupReq:
+		success = r
+	case *e2e.FailureSetupReq:
+		return serrors.New("failed e2e setup request cannot be admitted", "id", r.ID)
+	default:
+		return serrors.New("unknown e2e setup request type", "type", common.TypeOf(req))
+	}
+	return s.doInTx(ctx, func(tx backend.Transaction) error {
+		rsv, err := tx.GetE2ERsvFromID(ctx, &success.ID)
+		if err != nil {
+			return serrors.WrapStr("cannot obtain e2e reservation", err, "id", success.ID)
+		}
+		if rsv == nil {
+			segRsvs, err := e2eSegmentRsvs(ctx, tx, success.Ingress, success.Egress)
+			if err != nil {
+				return serrors.WrapStr("cannot create e2e reservation", err, "id", success.ID)
+			}
+			rsv = &e2e.Reservation{
+				ID:                  success.ID,
+				SegmentReservations: segRsvs,
+			}
+		}
+		requested := success.Token.BWCls.ToKbps()
+		for _, segRsv := range rsv.SegmentReservations {
+			index := segRsv.ActiveIndex()
+			if index == nil {
+				return serrors.New("segment reservation has no active index",
+					"e2e_id", rsv.ID, "segment_id", segRsv.ID)
+			}
+			others, err := tx.GetE2ERsvsOnSegRsv(ctx, &segRsv.ID)
+			if err != nil {
+				return serrors.WrapStr("cannot obtain e2e reservations", err,
+					"segment_id", segRsv.ID)
+			}
+			var used uint64
+			for _, other := range others {
+				if other.ID != rsv.ID {
+					used += other.MaxBlockedBW()
+				}
+			}
+			if used+requested > index.AllocBW.ToKbps() {
+				return serrors.New("e2e reservation not admitted, not enough bandwidth",
+					"e2e_id", rsv.ID, "segment_id", segRsv.ID, "requested_kbps", requested,
+					"used_kbps", used, "segment_kbps", index.AllocBW.ToKbps())
+			}
+		}
+		idx, err := rsv.NewIndex(success.Token.ExpirationTick.ToTime())
+		if err != nil {
+			return serrors.WrapStr("cannot create new e2e index", err, "id", rsv.ID)
+		}
+		if idx != success.Token.Idx {
+			return serrors.New("unexpected index number in request", "id", rsv.ID,
+				"expected", idx, "actual", success.Token.Idx)
+		}
+		tok := success.Token
+		index := &rsv.Indices[len(rsv.Indices)-1]
+		index.AllocBW = tok.BWCls
+		index.Token = &tok
+		return tx.PersistE2ERsv(ctx, rsv)
+	})
+}
+
+// e2eSegmentRsvs returns the segment reservations that an e2e reservation entering this AS at
+// ingress and leaving it at egress is stitched on. Only segment reservations with an active
+// index are considered. If no segment reservation traverses this AS with both interfaces, this
+// AS stitches the segment reservation ending at ingress with the one starting at egress.
+func e2eSegmentRsvs(ctx context.Context, tx backend.Transaction,
+	ingress, egress common.IFIDType) ([]*segment.Reservation, error) {
+
+	rsv, err := activeSegmentRsv(ctx, tx, ingress, egress)
+	if err != nil {
+		return nil, err
+	}
+	if rsv != nil {
+		return []*segment.Reservation{rsv}, nil
+	}
+	if ingress == 0 || egress == 0 {
+		return nil, serrors.New("no active segment reservation", "ingress", ingress,
+			"egress", egress)
+	}
+	in, err := activeSegmentRsv(ctx, tx, ingress, 0)
+	if err != nil {
+		return nil, err
+	}
+	out, err := activeSegmentRsv(ctx, tx, 0, egress)
+	if err != nil {
+		return nil, err
+	}
+	if in == nil || out == nil {
+		return nil, serrors.New("no active segment reservations to stitch", "ingress", ingress,
+			"egress", egress)
+	}
+	return []*segment.Reservation{in, out}, nil
+}
+
+// activeSegmentRsv returns a segment reservation with an active index entering this AS at
+// ingress and leaving it at egress, or nil if there is none.
+func activeSegmentRsv(ctx context.Context, tx backend.Transaction,
+	ingress, egress common.IFIDType) (*segment.Reservation, error) {
+
+	rsvs, err := tx.GetSegmentRsvsFromIFPair(ctx, &ingress, &egress)
+	if err != nil {
+		return nil, serrors.WrapStr("cannot obtain segment reservations", err,
+			"ingress", ingress, "egress", egress)
+	}
+	for _, rsv := range rsvs {
+		if rsv.ActiveIndex() != nil {
+			return rsv, nil
+		}
+	}
+	return nil, nil
+}
+
+// CleanupE2EReservation removes an index from an e2e reservation.
+func (s *Store) CleanupE2EReservation(ctx context.Context, id reservation.E2EID,
+	idx reservation.IndexNumber) error {
+
+	return s.doInTx(ctx, func(tx backend.Transaction) error {
+		rsv, err := tx.GetE2ERsvFromID(ctx, &id)
+		if err != nil {
+			return serrors.WrapStr("cannot obtain e2e reservation", err, "id", id)
+		}
+		if rsv == nil {
+			return serrors.New("unknown e2e reservation", "id", id)
+		}
+		if err := rsv.CleanupIndex(idx); err != nil {
+			return err
+		}
+		return tx.PersistE2ERsv(ctx, rsv)
+	})
+}
+
+// modifySegmentRsv loads the segment reservation and calls modify with it. If modify returns
+// true the reservation is persisted, otherwise it is deleted.
+func (s *Store) modifySegmentRsv(ctx context.Context, id reservation.SegmentID,
+	modify func(*segment.Reservation) (bool, error)) error {
+
+	return s.doInTx(ctx, func(tx backend.Transaction) error {
+		rsv, err := tx.GetSegmentRsvFromID(ctx, &id)
+		if err != nil {
+			return serrors.WrapStr("cannot obtain segment reservation", err, "id", id)
+		}
+		if rsv == nil {
+			return serrors.New("unknown segment reservation", "id", id)
+		}
+		keep, err := modify(rsv)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			return tx.DeleteSegmentRsv(ctx, &id)
+		}
+		return tx.PersistSegmentRsv(ctx, rsv)
+	})
+}
+
+func (s *Store) doInTx(ctx context.Context, action func(backend.Transaction) error) error {
+	tx, err := s.db.BeginTransaction(ctx, nil)
+	if err != nil {
+		return serrors.WrapStr("cannot create transaction", err)
+	}
+	defer tx.Rollback()
+	if err := action(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
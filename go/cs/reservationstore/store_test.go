@@ -0,0 +1,325 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reservationstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/cs/reservation/conf"
+	"github.com/scionproto/scion/go/cs/reservation/e2e"
+	"github.com/scionproto/scion/go/cs/reservation/segment"
+	"github.com/scionproto/scion/go/cs/reservation/segment/admission"
+	"github.com/scionproto/scion/go/cs/reservation/segmenttest"
+	"github.com/scionproto/scion/go/cs/reservation/sqlite"
+	"github.com/scionproto/scion/go/cs/reservationstore"
+	"github.com/scionproto/scion/go/lib/colibri/reservation"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestAdmitSegmentReservationTransit(t *testing.T) {
+	ctx := context.Background()
+	db, store := newStore(t)
+	req := newSetupReq(t, 1, 2, 0)
+	err := store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, req.AllocTrail, 1)
+
+	rsv, err := db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.NotNil(t, rsv)
+	require.Equal(t, common.IFIDType(1), rsv.Ingress)
+	require.Equal(t, common.IFIDType(2), rsv.Egress)
+	require.Len(t, rsv.Indices, 1)
+	require.Equal(t, segment.IndexTemporary, rsv.Indices[0].State())
+	require.Equal(t, reservation.BWCls(req.AllocTrail[0].AllocBW), rsv.Indices[0].AllocBW)
+
+	// renewal with different interfaces is not allowed
+	req = newSetupReq(t, 1, 3, 1)
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.Error(t, err)
+
+	// renewal
+	req = newSetupReq(t, 1, 2, 1)
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Len(t, rsv.Indices, 2)
+
+	// not admitted: nothing changes
+	req = newSetupReq(t, 1, 2, 2)
+	req.MinBW = 13
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.Error(t, err)
+	require.Len(t, req.AllocTrail, 1)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Len(t, rsv.Indices, 2)
+}
+
+func TestAdmitSegmentReservationSource(t *testing.T) {
+	ctx := context.Background()
+	db, store := newStore(t)
+	rsv := segment.NewReservation()
+	rsv.ID.ASID = xtest.MustParseAS("ff00:0:1")
+	rsv.Egress = 1
+	rsv.Path = segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 0)
+	err := db.NewSegmentRsv(ctx, rsv)
+	require.NoError(t, err)
+
+	req := newSetupReq(t, 0, 1, 0)
+	req.ID = rsv.ID
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Len(t, rsv.Indices, 1)
+	require.NotNil(t, rsv.Indices[0].Token)
+
+	// wrong index number
+	req.InfoField.Idx = 5
+	req.AllocTrail = nil
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.Error(t, err)
+
+	// unknown reservation starting here
+	req = newSetupReq(t, 0, 1, 0)
+	req.ID.ASID = xtest.MustParseAS("ff00:0:1")
+	req.ID.Suffix = [4]byte{0, 0, 0, 99}
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.Error(t, err)
+}
+
+func TestSegmentReservationLifecycle(t *testing.T) {
+	ctx := context.Background()
+	db, store := newStore(t)
+	req := newSetupReq(t, 1, 2, 0)
+	err := store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+	req = newSetupReq(t, 1, 2, 1)
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+
+	err = store.ConfirmSegmentReservation(ctx, req.ID, 0)
+	require.NoError(t, err)
+	rsv, err := db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Equal(t, segment.IndexPending, rsv.Indices[0].State())
+	err = store.ConfirmSegmentReservation(ctx, req.ID, 7)
+	require.Error(t, err)
+
+	err = store.CleanupSegmentReservation(ctx, req.ID, 1)
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Len(t, rsv.Indices, 1)
+	err = store.CleanupSegmentReservation(ctx, req.ID, 0)
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Nil(t, rsv)
+
+	req = newSetupReq(t, 1, 2, 0)
+	err = store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+	// only confirmed indices can be activated
+	err = store.ActivateSegmentReservation(ctx, req.ID, 0)
+	require.Error(t, err)
+	err = store.ConfirmSegmentReservation(ctx, req.ID, 0)
+	require.NoError(t, err)
+	err = store.ActivateSegmentReservation(ctx, req.ID, 0)
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Equal(t, segment.IndexActive, rsv.ActiveIndex().State())
+	err = store.CleanupSegmentReservation(ctx, req.ID, 0)
+	require.Error(t, err)
+	err = store.TearDownSegmentReservation(ctx, req.ID, 3)
+	require.Error(t, err)
+	err = store.TearDownSegmentReservation(ctx, req.ID, 0)
+	require.NoError(t, err)
+	rsv, err = db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.Nil(t, rsv)
+	err = store.TearDownSegmentReservation(ctx, req.ID, 0)
+	require.Error(t, err)
+}
+
+func TestE2EReservation(t *testing.T) {
+	ctx := context.Background()
+	db, store := newStore(t)
+	segRsv := newActiveSegmentRsv(t, db, store, newSetupReq(t, 1, 2, 0))
+	segAlloc := segRsv.ActiveIndex().AllocBW
+
+	id, err := reservation.NewE2EID(xtest.MustParseAS("ff00:0:1"),
+		xtest.MustParseHexString("beefcafebeefcafebeef"))
+	require.NoError(t, err)
+
+	// too much bandwidth
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, id, 1, 2, 0, segAlloc+1))
+	require.Error(t, err)
+	// wrong index
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, id, 1, 2, 1, segAlloc))
+	require.Error(t, err)
+	// no segment reservation for the interfaces
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, id, 1, 3, 0, segAlloc))
+	require.Error(t, err)
+	rsv, err := db.GetE2ERsvFromID(ctx, id)
+	require.NoError(t, err)
+	require.Nil(t, rsv)
+
+	// the first setup creates the reservation
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, id, 1, 2, 0, segAlloc))
+	require.NoError(t, err)
+	rsv, err = db.GetE2ERsvFromID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, rsv)
+	require.Len(t, rsv.SegmentReservations, 1)
+	require.Equal(t, segRsv.ID, rsv.SegmentReservations[0].ID)
+	require.Len(t, rsv.Indices, 1)
+	require.Equal(t, segAlloc, rsv.Indices[0].AllocBW)
+	// renewal of the existing reservation
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, id, 1, 2, 1, segAlloc))
+	require.NoError(t, err)
+	rsv, err = db.GetE2ERsvFromID(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, rsv.Indices, 2)
+	// other reservations cannot use the bandwidth already blocked
+	other, err := reservation.NewE2EID(xtest.MustParseAS("ff00:0:1"),
+		xtest.MustParseHexString("cafebeefcafebeefcafe"))
+	require.NoError(t, err)
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, other, 1, 2, 0, 1))
+	require.Error(t, err)
+	// failures are not admitted
+	err = store.AdmitE2EReservation(ctx, &e2e.FailureSetupReq{
+		BaseSetupReq: e2e.BaseSetupReq{ID: *id},
+	})
+	require.Error(t, err)
+
+	err = store.CleanupE2EReservation(ctx, *id, 0)
+	require.NoError(t, err)
+	rsv, err = db.GetE2ERsvFromID(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, rsv.Indices, 0)
+	err = store.CleanupE2EReservation(ctx, *id, 0)
+	require.Error(t, err)
+}
+
+func TestE2EReservationStitched(t *testing.T) {
+	ctx := context.Background()
+	db, store := newStore(t)
+	// a segment reservation ending here, and one starting here.
+	upReq := newSetupReq(t, 1, 0, 0)
+	upRsv := newActiveSegmentRsv(t, db, store, upReq)
+	downRsv := segment.NewReservation()
+	downRsv.ID.ASID = xtest.MustParseAS("ff00:0:1")
+	downRsv.Egress = 1
+	downRsv.Path = segmenttest.NewPathFromComponents(0, "1-ff00:0:1", 1, 1, "1-ff00:0:2", 0)
+	require.NoError(t, db.NewSegmentRsv(ctx, downRsv))
+	downReq := newSetupReq(t, 0, 1, 0)
+	downReq.ID = downRsv.ID
+	downRsv = newActiveSegmentRsv(t, db, store, downReq)
+
+	id, err := reservation.NewE2EID(xtest.MustParseAS("ff00:0:1"),
+		xtest.MustParseHexString("beefcafebeefcafebeef"))
+	require.NoError(t, err)
+	err = store.AdmitE2EReservation(ctx, newE2ESuccess(t, id, 1, 1, 0, 1))
+	require.NoError(t, err)
+	rsv, err := db.GetE2ERsvFromID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, rsv)
+	require.Len(t, rsv.SegmentReservations, 2)
+	require.Equal(t, upRsv.ID, rsv.SegmentReservations[0].ID)
+	require.Equal(t, downRsv.ID, rsv.SegmentReservations[1].ID)
+}
+
+// newActiveSegmentRsv admits the segment setup request and activates the index.
+func newActiveSegmentRsv(t *testing.T, db *sqlite.Backend, store *reservationstore.Store,
+	req *segment.SetupReq) *segment.Reservation {
+
+	t.Helper()
+	ctx := context.Background()
+	err := store.AdmitSegmentReservation(ctx, req)
+	require.NoError(t, err)
+	err = store.ConfirmSegmentReservation(ctx, req.ID, req.InfoField.Idx)
+	require.NoError(t, err)
+	err = store.ActivateSegmentReservation(ctx, req.ID, req.InfoField.Idx)
+	require.NoError(t, err)
+	rsv, err := db.GetSegmentRsvFromID(ctx, &req.ID)
+	require.NoError(t, err)
+	require.NotNil(t, rsv.ActiveIndex())
+	return rsv
+}
+
+func newStore(t *testing.T) (*sqlite.Backend, *reservationstore.Store) {
+	t.Helper()
+	db, err := sqlite.New("file::memory:")
+	require.NoError(t, err)
+	caps, err := conf.NewCapacities(map[common.IFIDType]map[common.IFIDType]uint64{
+		0: {1: 1000},
+		1: {0: 1000, 2: 1000, 3: 1000},
+	})
+	require.NoError(t, err)
+	return db, reservationstore.NewStore(db, &admission.NTube{Caps: caps})
+}
+
+func newSetupReq(t *testing.T, ingress, egress common.IFIDType,
+	idx reservation.IndexNumber) *segment.SetupReq {
+
+	t.Helper()
+	id, err := reservation.NewSegmentID(xtest.MustParseAS("ff00:0:2"),
+		xtest.MustParseHexString("00000001"))
+	require.NoError(t, err)
+	return &segment.SetupReq{
+		Request: segment.Request{
+			ID:      *id,
+			Ingress: ingress,
+			Egress:  egress,
+		},
+		InfoField: reservation.InfoField{
+			ExpirationTick: reservation.TickFromTime(time.Now().Add(time.Hour)),
+			Idx:            idx,
+			PathType:       reservation.CorePath,
+		},
+		MinBW: 1,
+		MaxBW: 13,
+	}
+}
+
+func newE2ESuccess(t *testing.T, id *reservation.E2EID, ingress, egress common.IFIDType,
+	idx reservation.IndexNumber, bw reservation.BWCls) *e2e.SuccessSetupReq {
+
+	t.Helper()
+	return &e2e.SuccessSetupReq{
+		BaseSetupReq: e2e.BaseSetupReq{
+			ID:      *id,
+			Ingress: ingress,
+			Egress:  egress,
+		},
+		Token: reservation.Token{
+			InfoField: reservation.InfoField{
+				ExpirationTick: reservation.TickFromTime(time.Now().Add(time.Hour)),
+				BWCls:          bw,
+				Idx:            idx,
+				PathType:       reservation.E2EPath,
+			},
+		},
+	}
+}
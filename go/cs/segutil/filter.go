@@ -17,6 +17,7 @@ package segutil
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
@@ -73,9 +74,10 @@ func psToSegs(ps pathpol.PathSet) seg.Segments {
 }
 
 type segWrap struct {
-	intfs   []snet.PathInterface
-	key     snet.PathFingerprint
-	origSeg *seg.PathSegment
+	intfs      []snet.PathInterface
+	key        snet.PathFingerprint
+	staticInfo *snet.PathStaticInfo
+	origSeg    *seg.PathSegment
 }
 
 func wrap(seg *seg.PathSegment, dir Direction) segWrap {
@@ -102,14 +104,62 @@ func wrap(seg *seg.PathSegment, dir Direction) segWrap {
 		}
 	}
 	return segWrap{
-		intfs:   intfs,
-		key:     snet.PathFingerprint(strings.Join(keyParts, " ")),
-		origSeg: seg,
+		intfs:      intfs,
+		key:        snet.PathFingerprint(strings.Join(keyParts, " ")),
+		staticInfo: segStaticInfo(seg, dir),
+		origSeg:    seg,
 	}
 }
 
 func (s segWrap) Interfaces() []snet.PathInterface  { return s.intfs }
 func (s segWrap) Fingerprint() snet.PathFingerprint { return s.key }
+func (s segWrap) StaticInfo() *snet.PathStaticInfo  { return s.staticInfo }
+
+// segStaticInfo aggregates the static info extensions of the AS entries of the
+// segment. The per-AS information is ordered in the direction of intended
+// usage. It returns nil if no AS entry carries the extension.
+func segStaticInfo(ps *seg.PathSegment, dir Direction) *snet.PathStaticInfo {
+	var info *snet.PathStaticInfo
+	for i := range ps.ASEntries {
+		asEntry := ps.ASEntries[i]
+		if dir == ReverseConsDir {
+			asEntry = ps.ASEntries[len(ps.ASEntries)-1-i]
+		}
+		si := asEntry.Exts.StaticInfo
+		if si == nil {
+			continue
+		}
+		if info == nil {
+			info = &snet.PathStaticInfo{}
+		}
+		ia := asEntry.IA()
+		info.Latency += time.Duration(si.Latency.IngressToEgressLatency) * time.Millisecond
+		info.Latency += time.Duration(si.Latency.Egresslatency) * time.Millisecond
+		for _, bw := range []uint32{si.Bandwidth.IngressToEgressBW, si.Bandwidth.EgressBW} {
+			if bw != 0 && (info.Bandwidth == 0 || uint64(bw) < info.Bandwidth) {
+				info.Bandwidth = uint64(bw)
+			}
+		}
+		info.InternalHops += uint32(si.Hops.InToOutHops)
+		info.LinkTypes = append(info.LinkTypes, snet.ASLinkType{
+			IA:    ia,
+			Inter: snet.LinkType(si.Linktype.EgressLinkType),
+		})
+		geo := snet.ASGeo{IA: ia}
+		for _, loc := range si.Geo.Locations {
+			geo.Locations = append(geo.Locations, snet.GeoLocation{
+				Latitude:  loc.GPSData.Latitude,
+				Longitude: loc.GPSData.Longitude,
+				Address:   loc.GPSData.Address,
+			})
+		}
+		info.Geo = append(info.Geo, geo)
+		if si.Note != "" {
+			info.Notes = append(info.Notes, snet.ASNote{IA: ia, Note: si.Note})
+		}
+	}
+	return info
+}
 
 type pathInterface struct {
 	ia   addr.IA
@@ -94,8 +94,9 @@ func (r *Router) translate(comb *combinator.Path, dst addr.IA) (path, error) {
 		underlay:   nextHop,
 		spath:      sp,
 		metadata: pathMetadata{
-			mtu:    comb.Mtu,
-			expiry: comb.ComputeExpTime(),
+			mtu:        comb.Mtu,
+			expiry:     comb.ComputeExpTime(),
+			staticInfo: comb.StaticInfoSummary().StaticInfo(),
 		},
 	}
 	for _, intf := range comb.Interfaces {
@@ -113,8 +114,9 @@ type path struct {
 }
 
 type pathMetadata struct {
-	mtu    uint16
-	expiry time.Time
+	mtu        uint16
+	expiry     time.Time
+	staticInfo *snet.PathStaticInfo
 }
 
 func (p path) UnderlayNextHop() *net.UDPAddr {
@@ -196,3 +198,7 @@ func (m pathMetadata) MTU() uint16 {
 func (m pathMetadata) Expiry() time.Time {
 	return m.expiry
 }
+
+func (m pathMetadata) StaticInfo() *snet.PathStaticInfo {
+	return m.staticInfo
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/scionproto/scion/go/dispatcher/internal/metrics"
 	"github.com/scionproto/scion/go/dispatcher/internal/respool"
 	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/capture"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/hpkt"
 	"github.com/scionproto/scion/go/lib/l4"
@@ -55,8 +56,13 @@ type NetToRingDataplane struct {
 }
 
 func (dp *NetToRingDataplane) Run() error {
+	// Record the received packets, such that they show up in packet captures.
+	conn := &capture.PacketConn{
+		PacketConn: dp.UnderlayConn,
+		Intf:       dp.UnderlayConn.LocalAddr().String(),
+	}
 	if !dp.HeaderV2 {
-		return dp.runLegacy()
+		return dp.runLegacy(conn)
 	}
 	for {
 		pkt := respool.GetPacket(true)
@@ -64,7 +70,7 @@ func (dp *NetToRingDataplane) Run() error {
 		// let the GC take care of this situation as they should be fairly
 		// rare.
 
-		if err := pkt.DecodeFromConn(dp.UnderlayConn); err != nil {
+		if err := pkt.DecodeFromConn(conn); err != nil {
 			log.Debug("error receiving next packet from underlay conn", "err", err)
 			continue
 		}
@@ -81,14 +87,14 @@ func (dp *NetToRingDataplane) Run() error {
 	}
 }
 
-func (dp *NetToRingDataplane) runLegacy() error {
+func (dp *NetToRingDataplane) runLegacy(conn net.PacketConn) error {
 	for {
 		pkt := respool.GetPacket(dp.HeaderV2)
 		// XXX(scrye): we don't release the reference on error conditions, and
 		// let the GC take care of this situation as they should be fairly
 		// rare.
 
-		if err := pkt.DecodeFromConn(dp.UnderlayConn); err != nil {
+		if err := pkt.DecodeFromConn(conn); err != nil {
 			log.Debug("error receiving next packet from underlay conn", "err", err)
 			continue
 		}
@@ -22,9 +22,11 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"os/user"
+	"syscall"
 
 	"github.com/scionproto/scion/go/dispatcher/config"
 	"github.com/scionproto/scion/go/dispatcher/network"
+	"github.com/scionproto/scion/go/lib/capture"
 	"github.com/scionproto/scion/go/lib/common"
 	libconfig "github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/env"
@@ -88,9 +90,12 @@ func realMain() int {
 	}()
 
 	env.SetupEnv(nil)
+	capture.NotifySignal(syscall.SIGUSR1, os.TempDir(), cfg.Dispatcher.ID,
+		capture.Config{HeaderV2: cfg.Features.HeaderV2})
 	statusPages := service.StatusPages{
-		"info":   service.NewInfoHandler(),
-		"config": service.NewConfigHandler(cfg),
+		"info":    service.NewInfoHandler(),
+		"config":  service.NewConfigHandler(cfg),
+		"capture": capture.NewHandler(cfg.Features.HeaderV2),
 	}
 	if err := statusPages.Register(http.DefaultServeMux, cfg.Dispatcher.ID); err != nil {
 		log.Error("registering status pages", "err", err)
@@ -0,0 +1,130 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hpsconfig contains the configuration of the hidden path service.
+package hpsconfig
+
+import (
+	"io"
+
+	"github.com/scionproto/scion/go/lib/config"
+	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/pkg/storage"
+)
+
+var _ config.Config = (*Config)(nil)
+
+// Config is the hidden path service configuration.
+type Config struct {
+	General  env.General      `toml:"general,omitempty"`
+	Features env.Features     `toml:"features,omitempty"`
+	Logging  log.Config       `toml:"log,omitempty"`
+	Metrics  env.Metrics      `toml:"metrics,omitempty"`
+	Tracing  env.Tracing      `toml:"tracing,omitempty"`
+	QUIC     env.QUIC         `toml:"quic,omitempty"`
+	SCIOND   env.SCIONDClient `toml:"sciond_connection,omitempty"`
+	TrustDB  storage.DBConfig `toml:"trust_db,omitempty"`
+	PathDB   storage.DBConfig `toml:"path_db,omitempty"`
+	HPS      HPSConfig        `toml:"hidden_path,omitempty"`
+}
+
+// InitDefaults initializes the default values for all parts of the config.
+func (cfg *Config) InitDefaults() {
+	config.InitAll(
+		&cfg.General,
+		&cfg.Features,
+		&cfg.Logging,
+		&cfg.Metrics,
+		&cfg.Tracing,
+		&cfg.SCIOND,
+		&cfg.TrustDB,
+		&cfg.PathDB,
+		&cfg.HPS,
+	)
+}
+
+// Validate validates all parts of the config.
+func (cfg *Config) Validate() error {
+	return config.ValidateAll(
+		&cfg.General,
+		&cfg.Features,
+		&cfg.Logging,
+		&cfg.Metrics,
+		&cfg.SCIOND,
+		&cfg.TrustDB,
+		&cfg.PathDB,
+		&cfg.HPS,
+	)
+}
+
+// Sample generates a sample config file for the hidden path service.
+func (cfg *Config) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
+	config.WriteSample(dst, path, config.CtxMap{config.ID: idSample},
+		&cfg.General,
+		&cfg.Features,
+		&cfg.Logging,
+		&cfg.Metrics,
+		&cfg.Tracing,
+		&cfg.QUIC,
+		&cfg.SCIOND,
+		config.OverrideName(
+			config.FormatData(
+				&cfg.TrustDB,
+				storage.SetID(storage.SampleTrustDB, idSample).Connection,
+			),
+			"trust_db",
+		),
+		config.OverrideName(
+			config.FormatData(
+				&cfg.PathDB,
+				storage.SetID(storage.SamplePathDB, idSample).Connection,
+			),
+			"path_db",
+		),
+		&cfg.HPS,
+	)
+}
+
+var _ config.Config = (*HPSConfig)(nil)
+
+// HPSConfig holds the configuration specific to the hidden path service.
+type HPSConfig struct {
+	// GroupConfigFiles are the files containing the hidden path group
+	// configurations in JSON format, one group per file.
+	GroupConfigFiles []string `toml:"group_config_files,omitempty"`
+}
+
+// InitDefaults initializes the default values. There are no defaults.
+func (cfg *HPSConfig) InitDefaults() {}
+
+// Validate checks that at least one group is configured.
+func (cfg *HPSConfig) Validate() error {
+	if len(cfg.GroupConfigFiles) == 0 {
+		return serrors.New("no hidden path group configured")
+	}
+	return nil
+}
+
+// Sample writes a sample of the hidden path service specific configuration.
+func (cfg *HPSConfig) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
+	config.WriteString(dst, hpsSample)
+}
+
+// ConfigName returns the name of the hidden path service specific
+// configuration section.
+func (cfg *HPSConfig) ConfigName() string {
+	return "hidden_path"
+}
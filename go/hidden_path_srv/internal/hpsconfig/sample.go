@@ -0,0 +1,23 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hpsconfig
+
+const idSample = "hps-1"
+
+const hpsSample = `
+# The files containing the hidden path group configurations in JSON format, one
+# group per file. At least one group must be configured. (required)
+group_config_files = ["/etc/scion/hp_groups/HPGCfg_ff00_0_110-69b5.json"]
+`
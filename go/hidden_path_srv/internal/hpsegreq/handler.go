@@ -15,6 +15,9 @@
 package hpsegreq
 
 import (
+	"net"
+
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
@@ -27,6 +30,9 @@ import (
 type hpSegReqHandler struct {
 	request *infra.Request
 	fetcher Fetcher
+	// localIA is set if requests from TCP peers are accepted. TCP peers are
+	// end hosts in the local AS.
+	localIA addr.IA
 }
 
 // NewSegReqHandler returns a hidden path segment request handler
@@ -41,6 +47,21 @@ func NewSegReqHandler(fetcher Fetcher) infra.Handler {
 	return infra.HandlerFunc(f)
 }
 
+// NewLocalSegReqHandler returns a hidden path segment request handler for
+// requests that are received over TCP from end hosts in the local AS. The
+// permissions of these peers are checked for the local AS.
+func NewLocalSegReqHandler(fetcher Fetcher, localIA addr.IA) infra.Handler {
+	f := func(r *infra.Request) *infra.HandlerResult {
+		handler := &hpSegReqHandler{
+			request: r,
+			fetcher: fetcher,
+			localIA: localIA,
+		}
+		return handler.Handle()
+	}
+	return infra.HandlerFunc(f)
+}
+
 // Handle handles a hidden path segment request
 func (h *hpSegReqHandler) Handle() *infra.HandlerResult {
 	logger := log.FromCtx(h.request.Context())
@@ -67,7 +88,7 @@ func (h *hpSegReqHandler) handle(logger log.Logger) (*infra.HandlerResult, error
 	sendAck := messenger.SendAckHelper(ctx, rw)
 	logger.Debug("[hpSegReqHandler] Received HPSegReq", "src", h.request.Peer, "req", hpSegReq)
 
-	snetPeer, ok := h.request.Peer.(*snet.UDPAddr)
+	snetPeer, ok := h.peer()
 	if !ok {
 		logger.Error("[hpSegReqHandler] Invalid peer address type, expected *snet.UDPAddr", nil,
 			"peer", h.request.Peer, "type", common.TypeOf(h.request.Peer))
@@ -95,3 +116,21 @@ func (h *hpSegReqHandler) handle(logger log.Logger) (*infra.HandlerResult, error
 	logger.Debug("[hpSegReqHandler] Replied with segments", "segs", numSegs)
 	return infra.MetricsResultOk, nil
 }
+
+// peer returns the SCION address of the peer.
+func (h *hpSegReqHandler) peer() (*snet.UDPAddr, bool) {
+	switch p := h.request.Peer.(type) {
+	case *snet.UDPAddr:
+		return p, true
+	case *net.TCPAddr:
+		if h.localIA.IsZero() {
+			return nil, false
+		}
+		return &snet.UDPAddr{
+			IA:   h.localIA,
+			Host: &net.UDPAddr{IP: p.IP, Port: p.Port, Zone: p.Zone},
+		}, true
+	default:
+		return nil, false
+	}
+}
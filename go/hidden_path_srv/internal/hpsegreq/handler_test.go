@@ -92,6 +92,20 @@ func TestSegReq(t *testing.T) {
 			res := handler.Handle(req)
 			assert.Equal(t, infra.MetricsErrInvalid, res)
 		},
+		"TCP peer": func(t *testing.T, ctx context.Context,
+			handler infra.Handler, m *mocks) {
+
+			msg := &path_mgmt.HPSegReq{}
+			peer := &net.TCPAddr{IP: net.IP{127, 0, 0, 1}, Port: 40000}
+			req := infra.NewRequest(ctx, msg, nil, peer, 0)
+			ack := ack.Ack{
+				Err:     proto.Ack_ErrCode_reject,
+				ErrDesc: messenger.AckRejectFailedToParse,
+			}
+			m.rw.EXPECT().SendAckReply(gomock.Any(), &matchers.AckMsg{Ack: ack})
+			res := handler.Handle(req)
+			assert.Equal(t, infra.MetricsErrInvalid, res)
+		},
 		"fetch fails": func(t *testing.T, ctx context.Context,
 			handler infra.Handler, m *mocks) {
 
@@ -127,6 +141,37 @@ func TestSegReq(t *testing.T) {
 	}
 }
 
+func TestLocalSegReq(t *testing.T) {
+	log.Discard()
+	newTestGraph(t, gomock.NewController(t))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := createMocks(ctrl)
+	ctx := infra.NewContextWithResponseWriter(context.Background(), m.rw)
+	handler := hpsegreq.NewLocalSegReqHandler(m.fetcher, ia111)
+
+	msg := &path_mgmt.HPSegReq{
+		RawDstIA: ia110.IAInt(),
+		GroupIds: []*path_mgmt.HPGroupId{group1.Id.ToMsg()},
+	}
+	peer := &net.TCPAddr{IP: net.IP{127, 0, 0, 1}, Port: 40000}
+	req := infra.NewRequest(ctx, msg, nil, peer, 0)
+	recs := []*path_mgmt.HPSegRecs{
+		{
+			GroupId: group1.Id.ToMsg(),
+			Recs:    []*seg.Meta{seg130_112},
+		},
+	}
+	expectedPeer := &snet.UDPAddr{
+		IA:   ia111,
+		Host: &net.UDPAddr{IP: peer.IP, Port: peer.Port},
+	}
+	m.fetcher.EXPECT().Fetch(gomock.Any(), msg, expectedPeer).Return(recs, nil)
+	m.rw.EXPECT().SendHPSegReply(gomock.Any(), &path_mgmt.HPSegReply{Recs: recs})
+	res := handler.Handle(req)
+	assert.Equal(t, infra.MetricsResultOk, res)
+}
+
 type mocks struct {
 	fetcher *mock_hpsegreq.MockFetcher
 	storage *mock_seghandler.MockStorage
@@ -0,0 +1,73 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics contains the prometheus metrics of the hidden path service.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/prom"
+)
+
+// Namespace is the metrics namespace for the hidden path service.
+const Namespace = "hps"
+
+// Label values for the request types handled by the hidden path service.
+const (
+	// ReqSegReg indicates a hidden path segment registration.
+	ReqSegReg = "seg_reg"
+	// ReqSegRequest indicates a hidden path segment request.
+	ReqSegRequest = "seg_request"
+	// ReqCfgRequest indicates a hidden path group configuration request.
+	ReqCfgRequest = "cfg_request"
+)
+
+// Requests contains the metrics for the requests handled by the hidden path
+// service.
+var Requests = newRequests()
+
+// Request is for request metrics.
+type Request struct {
+	count *prometheus.CounterVec
+}
+
+func newRequests() Request {
+	return Request{
+		count: prom.NewCounterVec(Namespace, "", "requests_total",
+			"Number of requests handled. \"type\" is the request type, \"result\" the outcome.",
+			[]string{"type", prom.LabelResult}),
+	}
+}
+
+// Count returns the counter for the requests of the given type with the
+// given result.
+func (r Request) Count(reqType, result string) prometheus.Counter {
+	return r.count.WithLabelValues(reqType, result)
+}
+
+// Handler wraps the handler so that the result of every handled request of
+// the given type is counted.
+func Handler(reqType string, h infra.Handler) infra.Handler {
+	return infra.HandlerFunc(func(r *infra.Request) *infra.HandlerResult {
+		result := h.Handle(r)
+		label := prom.ErrNotClassified
+		if result != nil {
+			label = result.Result
+		}
+		Requests.Count(reqType, label).Inc()
+		return result
+	})
+}
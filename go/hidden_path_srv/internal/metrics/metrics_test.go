@@ -0,0 +1,46 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/metrics"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/prom"
+)
+
+func TestHandler(t *testing.T) {
+	ok := metrics.Handler(metrics.ReqSegReg, infra.HandlerFunc(
+		func(*infra.Request) *infra.HandlerResult {
+			return infra.MetricsResultOk
+		},
+	))
+	invalid := metrics.Handler(metrics.ReqSegReg, infra.HandlerFunc(
+		func(*infra.Request) *infra.HandlerResult {
+			return infra.MetricsErrInvalid
+		},
+	))
+	ok.Handle(nil)
+	ok.Handle(nil)
+	invalid.Handle(nil)
+	assert.Equal(t, float64(2),
+		testutil.ToFloat64(metrics.Requests.Count(metrics.ReqSegReg, prom.Success)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		metrics.Requests.Count(metrics.ReqSegReg, infra.MetricsErrInvalid.Result)))
+}
@@ -0,0 +1,291 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/hiddenpathdb/adapter"
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/hpcfgreq"
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/hpsconfig"
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/hpsegreq"
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/metrics"
+	"github.com/scionproto/scion/go/hidden_path_srv/internal/registration"
+	"github.com/scionproto/scion/go/lib/addr"
+	libconfig "github.com/scionproto/scion/go/lib/config"
+	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/fatal"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/infraenv"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/infra/messenger/tcp"
+	"github.com/scionproto/scion/go/lib/infra/modules/itopo"
+	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/periodic"
+	"github.com/scionproto/scion/go/lib/prom"
+	"github.com/scionproto/scion/go/lib/revcache"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/pkg/command"
+	"github.com/scionproto/scion/go/pkg/sciond"
+	"github.com/scionproto/scion/go/pkg/service"
+	"github.com/scionproto/scion/go/pkg/storage"
+	"github.com/scionproto/scion/go/pkg/trust"
+	"github.com/scionproto/scion/go/pkg/trust/compat"
+	trustmetrics "github.com/scionproto/scion/go/pkg/trust/metrics"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func main() {
+	var flags struct {
+		config string
+	}
+	cmd := &cobra.Command{
+		Use:           "hidden_path_srv",
+		Short:         "SCION Hidden Path Service",
+		Example:       "  hidden_path_srv --config hps.toml",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(flags.config)
+		},
+	}
+	cmd.AddCommand(
+		command.NewCompletion(cmd),
+		command.NewSample(cmd, command.NewSampleConfig(&hpsconfig.Config{})),
+		command.NewVersion(cmd),
+	)
+	cmd.Flags().StringVar(&flags.config, "config", "", "Configuration file (required)")
+	cmd.MarkFlagRequired("config")
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(file string) error {
+	fatal.Init()
+	cfg, err := setupBasic(file)
+	if err != nil {
+		return err
+	}
+	defer log.Flush()
+	defer env.LogAppStopped("HPS", cfg.General.ID)
+	defer log.HandlePanic()
+	if err := setup(cfg); err != nil {
+		return err
+	}
+	topo := itopo.Get()
+
+	closer, err := sciond.InitTracer(cfg.Tracing, cfg.General.ID)
+	if err != nil {
+		return serrors.WrapStr("initializing tracer", err)
+	}
+	defer closer.Close()
+
+	groups, err := hiddenpath.LoadGroups(cfg.HPS.GroupConfigFiles)
+	if err != nil {
+		return serrors.WrapStr("loading hidden path groups", err)
+	}
+
+	router, err := infraenv.NewRouter(topo.IA(), cfg.SCIOND)
+	if err != nil {
+		return serrors.WrapStr("initializing router", err)
+	}
+	public := topo.PublicAddress(addr.SvcHPS, cfg.General.ID)
+	if public == nil {
+		return serrors.New("unable to find public address in topology", "id", cfg.General.ID)
+	}
+	nc := infraenv.NetworkConfig{
+		IA:                    topo.IA(),
+		Public:                public,
+		SVC:                   addr.SvcHPS,
+		ReconnectToDispatcher: cfg.General.ReconnectToDispatcher,
+		QUIC: infraenv.QUIC{
+			Address:  cfg.QUIC.Address,
+			CertFile: cfg.QUIC.CertFile,
+			KeyFile:  cfg.QUIC.KeyFile,
+		},
+		SVCResolutionFraction: cfg.QUIC.ResolutionFraction,
+		Router:                router,
+		SVCRouter:             messenger.NewSVCRouter(itopo.Provider()),
+		Version2:              cfg.Features.HeaderV2,
+	}
+	msgr, err := nc.Messenger()
+	if err != nil {
+		return serrors.WrapStr("initializing messenger", err)
+	}
+
+	revCache := storage.NewRevocationStorage()
+	pathDB, err := storage.NewPathStorage(cfg.PathDB)
+	if err != nil {
+		return serrors.WrapStr("initializing path storage", err)
+	}
+	pathDB = pathdb.WithMetrics(string(storage.BackendSqlite), pathDB)
+	defer pathDB.Close()
+	defer revCache.Close()
+	cleaner := periodic.Start(pathdb.NewCleaner(pathDB, "hps_segments"),
+		300*time.Second, 295*time.Second)
+	defer cleaner.Stop()
+	rcCleaner := periodic.Start(revcache.NewCleaner(revCache, "hps_revocation"),
+		10*time.Second, 10*time.Second)
+	defer rcCleaner.Stop()
+
+	trustDB, err := storage.NewTrustStorage(cfg.TrustDB)
+	if err != nil {
+		return serrors.WrapStr("initializing trust database", err)
+	}
+	trustDB = trustmetrics.WrapDB(string(storage.BackendSqlite), trustDB)
+	defer trustDB.Close()
+	engine, err := sciond.TrustEngine(cfg.General.ConfigDir, trustDB)
+	if err != nil {
+		return serrors.WrapStr("creating trust engine", err)
+	}
+
+	segHandler := seghandler.Handler{
+		Verifier: &seghandler.DefaultVerifier{
+			Verifier: compat.Verifier{Verifier: trust.Verifier{Engine: engine}},
+		},
+		Storage: &seghandler.DefaultStorage{PathDB: pathDB, RevCache: revCache},
+	}
+	fetcher := hpsegreq.NewDefaultFetcher(
+		&hpsegreq.GroupInfo{LocalIA: topo.IA(), Groups: groups},
+		msgr,
+		adapter.New(pathDB),
+	)
+	msgr.AddHandler(infra.HPSegReg, metrics.Handler(metrics.ReqSegReg,
+		registration.NewSegRegHandler(
+			registration.NewDefaultValidator(topo.IA(), groups),
+			segHandler,
+		),
+	))
+	msgr.AddHandler(infra.HPSegRequest, metrics.Handler(metrics.ReqSegRequest,
+		hpsegreq.NewSegReqHandler(fetcher)))
+	msgr.AddHandler(infra.HPCfgRequest, metrics.Handler(metrics.ReqCfgRequest,
+		hpcfgreq.NewHandler(sortedGroups(groups), topo.IA())))
+	go func() {
+		defer log.HandlePanic()
+		msgr.ListenAndServe()
+	}()
+	defer msgr.CloseServer()
+
+	// End hosts in the local AS request hidden segments over TCP.
+	tcpMsgr := tcp.NewServerMessenger(&net.TCPAddr{
+		IP:   public.IP,
+		Port: public.Port,
+		Zone: public.Zone,
+	})
+	tcpMsgr.AddHandler(infra.HPSegRequest, metrics.Handler(metrics.ReqSegRequest,
+		hpsegreq.NewLocalSegReqHandler(fetcher, topo.IA())))
+	go func() {
+		defer log.HandlePanic()
+		tcpMsgr.ListenAndServe()
+	}()
+	defer tcpMsgr.CloseServer()
+
+	// Start HTTP endpoints.
+	statusPages := service.StatusPages{
+		"info":     service.NewInfoHandler(),
+		"config":   service.NewConfigHandler(cfg),
+		"topology": itopo.TopologyHandler,
+		"groups":   groupsHandler(sortedGroups(groups)),
+	}
+	if err := statusPages.Register(http.DefaultServeMux, cfg.General.ID); err != nil {
+		return serrors.WrapStr("registering status pages", err)
+	}
+	cfg.Metrics.StartPrometheus()
+
+	select {
+	case <-fatal.ShutdownChan():
+		// Whenever we receive a SIGINT or SIGTERM we exit without an error.
+		// Deferred shutdowns for all running servers run now.
+		return nil
+	case <-fatal.FatalChan():
+		return serrors.New("shutdown on error")
+	}
+}
+
+func setupBasic(file string) (hpsconfig.Config, error) {
+	var cfg hpsconfig.Config
+	if err := libconfig.LoadFile(file, &cfg); err != nil {
+		return hpsconfig.Config{}, serrors.WrapStr("loading config from file", err, "file", file)
+	}
+	cfg.InitDefaults()
+	if err := log.Setup(cfg.Logging); err != nil {
+		return hpsconfig.Config{}, serrors.WrapStr("initialize logging", err)
+	}
+	prom.ExportElementID(cfg.General.ID)
+	if err := env.LogAppStarted("HPS", cfg.General.ID); err != nil {
+		return hpsconfig.Config{}, err
+	}
+	return cfg, nil
+}
+
+func setup(cfg hpsconfig.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return serrors.WrapStr("validating config", err)
+	}
+	topo, err := topology.FromJSONFile(cfg.General.Topology())
+	if err != nil {
+		return serrors.WrapStr("loading topology", err)
+	}
+	itopo.Init(&itopo.Config{
+		ID:  cfg.General.ID,
+		Svc: proto.ServiceType_hps,
+	})
+	if err := itopo.Update(topo); err != nil {
+		return serrors.WrapStr("unable to set initial static topology", err)
+	}
+	infraenv.InitInfraEnvironment(cfg.General.Topology())
+	return nil
+}
+
+// sortedGroups returns the groups sorted by their ID.
+func sortedGroups(groups map[hiddenpath.GroupId]*hiddenpath.Group) []*hiddenpath.Group {
+	res := make([]*hiddenpath.Group, 0, len(groups))
+	for _, g := range groups {
+		res = append(res, g)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Id.String() < res[j].Id.String()
+	})
+	return res
+}
+
+// groupsHandler returns an HTTP handler that serves the configured hidden
+// path groups in JSON format.
+func groupsHandler(groups []*hiddenpath.Group) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(groups); err != nil {
+			http.Error(w, "unable to marshal groups", http.StatusInternalServerError)
+		}
+	}
+}
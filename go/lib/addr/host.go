@@ -215,6 +215,8 @@ func HostSVCFromString(str string) HostSVC {
 		return SvcSB | m
 	case "SIG":
 		return SvcSIG | m
+	case "HPS":
+		return SvcHPS | m
 	case "Wildcard":
 		return SvcWildcard | m
 	default:
@@ -290,6 +292,8 @@ func (h HostSVC) BaseString() string {
 		return "SB"
 	case SvcSIG:
 		return "SIG"
+	case SvcHPS:
+		return "HPS"
 	case SvcWildcard:
 		return "Wildcard"
 	default:
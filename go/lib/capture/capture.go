@@ -0,0 +1,308 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capture implements on-demand packet captures in the pcapng format.
+//
+// The data plane of a process records the packets it sends and receives on
+// its capture points, e.g., the sockets of the interfaces, with Record. The
+// packets are only written if a capture has been started with Start. At most
+// one capture is active at a time, and it ends once the packet or time limit
+// is reached, or it is stopped explicitly.
+//
+// Each capture point is written as a separate pcapng interface. The captured
+// SCION packets are wrapped in the IP/UDP headers of the underlay, such that
+// they can be inspected with the usual tools, e.g., Wireshark.
+package capture
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+const (
+	// DefaultMaxPackets is the default maximum number of captured packets.
+	DefaultMaxPackets = 10000
+	// DefaultDuration is the default maximum duration of a capture.
+	DefaultDuration = time.Minute
+
+	// queueLen is the number of packets that are buffered before writing.
+	queueLen = 1024
+)
+
+// ErrActive indicates that a capture is already running.
+var ErrActive = serrors.New("capture already active")
+
+// Direction is the direction of a captured packet.
+type Direction uint32
+
+// Directions as encoded in the pcapng packet flags.
+const (
+	Inbound  Direction = 1
+	Outbound Direction = 2
+)
+
+// Config configures a capture.
+type Config struct {
+	// Filter selects the captured packets.
+	Filter Filter
+	// MaxPackets is the maximum number of captured packets. If zero,
+	// DefaultMaxPackets is used.
+	MaxPackets int
+	// Duration is the maximum duration of the capture. If zero,
+	// DefaultDuration is used.
+	Duration time.Duration
+	// HeaderV2 indicates whether the new header format is used. It is needed
+	// to filter by ISD-AS.
+	HeaderV2 bool
+}
+
+func (cfg *Config) initDefaults() {
+	if cfg.MaxPackets == 0 {
+		cfg.MaxPackets = DefaultMaxPackets
+	}
+	if cfg.Duration == 0 {
+		cfg.Duration = DefaultDuration
+	}
+}
+
+// active is the running capture, or nil.
+var (
+	active   atomic.Value
+	startMtx sync.Mutex
+)
+
+func current() *Capture {
+	c, _ := active.Load().(*Capture)
+	return c
+}
+
+// Active indicates whether a capture is running.
+func Active() bool {
+	return current() != nil
+}
+
+// Record records a raw SCION packet on the capture point with the given name,
+// if a capture is running and the packet matches its filter. The source and
+// destination are the underlay addresses of the packet. Record does not block;
+// if the writer does not keep up, the packet is dropped from the capture.
+func Record(intf string, dir Direction, raw []byte, src, dst *net.UDPAddr, ts time.Time) {
+	if c := current(); c != nil {
+		c.record(intf, dir, raw, src, dst, ts)
+	}
+}
+
+// Capture is a running capture.
+type Capture struct {
+	cfg      Config
+	w        *ngWriter
+	pkts     chan packet
+	stopChan chan struct{}
+	stopOnce sync.Once
+	doneChan chan struct{}
+	err      error
+	mtx      sync.Mutex
+	stopped  bool
+	captured int
+	dropped  int
+}
+
+type packet struct {
+	intf     string
+	dir      Direction
+	raw      []byte
+	src, dst *net.UDPAddr
+	ts       time.Time
+}
+
+// Start starts a capture that writes to w. It returns ErrActive if a capture is
+// already running. The caller must not use w until the capture is done.
+func Start(w io.Writer, cfg Config) (*Capture, error) {
+	cfg.initDefaults()
+	startMtx.Lock()
+	defer startMtx.Unlock()
+	if Active() {
+		return nil, ErrActive
+	}
+	nw, err := newNgWriter(w)
+	if err != nil {
+		return nil, serrors.WrapStr("writing section header", err)
+	}
+	c := &Capture{
+		cfg:      cfg,
+		w:        nw,
+		pkts:     make(chan packet, queueLen),
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+	active.Store(c)
+	go func() {
+		defer log.HandlePanic()
+		c.run()
+	}()
+	log.Info("Packet capture started", "filter", cfg.Filter, "max_packets", cfg.MaxPackets,
+		"duration", cfg.Duration)
+	return c, nil
+}
+
+// Stop stops the capture and waits until all captured packets are written.
+func (c *Capture) Stop() {
+	c.stopOnce.Do(func() { close(c.stopChan) })
+	<-c.doneChan
+}
+
+// Done returns a channel that is closed when the capture is done.
+func (c *Capture) Done() <-chan struct{} {
+	return c.doneChan
+}
+
+// Err returns the error that ended the capture. It must only be called after
+// the capture is done.
+func (c *Capture) Err() error {
+	return c.err
+}
+
+func (c *Capture) record(intf string, dir Direction, raw []byte, src, dst *net.UDPAddr,
+	ts time.Time) {
+
+	if !c.cfg.Filter.Match(intf, raw, c.cfg.HeaderV2) {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.stopped {
+		return
+	}
+	pkt := packet{
+		intf: intf,
+		dir:  dir,
+		raw:  append([]byte(nil), raw...),
+		src:  src,
+		dst:  dst,
+		ts:   ts,
+	}
+	select {
+	case c.pkts <- pkt:
+		c.captured++
+	default:
+		c.dropped++
+	}
+	if c.captured >= c.cfg.MaxPackets {
+		c.stopOnce.Do(func() { close(c.stopChan) })
+	}
+}
+
+func (c *Capture) run() {
+	defer close(c.doneChan)
+	timer := time.NewTimer(c.cfg.Duration)
+	defer timer.Stop()
+Loop:
+	for {
+		select {
+		case pkt := <-c.pkts:
+			if c.err = c.write(pkt); c.err != nil {
+				break Loop
+			}
+		case <-timer.C:
+			break Loop
+		case <-c.stopChan:
+			break Loop
+		}
+	}
+	c.mtx.Lock()
+	c.stopped = true
+	captured, dropped := c.captured, c.dropped
+	c.mtx.Unlock()
+	active.Store((*Capture)(nil))
+	// Write the remaining queued packets. No more packets are queued after the
+	// capture is marked as stopped.
+	for c.err == nil && len(c.pkts) > 0 {
+		c.err = c.write(<-c.pkts)
+	}
+	if c.err == nil {
+		c.err = c.w.flush()
+	}
+	log.Info("Packet capture finished", "captured", captured, "dropped", dropped, "err", c.err)
+}
+
+func (c *Capture) write(pkt packet) error {
+	data, err := encapsulate(pkt.raw, pkt.src, pkt.dst)
+	if err != nil {
+		return err
+	}
+	return c.w.writePacket(pkt.intf, pkt.dir, pkt.ts, data)
+}
+
+// encapsulate prepends the underlay IP and UDP headers to the raw SCION packet.
+// Missing addresses are replaced with the unspecified address.
+func encapsulate(raw []byte, src, dst *net.UDPAddr) ([]byte, error) {
+	if src == nil {
+		src = &net.UDPAddr{}
+	}
+	if dst == nil {
+		dst = &net.UDPAddr{}
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(src.Port),
+		DstPort: layers.UDPPort(dst.Port),
+	}
+	var ip gopacket.NetworkLayer
+	if isIPv4(src.IP) && isIPv4(dst.IP) {
+		ip = &layers.IPv4{
+			Version:  4,
+			TTL:      64,
+			Protocol: layers.IPProtocolUDP,
+			SrcIP:    orZero(src.IP, net.IPv4zero).To4(),
+			DstIP:    orZero(dst.IP, net.IPv4zero).To4(),
+		}
+	} else {
+		ip = &layers.IPv6{
+			Version:    6,
+			HopLimit:   64,
+			NextHeader: layers.IPProtocolUDP,
+			SrcIP:      orZero(src.IP, net.IPv6zero).To16(),
+			DstIP:      orZero(dst.IP, net.IPv6zero).To16(),
+		}
+	}
+	if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+		return nil, err
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	err := gopacket.SerializeLayers(buf, opts, ip.(gopacket.SerializableLayer), udp,
+		gopacket.Payload(raw))
+	if err != nil {
+		return nil, serrors.WrapStr("encapsulating packet", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func isIPv4(ip net.IP) bool {
+	return ip == nil || ip.To4() != nil
+}
+
+func orZero(ip, zero net.IP) net.IP {
+	if ip == nil {
+		return zero
+	}
+	return ip
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/capture"
+)
+
+var (
+	brAddr   = &net.UDPAddr{IP: net.IP{192, 168, 0, 1}, Port: 50000}
+	peerAddr = &net.UDPAddr{IP: net.IP{192, 168, 0, 2}, Port: 50001}
+)
+
+func TestCapture(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := capture.Start(&buf, capture.Config{
+		Filter: capture.Filter{SrcIA: ia110},
+	})
+	require.NoError(t, err)
+	assert.True(t, capture.Active())
+	_, err = capture.Start(ioutil.Discard, capture.Config{})
+	assert.True(t, errors.Is(err, capture.ErrActive), err)
+
+	ts := time.Unix(1600000000, 123456789)
+	pkt1 := rawPkt(false, ia111, ia110)
+	pkt2 := rawPkt(false, ia110, ia111)
+	capture.Record("1", capture.Inbound, pkt1, peerAddr, brAddr, ts)
+	capture.Record("1", capture.Inbound, pkt2, peerAddr, brAddr, ts)
+	capture.Record("loc", capture.Outbound, pkt1, brAddr, nil, ts)
+	c.Stop()
+	require.NoError(t, c.Err())
+	assert.False(t, capture.Active())
+	capture.Record("1", capture.Inbound, pkt1, peerAddr, brAddr, ts)
+
+	blocks := readBlocks(t, buf.Bytes())
+	require.Len(t, blocks, 5)
+	assert.EqualValues(t, 0x0A0D0D0A, blocks[0].Type)
+	assert.EqualValues(t, 1, blocks[1].Type)
+	assert.EqualValues(t, 6, blocks[2].Type)
+	assert.EqualValues(t, 1, blocks[3].Type)
+	assert.EqualValues(t, 6, blocks[4].Type)
+
+	assert.EqualValues(t, 0, binary.LittleEndian.Uint32(blocks[2].Body[0:]))
+	assert.EqualValues(t, 1, binary.LittleEndian.Uint32(blocks[4].Body[0:]))
+	nanos := uint64(binary.LittleEndian.Uint32(blocks[2].Body[4:]))<<32 |
+		uint64(binary.LittleEndian.Uint32(blocks[2].Body[8:]))
+	assert.EqualValues(t, ts.UnixNano(), nanos)
+
+	capLen := binary.LittleEndian.Uint32(blocks[2].Body[12:])
+	gpkt := gopacket.NewPacket(blocks[2].Body[20:20+capLen], layers.LayerTypeIPv4,
+		gopacket.Default)
+	require.Nil(t, gpkt.ErrorLayer())
+	ip := gpkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	assert.Equal(t, peerAddr.IP.To4(), ip.SrcIP)
+	assert.Equal(t, brAddr.IP.To4(), ip.DstIP)
+	udp := gpkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	assert.EqualValues(t, peerAddr.Port, udp.SrcPort)
+	assert.EqualValues(t, brAddr.Port, udp.DstPort)
+	assert.Equal(t, pkt1, udp.Payload)
+}
+
+func TestCaptureMaxPackets(t *testing.T) {
+	c, err := capture.Start(ioutil.Discard, capture.Config{MaxPackets: 2})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		capture.Record("1", capture.Inbound, []byte{0}, peerAddr, brAddr, time.Now())
+	}
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("capture did not stop after max packets")
+	}
+	assert.False(t, capture.Active())
+}
+
+func TestCaptureDuration(t *testing.T) {
+	c, err := capture.Start(ioutil.Discard, capture.Config{Duration: 10 * time.Millisecond})
+	require.NoError(t, err)
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("capture did not stop after duration")
+	}
+	assert.NoError(t, c.Err())
+}
+
+func TestHandler(t *testing.T) {
+	tests := map[string]struct {
+		Query        string
+		ExpectedCode int
+	}{
+		"valid": {
+			Query:        "?filter=ia+1-ff00:0:110&packets=10&duration=10ms",
+			ExpectedCode: http.StatusOK,
+		},
+		"invalid filter": {
+			Query:        "?filter=port+80",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		"invalid packets": {
+			Query:        "?packets=-1",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		"invalid duration": {
+			Query:        "?duration=10",
+			ExpectedCode: http.StatusBadRequest,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/capture"+test.Query, nil)
+			capture.NewHandler(false)(rr, req)
+			assert.Equal(t, test.ExpectedCode, rr.Code)
+			if test.ExpectedCode == http.StatusOK {
+				blocks := readBlocks(t, rr.Body.Bytes())
+				assert.Len(t, blocks, 1)
+			}
+		})
+	}
+}
+
+type block struct {
+	Type uint32
+	Body []byte
+}
+
+func readBlocks(t *testing.T, raw []byte) []block {
+	var blocks []block
+	for len(raw) > 0 {
+		require.True(t, len(raw) >= 12)
+		l := binary.LittleEndian.Uint32(raw[4:])
+		require.True(t, int(l) <= len(raw))
+		require.Equal(t, l, binary.LittleEndian.Uint32(raw[l-4:]))
+		blocks = append(blocks, block{
+			Type: binary.LittleEndian.Uint32(raw),
+			Body: raw[8 : l-4],
+		})
+		raw = raw[l:]
+	}
+	return blocks
+}
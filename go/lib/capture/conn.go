@@ -0,0 +1,48 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"net"
+	"time"
+)
+
+// PacketConn wraps a net.PacketConn and records the packets that are read and
+// written on the capture point with the name Intf.
+type PacketConn struct {
+	net.PacketConn
+	Intf string
+}
+
+func (c *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, a, err := c.PacketConn.ReadFrom(b)
+	if err == nil && Active() {
+		Record(c.Intf, Inbound, b[:n], udpAddr(a), udpAddr(c.LocalAddr()), time.Now())
+	}
+	return n, a, err
+}
+
+func (c *PacketConn) WriteTo(b []byte, a net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(b, a)
+	if err == nil && Active() {
+		Record(c.Intf, Outbound, b[:n], udpAddr(c.LocalAddr()), udpAddr(a), time.Now())
+	}
+	return n, err
+}
+
+func udpAddr(a net.Addr) *net.UDPAddr {
+	u, _ := a.(*net.UDPAddr)
+	return u
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/spkt"
+)
+
+// Filter selects the packets that are captured. The zero value matches all
+// packets.
+//
+// A filter is specified with a BPF-like expression that consists of primitives
+// that are combined with "and". The supported primitives are:
+//
+//	ia <ia>       the source or destination ISD-AS is <ia>
+//	src ia <ia>   the source ISD-AS is <ia>
+//	dst ia <ia>   the destination ISD-AS is <ia>
+//	intf <name>   the packet is captured on the capture point <name>
+//
+// For example: "src ia 1-ff00:0:110 and intf 1".
+type Filter struct {
+	// IA matches packets with the given source or destination ISD-AS.
+	IA addr.IA
+	// SrcIA matches packets with the given source ISD-AS.
+	SrcIA addr.IA
+	// DstIA matches packets with the given destination ISD-AS.
+	DstIA addr.IA
+	// Intf matches packets captured on the capture point with the given name.
+	Intf string
+}
+
+// ParseFilter parses a filter expression.
+func ParseFilter(expr string) (Filter, error) {
+	var f Filter
+	tokens := strings.Fields(expr)
+	for len(tokens) > 0 {
+		var err error
+		if tokens, err = f.parsePrimitive(tokens); err != nil {
+			return Filter{}, serrors.WrapStr("parsing filter", err, "filter", expr)
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		if tokens[0] != "and" || len(tokens) == 1 {
+			return Filter{}, serrors.New("expected \"and\" followed by primitive",
+				"filter", expr)
+		}
+		tokens = tokens[1:]
+	}
+	return f, nil
+}
+
+// parsePrimitive parses the primitive at the start of tokens and returns the
+// remaining tokens.
+func (f *Filter) parsePrimitive(tokens []string) ([]string, error) {
+	var target *addr.IA
+	switch tokens[0] {
+	case "src", "dst":
+		if len(tokens) < 2 || tokens[1] != "ia" {
+			return nil, serrors.New("expected \"ia\"", "after", tokens[0])
+		}
+		target = &f.SrcIA
+		if tokens[0] == "dst" {
+			target = &f.DstIA
+		}
+		tokens = tokens[1:]
+	case "ia":
+		target = &f.IA
+	case "intf":
+		if len(tokens) < 2 {
+			return nil, serrors.New("missing interface name")
+		}
+		if f.Intf != "" {
+			return nil, serrors.New("duplicate primitive", "primitive", "intf")
+		}
+		f.Intf = tokens[1]
+		return tokens[2:], nil
+	default:
+		return nil, serrors.New("unknown primitive", "primitive", tokens[0])
+	}
+	if len(tokens) < 2 {
+		return nil, serrors.New("missing ISD-AS")
+	}
+	if !target.IsZero() {
+		return nil, serrors.New("duplicate primitive", "primitive", tokens[0])
+	}
+	ia, err := addr.IAFromString(tokens[1])
+	if err != nil {
+		return nil, err
+	}
+	*target = ia
+	return tokens[2:], nil
+}
+
+// Match indicates whether the raw SCION packet captured on the capture point
+// matches the filter.
+func (f Filter) Match(intf string, raw []byte, headerV2 bool) bool {
+	if f.Intf != "" && f.Intf != intf {
+		return false
+	}
+	if f.IA.IsZero() && f.SrcIA.IsZero() && f.DstIA.IsZero() {
+		return true
+	}
+	offset := spkt.CmnHdrLen
+	if headerV2 {
+		offset = slayers.CmnHdrLen
+	}
+	if len(raw) < offset+2*addr.IABytes {
+		return false
+	}
+	dstIA := addr.IAFromRaw(raw[offset:])
+	srcIA := addr.IAFromRaw(raw[offset+addr.IABytes:])
+	switch {
+	case !f.IA.IsZero() && !f.IA.Equal(srcIA) && !f.IA.Equal(dstIA):
+		return false
+	case !f.SrcIA.IsZero() && !f.SrcIA.Equal(srcIA):
+		return false
+	case !f.DstIA.IsZero() && !f.DstIA.Equal(dstIA):
+		return false
+	}
+	return true
+}
+
+// String returns the filter expression.
+func (f Filter) String() string {
+	var primitives []string
+	if !f.IA.IsZero() {
+		primitives = append(primitives, "ia "+f.IA.String())
+	}
+	if !f.SrcIA.IsZero() {
+		primitives = append(primitives, "src ia "+f.SrcIA.String())
+	}
+	if !f.DstIA.IsZero() {
+		primitives = append(primitives, "dst ia "+f.DstIA.String())
+	}
+	if f.Intf != "" {
+		primitives = append(primitives, "intf "+f.Intf)
+	}
+	return strings.Join(primitives, " and ")
+}
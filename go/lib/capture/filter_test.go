@@ -0,0 +1,184 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/capture"
+	"github.com/scionproto/scion/go/lib/slayers"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	ia110 = xtest.MustParseIA("1-ff00:0:110")
+	ia111 = xtest.MustParseIA("1-ff00:0:111")
+	ia112 = xtest.MustParseIA("1-ff00:0:112")
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := map[string]struct {
+		Expr      string
+		Expected  capture.Filter
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"empty": {
+			Assertion: assert.NoError,
+		},
+		"ia": {
+			Expr:      "ia 1-ff00:0:110",
+			Expected:  capture.Filter{IA: ia110},
+			Assertion: assert.NoError,
+		},
+		"all primitives": {
+			Expr: "src ia 1-ff00:0:110 and dst ia 1-ff00:0:111 and intf 1 and ia 1-ff00:0:112",
+			Expected: capture.Filter{
+				IA:    ia112,
+				SrcIA: ia110,
+				DstIA: ia111,
+				Intf:  "1",
+			},
+			Assertion: assert.NoError,
+		},
+		"missing and": {
+			Expr:      "ia 1-ff00:0:110 intf 1",
+			Assertion: assert.Error,
+		},
+		"trailing and": {
+			Expr:      "ia 1-ff00:0:110 and",
+			Assertion: assert.Error,
+		},
+		"invalid ia": {
+			Expr:      "src ia 1-ff00:0:1100000",
+			Assertion: assert.Error,
+		},
+		"missing ia": {
+			Expr:      "dst ia",
+			Assertion: assert.Error,
+		},
+		"src without ia": {
+			Expr:      "src 1-ff00:0:110",
+			Assertion: assert.Error,
+		},
+		"duplicate": {
+			Expr:      "intf 1 and intf 2",
+			Assertion: assert.Error,
+		},
+		"unknown primitive": {
+			Expr:      "host 10.0.0.1",
+			Assertion: assert.Error,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := capture.ParseFilter(test.Expr)
+			test.Assertion(t, err)
+			assert.Equal(t, test.Expected, f)
+		})
+	}
+}
+
+func TestFilterString(t *testing.T) {
+	expr := "ia 1-ff00:0:112 and src ia 1-ff00:0:110 and dst ia 1-ff00:0:111 and intf 1"
+	f, err := capture.ParseFilter(expr)
+	require.NoError(t, err)
+	assert.Equal(t, expr, f.String())
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := map[string]struct {
+		Filter   string
+		Intf     string
+		Raw      []byte
+		HeaderV2 bool
+		Expected bool
+	}{
+		"empty": {
+			Raw:      []byte{0},
+			Expected: true,
+		},
+		"intf match": {
+			Filter:   "intf 1",
+			Intf:     "1",
+			Expected: true,
+		},
+		"intf mismatch": {
+			Filter: "intf 1",
+			Intf:   "loc",
+		},
+		"ia src match": {
+			Filter:   "ia 1-ff00:0:110",
+			Raw:      rawPkt(false, ia111, ia110),
+			Expected: true,
+		},
+		"ia dst match": {
+			Filter:   "ia 1-ff00:0:110",
+			Raw:      rawPkt(false, ia110, ia111),
+			Expected: true,
+		},
+		"ia mismatch": {
+			Filter: "ia 1-ff00:0:112",
+			Raw:    rawPkt(false, ia110, ia111),
+		},
+		"src and dst match": {
+			Filter:   "src ia 1-ff00:0:110 and dst ia 1-ff00:0:111",
+			Raw:      rawPkt(false, ia111, ia110),
+			Expected: true,
+		},
+		"src and dst swapped": {
+			Filter: "src ia 1-ff00:0:110 and dst ia 1-ff00:0:111",
+			Raw:    rawPkt(false, ia110, ia111),
+		},
+		"header v2": {
+			Filter:   "dst ia 1-ff00:0:112",
+			Raw:      rawPkt(true, ia112, ia110),
+			HeaderV2: true,
+			Expected: true,
+		},
+		"header v2 parsed as legacy": {
+			Filter: "dst ia 1-ff00:0:112",
+			Raw:    rawPkt(true, ia112, ia110),
+		},
+		"truncated": {
+			Filter: "ia 1-ff00:0:110",
+			Raw:    rawPkt(false, ia110, ia111)[:spkt.CmnHdrLen+addr.IABytes],
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := capture.ParseFilter(test.Filter)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, f.Match(test.Intf, test.Raw, test.HeaderV2))
+		})
+	}
+}
+
+// rawPkt returns the start of a SCION packet up to the end of the ISD-AS
+// fields of the address header.
+func rawPkt(headerV2 bool, dst, src addr.IA) []byte {
+	offset := spkt.CmnHdrLen
+	if headerV2 {
+		offset = slayers.CmnHdrLen
+	}
+	raw := make([]byte, offset+2*addr.IABytes)
+	dst.Write(raw[offset:])
+	src.Write(raw[offset+addr.IABytes:])
+	return raw
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// NewHandler returns an HTTP handler that runs a capture and streams it as a
+// pcapng file to the client. The capture is configured with the query
+// parameters:
+//
+//	filter    the filter expression, see Filter (default none)
+//	packets   the maximum number of captured packets (default DefaultMaxPackets)
+//	duration  the maximum duration of the capture (default DefaultDuration)
+//
+// The capture ends early if the client disconnects. For example:
+//
+//	curl -o br.pcapng 'http://<status addr>/capture?filter=ia+1-ff00:0:110&duration=10s'
+func NewHandler(headerV2 bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg, err := parseQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cfg.HeaderV2 = headerV2
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="capture.pcapng"`)
+		c, err := Start(w, cfg)
+		if err != nil {
+			w.Header().Del("Content-Disposition")
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrActive) {
+				code = http.StatusConflict
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		select {
+		case <-c.Done():
+		case <-r.Context().Done():
+			c.Stop()
+		}
+	}
+}
+
+func parseQuery(r *http.Request) (Config, error) {
+	var cfg Config
+	q := r.URL.Query()
+	if v := q.Get("filter"); v != "" {
+		f, err := ParseFilter(v)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Filter = f
+	}
+	if v := q.Get("packets"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Config{}, serrors.New("packets must be a positive number", "packets", v)
+		}
+		cfg.MaxPackets = n
+	}
+	if v := q.Get("duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return Config{}, serrors.New("duration must be a positive duration", "duration", v)
+		}
+		cfg.Duration = d
+	}
+	return cfg, nil
+}
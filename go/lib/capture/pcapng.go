@@ -0,0 +1,146 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Block types and options of the pcapng format, see
+// https://tools.ietf.org/html/draft-tuexen-opsawg-pcapng.
+const (
+	blockTypeSectionHeader = 0x0A0D0D0A
+	blockTypeInterface     = 0x00000001
+	blockTypeEnhancedPkt   = 0x00000006
+
+	byteOrderMagic = 0x1A2B3C4D
+
+	optEndOfOpt    = 0
+	optSHBUserAppl = 4
+	optIfName      = 2
+	optIfTsResol   = 9
+	optEPBFlags    = 2
+
+	// linkTypeRaw is the link type of raw IPv4 and IPv6 packets.
+	linkTypeRaw = 101
+	// tsResolNanos indicates nanosecond timestamps.
+	tsResolNanos = 9
+)
+
+// ngWriter writes packets in the pcapng format. All packets are written to a
+// single section, each capture point is represented by an interface.
+type ngWriter struct {
+	w     *bufio.Writer
+	intfs map[string]uint32
+}
+
+// newNgWriter creates a new pcapng writer and writes the section header.
+func newNgWriter(w io.Writer) (*ngWriter, error) {
+	nw := &ngWriter{
+		w:     bufio.NewWriter(w),
+		intfs: make(map[string]uint32),
+	}
+	body := make([]byte, 16)
+	binary.LittleEndian.PutUint32(body[0:], byteOrderMagic)
+	binary.LittleEndian.PutUint16(body[4:], 1)
+	binary.LittleEndian.PutUint16(body[6:], 0)
+	// The section length is not specified.
+	binary.LittleEndian.PutUint64(body[8:], ^uint64(0))
+	body = appendOption(body, optSHBUserAppl, []byte("scion"))
+	body = appendOption(body, optEndOfOpt, nil)
+	if err := nw.writeBlock(blockTypeSectionHeader, body); err != nil {
+		return nil, err
+	}
+	return nw, nw.flush()
+}
+
+// interfaceID returns the ID of the interface with the given name. If the
+// interface does not exist yet, its description is written.
+func (nw *ngWriter) interfaceID(name string) (uint32, error) {
+	if id, ok := nw.intfs[name]; ok {
+		return id, nil
+	}
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint16(body[0:], linkTypeRaw)
+	// The snap length 0 indicates that packets are not truncated.
+	binary.LittleEndian.PutUint32(body[4:], 0)
+	body = appendOption(body, optIfName, []byte(name))
+	body = appendOption(body, optIfTsResol, []byte{tsResolNanos})
+	body = appendOption(body, optEndOfOpt, nil)
+	if err := nw.writeBlock(blockTypeInterface, body); err != nil {
+		return 0, err
+	}
+	id := uint32(len(nw.intfs))
+	nw.intfs[name] = id
+	return id, nil
+}
+
+// writePacket writes the packet on the interface with the given name.
+func (nw *ngWriter) writePacket(intf string, dir Direction, ts time.Time, data []byte) error {
+	id, err := nw.interfaceID(intf)
+	if err != nil {
+		return err
+	}
+	body := make([]byte, 20, 20+len(data)+16)
+	nanos := uint64(ts.UnixNano())
+	binary.LittleEndian.PutUint32(body[0:], id)
+	binary.LittleEndian.PutUint32(body[4:], uint32(nanos>>32))
+	binary.LittleEndian.PutUint32(body[8:], uint32(nanos))
+	binary.LittleEndian.PutUint32(body[12:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(body[16:], uint32(len(data)))
+	body = append(body, data...)
+	body = append(body, make([]byte, padding(len(data)))...)
+	flags := make([]byte, 4)
+	binary.LittleEndian.PutUint32(flags, uint32(dir))
+	body = appendOption(body, optEPBFlags, flags)
+	body = appendOption(body, optEndOfOpt, nil)
+	return nw.writeBlock(blockTypeEnhancedPkt, body)
+}
+
+// flush writes any buffered data to the underlying writer.
+func (nw *ngWriter) flush() error {
+	return nw.w.Flush()
+}
+
+func (nw *ngWriter) writeBlock(blockType uint32, body []byte) error {
+	hdr := make([]byte, 8)
+	total := uint32(len(body) + 12)
+	binary.LittleEndian.PutUint32(hdr[0:], blockType)
+	binary.LittleEndian.PutUint32(hdr[4:], total)
+	if _, err := nw.w.Write(hdr); err != nil {
+		return err
+	}
+	if _, err := nw.w.Write(body); err != nil {
+		return err
+	}
+	_, err := nw.w.Write(hdr[4:])
+	return err
+}
+
+func appendOption(b []byte, code uint16, value []byte) []byte {
+	hdr := make([]byte, 4)
+	binary.LittleEndian.PutUint16(hdr[0:], code)
+	binary.LittleEndian.PutUint16(hdr[2:], uint16(len(value)))
+	b = append(b, hdr...)
+	b = append(b, value...)
+	return append(b, make([]byte, padding(len(value)))...)
+}
+
+func padding(l int) int {
+	return (4 - l%4) % 4
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/log"
+)
+
+// NotifySignal toggles a capture whenever the signal is received. If no
+// capture is running, a capture with the given configuration is started that
+// writes to a new file in dir, whose name starts with prefix. Otherwise, the
+// running capture is stopped.
+func NotifySignal(sig os.Signal, dir, prefix string, cfg Config) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sig)
+	go func() {
+		defer log.HandlePanic()
+		for range c {
+			toggleFileCapture(dir, prefix, cfg)
+		}
+	}()
+}
+
+func toggleFileCapture(dir, prefix string, cfg Config) {
+	if c := current(); c != nil {
+		c.Stop()
+		return
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%s-%s.pcapng", prefix,
+		time.Now().UTC().Format("20060102T150405Z")))
+	f, err := os.Create(name)
+	if err != nil {
+		log.Error("Failed to create packet capture file", "file", name, "err", err)
+		return
+	}
+	c, err := Start(f, cfg)
+	if err != nil {
+		f.Close()
+		os.Remove(name)
+		log.Error("Failed to start packet capture", "err", err)
+		return
+	}
+	log.Info("Writing packet capture", "file", name)
+	go func() {
+		defer log.HandlePanic()
+		<-c.Done()
+		if err := f.Close(); err != nil {
+			log.Error("Failed to close packet capture file", "file", name, "err", err)
+		}
+	}()
+}
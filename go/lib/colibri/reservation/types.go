@@ -17,6 +17,7 @@ package reservation
 import (
 	"encoding/binary"
 	"io"
+	"math"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
@@ -157,6 +158,30 @@ func (b BWCls) Validate() error {
 	return nil
 }
 
+// BWClsFromBW returns the largest bandwidth class whose bandwidth is not greater than the
+// argument, in kbps. Since bandwidth = 16 * sqrt(2^(BWCls - 1)), we have
+// BWCls = 1 + 2 * log2(bandwidth / 16).
+func BWClsFromBW(bwKbps uint64) BWCls {
+	if bwKbps < 16 {
+		return 0
+	}
+	cls := 1 + 2*math.Log2(float64(bwKbps)/16)
+	if cls >= 63 {
+		return 63
+	}
+	b := BWCls(cls)
+	// correct possible rounding errors on exact boundaries
+	if (b + 1).ToKbps() <= bwKbps {
+		b++
+	}
+	return b
+}
+
+// ToKbps returns the bandwidth, in kbps, this class represents.
+func (b BWCls) ToKbps() uint64 {
+	return uint64(16 * math.Sqrt(math.Pow(2, float64(b)-1)))
+}
+
 // SplitCls is the traffic split parameter. split = sqrt(2^c). The split divides the bandwidth
 // in control traffic (BW * split) and end to end traffic (BW * (1-s)). 0 <= splitCls <= 256 .
 type SplitCls uint8
@@ -88,6 +88,27 @@ func TestValidateBWCls(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestBWClsToKbps(t *testing.T) {
+	require.Equal(t, uint64(16), BWCls(1).ToKbps())
+	require.Equal(t, uint64(22), BWCls(2).ToKbps())
+	require.Equal(t, uint64(32), BWCls(3).ToKbps())
+	require.Equal(t, uint64(1024), BWCls(13).ToKbps())
+	require.Equal(t, uint64(16*1<<31), BWCls(63).ToKbps())
+}
+
+func TestBWClsFromBW(t *testing.T) {
+	require.Equal(t, BWCls(0), BWClsFromBW(0))
+	require.Equal(t, BWCls(0), BWClsFromBW(15))
+	require.Equal(t, BWCls(1), BWClsFromBW(16))
+	require.Equal(t, BWCls(1), BWClsFromBW(21))
+	require.Equal(t, BWCls(2), BWClsFromBW(22))
+	require.Equal(t, BWCls(14), BWClsFromBW(1500))
+	require.Equal(t, BWCls(63), BWClsFromBW(1<<63))
+	for i := 1; i < 64; i++ {
+		require.Equal(t, BWCls(i), BWClsFromBW(BWCls(i).ToKbps()))
+	}
+}
+
 func TestValidateRLC(t *testing.T) {
 	for i := 0; i < 64; i++ {
 		c := RLC(i)
@@ -25,6 +25,7 @@ type ColibriRequestPayload struct {
 	Which     proto.ColibriRequestPayload_Which
 	Request   *Request
 	Response  *Response
+	HopByHop  *HopByHopFields
 }
 
 var _ proto.Cerealizable = (*ColibriRequestPayload)(nil)
@@ -40,6 +40,31 @@ func TestSerializeRoot(t *testing.T) {
 	require.Equal(t, buffer, otherBuffer)
 }
 
+func TestSerializeHopByHop(t *testing.T) {
+	root := &colibri_mgmt.ColibriRequestPayload{
+		Timestamp: 42,
+		Which:     proto.ColibriRequestPayload_Which_request,
+		Request: &colibri_mgmt.Request{
+			Which:           proto.Request_Which_segmentTeardown,
+			SegmentTeardown: &colibri_mgmt.SegmentTeardownReq{},
+		},
+		HopByHop: &colibri_mgmt.HopByHopFields{
+			SegmentID: &colibri_mgmt.SegmentReservationID{
+				ASID:   xtest.MustParseHexString("ff00cafe0001"),
+				Suffix: xtest.MustParseHexString("deadbeef"),
+			},
+			InfoField:   xtest.MustParseHexString("16ebdb4f0d042500"),
+			Path:        xtest.MustParseHexString("0000000000000000"),
+			CurrentStep: 1,
+		},
+	}
+	buffer, err := root.PackRoot()
+	require.NoError(t, err)
+	otherRoot, err := colibri_mgmt.NewFromRaw(buffer)
+	require.NoError(t, err)
+	require.Equal(t, root.HopByHop, otherRoot.HopByHop)
+}
+
 // tests serialization for all types of requests
 func TestSerializeRequest(t *testing.T) {
 	newSegmentSetup := func() *colibri_mgmt.SegmentSetup {
@@ -331,6 +356,7 @@ func TestSerializeResponse(t *testing.T) {
 		},
 	}
 	for name, tc := range testCases {
+		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 			root := &colibri_mgmt.ColibriRequestPayload{
@@ -0,0 +1,32 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colibri_mgmt
+
+import (
+	"github.com/scionproto/scion/go/proto"
+)
+
+// HopByHopFields contains the fields of the hop by hop colibri extension. Until the extension
+// is available, they travel together with the request or response.
+type HopByHopFields struct {
+	SegmentID   *SegmentReservationID `capnp:"segmentID"`
+	InfoField   []byte
+	Path        []byte
+	CurrentStep uint8
+}
+
+func (h *HopByHopFields) ProtoId() proto.ProtoIdType {
+	return proto.HopByHopFields_TypeID
+}
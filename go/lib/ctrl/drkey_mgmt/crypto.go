@@ -0,0 +1,132 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_mgmt
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+const (
+	cipherKeyLen = 32
+	cipherLabel  = "DRKey level-1 key"
+)
+
+// encryptKey encrypts the key to the public key of the recipient with an
+// ephemeral ECDH key exchange on the curve of the recipient key. The key that
+// protects the DRKey is derived from the shared secret with HKDF-SHA256 and
+// is bound to both public keys. The additional data is authenticated. The
+// cipher text is the ephemeral public key followed by the sealed key.
+func encryptKey(key drkey.DRKey, pub crypto.PublicKey, additional []byte) ([]byte, error) {
+	recipient, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, serrors.New("unsupported public key type", "type", common.TypeOf(pub))
+	}
+	eph, err := ecdsa.GenerateKey(recipient.Curve, rand.Reader)
+	if err != nil {
+		return nil, serrors.WrapStr("generating ephemeral key", err)
+	}
+	ephPub := elliptic.Marshal(recipient.Curve, eph.X, eph.Y)
+	aead, err := newCipher(recipient.Curve, eph.D, recipient.X, recipient.Y,
+		ephPub, elliptic.Marshal(recipient.Curve, recipient.X, recipient.Y))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ephPub, nonce, key, additional), nil
+}
+
+// decryptKey decrypts a key that was encrypted with encryptKey to the public
+// key of priv.
+func decryptKey(ciphertext []byte, priv crypto.PrivateKey,
+	additional []byte) (drkey.DRKey, error) {
+
+	recipient, ok := priv.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, serrors.New("unsupported private key type", "type", common.TypeOf(priv))
+	}
+	curve := recipient.Curve
+	pointLen := 1 + 2*((curve.Params().BitSize+7)/8)
+	if len(ciphertext) < pointLen {
+		return nil, serrors.New("cipher text too short", "len", len(ciphertext))
+	}
+	ephPub := ciphertext[:pointLen]
+	x, y := elliptic.Unmarshal(curve, ephPub)
+	if x == nil {
+		return nil, serrors.New("invalid ephemeral public key")
+	}
+	aead, err := newCipher(curve, recipient.D, x, y, ephPub,
+		elliptic.Marshal(curve, recipient.X, recipient.Y))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	key, err := aead.Open(nil, nonce, ciphertext[pointLen:], additional)
+	if err != nil {
+		return nil, serrors.WrapStr("decrypting key", err)
+	}
+	return key, nil
+}
+
+// newCipher derives the AEAD from the shared secret of the private scalar d
+// and the public point (x, y). The AEAD is only used for a single message, a
+// fixed nonce is thus safe.
+func newCipher(curve elliptic.Curve, d, x, y *big.Int, ephPub,
+	recipientPub []byte) (cipher.AEAD, error) {
+
+	sx, _ := curve.ScalarMult(x, y, d.Bytes())
+	secret := make([]byte, (curve.Params().BitSize+7)/8)
+	b := sx.Bytes()
+	copy(secret[len(secret)-len(b):], b)
+	info := make([]byte, 0, len(cipherLabel)+len(ephPub)+len(recipientPub))
+	info = append(info, cipherLabel...)
+	info = append(info, ephPub...)
+	info = append(info, recipientPub...)
+	raw := make([]byte, cipherKeyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), raw); err != nil {
+		return nil, serrors.WrapStr("deriving key", err)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyAdditionalData returns the data that is authenticated together with the
+// encrypted key (isdas, dstIA, timestamp, expTime).
+func keyAdditionalData(srcIA, dstIA addr.IA, begin, end uint32) []byte {
+	data := make([]byte, 24)
+	binary.BigEndian.PutUint64(data, uint64(srcIA.IAInt()))
+	binary.BigEndian.PutUint64(data[8:], uint64(dstIA.IAInt()))
+	binary.BigEndian.PutUint32(data[16:], begin)
+	binary.BigEndian.PutUint32(data[20:], end)
+	return data
+}
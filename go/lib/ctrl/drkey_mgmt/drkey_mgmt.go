@@ -0,0 +1,148 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drkey_mgmt contains the control messages to exchange DRKeys.
+//
+// Requests and replies exchanged between ASes carry a signature created with
+// the control-plane PKI (see pkg/trust) in their signature field. The field
+// contains a packed signed blob without the blob, i.e., only the signature
+// metadata. The metadata already identifies the certificate used to sign, the
+// certificate and TRC version fields are thus left empty.
+//
+// The level-1 key in a reply to another AS is encrypted to the public key of
+// the certificate that signed the request, before the reply is signed. The key
+// is encrypted with an ephemeral ECDH key exchange and AES-GCM, and is bound to
+// the ISD-ASes and the epoch of the key. Level-2 keys are only served to hosts
+// in the local AS and are not encrypted.
+package drkey_mgmt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// Signer signs DRKey messages.
+type Signer interface {
+	Sign(ctx context.Context, msg []byte) (*proto.SignS, error)
+}
+
+// Verifier verifies the signature of DRKey messages.
+type Verifier interface {
+	Verify(ctx context.Context, msg []byte, sign *proto.SignS) error
+}
+
+func sign(ctx context.Context, signer Signer, input []byte) ([]byte, error) {
+	s, err := signer.Sign(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	// The signed input is implied by the message, only the signature
+	// metadata is encoded.
+	return proto.PackRoot(&proto.SignedBlobS{Sign: s})
+}
+
+func verify(ctx context.Context, verifier Verifier, input, rawSign []byte) error {
+	meta, err := signMeta(rawSign)
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(ctx, input, meta)
+}
+
+func signMeta(rawSign []byte) (*proto.SignS, error) {
+	if len(rawSign) == 0 {
+		return nil, serrors.New("signature missing")
+	}
+	s := &proto.SignedBlobS{}
+	if err := proto.ParseFromRaw(s, rawSign); err != nil {
+		return nil, serrors.WrapStr("parsing signature", err)
+	}
+	if s.Sign == nil {
+		return nil, serrors.New("signature metadata missing")
+	}
+	return s.Sign, nil
+}
+
+type union struct {
+	Which proto.DRKeyMgmt_Which
+	Req   *DRKeyReq `capnp:"drkeyReq"`
+	Rep   *DRKeyRep `capnp:"drkeyRep"`
+}
+
+func (u *union) set(c proto.Cerealizable) error {
+	switch p := c.(type) {
+	case *DRKeyReq:
+		u.Which = proto.DRKeyMgmt_Which_drkeyReq
+		u.Req = p
+	case *DRKeyRep:
+		u.Which = proto.DRKeyMgmt_Which_drkeyRep
+		u.Rep = p
+	default:
+		return common.NewBasicError("Unsupported drkey mgmt union type (set)", nil,
+			"type", common.TypeOf(c))
+	}
+	return nil
+}
+
+func (u *union) get() (proto.Cerealizable, error) {
+	switch u.Which {
+	case proto.DRKeyMgmt_Which_drkeyReq:
+		return u.Req, nil
+	case proto.DRKeyMgmt_Which_drkeyRep:
+		return u.Rep, nil
+	}
+	return nil, common.NewBasicError("Unsupported drkey mgmt union type (get)", nil,
+		"type", u.Which)
+}
+
+var _ proto.Cerealizable = (*Pld)(nil)
+
+type Pld struct {
+	union
+	*Data
+}
+
+// NewPld creates a new drkey mgmt payload, containing the supplied Cerealizable instance.
+func NewPld(u proto.Cerealizable, d *Data) (*Pld, error) {
+	p := &Pld{Data: d}
+	return p, p.union.set(u)
+}
+
+func (p *Pld) Union() (proto.Cerealizable, error) {
+	return p.union.get()
+}
+
+func (p *Pld) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyMgmt_TypeID
+}
+
+func (p *Pld) String() string {
+	desc := []string{"DRKeyMgmt: Union:"}
+	u, err := p.Union()
+	if err != nil {
+		desc = append(desc, err.Error())
+	} else {
+		desc = append(desc, fmt.Sprintf("%+v", u))
+	}
+	return strings.Join(desc, " ")
+}
+
+type Data struct {
+	// For passing any future non-union data.
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_mgmt_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestSerialize(t *testing.T) {
+	ia := xtest.MustParseIA("1-ff00:0:110")
+	testCases := map[string]proto.Cerealizable{
+		"request": drkey_mgmt.NewDRKeyReq(ia, time.Unix(1000, 0), true),
+		"reply": drkey_mgmt.NewDRKeyRep(ia, drkey.NewEpoch(900, 1200),
+			xtest.MustParseHexString("000102030405060708090a0b0c0d0e0f")),
+	}
+	for name, msg := range testCases {
+		name, msg := name, msg
+		t.Run(name, func(t *testing.T) {
+			pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, nil)
+			require.NoError(t, err)
+			raw, err := proto.PackRoot(pld)
+			require.NoError(t, err)
+			parsed, err := ctrl.NewPldFromRaw(raw)
+			require.NoError(t, err)
+			assert.Equal(t, proto.CtrlPld_Which_drkeyMgmt, parsed.Which)
+			got, err := parsed.DRKeyMgmt.Union()
+			require.NoError(t, err)
+			assert.Equal(t, msg, got)
+		})
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	ia := xtest.MustParseIA("1-ff00:0:110")
+	key := xtest.MustParseHexString("000102030405060708090a0b0c0d0e0f")
+	ctx := context.Background()
+
+	t.Run("request", func(t *testing.T) {
+		req := drkey_mgmt.NewDRKeyReq(ia, time.Unix(1000, 0), false)
+		assert.Error(t, req.Verify(ctx, testSigner{}), "unsigned")
+		require.NoError(t, req.Sign(ctx, testSigner{}))
+		assert.NoError(t, req.Verify(ctx, testSigner{}))
+		req.Flags.Prefetch = true
+		assert.Error(t, req.Verify(ctx, testSigner{}), "modified")
+	})
+	t.Run("reply", func(t *testing.T) {
+		rep := drkey_mgmt.NewDRKeyRep(ia, drkey.NewEpoch(900, 1200), key)
+		assert.Error(t, rep.Verify(ctx, testSigner{}), "unsigned")
+		require.NoError(t, rep.Sign(ctx, testSigner{}))
+		assert.NoError(t, rep.Verify(ctx, testSigner{}))
+		rep.Key = xtest.MustParseHexString("0f0e0d0c0b0a09080706050403020100")
+		assert.Error(t, rep.Verify(ctx, testSigner{}), "modified")
+	})
+}
+
+func TestEncryptedReply(t *testing.T) {
+	srcIA := xtest.MustParseIA("1-ff00:0:110")
+	dstIA := xtest.MustParseIA("1-ff00:0:111")
+	key := drkey.DRKey(xtest.MustParseHexString("000102030405060708090a0b0c0d0e0f"))
+	epoch := drkey.NewEpoch(900, 1200)
+	recipient, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	newRep := func(t *testing.T) *drkey_mgmt.DRKeyRep {
+		rep, err := drkey_mgmt.NewEncryptedDRKeyRep(srcIA, dstIA, epoch, key,
+			&recipient.PublicKey)
+		require.NoError(t, err)
+		assert.NotContains(t, string(rep.Key), string(key))
+		return rep
+	}
+	t.Run("valid", func(t *testing.T) {
+		rep := newRep(t)
+		decrypted, err := rep.DecryptKey(dstIA, recipient)
+		require.NoError(t, err)
+		assert.Equal(t, key, decrypted)
+	})
+	t.Run("wrong recipient", func(t *testing.T) {
+		rep := newRep(t)
+		_, err := rep.DecryptKey(dstIA, other)
+		assert.Error(t, err)
+	})
+	t.Run("wrong destination IA", func(t *testing.T) {
+		rep := newRep(t)
+		_, err := rep.DecryptKey(srcIA, recipient)
+		assert.Error(t, err)
+	})
+	t.Run("tampered cipher", func(t *testing.T) {
+		rep := newRep(t)
+		rep.Key[len(rep.Key)-1] ^= 0x01
+		_, err := rep.DecryptKey(dstIA, recipient)
+		assert.Error(t, err)
+	})
+	t.Run("tampered epoch", func(t *testing.T) {
+		rep := newRep(t)
+		rep.RawEpochEnd++
+		_, err := rep.DecryptKey(dstIA, recipient)
+		assert.Error(t, err)
+	})
+	t.Run("signature covers cipher", func(t *testing.T) {
+		ctx := context.Background()
+		rep := newRep(t)
+		require.NoError(t, rep.Sign(ctx, testSigner{}))
+		rep.Key[0] ^= 0x01
+		assert.Error(t, rep.Verify(ctx, testSigner{}))
+	})
+	t.Run("unsupported key", func(t *testing.T) {
+		_, err := drkey_mgmt.NewEncryptedDRKeyRep(srcIA, dstIA, epoch, key, []byte("key"))
+		assert.Error(t, err)
+	})
+}
+
+// testSigner uses the signature input as signature.
+type testSigner struct{}
+
+func (testSigner) Sign(_ context.Context, msg []byte) (*proto.SignS, error) {
+	return &proto.SignS{Src: []byte("test: src"), Signature: msg}, nil
+}
+
+func (testSigner) Verify(_ context.Context, msg []byte, sign *proto.SignS) error {
+	if !bytes.Equal(msg, sign.Signature) {
+		return serrors.New("invalid signature")
+	}
+	return nil
+}
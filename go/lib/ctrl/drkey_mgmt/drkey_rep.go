@@ -0,0 +1,122 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_mgmt
+
+import (
+	"context"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ proto.Cerealizable = (*DRKeyRep)(nil)
+
+// DRKeyRep is the reply to a DRKeyReq. It contains the requested key and its
+// epoch.
+type DRKeyRep struct {
+	RawSrcIA      addr.IAInt `capnp:"isdas"`
+	RawEpochBegin uint32     `capnp:"timestamp"`
+	RawEpochEnd   uint32     `capnp:"expTime"`
+	// Key is the key. In replies to level-1 requests, it is encrypted to the
+	// certificate key of the requesting AS, see the package documentation.
+	Key        []byte `capnp:"cipher"`
+	Signature  []byte
+	CertVerSrc uint32
+	CertVerDst uint32
+	TrcVer     uint32
+}
+
+// NewDRKeyRep creates a new reply containing the key.
+func NewDRKeyRep(srcIA addr.IA, epoch drkey.Epoch, key drkey.DRKey) *DRKeyRep {
+	return &DRKeyRep{
+		RawSrcIA:      srcIA.IAInt(),
+		RawEpochBegin: uint32(epoch.Begin.Unix()),
+		RawEpochEnd:   uint32(epoch.End.Unix()),
+		Key:           key,
+	}
+}
+
+// NewEncryptedDRKeyRep creates a new reply containing the key encrypted to
+// the public key pub of the certificate of dstIA.
+func NewEncryptedDRKeyRep(srcIA, dstIA addr.IA, epoch drkey.Epoch, key drkey.DRKey,
+	pub crypto.PublicKey) (*DRKeyRep, error) {
+
+	r := &DRKeyRep{
+		RawSrcIA:      srcIA.IAInt(),
+		RawEpochBegin: uint32(epoch.Begin.Unix()),
+		RawEpochEnd:   uint32(epoch.End.Unix()),
+	}
+	var err error
+	r.Key, err = encryptKey(key, pub, r.additionalData(dstIA))
+	if err != nil {
+		return nil, serrors.WrapStr("encrypting key", err)
+	}
+	return r, nil
+}
+
+// DecryptKey decrypts the key of a reply created with NewEncryptedDRKeyRep.
+// The private key priv must belong to the certificate of dstIA that the key
+// was encrypted to.
+func (r *DRKeyRep) DecryptKey(dstIA addr.IA, priv crypto.PrivateKey) (drkey.DRKey, error) {
+	return decryptKey(r.Key, priv, r.additionalData(dstIA))
+}
+
+func (r *DRKeyRep) additionalData(dstIA addr.IA) []byte {
+	return keyAdditionalData(r.SrcIA(), dstIA, r.RawEpochBegin, r.RawEpochEnd)
+}
+
+func (r *DRKeyRep) SrcIA() addr.IA {
+	return r.RawSrcIA.IA()
+}
+
+func (r *DRKeyRep) Epoch() drkey.Epoch {
+	return drkey.NewEpoch(r.RawEpochBegin, r.RawEpochEnd)
+}
+
+// Sign signs the reply and sets the signature field.
+func (r *DRKeyRep) Sign(ctx context.Context, signer Signer) error {
+	var err error
+	r.Signature, err = sign(ctx, signer, r.sigInput())
+	return err
+}
+
+// Verify verifies the signature of the reply.
+func (r *DRKeyRep) Verify(ctx context.Context, verifier Verifier) error {
+	return verify(ctx, verifier, r.sigInput(), r.Signature)
+}
+
+// sigInput returns the signature input (isdas, cipher, timestamp, expTime).
+func (r *DRKeyRep) sigInput() []byte {
+	input := make([]byte, 8, 16+len(r.Key))
+	binary.BigEndian.PutUint64(input, uint64(r.RawSrcIA))
+	input = append(input, r.Key...)
+	times := make([]byte, 8)
+	binary.BigEndian.PutUint32(times, r.RawEpochBegin)
+	binary.BigEndian.PutUint32(times[4:], r.RawEpochEnd)
+	return append(input, times...)
+}
+
+func (r *DRKeyRep) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyRep_TypeID
+}
+
+func (r *DRKeyRep) String() string {
+	return fmt.Sprintf("SrcIA: %s Epoch: %s Key: %s", r.SrcIA(), r.Epoch(), drkey.DRKey(r.Key))
+}
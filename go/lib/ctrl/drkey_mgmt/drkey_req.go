@@ -0,0 +1,104 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_mgmt
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/proto"
+)
+
+var _ proto.Cerealizable = (*DRKeyReq)(nil)
+
+// DRKeyReq is a request for a DRKey originating in the AS SrcIA.
+//
+// If the request is sent by another AS, it is a request for the level-1 key
+// from SrcIA to the requesting AS. If the request is sent by a host in the
+// local AS, it is a request for the level-2 AS-to-host key from SrcIA to the
+// requesting host.
+type DRKeyReq struct {
+	RawSrcIA addr.IAInt `capnp:"isdas"`
+	// RawValTime is the time, in seconds since Unix epoch, at which the key
+	// must be valid.
+	RawValTime uint32 `capnp:"timestamp"`
+	Signature  []byte
+	CertVer    uint32
+	TrcVer     uint32
+	Flags      DRKeyReqFlags
+}
+
+type DRKeyReqFlags struct {
+	// Prefetch indicates that the key of the epoch following the epoch
+	// containing the validity time is requested.
+	Prefetch bool
+}
+
+// NewDRKeyReq creates a new request for the key from srcIA valid at valTime.
+func NewDRKeyReq(srcIA addr.IA, valTime time.Time, prefetch bool) *DRKeyReq {
+	return &DRKeyReq{
+		RawSrcIA:   srcIA.IAInt(),
+		RawValTime: uint32(valTime.Unix()),
+		Flags:      DRKeyReqFlags{Prefetch: prefetch},
+	}
+}
+
+func (r *DRKeyReq) SrcIA() addr.IA {
+	return r.RawSrcIA.IA()
+}
+
+func (r *DRKeyReq) ValTime() time.Time {
+	return time.Unix(int64(r.RawValTime), 0)
+}
+
+// Sign signs the request and sets the signature field.
+func (r *DRKeyReq) Sign(ctx context.Context, signer Signer) error {
+	var err error
+	r.Signature, err = sign(ctx, signer, r.sigInput())
+	return err
+}
+
+// Verify verifies the signature of the request.
+func (r *DRKeyReq) Verify(ctx context.Context, verifier Verifier) error {
+	return verify(ctx, verifier, r.sigInput(), r.Signature)
+}
+
+// SignMeta returns the signature metadata of the request. It identifies the
+// certificate that signed the request.
+func (r *DRKeyReq) SignMeta() (*proto.SignS, error) {
+	return signMeta(r.Signature)
+}
+
+// sigInput returns the signature input (isdas, prefetch, timestamp).
+func (r *DRKeyReq) sigInput() []byte {
+	input := make([]byte, 13)
+	binary.BigEndian.PutUint64(input, uint64(r.RawSrcIA))
+	if r.Flags.Prefetch {
+		input[8] = 1
+	}
+	binary.BigEndian.PutUint32(input[9:], r.RawValTime)
+	return input
+}
+
+func (r *DRKeyReq) ProtoId() proto.ProtoIdType {
+	return proto.DRKeyReq_TypeID
+}
+
+func (r *DRKeyReq) String() string {
+	return fmt.Sprintf("SrcIA: %s ValTime: %v Flags: %+v", r.SrcIA(), r.ValTime(), r.Flags)
+}
@@ -26,6 +26,13 @@ var _ proto.Cerealizable = (*Poll)(nil)
 type Poll struct {
 	Addr    *Addr
 	Session SessionType
+	// PubKey is the ephemeral public key of the sender that is used to
+	// establish the key protecting the frames of the session. It is empty if
+	// the frames are not protected.
+	PubKey []byte
+	// Prefixes are the IP prefixes that the sender of a PollRep serves. They
+	// are only set in replies to polls of the default session.
+	Prefixes []*Prefix
 }
 
 func newPoll(a *Addr, s SessionType) *Poll {
@@ -0,0 +1,63 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sig_mgmt
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// Prefix is an IP prefix that a SIG announces to remote SIGs.
+type Prefix struct {
+	IP        []byte `capnp:"ip"`
+	PrefixLen uint8  `capnp:"prefixLen"`
+}
+
+// NewPrefix creates the prefix for ipnet.
+func NewPrefix(ipnet *net.IPNet) *Prefix {
+	ones, _ := ipnet.Mask.Size()
+	ip := ipnet.IP.To4()
+	if ip == nil {
+		ip = ipnet.IP.To16()
+	}
+	return &Prefix{
+		IP:        append([]byte(nil), ip...),
+		PrefixLen: uint8(ones),
+	}
+}
+
+// IPNet returns the prefix as a network. It returns an error if the address or
+// the prefix length are invalid, or if the address has bits set outside of the
+// prefix.
+func (p *Prefix) IPNet() (*net.IPNet, error) {
+	if len(p.IP) != net.IPv4len && len(p.IP) != net.IPv6len {
+		return nil, serrors.New("invalid address length", "len", len(p.IP))
+	}
+	if int(p.PrefixLen) > 8*len(p.IP) {
+		return nil, serrors.New("invalid prefix length", "prefix_len", p.PrefixLen)
+	}
+	mask := net.CIDRMask(int(p.PrefixLen), 8*len(p.IP))
+	ip := net.IP(p.IP)
+	if !ip.Mask(mask).Equal(ip) {
+		return nil, serrors.New("address has host bits set", "prefix", p)
+	}
+	return &net.IPNet{IP: append(net.IP(nil), p.IP...), Mask: mask}, nil
+}
+
+func (p *Prefix) String() string {
+	return fmt.Sprintf("%s/%d", net.IP(p.IP), p.PrefixLen)
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sig_mgmt_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestPrefixIPNet(t *testing.T) {
+	for _, s := range []string{"192.0.2.0/24", "0.0.0.0/0", "2001:db8::/32", "10.1.2.3/32"} {
+		t.Run(s, func(t *testing.T) {
+			_, ipnet, err := net.ParseCIDR(s)
+			require.NoError(t, err)
+			parsed, err := sig_mgmt.NewPrefix(ipnet).IPNet()
+			require.NoError(t, err)
+			assert.Equal(t, ipnet.String(), parsed.String())
+		})
+	}
+	invalid := map[string]*sig_mgmt.Prefix{
+		"address length": {IP: []byte{192, 0, 2}, PrefixLen: 24},
+		"prefix length":  {IP: []byte{192, 0, 2, 0}, PrefixLen: 33},
+		"host bits":      {IP: []byte{192, 0, 2, 1}, PrefixLen: 24},
+	}
+	for name, p := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := p.IPNet()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPollRepPrefixes(t *testing.T) {
+	_, ipnet, err := net.ParseCIDR("192.0.2.0/24")
+	require.NoError(t, err)
+	host := addr.HostIPv4(net.IP{192, 0, 2, 1})
+	rep := sig_mgmt.NewPollRep(sig_mgmt.NewAddr(host, 30256, host, 30056), 0)
+	rep.Prefixes = []*sig_mgmt.Prefix{sig_mgmt.NewPrefix(ipnet)}
+	pld, err := sig_mgmt.NewPld(1, rep)
+	require.NoError(t, err)
+	cpld, err := ctrl.NewPld(pld, nil)
+	require.NoError(t, err)
+	raw, err := proto.PackRoot(cpld)
+	require.NoError(t, err)
+
+	cpld, err = ctrl.NewPldFromRaw(raw)
+	require.NoError(t, err)
+	parsed, err := cpld.Union()
+	require.NoError(t, err)
+	require.IsType(t, &sig_mgmt.Pld{}, parsed)
+	u, err := parsed.(*sig_mgmt.Pld).Union()
+	require.NoError(t, err)
+	require.IsType(t, &sig_mgmt.PollRep{}, u)
+	assert.Equal(t, rep.Prefixes, u.(*sig_mgmt.PollRep).Prefixes)
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client contains the host side of the DRKey service. It fetches the
+// level-2 keys of the local host from the control service of the local AS.
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/infra/messenger/tcp"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// RPC is the interface used to request keys from the local control service.
+type RPC interface {
+	GetDRKey(ctx context.Context, msg *drkey_mgmt.DRKeyReq, a net.Addr,
+		id uint64) (*drkey_mgmt.DRKeyRep, error)
+}
+
+var _ snet.SCMPKeyProvider = (*SCMPKeys)(nil)
+
+// SCMPKeys fetches the AS-to-host keys that remote ASes use to authenticate
+// the SCMP messages they send to the local host. The keys are requested from
+// the local control service, which identifies the host by the source address
+// of the request. Fetched keys are cached until the end of their epoch.
+type SCMPKeys struct {
+	// RPC is used to request the keys.
+	RPC RPC
+	// CS is the address of the local control service.
+	CS net.Addr
+
+	mtx   sync.Mutex
+	cache map[addr.IA][]drkey.Lvl2Key
+}
+
+// NewSCMPKeys returns SCMPKeys that request the keys from a control service of
+// the local AS over TCP. The address of the control service is queried from
+// SCIOND.
+func NewSCMPKeys(ctx context.Context, sd sciond.Connector) (*SCMPKeys, error) {
+	reply, err := sd.SVCInfo(ctx, []proto.ServiceType{proto.ServiceType_cs})
+	if err != nil {
+		return nil, serrors.WrapStr("querying control service address", err)
+	}
+	if len(reply.Entries) == 0 || len(reply.Entries[0].HostInfos) == 0 {
+		return nil, serrors.New("no control service address")
+	}
+	cs := reply.Entries[0].HostInfos[0]
+	return &SCMPKeys{
+		RPC: tcp.NewClientMessenger(),
+		CS:  &net.TCPAddr{IP: cs.Host().IP(), Port: int(cs.Port)},
+	}, nil
+}
+
+// GetSCMPKey returns the AS-to-host key that srcIA uses to authenticate SCMP
+// messages sent to the local host at valTime.
+func (k *SCMPKeys) GetSCMPKey(ctx context.Context, srcIA addr.IA,
+	valTime time.Time) (common.RawBytes, error) {
+
+	if key, ok := k.cached(srcIA, valTime); ok {
+		return common.RawBytes(key.Key), nil
+	}
+	req := drkey_mgmt.NewDRKeyReq(srcIA, valTime, false)
+	rep, err := k.RPC.GetDRKey(ctx, req, k.CS, messenger.NextId())
+	if err != nil {
+		return nil, serrors.WrapStr("requesting key", err, "src_ia", srcIA)
+	}
+	if !rep.SrcIA().Equal(srcIA) {
+		return nil, serrors.New("reply for wrong ISD-AS",
+			"expected", srcIA, "actual", rep.SrcIA())
+	}
+	if !rep.Epoch().Contains(valTime) {
+		return nil, serrors.New("reply for wrong epoch",
+			"val_time", valTime, "epoch", rep.Epoch())
+	}
+	if len(rep.Key) != drkey.KeyLen {
+		return nil, serrors.New("invalid key length",
+			"expected", drkey.KeyLen, "actual", len(rep.Key))
+	}
+	key := drkey.Lvl2Key{
+		Lvl2Meta: drkey.Lvl2Meta{
+			KeyType:  drkey.AS2Host,
+			Protocol: drkey.ProtocolSCMP,
+			Epoch:    rep.Epoch(),
+			SrcIA:    srcIA,
+		},
+		Key: rep.Key,
+	}
+	k.store(key)
+	return common.RawBytes(key.Key), nil
+}
+
+func (k *SCMPKeys) cached(srcIA addr.IA, valTime time.Time) (drkey.Lvl2Key, bool) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	for _, key := range k.cache[srcIA] {
+		if key.Epoch.Contains(valTime) {
+			return key, true
+		}
+	}
+	return drkey.Lvl2Key{}, false
+}
+
+func (k *SCMPKeys) store(key drkey.Lvl2Key) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	if k.cache == nil {
+		k.cache = make(map[addr.IA][]drkey.Lvl2Key)
+	}
+	now := time.Now()
+	keys := []drkey.Lvl2Key{key}
+	for _, cached := range k.cache[key.SrcIA] {
+		if cached.Epoch.End.After(now) {
+			keys = append(keys, cached)
+		}
+	}
+	k.cache[key.SrcIA] = keys
+}
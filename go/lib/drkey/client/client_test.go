@@ -0,0 +1,151 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/drkey/client"
+	"github.com/scionproto/scion/go/lib/hostinfo"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/sciond/mock_sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/proto"
+)
+
+type rpcFunc func(*drkey_mgmt.DRKeyReq) (*drkey_mgmt.DRKeyRep, error)
+
+func (f rpcFunc) GetDRKey(_ context.Context, msg *drkey_mgmt.DRKeyReq, _ net.Addr,
+	_ uint64) (*drkey_mgmt.DRKeyRep, error) {
+
+	return f(msg)
+}
+
+func TestSCMPKeysGetSCMPKey(t *testing.T) {
+	ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+	defer cancelF()
+	srcIA := xtest.MustParseIA("1-ff00:0:110")
+	now := time.Now()
+	epoch := drkey.EpochAt(now, time.Hour)
+	key := drkey.DRKey("0123456789abcdef")
+
+	tests := map[string]struct {
+		Reply     *drkey_mgmt.DRKeyRep
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Reply:     drkey_mgmt.NewDRKeyRep(srcIA, epoch, key),
+			Assertion: assert.NoError,
+		},
+		"wrong ISD-AS": {
+			Reply: drkey_mgmt.NewDRKeyRep(xtest.MustParseIA("1-ff00:0:111"), epoch,
+				key),
+			Assertion: assert.Error,
+		},
+		"wrong epoch": {
+			Reply: drkey_mgmt.NewDRKeyRep(srcIA, drkey.EpochAt(now.Add(time.Hour),
+				time.Hour), key),
+			Assertion: assert.Error,
+		},
+		"invalid key": {
+			Reply:     drkey_mgmt.NewDRKeyRep(srcIA, epoch, key[:8]),
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			keys := &client.SCMPKeys{
+				RPC: rpcFunc(func(req *drkey_mgmt.DRKeyReq) (*drkey_mgmt.DRKeyRep, error) {
+					requests++
+					assert.Equal(t, srcIA, req.SrcIA())
+					return tc.Reply, nil
+				}),
+			}
+			k, err := keys.GetSCMPKey(ctx, srcIA, now)
+			tc.Assertion(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, common.RawBytes(key), k)
+			// The second lookup is served from the cache.
+			k, err = keys.GetSCMPKey(ctx, srcIA, now)
+			require.NoError(t, err)
+			assert.Equal(t, common.RawBytes(key), k)
+			assert.Equal(t, 1, requests)
+		})
+	}
+}
+
+func TestNewSCMPKeys(t *testing.T) {
+	cs := net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 30252}
+	tests := map[string]struct {
+		Reply     *sciond.ServiceInfoReply
+		Err       error
+		Expected  net.Addr
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Reply: &sciond.ServiceInfoReply{
+				Entries: []sciond.ServiceInfoReplyEntry{
+					{
+						ServiceType: proto.ServiceType_cs,
+						HostInfos:   []hostinfo.Host{hostinfo.FromUDPAddr(cs)},
+					},
+				},
+			},
+			Expected:  &net.TCPAddr{IP: cs.IP, Port: cs.Port},
+			Assertion: assert.NoError,
+		},
+		"no control service": {
+			Reply: &sciond.ServiceInfoReply{
+				Entries: []sciond.ServiceInfoReplyEntry{
+					{ServiceType: proto.ServiceType_cs},
+				},
+			},
+			Assertion: assert.Error,
+		},
+		"SCIOND error": {
+			Err:       serrors.New("test error"),
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			sd := mock_sciond.NewMockConnector(ctrl)
+			sd.EXPECT().SVCInfo(gomock.Any(), []proto.ServiceType{proto.ServiceType_cs}).
+				Return(tc.Reply, tc.Err)
+			keys, err := client.NewSCMPKeys(context.Background(), sd)
+			tc.Assertion(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.Expected.String(), keys.CS.String())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/infra/modules/db"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// ErrKeyNotFound indicates that the requested key is not available.
+var ErrKeyNotFound = serrors.New("key not found")
+
+// Lvl1DB is the database for level-1 keys. Implementations need to make sure
+// that the data in this DB is persistent.
+type Lvl1DB interface {
+	db.LimitSetter
+	io.Closer
+	// GetLvl1Key returns the level-1 key from srcIA to dstIA whose epoch
+	// contains valTime. If no such key is stored, ErrKeyNotFound is returned.
+	GetLvl1Key(ctx context.Context, srcIA, dstIA addr.IA, valTime time.Time) (Lvl1Key, error)
+	// InsertLvl1Key inserts the level-1 key. Inserting a key that is already
+	// stored is a no-op.
+	InsertLvl1Key(ctx context.Context, key Lvl1Key) error
+	// RemoveOutdatedLvl1Keys removes all keys that expired before the cutoff
+	// and returns the number of removed keys.
+	RemoveOutdatedLvl1Keys(ctx context.Context, cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/scrypto"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+const (
+	// KeyLen is the length of all keys in the DRKey hierarchy.
+	KeyLen = 16
+	// DefaultEpochDuration is the default duration of the secret value epochs.
+	// All services of an AS must use the same duration.
+	DefaultEpochDuration = 24 * time.Hour
+)
+
+var svSalt = []byte("Derive DRKey SV")
+
+// EpochAt returns the epoch of the given duration that contains t. Epochs are
+// aligned to multiples of the duration since Unix epoch, such that all entities
+// deriving keys with the same duration agree on the epoch boundaries.
+func EpochAt(t time.Time, duration time.Duration) Epoch {
+	secs := int64(duration / time.Second)
+	begin := t.Unix() - t.Unix()%secs
+	return NewEpoch(uint32(begin), uint32(begin+secs))
+}
+
+// DeriveSV derives the secret value of the epoch with the given duration that
+// contains t from the AS master key.
+func DeriveSV(masterKey []byte, t time.Time, duration time.Duration) (SV, error) {
+	if len(masterKey) == 0 {
+		return SV{}, serrors.New("master key must not be empty")
+	}
+	if duration < time.Second {
+		return SV{}, serrors.New("epoch duration too short", "duration", duration)
+	}
+	epoch := EpochAt(t, duration)
+	salt := make([]byte, len(svSalt)+8)
+	copy(salt, svSalt)
+	binary.BigEndian.PutUint32(salt[len(svSalt):], uint32(epoch.Begin.Unix()))
+	binary.BigEndian.PutUint32(salt[len(svSalt)+4:], uint32(epoch.End.Unix()))
+	return SV{
+		SVMeta: SVMeta{Epoch: epoch},
+		Key:    pbkdf2.Key(masterKey, salt, 1000, KeyLen, sha256.New),
+	}, nil
+}
+
+// DeriveLvl1 derives the level-1 key from the source AS, which owns the secret
+// value, to the destination AS. The epoch of the key is the epoch of the
+// secret value.
+func DeriveLvl1(srcIA, dstIA addr.IA, sv SV) (Lvl1Key, error) {
+	input := make([]byte, addr.IABytes)
+	dstIA.Write(input)
+	key, err := cmac(sv.Key, input)
+	if err != nil {
+		return Lvl1Key{}, err
+	}
+	return Lvl1Key{
+		Lvl1Meta: Lvl1Meta{
+			Epoch: sv.Epoch,
+			SrcIA: srcIA,
+			DstIA: dstIA,
+		},
+		Key: key,
+	}, nil
+}
+
+// DeriveLvl2 derives the level-2 key described by meta from the level-1 key.
+// The epoch, source and destination AS are taken from the level-1 key.
+func DeriveLvl2(meta Lvl2Meta, lvl1 Lvl1Key) (Lvl2Key, error) {
+	if len(meta.Protocol) == 0 || len(meta.Protocol) > 255 {
+		return Lvl2Key{}, serrors.New("invalid protocol length", "len", len(meta.Protocol))
+	}
+	input := []byte{byte(meta.KeyType), byte(len(meta.Protocol))}
+	input = append(input, meta.Protocol...)
+	switch meta.KeyType {
+	case AS2AS:
+	case AS2Host:
+		if meta.DstHost == nil {
+			return Lvl2Key{}, serrors.New("destination host required", "type", meta.KeyType)
+		}
+		input = appendHost(input, meta.DstHost)
+	case Host2Host:
+		if meta.SrcHost == nil || meta.DstHost == nil {
+			return Lvl2Key{}, serrors.New("source and destination host required",
+				"type", meta.KeyType)
+		}
+		input = appendHost(input, meta.SrcHost)
+		input = appendHost(input, meta.DstHost)
+	default:
+		return Lvl2Key{}, serrors.New("unsupported key type", "type", meta.KeyType)
+	}
+	key, err := cmac(lvl1.Key, input)
+	if err != nil {
+		return Lvl2Key{}, err
+	}
+	meta.Epoch = lvl1.Epoch
+	meta.SrcIA = lvl1.SrcIA
+	meta.DstIA = lvl1.DstIA
+	return Lvl2Key{Lvl2Meta: meta, Key: key}, nil
+}
+
+func appendHost(b []byte, host addr.HostAddr) []byte {
+	raw := host.Pack()
+	b = append(b, byte(host.Type()), byte(len(raw)))
+	return append(b, raw...)
+}
+
+func cmac(key DRKey, input []byte) (DRKey, error) {
+	mac, err := scrypto.InitMac(key)
+	if err != nil {
+		return nil, err
+	}
+	// Write must not return an error: https://godoc.org/hash#Hash
+	if _, err := mac.Write(input); err != nil {
+		panic(err)
+	}
+	return mac.Sum(nil), nil
+}
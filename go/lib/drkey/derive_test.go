@@ -0,0 +1,138 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	masterKey = []byte("0123456789abcdef")
+	srcIA     = xtest.MustParseIA("1-ff00:0:110")
+	dstIA     = xtest.MustParseIA("1-ff00:0:111")
+)
+
+func TestEpochAt(t *testing.T) {
+	epoch := drkey.EpochAt(time.Unix(1000, 0), 300*time.Second)
+	assert.Equal(t, drkey.NewEpoch(900, 1200), epoch)
+	assert.True(t, epoch.Contains(time.Unix(900, 0)))
+	assert.True(t, epoch.Contains(time.Unix(1199, 0)))
+	assert.False(t, epoch.Contains(time.Unix(1200, 0)))
+}
+
+func TestDeriveSV(t *testing.T) {
+	sv, err := drkey.DeriveSV(masterKey, time.Unix(1000, 0), 300*time.Second)
+	require.NoError(t, err)
+	assert.Len(t, sv.Key, drkey.KeyLen)
+	assert.Equal(t, drkey.NewEpoch(900, 1200), sv.Epoch)
+
+	same, err := drkey.DeriveSV(masterKey, time.Unix(1100, 0), 300*time.Second)
+	require.NoError(t, err)
+	assert.True(t, sv.Key.Equal(same.Key), "same epoch")
+
+	next, err := drkey.DeriveSV(masterKey, time.Unix(1200, 0), 300*time.Second)
+	require.NoError(t, err)
+	assert.False(t, sv.Key.Equal(next.Key), "next epoch")
+
+	_, err = drkey.DeriveSV(nil, time.Unix(1000, 0), 300*time.Second)
+	assert.Error(t, err)
+	_, err = drkey.DeriveSV(masterKey, time.Unix(1000, 0), time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestDeriveLvl1(t *testing.T) {
+	sv, err := drkey.DeriveSV(masterKey, time.Unix(1000, 0), 300*time.Second)
+	require.NoError(t, err)
+	key, err := drkey.DeriveLvl1(srcIA, dstIA, sv)
+	require.NoError(t, err)
+	assert.Len(t, key.Key, drkey.KeyLen)
+	assert.Equal(t, drkey.Lvl1Meta{Epoch: sv.Epoch, SrcIA: srcIA, DstIA: dstIA}, key.Lvl1Meta)
+
+	other, err := drkey.DeriveLvl1(srcIA, xtest.MustParseIA("1-ff00:0:112"), sv)
+	require.NoError(t, err)
+	assert.False(t, key.Key.Equal(other.Key))
+}
+
+func TestDeriveLvl2(t *testing.T) {
+	sv, err := drkey.DeriveSV(masterKey, time.Unix(1000, 0), 300*time.Second)
+	require.NoError(t, err)
+	lvl1, err := drkey.DeriveLvl1(srcIA, dstIA, sv)
+	require.NoError(t, err)
+
+	hostA := addr.HostFromIPStr("10.0.0.1")
+	hostB := addr.HostFromIPStr("10.0.0.2")
+	testCases := map[string]struct {
+		Meta         drkey.Lvl2Meta
+		ErrAssertion assert.ErrorAssertionFunc
+	}{
+		"as2as": {
+			Meta:         drkey.Lvl2Meta{KeyType: drkey.AS2AS, Protocol: "scmp"},
+			ErrAssertion: assert.NoError,
+		},
+		"as2host": {
+			Meta:         drkey.Lvl2Meta{KeyType: drkey.AS2Host, Protocol: "scmp", DstHost: hostA},
+			ErrAssertion: assert.NoError,
+		},
+		"host2host": {
+			Meta: drkey.Lvl2Meta{KeyType: drkey.Host2Host, Protocol: "scmp",
+				SrcHost: hostA, DstHost: hostB},
+			ErrAssertion: assert.NoError,
+		},
+		"as2host without host": {
+			Meta:         drkey.Lvl2Meta{KeyType: drkey.AS2Host, Protocol: "scmp"},
+			ErrAssertion: assert.Error,
+		},
+		"host2host without src host": {
+			Meta: drkey.Lvl2Meta{KeyType: drkey.Host2Host, Protocol: "scmp",
+				DstHost: hostB},
+			ErrAssertion: assert.Error,
+		},
+		"no protocol": {
+			Meta:         drkey.Lvl2Meta{KeyType: drkey.AS2AS},
+			ErrAssertion: assert.Error,
+		},
+		"unknown type": {
+			Meta:         drkey.Lvl2Meta{KeyType: 42, Protocol: "scmp"},
+			ErrAssertion: assert.Error,
+		},
+	}
+	keys := make(map[string]drkey.DRKey)
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			key, err := drkey.DeriveLvl2(tc.Meta, lvl1)
+			tc.ErrAssertion(t, err)
+			if err != nil {
+				return
+			}
+			assert.Len(t, key.Key, drkey.KeyLen)
+			assert.Equal(t, lvl1.Epoch, key.Epoch)
+			assert.Equal(t, srcIA, key.SrcIA)
+			assert.Equal(t, dstIA, key.DstIA)
+			for other, k := range keys {
+				assert.False(t, key.Key.Equal(k), "same key as %s", other)
+			}
+			keys[name] = key.Key
+		})
+	}
+}
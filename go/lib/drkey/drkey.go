@@ -0,0 +1,140 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drkey implements the dynamically recreatable key (DRKey)
+// hierarchy.
+//
+// Every AS derives a secret value (SV) per epoch from its master key. From
+// the secret value, the AS derives level-1 keys towards any other AS on the
+// fly. Level-1 keys are exchanged between the control services of the two
+// ASes. Level-2 keys are derived from level-1 keys for a specific protocol and,
+// depending on the key type, for specific end hosts.
+//
+// Because all derivations are deterministic, any entity with access to the
+// master key, e.g., a border router, can recreate the keys of its AS without
+// contacting the control service.
+package drkey
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+)
+
+// ProtocolSCMP is the protocol identifier of the level-2 keys used to
+// authenticate SCMP messages.
+const ProtocolSCMP = "scmp"
+
+// DRKey is a raw symmetric key of the DRKey hierarchy.
+type DRKey []byte
+
+// Equal compares the two keys in constant time.
+func (k DRKey) Equal(other DRKey) bool {
+	return subtle.ConstantTimeCompare(k, other) == 1
+}
+
+func (k DRKey) String() string {
+	return "[redacted key]"
+}
+
+// Epoch is the validity period of a key.
+type Epoch struct {
+	Begin time.Time
+	End   time.Time
+}
+
+// NewEpoch constructs an epoch from its begin and end timestamps, expressed in
+// seconds since Unix epoch.
+func NewEpoch(begin, end uint32) Epoch {
+	return Epoch{
+		Begin: time.Unix(int64(begin), 0).UTC(),
+		End:   time.Unix(int64(end), 0).UTC(),
+	}
+}
+
+// Contains indicates whether the time is inside the epoch. The begin of the
+// epoch is inclusive, the end is exclusive.
+func (e Epoch) Contains(t time.Time) bool {
+	return !t.Before(e.Begin) && t.Before(e.End)
+}
+
+func (e Epoch) String() string {
+	return fmt.Sprintf("[%s, %s)", e.Begin.Format(time.RFC3339), e.End.Format(time.RFC3339))
+}
+
+// SVMeta represents the information about a secret value.
+type SVMeta struct {
+	Epoch Epoch
+}
+
+// SV is a secret value of an AS for a given epoch.
+type SV struct {
+	SVMeta
+	Key DRKey
+}
+
+// Lvl1Meta represents the information about a level-1 key.
+type Lvl1Meta struct {
+	Epoch Epoch
+	SrcIA addr.IA
+	DstIA addr.IA
+}
+
+// Lvl1Key is a level-1 key from SrcIA to DstIA.
+type Lvl1Key struct {
+	Lvl1Meta
+	Key DRKey
+}
+
+// Lvl2KeyType is the type of a level-2 key.
+type Lvl2KeyType uint8
+
+// Level-2 key types.
+const (
+	AS2AS Lvl2KeyType = iota
+	AS2Host
+	Host2Host
+)
+
+func (t Lvl2KeyType) String() string {
+	switch t {
+	case AS2AS:
+		return "AS2AS"
+	case AS2Host:
+		return "AS2Host"
+	case Host2Host:
+		return "Host2Host"
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", uint8(t))
+}
+
+// Lvl2Meta represents the information about a level-2 key. SrcHost is only
+// used for Host2Host keys, DstHost for AS2Host and Host2Host keys.
+type Lvl2Meta struct {
+	KeyType  Lvl2KeyType
+	Protocol string
+	Epoch    Epoch
+	SrcIA    addr.IA
+	DstIA    addr.IA
+	SrcHost  addr.HostAddr
+	DstHost  addr.HostAddr
+}
+
+// Lvl2Key is a level-2 key for a specific protocol.
+type Lvl2Key struct {
+	Lvl2Meta
+	Key DRKey
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"sync"
+	"time"
+)
+
+// SecretValueStore derives and caches the secret values of the local AS.
+type SecretValueStore struct {
+	masterKey []byte
+	duration  time.Duration
+
+	mtx   sync.Mutex
+	cache map[int64]SV
+}
+
+// NewSecretValueStore creates a store that derives the secret values with the
+// given epoch duration from the master key.
+func NewSecretValueStore(masterKey []byte, epochDuration time.Duration) *SecretValueStore {
+	return &SecretValueStore{
+		masterKey: masterKey,
+		duration:  epochDuration,
+		cache:     make(map[int64]SV),
+	}
+}
+
+// Get returns the secret value of the epoch containing valTime.
+func (s *SecretValueStore) Get(valTime time.Time) (SV, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	begin := EpochAt(valTime, s.duration).Begin.Unix()
+	if sv, ok := s.cache[begin]; ok {
+		return sv, nil
+	}
+	sv, err := DeriveSV(s.masterKey, valTime, s.duration)
+	if err != nil {
+		return SV{}, err
+	}
+	now := time.Now()
+	for k, cached := range s.cache {
+		if !cached.Epoch.End.After(now) {
+			delete(s.cache, k)
+		}
+	}
+	s.cache[begin] = sv
+	return sv, nil
+}
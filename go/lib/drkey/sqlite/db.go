@@ -0,0 +1,145 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sqlite implements the DRKey databases with an SQLite backend.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra/modules/db"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+var _ drkey.Lvl1DB = DB{}
+
+// DB implements the level-1 key DB with an SQLite backend.
+type DB struct {
+	db *sql.DB
+	*executor
+}
+
+// New returns a new SQLite backend opening a database at the given path. If
+// no database exists a new database is be created. If the schema version of the
+// stored database is different from the one in schema.go, an error is returned.
+func New(path string) (DB, error) {
+	db, err := db.NewSqlite(path, Schema, SchemaVersion)
+	if err != nil {
+		return DB{}, err
+	}
+	return NewFromDB(db), nil
+}
+
+// NewFromDB returns a new backend from the given database.
+func NewFromDB(db *sql.DB) DB {
+	return DB{
+		db: db,
+		executor: &executor{
+			db: db,
+		},
+	}
+}
+
+// SetMaxOpenConns sets the maximum number of open connections.
+func (db DB) SetMaxOpenConns(maxOpenConns int) {
+	db.db.SetMaxOpenConns(maxOpenConns)
+}
+
+// SetMaxIdleConns sets the maximum number of idle connections.
+func (db DB) SetMaxIdleConns(maxIdleConns int) {
+	db.db.SetMaxIdleConns(maxIdleConns)
+}
+
+// Close closes the database.
+func (db DB) Close() error {
+	return db.db.Close()
+}
+
+type executor struct {
+	sync.RWMutex
+	db db.Sqler
+}
+
+func (e *executor) GetLvl1Key(ctx context.Context, srcIA, dstIA addr.IA,
+	valTime time.Time) (drkey.Lvl1Key, error) {
+
+	e.RLock()
+	defer e.RUnlock()
+
+	query := `SELECT epoch_begin, epoch_end, key FROM lvl1_keys
+			  WHERE src_isd_id=$1 AND src_as_id=$2 AND dst_isd_id=$3 AND dst_as_id=$4
+			  AND epoch_begin<=$5 AND epoch_end>$5`
+	var begin, end uint32
+	var key []byte
+	err := e.db.QueryRowContext(ctx, query, srcIA.I, srcIA.A, dstIA.I, dstIA.A,
+		uint32(valTime.Unix())).Scan(&begin, &end, &key)
+	switch {
+	case err == sql.ErrNoRows:
+		return drkey.Lvl1Key{}, serrors.WithCtx(drkey.ErrKeyNotFound,
+			"src_ia", srcIA, "dst_ia", dstIA, "val_time", valTime)
+	case err != nil:
+		return drkey.Lvl1Key{}, serrors.Wrap(db.ErrReadFailed, err)
+	}
+	return drkey.Lvl1Key{
+		Lvl1Meta: drkey.Lvl1Meta{
+			Epoch: drkey.NewEpoch(begin, end),
+			SrcIA: srcIA,
+			DstIA: dstIA,
+		},
+		Key: key,
+	}, nil
+}
+
+func (e *executor) InsertLvl1Key(ctx context.Context, key drkey.Lvl1Key) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if len(key.Key) != drkey.KeyLen {
+		return serrors.WithCtx(db.ErrInvalidInputData, "msg", "invalid key length",
+			"expected", drkey.KeyLen, "actual", len(key.Key))
+	}
+	query := `INSERT OR IGNORE INTO lvl1_keys (src_isd_id, src_as_id, dst_isd_id, dst_as_id,
+										epoch_begin, epoch_end, key)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := e.db.ExecContext(ctx, query, key.SrcIA.I, key.SrcIA.A, key.DstIA.I,
+		key.DstIA.A, uint32(key.Epoch.Begin.Unix()), uint32(key.Epoch.End.Unix()),
+		[]byte(key.Key))
+	if err != nil {
+		return serrors.Wrap(db.ErrWriteFailed, err)
+	}
+	return nil
+}
+
+func (e *executor) RemoveOutdatedLvl1Keys(ctx context.Context, cutoff time.Time) (int64, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	query := `DELETE FROM lvl1_keys WHERE epoch_end<=$1`
+	res, err := e.db.ExecContext(ctx, query, uint32(cutoff.Unix()))
+	if err != nil {
+		return 0, serrors.Wrap(db.ErrWriteFailed, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, serrors.Wrap(db.ErrWriteFailed, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/drkey/sqlite"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	srcIA = xtest.MustParseIA("1-ff00:0:110")
+	dstIA = xtest.MustParseIA("1-ff00:0:111")
+)
+
+func TestLvl1Keys(t *testing.T) {
+	ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+	defer cancelF()
+	db := newDatabase(t)
+	defer db.Close()
+
+	key := drkey.Lvl1Key{
+		Lvl1Meta: drkey.Lvl1Meta{
+			Epoch: drkey.NewEpoch(900, 1200),
+			SrcIA: srcIA,
+			DstIA: dstIA,
+		},
+		Key: drkey.DRKey(xtest.MustParseHexString("000102030405060708090a0b0c0d0e0f")),
+	}
+	next := key
+	next.Epoch = drkey.NewEpoch(1200, 1500)
+	next.Key = drkey.DRKey(xtest.MustParseHexString("0f0e0d0c0b0a09080706050403020100"))
+
+	require.NoError(t, db.InsertLvl1Key(ctx, key))
+	require.NoError(t, db.InsertLvl1Key(ctx, key), "insert twice")
+	require.NoError(t, db.InsertLvl1Key(ctx, next))
+	invalid := key
+	invalid.Key = drkey.DRKey{1, 2, 3}
+	assert.Error(t, db.InsertLvl1Key(ctx, invalid))
+
+	got, err := db.GetLvl1Key(ctx, srcIA, dstIA, time.Unix(1000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+	got, err = db.GetLvl1Key(ctx, srcIA, dstIA, time.Unix(1200, 0))
+	require.NoError(t, err)
+	assert.Equal(t, next, got)
+	_, err = db.GetLvl1Key(ctx, dstIA, srcIA, time.Unix(1000, 0))
+	assert.True(t, errors.Is(err, drkey.ErrKeyNotFound), err)
+
+	n, err := db.RemoveOutdatedLvl1Keys(ctx, time.Unix(1200, 0))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	_, err = db.GetLvl1Key(ctx, srcIA, dstIA, time.Unix(1000, 0))
+	assert.Error(t, err)
+	_, err = db.GetLvl1Key(ctx, srcIA, dstIA, time.Unix(1300, 0))
+	assert.NoError(t, err)
+}
+
+func newDatabase(t *testing.T) sqlite.DB {
+	db, err := sqlite.New("file::memory:")
+	require.NoError(t, err)
+	return db
+}
@@ -310,3 +310,59 @@ func TestHas(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadGroups(t *testing.T) {
+	group111 := &Group{
+		Id:         GroupId{OwnerAS: ia111.A, Suffix: 1},
+		Version:    2,
+		Owner:      ia111,
+		Writers:    []addr.IA{ia111},
+		Registries: []addr.IA{ia110},
+	}
+	tests := map[string]struct {
+		Files          []string
+		ExpectedGroups map[GroupId]*Group
+		ExpectedErrMsg string
+	}{
+		"no files": {
+			ExpectedGroups: map[GroupId]*Group{},
+		},
+		"valid": {
+			Files: []string{
+				"testdata/HPGCfg_ff00_0_110-69b5.json",
+				"testdata/HPGCfg_ff00_0_111-1.json",
+			},
+			ExpectedGroups: map[GroupId]*Group{
+				testGroup.Id: &testGroup,
+				group111.Id:  group111,
+			},
+		},
+		"duplicate": {
+			Files: []string{
+				"testdata/HPGCfg_ff00_0_111-1.json",
+				"testdata/HPGCfg_ff00_0_111-1.json",
+			},
+			ExpectedErrMsg: "group configured more than once",
+		},
+		"missing file": {
+			Files:          []string{"testdata/missing.json"},
+			ExpectedErrMsg: "reading group configuration",
+		},
+		"invalid": {
+			Files:          []string{"testdata/invalid.json"},
+			ExpectedErrMsg: "parsing group configuration",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			groups, err := LoadGroups(test.Files)
+			if test.ExpectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ExpectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedGroups, groups)
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/ifid"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/seg"
@@ -144,6 +146,10 @@ const (
 	HPSegReply
 	HPCfgRequest
 	HPCfgReply
+	ColibriRequest
+	ColibriResponse
+	DRKeyRequest
+	DRKeyReply
 )
 
 func (mt MessageType) String() string {
@@ -190,6 +196,14 @@ func (mt MessageType) String() string {
 		return "HPCfgRequest"
 	case HPCfgReply:
 		return "HPCfgReply"
+	case ColibriRequest:
+		return "ColibriRequest"
+	case ColibriResponse:
+		return "ColibriResponse"
+	case DRKeyRequest:
+		return "DRKeyRequest"
+	case DRKeyReply:
+		return "DRKeyReply"
 	default:
 		return fmt.Sprintf("Unknown (%d)", mt)
 	}
@@ -241,6 +255,14 @@ func (mt MessageType) MetricLabel() string {
 		return "hp_cfg_req"
 	case HPCfgReply:
 		return "hp_cfg_push"
+	case ColibriRequest:
+		return "colibri_req"
+	case ColibriResponse:
+		return "colibri_push"
+	case DRKeyRequest:
+		return "drkey_req"
+	case DRKeyReply:
+		return "drkey_push"
 	default:
 		return "unknown_mt"
 	}
@@ -318,10 +340,22 @@ type Messenger interface {
 	GetHPCfgs(ctx context.Context, msg *path_mgmt.HPCfgReq, a net.Addr,
 		id uint64) (*path_mgmt.HPCfgReply, error)
 	SendHPCfgReply(ctx context.Context, msg *path_mgmt.HPCfgReply, a net.Addr, id uint64) error
+	// SendColibriRequest sends a reliable COLIBRI request to address a.
+	SendColibriRequest(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload, a net.Addr,
+		id uint64) error
+	// SendColibriResponse sends a reliable COLIBRI response to address a.
+	SendColibriResponse(ctx context.Context, msg *colibri_mgmt.ColibriRequestPayload, a net.Addr,
+		id uint64) error
 	RequestChainRenewal(ctx context.Context, msg *cert_mgmt.ChainRenewalRequest, a net.Addr,
 		id uint64) (*cert_mgmt.ChainRenewalReply, error)
 	SendChainRenewalReply(ctx context.Context, msg *cert_mgmt.ChainRenewalReply, a net.Addr,
 		id uint64) error
+	// GetDRKey sends a drkey_mgmt.DRKeyReq to address a, blocks until it
+	// receives a reply and returns the reply.
+	GetDRKey(ctx context.Context, msg *drkey_mgmt.DRKeyReq, a net.Addr,
+		id uint64) (*drkey_mgmt.DRKeyRep, error)
+	// SendDRKeyReply sends a reliable drkey_mgmt.DRKeyRep to address a.
+	SendDRKeyReply(ctx context.Context, msg *drkey_mgmt.DRKeyRep, a net.Addr, id uint64) error
 	SendBeacon(ctx context.Context, msg *seg.Beacon, a net.Addr, id uint64) error
 	UpdateSigner(signer ctrl.Signer, types []MessageType)
 	UpdateVerifier(verifier Verifier)
@@ -339,6 +373,7 @@ type ResponseWriter interface {
 	SendIfStateInfoReply(ctx context.Context, msg *path_mgmt.IFStateInfos) error
 	SendHPSegReply(ctx context.Context, msg *path_mgmt.HPSegReply) error
 	SendHPCfgReply(ctx context.Context, msg *path_mgmt.HPCfgReply) error
+	SendDRKeyReply(ctx context.Context, msg *drkey_mgmt.DRKeyRep) error
 }
 
 func ResponseWriterFromContext(ctx context.Context) (ResponseWriter, bool) {
@@ -34,6 +34,10 @@
 //  infra.HPCfgReply          -> ctrl.SignedPld/ctrl.Pld/path_mgmt.HPCfgReply
 //  infra.ChainRenewalRequest   -> ctrl.SignedPld/ctrl.Pld/cert_mgmt.ChainRenewalRequest,
 //  infra.ChainRenewalReply     -> ctrl.SignedPld/ctrl.Pld/cert_mgmt.ChainRenewalReply,
+//  infra.ColibriRequest      -> ctrl.SignedPld/ctrl.Pld/colibri_mgmt.ColibriRequestPayload
+//  infra.ColibriResponse     -> ctrl.SignedPld/ctrl.Pld/colibri_mgmt.ColibriRequestPayload
+//  infra.DRKeyRequest        -> ctrl.SignedPld/ctrl.Pld/drkey_mgmt.DRKeyReq
+//  infra.DRKeyReply          -> ctrl.SignedPld/ctrl.Pld/drkey_mgmt.DRKeyRep
 //
 // To start processing messages received via the Messenger, call
 // ListenAndServe. The method runs in the current goroutine, and spawns new
@@ -86,6 +90,8 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/ctrl_msg"
 	"github.com/scionproto/scion/go/lib/ctrl/ifid"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
@@ -509,6 +515,20 @@ func (m *Messenger) SendHPCfgReply(ctx context.Context, msg *path_mgmt.HPCfgRepl
 	return m.getFallbackRequester(infra.HPCfgReply).Notify(ctx, pld, a)
 }
 
+// SendColibriRequest sends a reliable COLIBRI request to address a.
+func (m *Messenger) SendColibriRequest(ctx context.Context,
+	msg *colibri_mgmt.ColibriRequestPayload, a net.Addr, id uint64) error {
+
+	return m.sendMessage(ctx, msg, a, id, infra.ColibriRequest)
+}
+
+// SendColibriResponse sends a reliable COLIBRI response to address a.
+func (m *Messenger) SendColibriResponse(ctx context.Context,
+	msg *colibri_mgmt.ColibriRequestPayload, a net.Addr, id uint64) error {
+
+	return m.sendMessage(ctx, msg, a, id, infra.ColibriResponse)
+}
+
 func (m *Messenger) RequestChainRenewal(ctx context.Context,
 	msg *cert_mgmt.ChainRenewalRequest, a net.Addr,
 	id uint64) (*cert_mgmt.ChainRenewalReply, error) {
@@ -555,6 +575,54 @@ func (m *Messenger) SendChainRenewalReply(ctx context.Context, msg *cert_mgmt.Ch
 	return m.getFallbackRequester(infra.ChainRenewalReply).Notify(ctx, pld, a)
 }
 
+// GetDRKey sends a drkey_mgmt.DRKeyReq to address a, blocks until it receives
+// a reply and returns the reply. The request should be sent over QUIC, as the
+// reply contains the key in plain.
+func (m *Messenger) GetDRKey(ctx context.Context, msg *drkey_mgmt.DRKeyReq, a net.Addr,
+	id uint64) (*drkey_mgmt.DRKeyRep, error) {
+
+	logger := log.FromCtx(ctx)
+	data := &ctrl.Data{ReqId: id, TraceId: tracing.IDFromCtx(ctx)}
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("[Messenger] Sending request", "req_type", infra.DRKeyRequest,
+		"msg_id", id, "request", msg, "peer", a)
+	replyCtrlPld, err := m.getFallbackRequester(infra.DRKeyRequest).Request(ctx,
+		pld, a, false)
+	if err != nil {
+		return nil, common.NewBasicError("[Messenger] Request error", err,
+			"req_type", infra.DRKeyRequest)
+	}
+	_, replyMsg, err := Validate(replyCtrlPld)
+	if err != nil {
+		return nil, common.NewBasicError("[Messenger] Reply validation failed", err)
+	}
+	switch reply := replyMsg.(type) {
+	case *drkey_mgmt.DRKeyRep:
+		logger.Debug("[Messenger] Received reply", "req_id", id, "reply", reply)
+		return reply, nil
+	case *ack.Ack:
+		return nil, &infra.Error{Message: reply}
+	default:
+		err := newTypeAssertErr("*drkey_mgmt.DRKeyRep", replyMsg)
+		return nil, common.NewBasicError("[Messenger] Type assertion failed", err)
+	}
+}
+
+func (m *Messenger) SendDRKeyReply(ctx context.Context, msg *drkey_mgmt.DRKeyRep,
+	a net.Addr, id uint64) error {
+
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: id})
+	if err != nil {
+		return err
+	}
+	logger := log.FromCtx(ctx)
+	logger.Debug("[Messenger] Sending Notify", "type", infra.DRKeyReply, "to", a, "id", id)
+	return m.getFallbackRequester(infra.DRKeyReply).Notify(ctx, pld, a)
+}
+
 func (m *Messenger) SendBeacon(ctx context.Context, msg *seg.Beacon, a net.Addr, id uint64) error {
 	logger := log.FromCtx(ctx)
 	switch a.(type) {
@@ -1015,6 +1083,28 @@ func Validate(pld *ctrl.Pld) (infra.MessageType, proto.Cerealizable, error) {
 		}
 	case proto.CtrlPld_Which_ack:
 		return infra.Ack, pld.Ack, nil
+	case proto.CtrlPld_Which_colibri:
+		switch pld.Colibri.Which {
+		case proto.ColibriRequestPayload_Which_request:
+			return infra.ColibriRequest, pld.Colibri, nil
+		case proto.ColibriRequestPayload_Which_response:
+			return infra.ColibriResponse, pld.Colibri, nil
+		default:
+			return infra.None, nil,
+				common.NewBasicError("Unsupported SignedPld.CtrlPld.Colibri.Xxx message type",
+					nil, "capnp_which", pld.Colibri.Which)
+		}
+	case proto.CtrlPld_Which_drkeyMgmt:
+		switch pld.DRKeyMgmt.Which {
+		case proto.DRKeyMgmt_Which_drkeyReq:
+			return infra.DRKeyRequest, pld.DRKeyMgmt.Req, nil
+		case proto.DRKeyMgmt_Which_drkeyRep:
+			return infra.DRKeyReply, pld.DRKeyMgmt.Rep, nil
+		default:
+			return infra.None, nil,
+				common.NewBasicError("Unsupported SignedPld.CtrlPld.DRKeyMgmt.Xxx message type",
+					nil, "capnp_which", pld.DRKeyMgmt.Which)
+		}
 	default:
 		return infra.None, nil, common.NewBasicError("Unsupported SignedPld.Pld.Xxx message type",
 			nil, "capnp_which", pld.Which)
@@ -20,6 +20,7 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/rpc"
@@ -135,6 +136,19 @@ func (rw *QUICResponseWriter) SendHPCfgReply(ctx context.Context, msg *path_mgmt
 	return rw.sendMessage(ctx, ctrlPld)
 }
 
+func (rw *QUICResponseWriter) SendDRKeyReply(ctx context.Context, msg *drkey_mgmt.DRKeyRep) error {
+	go func() {
+		defer log.HandlePanic()
+		<-ctx.Done()
+		rw.ReplyWriter.Close()
+	}()
+	ctrlPld, err := ctrl.NewDRKeyMgmtPld(msg, nil, &ctrl.Data{ReqId: rw.ID})
+	if err != nil {
+		return err
+	}
+	return rw.sendMessage(ctx, ctrlPld)
+}
+
 func (rw *QUICResponseWriter) sendMessage(ctx context.Context, ctrlPld *ctrl.Pld) error {
 	signedCtrlPld, err := ctrlPld.SignedPld(ctx, infra.NullSigner)
 	if err != nil {
@@ -25,6 +25,7 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/ack"
 	"github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/messenger"
@@ -182,6 +183,77 @@ func (m *Messenger) GetSegs(ctx context.Context, msg *path_mgmt.SegReq, a net.Ad
 	}
 }
 
+// GetHPSegs asks the hidden path service at the remote address for the hidden
+// path segments that satisfy msg.
+func (m *Messenger) GetHPSegs(ctx context.Context, msg *path_mgmt.HPSegReq, a net.Addr,
+	id uint64) (*path_mgmt.HPSegReply, error) {
+
+	logger := log.FromCtx(ctx)
+	data := &ctrl.Data{ReqId: id, TraceId: tracing.IDFromCtx(ctx)}
+	pld, err := ctrl.NewPathMgmtPld(msg, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("[tcp-msger] Sending request", "req_type", infra.HPSegRequest,
+		"msg_id", id, "request", msg, "peer", a)
+	replyCtrlPld, err := m.client.Request(ctx, pld, a)
+	if err != nil {
+		return nil, serrors.WrapStr("[tcp-msger] request error", err,
+			"req_type", infra.HPSegRequest)
+	}
+	_, replyMsg, err := messenger.Validate(replyCtrlPld)
+	if err != nil {
+		return nil, serrors.WrapStr("[tcp-msger] reply validation failed", err)
+	}
+	switch reply := replyMsg.(type) {
+	case *path_mgmt.HPSegReply:
+		if err := reply.ParseRaw(); err != nil {
+			return nil, serrors.WrapStr("[tcp-msger] failed to parse reply", err)
+		}
+		logger.Debug("[tcp-msger] Received reply", "req_id", id)
+		return reply, nil
+	case *ack.Ack:
+		return nil, &infra.Error{Message: reply}
+	default:
+		return nil, serrors.New("[tcp-msger] Type assertion failed",
+			"msg", replyMsg, "type", "*path_mgmt.HPSegReply")
+	}
+}
+
+// GetDRKey asks the control service at the remote address for the DRKey
+// described by msg.
+func (m *Messenger) GetDRKey(ctx context.Context, msg *drkey_mgmt.DRKeyReq, a net.Addr,
+	id uint64) (*drkey_mgmt.DRKeyRep, error) {
+
+	logger := log.FromCtx(ctx)
+	data := &ctrl.Data{ReqId: id, TraceId: tracing.IDFromCtx(ctx)}
+	pld, err := ctrl.NewDRKeyMgmtPld(msg, nil, data)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("[tcp-msger] Sending request", "req_type", infra.DRKeyRequest,
+		"msg_id", id, "request", msg, "peer", a)
+	replyCtrlPld, err := m.client.Request(ctx, pld, a)
+	if err != nil {
+		return nil, serrors.WrapStr("[tcp-msger] request error", err,
+			"req_type", infra.DRKeyRequest)
+	}
+	_, replyMsg, err := messenger.Validate(replyCtrlPld)
+	if err != nil {
+		return nil, serrors.WrapStr("[tcp-msger] reply validation failed", err)
+	}
+	switch reply := replyMsg.(type) {
+	case *drkey_mgmt.DRKeyRep:
+		logger.Debug("[tcp-msger] Received reply", "req_id", id, "reply", reply)
+		return reply, nil
+	case *ack.Ack:
+		return nil, &infra.Error{Message: reply}
+	default:
+		return nil, serrors.New("[tcp-msger] Type assertion failed",
+			"msg", replyMsg, "type", "*drkey_mgmt.DRKeyRep")
+	}
+}
+
 func (m *Messenger) AddHandler(msgType infra.MessageType, h infra.Handler) {
 	m.Handler.Handle(msgType, h)
 }
@@ -11,6 +11,8 @@ import (
 	ctrl "github.com/scionproto/scion/go/lib/ctrl"
 	ack "github.com/scionproto/scion/go/lib/ctrl/ack"
 	cert_mgmt "github.com/scionproto/scion/go/lib/ctrl/cert_mgmt"
+	colibri_mgmt "github.com/scionproto/scion/go/lib/ctrl/colibri_mgmt"
+	drkey_mgmt "github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
 	ifid "github.com/scionproto/scion/go/lib/ctrl/ifid"
 	path_mgmt "github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	seg "github.com/scionproto/scion/go/lib/ctrl/seg"
@@ -84,6 +86,21 @@ func (mr *MockMessengerMockRecorder) GetCertChain(arg0, arg1, arg2, arg3 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertChain", reflect.TypeOf((*MockMessenger)(nil).GetCertChain), arg0, arg1, arg2, arg3)
 }
 
+// GetDRKey mocks base method
+func (m *MockMessenger) GetDRKey(arg0 context.Context, arg1 *drkey_mgmt.DRKeyReq, arg2 net.Addr, arg3 uint64) (*drkey_mgmt.DRKeyRep, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDRKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*drkey_mgmt.DRKeyRep)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDRKey indicates an expected call of GetDRKey
+func (mr *MockMessengerMockRecorder) GetDRKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDRKey", reflect.TypeOf((*MockMessenger)(nil).GetDRKey), arg0, arg1, arg2, arg3)
+}
+
 // GetHPCfgs mocks base method
 func (m *MockMessenger) GetHPCfgs(arg0 context.Context, arg1 *path_mgmt.HPCfgReq, arg2 net.Addr, arg3 uint64) (*path_mgmt.HPCfgReply, error) {
 	m.ctrl.T.Helper()
@@ -227,6 +244,48 @@ func (mr *MockMessengerMockRecorder) SendChainRenewalReply(arg0, arg1, arg2, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChainRenewalReply", reflect.TypeOf((*MockMessenger)(nil).SendChainRenewalReply), arg0, arg1, arg2, arg3)
 }
 
+// SendColibriRequest mocks base method
+func (m *MockMessenger) SendColibriRequest(arg0 context.Context, arg1 *colibri_mgmt.ColibriRequestPayload, arg2 net.Addr, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendColibriRequest", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendColibriRequest indicates an expected call of SendColibriRequest
+func (mr *MockMessengerMockRecorder) SendColibriRequest(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendColibriRequest", reflect.TypeOf((*MockMessenger)(nil).SendColibriRequest), arg0, arg1, arg2, arg3)
+}
+
+// SendColibriResponse mocks base method
+func (m *MockMessenger) SendColibriResponse(arg0 context.Context, arg1 *colibri_mgmt.ColibriRequestPayload, arg2 net.Addr, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendColibriResponse", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendColibriResponse indicates an expected call of SendColibriResponse
+func (mr *MockMessengerMockRecorder) SendColibriResponse(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendColibriResponse", reflect.TypeOf((*MockMessenger)(nil).SendColibriResponse), arg0, arg1, arg2, arg3)
+}
+
+// SendDRKeyReply mocks base method
+func (m *MockMessenger) SendDRKeyReply(arg0 context.Context, arg1 *drkey_mgmt.DRKeyRep, arg2 net.Addr, arg3 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDRKeyReply", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDRKeyReply indicates an expected call of SendDRKeyReply
+func (mr *MockMessengerMockRecorder) SendDRKeyReply(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDRKeyReply", reflect.TypeOf((*MockMessenger)(nil).SendDRKeyReply), arg0, arg1, arg2, arg3)
+}
+
 // SendHPCfgReply mocks base method
 func (m *MockMessenger) SendHPCfgReply(arg0 context.Context, arg1 *path_mgmt.HPCfgReply, arg2 net.Addr, arg3 uint64) error {
 	m.ctrl.T.Helper()
@@ -442,6 +501,20 @@ func (mr *MockResponseWriterMockRecorder) SendChainRenewalReply(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChainRenewalReply", reflect.TypeOf((*MockResponseWriter)(nil).SendChainRenewalReply), arg0, arg1)
 }
 
+// SendDRKeyReply mocks base method
+func (m *MockResponseWriter) SendDRKeyReply(arg0 context.Context, arg1 *drkey_mgmt.DRKeyRep) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDRKeyReply", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDRKeyReply indicates an expected call of SendDRKeyReply
+func (mr *MockResponseWriterMockRecorder) SendDRKeyReply(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDRKeyReply", reflect.TypeOf((*MockResponseWriter)(nil).SendDRKeyReply), arg0, arg1)
+}
+
 // SendHPCfgReply mocks base method
 func (m *MockResponseWriter) SendHPCfgReply(arg0 context.Context, arg1 *path_mgmt.HPCfgReply) error {
 	m.ctrl.T.Helper()
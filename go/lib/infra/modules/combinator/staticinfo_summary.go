@@ -0,0 +1,105 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package combinator
+
+import (
+	"math"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/sciond"
+)
+
+// StaticInfoSummary condenses the static information collected for the path
+// into the representation used by the SCIOND API: latencies and internal hops
+// are summed up, the bandwidth is the minimum announced bandwidth, and the
+// per-AS information is ordered along the path. It returns nil if none of the
+// ASes on the path announced static information.
+func (p *Path) StaticInfoSummary() *sciond.PathMetadata {
+	m := p.StaticInfo
+	if m == nil || m.isEmpty() {
+		return nil
+	}
+	var latency, hops uint64
+	for _, l := range m.ASLatencies {
+		latency += uint64(l.IntraLatency) + uint64(l.InterLatency) + uint64(l.PeerLatency)
+	}
+	for _, h := range m.ASHops {
+		hops += uint64(h.Hops)
+	}
+	var bw uint32
+	for _, b := range m.ASBandwidths {
+		bw = minNonZero(bw, b.IntraBW)
+		bw = minNonZero(bw, b.InterBW)
+	}
+	res := &sciond.PathMetadata{
+		TotalLatency:     uint16(saturate(latency, math.MaxUint16)),
+		TotalHops:        uint8(saturate(hops, math.MaxUint8)),
+		MinimalBandwidth: bw,
+	}
+	for _, ia := range p.orderedIAs() {
+		if l, ok := m.Links[ia]; ok {
+			res.LinkTypes = append(res.LinkTypes, sciond.InterfaceLinkType{
+				InterLinkType: l.InterLinkType,
+				PeerLinkType:  l.PeerLinkType,
+				RawIsdas:      ia.IAInt(),
+			})
+		}
+		if g, ok := m.Geo[ia]; ok {
+			geo := sciond.Geo{RawIsdas: ia.IAInt()}
+			for _, loc := range g.Locations {
+				geo.RouterLocations = append(geo.RouterLocations, sciond.GPSData{
+					Latitude:  loc.Latitude,
+					Longitude: loc.Longitude,
+					Address:   loc.Address,
+				})
+			}
+			res.ASLocations = append(res.ASLocations, geo)
+		}
+		if n, ok := m.Notes[ia]; ok && n.Note != "" {
+			res.Notes = append(res.Notes, sciond.Note{Note: n.Note, RawIsdas: ia.IAInt()})
+		}
+	}
+	return res
+}
+
+// orderedIAs returns the ASes on the path, in the order they are traversed.
+func (p *Path) orderedIAs() []addr.IA {
+	var ias []addr.IA
+	for _, intf := range p.Interfaces {
+		if len(ias) == 0 || !ias[len(ias)-1].Equal(intf.IA()) {
+			ias = append(ias, intf.IA())
+		}
+	}
+	return ias
+}
+
+func (m *PathMetadata) isEmpty() bool {
+	return len(m.ASLatencies) == 0 && len(m.ASBandwidths) == 0 && len(m.ASHops) == 0 &&
+		len(m.Geo) == 0 && len(m.Links) == 0 && len(m.Notes) == 0
+}
+
+func minNonZero(a, b uint32) uint32 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func saturate(v, max uint64) uint64 {
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package combinator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestStaticInfoSummary(t *testing.T) {
+	ia1 := xtest.MustParseIA("1-ff00:0:111")
+	ia2 := xtest.MustParseIA("1-ff00:0:110")
+	ia3 := xtest.MustParseIA("1-ff00:0:112")
+	interfaces := []sciond.PathInterface{
+		{RawIsdas: ia1.IAInt(), IfID: 1},
+		{RawIsdas: ia2.IAInt(), IfID: 2},
+		{RawIsdas: ia2.IAInt(), IfID: 3},
+		{RawIsdas: ia3.IAInt(), IfID: 4},
+	}
+	testCases := map[string]struct {
+		Path     *Path
+		Expected *sciond.PathMetadata
+	}{
+		"no static info": {
+			Path: &Path{Interfaces: interfaces},
+		},
+		"empty static info": {
+			Path: &Path{
+				Interfaces: interfaces,
+				StaticInfo: &PathMetadata{},
+			},
+		},
+		"full static info": {
+			Path: &Path{
+				Interfaces: interfaces,
+				StaticInfo: &PathMetadata{
+					ASLatencies: map[addr.IA]ASLatency{
+						ia2: {IntraLatency: 10, InterLatency: 5},
+						ia3: {InterLatency: 7, PeerLatency: 3},
+					},
+					ASBandwidths: map[addr.IA]ASBandwidth{
+						ia2: {IntraBW: 1000, InterBW: 400},
+						ia3: {InterBW: 500},
+					},
+					ASHops: map[addr.IA]ASHops{
+						ia2: {Hops: 2},
+						ia3: {},
+					},
+					Geo: map[addr.IA]ASGeo{
+						ia1: {Locations: []GeoLoc{{Latitude: 47.3, Longitude: 8.5, Address: "a"}}},
+						ia3: {Locations: []GeoLoc{{Latitude: 46.9, Longitude: 7.4, Address: "b"}}},
+					},
+					Links: map[addr.IA]ASLink{
+						ia3: {InterLinkType: 2},
+						ia2: {InterLinkType: 1, PeerLinkType: 0},
+					},
+					Notes: map[addr.IA]ASnote{
+						ia1: {Note: "first"},
+						ia2: {},
+					},
+				},
+			},
+			Expected: &sciond.PathMetadata{
+				TotalLatency:     25,
+				TotalHops:        2,
+				MinimalBandwidth: 400,
+				LinkTypes: []sciond.InterfaceLinkType{
+					{InterLinkType: 1, RawIsdas: ia2.IAInt()},
+					{InterLinkType: 2, RawIsdas: ia3.IAInt()},
+				},
+				ASLocations: []sciond.Geo{
+					{
+						RouterLocations: []sciond.GPSData{
+							{Latitude: 47.3, Longitude: 8.5, Address: "a"},
+						},
+						RawIsdas: ia1.IAInt(),
+					},
+					{
+						RouterLocations: []sciond.GPSData{
+							{Latitude: 46.9, Longitude: 7.4, Address: "b"},
+						},
+						RawIsdas: ia3.IAInt(),
+					},
+				},
+				Notes: []sciond.Note{{Note: "first", RawIsdas: ia1.IAInt()}},
+			},
+		},
+		"saturated latency": {
+			Path: &Path{
+				Interfaces: interfaces,
+				StaticInfo: &PathMetadata{
+					ASLatencies: map[addr.IA]ASLatency{
+						ia2: {IntraLatency: 40000},
+						ia3: {InterLatency: 40000},
+					},
+				},
+			},
+			Expected: &sciond.PathMetadata{
+				TotalLatency: 65535,
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, tc.Path.StaticInfoSummary())
+		})
+	}
+}
@@ -35,6 +35,13 @@ var (
 	ErrNoPaths = errors.New("no paths found")
 )
 
+// HiddenFetcher fetches verified hidden path segments.
+type HiddenFetcher interface {
+	// FetchHidden returns the hidden down segments that end at the
+	// destination.
+	FetchHidden(ctx context.Context, dst addr.IA) (Segments, error)
+}
+
 // Pather is used to construct paths from the path database. If necessary, paths
 // are fetched over the network.
 type Pather struct {
@@ -43,6 +50,9 @@ type Pather struct {
 	Fetcher      *Fetcher
 	Splitter     Splitter
 	HeaderV2     bool
+	// Hidden is optional. If it is set, the hidden segments it returns are
+	// combined with the public segments.
+	Hidden HiddenFetcher
 }
 
 // GetPaths returns all non-revoked and non-expired paths to the destination.
@@ -68,6 +78,15 @@ func (p *Pather) GetPaths(ctx context.Context, dst addr.IA,
 	if err != nil {
 		return nil, err
 	}
+	if p.Hidden != nil && !dst.IsWildcard() {
+		hidden, err := p.Hidden.FetchHidden(ctx, dst)
+		if err != nil {
+			// Continue, the public segments might still be usable.
+			log.FromCtx(ctx).Info("[segfetcher.Pather] Failed to fetch hidden segments",
+				"dst", dst, "err", err)
+		}
+		segs = mergeSegments(segs, hidden)
+	}
 	paths := p.buildAllPaths(src, dst, segs)
 	paths, err = p.filterRevoked(ctx, paths)
 	if err != nil {
@@ -142,6 +161,27 @@ func (p *Pather) filterRevoked(ctx context.Context,
 	return newPaths, nil
 }
 
+// mergeSegments appends the segments of b to a that are not already contained
+// in a.
+func mergeSegments(a, b Segments) Segments {
+	if len(b) == 0 {
+		return a
+	}
+	known := make(map[string]struct{}, len(a))
+	for _, s := range a {
+		known[string(s.Segment.FullID())] = struct{}{}
+	}
+	for _, s := range b {
+		id := string(s.Segment.FullID())
+		if _, ok := known[id]; ok {
+			continue
+		}
+		known[id] = struct{}{}
+		a = append(a, s)
+	}
+	return a
+}
+
 // categorizeSegs splits a flat list of segments with type info into one
 // separate list per segment type.
 func categorizeSegs(segs Segments) (up, core, down seg.Segments) {
@@ -18,6 +18,8 @@ import (
 	"fmt"
 
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/spse"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
 )
 
 func ExtensionFactory(class common.L4ProtocolType, extension *Extension) (common.Extension, error) {
@@ -33,6 +35,11 @@ func ExtensionFactory(class common.L4ProtocolType, extension *Extension) (common
 		}
 	case common.End2EndClass:
 		switch extension.Type {
+		case common.ExtnSCIONPacketSecurityType.Type:
+			if len(extension.Data) > 0 && spse.SecMode(extension.Data[0]) == spse.ScmpAuthDRKey {
+				return scmp_auth.NewDRKeyExtnFromRaw(extension.Data)
+			}
+			return NewExtnUnknownFromLayer(common.End2EndClass, extension)
 		default:
 			return NewExtnUnknownFromLayer(common.End2EndClass, extension)
 		}
@@ -0,0 +1,183 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// OrderMetric is a property of a path that paths can be ordered by.
+type OrderMetric string
+
+const (
+	// OrderLatency orders by the total latency from the static info.
+	OrderLatency OrderMetric = "latency"
+	// OrderBandwidth orders by the bottleneck bandwidth from the static info.
+	OrderBandwidth OrderMetric = "bandwidth"
+	// OrderHops orders by the number of inter-AS links.
+	OrderHops OrderMetric = "hops"
+	// OrderExpiry orders by the expiration time of the path.
+	OrderExpiry OrderMetric = "expiry"
+	// OrderMTU orders by the MTU of the path.
+	OrderMTU OrderMetric = "mtu"
+)
+
+// value returns the value of the metric for the path. If the value is not
+// available for the path, ok is false.
+func (m OrderMetric) value(path Path) (v int64, ok bool) {
+	switch m {
+	case OrderLatency:
+		if info := staticInfo(path); info != nil {
+			return int64(info.Latency), true
+		}
+	case OrderBandwidth:
+		if info := staticInfo(path); info != nil && info.Bandwidth != 0 {
+			return int64(info.Bandwidth), true
+		}
+	case OrderHops:
+		return int64(len(path.Interfaces()) / 2), true
+	case OrderExpiry:
+		if md := metadata(path); md != nil {
+			return md.Expiry().UnixNano(), true
+		}
+	case OrderMTU:
+		if md := metadata(path); md != nil {
+			return int64(md.MTU()), true
+		}
+	}
+	return 0, false
+}
+
+// OrderKey is a single ordering clause. It is represented as a string in JSON,
+// consisting of the metric and optionally the direction, e.g. "latency asc" or
+// "expiry desc". The direction defaults to ascending.
+type OrderKey struct {
+	Metric     OrderMetric
+	Descending bool
+}
+
+// OrderKeyFromString parses an ordering clause.
+func OrderKeyFromString(str string) (OrderKey, error) {
+	parts := strings.Fields(str)
+	if len(parts) == 0 || len(parts) > 2 {
+		return OrderKey{}, serrors.New("order key must be of the form \"<metric> [asc|desc]\"",
+			"value", str)
+	}
+	key := OrderKey{Metric: OrderMetric(strings.ToLower(parts[0]))}
+	switch key.Metric {
+	case OrderLatency, OrderBandwidth, OrderHops, OrderExpiry, OrderMTU:
+	default:
+		return OrderKey{}, serrors.New("unknown order metric", "value", str)
+	}
+	if len(parts) == 2 {
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+		case "desc":
+			key.Descending = true
+		default:
+			return OrderKey{}, serrors.New("unknown order direction", "value", str)
+		}
+	}
+	return key, nil
+}
+
+func (k OrderKey) String() string {
+	if k.Descending {
+		return string(k.Metric) + " desc"
+	}
+	return string(k.Metric) + " asc"
+}
+
+func (k OrderKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+func (k *OrderKey) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	var err error
+	*k, err = OrderKeyFromString(str)
+	return err
+}
+
+// compare compares the two paths with respect to the key. It returns a
+// negative value if a comes first, a positive value if b comes first, and 0
+// otherwise. Paths for which the metric is not available always come last.
+func (k OrderKey) compare(a, b Path) int {
+	va, okA := k.Metric.value(a)
+	vb, okB := k.Metric.value(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return 1
+	case !okB:
+		return -1
+	}
+	cmp := 0
+	if va < vb {
+		cmp = -1
+	} else if va > vb {
+		cmp = 1
+	}
+	if k.Descending {
+		return -cmp
+	}
+	return cmp
+}
+
+// Order is a list of ordering clauses. Paths are ordered by the first clause;
+// paths that are equal with respect to it are ordered by the next one, and so
+// on.
+type Order []OrderKey
+
+// Sort sorts the paths according to the order. The sort is stable, paths that
+// are equal with respect to all clauses keep their relative position.
+func (o Order) Sort(paths []Path) {
+	if len(o) == 0 {
+		return
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		for _, key := range o {
+			if cmp := key.compare(paths[i], paths[j]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+}
+
+// MetadataPath is a path that carries metadata, such as snet.Path.
+type MetadataPath interface {
+	Path
+	// Metadata returns the metadata of the path, or nil if it is not
+	// available.
+	Metadata() snet.PathMetadata
+}
+
+// metadata returns the metadata of the path, or nil if it is not available.
+func metadata(path Path) snet.PathMetadata {
+	if p, ok := path.(MetadataPath); ok {
+		return p.Metadata()
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestOrderKeyFromString(t *testing.T) {
+	tests := map[string]struct {
+		Input       string
+		Expected    OrderKey
+		ExpectedErr bool
+	}{
+		"default direction": {
+			Input:    "latency",
+			Expected: OrderKey{Metric: OrderLatency},
+		},
+		"ascending": {
+			Input:    "hops asc",
+			Expected: OrderKey{Metric: OrderHops},
+		},
+		"descending": {
+			Input:    "Expiry DESC",
+			Expected: OrderKey{Metric: OrderExpiry, Descending: true},
+		},
+		"empty": {
+			Input:       "",
+			ExpectedErr: true,
+		},
+		"unknown metric": {
+			Input:       "cost asc",
+			ExpectedErr: true,
+		},
+		"unknown direction": {
+			Input:       "mtu up",
+			ExpectedErr: true,
+		},
+		"too many parts": {
+			Input:       "mtu asc desc",
+			ExpectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, err := OrderKeyFromString(test.Input)
+			if test.ExpectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, key)
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	ia1 := xtest.MustParseIA("1-ff00:0:110")
+	ia2 := xtest.MustParseIA("1-ff00:0:111")
+	ia3 := xtest.MustParseIA("1-ff00:0:112")
+	now := time.Now()
+	newPath := func(hops int, latency time.Duration, bw uint64, expiry time.Time) Path {
+		ias := []snet.PathInterface{
+			testPathIntf{ia: ia1, ifid: 1},
+			testPathIntf{ia: ia2, ifid: 2},
+			testPathIntf{ia: ia2, ifid: 3},
+			testPathIntf{ia: ia3, ifid: 4},
+		}
+		return &metadataPath{
+			testPath: testPath{interfaces: ias[:hops*2]},
+			metadata: testMetadata{
+				expiry: expiry,
+				info:   &snet.PathStaticInfo{Latency: latency, Bandwidth: bw},
+			},
+		}
+	}
+	paths := PathSet{
+		"a": newPath(1, 30*time.Millisecond, 1000, now.Add(time.Hour)),
+		"b": newPath(2, 10*time.Millisecond, 0, now.Add(2*time.Hour)),
+		"c": newPath(2, 10*time.Millisecond, 5000, now.Add(time.Hour)),
+		"d": &testPath{interfaces: []snet.PathInterface{testPathIntf{ia: ia1, ifid: 5}}},
+	}
+	tests := map[string]struct {
+		Order    Order
+		Expected []Path
+	}{
+		"no order": {
+			Expected: []Path{paths["a"], paths["b"], paths["c"], paths["d"]},
+		},
+		"latency asc, hops asc, expiry desc": {
+			Order: Order{
+				{Metric: OrderLatency},
+				{Metric: OrderHops},
+				{Metric: OrderExpiry, Descending: true},
+			},
+			Expected: []Path{paths["b"], paths["c"], paths["a"], paths["d"]},
+		},
+		"bandwidth desc": {
+			Order:    Order{{Metric: OrderBandwidth, Descending: true}},
+			Expected: []Path{paths["c"], paths["a"], paths["b"], paths["d"]},
+		},
+		"hops desc": {
+			Order:    Order{{Metric: OrderHops, Descending: true}},
+			Expected: []Path{paths["b"], paths["c"], paths["a"], paths["d"]},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := &Policy{Order: test.Order}
+			assert.Equal(t, test.Expected, policy.Rank(paths))
+		})
+	}
+	t.Run("filter", func(t *testing.T) {
+		maxLatency := MaxLatency(20 * time.Millisecond)
+		policy := &Policy{
+			MaxLatency: &maxLatency,
+			Order:      Order{{Metric: OrderBandwidth, Descending: true}},
+		}
+		assert.Equal(t, []Path{paths["c"], paths["b"]}, policy.Rank(paths))
+	})
+}
+
+func TestOrderJSON(t *testing.T) {
+	var pol Policy
+	err := json.Unmarshal([]byte(`{"order": ["latency asc", "hops", "expiry desc"]}`), &pol)
+	require.NoError(t, err)
+	expected := Order{
+		{Metric: OrderLatency},
+		{Metric: OrderHops},
+		{Metric: OrderExpiry, Descending: true},
+	}
+	assert.Equal(t, expected, pol.Order)
+
+	raw, err := json.Marshal(&pol)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"order": ["latency asc", "hops asc", "expiry desc"]}`, string(raw))
+
+	err = json.Unmarshal([]byte(`{"order": ["latency sideways"]}`), &pol)
+	assert.Error(t, err)
+}
+
+func TestExtendsOrder(t *testing.T) {
+	order := Order{{Metric: OrderLatency}}
+	extPolicy := &ExtPolicy{
+		Extends: []string{"ordered"},
+		Policy:  &Policy{},
+	}
+	extended := []*ExtPolicy{{Policy: &Policy{Name: "ordered", Order: order}}}
+	pol, err := PolicyFromExtPolicy(extPolicy, extended)
+	require.NoError(t, err)
+	assert.Equal(t, order, pol.Order)
+}
+
+type metadataPath struct {
+	testPath
+	metadata testMetadata
+}
+
+func (p *metadataPath) Metadata() snet.PathMetadata {
+	return p.metadata
+}
+
+type testMetadata struct {
+	expiry time.Time
+	info   *snet.PathStaticInfo
+}
+
+func (m testMetadata) MTU() uint16                      { return 1472 }
+func (m testMetadata) Expiry() time.Time                { return m.expiry }
+func (m testMetadata) StaticInfo() *snet.PathStaticInfo { return m.info }
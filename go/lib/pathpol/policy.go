@@ -14,15 +14,26 @@
 // limitations under the License.
 
 // Package pathpol implements path policies, documentation in doc/PathPolicy.md
-// Currently implemented: ACL, Sequence, Extends and Options.
+// Currently implemented: ACL, Sequence, Extends, Options and predicates on the
+// static info of paths (MaxLatency, MinBandwidth, LinkType, Geofence and
+// AvoidCountries), and Order.
 //
-// A policy has an Act() method that takes an AppPathSet and returns a filtered AppPathSet
+// A policy has a Filter() method that takes a PathSet and returns a filtered
+// PathSet, and a Rank() method that additionally sorts the paths according to
+// the order of the policy.
 package pathpol
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
 	"sort"
 
+	"gopkg.in/yaml.v2"
+
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
 )
 
 // ExtPolicy is an extending policy, it may have a list of policies it extends
@@ -44,10 +55,16 @@ type FilterOptions struct {
 
 // Policy is a compiled path policy object, all extended policies have been merged.
 type Policy struct {
-	Name     string    `json:"-"`
-	ACL      *ACL      `json:"acl,omitempty"`
-	Sequence *Sequence `json:"sequence,omitempty"`
-	Options  []Option  `json:"options,omitempty"`
+	Name           string        `json:"-"`
+	ACL            *ACL          `json:"acl,omitempty"`
+	Sequence       *Sequence     `json:"sequence,omitempty"`
+	Options        []Option      `json:"options,omitempty"`
+	MaxLatency     *MaxLatency   `json:"max_latency,omitempty"`
+	MinBandwidth   *MinBandwidth `json:"min_bandwidth,omitempty"`
+	LinkType       LinkTypes     `json:"link_type,omitempty"`
+	Geofence       Geofence      `json:"geofence,omitempty"`
+	AvoidCountries AvoidRegions  `json:"avoid_countries,omitempty"`
+	Order          Order         `json:"order,omitempty"`
 }
 
 // NewPolicy creates a Policy and sorts its Options
@@ -75,6 +92,11 @@ func (p *Policy) FilterOpt(paths PathSet, opts FilterOptions) PathSet {
 	if p.Sequence != nil && !opts.IgnoreSequence {
 		resultSet = p.Sequence.Eval(resultSet)
 	}
+	resultSet = p.MaxLatency.Eval(resultSet)
+	resultSet = p.MinBandwidth.Eval(resultSet)
+	resultSet = p.LinkType.Eval(resultSet)
+	resultSet = p.Geofence.Eval(resultSet)
+	resultSet = p.AvoidCountries.Eval(resultSet)
 	// Filter on sub policies
 	if len(p.Options) > 0 {
 		resultSet = p.evalOptions(resultSet, opts)
@@ -82,6 +104,34 @@ func (p *Policy) FilterOpt(paths PathSet, opts FilterOptions) PathSet {
 	return resultSet
 }
 
+// Rank filters the path set according to the policy and returns the remaining
+// paths sorted according to the order of the policy. Paths that are equal with
+// respect to the order are sorted by fingerprint.
+func (p *Policy) Rank(paths PathSet) []Path {
+	filtered := p.Filter(paths)
+	keys := make([]snet.PathFingerprint, 0, len(filtered))
+	for key := range filtered {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	ranked := make([]Path, 0, len(keys))
+	for _, key := range keys {
+		ranked = append(ranked, filtered[key])
+	}
+	p.Sort(ranked)
+	return ranked
+}
+
+// Sort sorts the paths according to the order of the policy. The sort is
+// stable, paths that are equal with respect to the order keep their relative
+// position. The paths are not filtered.
+func (p *Policy) Sort(paths []Path) {
+	if p == nil {
+		return
+	}
+	p.Order.Sort(paths)
+}
+
 // PolicyFromExtPolicy creates a Policy from an extending Policy and the extended policies
 func PolicyFromExtPolicy(extPolicy *ExtPolicy, extended []*ExtPolicy) (*Policy, error) {
 	policy := extPolicy.Policy
@@ -95,6 +145,74 @@ func PolicyFromExtPolicy(extPolicy *ExtPolicy, extended []*ExtPolicy) (*Policy,
 	return policy, nil
 }
 
+// LoadPolicy loads a policy from a JSON or YAML file. Files with the extension
+// .yml or .yaml are parsed as YAML, all others as JSON. The YAML format uses
+// the same keys and values as the JSON format. The policy must not extend
+// other policies.
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, serrors.WrapStr("reading policy file", err, "file", file)
+	}
+	if ext := filepath.Ext(file); ext == ".yml" || ext == ".yaml" {
+		if b, err = yamlToJSON(b); err != nil {
+			return nil, serrors.WrapStr("parsing policy file", err, "file", file)
+		}
+	}
+	var extPolicy ExtPolicy
+	if err := json.Unmarshal(b, &extPolicy); err != nil {
+		return nil, serrors.WrapStr("parsing policy file", err, "file", file)
+	}
+	return PolicyFromExtPolicy(&extPolicy, nil)
+}
+
+// yamlToJSON converts a YAML document to JSON, such that the JSON unmarshalers
+// of the policy can be used.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	doc, err := jsonValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// jsonValue converts the maps with interface keys of a YAML document to maps
+// with string keys.
+func jsonValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, serrors.New("non-string key", "key", key)
+			}
+			converted, err := jsonValue(val)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, val := range v {
+			converted, err := jsonValue(val)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, converted)
+		}
+		return l, nil
+	default:
+		return v, nil
+	}
+}
+
 // applyExtended adds attributes of extended policies to the extending policy if they are not
 // already set
 func (p *Policy) applyExtended(extends []string, exPolicies []*ExtPolicy) error {
@@ -127,6 +245,26 @@ func (p *Policy) applyExtended(extends []string, exPolicies []*ExtPolicy) error
 		if p.Sequence == nil {
 			p.Sequence = policy.Sequence
 		}
+		// Replace static info predicates
+		if p.MaxLatency == nil {
+			p.MaxLatency = policy.MaxLatency
+		}
+		if p.MinBandwidth == nil {
+			p.MinBandwidth = policy.MinBandwidth
+		}
+		if p.LinkType == nil {
+			p.LinkType = policy.LinkType
+		}
+		if p.Geofence == nil {
+			p.Geofence = policy.Geofence
+		}
+		if p.AvoidCountries == nil {
+			p.AvoidCountries = policy.AvoidCountries
+		}
+		// Replace Order
+		if len(p.Order) == 0 {
+			p.Order = policy.Order
+		}
 	}
 	return nil
 }
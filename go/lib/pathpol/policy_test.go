@@ -18,8 +18,10 @@ package pathpol
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -610,6 +612,28 @@ func TestPolicyJsonConversion(t *testing.T) {
 	assert.Equal(t, policy, &pol)
 }
 
+func TestLoadPolicy(t *testing.T) {
+	maxLatency := MaxLatency(50 * time.Millisecond)
+	expected := &Policy{
+		ACL: &ACL{
+			Entries: []*ACLEntry{
+				{Action: Deny, Rule: mustHopPredicate(t, "1-ff00:0:133#0")},
+				{Action: Allow},
+			},
+		},
+		Sequence:   newSequence(t, "1-ff00:0:110#0 0* 2-ff00:0:220#0"),
+		MaxLatency: &maxLatency,
+		Order:      Order{{Metric: OrderLatency}, {Metric: OrderHops}},
+	}
+	for _, file := range []string{"policy.json", "policy.yml"} {
+		t.Run(file, func(t *testing.T) {
+			policy, err := LoadPolicy(filepath.Join("testdata", file))
+			require.NoError(t, err)
+			assert.Equal(t, expected, policy)
+		})
+	}
+}
+
 func newSequence(t *testing.T, str string) *Sequence {
 	seq, err := NewSequence(str)
 	xtest.FailOnErr(t, err)
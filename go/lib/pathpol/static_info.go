@@ -0,0 +1,372 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+// StaticInfoPath is a path that carries the static information announced by
+// the ASes on the path in the static info beacon extension. Paths that neither
+// implement it nor carry static info in their metadata never match the static
+// info predicates of a policy.
+type StaticInfoPath interface {
+	Path
+	// StaticInfo returns the static information of the path, or nil if it is
+	// not available.
+	StaticInfo() *snet.PathStaticInfo
+}
+
+// staticInfo returns the static information of the path, or nil if it is not
+// available.
+func staticInfo(path Path) *snet.PathStaticInfo {
+	if p, ok := path.(StaticInfoPath); ok {
+		return p.StaticInfo()
+	}
+	if md := metadata(path); md != nil {
+		return md.StaticInfo()
+	}
+	return nil
+}
+
+// evalStaticInfo returns the paths of the input set that have static
+// information for which match returns true.
+func evalStaticInfo(inputSet PathSet, match func(Path, *snet.PathStaticInfo) bool) PathSet {
+	resultSet := make(PathSet)
+	for key, path := range inputSet {
+		if info := staticInfo(path); info != nil && match(path, info) {
+			resultSet[key] = path
+		}
+	}
+	return resultSet
+}
+
+// MaxLatency only allows paths whose total latency does not exceed the given
+// value. It is represented as a duration string in JSON, e.g. "80ms".
+type MaxLatency time.Duration
+
+// Eval returns the set of paths that do not exceed the latency.
+func (l *MaxLatency) Eval(inputSet PathSet) PathSet {
+	if l == nil {
+		return inputSet
+	}
+	return evalStaticInfo(inputSet, func(_ Path, info *snet.PathStaticInfo) bool {
+		return info.Latency <= time.Duration(*l)
+	})
+}
+
+func (l MaxLatency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(l).String())
+}
+
+func (l *MaxLatency) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(str)
+	if err != nil {
+		return serrors.WrapStr("parsing max latency", err, "value", str)
+	}
+	if d < 0 {
+		return serrors.New("max latency must not be negative", "value", str)
+	}
+	*l = MaxLatency(d)
+	return nil
+}
+
+// MinBandwidth only allows paths whose bottleneck bandwidth is at least the
+// given value in Kbit/s. It is represented as a string with a unit in JSON,
+// e.g. "100Mbps". The supported units are the ones of util.ParseBandwidth, and
+// the bandwidth must be a multiple of 1kbps.
+type MinBandwidth uint64
+
+// Eval returns the set of paths that have at least the bandwidth.
+func (b *MinBandwidth) Eval(inputSet PathSet) PathSet {
+	if b == nil {
+		return inputSet
+	}
+	return evalStaticInfo(inputSet, func(_ Path, info *snet.PathStaticInfo) bool {
+		return info.Bandwidth != 0 && info.Bandwidth >= uint64(*b)
+	})
+}
+
+func (b MinBandwidth) String() string {
+	return util.FmtBandwidth(uint64(b) * 1000)
+}
+
+func (b MinBandwidth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+func (b *MinBandwidth) UnmarshalJSON(raw []byte) error {
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return err
+	}
+	bps, err := util.ParseBandwidth(str)
+	if err != nil {
+		return serrors.WrapStr("parsing min bandwidth", err)
+	}
+	if bps%1000 != 0 {
+		return serrors.New("min bandwidth is not a multiple of 1kbps", "value", str)
+	}
+	*b = MinBandwidth(bps / 1000)
+	return nil
+}
+
+// LinkTypes only allows paths whose inter-AS links are all of one of the
+// given types. It is represented as a list of link type names in JSON, e.g.
+// ["direct", "multihop"]. An empty list does not restrict the paths, but is
+// rejected in JSON.
+type LinkTypes []snet.LinkType
+
+// Eval returns the set of paths that only use the allowed link types.
+func (lt LinkTypes) Eval(inputSet PathSet) PathSet {
+	if len(lt) == 0 {
+		return inputSet
+	}
+	return evalStaticInfo(inputSet, func(_ Path, info *snet.PathStaticInfo) bool {
+		if len(info.LinkTypes) == 0 {
+			return false
+		}
+		for _, asLink := range info.LinkTypes {
+			if !lt.contains(asLink.Inter) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (lt LinkTypes) contains(t snet.LinkType) bool {
+	for _, allowed := range lt {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (lt LinkTypes) MarshalJSON() ([]byte, error) {
+	names := make([]string, 0, len(lt))
+	for _, t := range lt {
+		names = append(names, t.String())
+	}
+	return json.Marshal(names)
+}
+
+func (lt *LinkTypes) UnmarshalJSON(b []byte) error {
+	var names []string
+	if err := json.Unmarshal(b, &names); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return serrors.New("link type list must not be empty")
+	}
+	res := make(LinkTypes, 0, len(names))
+	for _, name := range names {
+		t, err := linkTypeFromString(name)
+		if err != nil {
+			return err
+		}
+		res = append(res, t)
+	}
+	*lt = res
+	return nil
+}
+
+func linkTypeFromString(name string) (snet.LinkType, error) {
+	for _, t := range []snet.LinkType{snet.LinkTypeDirect, snet.LinkTypeMultihop,
+		snet.LinkTypeOpenNet} {
+
+		if strings.EqualFold(name, t.String()) {
+			return t, nil
+		}
+	}
+	return 0, serrors.New("unknown link type", "value", name)
+}
+
+// Geofence only allows paths whose border routers are all located inside at
+// least one of the regions. Paths traversing an AS that did not announce its
+// location are not allowed, as their compliance cannot be verified. An empty
+// geofence does not restrict the paths, but is rejected in JSON.
+type Geofence []GeoRegion
+
+// Eval returns the set of paths that stay inside the geofence.
+func (g Geofence) Eval(inputSet PathSet) PathSet {
+	if len(g) == 0 {
+		return inputSet
+	}
+	return evalStaticInfo(inputSet, func(path Path, info *snet.PathStaticInfo) bool {
+		return allLocations(path, info, func(loc snet.GeoLocation) bool {
+			for _, region := range g {
+				if region.Contains(loc) {
+					return true
+				}
+			}
+			return false
+		})
+	})
+}
+
+func (g *Geofence) UnmarshalJSON(b []byte) error {
+	var regions []GeoRegion
+	if err := json.Unmarshal(b, &regions); err != nil {
+		return err
+	}
+	if len(regions) == 0 {
+		return serrors.New("geofence must have at least one region")
+	}
+	*g = regions
+	return nil
+}
+
+// AvoidRegions only allows paths whose border routers are all located outside
+// of the regions, e.g. the outlines of countries that must be avoided. Paths
+// traversing an AS that did not announce its location are not allowed, as
+// their compliance cannot be verified.
+type AvoidRegions []GeoRegion
+
+// Eval returns the set of paths that avoid the regions.
+func (a AvoidRegions) Eval(inputSet PathSet) PathSet {
+	if len(a) == 0 {
+		return inputSet
+	}
+	return evalStaticInfo(inputSet, func(path Path, info *snet.PathStaticInfo) bool {
+		return allLocations(path, info, func(loc snet.GeoLocation) bool {
+			for _, region := range a {
+				if region.Contains(loc) {
+					return false
+				}
+			}
+			return true
+		})
+	})
+}
+
+// allLocations returns true if every AS on the path announced at least one
+// location, and all the announced locations satisfy the check.
+func allLocations(path Path, info *snet.PathStaticInfo,
+	check func(snet.GeoLocation) bool) bool {
+
+	located := make(map[addr.IA]bool, len(info.Geo))
+	for _, asGeo := range info.Geo {
+		for _, loc := range asGeo.Locations {
+			if !check(loc) {
+				return false
+			}
+			located[asGeo.IA] = true
+		}
+	}
+	for _, intf := range path.Interfaces() {
+		if !located[intf.IA()] {
+			return false
+		}
+	}
+	return len(located) > 0
+}
+
+// GeoRegion is a geographic region described either by a bounding box or by a
+// polygon. Coordinates are in degrees.
+type GeoRegion struct {
+	// Name is an optional description of the region, e.g. a country code.
+	Name string `json:"name,omitempty"`
+	// Box is the bounding box of the region.
+	Box *GeoBox `json:"box,omitempty"`
+	// Polygon is the outline of the region. The polygon is implicitly closed,
+	// and must not cross the antimeridian.
+	Polygon []GeoPoint `json:"polygon,omitempty"`
+}
+
+// Contains returns true if the location lies inside the region.
+func (r GeoRegion) Contains(loc snet.GeoLocation) bool {
+	p := GeoPoint{Latitude: float64(loc.Latitude), Longitude: float64(loc.Longitude)}
+	if r.Box != nil {
+		return r.Box.contains(p)
+	}
+	return polygonContains(r.Polygon, p)
+}
+
+func (r *GeoRegion) UnmarshalJSON(b []byte) error {
+	type region GeoRegion
+	var parsed region
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		return err
+	}
+	switch {
+	case parsed.Box != nil && parsed.Polygon != nil:
+		return serrors.New("region must have either a box or a polygon", "name", parsed.Name)
+	case parsed.Box != nil:
+		if parsed.Box.South > parsed.Box.North {
+			return serrors.New("box south must not be greater than north", "name", parsed.Name)
+		}
+	case len(parsed.Polygon) < 3:
+		return serrors.New("polygon must have at least 3 points", "name", parsed.Name,
+			"points", len(parsed.Polygon))
+	}
+	*r = GeoRegion(parsed)
+	return nil
+}
+
+// GeoBox is a bounding box. If West is greater than East, the box crosses the
+// antimeridian.
+type GeoBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+func (b GeoBox) contains(p GeoPoint) bool {
+	if p.Latitude < b.South || p.Latitude > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return p.Longitude >= b.West && p.Longitude <= b.East
+	}
+	return p.Longitude >= b.West || p.Longitude <= b.East
+}
+
+// GeoPoint is a point on the globe.
+type GeoPoint struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+// polygonContains checks whether the point lies inside the polygon, using the
+// even-odd rule on the plane spanned by longitude and latitude.
+func polygonContains(polygon []GeoPoint, p GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Latitude > p.Latitude) == (b.Latitude > p.Latitude) {
+			continue
+		}
+		lon := a.Longitude + (p.Latitude-a.Latitude)*(b.Longitude-a.Longitude)/
+			(b.Latitude-a.Latitude)
+		if p.Longitude < lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,279 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathpol
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+var (
+	zurich    = snet.GeoLocation{Latitude: 47.37, Longitude: 8.54}
+	bern      = snet.GeoLocation{Latitude: 46.95, Longitude: 7.45}
+	frankfurt = snet.GeoLocation{Latitude: 50.11, Longitude: 8.68}
+	// switzerland is a rough outline of Switzerland.
+	switzerland = []GeoPoint{
+		{Latitude: 47.8, Longitude: 5.9},
+		{Latitude: 47.8, Longitude: 10.5},
+		{Latitude: 45.8, Longitude: 10.5},
+		{Latitude: 45.8, Longitude: 5.9},
+	}
+)
+
+func TestStaticInfoPredicates(t *testing.T) {
+	ia1 := xtest.MustParseIA("1-ff00:0:110")
+	ia2 := xtest.MustParseIA("1-ff00:0:111")
+	newPath := func(info *snet.PathStaticInfo) Path {
+		intfs := []snet.PathInterface{
+			testPathIntf{ia: ia1, ifid: 1},
+			testPathIntf{ia: ia2, ifid: 2},
+		}
+		if info == nil {
+			return &testPath{interfaces: intfs}
+		}
+		return &staticInfoPath{testPath: testPath{interfaces: intfs}, info: info}
+	}
+	geo := func(loc1, loc2 snet.GeoLocation) []snet.ASGeo {
+		return []snet.ASGeo{
+			{IA: ia1, Locations: []snet.GeoLocation{loc1}},
+			{IA: ia2, Locations: []snet.GeoLocation{loc2}},
+		}
+	}
+	paths := PathSet{
+		"fast": newPath(&snet.PathStaticInfo{
+			Latency:   20 * time.Millisecond,
+			Bandwidth: 10000,
+			LinkTypes: []snet.ASLinkType{{IA: ia1, Inter: snet.LinkTypeDirect}},
+			Geo:       geo(zurich, bern),
+		}),
+		"wide": newPath(&snet.PathStaticInfo{
+			Latency:   80 * time.Millisecond,
+			Bandwidth: 1000000,
+			LinkTypes: []snet.ASLinkType{
+				{IA: ia1, Inter: snet.LinkTypeDirect},
+				{IA: ia2, Inter: snet.LinkTypeOpenNet},
+			},
+			Geo: geo(zurich, frankfurt),
+		}),
+		"partial geo": newPath(&snet.PathStaticInfo{
+			Latency: 100 * time.Millisecond,
+			Geo:     []snet.ASGeo{{IA: ia1, Locations: []snet.GeoLocation{zurich}}},
+		}),
+		"no info": newPath(nil),
+	}
+	maxLatency := MaxLatency(80 * time.Millisecond)
+	minBandwidth := MinBandwidth(100000)
+	tests := map[string]struct {
+		Policy   *Policy
+		Expected []snet.PathFingerprint
+	}{
+		"max latency": {
+			Policy:   &Policy{MaxLatency: &maxLatency},
+			Expected: []snet.PathFingerprint{"fast", "wide"},
+		},
+		"min bandwidth": {
+			Policy:   &Policy{MinBandwidth: &minBandwidth},
+			Expected: []snet.PathFingerprint{"wide"},
+		},
+		"link type": {
+			Policy:   &Policy{LinkType: LinkTypes{snet.LinkTypeDirect}},
+			Expected: []snet.PathFingerprint{"fast"},
+		},
+		"geofence polygon": {
+			Policy:   &Policy{Geofence: Geofence{{Polygon: switzerland}}},
+			Expected: []snet.PathFingerprint{"fast"},
+		},
+		"geofence box": {
+			Policy: &Policy{Geofence: Geofence{
+				{Box: &GeoBox{South: 45, West: 5, North: 51, East: 10}},
+			}},
+			Expected: []snet.PathFingerprint{"fast", "wide"},
+		},
+		"avoid countries": {
+			Policy:   &Policy{AvoidCountries: AvoidRegions{{Name: "CH", Polygon: switzerland}}},
+			Expected: []snet.PathFingerprint{},
+		},
+		"avoid box": {
+			Policy: &Policy{AvoidCountries: AvoidRegions{
+				{Name: "DE", Box: &GeoBox{South: 49, West: 6, North: 55, East: 15}},
+			}},
+			Expected: []snet.PathFingerprint{"fast"},
+		},
+		"combined": {
+			Policy: &Policy{
+				MaxLatency:   &maxLatency,
+				MinBandwidth: &minBandwidth,
+				Geofence:     Geofence{{Polygon: switzerland}},
+			},
+			Expected: []snet.PathFingerprint{},
+		},
+		"empty link types": {
+			Policy:   &Policy{LinkType: LinkTypes{}},
+			Expected: []snet.PathFingerprint{"fast", "wide", "partial geo", "no info"},
+		},
+		"empty geofence": {
+			Policy:   &Policy{Geofence: Geofence{}},
+			Expected: []snet.PathFingerprint{"fast", "wide", "partial geo", "no info"},
+		},
+		"no predicates": {
+			Policy:   &Policy{},
+			Expected: []snet.PathFingerprint{"fast", "wide", "partial geo", "no info"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ElementsMatch(t, test.Expected, keys(test.Policy.Filter(paths)))
+		})
+	}
+}
+
+func TestGeoBoxAntimeridian(t *testing.T) {
+	box := GeoBox{South: -50, West: 170, North: -30, East: -170}
+	assert.True(t, box.contains(GeoPoint{Latitude: -40, Longitude: 175}))
+	assert.True(t, box.contains(GeoPoint{Latitude: -40, Longitude: -175}))
+	assert.False(t, box.contains(GeoPoint{Latitude: -40, Longitude: 0}))
+	assert.False(t, box.contains(GeoPoint{Latitude: -20, Longitude: 175}))
+}
+
+func TestStaticInfoJSON(t *testing.T) {
+	input := `{
+		"max_latency": "80ms",
+		"min_bandwidth": "100Mbps",
+		"link_type": ["direct", "multihop"],
+		"geofence": [
+			{"box": {"south": 45.8, "west": 5.9, "north": 47.8, "east": 10.5}}
+		],
+		"avoid_countries": [
+			{"name": "XX", "polygon": [
+				{"lat": 0, "lon": 0}, {"lat": 1, "lon": 0}, {"lat": 1, "lon": 1}
+			]}
+		]
+	}`
+	maxLatency := MaxLatency(80 * time.Millisecond)
+	minBandwidth := MinBandwidth(100000)
+	expected := &Policy{
+		MaxLatency:   &maxLatency,
+		MinBandwidth: &minBandwidth,
+		LinkType:     LinkTypes{snet.LinkTypeDirect, snet.LinkTypeMultihop},
+		Geofence: Geofence{
+			{Box: &GeoBox{South: 45.8, West: 5.9, North: 47.8, East: 10.5}},
+		},
+		AvoidCountries: AvoidRegions{
+			{Name: "XX", Polygon: []GeoPoint{
+				{Latitude: 0, Longitude: 0},
+				{Latitude: 1, Longitude: 0},
+				{Latitude: 1, Longitude: 1},
+			}},
+		},
+	}
+	var pol Policy
+	require.NoError(t, json.Unmarshal([]byte(input), &pol))
+	assert.Equal(t, expected, &pol)
+
+	raw, err := json.Marshal(&pol)
+	require.NoError(t, err)
+	var roundTrip Policy
+	require.NoError(t, json.Unmarshal(raw, &roundTrip))
+	assert.Equal(t, expected, &roundTrip)
+}
+
+func TestStaticInfoJSONErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad latency":          `{"max_latency": "80"}`,
+		"negative latency":     `{"max_latency": "-1ms"}`,
+		"bandwidth no unit":    `{"min_bandwidth": "100"}`,
+		"bad bandwidth":        `{"min_bandwidth": "fastMbps"}`,
+		"bandwidth overflow":   `{"min_bandwidth": "18446744073709552Tbps"}`,
+		"bandwidth in bytes":   `{"min_bandwidth": "100MBps"}`,
+		"bandwidth case":       `{"min_bandwidth": "100mbps"}`,
+		"bandwidth below kbps": `{"min_bandwidth": "1500bps"}`,
+		"unknown link type":    `{"link_type": ["satellite"]}`,
+		"empty link types":     `{"link_type": []}`,
+		"empty geofence":       `{"geofence": []}`,
+		"box and polygon":      `{"geofence": [{"box": {}, "polygon": [{}, {}, {}]}]}`,
+		"short polygon":        `{"geofence": [{"polygon": [{}, {}]}]}`,
+		"empty region":         `{"avoid_countries": [{"name": "XX"}]}`,
+		"south north of box":   `{"geofence": [{"box": {"south": 10, "north": 0}}]}`,
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			var pol Policy
+			assert.Error(t, json.Unmarshal([]byte(input), &pol))
+		})
+	}
+}
+
+func TestMinBandwidthString(t *testing.T) {
+	tests := map[MinBandwidth]string{
+		0:          "0bps",
+		1500:       "1500kbps",
+		100000:     "100Mbps",
+		2000000:    "2Gbps",
+		1000000000: "1Tbps",
+	}
+	for bw, expected := range tests {
+		assert.Equal(t, expected, bw.String())
+	}
+}
+
+func TestExtendsStaticInfo(t *testing.T) {
+	maxLatency := MaxLatency(80 * time.Millisecond)
+	otherLatency := MaxLatency(10 * time.Millisecond)
+	extPolicy := &ExtPolicy{
+		Extends: []string{"latency", "geo"},
+		Policy:  &Policy{MaxLatency: &maxLatency},
+	}
+	extended := []*ExtPolicy{
+		{Policy: &Policy{Name: "latency", MaxLatency: &otherLatency}},
+		{Policy: &Policy{
+			Name:           "geo",
+			LinkType:       LinkTypes{snet.LinkTypeDirect},
+			Geofence:       Geofence{{Polygon: switzerland}},
+			AvoidCountries: AvoidRegions{{Name: "DE", Polygon: switzerland}},
+		}},
+	}
+	pol, err := PolicyFromExtPolicy(extPolicy, extended)
+	require.NoError(t, err)
+	assert.Equal(t, &Policy{
+		MaxLatency:     &maxLatency,
+		LinkType:       LinkTypes{snet.LinkTypeDirect},
+		Geofence:       Geofence{{Polygon: switzerland}},
+		AvoidCountries: AvoidRegions{{Name: "DE", Polygon: switzerland}},
+	}, pol)
+}
+
+type staticInfoPath struct {
+	testPath
+	info *snet.PathStaticInfo
+}
+
+func (p *staticInfoPath) StaticInfo() *snet.PathStaticInfo {
+	return p.info
+}
+
+func keys(ps PathSet) []snet.PathFingerprint {
+	res := make([]snet.PathFingerprint, 0, len(ps))
+	for key := range ps {
+		res = append(res, key)
+	}
+	return res
+}
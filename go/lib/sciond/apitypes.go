@@ -84,6 +84,8 @@ func svcAddrToProto(svc addr.HostSVC) proto.ServiceType {
 		return proto.ServiceType_cs
 	case addr.SvcSIG:
 		return proto.ServiceType_sig
+	case addr.SvcHPS:
+		return proto.ServiceType_hps
 	default:
 		return proto.ServiceType_unset
 	}
@@ -156,8 +158,9 @@ func pathReplyEntryToPath(pe PathReplyEntry, dst addr.IA) (path, error) {
 
 func pathReplyEntryToMetadata(pe PathReplyEntry) pathMetadata {
 	return pathMetadata{
-		mtu:    pe.Path.Mtu,
-		expiry: pe.Path.Expiry(),
+		mtu:        pe.Path.Mtu,
+		expiry:     pe.Path.Expiry(),
+		staticInfo: pe.PathStaticInfo.StaticInfo(),
 	}
 }
 
@@ -242,8 +245,9 @@ func (i pathInterface) ID() common.IFIDType { return i.id }
 func (i pathInterface) IA() addr.IA         { return i.ia }
 
 type pathMetadata struct {
-	mtu    uint16
-	expiry time.Time
+	mtu        uint16
+	expiry     time.Time
+	staticInfo *snet.PathStaticInfo
 }
 
 func (m pathMetadata) MTU() uint16 {
@@ -253,3 +257,7 @@ func (m pathMetadata) MTU() uint16 {
 func (m pathMetadata) Expiry() time.Time {
 	return m.expiry
 }
+
+func (m pathMetadata) StaticInfo() *snet.PathStaticInfo {
+	return m.staticInfo
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pathprobe
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/lib/spath"
+)
+
+var errEchoReply = errors.New("scmp: echo reply")
+
+// GetRTTs probes the paths with SCMP echo requests and returns the measured
+// round trip times. The echo requests are addressed to the control service of
+// the destination AS. The returned map is keyed with PathKey, paths for which
+// no reply arrived before the deadline of ctx are not contained. The input
+// should only be non-empty paths.
+func (p Prober) GetRTTs(ctx context.Context,
+	paths []snet.Path) (map[string]time.Duration, error) {
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil, serrors.New("deadline required on ctx")
+	}
+	h := &echoHandler{
+		id:   rand.Uint64(),
+		keys: make([]string, len(paths)),
+		sent: make([]time.Time, len(paths)),
+		rtts: make(map[string]time.Duration, len(paths)),
+	}
+	svc := &snet.DefaultPacketDispatcherService{
+		Dispatcher:  reliable.NewDispatcher(""),
+		SCMPHandler: h,
+		Version2:    p.Version2,
+	}
+	conn, _, err := svc.Register(ctx, p.LocalIA, &net.UDPAddr{IP: p.LocalIP}, addr.SvcNone)
+	if err != nil {
+		return nil, common.NewBasicError("listening failed", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+
+	src := snet.SCIONAddress{IA: p.LocalIA, Host: addr.HostFromIP(p.LocalIP)}
+	dst := snet.SCIONAddress{IA: p.DstIA, Host: addr.SvcCS}
+	for i, path := range paths {
+		h.keys[i] = PathKey(path)
+		pkt := newEcho(src, dst, path.Path(), scmp.InfoEcho{Id: h.id, Seq: uint16(i)})
+		log.Debug("Sending echo request.", "path", path)
+		h.sent[i] = time.Now()
+		if err := conn.WriteTo(pkt, path.UnderlayNextHop()); err != nil {
+			return nil, common.NewBasicError("cannot send packet", err)
+		}
+	}
+	for len(h.rtts) < len(paths) {
+		var pkt snet.Packet
+		var ov net.UDPAddr
+		err := conn.ReadFrom(&pkt, &ov)
+		switch {
+		case err == nil, errors.Is(err, errEchoReply), errors.Is(err, errSCMP):
+		case common.IsTimeoutErr(err):
+			// Timeout expired before all replies were received.
+			return h.rtts, nil
+		default:
+			return nil, common.NewBasicError("failed to read packet", err)
+		}
+	}
+	return h.rtts, nil
+}
+
+func newEcho(src, dst snet.SCIONAddress, path *spath.Path, info scmp.InfoEcho) *snet.Packet {
+	pld := make([]byte, scmp.MetaLen+info.Len())
+	meta := scmp.Meta{InfoLen: uint8(info.Len() / common.LineLen)}
+	meta.Write(pld)
+	info.Write(pld[scmp.MetaLen:])
+	return &snet.Packet{
+		PacketInfo: snet.PacketInfo{
+			Destination: dst,
+			Source:      src,
+			Path:        path,
+			L4Header: scmp.NewHdr(
+				scmp.ClassType{Class: scmp.C_General, Type: scmp.T_G_EchoRequest},
+				len(pld),
+			),
+			Payload: common.RawBytes(pld),
+		},
+	}
+}
+
+// echoHandler records the round trip times of the echo replies. The sequence
+// number of the echo request is the index of the probed path.
+type echoHandler struct {
+	id   uint64
+	keys []string
+	sent []time.Time
+	rtts map[string]time.Duration
+}
+
+func (h *echoHandler) Handle(pkt *snet.Packet) error {
+	received := time.Now()
+	hdr, ok := pkt.L4Header.(*scmp.Hdr)
+	if !ok || hdr.Class != scmp.C_General || hdr.Type != scmp.T_G_EchoReply {
+		return errSCMP
+	}
+	pld, ok := pkt.Payload.(*scmp.Payload)
+	if !ok {
+		return errSCMP
+	}
+	info, ok := pld.Info.(*scmp.InfoEcho)
+	if !ok || info.Id != h.id || int(info.Seq) >= len(h.keys) {
+		return errSCMP
+	}
+	h.rtts[h.keys[info.Seq]] = received.Sub(h.sent[info.Seq]).Round(time.Microsecond)
+	return errEchoReply
+}
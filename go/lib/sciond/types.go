@@ -24,6 +24,7 @@ import (
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	"github.com/scionproto/scion/go/lib/hostinfo"
+	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/util"
 	"github.com/scionproto/scion/go/proto"
 )
@@ -165,8 +166,9 @@ func (r *PathReply) String() string {
 }
 
 type PathReplyEntry struct {
-	Path     *FwdPathMeta
-	HostInfo hostinfo.Host
+	Path           *FwdPathMeta
+	HostInfo       hostinfo.Host
+	PathStaticInfo *PathMetadata
 }
 
 func (e *PathReplyEntry) Copy() *PathReplyEntry {
@@ -174,8 +176,9 @@ func (e *PathReplyEntry) Copy() *PathReplyEntry {
 		return nil
 	}
 	return &PathReplyEntry{
-		Path:     e.Path.Copy(),
-		HostInfo: *e.HostInfo.Copy(),
+		Path:           e.Path.Copy(),
+		HostInfo:       *e.HostInfo.Copy(),
+		PathStaticInfo: e.PathStaticInfo.Copy(),
 	}
 }
 
@@ -271,6 +274,118 @@ func (iface PathInterface) String() string {
 	return fmt.Sprintf("%s#%d", iface.IA(), iface.IfID)
 }
 
+// PathMetadata is the static information of a path, as announced by the ASes on
+// the path. The per-AS entries are ordered along the path.
+type PathMetadata struct {
+	// TotalLatency is the total latency of the path in ms.
+	TotalLatency uint16
+	// TotalHops is the total number of AS internal hops of the path.
+	TotalHops uint8
+	// MinimalBandwidth is the bottleneck bandwidth of the path in Kbit/s.
+	MinimalBandwidth uint32
+	LinkTypes        []InterfaceLinkType
+	ASLocations      []Geo `capnp:"asLocations"`
+	Notes            []Note
+}
+
+func (m *PathMetadata) Copy() *PathMetadata {
+	if m == nil {
+		return nil
+	}
+	res := &PathMetadata{
+		TotalLatency:     m.TotalLatency,
+		TotalHops:        m.TotalHops,
+		MinimalBandwidth: m.MinimalBandwidth,
+	}
+	if m.LinkTypes != nil {
+		res.LinkTypes = append([]InterfaceLinkType{}, m.LinkTypes...)
+	}
+	if m.ASLocations != nil {
+		res.ASLocations = make([]Geo, 0, len(m.ASLocations))
+		for _, geo := range m.ASLocations {
+			geo.RouterLocations = append(geo.RouterLocations[:0:0], geo.RouterLocations...)
+			res.ASLocations = append(res.ASLocations, geo)
+		}
+	}
+	if m.Notes != nil {
+		res.Notes = append([]Note{}, m.Notes...)
+	}
+	return res
+}
+
+// StaticInfo converts the path metadata to the representation used by snet.
+func (m *PathMetadata) StaticInfo() *snet.PathStaticInfo {
+	if m == nil {
+		return nil
+	}
+	info := &snet.PathStaticInfo{
+		Latency:      time.Duration(m.TotalLatency) * time.Millisecond,
+		Bandwidth:    uint64(m.MinimalBandwidth),
+		InternalHops: uint32(m.TotalHops),
+	}
+	for _, lt := range m.LinkTypes {
+		info.LinkTypes = append(info.LinkTypes, snet.ASLinkType{
+			IA:    lt.IA(),
+			Inter: snet.LinkType(lt.InterLinkType),
+			Peer:  snet.LinkType(lt.PeerLinkType),
+		})
+	}
+	for _, geo := range m.ASLocations {
+		asGeo := snet.ASGeo{IA: geo.IA()}
+		for _, loc := range geo.RouterLocations {
+			asGeo.Locations = append(asGeo.Locations, snet.GeoLocation{
+				Latitude:  loc.Latitude,
+				Longitude: loc.Longitude,
+				Address:   loc.Address,
+			})
+		}
+		info.Geo = append(info.Geo, asGeo)
+	}
+	for _, note := range m.Notes {
+		info.Notes = append(info.Notes, snet.ASNote{IA: note.IA(), Note: note.Note})
+	}
+	return info
+}
+
+func (m *PathMetadata) String() string {
+	return fmt.Sprintf("Latency: %dms Hops: %d Bandwidth: %dKbit/s",
+		m.TotalLatency, m.TotalHops, m.MinimalBandwidth)
+}
+
+type InterfaceLinkType struct {
+	InterLinkType uint16
+	PeerLinkType  uint16
+	RawIsdas      addr.IAInt `capnp:"isdas"`
+}
+
+func (lt InterfaceLinkType) IA() addr.IA {
+	return lt.RawIsdas.IA()
+}
+
+type Geo struct {
+	RouterLocations []GPSData
+	RawIsdas        addr.IAInt `capnp:"isdas"`
+}
+
+func (g Geo) IA() addr.IA {
+	return g.RawIsdas.IA()
+}
+
+type GPSData struct {
+	Latitude  float32
+	Longitude float32
+	Address   string
+}
+
+type Note struct {
+	Note     string
+	RawIsdas addr.IAInt `capnp:"isdas"`
+}
+
+func (n Note) IA() addr.IA {
+	return n.RawIsdas.IA()
+}
+
 type ASInfoReq struct {
 	Isdas addr.IAInt
 }
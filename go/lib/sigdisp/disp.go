@@ -16,20 +16,36 @@
 package sigdisp
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pktdisp"
 	"github.com/scionproto/scion/go/lib/snet"
 )
 
-func Init(conn *snet.Conn, useid bool) {
+const (
+	// verifyTimeout is the maximum time to verify the signature of a message.
+	verifyTimeout = time.Second
+	// signWindow is the maximum difference between the signature timestamp of
+	// a message and the local time. Messages outside the window are rejected,
+	// such that old messages cannot be replayed.
+	signWindow = 10 * time.Second
+)
+
+// Init starts dispatching the SIG control messages that are received on conn.
+// If verifier is not nil, only messages that are signed by the AS of the
+// sender within signWindow of the local time are dispatched.
+func Init(conn *snet.Conn, useid bool, v infra.Verifier) {
 	useID = useid
+	verifier = v
 	go func() {
 		defer log.HandlePanic()
 		pktdisp.PktDispatcher(conn, dispFunc, nil)
@@ -61,6 +77,7 @@ type RegPldChan chan *RegPld
 var (
 	Dispatcher = newDispReg()
 	useID      bool
+	verifier   infra.Verifier
 )
 
 type dispRegistry struct {
@@ -148,7 +165,7 @@ func dispFunc(dp *pktdisp.DispPkt) {
 		log.Error("Unable to parse signed ctrl payload", "src", src, "err", err)
 		return
 	}
-	cpld, err := scpld.UnsafePld()
+	cpld, err := verifiedPld(scpld, src)
 	if err != nil {
 		log.Error("Unable to parse ctrl payload", "src", src, "err", err)
 		return
@@ -166,6 +183,19 @@ func dispFunc(dp *pktdisp.DispPkt) {
 	}
 }
 
+func verifiedPld(scpld *ctrl.SignedPld, src *snet.UDPAddr) (*ctrl.Pld, error) {
+	if verifier == nil {
+		return scpld.UnsafePld()
+	}
+	if ts := scpld.Sign.Time(); time.Since(ts) > signWindow || time.Until(ts) > signWindow {
+		return nil, common.NewBasicError("Signature timestamp outside window", nil,
+			"timestamp", ts, "window", signWindow)
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancelF()
+	return scpld.GetVerifiedPld(ctx, verifier.WithIA(src.IA))
+}
+
 type RegPollKey string
 
 func MkRegPollKey(ia addr.IA, session sig_mgmt.SessionType, id sig_mgmt.MsgIdType) RegPollKey {
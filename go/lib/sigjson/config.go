@@ -22,11 +22,29 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/pktcls"
+	"github.com/scionproto/scion/go/lib/serrors"
 )
 
+// MaxClassSessions is the maximum number of traffic class sessions per remote
+// AS. Session 0 is the default session, the class sessions use the IDs 1 to
+// MaxClassSessions.
+const MaxClassSessions = 255
+
+// DefaultMultipathPaths is the number of paths frames are striped over if
+// multipath is enabled without setting the maximum number of paths.
+const DefaultMultipathPaths = 2
+
 // Cfg is a direct Go representation of the JSON file format.
 type Cfg struct {
-	ASes          map[addr.IA]*ASEntry
+	ASes map[addr.IA]*ASEntry
+	// Classes contains the traffic classes that can be referenced by the
+	// sessions of the AS entries.
+	Classes pktcls.ClassMap `json:",omitempty"`
+	// Announce lists the IP prefixes that the local SIG serves. They are
+	// announced to the remote SIGs in the replies to their polls.
+	Announce      []*IPNet `json:",omitempty"`
 	ConfigVersion uint64
 }
 
@@ -40,9 +58,106 @@ func LoadFromFile(path string) (*Cfg, error) {
 	if err := json.Unmarshal(b, cfg); err != nil {
 		return nil, common.NewBasicError("Unable to parse SIG config", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, common.NewBasicError("Invalid SIG config", err)
+	}
 	return cfg, nil
 }
 
+// Validate checks that the sessions of all AS entries reference existing
+// traffic classes, and that all rate limits are valid.
+func (cfg *Cfg) Validate() error {
+	for ia, entry := range cfg.ASes {
+		if entry == nil {
+			continue
+		}
+		if entry.Multipath != nil && entry.Multipath.MaxPaths < 0 {
+			return serrors.New("negative number of multipath paths", "ia", ia,
+				"max_paths", entry.Multipath.MaxPaths)
+		}
+		if entry.RateLimit != nil {
+			if err := entry.RateLimit.validate(); err != nil {
+				return serrors.WithCtx(err, "ia", ia)
+			}
+		}
+		if len(entry.Sessions) > MaxClassSessions {
+			return serrors.New("too many sessions", "ia", ia,
+				"sessions", len(entry.Sessions), "max", MaxClassSessions)
+		}
+		seen := make(map[string]struct{}, len(entry.Sessions))
+		for _, sess := range entry.Sessions {
+			if sess == nil {
+				return serrors.New("empty session", "ia", ia)
+			}
+			if _, ok := cfg.Classes[sess.Class]; !ok {
+				return serrors.New("unknown traffic class", "ia", ia, "class", sess.Class)
+			}
+			if _, ok := seen[sess.Class]; ok {
+				return serrors.New("duplicate traffic class", "ia", ia, "class", sess.Class)
+			}
+			seen[sess.Class] = struct{}{}
+			if sess.RateLimit != nil {
+				if err := sess.RateLimit.validate(); err != nil {
+					return serrors.WithCtx(err, "ia", ia, "class", sess.Class)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 type ASEntry struct {
 	Nets []*IPNet
+	// Sessions lists the traffic classes that are sent over a dedicated
+	// session to the remote AS. A packet is sent over the session of the first
+	// class it matches, packets that match no class are sent over the default
+	// session.
+	Sessions []*SessionEntry `json:",omitempty"`
+	// Multipath enables striping the frames of all sessions to the remote AS
+	// over several disjoint paths. The remote SIG must reorder the frames, see
+	// the reorder_window option of the SIG.
+	Multipath *Multipath `json:",omitempty"`
+	// AcceptAnnounced enables routing to the IP prefixes that the remote SIG
+	// announces. Nets then only restricts the announced prefixes that are
+	// accepted: a prefix is accepted if it is contained in one of Nets.
+	AcceptAnnounced bool `json:",omitempty"`
+	// RateLimit limits the traffic of all sessions to the remote AS. If the
+	// limit is exceeded, packets are queued and sent in the order of the
+	// session priorities. If it is not set, the traffic is not limited.
+	RateLimit *RateLimit `json:",omitempty"`
+}
+
+// Multipath configures the striping of frames over several paths.
+type Multipath struct {
+	// MaxPaths is the maximum number of disjoint paths the frames are striped
+	// over. (default DefaultMultipathPaths)
+	MaxPaths int `json:",omitempty"`
+}
+
+// Paths returns the number of paths frames are striped over. If m is nil,
+// multipath is disabled and 1 is returned.
+func (m *Multipath) Paths() int {
+	if m == nil {
+		return 1
+	}
+	if m.MaxPaths == 0 {
+		return DefaultMultipathPaths
+	}
+	return m.MaxPaths
+}
+
+// SessionEntry maps a traffic class to the path policy of its session.
+type SessionEntry struct {
+	// Class is the name of a traffic class in Cfg.Classes.
+	Class string
+	// PathPolicy restricts the paths the session uses. If it is not set, the
+	// session uses all paths to the remote AS.
+	PathPolicy *pathpol.Policy `json:",omitempty"`
+	// Priority is the priority of the session if the traffic to the remote AS
+	// is rate limited. Queued packets of sessions with a higher priority are
+	// always sent first. The default session has priority 0.
+	Priority int `json:",omitempty"`
+	// RateLimit limits the traffic of the session. If it is not set, the
+	// traffic is only limited by the rate limit of the remote AS.
+	RateLimit *RateLimit `json:",omitempty"`
 }
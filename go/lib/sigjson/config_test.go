@@ -15,14 +15,18 @@
 package sigjson
 
 import (
+	"encoding/json"
 	"flag"
 	"net"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/pktcls"
 	"github.com/scionproto/scion/go/lib/xtest"
 )
 
@@ -31,6 +35,8 @@ var (
 )
 
 func TestLoadFromFile(t *testing.T) {
+	maxLatency := pathpol.MaxLatency(50 * time.Millisecond)
+	_, bulkNet, _ := net.ParseCIDR("10.0.0.0/8")
 	tests := []struct {
 		Name     string
 		FileName string
@@ -71,6 +77,108 @@ func TestLoadFromFile(t *testing.T) {
 				ConfigVersion: 9001,
 			},
 		},
+		{
+			Name:     "traffic classes",
+			FileName: "02-classes",
+			Config: Cfg{
+				ASes: map[addr.IA]*ASEntry{
+					xtest.MustParseIA("1-ff00:0:1"): {
+						Nets: []*IPNet{
+							{
+								IP:   net.IP{192, 0, 2, 0},
+								Mask: net.CIDRMask(24, 8*net.IPv4len),
+							},
+						},
+						Sessions: []*SessionEntry{
+							{
+								Class: "voip",
+								PathPolicy: &pathpol.Policy{
+									MaxLatency: &maxLatency,
+									Order:      pathpol.Order{{Metric: pathpol.OrderLatency}},
+								},
+							},
+							{
+								Class: "bulk",
+							},
+						},
+						Multipath: &Multipath{MaxPaths: 3},
+					},
+				},
+				Classes: pktcls.ClassMap{
+					"bulk": pktcls.NewClass("bulk", pktcls.NewCondIPv4(
+						&pktcls.IPv4MatchSource{Net: bulkNet},
+					)),
+					"voip": pktcls.NewClass("voip", pktcls.NewCondIPv4(
+						&pktcls.IPv4MatchDSCP{DSCP: 0x2e},
+					)),
+				},
+				ConfigVersion: 1,
+			},
+		},
+		{
+			Name:     "announced prefixes",
+			FileName: "04-announce",
+			Config: Cfg{
+				ASes: map[addr.IA]*ASEntry{
+					xtest.MustParseIA("1-ff00:0:1"): {
+						Nets: []*IPNet{
+							{
+								IP:   net.IP{10, 0, 0, 0},
+								Mask: net.CIDRMask(8, 8*net.IPv4len),
+							},
+						},
+						AcceptAnnounced: true,
+					},
+				},
+				Announce: []*IPNet{
+					{
+						IP:   net.IP{198, 51, 100, 0},
+						Mask: net.CIDRMask(24, 8*net.IPv4len),
+					},
+				},
+				ConfigVersion: 1,
+			},
+		},
+		{
+			Name:     "rate limits",
+			FileName: "05-ratelimit",
+			Config: Cfg{
+				ASes: map[addr.IA]*ASEntry{
+					xtest.MustParseIA("1-ff00:0:1"): {
+						Nets: []*IPNet{
+							{
+								IP:   net.IP{192, 0, 2, 0},
+								Mask: net.CIDRMask(24, 8*net.IPv4len),
+							},
+						},
+						Sessions: []*SessionEntry{
+							{
+								Class:    "voip",
+								Priority: 10,
+								RateLimit: &RateLimit{
+									Rate:  Bandwidth(2000),
+									Burst: 16000,
+								},
+							},
+							{
+								Class:    "bulk",
+								Priority: -1,
+							},
+						},
+						RateLimit: &RateLimit{Rate: Bandwidth(100000)},
+					},
+				},
+				Classes: pktcls.ClassMap{
+					"bulk": pktcls.NewClass("bulk", pktcls.NewCondIPv4(
+						&pktcls.IPv4MatchSource{Net: bulkNet},
+					)),
+					"voip": pktcls.NewClass("voip", pktcls.NewCondIPv4(
+						&pktcls.IPv4MatchDSCP{DSCP: 0x2e},
+					)),
+				},
+				ConfigVersion: 1,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -86,6 +194,65 @@ func TestLoadFromFile(t *testing.T) {
 	}
 }
 
+func TestLoadFromFileInvalid(t *testing.T) {
+	_, err := LoadFromFile(filepath.Join("testdata", "03-unknown-class.json"))
+	assert.Error(t, err)
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	tests := map[string]*RateLimit{
+		"no rate":        {Burst: 1500},
+		"negative burst": {Rate: Bandwidth(1000), Burst: -1},
+	}
+	for name, limit := range tests {
+		t.Run(name, func(t *testing.T) {
+			ia := xtest.MustParseIA("1-ff00:0:1")
+			cfg := &Cfg{ASes: map[addr.IA]*ASEntry{ia: {RateLimit: limit}}}
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
+
+func TestRateLimitBurstBytes(t *testing.T) {
+	assert.Equal(t, 1500, (&RateLimit{Rate: Bandwidth(1000), Burst: 1500}).BurstBytes())
+	// 50ms at 100Mbps
+	assert.Equal(t, 625000, (&RateLimit{Rate: Bandwidth(100000)}).BurstBytes())
+}
+
+func TestBandwidthJSON(t *testing.T) {
+	tests := map[string]Bandwidth{
+		`"1500kbps"`: 1500,
+		`"100Mbps"`:  100000,
+		`"10 Gbps"`:  10000000,
+		`"1Tbps"`:    1000000000,
+		`"2000bps"`:  2,
+	}
+	for raw, expected := range tests {
+		t.Run(raw, func(t *testing.T) {
+			var b Bandwidth
+			assert.NoError(t, json.Unmarshal([]byte(raw), &b))
+			assert.Equal(t, expected, b)
+		})
+	}
+	for _, raw := range []string{`"fastMbps"`, `"100"`, `100`, `"10 gbps"`, `"100MBPS"`,
+		`"100MBps"`, `"18446744073709552Tbps"`, `"1500bps"`} {
+		t.Run(raw, func(t *testing.T) {
+			var b Bandwidth
+			assert.Error(t, json.Unmarshal([]byte(raw), &b))
+		})
+	}
+	out, err := json.Marshal(Bandwidth(2000))
+	assert.NoError(t, err)
+	assert.Equal(t, `"2Mbps"`, string(out))
+}
+
+func TestMultipathPaths(t *testing.T) {
+	var disabled *Multipath
+	assert.Equal(t, 1, disabled.Paths())
+	assert.Equal(t, DefaultMultipathPaths, (&Multipath{}).Paths())
+	assert.Equal(t, 4, (&Multipath{MaxPaths: 4}).Paths())
+}
+
 func TestIPNetUnmarshalJSON(t *testing.T) {
 	tests := []struct {
 		Name  string
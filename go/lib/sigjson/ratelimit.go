@@ -0,0 +1,88 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigjson
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+// DefaultBurstDuration is the time it takes to send the default burst of a
+// rate limit at its rate.
+const DefaultBurstDuration = 50 * time.Millisecond
+
+// RateLimit limits the traffic with a token bucket.
+type RateLimit struct {
+	// Rate is the rate the traffic is limited to.
+	Rate Bandwidth
+	// Burst is the number of bytes that can be sent at once. (default: the
+	// bytes sent at Rate in DefaultBurstDuration)
+	Burst int `json:",omitempty"`
+}
+
+// BurstBytes returns the number of bytes that can be sent at once.
+func (l *RateLimit) BurstBytes() int {
+	if l.Burst != 0 {
+		return l.Burst
+	}
+	return int(l.Rate.BytesPerSecond() * DefaultBurstDuration.Seconds())
+}
+
+func (l *RateLimit) validate() error {
+	if l.Rate == 0 {
+		return serrors.New("rate limit without rate")
+	}
+	if l.Burst < 0 {
+		return serrors.New("negative rate limit burst", "burst", l.Burst)
+	}
+	return nil
+}
+
+// Bandwidth is a bandwidth in Kbit/s. It is represented as a string with a
+// unit in JSON, e.g. "100Mbps". The supported units are the ones of
+// util.ParseBandwidth, and the bandwidth must be a multiple of 1kbps.
+type Bandwidth uint64
+
+// BytesPerSecond returns the bandwidth in bytes per second.
+func (b Bandwidth) BytesPerSecond() float64 {
+	return float64(b) * 1000 / 8
+}
+
+func (b Bandwidth) String() string {
+	return util.FmtBandwidth(uint64(b) * 1000)
+}
+
+func (b Bandwidth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+func (b *Bandwidth) UnmarshalJSON(raw []byte) error {
+	var str string
+	if err := json.Unmarshal(raw, &str); err != nil {
+		return err
+	}
+	bps, err := util.ParseBandwidth(str)
+	if err != nil {
+		return err
+	}
+	if bps%1000 != 0 {
+		return serrors.New("bandwidth is not a multiple of 1kbps", "value", str)
+	}
+	*b = Bandwidth(bps / 1000)
+	return nil
+}
@@ -367,8 +367,8 @@ func parseAddr(addrType AddrType, addrLen AddrLen, raw []byte) (net.Addr, error)
 func packAddr(hostAddr net.Addr) (AddrLen, AddrType, []byte, error) {
 	switch a := hostAddr.(type) {
 	case *net.IPAddr:
-		if a.IP.To4() != nil {
-			return AddrLen4, T4Ip, a.IP, nil
+		if ip := a.IP.To4(); ip != nil {
+			return AddrLen4, T4Ip, ip, nil
 		}
 		return AddrLen16, T16Ip, a.IP, nil
 	case addr.HostSVC:
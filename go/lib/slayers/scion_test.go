@@ -124,6 +124,13 @@ func TestPackAddr(t *testing.T) {
 			rawAddr:   []byte(ip4Addr.IP),
 			errorFunc: assert.NoError,
 		},
+		"pack IPv4 in 16 bytes": {
+			addr:      &net.IPAddr{IP: net.ParseIP("10.0.0.100")},
+			addrType:  slayers.T4Ip,
+			addrLen:   slayers.AddrLen4,
+			rawAddr:   []byte(ip4Addr.IP),
+			errorFunc: assert.NoError,
+		},
 		"pack IPv6": {
 			addr:      ip6Addr,
 			addrType:  slayers.T16Ip,
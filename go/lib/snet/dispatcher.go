@@ -86,15 +86,31 @@ type SCMPHandler interface {
 	Handle(pkt *Packet) error
 }
 
+// SCMPHandlerOption is a functional option for NewSCMPHandler.
+type SCMPHandlerOption func(h *scmpHandler)
+
+// WithSCMPAuth requires SCMP errors to carry a valid DRKey authenticator of
+// their source AS. Errors that fail verification are dropped before
+// revocations are forwarded or path errors are returned to the caller.
+func WithSCMPAuth(keys SCMPKeyProvider) SCMPHandlerOption {
+	return func(h *scmpHandler) {
+		h.keys = keys
+	}
+}
+
 // NewSCMPHandler creates a default SCMP handler that forwards revocations to the revocation
 // handler. SCMP packets are also forwarded to snet callers via errors returned by Read calls.
 //
 // If the revocation handler is nil, revocations are not forwarded. However, they are still sent
 // back to the caller during read operations.
-func NewSCMPHandler(rh RevocationHandler) SCMPHandler {
-	return &scmpHandler{
+func NewSCMPHandler(rh RevocationHandler, opts ...SCMPHandlerOption) SCMPHandler {
+	h := &scmpHandler{
 		revocationHandler: rh,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // scmpHandler handles SCMP messages received from the network. If a revocation handler is
@@ -103,6 +119,9 @@ func NewSCMPHandler(rh RevocationHandler) SCMPHandler {
 type scmpHandler struct {
 	// revocationHandler manages revocations received via SCMP. If nil, the handler is not called.
 	revocationHandler RevocationHandler
+	// keys provides the keys to verify the DRKey authenticator of SCMP errors. If nil, SCMP
+	// errors are not authenticated.
+	keys SCMPKeyProvider
 }
 
 func (h *scmpHandler) Handle(pkt *Packet) error {
@@ -118,6 +137,17 @@ func (h *scmpHandler) Handle(pkt *Packet) error {
 		metrics.M.SCMPErrors().Inc()
 	}
 
+	if h.keys != nil && hdr.Class == scmp.C_Path {
+		ctx, cancelF := context.WithTimeout(context.Background(), SCMPAuthTimeout)
+		err := VerifySCMPAuth(ctx, pkt, h.keys)
+		cancelF()
+		if err != nil {
+			metrics.M.SCMPUnauthenticated().Inc()
+			log.Debug("Dropping unauthenticated scmp packet", "hdr", hdr, "src", pkt.Source,
+				"err", err)
+			return nil
+		}
+	}
 	// Only handle revocations for now
 	if hdr.Class == scmp.C_Path && hdr.Type == scmp.T_P_RevokedIF {
 		return h.handleSCMPRev(hdr, pkt)
@@ -30,6 +30,7 @@ const (
 	subRead            = "read"
 	subWrite           = "write"
 	subSCMPError       = "scmp_error"
+	subSCMPUnauth      = "scmp_unauthenticated"
 	subDispatcherError = "dispatcher_error"
 	subParseError      = "parse_error"
 )
@@ -49,6 +50,7 @@ type metrics struct {
 	writePackets     prometheus.Counter
 	parseErrors      prometheus.Counter
 	scmpErrors       prometheus.Counter
+	scmpUnauth       prometheus.Counter
 	dispatcherErrors prometheus.Counter
 }
 
@@ -70,6 +72,8 @@ func newMetrics() metrics {
 			"Total number of packets written"),
 		scmpErrors: prom.NewCounter(Namespace, subSCMPError, "total",
 			"Total number of SCMP errors"),
+		scmpUnauth: prom.NewCounter(Namespace, subSCMPUnauth, "total",
+			"Total number of SCMP errors dropped due to a missing or invalid authenticator"),
 		dispatcherErrors: prom.NewCounter(Namespace, subDispatcherError, "total",
 			"Total number of dispatcher errors"),
 		parseErrors: prom.NewCounter(Namespace, subParseError, "total",
@@ -122,6 +126,11 @@ func (m metrics) SCMPErrors() prometheus.Counter {
 	return m.scmpErrors
 }
 
+// SCMPUnauthenticated returns the unauthenticated SCMP errors counter.
+func (m metrics) SCMPUnauthenticated() prometheus.Counter {
+	return m.scmpUnauth
+}
+
 // ParseErrors returns the parse errors counter.
 func (m metrics) ParseErrors() prometheus.Counter {
 	return m.parseErrors
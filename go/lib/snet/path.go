@@ -71,6 +71,82 @@ type PathMetadata interface {
 	MTU() uint16
 	// Expiry returns the expiration time of the path.
 	Expiry() time.Time
+	// StaticInfo returns the static information announced by the ASes on the
+	// path. Returns nil if the information is not available.
+	StaticInfo() *PathStaticInfo
+}
+
+// PathStaticInfo contains the static information about a path, as announced by
+// the ASes on the path in the static info beacon extension. The per-AS
+// information is ordered along the path.
+type PathStaticInfo struct {
+	// Latency is the total latency of the path. ASes that do not announce
+	// latencies are not accounted for, i.e., the value is a lower bound.
+	Latency time.Duration
+	// Bandwidth is the bottleneck bandwidth of the path in Kbit/s. It is 0 if
+	// no AS announced bandwidth information.
+	Bandwidth uint64
+	// InternalHops is the total number of AS internal hops on the path.
+	InternalHops uint32
+	// LinkTypes contains the types of the inter-AS links of each AS.
+	LinkTypes []ASLinkType
+	// Geo contains the geographic location of the border routers of each AS.
+	Geo []ASGeo
+	// Notes contains the notes announced by each AS.
+	Notes []ASNote
+}
+
+// LinkType is the type of an inter-AS link.
+type LinkType uint16
+
+const (
+	// LinkTypeDirect is a direct link.
+	LinkTypeDirect LinkType = iota
+	// LinkTypeMultihop is a link with intermediate hops.
+	LinkTypeMultihop
+	// LinkTypeOpenNet is a link over the open Internet.
+	LinkTypeOpenNet
+)
+
+func (t LinkType) String() string {
+	switch t {
+	case LinkTypeDirect:
+		return "direct"
+	case LinkTypeMultihop:
+		return "multihop"
+	case LinkTypeOpenNet:
+		return "opennet"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint16(t))
+	}
+}
+
+// ASLinkType is the link type information of an AS on the path.
+type ASLinkType struct {
+	IA addr.IA
+	// Inter is the type of the link to the next AS on the path.
+	Inter LinkType
+	// Peer is the type of the peering link, if the path uses one in this AS.
+	Peer LinkType
+}
+
+// ASGeo is the geographic information of an AS on the path.
+type ASGeo struct {
+	IA        addr.IA
+	Locations []GeoLocation
+}
+
+// GeoLocation is the location of a border router.
+type GeoLocation struct {
+	Latitude  float32
+	Longitude float32
+	Address   string
+}
+
+// ASNote is the note announced by an AS on the path.
+type ASNote struct {
+	IA   addr.IA
+	Note string
 }
 
 type PathFingerprint string
@@ -0,0 +1,95 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snet
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
+)
+
+const (
+	// SCMPAuthTimeout is the maximum time spent on obtaining the key to
+	// verify the authenticator of an SCMP message.
+	SCMPAuthTimeout = time.Second
+	// SCMPAuthWindow is the maximum difference between the timestamp of an
+	// authenticated SCMP message and the local time. It bounds the time an
+	// authenticated SCMP message can be replayed.
+	SCMPAuthWindow = 5 * time.Second
+)
+
+// ErrSCMPUnauthenticated indicates that an SCMP message does not carry a valid
+// DRKey authenticator.
+var ErrSCMPUnauthenticated = serrors.New("SCMP message not authenticated")
+
+// SCMPKeyProvider provides the DRKeys that are used to authenticate SCMP
+// messages.
+type SCMPKeyProvider interface {
+	// GetSCMPKey returns the AS-to-host key that the AS srcIA uses to
+	// authenticate SCMP messages sent to the local host at valTime.
+	GetSCMPKey(ctx context.Context, srcIA addr.IA, valTime time.Time) (common.RawBytes, error)
+}
+
+// VerifySCMPAuth checks that the SCMP packet carries a valid DRKey
+// authenticator of the source AS, and that its timestamp is within
+// SCMPAuthWindow of the local time. If the authenticator is missing or
+// invalid, or the timestamp is outside the window, an error wrapping
+// ErrSCMPUnauthenticated is returned.
+func VerifySCMPAuth(ctx context.Context, pkt *Packet, keys SCMPKeyProvider) error {
+	hdr, ok := pkt.L4Header.(*scmp.Hdr)
+	if !ok {
+		return serrors.New("not an SCMP header", "type", common.TypeOf(pkt.L4Header))
+	}
+	if pkt.Payload == nil {
+		return serrors.New("SCMP payload missing")
+	}
+	var extn *scmp_auth.DRKeyExtn
+	for _, e := range pkt.Extensions {
+		if e, ok := e.(*scmp_auth.DRKeyExtn); ok {
+			extn = e
+			break
+		}
+	}
+	if extn == nil {
+		return serrors.WithCtx(ErrSCMPUnauthenticated, "reason", "no authenticator")
+	}
+	if extn.Direction != scmp_auth.AsToHost {
+		return serrors.WithCtx(ErrSCMPUnauthenticated, "reason", "unsupported direction",
+			"direction", extn.Direction)
+	}
+	if d := time.Since(hdr.Time()); d > SCMPAuthWindow || d < -SCMPAuthWindow {
+		return serrors.WithCtx(ErrSCMPUnauthenticated, "reason", "timestamp outside window",
+			"timestamp", hdr.Time())
+	}
+	key, err := keys.GetSCMPKey(ctx, pkt.Source.IA, hdr.Time())
+	if err != nil {
+		return serrors.Wrap(ErrSCMPUnauthenticated, err, "reason", "key not available")
+	}
+	mac, err := scmp_auth.ComputeDRKeyMAC(key, pkt.Destination.IA, pkt.Source.IA,
+		pkt.Destination.Host, pkt.Source.Host, hdr, pkt.Payload)
+	if err != nil {
+		return serrors.Wrap(ErrSCMPUnauthenticated, err, "reason", "computing MAC")
+	}
+	if subtle.ConstantTimeCompare(mac, extn.MAC) != 1 {
+		return serrors.WithCtx(ErrSCMPUnauthenticated, "reason", "invalid MAC")
+	}
+	return nil
+}
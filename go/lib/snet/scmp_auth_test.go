@@ -0,0 +1,204 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snet_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/hpkt"
+	"github.com/scionproto/scion/go/lib/layers"
+	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/spse/scmp_auth"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+type keyProviderFunc func(context.Context, addr.IA, time.Time) (common.RawBytes, error)
+
+func (f keyProviderFunc) GetSCMPKey(ctx context.Context, srcIA addr.IA,
+	valTime time.Time) (common.RawBytes, error) {
+
+	return f(ctx, srcIA, valTime)
+}
+
+func TestVerifySCMPAuth(t *testing.T) {
+	key := common.RawBytes("0123456789abcdef")
+	srcIA := xtest.MustParseIA("1-ff00:0:110")
+	keys := keyProviderFunc(
+		func(_ context.Context, ia addr.IA, _ time.Time) (common.RawBytes, error) {
+			if !ia.Equal(srcIA) {
+				return nil, serrors.New("unknown AS", "ia", ia)
+			}
+			return key, nil
+		},
+	)
+
+	tests := map[string]struct {
+		Modify    func(sp *spkt.ScnPkt)
+		Keys      snet.SCMPKeyProvider
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Modify:    func(sp *spkt.ScnPkt) {},
+			Keys:      keys,
+			Assertion: assert.NoError,
+		},
+		"no authenticator": {
+			Modify: func(sp *spkt.ScnPkt) {
+				sp.E2EExt = nil
+			},
+			Keys:      keys,
+			Assertion: assertUnauthenticated,
+		},
+		"wrong direction": {
+			Modify: func(sp *spkt.ScnPkt) {
+				require.NoError(t, sp.E2EExt[0].(*scmp_auth.DRKeyExtn).SetDirection(
+					scmp_auth.HostToHost))
+			},
+			Keys:      keys,
+			Assertion: assertUnauthenticated,
+		},
+		"modified payload": {
+			Modify: func(sp *spkt.ScnPkt) {
+				sp.Pld.(*scmp.Payload).Info.(*scmp.InfoPathOffsets).IfID = 42
+			},
+			Keys:      keys,
+			Assertion: assertUnauthenticated,
+		},
+		"modified source": {
+			Modify: func(sp *spkt.ScnPkt) {
+				sp.SrcHost = addr.HostFromIP(net.IPv4(192, 0, 2, 42))
+			},
+			Keys:      keys,
+			Assertion: assertUnauthenticated,
+		},
+		"wrong key": {
+			Modify: func(sp *spkt.ScnPkt) {},
+			Keys: keyProviderFunc(
+				func(context.Context, addr.IA, time.Time) (common.RawBytes, error) {
+					return common.RawBytes("fedcba9876543210"), nil
+				},
+			),
+			Assertion: assertUnauthenticated,
+		},
+		"old timestamp": {
+			Modify: func(sp *spkt.ScnPkt) {
+				sp.L4.(*scmp.Hdr).SetTime(time.Now().Add(-2 * snet.SCMPAuthWindow))
+				authenticate(t, sp, key)
+			},
+			Keys:      keys,
+			Assertion: assertUnauthenticated,
+		},
+		"future timestamp": {
+			Modify: func(sp *spkt.ScnPkt) {
+				sp.L4.(*scmp.Hdr).SetTime(time.Now().Add(2 * snet.SCMPAuthWindow))
+				authenticate(t, sp, key)
+			},
+			Keys:      keys,
+			Assertion: assertUnauthenticated,
+		},
+		"timestamp within window": {
+			Modify: func(sp *spkt.ScnPkt) {
+				sp.L4.(*scmp.Hdr).SetTime(time.Now().Add(-snet.SCMPAuthWindow / 2))
+				authenticate(t, sp, key)
+			},
+			Keys:      keys,
+			Assertion: assert.NoError,
+		},
+		"key not available": {
+			Modify: func(sp *spkt.ScnPkt) {},
+			Keys: keyProviderFunc(
+				func(context.Context, addr.IA, time.Time) (common.RawBytes, error) {
+					return nil, serrors.New("test error")
+				},
+			),
+			Assertion: assertUnauthenticated,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sp := newAuthenticatedSCMP(t, srcIA, key)
+			tc.Modify(sp)
+			pkt := serializeAndParse(t, sp)
+			tc.Assertion(t, snet.VerifySCMPAuth(context.Background(), pkt, tc.Keys))
+		})
+	}
+}
+
+func assertUnauthenticated(t assert.TestingT, err error, _ ...interface{}) bool {
+	return assert.True(t, errors.Is(err, snet.ErrSCMPUnauthenticated), "err: %v", err)
+}
+
+// newAuthenticatedSCMP creates an SCMP error from srcIA that is authenticated
+// with key.
+func newAuthenticatedSCMP(t *testing.T, srcIA addr.IA, key common.RawBytes) *spkt.ScnPkt {
+	ct := scmp.ClassType{Class: scmp.C_Path, Type: scmp.T_P_BadMac}
+	info := &scmp.InfoPathOffsets{InfoF: 1, HopF: 2, IfID: 5, Ingress: true}
+	pld := scmp.PldFromQuotes(ct, info, common.L4UDP, func(scmp.RawBlock) common.RawBytes {
+		return common.RawBytes{1, 2, 3, 4, 5, 6, 7, 8}
+	})
+	sp := &spkt.ScnPkt{
+		DstIA:   srcIA,
+		SrcIA:   srcIA,
+		DstHost: addr.HostFromIP(net.IPv4(192, 0, 2, 1)),
+		SrcHost: addr.HostFromIP(net.IPv4(192, 0, 2, 2)),
+		HBHExt:  []common.Extension{&layers.ExtnSCMP{Error: true}},
+		L4:      scmp.NewHdr(ct, pld.Len()),
+		Pld:     pld,
+	}
+	authenticate(t, sp, key)
+	return sp
+}
+
+// authenticate sets the DRKey authenticator of the SCMP packet computed with
+// key.
+func authenticate(t *testing.T, sp *spkt.ScnPkt, key common.RawBytes) {
+	mac, err := scmp_auth.ComputeDRKeyMAC(key, sp.DstIA, sp.SrcIA, sp.DstHost, sp.SrcHost,
+		sp.L4.(*scmp.Hdr), sp.Pld)
+	require.NoError(t, err)
+	extn := scmp_auth.NewDRKeyExtn()
+	require.NoError(t, extn.SetDirection(scmp_auth.AsToHost))
+	require.NoError(t, extn.SetMAC(mac))
+	sp.E2EExt = []common.Extension{extn}
+}
+
+func serializeAndParse(t *testing.T, sp *spkt.ScnPkt) *snet.Packet {
+	b := make(common.RawBytes, common.MaxMTU)
+	n, err := hpkt.WriteScnPkt(sp, b)
+	require.NoError(t, err)
+	parsed := &spkt.ScnPkt{}
+	require.NoError(t, hpkt.ParseScnPkt(parsed, b[:n]))
+	return &snet.Packet{
+		Bytes: snet.Bytes(b[:n]),
+		PacketInfo: snet.PacketInfo{
+			Destination: snet.SCIONAddress{IA: parsed.DstIA, Host: parsed.DstHost},
+			Source:      snet.SCIONAddress{IA: parsed.SrcIA, Host: parsed.SrcHost},
+			Extensions:  append(parsed.HBHExt, parsed.E2EExt...),
+			L4Header:    parsed.L4,
+			Payload:     parsed.Pld,
+		},
+	}
+}
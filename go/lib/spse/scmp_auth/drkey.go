@@ -30,7 +30,10 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/scrypto"
 	"github.com/scionproto/scion/go/lib/spse"
 )
 
@@ -42,7 +45,7 @@ type DRKeyExtn struct {
 	*spse.BaseExtn
 	// Direction indicates which key has been used during authentication.
 	Direction Dir
-	// MAC is the mac of the SCION Packet with CurrHF and CurrINF set to zero.
+	// MAC is the authenticator of the SCMP message, see ComputeDRKeyMAC.
 	MAC common.RawBytes
 }
 
@@ -92,7 +95,25 @@ func NewDRKeyExtn() *DRKeyExtn {
 	return s
 }
 
-func (s DRKeyExtn) SetDirection(dir Dir) error {
+// NewDRKeyExtnFromRaw parses the extension from raw. The raw bytes must not
+// contain the extension sub-header.
+func NewDRKeyExtnFromRaw(raw common.RawBytes) (*DRKeyExtn, error) {
+	if len(raw) != DRKeyTotalLength {
+		return nil, common.NewBasicError("Invalid header length", nil,
+			"expected", DRKeyTotalLength, "actual", len(raw))
+	}
+	if mode := spse.SecMode(raw[0]); mode != spse.ScmpAuthDRKey {
+		return nil, common.NewBasicError("Invalid SecMode", nil, "mode", mode)
+	}
+	s := NewDRKeyExtn()
+	if err := s.SetDirection(Dir(raw[DirectionOffset])); err != nil {
+		return nil, err
+	}
+	copy(s.MAC, raw[MACOffset:DRKeyTotalLength])
+	return s, nil
+}
+
+func (s *DRKeyExtn) SetDirection(dir Dir) error {
 	if dir > HostToHostReversed {
 		return common.NewBasicError("Invalid direction", nil, "dir", dir)
 	}
@@ -100,7 +121,7 @@ func (s DRKeyExtn) SetDirection(dir Dir) error {
 	return nil
 }
 
-func (s DRKeyExtn) SetMAC(mac common.RawBytes) error {
+func (s *DRKeyExtn) SetMAC(mac common.RawBytes) error {
 	if len(mac) != MACLength {
 		return common.NewBasicError("Invalid MAC size", nil,
 			"expected", MACLength, "actual", len(mac))
@@ -152,3 +173,34 @@ func (s *DRKeyExtn) String() string {
 	fmt.Fprintf(buf, " MAC: %s", s.MAC.String())
 	return buf.String()
 }
+
+// ComputeDRKeyMAC computes the authenticator of an SCMP message with the
+// given DRKey. The MAC covers the destination and source addresses, the SCMP
+// header with the checksum set to zero, and the SCMP payload. Neither the path
+// nor the extensions are covered, as they can be modified on the way to the
+// destination.
+func ComputeDRKeyMAC(key common.RawBytes, dstIA, srcIA addr.IA, dstHost,
+	srcHost addr.HostAddr, hdr *scmp.Hdr, pld common.Payload) (common.RawBytes, error) {
+
+	mac, err := scrypto.InitMac(key)
+	if err != nil {
+		return nil, err
+	}
+	rawHdr, err := hdr.Pack(true)
+	if err != nil {
+		return nil, err
+	}
+	rawPld := make(common.RawBytes, pld.Len())
+	if _, err := pld.WritePld(rawPld); err != nil {
+		return nil, err
+	}
+	input := make(common.RawBytes, 2*addr.IABytes)
+	dstIA.Write(input)
+	srcIA.Write(input[addr.IABytes:])
+	input = append(input, dstHost.Pack()...)
+	input = append(input, srcHost.Pack()...)
+	input = append(input, rawHdr...)
+	input = append(input, rawPld...)
+	mac.Write(input)
+	return mac.Sum(nil), nil
+}
@@ -247,6 +247,8 @@ func (t *topologyS) topoAddress(svc addr.HostSVC, name string) *TopoAddr {
 		addresses = t.Topology.CS
 	case addr.SvcSIG:
 		addresses = t.Topology.SIG
+	case addr.SvcHPS:
+		addresses = t.Topology.HPS
 	}
 	if addresses == nil {
 		return nil
@@ -312,7 +314,8 @@ func (t *topologyS) UnderlayAnycast(svc addr.HostSVC) (*net.UDPAddr, error) {
 
 func supportedSVC(svc addr.HostSVC) bool {
 	b := svc.Base()
-	return b == addr.SvcBS || b == addr.SvcCS || b == addr.SvcPS || b == addr.SvcSIG
+	return b == addr.SvcBS || b == addr.SvcCS || b == addr.SvcPS || b == addr.SvcSIG ||
+		b == addr.SvcHPS
 }
 
 func (t *topologyS) UnderlayMulticast(svc addr.HostSVC) ([]*net.UDPAddr, error) {
@@ -372,6 +375,8 @@ func toProtoServiceType(svc addr.HostSVC) (proto.ServiceType, error) {
 		return proto.ServiceType_cs, nil
 	case addr.SvcSIG:
 		return proto.ServiceType_sig, nil
+	case addr.SvcHPS:
+		return proto.ServiceType_hps, nil
 	default:
 		// FIXME(scrye): Return this error because some calling code in the BR searches for it.
 		// Ideally, the error should be communicated in a more explicit way.
@@ -407,6 +412,8 @@ func (t *topologyS) SVCNames(svc addr.HostSVC) ServiceNames {
 		m = t.Topology.CS
 	case addr.SvcSIG:
 		m = t.Topology.SIG
+	case addr.SvcHPS:
+		m = t.Topology.HPS
 	}
 
 	var names ServiceNames
@@ -37,3 +37,12 @@ func TestTopologySMulticast(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, a)
 }
+
+func TestTopologySHPS(t *testing.T) {
+	topo := topologyS{Topology: MustLoadTopo(t, "testdata/basic.json")}
+	assert.Equal(t, ServiceNames{"hps1-ff00:0:311-1"}, topo.SVCNames(addr.SvcHPS))
+	a, err := topo.Anycast(addr.SvcHPS)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.83:30254", a.String())
+	assert.NotNil(t, topo.PublicAddress(addr.SvcHPS, "hps1-ff00:0:311-1"))
+}
@@ -126,6 +126,7 @@ func TestServiceCount(t *testing.T) {
 	c := MustLoadTopo(t, "testdata/basic.json")
 	assert.Len(t, c.CS, 3, "CS")
 	assert.Len(t, c.SIG, 2, "SIG")
+	assert.Len(t, c.HPS, 1, "HPS")
 }
 
 func TestIFInfoMap(t *testing.T) {
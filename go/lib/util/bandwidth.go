@@ -0,0 +1,70 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// bandwidthUnits are the supported bandwidth units and their size in bit/s,
+// ordered from the largest to the smallest unit.
+var bandwidthUnits = []struct {
+	unit string
+	bps  uint64
+}{
+	{"Tbps", 1000 * 1000 * 1000 * 1000},
+	{"Gbps", 1000 * 1000 * 1000},
+	{"Mbps", 1000 * 1000},
+	{"kbps", 1000},
+	{"bps", 1},
+}
+
+// ParseBandwidth parses a bandwidth with a unit, e.g. "100Mbps", and returns
+// it in bit/s. The supported units are bps, kbps, Mbps, Gbps and Tbps. Units
+// are case-sensitive, such that a bandwidth in bytes, e.g. "100MBps", is not
+// mistaken for one in bits.
+func ParseBandwidth(s string) (uint64, error) {
+	trimmed := strings.TrimSpace(s)
+	for _, u := range bandwidthUnits {
+		if !strings.HasSuffix(trimmed, u.unit) {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(strings.TrimSuffix(trimmed, u.unit)), 10, 64)
+		if err != nil {
+			return 0, serrors.WrapStr("parsing bandwidth", err, "value", s)
+		}
+		if v > math.MaxUint64/u.bps {
+			return 0, serrors.New("bandwidth out of range", "value", s)
+		}
+		return v * u.bps, nil
+	}
+	return 0, serrors.New("bandwidth has no valid unit", "value", s)
+}
+
+// FmtBandwidth formats a bandwidth in bit/s with the largest unit that
+// represents it exactly, e.g. "100Mbps".
+func FmtBandwidth(bps uint64) string {
+	for _, u := range bandwidthUnits {
+		if bps >= u.bps && bps%u.bps == 0 {
+			return fmt.Sprintf("%d%s", bps/u.bps, u.unit)
+		}
+	}
+	return "0bps"
+}
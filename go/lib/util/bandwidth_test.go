@@ -0,0 +1,69 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	tests := map[string]struct {
+		Input     string
+		Expected  uint64
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"bps":        {Input: "800bps", Expected: 800, Assertion: assert.NoError},
+		"kbps":       {Input: "1500kbps", Expected: 1500000, Assertion: assert.NoError},
+		"Mbps":       {Input: "100Mbps", Expected: 100000000, Assertion: assert.NoError},
+		"Gbps":       {Input: "10 Gbps", Expected: 10000000000, Assertion: assert.NoError},
+		"Tbps":       {Input: "1Tbps", Expected: 1000000000000, Assertion: assert.NoError},
+		"zero":       {Input: "0kbps", Expected: 0, Assertion: assert.NoError},
+		"no unit":    {Input: "100", Assertion: assert.Error},
+		"no number":  {Input: "Mbps", Assertion: assert.Error},
+		"bad number": {Input: "fastMbps", Assertion: assert.Error},
+		"negative":   {Input: "-1Mbps", Assertion: assert.Error},
+		"lowercase":  {Input: "1gbps", Assertion: assert.Error},
+		"uppercase":  {Input: "100MBPS", Assertion: assert.Error},
+		"bytes":      {Input: "100MBps", Assertion: assert.Error},
+		"overflow":   {Input: "18446745Tbps", Assertion: assert.Error},
+		"largest":    {Input: "18446744Tbps", Expected: 18446744e12, Assertion: assert.NoError},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bps, err := util.ParseBandwidth(tc.Input)
+			tc.Assertion(t, err)
+			assert.Equal(t, tc.Expected, bps)
+		})
+	}
+}
+
+func TestFmtBandwidth(t *testing.T) {
+	tests := map[uint64]string{
+		0:             "0bps",
+		800:           "800bps",
+		1500000:       "1500kbps",
+		100000000:     "100Mbps",
+		2000000000:    "2Gbps",
+		1000000000000: "1Tbps",
+		1000000001:    "1000000001bps",
+	}
+	for bps, expected := range tests {
+		assert.Equal(t, expected, util.FmtBandwidth(bps))
+	}
+}
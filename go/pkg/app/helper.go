@@ -25,6 +25,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
@@ -48,9 +49,11 @@ func QueryASInfo(ctx context.Context, conn sciond.Connector) (ASInfo, error) {
 	}, nil
 }
 
-// ChoosePath selects a path to the remote
+// ChoosePath selects a path to the remote. Only the paths that are allowed by
+// the policy are considered. If the policy has an order, the first path is
+// chosen in non-interactive mode, otherwise a random one.
 func ChoosePath(ctx context.Context, conn sciond.Connector, remote addr.IA,
-	interactive, refresh bool) (snet.Path, error) {
+	interactive, refresh bool, policy *pathpol.Policy) (snet.Path, error) {
 
 	paths, err := conn.Paths(ctx, remote, addr.IA{}, sciond.PathReqFlags{Refresh: refresh})
 	if err != nil {
@@ -59,7 +62,15 @@ func ChoosePath(ctx context.Context, conn sciond.Connector, remote addr.IA,
 	if len(paths) == 0 {
 		return nil, serrors.New("no path available")
 	}
+	if policy != nil {
+		if paths = FilterPaths(paths, policy); len(paths) == 0 {
+			return nil, serrors.New("no path matching the policy available")
+		}
+	}
 	if !interactive {
+		if policy != nil && len(policy.Order) > 0 {
+			return paths[0], nil
+		}
 		return paths[rand.Intn(len(paths))], nil
 	}
 
@@ -79,6 +90,64 @@ func ChoosePath(ctx context.Context, conn sciond.Connector, remote addr.IA,
 	}
 }
 
+// PathPolicy creates the policy that restricts the paths to a remote. The
+// policy is loaded from the policy file, if it is not empty. The sequence, if
+// it is not empty, restricts the paths of the policy further. If both are
+// empty, nil is returned.
+func PathPolicy(file, sequence string) (*pathpol.Policy, error) {
+	if file == "" && sequence == "" {
+		return nil, nil
+	}
+	policy := &pathpol.Policy{}
+	if file != "" {
+		var err error
+		if policy, err = pathpol.LoadPolicy(file); err != nil {
+			return nil, err
+		}
+	}
+	if sequence != "" {
+		if policy.Sequence != nil {
+			return nil, serrors.New("sequence is set in both the policy file and the flag")
+		}
+		seq, err := pathpol.NewSequence(sequence)
+		if err != nil {
+			return nil, serrors.WrapStr("parsing sequence", err)
+		}
+		policy.Sequence = seq
+	}
+	return policy, nil
+}
+
+// FilterPaths returns the paths that are allowed by the policy, sorted
+// according to the order of the policy. Paths that are equal with respect to
+// the order keep their relative position. If the policy is nil, the paths are
+// returned unchanged.
+func FilterPaths(paths []snet.Path, policy *pathpol.Policy) []snet.Path {
+	if policy == nil {
+		return paths
+	}
+	set := make(pathpol.PathSet, len(paths))
+	for _, path := range paths {
+		set[snet.Fingerprint(path)] = path
+	}
+	allowed := policy.Filter(set)
+	filtered := make([]pathpol.Path, 0, len(allowed))
+	for _, path := range paths {
+		fp := snet.Fingerprint(path)
+		if _, ok := allowed[fp]; ok {
+			filtered = append(filtered, path)
+			// Only keep the first path with the fingerprint.
+			delete(allowed, fp)
+		}
+	}
+	policy.Sort(filtered)
+	result := make([]snet.Path, 0, len(filtered))
+	for _, path := range filtered {
+		result = append(result, path.(snet.Path))
+	}
+	return result
+}
+
 // WithSignal derives a child context that subsribes a signal handler for the
 // provided signals. The returned context gets cancled if any of the subscribed
 // signals is received
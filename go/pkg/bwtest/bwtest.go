@@ -0,0 +1,155 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bwtest implements a bandwidth test over SCION/UDP.
+//
+// The client requests a test session from the server. The server accepts the
+// request with a random nonce, which the client echoes to start the test. The
+// session is bound to the address the request came from: messages from other
+// addresses are dropped, and test packets are only sent to that address. This
+// ensures that the server only sends test packets to clients that can receive
+// them, i.e., that do not spoof their address. Both sides then simultaneously
+// send test packets at the requested rate for the requested duration. After
+// the test, the client queries the statistics the server collected for the
+// client to server direction.
+package bwtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/util"
+)
+
+const (
+	// MaxDuration is the maximum duration of a test.
+	MaxDuration = time.Minute
+	// MinPacketSize is the minimum size of a test packet. It is the size of
+	// the header of a test packet.
+	MinPacketSize = dataHdrLen
+	// MaxPacketSize is the maximum size of a test packet.
+	MaxPacketSize = 9000
+	// DefaultMaxRate is the default maximum rate of the test traffic the
+	// server accepts in either direction.
+	DefaultMaxRate BitRate = 10 * 1000 * 1000
+)
+
+// Parameters describe the test traffic in one direction.
+type Parameters struct {
+	// Duration is the duration of the test.
+	Duration time.Duration
+	// PacketSize is the size of the UDP payload of the test packets.
+	PacketSize int
+	// Rate is the target rate of the test traffic.
+	Rate BitRate
+}
+
+// NumPackets returns the number of test packets that are sent.
+func (p Parameters) NumPackets() int {
+	if p.PacketSize <= 0 {
+		return 0
+	}
+	return int(float64(p.Rate) * p.Duration.Seconds() / float64(8*p.PacketSize))
+}
+
+// validate validates the parameters. A maxRate of zero does not limit the rate.
+func (p Parameters) validate(maxRate BitRate) error {
+	if p.Duration <= 0 || p.Duration > MaxDuration {
+		return serrors.New("invalid duration", "duration", p.Duration, "max", MaxDuration)
+	}
+	if p.PacketSize < MinPacketSize || p.PacketSize > MaxPacketSize {
+		return serrors.New("invalid packet size", "size", p.PacketSize,
+			"min", MinPacketSize, "max", MaxPacketSize)
+	}
+	if maxRate != 0 && p.Rate > maxRate {
+		return serrors.New("rate too high", "rate", p.Rate, "max", maxRate)
+	}
+	if p.NumPackets() == 0 {
+		return serrors.New("rate too low to send a single packet", "rate", p.Rate)
+	}
+	return nil
+}
+
+func (p Parameters) String() string {
+	return fmt.Sprintf("%s, %d bytes, %s", p.Duration, p.PacketSize, p.Rate)
+}
+
+// BitRate is a rate in bit/s. It is represented as a string with a unit,
+// e.g. "10Mbps", except for zero, which is "0". The supported units are the
+// ones of util.ParseBandwidth. It implements the pflag.Value interface.
+type BitRate uint64
+
+func (b BitRate) String() string {
+	if b == 0 {
+		return "0"
+	}
+	return util.FmtBandwidth(uint64(b))
+}
+
+// Set parses the bit rate from a string.
+func (b *BitRate) Set(s string) error {
+	if strings.TrimSpace(s) == "0" {
+		*b = 0
+		return nil
+	}
+	bps, err := util.ParseBandwidth(s)
+	if err != nil {
+		return err
+	}
+	*b = BitRate(bps)
+	return nil
+}
+
+// Type returns the type name used in the command line help.
+func (b *BitRate) Type() string {
+	return "rate"
+}
+
+// Stats are the statistics of the test traffic in one direction.
+type Stats struct {
+	// Sent is the number of sent test packets.
+	Sent int
+	// Received is the number of received test packets. Duplicates are not
+	// counted.
+	Received int
+	// Jitter is the interarrival jitter of the test packets as defined in
+	// RFC 3550.
+	Jitter time.Duration
+}
+
+// Result is the result of one direction of the bandwidth test.
+type Result struct {
+	Parameters
+	Stats
+}
+
+// Throughput is the achieved throughput, i.e., the rate of the received test
+// packets over the test duration.
+func (r Result) Throughput() BitRate {
+	if r.Duration <= 0 {
+		return 0
+	}
+	bits := float64(r.Received) * float64(8*r.PacketSize)
+	return BitRate(bits / r.Duration.Seconds())
+}
+
+// Loss is the fraction of the sent test packets that were not received.
+func (r Result) Loss() float64 {
+	if r.Sent == 0 {
+		return 0
+	}
+	return float64(r.Sent-r.Received) / float64(r.Sent)
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bwtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitRate(t *testing.T) {
+	testCases := map[string]struct {
+		Input    string
+		Expected BitRate
+		Str      string
+		ErrAsser assert.ErrorAssertionFunc
+	}{
+		"bps": {
+			Input:    "800bps",
+			Expected: 800,
+			Str:      "800bps",
+			ErrAsser: assert.NoError,
+		},
+		"kbps": {
+			Input:    "1500kbps",
+			Expected: 1500000,
+			Str:      "1500kbps",
+			ErrAsser: assert.NoError,
+		},
+		"Mbps": {
+			Input:    "10 Mbps",
+			Expected: 10000000,
+			Str:      "10Mbps",
+			ErrAsser: assert.NoError,
+		},
+		"Gbps": {
+			Input:    "1Gbps",
+			Expected: 1000000000,
+			Str:      "1Gbps",
+			ErrAsser: assert.NoError,
+		},
+		"zero":      {Input: "0", Expected: 0, Str: "0", ErrAsser: assert.NoError},
+		"lowercase": {Input: "1gbps", ErrAsser: assert.Error},
+		"bytes":     {Input: "10MBps", ErrAsser: assert.Error},
+		"no unit":   {Input: "100", ErrAsser: assert.Error},
+		"no num":    {Input: "Mbps", ErrAsser: assert.Error},
+		"overflow":  {Input: "18446744073709552Gbps", ErrAsser: assert.Error},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var b BitRate
+			err := b.Set(tc.Input)
+			tc.ErrAsser(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.Expected, b)
+			assert.Equal(t, tc.Str, b.String())
+		})
+	}
+}
+
+func TestParametersValidate(t *testing.T) {
+	valid := Parameters{Duration: 3 * time.Second, PacketSize: 1000, Rate: 8000000}
+	assert.NoError(t, valid.validate(0))
+	assert.NoError(t, valid.validate(DefaultMaxRate))
+	assert.Equal(t, 3000, valid.NumPackets())
+
+	tooLong := valid
+	tooLong.Duration = 2 * MaxDuration
+	assert.Error(t, tooLong.validate(0))
+
+	tooSmall := valid
+	tooSmall.PacketSize = MinPacketSize - 1
+	assert.Error(t, tooSmall.validate(0))
+
+	tooSlow := valid
+	tooSlow.Rate = 1
+	assert.Error(t, tooSlow.validate(0))
+
+	tooFast := valid
+	tooFast.Rate = DefaultMaxRate + 1
+	assert.NoError(t, tooFast.validate(0))
+	assert.Error(t, tooFast.validate(DefaultMaxRate))
+}
+
+func TestResult(t *testing.T) {
+	r := Result{
+		Parameters: Parameters{Duration: 2 * time.Second, PacketSize: 1000, Rate: 8000000},
+		Stats:      Stats{Sent: 2000, Received: 1500},
+	}
+	assert.Equal(t, BitRate(6000000), r.Throughput())
+	assert.Equal(t, 0.25, r.Loss())
+}
+
+func TestWire(t *testing.T) {
+	cs := Parameters{Duration: 3 * time.Second, PacketSize: 1000, Rate: 8000000}
+	sc := Parameters{Duration: time.Second, PacketSize: 200, Rate: 1000000}
+
+	raw := encodeRequest(42, cs, sc)
+	typ, session, err := parseHdr(raw)
+	require.NoError(t, err)
+	assert.Equal(t, msgRequest, typ)
+	assert.Equal(t, uint64(42), session)
+	decCS, decSC, err := decodeRequest(raw)
+	require.NoError(t, err)
+	assert.Equal(t, cs, decCS)
+	assert.Equal(t, sc, decSC)
+
+	buf := make([]byte, 100)
+	sent := time.Unix(0, 1234567890)
+	putData(buf, 42, 7, sent)
+	seq, decSent, err := parseData(buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), seq)
+	assert.True(t, sent.Equal(decSent))
+
+	stats := Stats{Received: 10, Jitter: time.Millisecond}
+	decStats, scSent, err := decodeResult(encodeResult(42, stats, 20))
+	require.NoError(t, err)
+	assert.Equal(t, stats, decStats)
+	assert.Equal(t, 20, scSent)
+
+	raw = encodeNonce(msgAccept, 42, 0xdeadbeef)
+	typ, session, err = parseHdr(raw)
+	require.NoError(t, err)
+	assert.Equal(t, msgAccept, typ)
+	assert.Equal(t, uint64(42), session)
+	nonce, err := decodeNonce(raw)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0xdeadbeef), nonce)
+	_, err = decodeNonce(encodeControl(msgStart, 42))
+	assert.Error(t, err)
+
+	assert.Equal(t, "busy", decodeReject(encodeReject(42, "busy")))
+	_, _, err = parseHdr([]byte{1})
+	assert.Error(t, err)
+}
+
+func TestReceiver(t *testing.T) {
+	r := newReceiver(Parameters{Duration: time.Second, PacketSize: 1000, Rate: 80000})
+	start := time.Now()
+	// The transit times alternate between 10ms and 12ms.
+	for i := 0; i < 10; i++ {
+		sent := start.Add(time.Duration(i) * time.Millisecond)
+		transit := 10 * time.Millisecond
+		if i%2 == 1 {
+			transit = 12 * time.Millisecond
+		}
+		r.Add(uint32(i), sent, sent.Add(transit))
+	}
+	// Duplicates and out of range sequence numbers are ignored.
+	r.Add(3, start, start)
+	r.Add(10, start, start)
+
+	stats := r.Stats()
+	assert.Equal(t, 10, stats.Received)
+	assert.True(t, stats.Jitter > 0 && stats.Jitter < 2*time.Millisecond, stats.Jitter)
+}
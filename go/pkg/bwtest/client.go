@@ -0,0 +1,234 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bwtest
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+)
+
+// DefaultPort is the default port of the bandwidth test server.
+const DefaultPort = 30100
+
+// controlAttempts is the number of times a control message is sent before
+// giving up.
+const controlAttempts = 3
+
+// Config configures the bandwidth test client.
+type Config struct {
+	Dispatcher reliable.Dispatcher
+	Local      *snet.UDPAddr
+	// Remote is the address of the server. The path and the next hop must be
+	// set if the server is in a remote AS.
+	Remote *snet.UDPAddr
+
+	// ClientToServer are the parameters of the client to server direction.
+	ClientToServer Parameters
+	// ServerToClient are the parameters of the server to client direction.
+	ServerToClient Parameters
+	// Timeout is the time to wait for the reply to a control message, and
+	// for outstanding test packets after the test.
+	Timeout time.Duration
+
+	// ErrHandler is invoked for every error that does not cause the test to
+	// abort. Execution time must be small, as it is run synchronous.
+	ErrHandler func(err error)
+	// SCMPKeys, if set, are used to verify that SCMP errors are authenticated
+	// by the AS that sent them. SCMP errors that fail verification are
+	// dropped.
+	SCMPKeys snet.SCMPKeyProvider
+
+	// HeaderV2 indicates whether the new header format is used.
+	HeaderV2 bool
+}
+
+// Run runs a bandwidth test against the server and returns the results of
+// the client to server and the server to client direction.
+func Run(ctx context.Context, cfg Config) (Result, Result, error) {
+	if err := cfg.ClientToServer.validate(0); err != nil {
+		return Result{}, Result{}, serrors.WrapStr("client to server parameters", err)
+	}
+	if err := cfg.ServerToClient.validate(0); err != nil {
+		return Result{}, Result{}, serrors.WrapStr("server to client parameters", err)
+	}
+	network := &snet.SCIONNetwork{
+		LocalIA: cfg.Local.IA,
+		Dispatcher: &snet.DefaultPacketDispatcherService{
+			Dispatcher:  cfg.Dispatcher,
+			SCMPHandler: newSCMPHandler(cfg.SCMPKeys),
+			Version2:    cfg.HeaderV2,
+		},
+		Version2: cfg.HeaderV2,
+	}
+	conn, err := network.Listen(ctx, "udp", cfg.Local.Host, addr.SvcNone)
+	if err != nil {
+		return Result{}, Result{}, serrors.WrapStr("listening", err)
+	}
+	defer conn.Close()
+
+	c := &client{
+		cfg:      cfg,
+		conn:     conn,
+		session:  rand.Uint64(),
+		control:  make(chan []byte, controlAttempts),
+		receiver: newReceiver(cfg.ServerToClient),
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		defer log.HandlePanic()
+		c.read(ctx)
+	}()
+	return c.run(ctx)
+}
+
+type client struct {
+	cfg      Config
+	conn     *snet.Conn
+	session  uint64
+	control  chan []byte
+	receiver *receiver
+}
+
+func (c *client) run(ctx context.Context) (Result, Result, error) {
+	req := encodeRequest(c.session, c.cfg.ClientToServer, c.cfg.ServerToClient)
+	raw, err := c.exchange(ctx, req, msgAccept)
+	if err != nil {
+		return Result{}, Result{}, serrors.WrapStr("requesting test session", err)
+	}
+	nonce, err := decodeNonce(raw)
+	if err != nil {
+		return Result{}, Result{}, err
+	}
+	if _, err := c.exchange(ctx, encodeNonce(msgStart, c.session, nonce), msgStarted); err != nil {
+		return Result{}, Result{}, serrors.WrapStr("starting test session", err)
+	}
+
+	start := time.Now()
+	sent, err := send(ctx, c.conn, c.cfg.Remote, c.session, c.cfg.ClientToServer)
+	if err != nil {
+		return Result{}, Result{}, err
+	}
+	// Wait for the end of the server to client direction and the outstanding
+	// test packets.
+	end := start.Add(c.cfg.ServerToClient.Duration + c.cfg.Timeout)
+	select {
+	case <-ctx.Done():
+		return Result{}, Result{}, ctx.Err()
+	case <-time.After(time.Until(end)):
+	}
+
+	raw, err = c.exchange(ctx, encodeControl(msgResultRequest, c.session), msgResult)
+	if err != nil {
+		return Result{}, Result{}, serrors.WrapStr("requesting result", err)
+	}
+	csStats, scSent, err := decodeResult(raw)
+	if err != nil {
+		return Result{}, Result{}, err
+	}
+	csStats.Sent = sent
+	scStats := c.receiver.Stats()
+	scStats.Sent = scSent
+	return Result{Parameters: c.cfg.ClientToServer, Stats: csStats},
+		Result{Parameters: c.cfg.ServerToClient, Stats: scStats}, nil
+}
+
+// exchange sends the control message to the server until a reply of the
+// expected type is received.
+func (c *client) exchange(ctx context.Context, msg []byte, expected msgType) ([]byte, error) {
+	for i := 0; i < controlAttempts; i++ {
+		if _, err := c.conn.WriteTo(msg, c.cfg.Remote); err != nil {
+			return nil, serrors.WrapStr("sending control message", err)
+		}
+		raw, err := c.await(ctx, expected)
+		if err != nil || raw != nil {
+			return raw, err
+		}
+	}
+	return nil, serrors.New("no reply from server", "attempts", controlAttempts)
+}
+
+// await waits for a control message of the expected type. It returns nil if
+// no such message is received before the timeout.
+func (c *client) await(ctx context.Context, expected msgType) ([]byte, error) {
+	timeout := time.NewTimer(c.cfg.Timeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, nil
+		case raw := <-c.control:
+			switch msgType(raw[0]) {
+			case expected:
+				return raw, nil
+			case msgReject:
+				return nil, serrors.New("rejected by server", "reason", decodeReject(raw))
+			}
+		}
+	}
+}
+
+// read reads the messages from the server. Test packets are recorded, and
+// control messages are passed on.
+func (c *client) read(ctx context.Context) {
+	buf := make([]byte, MaxPacketSize)
+	for {
+		n, _, err := c.conn.ReadFrom(buf)
+		received := time.Now()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.handleErr(serrors.WrapStr("reading packet", err))
+			continue
+		}
+		t, session, err := parseHdr(buf[:n])
+		if err != nil {
+			c.handleErr(err)
+			continue
+		}
+		if session != c.session {
+			continue
+		}
+		if t != msgData {
+			select {
+			case c.control <- append([]byte(nil), buf[:n]...):
+			default:
+			}
+			continue
+		}
+		seq, sent, err := parseData(buf[:n])
+		if err != nil {
+			c.handleErr(err)
+			continue
+		}
+		c.receiver.Add(seq, sent, received)
+	}
+}
+
+func (c *client) handleErr(err error) {
+	if c.cfg.ErrHandler != nil {
+		c.cfg.ErrHandler(err)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bwtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+)
+
+const (
+	// maxSessions is the maximum number of concurrent test sessions.
+	maxSessions = 8
+	// sessionGrace is the time a session is kept after the test, such that
+	// the client can query the result.
+	sessionGrace = 30 * time.Second
+	// startTimeout is the time the server waits for the client to start an
+	// accepted session.
+	startTimeout = 10 * time.Second
+)
+
+// ServerConfig configures the bandwidth test server.
+type ServerConfig struct {
+	Dispatcher reliable.Dispatcher
+	Local      *snet.UDPAddr
+
+	// ErrHandler is invoked for every error that does not cause the server to
+	// abort. Execution time must be small, as it is run synchronous.
+	ErrHandler func(err error)
+	// SessionHandler is invoked for every accepted test session. Execution
+	// time must be small, as it is run synchronous.
+	SessionHandler func(remote *snet.UDPAddr, cs, sc Parameters)
+
+	// MaxRate is the maximum rate of the test traffic in either direction.
+	// If zero, DefaultMaxRate is used.
+	MaxRate BitRate
+	// SCMPKeys, if set, are used to verify that SCMP errors are authenticated
+	// by the AS that sent them. SCMP errors that fail verification are
+	// dropped.
+	SCMPKeys snet.SCMPKeyProvider
+
+	// HeaderV2 indicates whether the new header format is used.
+	HeaderV2 bool
+}
+
+// Serve serves bandwidth test sessions until the context is canceled.
+func Serve(ctx context.Context, cfg ServerConfig) error {
+	network := &snet.SCIONNetwork{
+		LocalIA: cfg.Local.IA,
+		Dispatcher: &snet.DefaultPacketDispatcherService{
+			Dispatcher:  cfg.Dispatcher,
+			SCMPHandler: newSCMPHandler(cfg.SCMPKeys),
+			Version2:    cfg.HeaderV2,
+		},
+		Version2: cfg.HeaderV2,
+	}
+	conn, err := network.Listen(ctx, "udp", cfg.Local.Host, addr.SvcNone)
+	if err != nil {
+		return serrors.WrapStr("listening", err)
+	}
+	defer conn.Close()
+	go func() {
+		defer log.HandlePanic()
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	s := &server{
+		cfg:      cfg,
+		conn:     conn,
+		sessions: make(map[uint64]*serverSession),
+	}
+	buf := make([]byte, MaxPacketSize)
+	for {
+		n, remote, err := conn.ReadFrom(buf)
+		received := time.Now()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			s.handleErr(serrors.WrapStr("reading packet", err))
+			continue
+		}
+		if err := s.handle(ctx, buf[:n], remote.(*snet.UDPAddr), received); err != nil {
+			s.handleErr(err)
+		}
+	}
+}
+
+type server struct {
+	cfg      ServerConfig
+	conn     net.PacketConn
+	sessions map[uint64]*serverSession
+}
+
+type serverSession struct {
+	cs, sc Parameters
+	// remote is the client that requested the session. Messages of the
+	// session from other addresses are dropped, and all messages and test
+	// packets are only sent to it.
+	remote *snet.UDPAddr
+	// nonce is the nonce the client has to echo to start the session.
+	nonce uint64
+	// started indicates whether the client started the session. The
+	// receiver is only set for started sessions.
+	started  bool
+	receiver *receiver
+	expires  time.Time
+
+	mtx sync.Mutex
+	// sent is the number of test packets sent to the client. It is negative
+	// while the test packets are being sent.
+	sent int
+}
+
+func (s *server) handle(ctx context.Context, raw []byte, remote *snet.UDPAddr,
+	received time.Time) error {
+
+	t, id, err := parseHdr(raw)
+	if err != nil {
+		return err
+	}
+	if t == msgRequest {
+		return s.handleRequest(raw, id, remote)
+	}
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil
+	}
+	if !sameAddr(session.remote, remote) {
+		return serrors.New("message from unexpected address", "session", id,
+			"remote", remote, "expected", session.remote)
+	}
+	if t == msgStart {
+		return s.handleStart(ctx, raw, id, session)
+	}
+	// Messages of sessions that are not started are ignored.
+	if !session.started {
+		return nil
+	}
+	switch t {
+	case msgData:
+		seq, sent, err := parseData(raw)
+		if err != nil {
+			return err
+		}
+		session.receiver.Add(seq, sent, received)
+	case msgResultRequest:
+		session.mtx.Lock()
+		sent := session.sent
+		session.mtx.Unlock()
+		// The client retries the request if the test packets are still
+		// being sent.
+		if sent < 0 {
+			return nil
+		}
+		return s.write(encodeResult(id, session.receiver.Stats(), sent), session.remote)
+	default:
+		return serrors.New("unexpected message", "type", t)
+	}
+	return nil
+}
+
+// handleRequest accepts the requested session with a random nonce. No test
+// packets are sent before the client echoes the nonce.
+func (s *server) handleRequest(raw []byte, id uint64, remote *snet.UDPAddr) error {
+	// Retransmitted requests are accepted again, without creating a new session.
+	if session, ok := s.sessions[id]; ok {
+		if !sameAddr(session.remote, remote) {
+			return serrors.New("request from unexpected address", "session", id,
+				"remote", remote, "expected", session.remote)
+		}
+		return s.write(encodeNonce(msgAccept, id, session.nonce), session.remote)
+	}
+	cs, sc, err := decodeRequest(raw)
+	if err != nil {
+		return err
+	}
+	maxRate := s.cfg.MaxRate
+	if maxRate == 0 {
+		maxRate = DefaultMaxRate
+	}
+	if err := cs.validate(maxRate); err != nil {
+		return s.write(encodeReject(id, err.Error()), remote)
+	}
+	if err := sc.validate(maxRate); err != nil {
+		return s.write(encodeReject(id, err.Error()), remote)
+	}
+	now := time.Now()
+	for k, session := range s.sessions {
+		if now.After(session.expires) {
+			delete(s.sessions, k)
+		}
+	}
+	if len(s.sessions) >= maxSessions {
+		return s.write(encodeReject(id, "too many sessions"), remote)
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	s.sessions[id] = &serverSession{
+		cs:      cs,
+		sc:      sc,
+		remote:  remote.Copy(),
+		nonce:   nonce,
+		expires: now.Add(startTimeout),
+		sent:    -1,
+	}
+	return s.write(encodeNonce(msgAccept, id, nonce), remote)
+}
+
+// handleStart starts the test of the session if the client echoes the nonce.
+func (s *server) handleStart(ctx context.Context, raw []byte, id uint64,
+	session *serverSession) error {
+
+	nonce, err := decodeNonce(raw)
+	if err != nil {
+		return err
+	}
+	if nonce != session.nonce {
+		return serrors.New("invalid nonce", "session", id, "remote", session.remote)
+	}
+	// Retransmitted start messages are confirmed again, without starting a
+	// new test.
+	if session.started {
+		return s.write(encodeControl(msgStarted, id), session.remote)
+	}
+	duration := session.cs.Duration
+	if session.sc.Duration > duration {
+		duration = session.sc.Duration
+	}
+	session.started = true
+	session.receiver = newReceiver(session.cs)
+	session.expires = time.Now().Add(duration + sessionGrace)
+	if s.cfg.SessionHandler != nil {
+		s.cfg.SessionHandler(session.remote, session.cs, session.sc)
+	}
+	if err := s.write(encodeControl(msgStarted, id), session.remote); err != nil {
+		return err
+	}
+	go func() {
+		defer log.HandlePanic()
+		sent, err := send(ctx, s.conn, session.remote, id, session.sc)
+		if err != nil {
+			s.handleErr(err)
+		}
+		session.mtx.Lock()
+		defer session.mtx.Unlock()
+		session.sent = sent
+	}()
+	return nil
+}
+
+func (s *server) write(msg []byte, remote *snet.UDPAddr) error {
+	if _, err := s.conn.WriteTo(msg, remote); err != nil {
+		return serrors.WrapStr("sending control message", err, "remote", remote)
+	}
+	return nil
+}
+
+// sameAddr returns whether the addresses refer to the same host and port in
+// the same AS. The paths are not compared, as they can change between the
+// packets of a session.
+func sameAddr(a, b *snet.UDPAddr) bool {
+	return a.IA.Equal(b.IA) && a.Host.IP.Equal(b.Host.IP) && a.Host.Port == b.Host.Port
+}
+
+func (s *server) handleErr(err error) {
+	if s.cfg.ErrHandler != nil {
+		s.cfg.ErrHandler(err)
+	}
+}
+
+func newNonce() (uint64, error) {
+	var raw [8]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return 0, serrors.WrapStr("generating nonce", err)
+	}
+	return binary.BigEndian.Uint64(raw[:]), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bwtest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestServerSpoofedClient(t *testing.T) {
+	client := &snet.UDPAddr{
+		IA:   xtest.MustParseIA("1-ff00:0:110"),
+		Host: &net.UDPAddr{IP: net.IP{192, 0, 2, 1}, Port: 40000},
+	}
+	params := Parameters{Duration: time.Second, PacketSize: 1000, Rate: 80000}
+	tests := map[string]*snet.UDPAddr{
+		"other IA": {
+			IA:   xtest.MustParseIA("1-ff00:0:111"),
+			Host: client.Host,
+		},
+		"other IP": {
+			IA:   client.IA,
+			Host: &net.UDPAddr{IP: net.IP{192, 0, 2, 2}, Port: 40000},
+		},
+		"other port": {
+			IA:   client.IA,
+			Host: &net.UDPAddr{IP: net.IP{192, 0, 2, 1}, Port: 40001},
+		},
+	}
+	for name, victim := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancelF := context.WithCancel(context.Background())
+			defer cancelF()
+			conn := &recordingConn{}
+			s := &server{conn: conn, sessions: make(map[uint64]*serverSession)}
+
+			now := time.Now()
+			require.NoError(t, s.handle(ctx, encodeRequest(42, params, params), client, now))
+			accept := conn.take()
+			require.Len(t, accept, 1)
+			nonce, err := decodeNonce(accept[0])
+			require.NoError(t, err)
+
+			// The attacker knows the nonce, but sends from the victim's address.
+			assert.Error(t, s.handle(ctx, encodeNonce(msgStart, 42, nonce), victim, now))
+			assert.Error(t, s.handle(ctx, encodeRequest(42, params, params), victim, now))
+			assert.Error(t, s.handle(ctx, encodeControl(msgResultRequest, 42), victim, now))
+			assert.False(t, s.sessions[42].started)
+			assert.Empty(t, conn.take())
+
+			// The actual client can still start the session, and the test
+			// packets are sent to it.
+			require.NoError(t, s.handle(ctx, encodeNonce(msgStart, 42, nonce), client, now))
+			assert.True(t, s.sessions[42].started)
+			cancelF()
+			for _, w := range conn.writes() {
+				assert.Equal(t, client, w)
+			}
+		})
+	}
+}
+
+// recordingConn records the messages written to it and their destinations.
+type recordingConn struct {
+	net.PacketConn
+
+	mtx  sync.Mutex
+	msgs [][]byte
+	dsts []net.Addr
+}
+
+func (c *recordingConn) WriteTo(b []byte, dst net.Addr) (int, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.msgs = append(c.msgs, append([]byte(nil), b...))
+	c.dsts = append(c.dsts, dst)
+	return len(b), nil
+}
+
+// take returns the recorded messages and resets them.
+func (c *recordingConn) take() [][]byte {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	msgs := c.msgs
+	c.msgs, c.dsts = nil, nil
+	return msgs
+}
+
+// writes returns the destinations of the recorded messages.
+func (c *recordingConn) writes() []net.Addr {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]net.Addr(nil), c.dsts...)
+}
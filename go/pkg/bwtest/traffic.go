@@ -0,0 +1,112 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bwtest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+// receiver collects the statistics of the received test packets.
+type receiver struct {
+	mtx  sync.Mutex
+	seen []bool
+	// received is the number of distinct test packets that were received.
+	received int
+	// jitter is the interarrival jitter in nanoseconds.
+	jitter float64
+	// transit is the relative transit time of the previous test packet.
+	transit    time.Duration
+	hasTransit bool
+}
+
+func newReceiver(p Parameters) *receiver {
+	return &receiver{seen: make([]bool, p.NumPackets())}
+}
+
+// Add records the reception of a test packet. Duplicates and packets with a
+// sequence number that is out of range are ignored.
+func (r *receiver) Add(seq uint32, sent, received time.Time) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if int(seq) >= len(r.seen) || r.seen[seq] {
+		return
+	}
+	r.seen[seq] = true
+	r.received++
+
+	// The transit time includes the clock offset between sender and
+	// receiver, which cancels out in the difference. See RFC 3550 6.4.1.
+	transit := received.Sub(sent)
+	if r.hasTransit {
+		d := transit - r.transit
+		if d < 0 {
+			d = -d
+		}
+		r.jitter += (float64(d) - r.jitter) / 16
+	}
+	r.transit, r.hasTransit = transit, true
+}
+
+// Stats returns the current statistics. The number of sent packets is not
+// known to the receiver and is left zero.
+func (r *receiver) Stats() Stats {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return Stats{Received: r.received, Jitter: time.Duration(r.jitter)}
+}
+
+// send sends the test packets to remote, paced to the configured rate. It
+// returns the number of sent test packets. Sending stops after the configured
+// duration, even if not all test packets were sent.
+func send(ctx context.Context, conn net.PacketConn, remote net.Addr, session uint64,
+	p Parameters) (int, error) {
+
+	n := p.NumPackets()
+	interval := p.Duration / time.Duration(n)
+	buf := make([]byte, p.PacketSize)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		next := start.Add(time.Duration(i) * interval)
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return i, nil
+			case <-time.After(wait):
+			}
+		} else if time.Since(start) > p.Duration {
+			return i, nil
+		}
+		putData(buf, session, uint32(i), time.Now())
+		if _, err := conn.WriteTo(buf, remote); err != nil {
+			return i, serrors.WrapStr("sending test packet", err, "seq", i)
+		}
+	}
+	return n, nil
+}
+
+// newSCMPHandler returns the SCMP handler, which verifies the authenticator of
+// SCMP errors if keys are set.
+func newSCMPHandler(keys snet.SCMPKeyProvider) snet.SCMPHandler {
+	if keys == nil {
+		return snet.NewSCMPHandler(nil)
+	}
+	return snet.NewSCMPHandler(nil, snet.WithSCMPAuth(keys))
+}
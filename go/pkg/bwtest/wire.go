@@ -0,0 +1,172 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bwtest
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// msgType is the type of a bandwidth test message. Every message starts with
+// the message type and the session ID.
+type msgType uint8
+
+const (
+	// msgRequest requests a test session. It is sent by the client and
+	// contains the parameters of both directions.
+	msgRequest msgType = iota + 1
+	// msgAccept is the reply of the server to an acceptable request. It
+	// contains the nonce the client has to echo to start the test.
+	msgAccept
+	// msgReject is the reply of the server to a request that is not
+	// acceptable. It contains the reason.
+	msgReject
+	// msgData is a test packet. It contains the sequence number and the send
+	// time, and is padded to the packet size.
+	msgData
+	// msgResultRequest requests the statistics of the client to server
+	// direction from the server.
+	msgResultRequest
+	// msgResult contains the statistics of the client to server direction,
+	// and the number of test packets the server sent.
+	msgResult
+	// msgStart starts the accepted test session. It contains the nonce of the
+	// accept message.
+	msgStart
+	// msgStarted is the reply of the server to a valid start message.
+	msgStarted
+)
+
+const (
+	hdrLen     = 1 + 8
+	paramsLen  = 8 + 4 + 8
+	dataHdrLen = hdrLen + 4 + 8
+	resultLen  = hdrLen + 4 + 4 + 8
+	nonceLen   = 8
+)
+
+func putHdr(b []byte, t msgType, session uint64) {
+	b[0] = byte(t)
+	binary.BigEndian.PutUint64(b[1:], session)
+}
+
+func parseHdr(b []byte) (msgType, uint64, error) {
+	if len(b) < hdrLen {
+		return 0, 0, serrors.New("message too short", "len", len(b))
+	}
+	return msgType(b[0]), binary.BigEndian.Uint64(b[1:]), nil
+}
+
+func encodeRequest(session uint64, cs, sc Parameters) []byte {
+	b := make([]byte, hdrLen+2*paramsLen)
+	putHdr(b, msgRequest, session)
+	putParams(b[hdrLen:], cs)
+	putParams(b[hdrLen+paramsLen:], sc)
+	return b
+}
+
+func decodeRequest(b []byte) (Parameters, Parameters, error) {
+	if len(b) < hdrLen+2*paramsLen {
+		return Parameters{}, Parameters{}, serrors.New("request too short", "len", len(b))
+	}
+	return parseParams(b[hdrLen:]), parseParams(b[hdrLen+paramsLen:]), nil
+}
+
+func putParams(b []byte, p Parameters) {
+	binary.BigEndian.PutUint64(b, uint64(p.Duration))
+	binary.BigEndian.PutUint32(b[8:], uint32(p.PacketSize))
+	binary.BigEndian.PutUint64(b[12:], uint64(p.Rate))
+}
+
+func parseParams(b []byte) Parameters {
+	return Parameters{
+		Duration:   time.Duration(binary.BigEndian.Uint64(b)),
+		PacketSize: int(binary.BigEndian.Uint32(b[8:])),
+		Rate:       BitRate(binary.BigEndian.Uint64(b[12:])),
+	}
+}
+
+func encodeControl(t msgType, session uint64) []byte {
+	b := make([]byte, hdrLen)
+	putHdr(b, t, session)
+	return b
+}
+
+// encodeNonce encodes an accept or start message with the nonce.
+func encodeNonce(t msgType, session uint64, nonce uint64) []byte {
+	b := make([]byte, hdrLen+nonceLen)
+	putHdr(b, t, session)
+	binary.BigEndian.PutUint64(b[hdrLen:], nonce)
+	return b
+}
+
+func decodeNonce(b []byte) (uint64, error) {
+	if len(b) < hdrLen+nonceLen {
+		return 0, serrors.New("nonce message too short", "len", len(b))
+	}
+	return binary.BigEndian.Uint64(b[hdrLen:]), nil
+}
+
+func encodeReject(session uint64, reason string) []byte {
+	b := make([]byte, hdrLen+len(reason))
+	putHdr(b, msgReject, session)
+	copy(b[hdrLen:], reason)
+	return b
+}
+
+func decodeReject(b []byte) string {
+	return string(b[hdrLen:])
+}
+
+// putData writes the test packet header to b. The rest of b is padding.
+func putData(b []byte, session uint64, seq uint32, sent time.Time) {
+	putHdr(b, msgData, session)
+	binary.BigEndian.PutUint32(b[hdrLen:], seq)
+	binary.BigEndian.PutUint64(b[hdrLen+4:], uint64(sent.UnixNano()))
+}
+
+func parseData(b []byte) (uint32, time.Time, error) {
+	if len(b) < dataHdrLen {
+		return 0, time.Time{}, serrors.New("test packet too short", "len", len(b))
+	}
+	seq := binary.BigEndian.Uint32(b[hdrLen:])
+	sent := time.Unix(0, int64(binary.BigEndian.Uint64(b[hdrLen+4:])))
+	return seq, sent, nil
+}
+
+// encodeResult encodes the statistics of the client to server direction as
+// observed by the server, and the number of test packets sent by the server.
+// The number of packets sent by the client is not encoded.
+func encodeResult(session uint64, cs Stats, scSent int) []byte {
+	b := make([]byte, resultLen)
+	putHdr(b, msgResult, session)
+	binary.BigEndian.PutUint32(b[hdrLen:], uint32(cs.Received))
+	binary.BigEndian.PutUint64(b[hdrLen+4:], uint64(cs.Jitter))
+	binary.BigEndian.PutUint32(b[hdrLen+12:], uint32(scSent))
+	return b
+}
+
+func decodeResult(b []byte) (Stats, int, error) {
+	if len(b) < resultLen {
+		return Stats{}, 0, serrors.New("result too short", "len", len(b))
+	}
+	cs := Stats{
+		Received: int(binary.BigEndian.Uint32(b[hdrLen:])),
+		Jitter:   time.Duration(binary.BigEndian.Uint64(b[hdrLen+4:])),
+	}
+	return cs, int(binary.BigEndian.Uint32(b[hdrLen+12:])), nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/pkg/trust"
+)
+
+// RPC is the interface used to request keys from remote control services.
+type RPC interface {
+	GetDRKey(ctx context.Context, msg *drkey_mgmt.DRKeyReq, a net.Addr,
+		id uint64) (*drkey_mgmt.DRKeyRep, error)
+}
+
+var _ Lvl1Fetcher = Fetcher{}
+
+// SignerGen generates the signer of the local AS.
+type SignerGen interface {
+	Generate(ctx context.Context) (trust.Signer, error)
+}
+
+// Fetcher fetches level-1 keys from the control service of the source AS.
+// The requests are signed with a signer from SignerGen, and the key in the
+// reply is decrypted with the private key of the same signer. The replies are
+// verified with Verifier.
+type Fetcher struct {
+	LocalIA   addr.IA
+	RPC       RPC
+	Router    snet.Router
+	SignerGen SignerGen
+	Verifier  infra.Verifier
+}
+
+// GetLvl1Key fetches the level-1 key from srcIA to the local AS that is valid
+// at valTime.
+func (f Fetcher) GetLvl1Key(ctx context.Context, srcIA addr.IA,
+	valTime time.Time) (drkey.Lvl1Key, error) {
+
+	path, err := f.Router.Route(ctx, srcIA)
+	if err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("finding path", err, "ia", srcIA)
+	}
+	if path == nil {
+		return drkey.Lvl1Key{}, serrors.New("no path found", "ia", srcIA)
+	}
+	remote := &snet.SVCAddr{
+		IA:      path.Destination(),
+		Path:    path.Path(),
+		NextHop: path.UnderlayNextHop(),
+		SVC:     addr.SvcCS,
+	}
+	signer, err := f.SignerGen.Generate(ctx)
+	if err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("generating signer", err)
+	}
+	req := drkey_mgmt.NewDRKeyReq(srcIA, valTime, false)
+	if err := req.Sign(ctx, signer); err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("signing request", err)
+	}
+	rep, err := f.RPC.GetDRKey(ctx, req, remote, messenger.NextId())
+	if err != nil {
+		return drkey.Lvl1Key{}, err
+	}
+	if err := rep.Verify(ctx, f.Verifier.WithIA(srcIA)); err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("verifying reply", err)
+	}
+	if !rep.SrcIA().Equal(srcIA) {
+		return drkey.Lvl1Key{}, serrors.New("reply for wrong ISD-AS",
+			"expected", srcIA, "actual", rep.SrcIA())
+	}
+	if !rep.Epoch().Contains(valTime) {
+		return drkey.Lvl1Key{}, serrors.New("reply for wrong epoch",
+			"val_time", valTime, "epoch", rep.Epoch())
+	}
+	key, err := rep.DecryptKey(f.LocalIA, signer.PrivateKey)
+	if err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("decrypting key", err)
+	}
+	if len(key) != drkey.KeyLen {
+		return drkey.Lvl1Key{}, serrors.New("invalid key length",
+			"expected", drkey.KeyLen, "actual", len(key))
+	}
+	return drkey.Lvl1Key{
+		Lvl1Meta: drkey.Lvl1Meta{
+			Epoch: rep.Epoch(),
+			SrcIA: srcIA,
+			DstIA: f.LocalIA,
+		},
+		Key: key,
+	}, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra/mock_infra"
+	"github.com/scionproto/scion/go/lib/snet/mock_snet"
+	"github.com/scionproto/scion/go/lib/spath"
+	csdrkey "github.com/scionproto/scion/go/pkg/cs/drkey"
+	"github.com/scionproto/scion/go/pkg/trust"
+)
+
+type signerGenFunc func(context.Context) (trust.Signer, error)
+
+func (f signerGenFunc) Generate(ctx context.Context) (trust.Signer, error) {
+	return f(ctx)
+}
+
+type rpcFunc func(context.Context, *drkey_mgmt.DRKeyReq) (*drkey_mgmt.DRKeyRep, error)
+
+func (f rpcFunc) GetDRKey(ctx context.Context, msg *drkey_mgmt.DRKeyReq, _ net.Addr,
+	_ uint64) (*drkey_mgmt.DRKeyRep, error) {
+
+	return f(ctx, msg)
+}
+
+func TestFetcherGetLvl1Key(t *testing.T) {
+	now := time.Now()
+	remote := newStore(t, remoteIA, nil)
+	remoteKey, err := remote.DeriveLvl1(localIA, now)
+	require.NoError(t, err)
+	localPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	remotePriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	remoteSigner := trust.Signer{
+		PrivateKey: remotePriv,
+		Hash:       crypto.SHA256,
+		IA:         remoteIA,
+		Expiration: now.Add(time.Hour),
+	}
+
+	reply := func(pub crypto.PublicKey) (*drkey_mgmt.DRKeyRep, error) {
+		rep, err := drkey_mgmt.NewEncryptedDRKeyRep(remoteKey.SrcIA, localIA, remoteKey.Epoch,
+			remoteKey.Key, pub)
+		if err != nil {
+			return nil, err
+		}
+		return rep, rep.Sign(context.Background(), remoteSigner)
+	}
+	testCases := map[string]struct {
+		Reply        func() (*drkey_mgmt.DRKeyRep, error)
+		ErrAssertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Reply: func() (*drkey_mgmt.DRKeyRep, error) {
+				return reply(&localPriv.PublicKey)
+			},
+			ErrAssertion: assert.NoError,
+		},
+		"wrong recipient": {
+			Reply: func() (*drkey_mgmt.DRKeyRep, error) {
+				return reply(&otherPriv.PublicKey)
+			},
+			ErrAssertion: assert.Error,
+		},
+		"tampered": {
+			Reply: func() (*drkey_mgmt.DRKeyRep, error) {
+				rep, err := reply(&localPriv.PublicKey)
+				if err != nil {
+					return nil, err
+				}
+				rep.Key[len(rep.Key)-1] ^= 0x01
+				return rep, nil
+			},
+			ErrAssertion: assert.Error,
+		},
+		"plaintext": {
+			Reply: func() (*drkey_mgmt.DRKeyRep, error) {
+				rep := drkey_mgmt.NewDRKeyRep(remoteKey.SrcIA, remoteKey.Epoch, remoteKey.Key)
+				return rep, rep.Sign(context.Background(), remoteSigner)
+			},
+			ErrAssertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			mctrl := gomock.NewController(t)
+			defer mctrl.Finish()
+			path := mock_snet.NewMockPath(mctrl)
+			path.EXPECT().Destination().Return(remoteIA).AnyTimes()
+			path.EXPECT().Path().Return(spath.New(nil)).AnyTimes()
+			path.EXPECT().UnderlayNextHop().Return(&net.UDPAddr{}).AnyTimes()
+			router := mock_snet.NewMockRouter(mctrl)
+			router.EXPECT().Route(gomock.Any(), remoteIA).Return(path, nil)
+			verifier := mock_infra.NewMockVerifier(mctrl)
+			verifier.EXPECT().WithIA(remoteIA).Return(verifier)
+			verifier.EXPECT().Verify(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+			fetcher := csdrkey.Fetcher{
+				LocalIA: localIA,
+				RPC: rpcFunc(func(ctx context.Context,
+					req *drkey_mgmt.DRKeyReq) (*drkey_mgmt.DRKeyRep, error) {
+
+					assert.Equal(t, remoteIA, req.SrcIA())
+					assert.NotEmpty(t, req.Signature)
+					return tc.Reply()
+				}),
+				Router: router,
+				SignerGen: signerGenFunc(func(context.Context) (trust.Signer, error) {
+					return trust.Signer{
+						PrivateKey: localPriv,
+						Hash:       crypto.SHA256,
+						IA:         localIA,
+						Expiration: now.Add(time.Hour),
+					}, nil
+				}),
+				Verifier: verifier,
+			}
+			key, err := fetcher.GetLvl1Key(context.Background(), remoteIA, now)
+			tc.ErrAssertion(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, remoteKey, key)
+			assert.Equal(t, drkey.KeyLen, len(key.Key))
+		})
+	}
+}
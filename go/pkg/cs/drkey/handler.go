@@ -0,0 +1,194 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"net"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl"
+	"github.com/scionproto/scion/go/lib/ctrl/drkey_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/pkg/trust"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// Handler handles DRKey requests.
+//
+// Requests from remote ASes must be signed by the requesting AS. They are
+// answered with the level-1 key from the local AS to the requesting AS. The key
+// is encrypted to the public key of the certificate that signed the request,
+// which is looked up with Chains. The reply is signed with Signer.
+//
+// Requests from hosts in the local AS are answered with the level-2 AS-to-host
+// key for SCMP from the requested AS to the requesting host. This is the key
+// that the border routers of the requested AS use to authenticate the SCMP
+// messages they send to the host.
+type Handler struct {
+	Store    *ServiceStore
+	Signer   drkey_mgmt.Signer
+	Verifier infra.Verifier
+	Chains   ChainProvider
+}
+
+// ChainProvider provides the certificate chains of remote ASes.
+type ChainProvider interface {
+	GetChains(context.Context, trust.ChainQuery, ...trust.Option) ([][]*x509.Certificate, error)
+}
+
+// Handle handles a DRKey request.
+func (h Handler) Handle(req *infra.Request) *infra.HandlerResult {
+	if req == nil {
+		log.Error("[drkey:Handler] Request is nil")
+		return infra.MetricsErrInternal
+	}
+	span, ctx := opentracing.StartSpanFromContext(req.Context(), "drkey.handler")
+	defer span.Finish()
+	logger := log.FromCtx(ctx)
+
+	drkeyReq, ok := req.Message.(*drkey_mgmt.DRKeyReq)
+	if !ok {
+		logger.Error("[drkey:Handler] Wrong message type, expected drkey_mgmt.DRKeyReq",
+			"msg", req.Message, "type", common.TypeOf(req.Message))
+		return infra.MetricsErrInternal
+	}
+	logger.Debug("[drkey:Handler] Received request", "req", drkeyReq, "peer", req.Peer)
+	rw, ok := infra.ResponseWriterFromContext(ctx)
+	if !ok {
+		logger.Error("[drkey:Handler] Unable to service request, no ResponseWriter found")
+		return infra.MetricsErrInternal
+	}
+	sendAck := messenger.SendAckHelper(ctx, rw)
+
+	var rep *drkey_mgmt.DRKeyRep
+	var err error
+	switch peer := req.Peer.(type) {
+	case *snet.UDPAddr:
+		if peer.IA.Equal(h.Store.LocalIA) {
+			rep, err = h.lvl2Reply(ctx, drkeyReq, addr.HostFromIP(peer.Host.IP))
+		} else {
+			rep, err = h.lvl1Reply(ctx, drkeyReq, peer.IA)
+		}
+	case *net.TCPAddr:
+		// The TCP messenger is only reachable from within the local AS.
+		rep, err = h.lvl2Reply(ctx, drkeyReq, addr.HostFromIP(peer.IP))
+	default:
+		logger.Error("[drkey:Handler] Invalid peer address type",
+			"peer", req.Peer, "type", common.TypeOf(req.Peer))
+		sendAck(proto.Ack_ErrCode_reject, messenger.AckRejectFailedToParse)
+		return infra.MetricsErrInvalid
+	}
+	if err != nil {
+		logger.Info("[drkey:Handler] Unable to service request", "peer", req.Peer, "err", err)
+		sendAck(proto.Ack_ErrCode_reject, err.Error())
+		return infra.MetricsErrInvalid
+	}
+	if err := rw.SendDRKeyReply(ctx, rep); err != nil {
+		logger.Error("[drkey:Handler] Messenger API error", "err", err)
+		return infra.MetricsErrMsger(err)
+	}
+	logger.Debug("[drkey:Handler] Replied with key", "peer", req.Peer, "epoch", rep.Epoch())
+	return infra.MetricsResultOk
+}
+
+func (h Handler) lvl1Reply(ctx context.Context, req *drkey_mgmt.DRKeyReq,
+	dstIA addr.IA) (*drkey_mgmt.DRKeyRep, error) {
+
+	if !req.SrcIA().Equal(h.Store.LocalIA) {
+		return nil, serrors.New("level-1 key requested for remote ISD-AS",
+			"requested", req.SrcIA())
+	}
+	if err := req.Verify(ctx, h.Verifier.WithIA(dstIA)); err != nil {
+		return nil, serrors.WrapStr("verifying request", err)
+	}
+	key, err := h.Store.DeriveLvl1(dstIA, req.ValTime())
+	if err == nil && req.Flags.Prefetch {
+		key, err = h.Store.DeriveLvl1(dstIA, key.Epoch.End)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pub, err := h.requesterKey(ctx, req, dstIA)
+	if err != nil {
+		return nil, err
+	}
+	rep, err := drkey_mgmt.NewEncryptedDRKeyRep(key.SrcIA, dstIA, key.Epoch, key.Key, pub)
+	if err != nil {
+		return nil, err
+	}
+	if err := rep.Sign(ctx, h.Signer); err != nil {
+		return nil, serrors.WrapStr("signing reply", err)
+	}
+	return rep, nil
+}
+
+// requesterKey returns the public key of the certificate that signed the
+// already verified request.
+func (h Handler) requesterKey(ctx context.Context, req *drkey_mgmt.DRKeyReq,
+	dstIA addr.IA) (crypto.PublicKey, error) {
+
+	meta, err := req.SignMeta()
+	if err != nil {
+		return nil, err
+	}
+	src, err := ctrl.NewX509SignSrc(meta.Src)
+	if err != nil {
+		return nil, serrors.WrapStr("parsing signature source", err)
+	}
+	chains, err := h.Chains.GetChains(ctx, trust.ChainQuery{
+		IA:           dstIA,
+		SubjectKeyID: src.SubjectKeyID,
+		Date:         time.Now(),
+	})
+	if err != nil {
+		return nil, serrors.WrapStr("fetching certificate chain", err)
+	}
+	if len(chains) == 0 {
+		return nil, serrors.New("no certificate chain found", "ia", dstIA)
+	}
+	return chains[0][0].PublicKey, nil
+}
+
+func (h Handler) lvl2Reply(ctx context.Context, req *drkey_mgmt.DRKeyReq,
+	host addr.HostAddr) (*drkey_mgmt.DRKeyRep, error) {
+
+	meta := drkey.Lvl2Meta{
+		KeyType:  drkey.AS2Host,
+		Protocol: drkey.ProtocolSCMP,
+		SrcIA:    req.SrcIA(),
+		DstIA:    h.Store.LocalIA,
+		DstHost:  host,
+	}
+	key, err := h.Store.GetLvl2Key(ctx, meta, req.ValTime())
+	if err == nil && req.Flags.Prefetch {
+		key, err = h.Store.GetLvl2Key(ctx, meta, key.Epoch.End)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return drkey_mgmt.NewDRKeyRep(key.SrcIA, key.Epoch, key.Key), nil
+}
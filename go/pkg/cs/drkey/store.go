@@ -0,0 +1,112 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drkey implements the DRKey service of the control service. It
+// derives the level-1 keys of the local AS, fetches the level-1 keys of remote
+// ASes and serves level-2 keys to hosts in the local AS.
+package drkey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/infra/modules/cleaner"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// Lvl1Fetcher fetches level-1 keys from remote ASes.
+type Lvl1Fetcher interface {
+	// GetLvl1Key fetches the level-1 key from srcIA to the local AS that is
+	// valid at valTime.
+	GetLvl1Key(ctx context.Context, srcIA addr.IA, valTime time.Time) (drkey.Lvl1Key, error)
+}
+
+// ServiceStore provides the level-1 and level-2 keys of the local AS. Keys
+// originating in the local AS are derived from the secret values. Keys
+// originating in remote ASes are looked up in the DB, and fetched from the
+// remote AS if they are missing.
+type ServiceStore struct {
+	LocalIA      addr.IA
+	SecretValues *drkey.SecretValueStore
+	DB           drkey.Lvl1DB
+	Fetcher      Lvl1Fetcher
+}
+
+// DeriveLvl1 derives the level-1 key from the local AS to dstIA that is valid
+// at valTime.
+func (s *ServiceStore) DeriveLvl1(dstIA addr.IA, valTime time.Time) (drkey.Lvl1Key, error) {
+	sv, err := s.SecretValues.Get(valTime)
+	if err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("getting secret value", err)
+	}
+	return drkey.DeriveLvl1(s.LocalIA, dstIA, sv)
+}
+
+// GetLvl1Key returns the level-1 key from srcIA to the local AS that is valid
+// at valTime.
+func (s *ServiceStore) GetLvl1Key(ctx context.Context, srcIA addr.IA,
+	valTime time.Time) (drkey.Lvl1Key, error) {
+
+	if srcIA.Equal(s.LocalIA) {
+		return s.DeriveLvl1(s.LocalIA, valTime)
+	}
+	key, err := s.DB.GetLvl1Key(ctx, srcIA, s.LocalIA, valTime)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, drkey.ErrKeyNotFound) {
+		return drkey.Lvl1Key{}, serrors.WrapStr("looking up level-1 key", err)
+	}
+	if key, err = s.Fetcher.GetLvl1Key(ctx, srcIA, valTime); err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("fetching level-1 key", err,
+			"src_ia", srcIA)
+	}
+	if err := s.DB.InsertLvl1Key(ctx, key); err != nil {
+		return drkey.Lvl1Key{}, serrors.WrapStr("storing level-1 key", err)
+	}
+	return key, nil
+}
+
+// GetLvl2Key returns the level-2 key described by meta that is valid at
+// valTime. Either the source or the destination AS must be the local AS.
+func (s *ServiceStore) GetLvl2Key(ctx context.Context, meta drkey.Lvl2Meta,
+	valTime time.Time) (drkey.Lvl2Key, error) {
+
+	var lvl1 drkey.Lvl1Key
+	var err error
+	switch {
+	case meta.SrcIA.Equal(s.LocalIA):
+		lvl1, err = s.DeriveLvl1(meta.DstIA, valTime)
+	case meta.DstIA.Equal(s.LocalIA):
+		lvl1, err = s.GetLvl1Key(ctx, meta.SrcIA, valTime)
+	default:
+		return drkey.Lvl2Key{}, serrors.New("neither source nor destination is local",
+			"src_ia", meta.SrcIA, "dst_ia", meta.DstIA)
+	}
+	if err != nil {
+		return drkey.Lvl2Key{}, err
+	}
+	return drkey.DeriveLvl2(meta, lvl1)
+}
+
+// NewLvl1Cleaner creates a cleaner task that removes expired level-1 keys.
+func NewLvl1Cleaner(db drkey.Lvl1DB) *cleaner.Cleaner {
+	return cleaner.New(func(ctx context.Context) (int, error) {
+		n, err := db.RemoveOutdatedLvl1Keys(ctx, time.Now())
+		return int(n), err
+	}, "drkey_lvl1")
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drkey_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/drkey"
+	"github.com/scionproto/scion/go/lib/drkey/sqlite"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/xtest"
+	csdrkey "github.com/scionproto/scion/go/pkg/cs/drkey"
+)
+
+var (
+	localIA  = xtest.MustParseIA("1-ff00:0:110")
+	remoteIA = xtest.MustParseIA("1-ff00:0:111")
+)
+
+type fetcherFunc func(context.Context, addr.IA, time.Time) (drkey.Lvl1Key, error)
+
+func (f fetcherFunc) GetLvl1Key(ctx context.Context, srcIA addr.IA,
+	valTime time.Time) (drkey.Lvl1Key, error) {
+
+	return f(ctx, srcIA, valTime)
+}
+
+func TestServiceStoreGetLvl1Key(t *testing.T) {
+	ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+	defer cancelF()
+	now := time.Now()
+	remote := newStore(t, remoteIA, nil)
+	remoteKey, err := remote.DeriveLvl1(localIA, now)
+	require.NoError(t, err)
+
+	fetches := 0
+	local := newStore(t, localIA, fetcherFunc(
+		func(_ context.Context, srcIA addr.IA, valTime time.Time) (drkey.Lvl1Key, error) {
+			fetches++
+			if !srcIA.Equal(remoteIA) {
+				return drkey.Lvl1Key{}, serrors.New("unknown AS", "ia", srcIA)
+			}
+			return remote.DeriveLvl1(localIA, valTime)
+		},
+	))
+
+	key, err := local.GetLvl1Key(ctx, remoteIA, now)
+	require.NoError(t, err)
+	assert.Equal(t, remoteKey, key)
+	assert.Equal(t, 1, fetches)
+
+	// The second lookup is served from the DB.
+	key, err = local.GetLvl1Key(ctx, remoteIA, now)
+	require.NoError(t, err)
+	assert.Equal(t, remoteKey, key)
+	assert.Equal(t, 1, fetches)
+
+	// Keys of the local AS are derived and never fetched.
+	key, err = local.GetLvl1Key(ctx, localIA, now)
+	require.NoError(t, err)
+	assert.Equal(t, localIA, key.SrcIA)
+	assert.Equal(t, 1, fetches)
+
+	_, err = local.GetLvl1Key(ctx, xtest.MustParseIA("1-ff00:0:112"), now)
+	assert.Error(t, err)
+}
+
+func TestServiceStoreGetLvl2Key(t *testing.T) {
+	ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+	defer cancelF()
+	now := time.Now()
+	remote := newStore(t, remoteIA, nil)
+	local := newStore(t, localIA, fetcherFunc(
+		func(_ context.Context, _ addr.IA, valTime time.Time) (drkey.Lvl1Key, error) {
+			return remote.DeriveLvl1(localIA, valTime)
+		},
+	))
+	host := addr.HostFromIP(net.IPv4(192, 0, 2, 1))
+
+	testCases := map[string]struct {
+		Meta      drkey.Lvl2Meta
+		Derive    *csdrkey.ServiceStore
+		ShouldErr bool
+	}{
+		"remote source": {
+			Meta: drkey.Lvl2Meta{
+				KeyType:  drkey.AS2Host,
+				Protocol: drkey.ProtocolSCMP,
+				SrcIA:    remoteIA,
+				DstIA:    localIA,
+				DstHost:  host,
+			},
+			Derive: remote,
+		},
+		"local source": {
+			Meta: drkey.Lvl2Meta{
+				KeyType:  drkey.AS2AS,
+				Protocol: drkey.ProtocolSCMP,
+				SrcIA:    localIA,
+				DstIA:    remoteIA,
+			},
+			Derive: local,
+		},
+		"not local": {
+			Meta: drkey.Lvl2Meta{
+				KeyType:  drkey.AS2AS,
+				Protocol: drkey.ProtocolSCMP,
+				SrcIA:    remoteIA,
+				DstIA:    xtest.MustParseIA("1-ff00:0:112"),
+			},
+			ShouldErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			key, err := local.GetLvl2Key(ctx, tc.Meta, now)
+			if tc.ShouldErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			// The other side derives the same key from its own secret value.
+			lvl1, err := tc.Derive.DeriveLvl1(tc.Meta.DstIA, now)
+			require.NoError(t, err)
+			expected, err := drkey.DeriveLvl2(tc.Meta, lvl1)
+			require.NoError(t, err)
+			assert.Equal(t, expected, key)
+		})
+	}
+}
+
+func newStore(t *testing.T, ia addr.IA, fetcher csdrkey.Lvl1Fetcher) *csdrkey.ServiceStore {
+	db, err := sqlite.New("file::memory:")
+	require.NoError(t, err)
+	return &csdrkey.ServiceStore{
+		LocalIA:      ia,
+		SecretValues: drkey.NewSecretValueStore([]byte(ia.String()), time.Hour),
+		DB:           db,
+		Fetcher:      fetcher,
+	}
+}
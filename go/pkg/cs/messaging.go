@@ -19,8 +19,10 @@ import (
 	"hash"
 	"net"
 	"path/filepath"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/drkey"
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/infraenv"
 	"github.com/scionproto/scion/go/lib/infra/messenger/tcp"
@@ -60,6 +62,18 @@ func MACGenFactory(configDir string) (func() hash.Hash, error) {
 	return hfMacFactory, nil
 }
 
+// NewDRKeySecretValueStore creates the store for the DRKey secret values that
+// are derived from the AS master key.
+func NewDRKeySecretValueStore(configDir string,
+	epochDuration time.Duration) (*drkey.SecretValueStore, error) {
+
+	mk, err := keyconf.LoadMaster(filepath.Join(configDir, "keys"))
+	if err != nil {
+		return nil, serrors.WrapStr("loading master key", err)
+	}
+	return drkey.NewSecretValueStore(mk.Key0, epochDuration), nil
+}
+
 // NewOneHopConn registers a new connection that should be used with one hop
 // paths.
 func NewOneHopConn(ia addr.IA, pub *net.UDPAddr, disp string, reconnecting,
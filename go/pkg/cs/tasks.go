@@ -68,6 +68,12 @@ type TasksConfig struct {
 	PropagationInterval  time.Duration
 	RegistrationInterval time.Duration
 
+	// HiddenPathRegistration is the optional hidden path registration policy
+	// for down segments. HiddenPathRouter provides the paths to the hidden
+	// path registries in remote ASes.
+	HiddenPathRegistration *beacon.HPRegistration
+	HiddenPathRouter       snet.Router
+
 	AllowIsdLoop bool
 	HeaderV2     bool
 }
@@ -160,6 +166,13 @@ func (t *TasksConfig) registrar(topo topology.Topology, segType proto.PathSegTyp
 		Pather:   addrutil.NewPather(t.TopoProvider, t.HeaderV2),
 		Tick:     beaconing.NewTick(t.RegistrationInterval),
 	}
+	if segType == proto.PathSegType_down && t.HiddenPathRegistration != nil {
+		r.HiddenPaths = &beaconing.HiddenPathRegistration{
+			Policy: t.HiddenPathRegistration,
+			RPC:    beaconingcompat.RPC{Messenger: t.Msgr},
+			Router: t.HiddenPathRouter,
+		}
+	}
 	return periodic.Start(r, 500*time.Millisecond, t.RegistrationInterval)
 }
 
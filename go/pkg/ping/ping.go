@@ -78,6 +78,11 @@ type Config struct {
 	// small, as it is run synchronous.
 	UpdateHandler func(Update)
 
+	// SCMPKeys, if set, are used to verify that SCMP errors are authenticated
+	// by the AS that sent them. SCMP errors that fail verification are
+	// dropped.
+	SCMPKeys snet.SCMPKeyProvider
+
 	HeaderV2 bool
 }
 
@@ -96,6 +101,7 @@ func Run(ctx context.Context, cfg Config) (Stats, error) {
 		SCMPHandler: scmpHandler{
 			id:      id,
 			replies: replies,
+			keys:    cfg.SCMPKeys,
 		},
 		Version2: cfg.HeaderV2,
 	}
@@ -261,9 +267,14 @@ type reply struct {
 type scmpHandler struct {
 	id      uint64
 	replies chan<- reply
+	keys    snet.SCMPKeyProvider
 }
 
 func (h scmpHandler) Handle(pkt *snet.Packet) error {
+	// Unauthenticated SCMP errors are dropped.
+	if err := h.verify(pkt); err != nil {
+		return nil
+	}
 	hdr, info, err := h.handle(pkt)
 	h.replies <- reply{
 		Error:    err,
@@ -296,3 +307,17 @@ func (h scmpHandler) handle(pkt *snet.Packet) (*scmp.Hdr, *scmp.InfoEcho, error)
 	}
 	return scmpHdr, info, nil
 }
+
+// verify verifies the authenticator of SCMP errors if keys are set. Echo
+// replies are not authenticated.
+func (h scmpHandler) verify(pkt *snet.Packet) error {
+	if h.keys == nil {
+		return nil
+	}
+	if hdr, ok := pkt.L4Header.(*scmp.Hdr); ok && hdr.Class == scmp.C_General {
+		return nil
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), snet.SCMPAuthTimeout)
+	defer cancelF()
+	return snet.VerifySCMPAuth(ctx, pkt, h.keys)
+}
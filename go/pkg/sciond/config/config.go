@@ -21,6 +21,7 @@ import (
 
 	"github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/serrors"
@@ -104,6 +105,14 @@ type SDConfig struct {
 	// QueryInterval specifies after how much time segments
 	// for a destination should be refetched.
 	QueryInterval util.DurWrap `toml:"query_interval,omitempty"`
+	// PathPolicy is the file containing the path policy in JSON format. If
+	// set, the policy filters and orders the paths of all path replies.
+	PathPolicy string `toml:"path_policy,omitempty"`
+	// HiddenPathGroups are the IDs of the hidden path groups the local AS is
+	// a reader of, e.g. "ff00:0:110-69b5". Hidden segments of these groups are
+	// requested from the hidden path service in the local AS, and are
+	// combined with the public segments.
+	HiddenPathGroups []string `toml:"hidden_path_groups,omitempty"`
 }
 
 func (cfg *SDConfig) InitDefaults() {
@@ -119,9 +128,25 @@ func (cfg *SDConfig) Validate() error {
 	if cfg.QueryInterval.Duration == 0 {
 		return serrors.New("QueryInterval must not be zero")
 	}
+	if _, err := cfg.HiddenPathGroupIds(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// HiddenPathGroupIds returns the parsed IDs of the hidden path groups.
+func (cfg *SDConfig) HiddenPathGroupIds() ([]hiddenpath.GroupId, error) {
+	ids := make([]hiddenpath.GroupId, 0, len(cfg.HiddenPathGroups))
+	for _, raw := range cfg.HiddenPathGroups {
+		var id hiddenpath.GroupId
+		if err := id.UnmarshalText([]byte(raw)); err != nil {
+			return nil, serrors.WrapStr("parsing hidden path group ID", err, "id", raw)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func (cfg *SDConfig) Sample(dst io.Writer, path config.Path, ctx config.CtxMap) {
 	config.WriteString(dst, sdSample)
 }
@@ -20,10 +20,13 @@ import (
 
 	"github.com/pelletier/go-toml"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scionproto/scion/go/lib/env/envtest"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
 	"github.com/scionproto/scion/go/lib/log/logtest"
 	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/xtest"
 	storagetest "github.com/scionproto/scion/go/pkg/storage/test"
 )
 
@@ -38,6 +41,44 @@ func TestConfigSample(t *testing.T) {
 	CheckTestConfig(t, &cfg, idSample)
 }
 
+func TestSDConfigHiddenPathGroupIds(t *testing.T) {
+	tests := map[string]struct {
+		Groups      []string
+		Expected    []hiddenpath.GroupId
+		ExpectedErr bool
+	}{
+		"none": {
+			Expected: []hiddenpath.GroupId{},
+		},
+		"valid": {
+			Groups: []string{"ff00:0:110-69b5", "ff00_0_111-abcd"},
+			Expected: []hiddenpath.GroupId{
+				{OwnerAS: xtest.MustParseAS("ff00:0:110"), Suffix: 0x69b5},
+				{OwnerAS: xtest.MustParseAS("ff00:0:111"), Suffix: 0xabcd},
+			},
+		},
+		"invalid": {
+			Groups:      []string{"ff00:0:110"},
+			ExpectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := SDConfig{HiddenPathGroups: test.Groups}
+			cfg.InitDefaults()
+			ids, err := cfg.HiddenPathGroupIds()
+			if test.ExpectedErr {
+				assert.Error(t, err)
+				assert.Error(t, cfg.Validate())
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, cfg.Validate())
+			assert.Equal(t, test.Expected, ids)
+		})
+	}
+}
+
 func InitTestConfig(cfg *Config) {
 	envtest.InitTest(&cfg.General, &cfg.Metrics, &cfg.Tracing, nil)
 	logtest.InitTestLogging(&cfg.Logging)
@@ -59,4 +100,6 @@ func CheckTestConfig(t *testing.T, cfg *Config, id string) {
 func CheckTestSDConfig(t *testing.T, cfg *SDConfig, id string) {
 	assert.Equal(t, sciond.DefaultSCIONDAddress, cfg.Address)
 	assert.Equal(t, DefaultQueryInterval, cfg.QueryInterval.Duration)
+	assert.Empty(t, cfg.PathPolicy)
+	assert.Empty(t, cfg.HiddenPathGroups)
 }
@@ -22,4 +22,14 @@ address = "127.0.0.1:30255"
 
 # The time after which segments for a destination are refetched. (default 5m)
 query_interval = "5m"
+
+# The file containing the path policy in JSON format. The policy is applied to all
+# path replies, it filters the paths and determines their order. (default "", no
+# policy)
+path_policy = ""
+
+# The hidden path groups the local AS is a reader of, e.g. ["ff00:0:110-69b5"].
+# Hidden segments of these groups are requested from the hidden path service in
+# the local AS. (default [], no hidden paths)
+hidden_path_groups = []
 `
@@ -28,6 +28,7 @@ import (
 	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/infra/modules/combinator"
 	"github.com/scionproto/scion/go/lib/infra/modules/segfetcher"
+	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathdb"
 	"github.com/scionproto/scion/go/lib/revcache"
@@ -57,14 +58,19 @@ type Fetcher interface {
 type fetcher struct {
 	pather segfetcher.Pather
 	config config.SDConfig
+	policy Policy
 }
 
-func NewFetcher(requestAPI segfetcher.RequestAPI, pathDB pathdb.PathDB, inspector trust.Inspector,
+// NewFetcher creates a new fetcher. If policy is not nil, the paths are
+// filtered and ordered according to it before they are returned. If the
+// configuration lists hidden path groups, their hidden segments are combined
+// with the public segments.
+func NewFetcher(requestAPI RequestAPI, pathDB pathdb.PathDB, inspector trust.Inspector,
 	verifier infra.Verifier, revCache revcache.RevCache, cfg config.SDConfig,
-	topoProvider topology.Provider, headerV2 bool) Fetcher {
+	topoProvider topology.Provider, headerV2 bool, policy Policy) Fetcher {
 
 	localIA := topoProvider.Get().IA()
-	return &fetcher{
+	f := &fetcher{
 		pather: segfetcher.Pather{
 			RevCache:     revCache,
 			TopoProvider: topoProvider,
@@ -86,7 +92,22 @@ func NewFetcher(requestAPI segfetcher.RequestAPI, pathDB pathdb.PathDB, inspecto
 			HeaderV2: headerV2,
 		},
 		config: cfg,
+		policy: policy,
 	}
+	// The configuration is validated when it is loaded.
+	groups, err := cfg.HiddenPathGroupIds()
+	if err != nil {
+		log.Error("Ignoring invalid hidden path groups", "err", err)
+	}
+	if len(groups) > 0 {
+		f.pather.Hidden = &hiddenFetcher{
+			groups:       groups,
+			requestAPI:   requestAPI,
+			verifier:     &seghandler.DefaultVerifier{Verifier: verifier},
+			topoProvider: topoProvider,
+		}
+	}
+	return f
 }
 
 // GetPaths fulfills the path request described by req. GetPaths will attempt
@@ -120,6 +141,13 @@ func (f *fetcher) GetPaths(ctx context.Context, req *sciond.PathReq,
 	default:
 		return &sciond.PathReply{ErrorCode: sciond.ErrorInternal}, err
 	}
+	if f.policy != nil {
+		cPaths = Filter(cPaths, f.policy)
+		if len(cPaths) == 0 {
+			return &sciond.PathReply{ErrorCode: sciond.ErrorNoPaths},
+				serrors.New("no paths after applying path policy", "dst", req.Dst.IA())
+		}
+	}
 	var paths []sciond.PathReplyEntry
 	var errs serrors.List
 	for _, path := range cPaths {
@@ -180,7 +208,8 @@ func (f *fetcher) translate(path *combinator.Path) (sciond.PathReplyEntry, error
 			ExpTime:    uint32(path.ComputeExpTime().Unix()),
 			HeaderV2:   path.HeaderV2,
 		},
-		HostInfo: hostinfo.FromUDPAddr(*nextHop),
+		HostInfo:       hostinfo.FromUDPAddr(*nextHop),
+		PathStaticInfo: path.StaticInfoSummary(),
 	}
 	return entry, nil
 }
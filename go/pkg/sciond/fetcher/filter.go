@@ -17,43 +17,53 @@ package fetcher
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/scionproto/scion/go/lib/infra/modules/combinator"
 	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/snet"
 )
 
-// Policy is a filter on path sets.
+// Policy filters and sorts paths.
 type Policy interface {
 	Filter(pathpol.PathSet) pathpol.PathSet
+	Sort([]pathpol.Path)
 }
 
-// Filter filters the given paths with the given policy. Note that this
-// function might change the order of elements.
+// Filter filters the given paths with the given policy, and sorts the
+// remaining paths according to the policy. Paths that are equal with respect
+// to the policy keep their relative order.
 func Filter(paths []*combinator.Path, policy Policy) []*combinator.Path {
-	return psToPaths(policy.Filter(pathsToPs(paths)))
-}
-
-func pathsToPs(paths []*combinator.Path) pathpol.PathSet {
-	ps := make(pathpol.PathSet, len(paths))
+	wrapped := make([]pathWrap, 0, len(paths))
 	for _, path := range paths {
-		wp := newPathWrap(path)
+		wrapped = append(wrapped, newPathWrap(path))
+	}
+	ps := make(pathpol.PathSet, len(wrapped))
+	for _, wp := range wrapped {
 		ps[wp.key] = wp
 	}
-	return ps
-}
-
-func psToPaths(ps pathpol.PathSet) []*combinator.Path {
-	paths := make([]*combinator.Path, 0, len(ps))
-	for _, wp := range ps {
-		paths = append(paths, wp.(pathWrap).origPath)
+	filtered := policy.Filter(ps)
+	// Restore the order of the input, so that the sort only changes the order
+	// as required by the policy.
+	sorted := make([]pathpol.Path, 0, len(filtered))
+	for _, wp := range wrapped {
+		if _, ok := filtered[wp.key]; ok {
+			sorted = append(sorted, wp)
+			delete(filtered, wp.key)
+		}
 	}
-	return paths
+	policy.Sort(sorted)
+	res := make([]*combinator.Path, 0, len(sorted))
+	for _, wp := range sorted {
+		res = append(res, wp.(pathWrap).origPath)
+	}
+	return res
 }
 
 type pathWrap struct {
 	key      snet.PathFingerprint
 	intfs    []snet.PathInterface
+	metadata pathMetadata
 	origPath *combinator.Path
 }
 
@@ -65,10 +75,26 @@ func newPathWrap(p *combinator.Path) pathWrap {
 		keyParts = append(keyParts, fmt.Sprintf("%s#%d", intf.IA(), intf.ID()))
 	}
 	return pathWrap{
-		key:      snet.PathFingerprint(strings.Join(keyParts, " ")),
-		intfs:    intfs,
+		key:   snet.PathFingerprint(strings.Join(keyParts, " ")),
+		intfs: intfs,
+		metadata: pathMetadata{
+			mtu:        p.Mtu,
+			expiry:     p.ComputeExpTime(),
+			staticInfo: p.StaticInfoSummary().StaticInfo(),
+		},
 		origPath: p,
 	}
 }
 
 func (p pathWrap) Interfaces() []snet.PathInterface { return p.intfs }
+func (p pathWrap) Metadata() snet.PathMetadata      { return p.metadata }
+
+type pathMetadata struct {
+	mtu        uint16
+	expiry     time.Time
+	staticInfo *snet.PathStaticInfo
+}
+
+func (m pathMetadata) MTU() uint16                      { return m.mtu }
+func (m pathMetadata) Expiry() time.Time                { return m.expiry }
+func (m pathMetadata) StaticInfo() *snet.PathStaticInfo { return m.staticInfo }
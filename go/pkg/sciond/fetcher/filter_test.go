@@ -65,6 +65,7 @@ func TestFilter(t *testing.T) {
 						}
 						return paths
 					})
+				pol.EXPECT().Sort(gomock.Any())
 				return pol
 			},
 			ExpectedPaths: combinator.Combine(ia111, ia110,
@@ -81,4 +82,20 @@ func TestFilter(t *testing.T) {
 			assert.ElementsMatch(t, test.ExpectedPaths, filtered)
 		})
 	}
+	t.Run("order of the policy", func(t *testing.T) {
+		ia120 := xtest.MustParseIA("1-ff00:0:120")
+		paths111To120 := combinator.Combine(ia111, ia120,
+			[]*seg.PathSegment{seg120To111}, nil, nil)
+		paths := append(append([]*combinator.Path{}, paths111To110...), paths111To120...)
+		policy := &pathpol.Policy{
+			Order: pathpol.Order{{Metric: pathpol.OrderHops}},
+		}
+		filtered := fetcher.Filter(paths, policy)
+		expected := append(append([]*combinator.Path{}, paths111To120...), paths111To110...)
+		assert.Equal(t, expected, filtered)
+	})
+	t.Run("default order is kept", func(t *testing.T) {
+		filtered := fetcher.Filter(paths111To110, &pathpol.Policy{})
+		assert.Equal(t, paths111To110, filtered)
+	})
 }
@@ -0,0 +1,191 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetcher
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
+	"github.com/scionproto/scion/go/lib/infra/messenger"
+	"github.com/scionproto/scion/go/lib/infra/modules/segfetcher"
+	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// HiddenRequestAPI is the API to request hidden path segments.
+type HiddenRequestAPI interface {
+	GetHPSegs(ctx context.Context, msg *path_mgmt.HPSegReq, a net.Addr,
+		id uint64) (*path_mgmt.HPSegReply, error)
+}
+
+// RequestAPI is the API to request public and hidden path segments.
+type RequestAPI interface {
+	segfetcher.RequestAPI
+	HiddenRequestAPI
+}
+
+// hiddenCacheTTL is the maximum time the hidden segments to a destination are
+// cached. It bounds the time until newly registered hidden segments are used.
+const hiddenCacheTTL = time.Minute
+
+var _ segfetcher.HiddenFetcher = (*hiddenFetcher)(nil)
+
+// hiddenFetcher requests the hidden segments of the configured groups from the
+// hidden path service in the local AS. The service looks up the segments at
+// the registries of the groups. The verified segments are cached per
+// destination until the first of them expires, or at most for hiddenCacheTTL.
+type hiddenFetcher struct {
+	groups       []hiddenpath.GroupId
+	requestAPI   HiddenRequestAPI
+	verifier     seghandler.Verifier
+	topoProvider topology.Provider
+
+	mtx   sync.Mutex
+	cache map[addr.IA]hiddenCacheEntry
+}
+
+// hiddenCacheEntry holds the cached hidden segments to a destination.
+type hiddenCacheEntry struct {
+	segs    segfetcher.Segments
+	expires time.Time
+}
+
+// FetchHidden returns the verified hidden down segments that end at the
+// destination. Segments of groups that fail, or that fail to verify, are
+// ignored. The segments are only cached if no group failed.
+func (f *hiddenFetcher) FetchHidden(ctx context.Context,
+	dst addr.IA) (segfetcher.Segments, error) {
+
+	if segs, ok := f.cached(dst); ok {
+		return segs, nil
+	}
+	segs, complete, err := f.fetch(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+	if complete {
+		f.store(dst, segs)
+	}
+	return segs, nil
+}
+
+// fetch requests the hidden segments from the hidden path service. The
+// returned flag indicates whether the segments of all groups were obtained.
+func (f *hiddenFetcher) fetch(ctx context.Context,
+	dst addr.IA) (segfetcher.Segments, bool, error) {
+
+	server, err := f.topoProvider.Get().Anycast(addr.SvcHPS)
+	if err != nil {
+		return nil, false, serrors.WrapStr("resolving hidden path service", err)
+	}
+	req := &path_mgmt.HPSegReq{
+		RawDstIA: dst.IAInt(),
+		GroupIds: make([]*path_mgmt.HPGroupId, 0, len(f.groups)),
+	}
+	for _, id := range f.groups {
+		req.GroupIds = append(req.GroupIds, id.ToMsg())
+	}
+	reply, err := f.requestAPI.GetHPSegs(ctx, req, server, messenger.NextId())
+	if err != nil {
+		return nil, false, serrors.WrapStr("requesting hidden segments", err,
+			"server", server)
+	}
+	logger := log.FromCtx(ctx)
+	var segs segfetcher.Segments
+	complete := len(reply.Recs) == len(f.groups)
+	for _, recs := range reply.Recs {
+		id := hiddenpath.IdFromMsg(recs.GroupId)
+		if recs.Err != "" {
+			logger.Info("Hidden path registry returned error", "group", id, "err", recs.Err)
+			complete = false
+			continue
+		}
+		verified, errs := f.verify(ctx, id, recs, server)
+		if len(errs) > 0 {
+			logger.Info("Failed to verify hidden segments", "group", id,
+				"errs", errs.ToError())
+		}
+		segs = append(segs, verified...)
+	}
+	return segs, complete, nil
+}
+
+// cached returns the cached segments to the destination, if they are not
+// expired.
+func (f *hiddenFetcher) cached(dst addr.IA) (segfetcher.Segments, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	entry, ok := f.cache[dst]
+	if !ok || !time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.segs, true
+}
+
+// store caches the segments to the destination until the first of them
+// expires, or at most for hiddenCacheTTL. Expired entries are removed.
+func (f *hiddenFetcher) store(dst addr.IA, segs segfetcher.Segments) {
+	now := time.Now()
+	expires := now.Add(hiddenCacheTTL)
+	for _, meta := range segs {
+		if exp := meta.Segment.MinExpiry(); exp.Before(expires) {
+			expires = exp
+		}
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.cache == nil {
+		f.cache = make(map[addr.IA]hiddenCacheEntry)
+	}
+	for ia, entry := range f.cache {
+		if !now.Before(entry.expires) {
+			delete(f.cache, ia)
+		}
+	}
+	f.cache[dst] = hiddenCacheEntry{segs: segs, expires: expires}
+}
+
+// verify returns the down segments of recs that could be verified.
+func (f *hiddenFetcher) verify(ctx context.Context, id hiddenpath.GroupId,
+	recs *path_mgmt.HPSegRecs, server net.Addr) (segfetcher.Segments, serrors.List) {
+
+	var downs segfetcher.Segments
+	for _, meta := range recs.Recs {
+		if meta.Type == proto.PathSegType_down {
+			downs = append(downs, meta)
+		}
+	}
+	results, units := f.verifier.Verify(ctx, seghandler.Segments{Segs: downs, HPGroupID: id},
+		server)
+	var verified segfetcher.Segments
+	var errs serrors.List
+	for i := 0; i < units; i++ {
+		res := <-results
+		if err := res.SegError(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		verified = append(verified, res.Unit.SegMeta)
+	}
+	return verified, errs
+}
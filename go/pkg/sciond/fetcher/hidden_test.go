@@ -0,0 +1,247 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
+	"github.com/scionproto/scion/go/lib/ctrl/seg"
+	"github.com/scionproto/scion/go/lib/hiddenpath"
+	"github.com/scionproto/scion/go/lib/infra/modules/itopo/itopotest"
+	"github.com/scionproto/scion/go/lib/infra/modules/segfetcher"
+	"github.com/scionproto/scion/go/lib/infra/modules/seghandler"
+	"github.com/scionproto/scion/go/lib/infra/modules/seghandler/mock_seghandler"
+	"github.com/scionproto/scion/go/lib/infra/modules/segverifier"
+	"github.com/scionproto/scion/go/lib/topology"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/lib/xtest/graph"
+	"github.com/scionproto/scion/go/pkg/sciond/fetcher/mock_fetcher"
+	"github.com/scionproto/scion/go/proto"
+)
+
+func TestHiddenFetcherFetchHidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	g := graph.NewDefaultGraph(ctrl)
+
+	dst := xtest.MustParseIA("1-ff00:0:112")
+	group1 := hiddenpath.GroupId{OwnerAS: xtest.MustParseAS("ff00:0:110"), Suffix: 1}
+	group2 := hiddenpath.GroupId{OwnerAS: xtest.MustParseAS("ff00:0:110"), Suffix: 2}
+	down := &seg.Meta{
+		Type:    proto.PathSegType_down,
+		Segment: g.Beacon([]common.IFIDType{graph.If_110_X_130_A, graph.If_130_A_112_X}),
+	}
+	invalid := &seg.Meta{
+		Type:    proto.PathSegType_down,
+		Segment: g.Beacon([]common.IFIDType{graph.If_120_X_111_B, graph.If_111_A_112_X}),
+	}
+	up := &seg.Meta{
+		Type:    proto.PathSegType_up,
+		Segment: g.Beacon([]common.IFIDType{graph.If_130_B_111_A}),
+	}
+	server := &net.UDPAddr{IP: net.IP{127, 0, 0, 83}, Port: 30254}
+	topo := topology.NewRWTopology()
+	topo.HPS = topology.IDAddrMap{"hps1": topology.TopoAddr{SCIONAddress: server}}
+	expectedReq := &path_mgmt.HPSegReq{
+		RawDstIA: dst.IAInt(),
+		GroupIds: []*path_mgmt.HPGroupId{group1.ToMsg(), group2.ToMsg()},
+	}
+
+	tests := map[string]struct {
+		Reply       *path_mgmt.HPSegReply
+		ReplyErr    error
+		Verified    map[*seg.Meta]error
+		Expected    segfetcher.Segments
+		ExpectedErr bool
+	}{
+		"verified down segments": {
+			Reply: &path_mgmt.HPSegReply{
+				Recs: []*path_mgmt.HPSegRecs{
+					{GroupId: group1.ToMsg(), Recs: []*seg.Meta{down, up, invalid}},
+					{GroupId: group2.ToMsg(), Err: "no registry reachable"},
+				},
+			},
+			Verified: map[*seg.Meta]error{
+				down:    nil,
+				invalid: errors.New("invalid signature"),
+			},
+			Expected: segfetcher.Segments{down},
+		},
+		"request fails": {
+			ReplyErr:    errors.New("connection refused"),
+			ExpectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			api := mock_fetcher.NewMockHiddenRequestAPI(ctrl)
+			verifier := mock_seghandler.NewMockVerifier(ctrl)
+			api.EXPECT().GetHPSegs(gomock.Any(), expectedReq, server, gomock.Any()).
+				Return(test.Reply, test.ReplyErr)
+			if test.Verified != nil {
+				verifier.EXPECT().Verify(gomock.Any(), gomock.Any(), server).DoAndReturn(
+					func(_ context.Context, recs seghandler.Segments,
+						_ net.Addr) (chan segverifier.UnitResult, int) {
+
+						assert.Equal(t, group1, recs.HPGroupID)
+						res := make(chan segverifier.UnitResult, len(recs.Segs))
+						for _, meta := range recs.Segs {
+							errs := map[int]error{}
+							if err := test.Verified[meta]; err != nil {
+								errs[-1] = err
+							}
+							res <- segverifier.UnitResult{
+								Unit:   &segverifier.Unit{SegMeta: meta},
+								Errors: errs,
+							}
+						}
+						return res, len(recs.Segs)
+					},
+				)
+			}
+			f := &hiddenFetcher{
+				groups:       []hiddenpath.GroupId{group1, group2},
+				requestAPI:   api,
+				verifier:     verifier,
+				topoProvider: &itopotest.TestTopoProvider{RWTopology: topo},
+			}
+			segs, err := f.FetchHidden(context.Background(), dst)
+			if test.ExpectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, segs)
+		})
+	}
+}
+
+func TestHiddenFetcherCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	g := graph.NewDefaultGraph(ctrl)
+
+	dst := xtest.MustParseIA("1-ff00:0:112")
+	other := xtest.MustParseIA("1-ff00:0:111")
+	group := hiddenpath.GroupId{OwnerAS: xtest.MustParseAS("ff00:0:110"), Suffix: 1}
+	down := &seg.Meta{
+		Type:    proto.PathSegType_down,
+		Segment: g.Beacon([]common.IFIDType{graph.If_110_X_130_A, graph.If_130_A_112_X}),
+	}
+	server := &net.UDPAddr{IP: net.IP{127, 0, 0, 83}, Port: 30254}
+	topo := topology.NewRWTopology()
+	topo.HPS = topology.IDAddrMap{"hps1": topology.TopoAddr{SCIONAddress: server}}
+	ok := &path_mgmt.HPSegReply{
+		Recs: []*path_mgmt.HPSegRecs{{GroupId: group.ToMsg(), Recs: []*seg.Meta{down}}},
+	}
+	failed := &path_mgmt.HPSegReply{
+		Recs: []*path_mgmt.HPSegRecs{{GroupId: group.ToMsg(), Err: "no registry reachable"}},
+	}
+
+	newFetcher := func(ctrl *gomock.Controller, api HiddenRequestAPI) *hiddenFetcher {
+		verifier := mock_seghandler.NewMockVerifier(ctrl)
+		verifier.EXPECT().Verify(gomock.Any(), gomock.Any(), server).DoAndReturn(
+			func(_ context.Context, recs seghandler.Segments,
+				_ net.Addr) (chan segverifier.UnitResult, int) {
+
+				res := make(chan segverifier.UnitResult, len(recs.Segs))
+				for _, meta := range recs.Segs {
+					res <- segverifier.UnitResult{Unit: &segverifier.Unit{SegMeta: meta}}
+				}
+				return res, len(recs.Segs)
+			},
+		).AnyTimes()
+		return &hiddenFetcher{
+			groups:       []hiddenpath.GroupId{group},
+			requestAPI:   api,
+			verifier:     verifier,
+			topoProvider: &itopotest.TestTopoProvider{RWTopology: topo},
+		}
+	}
+
+	t.Run("cached per destination", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		api := mock_fetcher.NewMockHiddenRequestAPI(ctrl)
+		f := newFetcher(ctrl, api)
+		api.EXPECT().GetHPSegs(gomock.Any(), reqFor(dst, group), server, gomock.Any()).
+			Return(ok, nil)
+		api.EXPECT().GetHPSegs(gomock.Any(), reqFor(other, group), server, gomock.Any()).
+			Return(ok, nil)
+		for i := 0; i < 2; i++ {
+			segs, err := f.FetchHidden(context.Background(), dst)
+			require.NoError(t, err)
+			assert.Equal(t, segfetcher.Segments{down}, segs)
+		}
+		_, err := f.FetchHidden(context.Background(), other)
+		require.NoError(t, err)
+		// The segment expires after the cache TTL.
+		assert.WithinDuration(t, time.Now().Add(hiddenCacheTTL), f.cache[dst].expires,
+			time.Second)
+	})
+	t.Run("expired entry is fetched again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		api := mock_fetcher.NewMockHiddenRequestAPI(ctrl)
+		f := newFetcher(ctrl, api)
+		api.EXPECT().GetHPSegs(gomock.Any(), reqFor(dst, group), server, gomock.Any()).
+			Return(ok, nil).Times(2)
+		_, err := f.FetchHidden(context.Background(), dst)
+		require.NoError(t, err)
+		f.cache[dst] = hiddenCacheEntry{segs: f.cache[dst].segs, expires: time.Now()}
+		segs, err := f.FetchHidden(context.Background(), dst)
+		require.NoError(t, err)
+		assert.Equal(t, segfetcher.Segments{down}, segs)
+	})
+	t.Run("failed groups are not cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		api := mock_fetcher.NewMockHiddenRequestAPI(ctrl)
+		f := newFetcher(ctrl, api)
+		gomock.InOrder(
+			api.EXPECT().GetHPSegs(gomock.Any(), reqFor(dst, group), server, gomock.Any()).
+				Return(failed, nil),
+			api.EXPECT().GetHPSegs(gomock.Any(), reqFor(dst, group), server, gomock.Any()).
+				Return(ok, nil),
+		)
+		segs, err := f.FetchHidden(context.Background(), dst)
+		require.NoError(t, err)
+		assert.Empty(t, segs)
+		segs, err = f.FetchHidden(context.Background(), dst)
+		require.NoError(t, err)
+		assert.Equal(t, segfetcher.Segments{down}, segs)
+	})
+}
+
+func reqFor(dst addr.IA, groups ...hiddenpath.GroupId) *path_mgmt.HPSegReq {
+	req := &path_mgmt.HPSegReq{RawDstIA: dst.IAInt()}
+	for _, id := range groups {
+		req.GroupIds = append(req.GroupIds, id.ToMsg())
+	}
+	return req
+}
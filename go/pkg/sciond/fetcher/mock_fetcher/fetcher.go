@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/scionproto/scion/go/pkg/sciond/fetcher (interfaces: Fetcher,Policy)
+// Source: github.com/scionproto/scion/go/pkg/sciond/fetcher (interfaces: Fetcher,HiddenRequestAPI,Policy)
 
 // Package mock_fetcher is a generated GoMock package.
 package mock_fetcher
@@ -7,8 +7,10 @@ package mock_fetcher
 import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
+	path_mgmt "github.com/scionproto/scion/go/lib/ctrl/path_mgmt"
 	pathpol "github.com/scionproto/scion/go/lib/pathpol"
 	sciond "github.com/scionproto/scion/go/lib/sciond"
+	net "net"
 	reflect "reflect"
 	time "time"
 )
@@ -51,6 +53,44 @@ func (mr *MockFetcherMockRecorder) GetPaths(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaths", reflect.TypeOf((*MockFetcher)(nil).GetPaths), arg0, arg1, arg2)
 }
 
+// MockHiddenRequestAPI is a mock of HiddenRequestAPI interface
+type MockHiddenRequestAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockHiddenRequestAPIMockRecorder
+}
+
+// MockHiddenRequestAPIMockRecorder is the mock recorder for MockHiddenRequestAPI
+type MockHiddenRequestAPIMockRecorder struct {
+	mock *MockHiddenRequestAPI
+}
+
+// NewMockHiddenRequestAPI creates a new mock instance
+func NewMockHiddenRequestAPI(ctrl *gomock.Controller) *MockHiddenRequestAPI {
+	mock := &MockHiddenRequestAPI{ctrl: ctrl}
+	mock.recorder = &MockHiddenRequestAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHiddenRequestAPI) EXPECT() *MockHiddenRequestAPIMockRecorder {
+	return m.recorder
+}
+
+// GetHPSegs mocks base method
+func (m *MockHiddenRequestAPI) GetHPSegs(arg0 context.Context, arg1 *path_mgmt.HPSegReq, arg2 net.Addr, arg3 uint64) (*path_mgmt.HPSegReply, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHPSegs", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*path_mgmt.HPSegReply)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHPSegs indicates an expected call of GetHPSegs
+func (mr *MockHiddenRequestAPIMockRecorder) GetHPSegs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHPSegs", reflect.TypeOf((*MockHiddenRequestAPI)(nil).GetHPSegs), arg0, arg1, arg2, arg3)
+}
+
 // MockPolicy is a mock of Policy interface
 type MockPolicy struct {
 	ctrl     *gomock.Controller
@@ -87,3 +127,15 @@ func (mr *MockPolicyMockRecorder) Filter(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Filter", reflect.TypeOf((*MockPolicy)(nil).Filter), arg0)
 }
+
+// Sort mocks base method
+func (m *MockPolicy) Sort(arg0 []pathpol.Path) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Sort", arg0)
+}
+
+// Sort indicates an expected call of Sort
+func (mr *MockPolicyMockRecorder) Sort(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sort", reflect.TypeOf((*MockPolicy)(nil).Sort), arg0)
+}
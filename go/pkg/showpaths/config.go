@@ -16,6 +16,8 @@ package showpaths
 
 import (
 	"net"
+
+	"github.com/scionproto/scion/go/lib/pathpol"
 )
 
 // DefaultMaxPaths is the maximum number of paths that are displayed by default.
@@ -35,4 +37,10 @@ type Config struct {
 	Refresh bool
 	// NoProbe configures whether the path status is probed or not.
 	NoProbe bool
+	// Extended configures whether the paths are probed with SCMP echo
+	// requests, which also measures the round trip time of each path.
+	Extended bool
+	// Policy restricts the displayed paths. If it is nil, all paths are
+	// displayed.
+	Policy *pathpol.Policy
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/pkg/app"
 )
 
 // Result contains all the discovered paths.
@@ -49,6 +50,69 @@ type Path struct {
 	Status      string    `json:"status,omitempty"`
 	StatusInfo  string    `json:"status_info,omitempty"`
 	Local       net.IP    `json:"local_ip,omitempty"`
+	// RTT is the round trip time in milliseconds measured in the extended
+	// probe mode.
+	RTT float64 `json:"rtt_ms,omitempty"`
+	// StaticInfo is the static information of the path. It is nil if the
+	// information is not available.
+	StaticInfo *StaticInfo `json:"static_info,omitempty"`
+}
+
+// StaticInfo is the static information announced by the ASes on the path.
+type StaticInfo struct {
+	// Latency is the total latency of the path in milliseconds. Zero means
+	// unknown.
+	Latency float64 `json:"latency_ms"`
+	// Bandwidth is the bottleneck bandwidth of the path in Kbit/s. Zero means
+	// unknown.
+	Bandwidth uint64     `json:"bandwidth_kbps"`
+	LinkTypes []LinkType `json:"link_types"`
+	Geo       []Geo      `json:"geo"`
+}
+
+// LinkType is the type of the inter-AS link of an AS on the path.
+type LinkType struct {
+	IA   addr.IA `json:"isd_as"`
+	Type string  `json:"type"`
+}
+
+// Geo holds the locations of the border routers of an AS on the path.
+type Geo struct {
+	IA        addr.IA    `json:"isd_as"`
+	Locations []Location `json:"locations"`
+}
+
+// Location is the location of a border router.
+type Location struct {
+	Latitude  float32 `json:"latitude"`
+	Longitude float32 `json:"longitude"`
+	Address   string  `json:"address,omitempty"`
+}
+
+func (s *StaticInfo) String() string {
+	latency, bandwidth := "unknown", "unknown"
+	if s.Latency > 0 {
+		latency = fmt.Sprintf("%.3fms", s.Latency)
+	}
+	if s.Bandwidth > 0 {
+		bandwidth = fmt.Sprintf("%dKbit/s", s.Bandwidth)
+	}
+	linkTypes := make([]string, 0, len(s.LinkTypes))
+	for _, lt := range s.LinkTypes {
+		linkTypes = append(linkTypes, fmt.Sprintf("%s %s", lt.IA, lt.Type))
+	}
+	var geo []string
+	for _, g := range s.Geo {
+		for _, loc := range g.Locations {
+			l := fmt.Sprintf("%s %.4f,%.4f", g.IA, loc.Latitude, loc.Longitude)
+			if loc.Address != "" {
+				l += fmt.Sprintf(" (%s)", loc.Address)
+			}
+			geo = append(geo, l)
+		}
+	}
+	return fmt.Sprintf("Latency: %s Bandwidth: %s LinkTypes: [%s] Geo: [%s]", latency,
+		bandwidth, strings.Join(linkTypes, ", "), strings.Join(geo, ", "))
 }
 
 // Hop represents an hop on the path.
@@ -57,8 +121,9 @@ type Hop struct {
 	IA   addr.IA         `json:"isd_as"`
 }
 
-// Human writes human readable output to the writer.
-func (r Result) Human(w io.Writer, showExpiration bool) {
+// Human writes human readable output to the writer. If showStaticInfo is set,
+// the static information of the paths is displayed.
+func (r Result) Human(w io.Writer, showExpiration, showStaticInfo bool) {
 	fmt.Fprintln(w, "Available paths to", r.Destination)
 	for i, path := range r.Paths {
 		fmt.Fprintf(w, "[%2d] %s", i, fmt.Sprintf("%s", path.FullPath))
@@ -66,8 +131,15 @@ func (r Result) Human(w io.Writer, showExpiration bool) {
 			ttl := time.Until(path.Expiry).Truncate(time.Second)
 			fmt.Fprintf(w, " Expires: %s (%s)", path.Expiry, ttl)
 		}
+		if showStaticInfo && path.StaticInfo != nil {
+			fmt.Fprintf(w, " %s", path.StaticInfo)
+		}
 		if path.Status != "" {
-			fmt.Fprintf(w, " Status: %s LocalIP: %s", path.Status, path.Local)
+			fmt.Fprintf(w, " Status: %s", path.Status)
+			if path.RTT > 0 {
+				fmt.Fprintf(w, " RTT: %.3fms", path.RTT)
+			}
+			fmt.Fprintf(w, " LocalIP: %s", path.Local)
 		}
 		fmt.Fprintln(w)
 	}
@@ -95,13 +167,26 @@ func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 	// TODO(lukedirtwalker): Replace this with snet.Router once we have the
 	// possibility to have the same functionality, i.e. refresh, fetch all paths.
 	// https://github.com/scionproto/scion/issues/3348
+	// With a policy, all paths are requested, such that the paths that are
+	// allowed by the policy are not cut off by SCIOND before filtering.
+	pathCount := uint16(cfg.MaxPaths)
+	if cfg.Policy != nil {
+		pathCount = 0
+	}
 	paths, err := sdConn.Paths(ctx, dst, addr.IA{},
-		sciond.PathReqFlags{Refresh: cfg.Refresh, PathCount: uint16(cfg.MaxPaths)})
+		sciond.PathReqFlags{Refresh: cfg.Refresh, PathCount: pathCount})
 	if err != nil {
 		return nil, serrors.WrapStr("failed to retrieve paths from SCIOND", err)
 	}
+	if cfg.Policy != nil {
+		paths = app.FilterPaths(paths, cfg.Policy)
+		if cfg.MaxPaths > 0 && len(paths) > cfg.MaxPaths {
+			paths = paths[:cfg.MaxPaths]
+		}
+	}
 
 	var statuses map[string]pathprobe.Status
+	var rtts map[string]time.Duration
 	var localIP net.IP
 	if !cfg.NoProbe {
 		// Resolve local IP in case it is not configured.
@@ -112,15 +197,31 @@ func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 			}
 		}
 		paths := pathprobe.FilterEmptyPaths(paths)
-		statuses, err = pathprobe.Prober{
+		prober := pathprobe.Prober{
 			DstIA:   dst,
 			LocalIA: localIA,
 			LocalIP: localIP,
 			// TODO(scrye): set this when we have CLI support for features
 			Version2: false,
-		}.GetStatuses(ctx, paths)
-		if err != nil {
-			serrors.WrapStr("failed to get status", err)
+		}
+		if cfg.Extended {
+			rtts, err = prober.GetRTTs(ctx, paths)
+			if err != nil {
+				return nil, serrors.WrapStr("failed to measure round trip times", err)
+			}
+			statuses = make(map[string]pathprobe.Status, len(paths))
+			for _, path := range paths {
+				key := pathprobe.PathKey(path)
+				statuses[key] = pathprobe.Status{Status: pathprobe.StatusTimeout}
+				if _, ok := rtts[key]; ok {
+					statuses[key] = pathprobe.Status{Status: pathprobe.StatusAlive}
+				}
+			}
+		} else {
+			statuses, err = prober.GetStatuses(ctx, paths)
+			if err != nil {
+				serrors.WrapStr("failed to get status", err)
+			}
 		}
 	}
 
@@ -151,11 +252,45 @@ func Run(ctx context.Context, dst addr.IA, cfg Config) (*Result, error) {
 			rpath.Status = strings.ToLower(string(status.Status))
 			rpath.StatusInfo = status.AdditionalInfo
 		}
+		if rtt, ok := rtts[pathprobe.PathKey(path)]; ok {
+			rpath.RTT = millis(rtt)
+		}
+		if info := path.Metadata().StaticInfo(); info != nil {
+			rpath.StaticInfo = newStaticInfo(info)
+		}
 		res.Paths = append(res.Paths, rpath)
 	}
 	return res, nil
 }
 
+func newStaticInfo(info *snet.PathStaticInfo) *StaticInfo {
+	s := &StaticInfo{
+		Latency:   millis(info.Latency),
+		Bandwidth: info.Bandwidth,
+		LinkTypes: []LinkType{},
+		Geo:       []Geo{},
+	}
+	for _, lt := range info.LinkTypes {
+		s.LinkTypes = append(s.LinkTypes, LinkType{IA: lt.IA, Type: lt.Inter.String()})
+	}
+	for _, g := range info.Geo {
+		geo := Geo{IA: g.IA, Locations: []Location{}}
+		for _, loc := range g.Locations {
+			geo.Locations = append(geo.Locations, Location{
+				Latitude:  loc.Latitude,
+				Longitude: loc.Longitude,
+				Address:   loc.Address,
+			})
+		}
+		s.Geo = append(s.Geo, geo)
+	}
+	return s
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
 // TODO(matzf): this is a simple, hopefully temporary, workaround to not having
 // wildcard addresses in snet.
 // Here we just use a seemingly sensible default IP, but in the general case
@@ -24,6 +24,7 @@ import (
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/pkg/storage"
 )
 
 const (
@@ -31,6 +32,11 @@ const (
 	DefaultEncapPort   = 30056
 	DefaultTunName     = "sig"
 	DefaultTunRTableId = 11
+
+	// EncryptionAESGCM protects the frames with AES-256-GCM.
+	EncryptionAESGCM = "aes-gcm"
+	// EncryptionChaCha20Poly1305 protects the frames with ChaCha20-Poly1305.
+	EncryptionChaCha20Poly1305 = "chacha20-poly1305"
 )
 
 type Config struct {
@@ -39,6 +45,9 @@ type Config struct {
 	Metrics  env.Metrics      `toml:"metrics,omitempty"`
 	Sciond   env.SCIONDClient `toml:"sciond_connection,omitempty"`
 	Sig      SigConf          `toml:"sig,omitempty"`
+	// TrustDB is the trust database that holds the certificates that are used
+	// to authenticate remote SIGs. It is only used if encryption is enabled.
+	TrustDB storage.DBConfig `toml:"trust_db,omitempty"`
 }
 
 func (cfg *Config) InitDefaults() {
@@ -48,6 +57,7 @@ func (cfg *Config) InitDefaults() {
 		&cfg.Metrics,
 		&cfg.Sciond,
 		&cfg.Sig,
+		&cfg.TrustDB,
 	)
 }
 
@@ -58,6 +68,7 @@ func (cfg *Config) Validate() error {
 		&cfg.Metrics,
 		&cfg.Sciond,
 		&cfg.Sig,
+		&cfg.TrustDB,
 	)
 }
 
@@ -68,6 +79,13 @@ func (cfg *Config) Sample(dst io.Writer, path config.Path, _ config.CtxMap) {
 		&cfg.Metrics,
 		&cfg.Sciond,
 		&cfg.Sig,
+		config.OverrideName(
+			config.FormatData(
+				&cfg.TrustDB,
+				storage.SetID(storage.SampleTrustDB, idSample).Connection,
+			),
+			"trust_db",
+		),
 	)
 }
 
@@ -99,6 +117,25 @@ type SigConf struct {
 	// dispatcher. If the field is empty bypass is not done and SCION dispatcher is used
 	// instead.
 	DispatcherBypass string `toml:"disaptcher_bypass,omitempty"`
+	// ReorderWindow is the number of frames the ingress reorders per session.
+	// It must be set if remote SIGs stripe frames over several paths. If it
+	// is 0, frames are not reordered.
+	ReorderWindow int `toml:"reorder_window,omitempty"`
+	// Encryption is the AEAD that protects the frames, either "aes-gcm" or
+	// "chacha20-poly1305". The frame keys are negotiated with the remote SIGs,
+	// which must be configured with the same AEAD. If it is empty, frames are
+	// sent in the clear.
+	Encryption string `toml:"encryption,omitempty"`
+	// ConfigDir is the directory that holds the AS key and certificate chain
+	// (in crypto/as), as well as the TRCs and the certificate chains of the
+	// remote ASes (in certs) that are used to authenticate the key exchange
+	// with the remote SIGs. It is required if encryption is enabled.
+	ConfigDir string `toml:"config_dir,omitempty"`
+	// SCMPAuth requires SCMP errors to be authenticated with DRKey by the AS
+	// that sent them. The keys are fetched from the control service of the
+	// local AS. SCMP errors that fail verification, including revocations,
+	// are dropped.
+	SCMPAuth bool `toml:"scmp_auth,omitempty"`
 }
 
 // InitDefaults sets the default values to unset values.
@@ -122,6 +159,17 @@ func (cfg *SigConf) Validate() error {
 	if cfg.IP.IsUnspecified() {
 		return serrors.New("ip must be set")
 	}
+	if cfg.ReorderWindow < 0 {
+		return serrors.New("reorder_window must not be negative")
+	}
+	switch cfg.Encryption {
+	case "", EncryptionAESGCM, EncryptionChaCha20Poly1305:
+	default:
+		return serrors.New("unsupported encryption", "encryption", cfg.Encryption)
+	}
+	if cfg.Encryption != "" && cfg.ConfigDir == "" {
+		return serrors.New("config_dir must be set if encryption is enabled")
+	}
 	if cfg.CtrlPort == 0 {
 		cfg.CtrlPort = DefaultCtrlPort
 	}
@@ -25,6 +25,7 @@ import (
 	"github.com/scionproto/scion/go/lib/log/logtest"
 	"github.com/scionproto/scion/go/pkg/sig/config"
 	"github.com/scionproto/scion/go/pkg/sig/config/configtest"
+	storagetest "github.com/scionproto/scion/go/pkg/storage/test"
 )
 
 func TestConfigSample(t *testing.T) {
@@ -47,4 +48,5 @@ func CheckTestConfig(t *testing.T, cfg *config.Config, id string) {
 	envtest.CheckTest(t, nil, &cfg.Metrics, nil, &cfg.Sciond, id)
 	logtest.CheckTestLogging(t, &cfg.Logging, id)
 	configtest.CheckTestSIG(t, &cfg.Sig, id)
+	storagetest.CheckTestTrustDBConfig(t, &cfg.TrustDB, id)
 }
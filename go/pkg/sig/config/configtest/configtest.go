@@ -33,4 +33,8 @@ func CheckTestSIG(t *testing.T, cfg *config.SigConf, id string) {
 	assert.Equal(t, config.DefaultEncapPort, int(cfg.EncapPort))
 	assert.Equal(t, config.DefaultTunName, cfg.Tun)
 	assert.Equal(t, config.DefaultTunRTableId, cfg.TunRTableId)
+	assert.Equal(t, 0, cfg.ReorderWindow)
+	assert.Equal(t, "", cfg.Encryption)
+	assert.Equal(t, "/etc/scion", cfg.ConfigDir)
+	assert.False(t, cfg.SCMPAuth)
 }
@@ -40,4 +40,20 @@ tun = "sig"
 
 # Id of the routing table. (default 11)
 tun_routing_table_id = 11
+
+# Number of frames that are reordered per session. Must be set if remote SIGs
+# stripe frames over several paths. (default 0, no reordering)
+reorder_window = 0
+
+# AEAD that protects the frames, either "aes-gcm" or "chacha20-poly1305". The
+# remote SIGs must use the same AEAD. (default "", frames are not protected)
+encryption = ""
+
+# Directory with the AS key and certificate chain (crypto/as), and the TRCs and
+# certificate chains of the remote ASes (certs). Required if encryption is set.
+config_dir = "/etc/scion"
+
+# Drop SCMP errors that are not authenticated with DRKey by the AS that sent
+# them. Requires the SCION Daemon. (default false)
+scmp_auth = false
 `
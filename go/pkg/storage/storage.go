@@ -21,8 +21,12 @@ import (
 
 	"github.com/scionproto/scion/go/cs/beacon"
 	sqlitebeacondb "github.com/scionproto/scion/go/cs/beacon/beacondbsqlite"
+	sqlitereservationdb "github.com/scionproto/scion/go/cs/reservation/sqlite"
+	"github.com/scionproto/scion/go/cs/reservationstorage/backend"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/config"
+	"github.com/scionproto/scion/go/lib/drkey"
+	sqlitedrkeydb "github.com/scionproto/scion/go/lib/drkey/sqlite"
 	"github.com/scionproto/scion/go/lib/infra/modules/db"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathdb"
@@ -53,12 +57,18 @@ var (
 	SamplePathDB = DBConfig{
 		Connection: "/share/cache/%s.path.db",
 	}
+	SampleReservationDB = DBConfig{
+		Connection: "/share/cache/%s.reservation.db",
+	}
 	SampleRenewalDB = DBConfig{
 		Connection: "/share/data/trustdb/%s.renewal.db",
 	}
 	SampleTrustDB = DBConfig{
 		Connection: "/share/data/trustdb/%s.trust.db",
 	}
+	SampleDRKeyLvl1DB = DBConfig{
+		Connection: "/share/cache/%s.drkey_lvl1.db",
+	}
 )
 
 // SetID returns a clone of the configuration that has the ID set on the connection string.
@@ -150,3 +160,23 @@ func NewRenewalStorage(c DBConfig) (renewal.DB, error) {
 	SetConnLimits(db, c)
 	return db, nil
 }
+
+func NewReservationStorage(c DBConfig) (backend.DB, error) {
+	log.Info("Connecting ReservationDB", "backend", BackendSqlite, "connection", c.Connection)
+	db, err := sqlitereservationdb.New(c.Connection)
+	if err != nil {
+		return nil, err
+	}
+	SetConnLimits(db, c)
+	return db, nil
+}
+
+func NewDRKeyLvl1Storage(c DBConfig) (drkey.Lvl1DB, error) {
+	log.Info("Connecting DRKeyLvl1DB", "backend", BackendSqlite, "connection", c.Connection)
+	db, err := sqlitedrkeydb.New(c.Connection)
+	if err != nil {
+		return nil, err
+	}
+	SetConnLimits(db, c)
+	return db, nil
+}
@@ -26,6 +26,10 @@ func CheckTestBeaconDBConfig(t *testing.T, cfg *storage.DBConfig, id string) {
 	assert.Equal(t, storage.SetID(storage.SampleBeaconDB, id), cfg)
 }
 
+func CheckTestDRKeyLvl1DBConfig(t *testing.T, cfg *storage.DBConfig, id string) {
+	assert.Equal(t, storage.SetID(storage.SampleDRKeyLvl1DB, id), cfg)
+}
+
 func CheckTestPathDBConfig(t *testing.T, cfg *storage.DBConfig, id string) {
 	assert.Equal(t, storage.SetID(storage.SamplePathDB, id), cfg)
 }
@@ -34,6 +38,10 @@ func CheckTestRenewalDBConfig(t *testing.T, cfg *storage.DBConfig, id string) {
 	assert.Equal(t, storage.SetID(storage.SampleRenewalDB, id), cfg)
 }
 
+func CheckTestReservationDBConfig(t *testing.T, cfg *storage.DBConfig, id string) {
+	assert.Equal(t, storage.SetID(storage.SampleReservationDB, id), cfg)
+}
+
 func CheckTestTrustDBConfig(t *testing.T, cfg *storage.DBConfig, id string) {
 	assert.Equal(t, storage.SetID(storage.SampleTrustDB, id), cfg)
 }
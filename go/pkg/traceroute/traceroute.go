@@ -0,0 +1,426 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package traceroute implements tracing the path to a remote host based on
+// SCMP traceroute messages.
+//
+// Each interface on the path is probed, followed by the remote host. With the
+// SCION header v1, the border router that must reply to a probe is selected
+// with the hop offset in the traceroute info and the SCMP hop-by-hop
+// extension. The header v2 has no extensions, there the router alert flag of
+// the hop field of the interface is set instead.
+package traceroute
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/layers"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/scmp"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/spkt"
+	"github.com/scionproto/scion/go/lib/topology/underlay"
+)
+
+// Stats contains the statistics of a traceroute run.
+type Stats struct {
+	Sent     int
+	Received int
+}
+
+// Update contains the replies to the probes of a hop.
+type Update struct {
+	// Index is the index of the hop. The hops are the interfaces on the path,
+	// followed by the remote host.
+	Index int
+	// Remote is the address that replied to the probes. It is empty if no
+	// probe was answered.
+	Remote snet.SCIONAddress
+	// Interface is the interface the reply was sent for. It is 0 for the
+	// remote host.
+	Interface common.IFIDType
+	// RTTs are the round trip times of the probes. The RTT of a probe that
+	// timed out is negative.
+	RTTs []time.Duration
+}
+
+// Config configures the traceroute run.
+type Config struct {
+	Dispatcher reliable.Dispatcher
+	Local      *snet.UDPAddr
+	// Remote is the remote host. Its path is the path that is traced.
+	Remote *snet.UDPAddr
+	// PathEntry is the path to the remote host. It is nil if the remote host
+	// is in the local AS.
+	PathEntry snet.Path
+
+	// ProbesPerHop is the number of probes that are sent to each hop.
+	ProbesPerHop int
+	// Timeout is the time until a probe is considered to have timed out.
+	Timeout time.Duration
+
+	// ErrHandler is invoked for every error that does not cause tracing to
+	// abort. Execution time must be small, as it is run synchronous.
+	ErrHandler func(err error)
+	// UpdateHandler is invoked for every hop after all its probes were
+	// answered or timed out. Execution time must be small, as it is run
+	// synchronous.
+	UpdateHandler func(Update)
+
+	// SCMPKeys, if set, are used to verify that SCMP errors are authenticated
+	// by the AS that sent them. SCMP errors that fail verification are
+	// dropped.
+	SCMPKeys snet.SCMPKeyProvider
+
+	HeaderV2 bool
+}
+
+// Run traces the path to the remote host with the configuration. This blocks
+// until all hops are probed, or the context is canceled.
+func Run(ctx context.Context, cfg Config) (Stats, error) {
+	if cfg.ProbesPerHop < 1 {
+		return Stats{}, serrors.New("at least one probe per hop required")
+	}
+	var path *spath.Path
+	var numIntfs int
+	if cfg.PathEntry != nil {
+		path = cfg.PathEntry.Path()
+		numIntfs = len(cfg.PathEntry.Interfaces())
+	}
+	if path == nil && !cfg.Local.IA.Equal(cfg.Remote.IA) {
+		return Stats{}, serrors.New("no path for remote ISD-AS", "local", cfg.Local.IA,
+			"remote", cfg.Remote.IA)
+	}
+	var hops []probe
+	var err error
+	if cfg.HeaderV2 {
+		hops, err = probesV2(path, numIntfs)
+	} else {
+		hops, err = probesV1(cfg.Local, cfg.Remote, path, numIntfs)
+	}
+	if err != nil {
+		return Stats{}, err
+	}
+
+	replies := make(chan reply, 10)
+	svc := snet.DefaultPacketDispatcherService{
+		Dispatcher:  cfg.Dispatcher,
+		SCMPHandler: scmpHandler{replies: replies, keys: cfg.SCMPKeys},
+		Version2:    cfg.HeaderV2,
+	}
+	conn, port, err := svc.Register(ctx, cfg.Local.IA, cfg.Local.Host, addr.SvcNone)
+	if err != nil {
+		return Stats{}, err
+	}
+	local := cfg.Local.Copy()
+	local.Host.Port = int(port)
+
+	t := tracer{
+		probesPerHop:  cfg.ProbesPerHop,
+		timeout:       cfg.Timeout,
+		id:            rand.Uint64(),
+		conn:          conn.(*snet.SCIONPacketConn),
+		local:         local,
+		remote:        cfg.Remote,
+		replies:       replies,
+		errHandler:    cfg.ErrHandler,
+		updateHandler: cfg.UpdateHandler,
+	}
+	return t.Trace(ctx, hops)
+}
+
+// probe describes how the probes of a hop are sent.
+type probe struct {
+	path *spath.Path
+	ext  []common.Extension
+	info scmp.InfoTraceRoute
+}
+
+// probesV1 returns the probes of the interfaces on the header v1 path,
+// followed by the probe of the remote host.
+func probesV1(local, remote *snet.UDPAddr, path *spath.Path, numIntfs int) ([]probe, error) {
+	ext := []common.Extension{&layers.ExtnSCMP{Error: false, HopByHop: true}}
+	if path == nil {
+		return []probe{{ext: ext}}, nil
+	}
+	// The hop offset in the traceroute info is relative to the start of the
+	// packet.
+	hdrLen := spkt.CmnHdrLen + spkt.AddrHdrLen(addr.HostFromIP(local.Host.IP),
+		addr.HostFromIP(remote.Host.IP))
+	p := path.Copy()
+	probes := make([]probe, 0, numIntfs+1)
+	in := false
+	for i := 0; i < numIntfs; i++ {
+		if i > 0 {
+			if err := nextHop(p, in); err != nil {
+				return nil, err
+			}
+			in = !in
+		}
+		probes = append(probes, probe{
+			path: path,
+			ext:  ext,
+			info: scmp.InfoTraceRoute{
+				HopOff: uint8((hdrLen + p.HopOff) / common.LineLen),
+				In:     in,
+			},
+		})
+	}
+	return append(probes, probe{path: path, ext: ext}), nil
+}
+
+// nextHop advances the offsets of the path to the hop field of the next
+// interface. If in is false, the current interface is the egress interface of
+// the current hop field.
+func nextHop(p *spath.Path, in bool) error {
+	if !in {
+		return p.IncOffsets()
+	}
+	hopF, err := p.GetHopField(p.HopOff)
+	if err != nil {
+		return err
+	}
+	if hopF.Xover {
+		// The egress interface of this AS is in the hop field of the next
+		// segment.
+		return p.IncOffsets()
+	}
+	return nil
+}
+
+// probesV2 returns the probes of the interfaces on the header v2 path,
+// followed by the probe of the remote host.
+func probesV2(path *spath.Path, numIntfs int) ([]probe, error) {
+	probes := make([]probe, 0, numIntfs+1)
+	for i := 0; i < numIntfs; i++ {
+		p, in, err := routerAlert(path, i)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, probe{path: p, info: scmp.InfoTraceRoute{In: in}})
+	}
+	return append(probes, probe{path: path}), nil
+}
+
+type tracer struct {
+	probesPerHop int
+	timeout      time.Duration
+
+	id      uint64
+	conn    *snet.SCIONPacketConn
+	local   *snet.UDPAddr
+	remote  *snet.UDPAddr
+	replies <-chan reply
+
+	// Handlers
+	errHandler    func(error)
+	updateHandler func(Update)
+
+	// Mutable state
+	sent  uint64
+	stats Stats
+}
+
+func (t *tracer) Trace(ctx context.Context, hops []probe) (Stats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		defer log.HandlePanic()
+		t.drain(ctx)
+	}()
+
+	for i, hop := range hops {
+		u := Update{Index: i}
+		for j := 0; j < t.probesPerHop; j++ {
+			reply, rtt, err := t.probe(ctx, hop)
+			if err != nil {
+				return t.stats, err
+			}
+			if ctx.Err() != nil {
+				return t.stats, nil
+			}
+			u.RTTs = append(u.RTTs, rtt)
+			if reply != nil {
+				u.Remote = reply.Source
+				u.Interface = reply.Info.IfID
+			}
+		}
+		if t.updateHandler != nil {
+			t.updateHandler(u)
+		}
+	}
+	return t.stats, nil
+}
+
+// probe sends a probe and waits for the reply. If the probe times out, the
+// reply is nil and the RTT is negative.
+func (t *tracer) probe(ctx context.Context, hop probe) (*reply, time.Duration, error) {
+	info := hop.info
+	info.Id = t.id + t.sent
+	if err := t.send(hop, info); err != nil {
+		return nil, 0, serrors.WrapStr("sending", err)
+	}
+	sent := time.Now()
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, -1, nil
+		case <-timer.C:
+			return nil, -1, nil
+		case reply := <-t.replies:
+			if reply.Error != nil {
+				if t.errHandler != nil {
+					t.errHandler(reply.Error)
+				}
+				continue
+			}
+			if reply.Info.Id != info.Id {
+				// Reply to a probe that already timed out.
+				continue
+			}
+			t.stats.Received++
+			return &reply, reply.Received.Sub(sent).Round(time.Microsecond), nil
+		}
+	}
+}
+
+func (t *tracer) send(hop probe, info scmp.InfoTraceRoute) error {
+	pld := make([]byte, scmp.MetaLen+info.Len())
+	meta := scmp.Meta{InfoLen: uint8(info.Len() / common.LineLen)}
+	meta.Write(pld)
+	info.Write(pld[scmp.MetaLen:])
+	pkt := &snet.Packet{
+		PacketInfo: snet.PacketInfo{
+			Destination: snet.SCIONAddress{
+				IA:   t.remote.IA,
+				Host: addr.HostFromIP(t.remote.Host.IP),
+			},
+			Source: snet.SCIONAddress{
+				IA:   t.local.IA,
+				Host: addr.HostFromIP(t.local.Host.IP),
+			},
+			Path:       hop.path,
+			Extensions: hop.ext,
+			L4Header: scmp.NewHdr(
+				scmp.ClassType{
+					Class: scmp.C_General,
+					Type:  scmp.T_G_TraceRouteRequest,
+				},
+				len(pld),
+			),
+			Payload: common.RawBytes(pld),
+		},
+	}
+	nextHop := t.remote.NextHop
+	if nextHop == nil && t.local.IA.Equal(t.remote.IA) {
+		nextHop = &net.UDPAddr{
+			IP:   t.remote.Host.IP,
+			Port: underlay.EndhostPort,
+			Zone: t.remote.Host.Zone,
+		}
+	}
+	if err := t.conn.WriteTo(pkt, nextHop); err != nil {
+		return err
+	}
+	t.sent++
+	t.stats.Sent++
+	return nil
+}
+
+func (t *tracer) drain(ctx context.Context) {
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			var pkt snet.Packet
+			var ov net.UDPAddr
+			if err := t.conn.ReadFrom(&pkt, &ov); err != nil && t.errHandler != nil {
+				// Rate limit the error reports.
+				if now := time.Now(); now.Sub(last) > 500*time.Millisecond {
+					t.errHandler(serrors.WrapStr("reading packet", err))
+					last = now
+				}
+			}
+		}
+	}
+}
+
+type reply struct {
+	Received time.Time
+	Source   snet.SCIONAddress
+	Info     *scmp.InfoTraceRoute
+	Error    error
+}
+
+type scmpHandler struct {
+	replies chan<- reply
+	keys    snet.SCMPKeyProvider
+}
+
+func (h scmpHandler) Handle(pkt *snet.Packet) error {
+	// Unauthenticated SCMP errors are dropped.
+	if err := h.verify(pkt); err != nil {
+		return nil
+	}
+	info, err := h.handle(pkt)
+	h.replies <- reply{
+		Error:    err,
+		Source:   pkt.Source,
+		Info:     info,
+		Received: time.Now(),
+	}
+	return nil
+}
+
+func (h scmpHandler) handle(pkt *snet.Packet) (*scmp.InfoTraceRoute, error) {
+	if _, ok := pkt.L4Header.(*scmp.Hdr); !ok {
+		return nil, serrors.New("not an SCMP header", "type", common.TypeOf(pkt.L4Header))
+	}
+	scmpPld, ok := pkt.PacketInfo.Payload.(*scmp.Payload)
+	if !ok {
+		return nil, serrors.New("not an SCMP payload", "type", common.TypeOf(pkt.Payload))
+	}
+	info, ok := scmpPld.Info.(*scmp.InfoTraceRoute)
+	if !ok {
+		return nil, serrors.New("not a traceroute", "type", common.TypeOf(scmpPld.Info))
+	}
+	return info, nil
+}
+
+// verify verifies the authenticator of SCMP errors if keys are set. Traceroute
+// replies are not authenticated.
+func (h scmpHandler) verify(pkt *snet.Packet) error {
+	if h.keys == nil {
+		return nil
+	}
+	if hdr, ok := pkt.L4Header.(*scmp.Hdr); ok && hdr.Class == scmp.C_General {
+		return nil
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), snet.SCMPAuthTimeout)
+	defer cancelF()
+	return snet.VerifySCMPAuth(ctx, pkt, h.keys)
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceroute
+
+import (
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/spath"
+)
+
+// routerAlert returns a copy of the header v2 path with the router alert flag
+// set for the interface at index idx of the path interfaces. The interfaces
+// are the non-zero interfaces of the hop fields in the order they are
+// traversed. It also returns whether the interface is an ingress interface in
+// the direction of travel.
+func routerAlert(path *spath.Path, idx int) (*spath.Path, bool, error) {
+	var decoded scion.Decoded
+	if err := decoded.DecodeFromBytes(path.Raw); err != nil {
+		return nil, false, serrors.WrapStr("decoding path", err)
+	}
+	seg, segEnd := 0, int(decoded.PathMeta.SegLen[0])
+	current := 0
+	for i, hf := range decoded.HopFields {
+		for i >= segEnd && seg < decoded.NumINF-1 {
+			seg++
+			segEnd += int(decoded.PathMeta.SegLen[seg])
+		}
+		consDir := decoded.InfoFields[seg].ConsDir
+		ingress, egress := hf.ConsIngress, hf.ConsEgress
+		if !consDir {
+			ingress, egress = egress, ingress
+		}
+		if ingress != 0 {
+			if current == idx {
+				// The flags refer to the interfaces in construction direction.
+				if consDir {
+					hf.IngressRouterAlert = true
+				} else {
+					hf.EgressRouterAlert = true
+				}
+				p, err := serialize(&decoded)
+				return p, true, err
+			}
+			current++
+		}
+		if egress != 0 {
+			if current == idx {
+				if consDir {
+					hf.EgressRouterAlert = true
+				} else {
+					hf.IngressRouterAlert = true
+				}
+				p, err := serialize(&decoded)
+				return p, false, err
+			}
+			current++
+		}
+	}
+	return nil, false, serrors.New("interface not on path", "index", idx, "interfaces", current)
+}
+
+func serialize(decoded *scion.Decoded) (*spath.Path, error) {
+	raw := make([]byte, decoded.Len())
+	if err := decoded.SerializeTo(raw); err != nil {
+		return nil, serrors.WrapStr("serializing path", err)
+	}
+	return spath.NewV2(raw, false), nil
+}
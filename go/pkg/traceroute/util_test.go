@@ -0,0 +1,81 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package traceroute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/slayers/path"
+	"github.com/scionproto/scion/go/lib/slayers/path/scion"
+	"github.com/scionproto/scion/go/lib/spath"
+)
+
+func TestRouterAlert(t *testing.T) {
+	mac := []byte{1, 2, 3, 4, 5, 6}
+	// Up segment 110 -> 120, down segment 120 -> 130. The path interfaces
+	// are 110#1, 120#2, 120#3 and 130#4.
+	decoded := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{SegLen: [3]uint8{2, 2, 0}},
+			NumINF:   2,
+			NumHops:  4,
+		},
+		InfoFields: []*path.InfoField{
+			{ConsDir: false, SegID: 0x111},
+			{ConsDir: true, SegID: 0x222},
+		},
+		HopFields: []*path.HopField{
+			{ConsIngress: 1, Mac: mac},
+			{ConsEgress: 2, Mac: mac},
+			{ConsEgress: 3, Mac: mac},
+			{ConsIngress: 4, Mac: mac},
+		},
+	}
+	raw := make([]byte, decoded.Len())
+	require.NoError(t, decoded.SerializeTo(raw))
+	orig := spath.NewV2(raw, false)
+
+	tests := []struct {
+		Hop     int
+		Ingress bool
+		In      bool
+	}{
+		{Hop: 0, Ingress: true, In: false},
+		{Hop: 1, Ingress: false, In: true},
+		{Hop: 2, Ingress: false, In: false},
+		{Hop: 3, Ingress: true, In: true},
+	}
+	for i, test := range tests {
+		p, in, err := routerAlert(orig, i)
+		require.NoError(t, err, "interface %d", i)
+		assert.Equal(t, test.In, in, "interface %d", i)
+		var result scion.Decoded
+		require.NoError(t, result.DecodeFromBytes(p.Raw))
+		for j, hf := range result.HopFields {
+			alert := j == test.Hop
+			assert.Equal(t, alert && test.Ingress, hf.IngressRouterAlert,
+				"interface %d hop %d", i, j)
+			assert.Equal(t, alert && !test.Ingress, hf.EgressRouterAlert,
+				"interface %d hop %d", i, j)
+		}
+	}
+	_, _, err := routerAlert(orig, 4)
+	assert.Error(t, err)
+	// The original path is not modified.
+	assert.Equal(t, raw, []byte(orig.Raw))
+}
@@ -2314,6 +2314,122 @@ func (p Response_Promise) E2eCleanup() E2ECleanupData_Promise {
 	return E2ECleanupData_Promise{Pipeline: p.Pipeline.GetPipeline(0)}
 }
 
+type HopByHopFields struct{ capnp.Struct }
+
+// HopByHopFields_TypeID is the unique identifier for the type HopByHopFields.
+const HopByHopFields_TypeID = 0xa4d6dce2a8cc08d8
+
+func NewHopByHopFields(s *capnp.Segment) (HopByHopFields, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return HopByHopFields{st}, err
+}
+
+func NewRootHopByHopFields(s *capnp.Segment) (HopByHopFields, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
+	return HopByHopFields{st}, err
+}
+
+func ReadRootHopByHopFields(msg *capnp.Message) (HopByHopFields, error) {
+	root, err := msg.RootPtr()
+	return HopByHopFields{root.Struct()}, err
+}
+
+func (s HopByHopFields) String() string {
+	str, _ := text.Marshal(0xa4d6dce2a8cc08d8, s.Struct)
+	return str
+}
+
+func (s HopByHopFields) SegmentID() (SegmentReservationID, error) {
+	p, err := s.Struct.Ptr(0)
+	return SegmentReservationID{Struct: p.Struct()}, err
+}
+
+func (s HopByHopFields) HasSegmentID() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s HopByHopFields) SetSegmentID(v SegmentReservationID) error {
+	return s.Struct.SetPtr(0, v.Struct.ToPtr())
+}
+
+// NewSegmentID sets the segmentID field to a newly
+// allocated SegmentReservationID struct, preferring placement in s's segment.
+func (s HopByHopFields) NewSegmentID() (SegmentReservationID, error) {
+	ss, err := NewSegmentReservationID(s.Struct.Segment())
+	if err != nil {
+		return SegmentReservationID{}, err
+	}
+	err = s.Struct.SetPtr(0, ss.Struct.ToPtr())
+	return ss, err
+}
+
+func (s HopByHopFields) InfoField() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return []byte(p.Data()), err
+}
+
+func (s HopByHopFields) HasInfoField() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s HopByHopFields) SetInfoField(v []byte) error {
+	return s.Struct.SetData(1, v)
+}
+
+func (s HopByHopFields) Path() ([]byte, error) {
+	p, err := s.Struct.Ptr(2)
+	return []byte(p.Data()), err
+}
+
+func (s HopByHopFields) HasPath() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s HopByHopFields) SetPath(v []byte) error {
+	return s.Struct.SetData(2, v)
+}
+
+func (s HopByHopFields) CurrentStep() uint8 {
+	return s.Struct.Uint8(0)
+}
+
+func (s HopByHopFields) SetCurrentStep(v uint8) {
+	s.Struct.SetUint8(0, v)
+}
+
+// HopByHopFields_List is a list of HopByHopFields.
+type HopByHopFields_List struct{ capnp.List }
+
+// NewHopByHopFields creates a new list of HopByHopFields.
+func NewHopByHopFields_List(s *capnp.Segment, sz int32) (HopByHopFields_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3}, sz)
+	return HopByHopFields_List{l}, err
+}
+
+func (s HopByHopFields_List) At(i int) HopByHopFields { return HopByHopFields{s.List.Struct(i)} }
+
+func (s HopByHopFields_List) Set(i int, v HopByHopFields) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s HopByHopFields_List) String() string {
+	str, _ := text.MarshalList(0xa4d6dce2a8cc08d8, s.List)
+	return str
+}
+
+// HopByHopFields_Promise is a wrapper for a HopByHopFields promised by a client call.
+type HopByHopFields_Promise struct{ *capnp.Pipeline }
+
+func (p HopByHopFields_Promise) Struct() (HopByHopFields, error) {
+	s, err := p.Pipeline.Struct()
+	return HopByHopFields{s}, err
+}
+
+func (p HopByHopFields_Promise) SegmentID() SegmentReservationID_Promise {
+	return SegmentReservationID_Promise{Pipeline: p.Pipeline.GetPipeline(0)}
+}
+
 type ColibriRequestPayload struct{ capnp.Struct }
 type ColibriRequestPayload_Which uint16
 
@@ -2341,12 +2457,12 @@ func (w ColibriRequestPayload_Which) String() string {
 const ColibriRequestPayload_TypeID = 0xc571cc47a792000f
 
 func NewColibriRequestPayload(s *capnp.Segment) (ColibriRequestPayload, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
 	return ColibriRequestPayload{st}, err
 }
 
 func NewRootColibriRequestPayload(s *capnp.Segment) (ColibriRequestPayload, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2})
 	return ColibriRequestPayload{st}, err
 }
 
@@ -2442,12 +2558,37 @@ func (s ColibriRequestPayload) NewResponse() (Response, error) {
 	return ss, err
 }
 
+func (s ColibriRequestPayload) HopByHop() (HopByHopFields, error) {
+	p, err := s.Struct.Ptr(1)
+	return HopByHopFields{Struct: p.Struct()}, err
+}
+
+func (s ColibriRequestPayload) HasHopByHop() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s ColibriRequestPayload) SetHopByHop(v HopByHopFields) error {
+	return s.Struct.SetPtr(1, v.Struct.ToPtr())
+}
+
+// NewHopByHop sets the hopByHop field to a newly
+// allocated HopByHopFields struct, preferring placement in s's segment.
+func (s ColibriRequestPayload) NewHopByHop() (HopByHopFields, error) {
+	ss, err := NewHopByHopFields(s.Struct.Segment())
+	if err != nil {
+		return HopByHopFields{}, err
+	}
+	err = s.Struct.SetPtr(1, ss.Struct.ToPtr())
+	return ss, err
+}
+
 // ColibriRequestPayload_List is a list of ColibriRequestPayload.
 type ColibriRequestPayload_List struct{ capnp.List }
 
 // NewColibriRequestPayload creates a new list of ColibriRequestPayload.
 func NewColibriRequestPayload_List(s *capnp.Segment, sz int32) (ColibriRequestPayload_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 2}, sz)
 	return ColibriRequestPayload_List{l}, err
 }
 
@@ -2480,126 +2621,139 @@ func (p ColibriRequestPayload_Promise) Response() Response_Promise {
 	return Response_Promise{Pipeline: p.Pipeline.GetPipeline(0)}
 }
 
-const schema_a3bf9fed859570f0 = "x\xda\xd4Wm\x8cTW\xf9\x7f\x9es\xe6maw" +
-	"g\x86;M\xf9\xf3O\\KJ\xb3\x10\x0at\x97\xa8" +
-	" \xed\xd4\xdd\x1de+\xd593\xbb)E6\xe5\xb2" +
-	"s\xb6\\\x9d\xb9w\xe6\xde\xbb]j\x8aT\xc4D\x82" +
-	"\x8amZ\xad\x1f\xacPi,\xbaI\x8b\xa9Ii\xa8" +
-	"o)\x81\xbe\x18c\xacV\x1b\x8d\x1am\x9aX1\x85" +
-	"\xa4\xd4-\x96k\x9e33\xf7\xce\xab\xcb\x07\xbf\xf8m" +
-	"\xf7<\xbfy\xces\x9f\xdf\xefy9\x1b\xfa\xc3\xb7\xb2" +
-	"\x9b\xc2G\"\x00b[8\xe2\x1d\\\xd8\xb6\xf3\xfb?" +
-	"\xfb\xcda\x10\xfd\x88\xde[\xe5\x87\xbfx\xfe\xdb?\xfe" +
-	"\x0e\x84\xa2\x00\xc37\xf25\xa8\xdd\xcc\xa3\x00\xda&\x9e" +
-	"\x06\xf4~x\xe5\x8d\xdf^<\xf7\x8f\xafB\xb2\xbf\x01" +
-	"\x1bf\x04\x9e\xe2+P+)\xb0\xc1\xe7\x00\xbdWg" +
-	"\x9e\xfb\x84\xbd\xeb\xef_o\x01#!\xce\xf0\xbfi\xbf" +
-	"T\xd8\x9f+\xc7w\xad\xdc\xfd\x97[^\xba\xf4\x0d\x10" +
-	"\xff\x8f\xe8\xfd\xc2\x9d\x1a\xfe\xc2\xce\xfc\x9bp\x0d\x8f\"" +
-	"\xc0\xf0\x05\xbe\x02\x01\xb5\x05\x05\xfd\xdc\xe0\x9b\xc7\x7f\xe4" +
-	"\x96\x1ek\x098L\xde\x86\xff/\xb4\x0c\xb5\xd5*\xf8" +
-	"U\xa1;\x10\xd0\xdbsf\xe9\xa7\xce\x7f\xfc\xc3\x8f7" +
-	"G\xa1\x10\x0f\x84\xd7\xa0v,\x1c\x05\xee=;\xf8\xd7" +
-	"\xc37?\x99?\xd1\xe9\xc3\xf6\x85W\xa2\xf6\x950\x05" +
-	"{(L\x1f\xf6\xf8\xfaug'\xc4\xc6\xef\xb5D\x90" +
-	"\xc1(\x07\x18~=\xbc\x0c\xb5\xb7\x15\xfcB\xf8I@" +
-	"\xef\x86?L\x1dY\xe6\xcd\x9f\xee\x90a\xedP\xe45" +
-	"\xed\xe1\x08\xfd\xf5@\x84\\\xcf/\x0c\xbe\xb3\xf3\x937" +
-	"<\xdf\x89\x8d\x0b\x91\x1d\xa8\x85\xa3\x04\xc6\xe8\x1c\xe0{" +
-	"\xf1\x07\xbf\xfb\xb1\x97+g\xda\xa3@6<\x15]\x89" +
-	"ZI\x81\x8d\xe8\x1b\x80\xde\xb1\x85\x89\xb7\x8e\\\xfb\xb5" +
-	"\xb3\x90\xecg\x01\x1ap8\x13[\x89\xdad\x8c\x90\"" +
-	"\xf6A@o\xcb\x8b\x9b\xde\x97.\xbd\xfe\xab\xd6\x04\x13" +
-	"q\xc3\x93\xb1%\xa8I\x85\xd6cs\x80\x97G.\x0c" +
-	"}\xe0\xf2\xe1_w\xf8\xb6\xe7b\x17\xb5\x17\x14\xf2\x0c" +
-	"!\xbd}\xdb\xcf\xffn\xfd\xfa\xb3\x7fjO\xdbR\x00" +
-	"\xed\xba\x9eg\xb5\xd5=\x8a\xb8\x9e?2@o\xcc>" +
-	"\xf7\xfb\xf9\xbbJ\x7f\xee\x0c\x9f\xec\xfd\x816\xd5K\xf0" +
-	";{-\x82\xfb\x9ai\x81s\"E\xeb\x89\xbf\xa6]" +
-	"\x13\xa7X\x92q\xca\xc6\xa3\x95[V]\xcf_\xba\xd8" +
-	"Yo'\xe3Jo\xa7\xe2D\xdf\xc1\xe5\xd7>z\xe0" +
-	"\xd5\xeb.u\x90\x86f$\xde\xd5f\x13\xf4W%A" +
-	"\x9f8m\x15\x8d\xdd\xb6\xb1\x8eO\xebe\xb3\xbc9/" +
-	"\xef.I\xd3\x9d\x90\xba]\xb0\xe6\xcc\x9ct\xe2c\xba" +
-	"\xabg\x11E\x88\x87\x00B\x08\x90\xec\xcb\x01\x88^\x8e" +
-	"b9CO\xda\xb6e\x8fZ\x05@\x89\x11`\x18\xe9" +
-	"\xea4'\x1di\xdf\xa3\xbb\x86e\xf2\xf11r\x19\xf3" +
-	"]\xae^\x03 \xae\xe7(60L\"\xa6\x90\x0eo" +
-	"\xdc\x0c \x069\x8a\x8d\x0c\xe3\xbac\x14\xb0\x0f\x18\xf6" +
-	"\x01\xa6\x9d\xd9\x99\x19co\xfd_\xff>V\xbd/3" +
-	"\x94\x19-J\xdd\x9c-w\x0a\xden\x08\xde\xae\x85\x04" +
-	"\x03\x86e\x8e\x8fa\"\xc8\x1d &\xda?%3\x94" +
-	"\xc9Kw\xb6L~\xd79\xb3\xd3\xd3\x92;\x0eyO" +
-	"`\x0a\x19\xb9\x1f\x02\x101\x8e\"\xc5p\xc0\xb5>#" +
-	"\xcd\xb6(\x9b\xb3\xa2\xdc\xe5deLwQ\x85\xba\xdc" +
-	"\x0f\xf5\x9b\xe4\xeb!\x8e\xe2hCR\xbeE\x87\x8fp" +
-	"\x14\xc7\x19&\x19\xab\xdez\xec6\x00q\x94\xa3\x98g" +
-	"\x88<\x85\x1c yb\x07\x80x\x82\xa3x\x9aa2" +
-	"\x84)\x0c\x01$O\x12\xf0)\x8e\xe24\xc3d\x98\xa5" +
-	"0\x0c\x90<u\x00@<\xc3Q\xbc\xc2p\xa0d\x98" +
-	"#w\xd4\x99\x1c(\xe9{\x83\xff<\xa7\\4\xdc\xd1" +
-	"\xa2\x03\x00\xc1\x99\xab\xdbn\xd6\xb6\x80\x97\x1dL\x04\xad" +
-	"\xa3\x96?i\x16\xb2\xb6UV?i\xb7\xea\xc5\xa25" +
-	"\xad\xbb\x06Z\xe6\x84\xad\x1bE\xc0~\xc0,GL\xd4" +
-	"\xab\x14\x90\x8e\x16\x95i\xa5\xcetWdQ:U\xe6" +
-	"\xa25I4\x88oh\x11\xf1\x0d8\xf4KL\x04\x9d" +
-	"\xbc\x1a\x7fz\xb7\xeeH%\x1b\x7f\xcct\x96M3\xd7" +
-	"\x8e\xcfu/\x0f\xf5z\x9e\x0a\"CA\xdc\xcaQl" +
-	"c\xd8\x87W\xbcj\x14\xe3#\x00b\x8c\xa3\xc82\xec" +
-	"c\xefyU\xbao'\xecV\x8eb\x82\xe1\xc0\xac\xe9" +
-	"H\x17\"\xfbgt\xa38k\xcb\xf6(\xbb\xc8\xb0V" +
-	",Y\xdd\xdd\x93Q$\xf1\xb2s5y!\x05\xad\xe5" +
-	"(>\xc4p\x80\xd8+\"\x02C\x1a\\\xae\xad\x9b\xce" +
-	"\x8c\xb4\x89\xeb\xfaY\xfd\xb6PS\"\xc6\xcd\x82\xdc;" +
-	"j\x993\x86]R\x0daL\xe7W\xc7\xcaP\x03+" +
-	"\x06y\xf1\xa5\xea\xb8\xba+1\x1e\x8c\x0d@\x8c\xb7S" +
-	"1Z\xfd7'+\xb3\xd2q\xb3\xfa\xbd\xc5\xa8\xa5\x17" +
-	"\xe8\xea\x84\x7f\xb5N\x0dn\x17GQ$.\xbc\x1a\x17" +
-	"\x06\xdd]\xe0(\xca\xc4\xc5\x95\x1a\x17%bh\x0fG" +
-	"\xe12\xec\xe3\xc4\x10\x15_\x85\xb2T\xe6(\xeec\xe8" +
-	"\xb9FI:\xae^\x02,c\x0c\x18\xc6 `\xcd\xae" +
-	"\xc6\x81\x89`z\xd4$dK\xa7l\x99\x8e\xacV\x8e" +
-	"?\x8a:\x0b\xac\xa1\xb7\xaa\xdc\xe6\xdd\xa8\xeeJ\x95P" +
-	"\x15er\x84~\x98\xec\xd9\x0c\xb0\xbf,\xcd\x82a\xde" +
-	"\x9d\xd6\xa7]\xe3\x1e\xd9E\xaa\xb5\x06JU\x05\xd0B" +
-	"\xcc\x8a\x80\x98N\xbcp\xa3\xd0^\x11\xcdd\xb5\x8a\xf0" +
-	"#\xb5>`\x99\x03#R/\xb4\xeapd\x11%\xec" +
-	"W}\xa4k\xdb\xaa_\x86\xf5\\\xa5\xab\x99\xa5[6" +
-	"\xfa\x15\xa8M\xe1\x10@~;r\xcc\x17\xb0\xa1\x085" +
-	"\x1d?\x0d\x90\xdfE\x86\"6\xd4\xa1f\xe0g\x01\xf2" +
-	"{\xc8\xe0\x92\x81\xff\xabJ\xbfV\xc1\x07\x01\xf2.\x19" +
-	"\xee'C\xe8\xb2\xa7\xfa\xaf\xb6\x0f\x1f\x03\xc8\xdfO\x86" +
-	"/\x93!\xfc\xae\xa7z\xb0v\x08\x0f\x00\xe4\xbfD\x86" +
-	"\x87\xc8\x10Y\xf0R\x18\xa1-\x0b_\x04\xc8?B\x86" +
-	"\xe3d\x88\xfe\xd3K\xa9\x85\xf4\x98\xba\xfc(\x19\xe6\xc9" +
-	"\x10{\xc7Ka\x0c@;\x81\xb7\x01\xe4\x9f \xc3\xd3" +
-	"d\xe8\xb9\xe4\xa5\xb0\x07@;\x89;\x00\xf2O\x91\xe1" +
-	"4\x19\x96\xbc\xed\xa5p\x09\x80vJ\x19\x9e!\xc3\xf3" +
-	"\xc80\xb94\x91BZY~\xaa<\xfd\x84\xce_\xa6" +
-	"\xf3^\x9e\xc2^\x00\xed\x05\xcc\x01\xe4\xcf\xd1\xf9+\x18" +
-	"\xb4 \xcf\xa9\xb59\x88\xd7\xfa\xa5\xbfw\xd6T[\x03" +
-	"\xe4 -M9\xa7\x17\xbbC&\xb0\xde\xb1\xb1\xbc\x18" +
-	"*'M\x19]\xcc[uRP)\xf9\xef\x87f\xcc" +
-	"8kmJ\xaa\xf0\xfc\xfd\xb6\x19=\x0a\xe9j\x85`" +
-	"\"X?\xeb3oH\xaaN_\xad\\\x7fU\x0b\xac" +
-	"\x14\xf0\x1cp\x15q\x07\xb3\xaa=\xe0\xca\xb7\xff$\xa9" +
-	"O\xcc\xe9iYve\xa1\xb1\xc7R\xe7\x97\x85\xad\x16" +
-	"\xf5\x97\xae\x9a\x1f\xa8\xf6;D\xb16\x90\xfc*%\xf9" +
-	"\xf7\x13\x91k\x9b$\xbfZI~\x90\x0c\x1b\x9b$\x7f" +
-	"\x93R\xdd\x062li\x92\xfc&%\xf9-d\xd8\xda" +
-	"$\xf9\x8c\x92\xfcV2L4I^(\xc9g\xc9\xb0" +
-	"\xb3I\xf2w*\xc9\x07\xf5\xe6K\xbe\xb5\xde|\xc9W" +
-	"\x94P\xcbd\xb8\xafI\xf2\xf7*e\xef%\xc3\xc1&" +
-	"\xc9\x7f^\x19\xfcB\xec.\xe1\x96a\xdaI\xc2] " +
-	"-\x12\xf6\x1fl\x8bH\xb8;.\x90\xb0\xffD\xfc_" +
-	"\x94p\xfb\xb6^[\xa9k+@\xc3\x1c\xb6\xaff\x0e" +
-	"c\xc3c\x9c\xc61\xa31\x8c\x0d/\xa6\xe4\xe4\x08\xb0" +
-	"\xc5w}\x7f&\xab\xbd\xdeq\xea\x1b\xd5\x7f|\x03(" +
-	"\x0c\xb7%\xadr\x09V\x1d\xfe\x99\\\xb0\xb4\xa9yE" +
-	"\x9b\xf7\xedt\xb8\x8d\xa3\xd8^\xdb\xdbi\xf3\x9e\xa4%" +
-	"3[\xfd\xc0\x8e/)\xc3\x9c\xb1>j\xc8\"`\xf0" +
-	"\xf8Q\xc3\xcd\xa9o\xca\x84\xec\xef\x98\xd5\xdaN\x90V" +
-	"K\xc1\x7f\xf3\xbd\xf5\xef\x00\x00\x00\xff\xff\xb3\xde\xb9R"
+func (p ColibriRequestPayload_Promise) HopByHop() HopByHopFields_Promise {
+	return HopByHopFields_Promise{Pipeline: p.Pipeline.GetPipeline(1)}
+}
+
+const schema_a3bf9fed859570f0 = "x\xda\xd4Xkl\x14\xd7\xf5?g\xee\xbel\xb0\xbd" +
+	"\xc3l\x14\xc2_\xf9\xbbE!\"\x88\x02\xb1Q\xdbP" +
+	"\x1a\xa7~\xa46%\xad\xaf\xd7(\x84\x82\xc2\xe0\xbd\x8e" +
+	"\xa7]\xcf\x8cg\xc61T\xa1\x10J\xa4 h\xf3P" +
+	"h\xe1\x03)\xa4\xa0\xe2\x96*\xa1\x0aR\x88\xa0/\x05" +
+	"\xf1\x08R?4M\xd2(U\xdfBJJ\x95 \x95" +
+	"\xd4\xa10\xd5\xb93;\xb3\xbb\xde\x8d\xf3\xa1_\xfa\xcd" +
+	"{\x7f\xbf9\xf7\xdcs~\xe7\x9es\xbd\xccL\xde\xa3" +
+	"\xdc\x99\xbc\x94\x02\xe0\xfd\xc9\x94\xbfsj\xf5\xfa\x1f\xff" +
+	"\xea\xf5\xdd\xc0\x9b\x11\xfd\xf7\xec\xbd\x8f]\xfe\xfe\xcf\x7f" +
+	"\x00\x894@\xfb\x03l\x11j\x06K\x03h\x82u\x00" +
+	"\xfa'n\\z\xf3\xca\xb9\x7f|\x07\xd4\xe62nR" +
+	"!\xf2.6\x0f\xb5\xfd\x92\xbc\x97M\x00\xfao\x0c\x9f" +
+	"\xfe\xb2\xb3\xf1\xef\xdf\xad\"#1\xdeg\xefh\xd7%" +
+	"wJ\x1a~p\xfe\xa6\xbf\xdc\xfd\xea\xd5\xef\x01\xff?" +
+	"D\xff\xd7\xde\x86\xf6o\xad\xcf\xbf\x0b7\xb14\x02\xb4" +
+	"\xdf\x9a\x98\x87\x80\xda\x82\x04Q\xbf\xb9\xf0\xdd\xc3?\xf3" +
+	"F\x9f\xabr8I\xd6\xda{\x12sP[#\x9d\xe7" +
+	"\x89\xfb\x11\xd0\x7f3s\xf1\xe8\x9f\xdf~\xfdp-\xba" +
+	"v:\xf9\x8ev>I\x7f\x9dI^\x02\xf4G\xce\xcc" +
+	"\xfa\xea\xe5/}\xeeH\xa5\xcb\xd2\xdc\x91\xd4\"\xd4N" +
+	"\xa4\xd2\xc0\xfc\x97\x17\xfeu\xf7\xe7\x9f\xcfO\xd6\x8a\xc2" +
+	"S\xa9\xf9\xa8\x1dJ\x91\xc9\x03)\x8a\xc2\x91\xa5K\xce" +
+	"\x0e\xf2\xe5?\xaa\xda\xbf\x07\xd3\x0c\xa0}*5\x07\xb5" +
+	"\x864\xd1\x93\xe9\xe7\x01\xfd\xdb\x7f\xbf\xe1\x899\xfe\xb1" +
+	"S5\xd2\xa1\x1dH\xbf\xa5MJ\xee\x914\x99>6" +
+	"\xb5\xf0\x83\xf5_\xb9\xfd\x95Z\xa9Kf\xd6\xa1vK" +
+	"\x86\xc87e&\x00\xaf\xb7<\xfd\xc3/^\x1c;S" +
+	"\xe5\x85\x92f\xa8\xb4\x8fe\xe6\xa3\xf6(\x91\xdb\xb7f" +
+	"Z\x11\xd0?45\xf8\xde\x137?y\x16\xd4f%" +
+	"\xa6\x03\xb6\xefo\x98\x8f\xdad\x83t\xa2\xe13\x80\xfe" +
+	"\xca\x0bw\xfd\x7f\xc7\xe8\xdf~S\x1d_Js\xfbd" +
+	"C#j'%\xfbD\xc3\x04\xe0\xb5\xce\xf7\xdb>}" +
+	"m\xf7ok\x1cNm\xbc\xa2\xdd\xdaH\x7f\xdd\xd2H" +
+	"\x87\xdb\xba\xf6\xf2\xef\x96.=\xfb\xc7\xe9q\x9b\x05\xa0" +
+	"\x8d7\xbe\xacm%v\xfb\x96\xc6?(\x80~\xb7s" +
+	"\xee\xedc\x0f\x8e\xfe\xa96}\xb2\xe9\xa7\xda\xf1&\xa2" +
+	"\xff\xa4\xc9R\xa0LaUtFY\xd16d\xdf\xd2" +
+	"\x8c\xac\x94}\x96d\xf1\xec\xd8\xdd\x0bnc\xaf^\xa9" +
+	"\xad\xce\xa4*\xd5\xd9\xa4R\xfev\xce\xbd\xf9\xd9\x1do" +
+	"|\xf2j\x0dmh\xa7\xd5\x0f\xb5\xf3\xaaT\x9bJG" +
+	"\x1c\xb2\x8a\xc6&\xc7X\xc2\x86t\xdb\xb4W\xe4\xc5C" +
+	"\xa3\xc2\xf4\x06\x85\xee\x14\xac\x09s@\xb8-\xdd\xba\xa7" +
+	"\xf7#\xf2\x04K\x00$\x10@m\x1a\x00\xe0\xb3\x19\xf2" +
+	"\xb9\x0a\xfa\xc2q,\xa7\xcb*\x00\x0aL\x81\x82\xa9\xba" +
+	"F\x07\x84+\x9c\x87u\xcf\xb0L\xd6\xd7M&3\x91" +
+	"\xc9;\x16\x01\xf0\xdb\x18\xf2e\x0a\xaa\x889\xa4\xc5O" +
+	"\xad\x00\xe0\x0b\x19\xf2\xe5\x0a\xb6\xe8\xaeQ\xc0&P\xb0" +
+	"\x09\xb0\xc3\x1d\x1f\x1e66\x97~F\xfb)\xc1~=" +
+	"m=]E\xa1\x9b\xe3v-\xe7\x9d2\xe7\x9d\xd0%" +
+	"h5,\xb3\xaf\x1b\xb3q\xec\x001;\xfd(=m" +
+	"=y\xe1\x8d\xdbdw\x89;>4$\x98\xeb\x92\xf5" +
+	",\xe6P!\xf3m\x00<\xc3\x90\xe7\x14l\xf5\xac\xaf" +
+	"\x0bs\x9a\x97\x95Q\x91\xe6\x06\xc4X\xb7\xee\xa1tu" +
+	"n\xe4\xea~\xb2\xf5\x0cC~\xb0,(\x07hq\x1f" +
+	"C~XAUQ\x82]\x0f\xad\x02\xe0\x07\x19\xf2c" +
+	"\x0a\"\xcb!\x03P'\xd7\x01\xf0\xa3\x0c\xf9\x8b\x0a\xaa" +
+	"\x09\xcca\x02@=N\xc4\x17\x18\xf2S\x0a\xaaI%" +
+	"\x87I\x00\xf5\xe4\x0e\x00\xfe\x12C\xfe\x9a\x82\xad\xa3\x86" +
+	"\xd9y\x7f)\x93\xad\xa3\xfa\xe6\xf8\x97\xef\xdaE\xc3\xeb" +
+	"*\xba\x00\x10\xafy\xba\xe3\xf5;\x160\xdb\xc5l|" +
+	"w\x84\xf1\x13f\xa1\xdf\xb1l\xf9\xc9tT/\x16\xad" +
+	"!\xdd3\xd02\x07\x1d\xdd(\x026\x03\xf63\xc4l" +
+	"\xa9J\x01\xb1\xb9,va\x86{-\xbbsK\xafe" +
+	"\xdfk\xb4\x88b\xc1\xa5\xb0e\xa3\xb0\xe9$\xcf\x8d\x0c" +
+	"y\xb1,l\x06-\x8e0\xe4^Y\xd8\xc6HuE" +
+	"\x86|s\x1c\xb6\xf1M\x00\xdcc\xc8\xb7+\xe8\xbbA" +
+	"\x8e\xfa\x00I\x1bQ\xe7\x09\xbd7\xcca\xeb^C\x14" +
+	"\x01#e\xb6\xd8\xba7\x12g|\xdcq(\xc5\x90\xf6" +
+	"\x84=Cu\xc4%7VRm]fQ\xb8\x81\x0a" +
+	"\xd3\xa1\xbc\xcb\x0a\xa9m\x86Bju\xe9K\xcc\xc6=" +
+	",8M\xc7&\xdd\x15}\xb5\x8e\xf9\x91\xbau#\xdd" +
+	"\xcef\x89\xd9\xbe/\x9d\xe8!'\xeea\xc8W+\xd8" +
+	"\x847\xfc\xc0\x8b\xbeN\x00\xde\xcd\x90\xf7+\xd8\xa4\\" +
+	"\xf7\x83\x1c\xdcG\xdc^\x86|P\xc1\xd6q\xd3\x15\x1e" +
+	"\xa4\xb6\x0d\xebFq\xdc\x11\xd3\xbd\xacSR\xa1,\xfa" +
+	"uo\xa4G\x0a\x8e\xd9\xee\xc7\x89\x0bU\xc3b\x86\xfc" +
+	"\xb3\x0a\xb6\x92\x12\x8b\x88\xa0 \x02\xfa\x9e\xa3\x9b\xee\xb0" +
+	"p\x00 Z+\xed\x96\xa8\x08D\x9fY\x10\x9b\xbb," +
+	"s\xd8pF\xe5\xe5\xd6\xad\xb3\x8f\x97\x95\xb6\xb2\xac\x18" +
+	"d%*;\xd7\xd3=\x81-q\x0b\x04\xc4\x96\xe9\xa9" +
+	"\xe8\x0a~\x0e\x88\xb1q\xe1z\xfd\xfa\x96b\xda\xd2\x0b" +
+	"\xb4u.\xdaz+\x09\xff\x11\x86\xfcq\xca\x85\x1f\xe6" +
+	"\xe21\xda{;C\xbe\x87rq#\xcc\xc5.\xca\xd0" +
+	"N\x86\xfcI\x05\x9b\xd8u?\xa8\x88oS\x94\xf60" +
+	"\xe4\xfb\xca.\x92\xbd\xab\xe2\xbb\xc9\xf7\x8cQ\xe1z\xfa" +
+	"(\xa0\x8d\x19P0\x03q*\x9d\xc09\xcc\xc6\xed1" +
+	"\xd4\x95#\\\xdb2]\x11\\\x0dQ\xaf\x0d\xd1\x91\xb0" +
+	"\xc4\x034\x9a\xa0jk\xb2\xac\xb5\xc8t\xe4\xbd\xb4\xee" +
+	"\x09\x99\x03y0\xb5\x93>T\x1bV\x00l\xb3\x85Y" +
+	"0\xcc\x87:\xf4!\xcfxX\xd4Qw\xd8?\xa8\x10" +
+	"\x01\xaar9/\xcee\xadT2\xa30\xbd\x88*\xf3" +
+	"[\xad\xdb/\x84\xd7\xa0e\xb6v\x0a\xbdP-\xdd\xce" +
+	"\x19\xc4\xb3M^\xa3uo\xed\xd2fX\x8aUG\x10" +
+	"w\xdaeyT\xb4\xda\x06l\x03\xc8\xafE\x86\xf9\x02" +
+	"\x96\xd5\xad\xa6\xe3\xd7\x00\xf2\x1b\x09(bY\xe9j\x06" +
+	"~\x03 ?B\x80G\x00\xfbw\xa0\x18m\x0c\x9f\x06" +
+	"\xc8{\x04l' q\xcd\x97\xaa\xd1\xb6\xe2s\x00\xf9" +
+	"\xed\x04\xec! \xf9\xa1/[\x90\xb6\x0bw\x00\xe4\x1f" +
+	"'\xe0\x19\x02RS~\x0eS\x00\xdaSx\x01 \xbf" +
+	"\x8f\x80\xc3\x04\xa4\xff\xe5\xe7\xe4\xf4~Hn~\x90\x80" +
+	"c\x04d>\xf0s\x98\xa19\x0bW\x01\xe4\x8f\x12\xf0" +
+	"\"\x01\x0dW\xfd\x1c6\x00h\xc7q\x1d@\xfe\x05\x02" +
+	"N\x11\xd0\xf8O?\x87\x8d\x00\xdaI\x09\xbcD\xc0+" +
+	"\xa8\xa0:+\x9bC\x9a\xd8~)-\xfd\x82\xd6/\xd2" +
+	"\xfal\x96\xc3\xd9\x00\xday\x1c\x00\xc8\x9f\xa3\xf5\xd70" +
+	"\xbe\xb5J\xdd\"\x0f-\xe1\x15\x1b\xcd\xdd\xa1jC\xc2" +
+	"\x00t\x08SL\xe8\xc5\xfa\x94A,]\xf2h\xcf\xc4" +
+	"\x1a\x10\xa6H\xcfd-h.TJ\xd1c\xab\x92\xd3" +
+	"\xa7T\xdfc\xb2\xf0\xa2\xf9\xbe\x92\xdd\x05\x1dA\x85`" +
+	"6\x9e\xbeC\x8ah\x13\xb29\x04\x95\x1bM\xaa1J" +
+	"\x0eO\x00\xd3\x8b\xb5aY{\xc0\xa4\xed\xe8\xfd\x16\xc2" +
+	"\xfa\xd0\x90\xb0=Q(\xbf\x96\xa9Y\x88B\xaf\x05h" +
+	"\xd7\xd7|kpE\"\xf2\xc5\xb1\xe4\x17H\xc9\x7f\x82" +
+	"\x12\xb9\xb8B\xf2wH\xc9/$`y\x85\xe4\xef\x94" +
+	"\xaa[F\xc0\xca\x0a\xc9\xdf%%\xbf\x92\x80\xde\x0a\xc9" +
+	"\xf7H\xc9\xf7\x120X!y.%\xdfO\xc0\xfa\x0a" +
+	"\xc9?\x80\x17*\xea-\x92|u\xbdE\x92\x1f\x93B" +
+	"\xb5\x09x\xa4B\xf2[\xa4\xb27\x13\xb0\xb3B\xf2\x8f" +
+	"\xe2\xba\xf2B\xac/\xe1\xaa\xfe[K\xc2u(U\x12" +
+	"\x8e\x1e\xac3H\xb8>/\x96p\xf4D\xfe_\x94\xf0" +
+	"\xf4\xc7J\xf8\xa2\x08\xa7\x86\xb2A\xd6\x89\x07\xd9\xb8u" +
+	"\x1bt\xf3\x17\x18r;h\xddX\xf6\x9f\x0bu\xb4\x13" +
+	"\x14\xea\xdcX\xf6`T\xd7t\x822\xf3S'\xea\xd8" +
+	"\xf2Y\xe3\xba\xa5!\xec#\x9f@\x92\xc3\x1cA\xd3_" +
+	"V\x09\xe6\x85\x9e\x81x\xceS1\x9c\x17\xee\xa3\xc5\xd5" +
+	"\x0c\xf9\xdap\xfe\xa6\x87\xc7\x1a\x9aK\xfb\x83\x03\xd6|" +
+	"H\xd6\x98\xb0;dssK\x0f\x05b6\xd7\x8cj" +
+	"8\x13t\xc8\xa1\xe0\xbf\xf9\xdc\xfc\xcf\x00\x88]\x0a\xb7"
 
 func init() {
 	schemas.Register(schema_a3bf9fed859570f0,
@@ -2608,6 +2762,7 @@ func init() {
 		0x96ec60724ebd66d7,
 		0x97f6cb3ee362225f,
 		0xa26d74bea4eb287e,
+		0xa4d6dce2a8cc08d8,
 		0xa53b4bed5b0bc568,
 		0xa953af3d8be428ba,
 		0xaa345154c72e2fa5,
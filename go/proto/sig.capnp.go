@@ -177,12 +177,12 @@ type SIGPoll struct{ capnp.Struct }
 const SIGPoll_TypeID = 0x9ad73a0235a46141
 
 func NewSIGPoll(s *capnp.Segment) (SIGPoll, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
 	return SIGPoll{st}, err
 }
 
 func NewRootSIGPoll(s *capnp.Segment) (SIGPoll, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3})
 	return SIGPoll{st}, err
 }
 
@@ -229,12 +229,51 @@ func (s SIGPoll) SetSession(v uint8) {
 	s.Struct.SetUint8(0, v)
 }
 
+func (s SIGPoll) PubKey() ([]byte, error) {
+	p, err := s.Struct.Ptr(1)
+	return []byte(p.Data()), err
+}
+
+func (s SIGPoll) HasPubKey() bool {
+	p, err := s.Struct.Ptr(1)
+	return p.IsValid() || err != nil
+}
+
+func (s SIGPoll) SetPubKey(v []byte) error {
+	return s.Struct.SetData(1, v)
+}
+
+func (s SIGPoll) Prefixes() (SIGPrefix_List, error) {
+	p, err := s.Struct.Ptr(2)
+	return SIGPrefix_List{List: p.List()}, err
+}
+
+func (s SIGPoll) HasPrefixes() bool {
+	p, err := s.Struct.Ptr(2)
+	return p.IsValid() || err != nil
+}
+
+func (s SIGPoll) SetPrefixes(v SIGPrefix_List) error {
+	return s.Struct.SetPtr(2, v.List.ToPtr())
+}
+
+// NewPrefixes sets the prefixes field to a newly
+// allocated SIGPrefix_List, preferring placement in s's segment.
+func (s SIGPoll) NewPrefixes(n int32) (SIGPrefix_List, error) {
+	l, err := NewSIGPrefix_List(s.Struct.Segment(), n)
+	if err != nil {
+		return SIGPrefix_List{}, err
+	}
+	err = s.Struct.SetPtr(2, l.List.ToPtr())
+	return l, err
+}
+
 // SIGPoll_List is a list of SIGPoll.
 type SIGPoll_List struct{ capnp.List }
 
 // NewSIGPoll creates a new list of SIGPoll.
 func NewSIGPoll_List(s *capnp.Segment, sz int32) (SIGPoll_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 3}, sz)
 	return SIGPoll_List{l}, err
 }
 
@@ -368,33 +407,114 @@ func (p SIGAddr_Promise) Data() HostInfo_Promise {
 	return HostInfo_Promise{Pipeline: p.Pipeline.GetPipeline(1)}
 }
 
-const schema_8273379c3e06a721 = "x\xdat\x91Ok\x1aQ\x14\xc5\xefy\xcf\x19\xffP" +
-	"q\x06]\x09\xa5-X\xaa\x82\xa5J\xa5 \xb4\xd4\x96" +
-	"R\xb2\xf3%\xdb\x1028C\x1c\x98\xe883\xe2R" +
-	"\xc8G0\xbb$\xcb@6Y\xe5{d\x91E\xc8*" +
-	"\x0bWYg\x1f\xf3\xc2c\x12\x95HV\x17\xce\xb9\xdc" +
-	"\xdf;\xef\x18\xe7\xbfY]\xcb\x81H\xbc\xd3t\xd9\xb6" +
-	"N\x9b\xacusD\"\x03\xc8Og\xfa\xaf\x93\x1f\xe1" +
-	"\x01iH\x12\x99\xfbSs\xa4\xe6pL\x98\x97\x8f\xdf" +
-	"\xcf\x1e\xeeo\xcd\xcc\xea\x1aS\xf6\xe5\xd4\xbcV\xf3j" +
-	"L\x90\xa9\x9f\x8d\xb0R\xda\x99\xa9\x83l\xb9\xf9\x0fI" +
-	"\x8eD\xbe\x86i\xbe\xa9n\xe7\xeb\xb8#\xc8\xd0\xdd\xfb" +
-	"\xda\xb5\xfc>\xfc\xd6\xd6\xc6\xff\xce\xc0\x83\xd7\x01D\x8a" +
-	"'\x88\x12 2+U\"Q\xe2\x10\xdf\x18\x80\x02\x94" +
-	"V\xfbC$\xca\x1c\xe2;C\xce\xb2\xed\x00\xc6\xcb\xeb" +
-	"\x080\x08\x93\xd0\x09Cw\xd0\x87N\x0c\xfa\x1a\xa6m" +
-	"\xdb\x08\xde\xc6\x98\x0bNu\x85\xd3\x8d\x02\x0f\x86\xfc\xf8" +
-	"\xf9p\xac})^PL\xca\xd9Vd\xad\xcb\xaf\x80" +
-	"\x7f\xa3 \xcee,\x80V\x91Hls\x88\x1eC\x16" +
-	"R\xc6D\xa7A$v9\x84\xc7\x90e\x8f\xb2\x00F" +
-	"d\xba*\xaf\xcd!|\x86,\x9f\xcb\x02\xb8*G\xa9" +
-	"=\x0e\x111p\xd7F\x9a\x18\xd2\x84\x0f\xa3~\xe8D" +
-	"\xa4O\xfc\x81\xe7m:C\x18\xcb\x8e\x9f\x7f'v\xfc" +
-	"u\xe7)\x00\x00\xff\xff\x12\xacx\x8f"
+type SIGPrefix struct{ capnp.Struct }
+
+// SIGPrefix_TypeID is the unique identifier for the type SIGPrefix.
+const SIGPrefix_TypeID = 0xe15c4ba0a7f1d3b6
+
+func NewSIGPrefix(s *capnp.Segment) (SIGPrefix, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return SIGPrefix{st}, err
+}
+
+func NewRootSIGPrefix(s *capnp.Segment) (SIGPrefix, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1})
+	return SIGPrefix{st}, err
+}
+
+func ReadRootSIGPrefix(msg *capnp.Message) (SIGPrefix, error) {
+	root, err := msg.RootPtr()
+	return SIGPrefix{root.Struct()}, err
+}
+
+func (s SIGPrefix) String() string {
+	str, _ := text.Marshal(0xe15c4ba0a7f1d3b6, s.Struct)
+	return str
+}
+
+func (s SIGPrefix) Ip() ([]byte, error) {
+	p, err := s.Struct.Ptr(0)
+	return []byte(p.Data()), err
+}
+
+func (s SIGPrefix) HasIp() bool {
+	p, err := s.Struct.Ptr(0)
+	return p.IsValid() || err != nil
+}
+
+func (s SIGPrefix) SetIp(v []byte) error {
+	return s.Struct.SetData(0, v)
+}
+
+func (s SIGPrefix) PrefixLen() uint8 {
+	return s.Struct.Uint8(0)
+}
+
+func (s SIGPrefix) SetPrefixLen(v uint8) {
+	s.Struct.SetUint8(0, v)
+}
+
+// SIGPrefix_List is a list of SIGPrefix.
+type SIGPrefix_List struct{ capnp.List }
+
+// NewSIGPrefix creates a new list of SIGPrefix.
+func NewSIGPrefix_List(s *capnp.Segment, sz int32) (SIGPrefix_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 8, PointerCount: 1}, sz)
+	return SIGPrefix_List{l}, err
+}
+
+func (s SIGPrefix_List) At(i int) SIGPrefix { return SIGPrefix{s.List.Struct(i)} }
+
+func (s SIGPrefix_List) Set(i int, v SIGPrefix) error { return s.List.SetStruct(i, v.Struct) }
+
+func (s SIGPrefix_List) String() string {
+	str, _ := text.MarshalList(0xe15c4ba0a7f1d3b6, s.List)
+	return str
+}
+
+// SIGPrefix_Promise is a wrapper for a SIGPrefix promised by a client call.
+type SIGPrefix_Promise struct{ *capnp.Pipeline }
+
+func (p SIGPrefix_Promise) Struct() (SIGPrefix, error) {
+	s, err := p.Pipeline.Struct()
+	return SIGPrefix{s}, err
+}
+
+const schema_8273379c3e06a721 = "x\xdad\x92\xbbk\x14Q\x18\xc5\xcf\xf9\xee>\x92\xc0" +
+	"fg\xd8\xad\x16D\x85\x88\x89\xa8hP\x94\x05\x1f\x89" +
+	"\x88\xc4(\xec\x8dm\x10\xc7\xcc$\x19\x18w\xc7\x99\x0d" +
+	"\x89U@\x10\xb4\xb0Y+_\xa0\"\xa4\xb7\xf0\x1f\xb1" +
+	"\xb0\xb2H!\xc1\xc2\"\xbd\xc9\x95\x99lv\xc2l5" +
+	"\x97s\x0fs\xbe\xf3\xfd\xae\xb5\x7fS.\x16W\x08h" +
+	"\xabX23\xce\xd7\xcb\xd2\xfc\xf9\x0ez\x8c4'\xb7" +
+	"J\xd7?\\\x89\x9f\xa3\xa8\xca\x80\xfd\xb6g\x7fN\xbe" +
+	"\x1fw\xc0\xbd\xc9\xf7\xc7\xb6\xff\xed\xfe\xb2\xc7\x8e\xda\xa4" +
+	"\x0c\xd4^\xb0W{\xcd\xe4\xf4\x8a\xeb\xa0\xf9\xfecw" +
+	"\xeb\xd3\xfc\xe2v\xfe\x9f\xa9\xe57\xbf\xd4\xfe\xa6\xa7?" +
+	"\xa9y\xe4\xdat<5\xf105Kf\xbe\xcd\xb2b" +
+	"\xa16'\xbd\x9aNC\xee\xcb\x0ehb\x7f\xe5\xfc\x92" +
+	"\x13\xb6\x196\x1f\xcc\xddiu\x02\x06-R[\xaa\x00" +
+	"\x14\x08\xd8\xce\x19@/*\xeaU!Yg\xa2y\xb3" +
+	"\x80~\xa4\xa8\x03\xa1-\xacS\x00\xdbo\x02\xdaU\xd4" +
+	"\xa1\xd0VR\xa7\x02\xec'w\x01\x1d(\xea\x97\xc2\xaa" +
+	"\xe3\xba\x11\xad\xc3\xde -p3\xf6\xe2\xd8\xef\xb4Y" +
+	"\x82\xb0\x04\xde\x08\xd7\x1e\xcf{\xcfX\x81\xb0\x02\x9a0" +
+	"\xf2\x96\xfd\x0d/\x06\xc0q\xb0\xa5H+\xdb\x07\xc8\xf1" +
+	"\xa1\x123\xae\xcb()12(1\x95\x94\x98P\xd4" +
+	"\x17\x84\xf6a\x8bs\x898\xa9\xa8/\x09\xabK\xdd(" +
+	"\xa0eN\x9cz\xb3^<\xdd\xf8\x86\x83\xe9\xaa\xae\xd3" +
+	"u\x86\xe5\xfc\xd6\"\xaf\xbc\xeco\xe4\"\x1bY\xe4 " +
+	"q\x01\xd0g\x15\xf5U\xa1\xf2\xc3\\\xc9{\x1e8\xd8" +
+	"C.\xe2V7\x1a\x02\xd3\xc8\xc0ThL\x1f\xcdt" +
+	"\x86\xa6\"\xfb\xa6\xcff6cSQ{\xa6\x0f'Q" +
+	"W\x15u7\x19\xc7\xe5(\x84\xa3\xe0\xf1\xb5v\xecu" +
+	"Q\xda\x0c;A\xb0\xe0=\xa5\x95=\xea>\xb4\x83\x9b" +
+	"p\xf8\xe6\xff\x00\xa3\xa5\xae+"
 
 func init() {
 	schemas.Register(schema_8273379c3e06a721,
 		0x9ad73a0235a46141,
 		0xddf1fce11d9b0028,
+		0xe15c4ba0a7f1d3b6,
 		0xe15e242973323d08)
 }
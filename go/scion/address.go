@@ -0,0 +1,100 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet/addrutil"
+)
+
+// addressResult is the JSON representation of the local address.
+type addressResult struct {
+	IA      addr.IA `json:"isd_as"`
+	IP      net.IP  `json:"ip"`
+	Address string  `json:"address"`
+}
+
+func newAddress(pather CommandPather) *cobra.Command {
+	var flags struct {
+		sciond  string
+		timeout time.Duration
+		json    bool
+	}
+
+	var cmd = &cobra.Command{
+		Use:   "address",
+		Short: "Show the SCION address of this host",
+		Example: fmt.Sprintf(`  %[1]s address
+  %[1]s address --json`, pather.CommandPath()),
+		Long: `'address' shows the SCION address of this host.
+
+The ISD-AS is the local ISD-AS as reported by the SCION Daemon. The IP is the
+address of this host that is used to reach the control service of the local
+AS. On hosts with multiple addresses, this is one of possibly many SCION
+addresses.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			ctx, cancelF := context.WithTimeout(context.Background(), flags.timeout)
+			defer cancelF()
+			sd, err := sciond.NewService(flags.sciond).Connect(ctx)
+			if err != nil {
+				return serrors.WrapStr("connecting to SCION Daemon", err)
+			}
+			defer sd.Close(ctx)
+			ia, err := sd.LocalIA(ctx)
+			if err != nil {
+				return serrors.WrapStr("determining local ISD-AS", err)
+			}
+			cs, err := sciond.TopoQuerier{Connector: sd}.UnderlayAnycast(ctx, addr.SvcCS)
+			if err != nil {
+				return serrors.WrapStr("resolving control service", err)
+			}
+			ip, err := addrutil.ResolveLocal(cs.IP)
+			if err != nil {
+				return serrors.WrapStr("determining local IP", err)
+			}
+			address := fmt.Sprintf("%s,%s", ia, ip)
+			if !flags.json {
+				fmt.Println(address)
+				return nil
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.SetEscapeHTML(false)
+			return enc.Encode(addressResult{IA: ia, IP: ip, Address: address})
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress, "SCIOND address")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 5*time.Second, "timeout")
+	cmd.Flags().BoolVarP(&flags.json, "json", "j", false,
+		"write the output as machine readable json")
+
+	return cmd
+}
@@ -0,0 +1,401 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/pkg/app"
+	"github.com/scionproto/scion/go/pkg/bwtest"
+)
+
+// bwtestResult is the JSON representation of a bandwidth test run.
+type bwtestResult struct {
+	Path           bwtestPath      `json:"path"`
+	ClientToServer bwtestDirection `json:"client_to_server"`
+	ServerToClient bwtestDirection `json:"server_to_client"`
+}
+
+// bwtestPath describes the path that is used for the bandwidth test.
+type bwtestPath struct {
+	Fingerprint string      `json:"fingerprint"`
+	Hops        []bwtestIfc `json:"hops"`
+	NextHop     string      `json:"next_hop,omitempty"`
+}
+
+type bwtestIfc struct {
+	IfID common.IFIDType `json:"ifid"`
+	IA   addr.IA         `json:"isd_as"`
+}
+
+// bwtestDirection is the result of one direction of the bandwidth test.
+// Bandwidths are in bit/s.
+type bwtestDirection struct {
+	Duration   durationMillis `json:"duration_ms"`
+	PacketSize int            `json:"packet_size"`
+	Rate       uint64         `json:"target_rate"`
+	Sent       int            `json:"sent"`
+	Received   int            `json:"received"`
+	Loss       float64        `json:"loss"`
+	Throughput uint64         `json:"throughput"`
+	Jitter     durationMillis `json:"jitter_ms"`
+}
+
+func newBwtestPath(path snet.Path, remote *snet.UDPAddr) bwtestPath {
+	res := bwtestPath{
+		Fingerprint: "local",
+		Hops:        []bwtestIfc{},
+	}
+	if len(path.Interfaces()) > 0 {
+		res.Fingerprint = snet.Fingerprint(path).String()[:16]
+	}
+	if remote.NextHop != nil {
+		res.NextHop = remote.NextHop.String()
+	}
+	for _, ifc := range path.Interfaces() {
+		res.Hops = append(res.Hops, bwtestIfc{IA: ifc.IA(), IfID: ifc.ID()})
+	}
+	return res
+}
+
+func newBwtestDirection(r bwtest.Result) bwtestDirection {
+	return bwtestDirection{
+		Duration:   durationMillis(r.Duration),
+		PacketSize: r.PacketSize,
+		Rate:       uint64(r.Rate),
+		Sent:       r.Sent,
+		Received:   r.Received,
+		Loss:       r.Loss(),
+		Throughput: uint64(r.Throughput()),
+		Jitter:     durationMillis(r.Jitter),
+	}
+}
+
+func formatBwtestResult(w io.Writer, name string, r bwtest.Result) {
+	fmt.Fprintf(w, "%s (%s):\n", name, r.Parameters)
+	fmt.Fprintf(w, "  %d packets sent, %d received, %.2f%% loss\n",
+		r.Sent, r.Received, 100*r.Loss())
+	fmt.Fprintf(w, "  achieved throughput: %.3f Mbit/s, jitter: %s\n",
+		float64(r.Throughput())/1e6, r.Jitter.Round(time.Microsecond))
+}
+
+func newBwtest(pather CommandPather) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "bwtest",
+		Short: "Measure the bandwidth to a remote SCION host",
+		Long: `'bwtest' measures the achievable bandwidth between a client and a server.
+
+The server is started with 'bwtest server'. The client, started with
+'bwtest client', requests a test session from the server, and both then send
+test packets at the target rate for the test duration. For both directions,
+the achieved throughput, the packet loss and the jitter are reported.
+`,
+	}
+	cmd.AddCommand(
+		newBwtestClient(pather),
+		newBwtestServer(pather),
+	)
+	return cmd
+}
+
+func newBwtestClient(pather CommandPather) *cobra.Command {
+	var flags struct {
+		interactive bool
+		local       net.IP
+		refresh     bool
+		sequence    string
+		policy      string
+		sciond      string
+		dispatcher  string
+		timeout     time.Duration
+		duration    time.Duration
+		packetSize  int
+		rate        bwtest.BitRate
+		reverseRate bwtest.BitRate
+		json        bool
+		scmpAuth    bool
+
+		features []string
+	}
+	flags.rate = 1000 * 1000
+
+	var cmd = &cobra.Command{
+		Use:   "client [flags] <remote>",
+		Short: "Run a bandwidth test against a bwtest server",
+		Example: fmt.Sprintf(`  %[1]s bwtest client 1-ff00:0:110,10.0.0.1
+  %[1]s bwtest client 1-ff00:0:110,[10.0.0.1]:30100 --duration 10s --rate 50Mbps
+  %[1]s bwtest client 1-ff00:0:110,10.0.0.1 -s 1200 --reverse-rate 10Mbps --json`,
+			pather.CommandPath()),
+		Long: fmt.Sprintf(`'client' runs a bandwidth test against a bwtest server.
+
+The test packets are sent in both directions at the target rate (--rate) for
+the test duration (--duration). The rate of the server to client direction can
+be set separately with --reverse-rate. If the port of the remote address is not
+set, the default port %d is used.
+`, bwtest.DefaultPort),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, err := snet.ParseUDPAddr(args[0])
+			if err != nil {
+				return serrors.WrapStr("parsing remote", err)
+			}
+			if remote.Host.Port == 0 {
+				remote.Host.Port = bwtest.DefaultPort
+			}
+			features, err := parseFeatures(flags.features)
+			if err != nil {
+				return err
+			}
+			policy, err := app.PathPolicy(flags.policy, flags.sequence)
+			if err != nil {
+				return err
+			}
+			reverseRate := flags.rate
+			if flags.reverseRate != 0 {
+				reverseRate = flags.reverseRate
+			}
+			cs := bwtest.Parameters{
+				Duration:   flags.duration,
+				PacketSize: flags.packetSize,
+				Rate:       flags.rate,
+			}
+			sc := bwtest.Parameters{
+				Duration:   flags.duration,
+				PacketSize: flags.packetSize,
+				Rate:       reverseRate,
+			}
+			cmd.SilenceUsage = true
+
+			// In JSON mode, only the result is written to stdout.
+			var out io.Writer = os.Stdout
+			if flags.json {
+				out = ioutil.Discard
+			}
+
+			ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+			defer cancelF()
+			sd, err := sciond.NewService(flags.sciond).Connect(ctx)
+			if err != nil {
+				return serrors.WrapStr("connecting to SCION Daemon", err)
+			}
+			keys, err := scmpKeys(ctx, sd, flags.scmpAuth)
+			if err != nil {
+				return err
+			}
+
+			info, err := app.QueryASInfo(context.Background(), sd)
+			if err != nil {
+				return err
+			}
+			path, err := app.ChoosePath(context.Background(), sd, remote.IA,
+				flags.interactive, flags.refresh, policy)
+			if err != nil {
+				return err
+			}
+			if mtu := int(path.Metadata().MTU()); mtu != 0 && flags.packetSize > mtu {
+				return serrors.New("packet size exceeds the path MTU",
+					"packet_size", flags.packetSize, "mtu", mtu)
+			}
+			remote.Path = path.Path()
+			remote.NextHop = path.UnderlayNextHop()
+
+			localIP := flags.local
+			if localIP == nil {
+				target := remote.Host.IP
+				if remote.NextHop != nil {
+					target = remote.NextHop.IP
+				}
+				if localIP, err = addrutil.ResolveLocal(target); err != nil {
+					return serrors.WrapStr("resolving local address", err)
+				}
+				fmt.Fprintf(out, "Resolved local address:\n  %s\n", localIP)
+			}
+			fmt.Fprintf(out, "Using path:\n  %s\n\n", path)
+			local := &snet.UDPAddr{
+				IA:   info.IA,
+				Host: &net.UDPAddr{IP: localIP},
+			}
+
+			ctx = app.WithSignal(context.Background(), os.Interrupt, syscall.SIGTERM)
+			csRes, scRes, err := bwtest.Run(ctx, bwtest.Config{
+				Dispatcher:     reliable.NewDispatcher(flags.dispatcher),
+				Local:          local,
+				Remote:         remote,
+				ClientToServer: cs,
+				ServerToClient: sc,
+				Timeout:        flags.timeout,
+				ErrHandler: func(err error) {
+					fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+				},
+				SCMPKeys: keys,
+				HeaderV2: features.HeaderV2,
+			})
+			if err != nil {
+				return err
+			}
+			if flags.json {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				enc.SetEscapeHTML(false)
+				return enc.Encode(bwtestResult{
+					Path:           newBwtestPath(path, remote),
+					ClientToServer: newBwtestDirection(csRes),
+					ServerToClient: newBwtestDirection(scRes),
+				})
+			}
+			formatBwtestResult(os.Stdout, "Client to server", csRes)
+			formatBwtestResult(os.Stdout, "Server to client", scRes)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, "interactive mode")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", time.Second,
+		"timeout for control messages and outstanding test packets")
+	cmd.Flags().DurationVarP(&flags.duration, "duration", "d", 3*time.Second,
+		"duration of the test")
+	cmd.Flags().IntVarP(&flags.packetSize, "packet-size", "s", 1000,
+		"size of the test packets in bytes")
+	cmd.Flags().VarP(&flags.rate, "rate", "r",
+		"target rate of the test traffic, e.g. 10Mbps")
+	cmd.Flags().Var(&flags.reverseRate, "reverse-rate",
+		"target rate of the server to client direction, defaults to --rate")
+	cmd.Flags().IPVar(&flags.local, "local", nil, "IP address to listen on")
+	cmd.Flags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress, "SCIOND address")
+	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
+		"dispatcher socket")
+	cmd.Flags().BoolVar(&flags.refresh, "refresh", false, "set refresh flag for path request")
+	addPathPolicyFlags(cmd, &flags.sequence, &flags.policy)
+	cmd.Flags().BoolVarP(&flags.json, "json", "j", false,
+		"write the output as machine readable json")
+	addSCMPAuthFlag(cmd, &flags.scmpAuth)
+	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
+		"enable development features "+features{}.supported())
+
+	return cmd
+}
+
+func newBwtestServer(pather CommandPather) *cobra.Command {
+	var flags struct {
+		local      net.IP
+		port       uint16
+		sciond     string
+		dispatcher string
+		maxRate    bwtest.BitRate
+		scmpAuth   bool
+
+		features []string
+	}
+	flags.maxRate = bwtest.DefaultMaxRate
+
+	var cmd = &cobra.Command{
+		Use:   "server [flags]",
+		Short: "Serve bandwidth tests",
+		Example: fmt.Sprintf(`  %[1]s bwtest server
+  %[1]s bwtest server --local 10.0.0.1 --port 40000`, pather.CommandPath()),
+		Long: `'server' serves bandwidth tests until it is interrupted.
+
+If the local address is not set, an address that can reach the control service
+of the local AS is used.
+
+Test sessions with a rate above the maximum rate in either direction are
+rejected.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			features, err := parseFeatures(flags.features)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+			defer cancelF()
+			sd, err := sciond.NewService(flags.sciond).Connect(ctx)
+			if err != nil {
+				return serrors.WrapStr("connecting to SCION Daemon", err)
+			}
+			keys, err := scmpKeys(ctx, sd, flags.scmpAuth)
+			if err != nil {
+				return err
+			}
+			info, err := app.QueryASInfo(ctx, sd)
+			if err != nil {
+				return err
+			}
+			localIP := flags.local
+			if localIP == nil {
+				cs, err := sciond.TopoQuerier{Connector: sd}.UnderlayAnycast(ctx, addr.SvcCS)
+				if err != nil {
+					return serrors.WrapStr("resolving control service", err)
+				}
+				if localIP, err = addrutil.ResolveLocal(cs.IP); err != nil {
+					return serrors.WrapStr("resolving local address", err)
+				}
+			}
+			local := &snet.UDPAddr{
+				IA:   info.IA,
+				Host: &net.UDPAddr{IP: localIP, Port: int(flags.port)},
+			}
+			fmt.Printf("Serving bandwidth tests on %s\n", local)
+
+			ctx = app.WithSignal(context.Background(), os.Interrupt, syscall.SIGTERM)
+			return bwtest.Serve(ctx, bwtest.ServerConfig{
+				Dispatcher: reliable.NewDispatcher(flags.dispatcher),
+				Local:      local,
+				ErrHandler: func(err error) {
+					fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+				},
+				SessionHandler: func(remote *snet.UDPAddr, cs, sc bwtest.Parameters) {
+					fmt.Printf("Test session with %s,%s: client to server (%s), "+
+						"server to client (%s)\n", remote.IA, remote.Host, cs, sc)
+				},
+				MaxRate:  flags.maxRate,
+				SCMPKeys: keys,
+				HeaderV2: features.HeaderV2,
+			})
+		},
+	}
+
+	cmd.Flags().IPVar(&flags.local, "local", nil, "IP address to listen on")
+	cmd.Flags().Uint16Var(&flags.port, "port", bwtest.DefaultPort, "port to listen on")
+	cmd.Flags().Var(&flags.maxRate, "max-rate",
+		"maximum rate of the test traffic in either direction")
+	cmd.Flags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress, "SCIOND address")
+	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
+		"dispatcher socket")
+	addSCMPAuthFlag(cmd, &flags.scmpAuth)
+	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
+		"enable development features "+features{}.supported())
+
+	return cmd
+}
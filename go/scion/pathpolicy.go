@@ -0,0 +1,29 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// addPathPolicyFlags adds the flags that restrict the paths to the remote.
+func addPathPolicyFlags(cmd *cobra.Command, sequence, policy *string) {
+	cmd.Flags().StringVar(sequence, "sequence", "",
+		`space separated list of hop predicates the path must match, e.g.
+"1-ff00:0:110#0 0* 2-ff00:0:220#0". See the path policy sequence documentation
+for the syntax.`)
+	cmd.Flags().StringVar(policy, "policy", "",
+		"file with the path policy the path must match (JSON or YAML)")
+}
@@ -42,19 +42,23 @@ func newPing(pather CommandPather) *cobra.Command {
 		interactive bool
 		local       net.IP
 		refresh     bool
+		sequence    string
+		policy      string
 		sciond      string
 		dispatcher  string
 		timeout     time.Duration
 		maxMTU      bool
+		scmpAuth    bool
 
 		features []string
 	}
 
 	var cmd = &cobra.Command{
-		Use:     "ping [flags] <remote>",
-		Short:   "Test connectivity to a remote SCION host using SCMP echo packets",
-		Example: fmt.Sprintf("  %[1]s ping 1-ff00:0:110,10.0.0.1", pather.CommandPath()),
-		Args:    cobra.ExactArgs(1),
+		Use:   "ping [flags] <remote>",
+		Short: "Test connectivity to a remote SCION host using SCMP echo packets",
+		Example: fmt.Sprintf(`  %[1]s ping 1-ff00:0:110,10.0.0.1
+  %[1]s ping 1-ff00:0:110,10.0.0.1 --sequence "0* 1-ff00:0:112 0*"`, pather.CommandPath()),
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			remote, err := snet.ParseUDPAddr(args[0])
 			if err != nil {
@@ -64,6 +68,10 @@ func newPing(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			policy, err := app.PathPolicy(flags.policy, flags.sequence)
+			if err != nil {
+				return err
+			}
 			cmd.SilenceUsage = true
 
 			ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
@@ -72,13 +80,17 @@ func newPing(pather CommandPather) *cobra.Command {
 			if err != nil {
 				return serrors.WrapStr("connecting to SCION Daemon", err)
 			}
+			keys, err := scmpKeys(ctx, sd, flags.scmpAuth)
+			if err != nil {
+				return err
+			}
 
 			info, err := app.QueryASInfo(context.Background(), sd)
 			if err != nil {
 				return err
 			}
 			path, err := app.ChoosePath(context.Background(), sd, remote.IA,
-				flags.interactive, flags.refresh)
+				flags.interactive, flags.refresh, policy)
 			if err != nil {
 				return err
 			}
@@ -147,6 +159,7 @@ func newPing(pather CommandPather) *cobra.Command {
 						update.Size, update.Source.IA, update.Source.Host, update.Sequence,
 						update.RTT, additional)
 				},
+				SCMPKeys: keys,
 				HeaderV2: features.HeaderV2,
 			})
 			pingSummary(stats, remote, time.Since(start))
@@ -164,6 +177,7 @@ func newPing(pather CommandPather) *cobra.Command {
 	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
 		"dispatcher socket")
 	cmd.Flags().BoolVar(&flags.refresh, "refresh", false, "set refresh flag for path request")
+	addPathPolicyFlags(cmd, &flags.sequence, &flags.policy)
 	cmd.Flags().DurationVar(&flags.interval, "interval", time.Second, "time between packets")
 	cmd.Flags().Uint16VarP(&flags.count, "count", "c", 0, "total number of packets to send")
 	cmd.Flags().UintVarP(&flags.size, "payload_size", "s", 0,
@@ -175,6 +189,7 @@ the SCION path.`,
 		`choose the payload size such that the sent SCION packet including the SCION Header,
 SCMP echo header and payload are equal to the MTU of the path. This flag overrides the
 'payload_size' flag.`)
+	addSCMPAuthFlag(cmd, &flags.scmpAuth)
 	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
 		"enable development features "+features{}.supported())
 
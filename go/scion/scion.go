@@ -45,8 +45,12 @@ func main() {
 	cmd.AddCommand(
 		command.NewCompletion(cmd),
 		command.NewVersion(cmd),
+		newAddress(cmd),
+		newBwtest(cmd),
 		newPing(cmd),
+		newSciond(cmd),
 		newShowpaths(cmd),
+		newTraceroute(cmd),
 	)
 
 	if err := cmd.Execute(); err != nil {
@@ -0,0 +1,263 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/proto"
+)
+
+// sciondASInfo is the JSON representation of an AS info entry.
+type sciondASInfo struct {
+	IA     addr.IA `json:"isd_as"`
+	MTU    uint16  `json:"mtu"`
+	IsCore bool    `json:"core"`
+}
+
+// sciondIFInfo is the JSON representation of an interface info entry.
+type sciondIFInfo struct {
+	IfID    common.IFIDType `json:"ifid"`
+	Address string          `json:"address"`
+}
+
+// sciondSVCInfo is the JSON representation of a service info entry.
+type sciondSVCInfo struct {
+	Service string   `json:"service"`
+	TTL     uint32   `json:"ttl"`
+	Hosts   []string `json:"hosts"`
+}
+
+// sciondFlags are the flags shared by all sciond subcommands.
+type sciondFlags struct {
+	sciond  string
+	timeout time.Duration
+	json    bool
+}
+
+func (f *sciondFlags) connect() (sciond.Connector, context.Context, func(), error) {
+	ctx, cancelF := context.WithTimeout(context.Background(), f.timeout)
+	sd, err := sciond.NewService(f.sciond).Connect(ctx)
+	if err != nil {
+		cancelF()
+		return nil, nil, nil, serrors.WrapStr("connecting to SCION Daemon", err)
+	}
+	return sd, ctx, func() {
+		sd.Close(ctx)
+		cancelF()
+	}, nil
+}
+
+func (f *sciondFlags) write(v interface{}, human func(w io.Writer)) error {
+	if !f.json {
+		human(os.Stdout)
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func newSciond(pather CommandPather) *cobra.Command {
+	var flags sciondFlags
+	var cmd = &cobra.Command{
+		Use:   "sciond",
+		Short: "Query the SCION Daemon",
+		Long: `'sciond' queries the information that the SCION Daemon has about the local AS.
+
+All subcommands can write their output as machine readable json.
+`,
+	}
+	cmd.PersistentFlags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress,
+		"SCIOND address")
+	cmd.PersistentFlags().DurationVar(&flags.timeout, "timeout", 5*time.Second, "timeout")
+	cmd.PersistentFlags().BoolVarP(&flags.json, "json", "j", false,
+		"write the output as machine readable json")
+	cmd.AddCommand(
+		newSciondASInfo(pather, &flags),
+		newSciondIFInfo(pather, &flags),
+		newSciondSVCInfo(pather, &flags),
+	)
+	return cmd
+}
+
+func newSciondASInfo(pather CommandPather, flags *sciondFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "as-info [isd-as]",
+		Short: "Show the AS information",
+		Example: fmt.Sprintf(`  %[1]s sciond as-info
+  %[1]s sciond as-info 1-ff00:0:110 --json`, pather.CommandPath()),
+		Long: `'as-info' shows the ISD-AS, the MTU and the core flag of an AS.
+
+If no ISD-AS is given, the information about the local AS is shown.
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var ia addr.IA
+			if len(args) == 1 {
+				var err error
+				if ia, err = addr.IAFromString(args[0]); err != nil {
+					return serrors.WrapStr("parsing ISD-AS", err)
+				}
+			}
+			cmd.SilenceUsage = true
+
+			sd, ctx, cleanup, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			reply, err := sd.ASInfo(ctx, ia)
+			if err != nil {
+				return err
+			}
+			entries := make([]sciondASInfo, 0, len(reply.Entries))
+			for _, e := range reply.Entries {
+				entries = append(entries, sciondASInfo{
+					IA:     e.ISD_AS(),
+					MTU:    e.Mtu,
+					IsCore: e.IsCore,
+				})
+			}
+			return flags.write(entries, func(w io.Writer) {
+				for _, e := range entries {
+					fmt.Fprintf(w, "%s MTU: %d Core: %t\n", e.IA, e.MTU, e.IsCore)
+				}
+			})
+		},
+	}
+}
+
+func newSciondIFInfo(pather CommandPather, flags *sciondFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "if-info [ifid...]",
+		Short: "Show the underlay addresses of the local AS interfaces",
+		Example: fmt.Sprintf(`  %[1]s sciond if-info
+  %[1]s sciond if-info 1 2 --json`, pather.CommandPath()),
+		Long: `'if-info' shows the underlay addresses of the border routers that own the
+interfaces of the local AS.
+
+If no interface IDs are given, all interfaces are shown.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ifids := make([]common.IFIDType, 0, len(args))
+			for _, arg := range args {
+				ifid, err := strconv.ParseUint(arg, 10, 64)
+				if err != nil {
+					return serrors.WrapStr("parsing interface ID", err, "value", arg)
+				}
+				ifids = append(ifids, common.IFIDType(ifid))
+			}
+			cmd.SilenceUsage = true
+
+			sd, ctx, cleanup, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			reply, err := sd.IFInfo(ctx, ifids)
+			if err != nil {
+				return err
+			}
+			entries := make([]sciondIFInfo, 0, len(reply))
+			for ifid, a := range reply {
+				entries = append(entries, sciondIFInfo{IfID: ifid, Address: udpString(a)})
+			}
+			sort.Slice(entries, func(i, j int) bool { return entries[i].IfID < entries[j].IfID })
+			return flags.write(entries, func(w io.Writer) {
+				for _, e := range entries {
+					fmt.Fprintf(w, "%d: %s\n", e.IfID, e.Address)
+				}
+			})
+		},
+	}
+}
+
+func newSciondSVCInfo(pather CommandPather, flags *sciondFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "svc-info [service...]",
+		Short: "Show the addresses of the infrastructure services of the local AS",
+		Example: fmt.Sprintf(`  %[1]s sciond svc-info
+  %[1]s sciond svc-info cs --json`, pather.CommandPath()),
+		Long: `'svc-info' shows the addresses of the infrastructure services of the local AS.
+
+The services are identified by their short name, e.g. 'cs' or 'sig'. If no
+service is given, all services are shown.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svcs := make([]proto.ServiceType, 0, len(args))
+			for _, arg := range args {
+				svc := proto.ServiceTypeFromString(arg)
+				if svc == proto.ServiceType_unset {
+					return serrors.New("unknown service", "value", arg)
+				}
+				svcs = append(svcs, svc)
+			}
+			cmd.SilenceUsage = true
+
+			sd, ctx, cleanup, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			reply, err := sd.SVCInfo(ctx, svcs)
+			if err != nil {
+				return err
+			}
+			entries := make([]sciondSVCInfo, 0, len(reply.Entries))
+			for _, e := range reply.Entries {
+				entry := sciondSVCInfo{
+					Service: e.ServiceType.String(),
+					TTL:     e.Ttl,
+					Hosts:   []string{},
+				}
+				for _, h := range e.HostInfos {
+					entry.Hosts = append(entry.Hosts, udpString(h.UDP()))
+				}
+				entries = append(entries, entry)
+			}
+			return flags.write(entries, func(w io.Writer) {
+				for _, e := range entries {
+					fmt.Fprintf(w, "%s (TTL %ds):\n", e.Service, e.TTL)
+					for _, h := range e.Hosts {
+						fmt.Fprintf(w, "  %s\n", h)
+					}
+				}
+			})
+		},
+	}
+}
+
+func udpString(a *net.UDPAddr) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
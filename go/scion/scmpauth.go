@@ -0,0 +1,47 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/drkey/client"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+)
+
+func addSCMPAuthFlag(cmd *cobra.Command, scmpAuth *bool) {
+	cmd.Flags().BoolVar(scmpAuth, "scmp_auth", false,
+		`drop SCMP errors that are not authenticated with DRKey by the AS that sent
+them. The keys are fetched from the control service of the local AS.`)
+}
+
+// scmpKeys returns the provider of the keys to verify the authenticator of
+// SCMP errors. It returns nil if SCMP errors are not authenticated.
+func scmpKeys(ctx context.Context, sd sciond.Connector,
+	scmpAuth bool) (snet.SCMPKeyProvider, error) {
+
+	if !scmpAuth {
+		return nil, nil
+	}
+	keys, err := client.NewSCMPKeys(ctx, sd)
+	if err != nil {
+		return nil, serrors.WrapStr("initializing SCMP authentication", err)
+	}
+	return keys, nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/pkg/app"
 	"github.com/scionproto/scion/go/pkg/showpaths"
 )
 
@@ -34,7 +35,10 @@ func newShowpaths(pather CommandPather) *cobra.Command {
 		timeout    time.Duration
 		cfg        showpaths.Config
 		expiration bool
+		staticInfo bool
 		json       bool
+		sequence   string
+		policy     string
 	}
 
 	var cmd = &cobra.Command{
@@ -44,20 +48,38 @@ func newShowpaths(pather CommandPather) *cobra.Command {
 		Args:    cobra.ExactArgs(1),
 		Example: fmt.Sprintf(`  %[1]s showpaths 1-ff00:0:110 --expiration
   %[1]s showpaths 1-ff00:0:110 --local 127.0.0.55 --json
-  %[1]s showpaths 1-ff00:0:110 --no-probe`, pather.CommandPath()),
+  %[1]s showpaths 1-ff00:0:110 --no-probe
+  %[1]s showpaths 1-ff00:0:110 --extended --static-info
+  %[1]s showpaths 1-ff00:0:110 --sequence "0* 1-ff00:0:112 0*"`, pather.CommandPath()),
 		Long: `'showpaths' lists available paths between the local and the specified SCION ASe a.
 
 By default, the paths are probed. Paths served from the SCION Deamon's might not
 forward traffic successfully (e.g. if a network link went down, or there is a black
-hole on the path). To disable path probing, set the appropriate flag.
+hole on the path). To disable path probing, set the appropriate flag. In the
+extended probe mode (--extended), the paths are probed with SCMP echo requests
+to the control service of the destination AS and the round trip time of each
+path is displayed.
+
+The static information announced by the ASes on the path, i.e., latency,
+bandwidth, link types and geographic locations, is displayed with --static-info.
+It is always part of the json output, if available.
 
 'showpaths' can be instructed to output the paths as json using the the --json flag.
+
+The displayed paths can be restricted with a path policy file (--policy) or a
+sequence of hop predicates (--sequence).
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			dst, err := addr.IAFromString(args[0])
 			if err != nil {
 				return serrors.WrapStr("invalid destination ISD-AS", err)
 			}
+			if flags.cfg.Extended && flags.cfg.NoProbe {
+				return serrors.New("--extended and --no-probe are mutually exclusive")
+			}
+			if flags.cfg.Policy, err = app.PathPolicy(flags.policy, flags.sequence); err != nil {
+				return err
+			}
 
 			// At this point it is reasonable to assume that the caller knows how to
 			// call the command. Silence the usage help output on error, because subsequent
@@ -78,7 +100,7 @@ hole on the path). To disable path probing, set the appropriate flag.
 			if flags.json {
 				return res.JSON(os.Stdout)
 			}
-			res.Human(os.Stdout, flags.expiration)
+			res.Human(os.Stdout, flags.expiration, flags.staticInfo)
 			return nil
 		},
 	}
@@ -94,8 +116,13 @@ hole on the path). To disable path probing, set the appropriate flag.
 		"Set refresh flag for SCION Deamon path request")
 	cmd.Flags().BoolVar(&flags.cfg.NoProbe, "no-probe", false,
 		"Do not probe the paths and print the health status")
+	cmd.Flags().BoolVar(&flags.cfg.Extended, "extended", false,
+		"Probe the paths with SCMP echo requests and print the round trip time")
+	cmd.Flags().BoolVar(&flags.staticInfo, "static-info", false,
+		"Show the static information announced by the ASes on the path")
 	cmd.Flags().BoolVarP(&flags.json, "json", "j", false,
 		"Write the output as machine readable json")
+	addPathPolicyFlags(cmd, &flags.sequence, &flags.policy)
 	cmd.Flags().IPVarP(&flags.cfg.Local, "local", "l", nil,
 		"Optional local IP address to use for probing health checks")
 
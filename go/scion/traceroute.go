@@ -0,0 +1,280 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/sciond"
+	"github.com/scionproto/scion/go/lib/serrors"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/snet/addrutil"
+	"github.com/scionproto/scion/go/lib/sock/reliable"
+	"github.com/scionproto/scion/go/pkg/app"
+	"github.com/scionproto/scion/go/pkg/traceroute"
+)
+
+// tracerouteResult is the JSON representation of a traceroute run.
+type tracerouteResult struct {
+	Path tracerouteResultPath `json:"path"`
+	Hops []tracerouteHop      `json:"hops"`
+}
+
+// tracerouteResultPath describes the path that is traced.
+type tracerouteResultPath struct {
+	Fingerprint string          `json:"fingerprint"`
+	Hops        []tracerouteIfc `json:"hops"`
+	NextHop     string          `json:"next_hop,omitempty"`
+}
+
+type tracerouteIfc struct {
+	IfID common.IFIDType `json:"ifid"`
+	IA   addr.IA         `json:"isd_as"`
+}
+
+// tracerouteHop contains the replies to the probes of a hop. The RTTs of
+// probes that timed out are null.
+type tracerouteHop struct {
+	Index     int               `json:"index"`
+	IA        addr.IA           `json:"isd_as"`
+	Interface common.IFIDType   `json:"interface,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	RTTs      []*durationMillis `json:"round_trip_times"`
+}
+
+// durationMillis is a duration that is represented in milliseconds in JSON.
+type durationMillis time.Duration
+
+func (d durationMillis) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(d) / float64(time.Millisecond))
+}
+
+func newTraceroute(pather CommandPather) *cobra.Command {
+	var flags struct {
+		interactive bool
+		local       net.IP
+		refresh     bool
+		sequence    string
+		policy      string
+		sciond      string
+		dispatcher  string
+		timeout     time.Duration
+		probes      int
+		json        bool
+		scmpAuth    bool
+
+		features []string
+	}
+
+	var cmd = &cobra.Command{
+		Use:     "traceroute [flags] <remote>",
+		Aliases: []string{"tr"},
+		Short:   "Trace the SCION route to a remote SCION host using SCMP traceroute packets",
+		Example: fmt.Sprintf(`  %[1]s traceroute 1-ff00:0:110,10.0.0.1
+  %[1]s traceroute 1-ff00:0:110,10.0.0.1 --json`, pather.CommandPath()),
+		Long: `'traceroute' probes all interfaces on the path to the remote host, and the
+remote host itself, with SCMP traceroute packets.
+
+For every hop, the replying ISD-AS, interface and the round trip times of the
+probes are displayed. Probes that timed out are displayed as '*'.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, err := snet.ParseUDPAddr(args[0])
+			if err != nil {
+				return serrors.WrapStr("parsing remote", err)
+			}
+			features, err := parseFeatures(flags.features)
+			if err != nil {
+				return err
+			}
+			policy, err := app.PathPolicy(flags.policy, flags.sequence)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			// In JSON mode, only the result is written to stdout.
+			var out io.Writer = os.Stdout
+			if flags.json {
+				out = ioutil.Discard
+			}
+
+			ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+			defer cancelF()
+			sd, err := sciond.NewService(flags.sciond).Connect(ctx)
+			if err != nil {
+				return serrors.WrapStr("connecting to SCION Daemon", err)
+			}
+			keys, err := scmpKeys(ctx, sd, flags.scmpAuth)
+			if err != nil {
+				return err
+			}
+
+			info, err := app.QueryASInfo(context.Background(), sd)
+			if err != nil {
+				return err
+			}
+			path, err := app.ChoosePath(context.Background(), sd, remote.IA,
+				flags.interactive, flags.refresh, policy)
+			if err != nil {
+				return err
+			}
+			remote.Path = path.Path()
+			remote.NextHop = path.UnderlayNextHop()
+
+			localIP := flags.local
+			if localIP == nil {
+				target := remote.Host.IP
+				if remote.NextHop != nil {
+					target = remote.NextHop.IP
+				}
+				if localIP, err = addrutil.ResolveLocal(target); err != nil {
+					return serrors.WrapStr("resolving local address", err)
+				}
+				fmt.Fprintf(out, "Resolved local address:\n  %s\n", localIP)
+			}
+			fmt.Fprintf(out, "Using path:\n  %s\n\n", path)
+			local := &snet.UDPAddr{
+				IA:   info.IA,
+				Host: &net.UDPAddr{IP: localIP},
+			}
+
+			res := newTracerouteResult(path, remote)
+			ctx = app.WithSignal(context.Background(), os.Interrupt, syscall.SIGTERM)
+			var pathEntry snet.Path
+			if len(path.Interfaces()) > 0 {
+				pathEntry = path
+			}
+			stats, err := traceroute.Run(ctx, traceroute.Config{
+				Dispatcher:   reliable.NewDispatcher(flags.dispatcher),
+				Local:        local,
+				Remote:       remote,
+				PathEntry:    pathEntry,
+				ProbesPerHop: flags.probes,
+				Timeout:      flags.timeout,
+				ErrHandler: func(err error) {
+					fmt.Fprintf(os.Stderr, "ERROR: %s\n", err)
+				},
+				UpdateHandler: func(u traceroute.Update) {
+					fmt.Fprintln(out, formatUpdate(u))
+					res.Hops = append(res.Hops, newTracerouteHop(u))
+				},
+				SCMPKeys: keys,
+				HeaderV2: features.HeaderV2,
+			})
+			if err != nil {
+				return err
+			}
+			if flags.json {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				enc.SetEscapeHTML(false)
+				return enc.Encode(res)
+			}
+			fmt.Printf("\n%d probes sent, %d received\n", stats.Sent, stats.Received)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&flags.interactive, "interactive", "i", false, "interactive mode")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", time.Second, "timeout per packet")
+	cmd.Flags().IPVar(&flags.local, "local", nil, "IP address to listen on")
+	cmd.Flags().StringVar(&flags.sciond, "sciond", sciond.DefaultSCIONDAddress, "SCIOND address")
+	cmd.Flags().StringVar(&flags.dispatcher, "dispatcher", reliable.DefaultDispPath,
+		"dispatcher socket")
+	cmd.Flags().BoolVar(&flags.refresh, "refresh", false, "set refresh flag for path request")
+	addPathPolicyFlags(cmd, &flags.sequence, &flags.policy)
+	cmd.Flags().IntVarP(&flags.probes, "probes", "p", 3, "number of probes per hop")
+	cmd.Flags().BoolVarP(&flags.json, "json", "j", false,
+		"write the output as machine readable json")
+	addSCMPAuthFlag(cmd, &flags.scmpAuth)
+	cmd.Flags().StringSliceVar(&flags.features, "features", nil,
+		"enable development features "+features{}.supported())
+
+	return cmd
+}
+
+func newTracerouteResult(path snet.Path, remote *snet.UDPAddr) *tracerouteResult {
+	res := &tracerouteResult{
+		Path: tracerouteResultPath{
+			Fingerprint: "local",
+			Hops:        []tracerouteIfc{},
+		},
+		Hops: []tracerouteHop{},
+	}
+	if len(path.Interfaces()) > 0 {
+		res.Path.Fingerprint = snet.Fingerprint(path).String()[:16]
+	}
+	if remote.NextHop != nil {
+		res.Path.NextHop = remote.NextHop.String()
+	}
+	for _, ifc := range path.Interfaces() {
+		res.Path.Hops = append(res.Path.Hops, tracerouteIfc{IA: ifc.IA(), IfID: ifc.ID()})
+	}
+	return res
+}
+
+func newTracerouteHop(u traceroute.Update) tracerouteHop {
+	hop := tracerouteHop{
+		Index:     u.Index,
+		IA:        u.Remote.IA,
+		Interface: u.Interface,
+	}
+	if u.Remote.Host != nil {
+		hop.IP = u.Remote.Host.String()
+	}
+	for _, rtt := range u.RTTs {
+		if rtt < 0 {
+			hop.RTTs = append(hop.RTTs, nil)
+			continue
+		}
+		d := durationMillis(rtt)
+		hop.RTTs = append(hop.RTTs, &d)
+	}
+	return hop
+}
+
+func formatUpdate(u traceroute.Update) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%d", u.Index)
+	if u.Remote.Host != nil {
+		fmt.Fprintf(&s, " %s,%s", u.Remote.IA, u.Remote.Host)
+		if u.Interface != 0 {
+			fmt.Fprintf(&s, " IfID=%d", u.Interface)
+		}
+	}
+	for _, rtt := range u.RTTs {
+		if rtt < 0 {
+			s.WriteString(" *")
+			continue
+		}
+		fmt.Fprintf(&s, " %s", rtt)
+	}
+	return s.String()
+}
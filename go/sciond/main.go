@@ -32,6 +32,7 @@ import (
 	"github.com/scionproto/scion/go/lib/infra/modules/itopo"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathdb"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/periodic"
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/revcache"
@@ -130,6 +131,13 @@ func run(file string) error {
 		return serrors.WrapStr("creating trust engine", err)
 	}
 
+	var pathPolicy fetcher.Policy
+	if cfg.SD.PathPolicy != "" {
+		if pathPolicy, err = pathpol.LoadPolicy(cfg.SD.PathPolicy); err != nil {
+			return serrors.WrapStr("loading path policy", err)
+		}
+	}
+
 	srv := sciond.Server(cfg.SD.Address, sciond.ServerCfg{
 		Fetcher: fetcher.NewFetcher(
 			tcp.NewClientMessenger(),
@@ -140,6 +148,7 @@ func run(file string) error {
 			cfg.SD,
 			itopo.Provider(),
 			cfg.Features.HeaderV2,
+			pathPolicy,
 		),
 		Engine:   engine,
 		PathDB:   pathDB,
@@ -41,3 +41,8 @@ func ReloadConfig(cfg *sigjson.Cfg) bool {
 	log.Info("Config reloaded")
 	return res
 }
+
+// Status returns a snapshot of the state of the remote ASes.
+func Status() []asmap.ASStatus {
+	return asmap.Map.Status()
+}
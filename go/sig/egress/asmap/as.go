@@ -18,16 +18,21 @@ package asmap
 import (
 	"fmt"
 	"net"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
 	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/pathpol"
+	"github.com/scionproto/scion/go/lib/pktcls"
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/lib/sigjson"
 	"github.com/scionproto/scion/go/sig/egress/dispatcher"
 	"github.com/scionproto/scion/go/sig/egress/iface"
+	"github.com/scionproto/scion/go/sig/egress/qos"
 	"github.com/scionproto/scion/go/sig/egress/router"
 	"github.com/scionproto/scion/go/sig/egress/selector"
 	"github.com/scionproto/scion/go/sig/egress/session"
@@ -49,7 +54,35 @@ type ASEntry struct {
 	version           uint64 // used to track certain changes made to ASEntry
 	logger            log.Logger
 
+	// Session is the default session, used for all traffic that does not
+	// match a traffic class.
 	Session *session.Session
+	// classSessions are the sessions of the configured traffic classes. The
+	// session of the class at position i in the config has ID i+1.
+	classSessions []*classSession
+	selector      *selector.ClassSelector
+	// scheduler applies the rate limits and priorities to the packets of the
+	// sessions.
+	scheduler *qos.Scheduler
+	rateLimit *sigjson.RateLimit
+
+	// cfgNets are the configured networks. If acceptAnnounced is set, they
+	// restrict the prefixes announced by the remote SIG that are routed.
+	// Otherwise, they are the routed networks.
+	cfgNets         []*net.IPNet
+	acceptAnnounced bool
+	announceStop    chan struct{}
+	closed          bool
+}
+
+// classSession is a session that carries the traffic of a traffic class over
+// the paths allowed by the path policy.
+type classSession struct {
+	class    *pktcls.Class
+	policy   *pathpol.Policy
+	session  *session.Session
+	priority int
+	limit    *sigjson.RateLimit
 }
 
 func newASEntry(ia addr.IA) (*ASEntry, error) {
@@ -59,32 +92,201 @@ func newASEntry(ia addr.IA) (*ASEntry, error) {
 		IAString:          ia.String(),
 		Nets:              make(map[string]*net.IPNet),
 		healthMonitorStop: make(chan struct{}),
+		announceStop:      make(chan struct{}),
+		scheduler:         qos.NewScheduler(ia),
 	}
 	var err error
-	pool, err := session.NewPathPool(ia)
+	ae.Session, err = ae.newSession(0, nil)
 	if err != nil {
 		return nil, err
 	}
-	ae.Session, err = session.NewSession(ia, 0, ae.logger, pool)
+	ae.selector = selector.NewClassSelector(ae.Session)
+	ae.updateScheduler()
+	return ae, nil
+}
+
+func (ae *ASEntry) newSession(id sig_mgmt.SessionType,
+	policy *pathpol.Policy) (*session.Session, error) {
+
+	pool, err := session.NewPathPool(ae.IA, policy)
 	if err != nil {
 		return nil, err
 	}
-	return ae, nil
+	return session.NewSession(ae.IA, id, ae.logger, pool)
 }
 
 func (ae *ASEntry) ReloadConfig(cfg *sigjson.Cfg, cfgEntry *sigjson.ASEntry) bool {
 	ae.Lock()
 	defer ae.Unlock()
+	ae.cfgNets = make([]*net.IPNet, 0, len(cfgEntry.Nets))
+	for _, ipnet := range cfgEntry.Nets {
+		ae.cfgNets = append(ae.cfgNets, ipnet.IPNet())
+	}
+	ae.acceptAnnounced = cfgEntry.AcceptAnnounced
+	ae.rateLimit = cfgEntry.RateLimit
+	if ae.acceptAnnounced && ae.egressRing == nil {
+		// The default session must be running to receive the announcements.
+		ae.setupNet()
+	}
+	// Method calls first to prevent skips due to logical short-circuit
+	s := ae.updateNets()
+	s = ae.updateSessions(cfg.Classes, cfgEntry.Sessions) && s
+	ae.setMaxPaths(cfgEntry.Multipath.Paths())
+	return s
+}
+
+// setMaxPaths sets the number of paths the frames of all sessions are striped
+// over.
+func (ae *ASEntry) setMaxPaths(n int) {
+	ae.Session.SetMaxPaths(n)
+	for _, cs := range ae.classSessions {
+		cs.session.SetMaxPaths(n)
+	}
+}
+
+// updateSessions creates the sessions of the configured traffic classes. Class
+// sessions whose class and path policy did not change are kept, all others
+// are replaced. The priorities and rate limits of all sessions are updated.
+func (ae *ASEntry) updateSessions(classes pktcls.ClassMap,
+	entries []*sigjson.SessionEntry) bool {
+
+	if len(entries) > 0 && ae.egressRing == nil {
+		// Ensure that the network setup is done
+		ae.setupNet()
+	}
+	s := true
+	old := make(map[sig_mgmt.SessionType]*classSession, len(ae.classSessions))
+	for _, cs := range ae.classSessions {
+		old[cs.session.SessId] = cs
+	}
+	var stale []*classSession
+	updated := make([]*classSession, 0, len(entries))
+	for i, entry := range entries {
+		id := sig_mgmt.SessionType(i + 1)
+		class, ok := classes[entry.Class]
+		if !ok {
+			ae.logger.Error("Unknown traffic class", "class", entry.Class)
+			s = false
+			continue
+		}
+		if cs, ok := old[id]; ok {
+			delete(old, id)
+			if reflect.DeepEqual(cs.class, class) &&
+				reflect.DeepEqual(cs.policy, entry.PathPolicy) {
+				cs.priority, cs.limit = entry.Priority, entry.RateLimit
+				updated = append(updated, cs)
+				continue
+			}
+			stale = append(stale, cs)
+		}
+		sess, err := ae.newSession(id, entry.PathPolicy)
+		if err != nil {
+			ae.logger.Error("Unable to create session", "class", entry.Class, "err", err)
+			s = false
+			continue
+		}
+		sess.Start()
+		updated = append(updated, &classSession{
+			class:    class,
+			policy:   entry.PathPolicy,
+			session:  sess,
+			priority: entry.Priority,
+			limit:    entry.RateLimit,
+		})
+		ae.logger.Info("Added traffic class session", "class", entry.Class, "sessId", id)
+	}
+	for _, cs := range old {
+		stale = append(stale, cs)
+	}
+	ae.classSessions = updated
+	ae.updateSelector()
+	ae.updateScheduler()
+	// The stale sessions are only cleaned up after the selector no longer
+	// returns them.
+	for _, cs := range stale {
+		ae.cleanSession(cs.session)
+		ae.logger.Info("Removed traffic class session", "class", cs.class.GetName(),
+			"sessId", cs.session.SessId)
+	}
+	return s
+}
+
+func (ae *ASEntry) updateSelector() {
+	classes := make([]selector.ClassSession, 0, len(ae.classSessions))
+	for _, cs := range ae.classSessions {
+		classes = append(classes, selector.ClassSession{Class: cs.class, Session: cs.session})
+	}
+	ae.selector.SetClasses(classes)
+}
+
+// updateScheduler configures the scheduler with the rate limits and priorities
+// of the sessions. The default session has priority 0 and is only limited by
+// the rate limit of the remote AS.
+func (ae *ASEntry) updateScheduler() {
+	classes := make([]qos.Class, 0, len(ae.classSessions)+1)
+	classes = append(classes, qos.Class{Session: ae.Session})
+	for _, cs := range ae.classSessions {
+		classes = append(classes, qos.Class{
+			Session:  cs.session,
+			Priority: cs.priority,
+			Limit:    cs.limit,
+		})
+	}
+	ae.scheduler.Configure(ae.rateLimit, classes)
+}
+
+// updateNets updates the routed networks. These are either the configured
+// networks, or the prefixes announced by the remote SIG that are contained in
+// one of the configured networks.
+func (ae *ASEntry) updateNets() bool {
+	nets := ae.cfgNets
+	if ae.acceptAnnounced {
+		nets = ae.acceptedNets(ae.Session.Announced())
+	}
 	// Method calls first to prevent skips due to logical short-circuit
-	s := ae.addNewNets(cfgEntry.Nets)
-	return ae.delOldNets(cfgEntry.Nets) && s
+	s := ae.addNewNets(nets)
+	return ae.delOldNets(nets) && s
+}
+
+// acceptedNets returns the announced prefixes that are contained in one of the
+// configured networks.
+func (ae *ASEntry) acceptedNets(announced []*net.IPNet) []*net.IPNet {
+	accepted := make([]*net.IPNet, 0, len(announced))
+Top:
+	for _, ipnet := range announced {
+		for _, cfgNet := range ae.cfgNets {
+			if containsNet(cfgNet, ipnet) {
+				accepted = append(accepted, ipnet)
+				continue Top
+			}
+		}
+		ae.logger.Info("Ignoring announced network that is not allowed", "net", ipnet)
+	}
+	return accepted
+}
+
+// watchAnnounced updates the routed networks when the prefixes announced by
+// the remote SIG change.
+func (ae *ASEntry) watchAnnounced() {
+	for {
+		select {
+		case <-ae.announceStop:
+			return
+		case <-ae.Session.AnnouncedChanged():
+			ae.Lock()
+			if ae.acceptAnnounced && !ae.closed {
+				ae.updateNets()
+			}
+			ae.Unlock()
+		}
+	}
 }
 
 // addNewNets adds the networks in ipnets that are not currently configured.
-func (ae *ASEntry) addNewNets(ipnets []*sigjson.IPNet) bool {
+func (ae *ASEntry) addNewNets(ipnets []*net.IPNet) bool {
 	s := true
 	for _, ipnet := range ipnets {
-		err := ae.addNet(ipnet.IPNet())
+		err := ae.addNet(ipnet)
 		if err != nil {
 			ae.logger.Error("Unable to add network", "net", ipnet, "err", err)
 			s = false
@@ -94,12 +296,12 @@ func (ae *ASEntry) addNewNets(ipnets []*sigjson.IPNet) bool {
 }
 
 // delOldNets deletes currently configured networks that are not in ipnets.
-func (ae *ASEntry) delOldNets(ipnets []*sigjson.IPNet) bool {
+func (ae *ASEntry) delOldNets(ipnets []*net.IPNet) bool {
 	s := true
 Top:
 	for k, v := range ae.Nets {
 		for _, ipnet := range ipnets {
-			if k == ipnet.IPNet().String() {
+			if k == ipnet.String() {
 				continue Top
 			}
 		}
@@ -212,6 +414,9 @@ func (ae *ASEntry) Cleanup() error {
 	defer ae.Unlock()
 	// Clean up health monitor
 	ae.healthMonitorStop <- struct{}{}
+	// Stop following the announced prefixes.
+	ae.closed = true
+	close(ae.announceStop)
 	// Clean up NetMap entries
 	for _, v := range ae.Nets {
 		if err := ae.delNet(v); err != nil {
@@ -225,8 +430,18 @@ func (ae *ASEntry) Cleanup() error {
 }
 
 func (ae *ASEntry) cleanSessions() {
-	if err := ae.Session.Cleanup(); err != nil {
-		ae.Session.Logger().Error("Error cleaning up session", "err", err)
+	classSessions := ae.classSessions
+	ae.classSessions = nil
+	ae.updateSelector()
+	for _, cs := range classSessions {
+		ae.cleanSession(cs.session)
+	}
+	ae.cleanSession(ae.Session)
+}
+
+func (ae *ASEntry) cleanSession(sess *session.Session) {
+	if err := sess.Cleanup(); err != nil {
+		sess.Logger().Error("Error cleaning up session", "err", err)
 	}
 }
 
@@ -234,13 +449,23 @@ func (ae *ASEntry) setupNet() {
 	ae.egressRing = ringbuf.New(iface.EgressRemotePkts, nil, fmt.Sprintf("egress_%s", ae.IAString))
 	go func() {
 		defer log.HandlePanic()
-		dispatcher.NewDispatcher(ae.IA, ae.egressRing,
-			&selector.SingleSession{Session: ae.Session}).Run()
+		dispatcher.NewDispatcher(ae.IA, ae.egressRing, ae.selector, ae.scheduler).Run()
 	}()
 	go func() {
 		defer log.HandlePanic()
 		ae.monitorHealth()
 	}()
+	go func() {
+		defer log.HandlePanic()
+		ae.watchAnnounced()
+	}()
 	ae.Session.Start()
 	ae.logger.Info("Network setup done")
 }
+
+// containsNet returns whether inner is contained in outer.
+func containsNet(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerOnes <= innerOnes && outer.Contains(inner.IP)
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asmap
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainsNet(t *testing.T) {
+	tests := map[string]struct {
+		Outer    string
+		Inner    string
+		Expected bool
+	}{
+		"equal": {
+			Outer:    "192.0.2.0/24",
+			Inner:    "192.0.2.0/24",
+			Expected: true,
+		},
+		"more specific": {
+			Outer:    "192.0.2.0/24",
+			Inner:    "192.0.2.128/25",
+			Expected: true,
+		},
+		"less specific": {
+			Outer:    "192.0.2.0/24",
+			Inner:    "192.0.0.0/16",
+			Expected: false,
+		},
+		"disjoint": {
+			Outer:    "192.0.2.0/24",
+			Inner:    "198.51.100.0/24",
+			Expected: false,
+		},
+		"IPv6 more specific": {
+			Outer:    "2001:db8::/32",
+			Inner:    "2001:db8:1::/48",
+			Expected: true,
+		},
+		"IPv6 in IPv4": {
+			Outer:    "0.0.0.0/0",
+			Inner:    "2001:db8::/32",
+			Expected: false,
+		},
+		"IPv4 in IPv6": {
+			Outer:    "::/0",
+			Inner:    "192.0.2.0/24",
+			Expected: false,
+		},
+		"IPv4-mapped in IPv4": {
+			Outer:    "192.0.2.0/24",
+			Inner:    "::ffff:192.0.2.0/120",
+			Expected: false,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, containsNet(parseNet(t, tc.Outer), parseNet(t, tc.Inner)))
+		})
+	}
+}
+
+func parseNet(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+
+	_, ipnet, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return ipnet
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asmap
+
+import (
+	"sort"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/sigjson"
+	"github.com/scionproto/scion/go/sig/egress/session"
+)
+
+// ASStatus is a snapshot of the state of a remote AS.
+type ASStatus struct {
+	IA addr.IA
+	// Nets are the routed networks.
+	Nets []string
+	// ConfiguredNets are the networks in the configuration. If
+	// AcceptAnnounced is set, they restrict the announced prefixes that are
+	// routed.
+	ConfiguredNets  []string
+	AcceptAnnounced bool
+	Healthy         bool
+	// RateLimit is the rate limit of all sessions to the remote AS.
+	RateLimit *sigjson.RateLimit `json:",omitempty"`
+	// Sessions are the default session, followed by the sessions of the
+	// traffic classes.
+	Sessions []SessionStatus
+}
+
+// SessionStatus is a snapshot of the state of a session to a remote AS.
+type SessionStatus struct {
+	// Class is the traffic class of the session. It is empty for the default
+	// session.
+	Class    string `json:",omitempty"`
+	Priority int
+	// RateLimit is the rate limit of the session.
+	RateLimit *sigjson.RateLimit `json:",omitempty"`
+	session.Status
+}
+
+// Status returns a snapshot of the state of all remote ASes, sorted by ISD-AS.
+func (am *ASMap) Status() []ASStatus {
+	var status []ASStatus
+	am.Range(func(_ addr.IAInt, ae *ASEntry) bool {
+		status = append(status, ae.Status())
+		return true
+	})
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].IA.IAInt() < status[j].IA.IAInt()
+	})
+	return status
+}
+
+// Status returns a snapshot of the state of the remote AS.
+func (ae *ASEntry) Status() ASStatus {
+	ae.RLock()
+	defer ae.RUnlock()
+	status := ASStatus{
+		IA:              ae.IA,
+		Nets:            make([]string, 0, len(ae.Nets)),
+		ConfiguredNets:  make([]string, 0, len(ae.cfgNets)),
+		AcceptAnnounced: ae.acceptAnnounced,
+		Healthy:         ae.checkHealth(),
+		RateLimit:       ae.rateLimit,
+		Sessions:        []SessionStatus{{Status: ae.Session.Status()}},
+	}
+	for key := range ae.Nets {
+		status.Nets = append(status.Nets, key)
+	}
+	sort.Strings(status.Nets)
+	for _, ipnet := range ae.cfgNets {
+		status.ConfiguredNets = append(status.ConfiguredNets, ipnet.String())
+	}
+	for _, cs := range ae.classSessions {
+		status.Sessions = append(status.Sessions, SessionStatus{
+			Class:     cs.class.GetName(),
+			Priority:  cs.priority,
+			RateLimit: cs.limit,
+			Status:    cs.session.Status(),
+		})
+	}
+	return status
+}
@@ -14,32 +14,45 @@
 // limitations under the License.
 
 // Package dispatcher reads from input ring buffer, decides on a Session and
-// puts data on the ring buffer of the Session.
+// puts data on the ring buffer of the Session. The packets are passed to the
+// Session through a scheduler that applies the rate limits and priorities of
+// the remote AS.
 package dispatcher
 
 import (
+	"time"
+
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/sig/egress/iface"
+	"github.com/scionproto/scion/go/sig/egress/qos"
 	"github.com/scionproto/scion/go/sig/internal/metrics"
 )
 
+// sendInterval is the interval in which queued packets are sent while no new
+// packets arrive.
+const sendInterval = time.Millisecond
+
 type egressDispatcher struct {
 	log.Logger
 	ia               addr.IA
 	ring             *ringbuf.Ring
 	sessionSelector  iface.SessionSelector
+	scheduler        *qos.Scheduler
 	pktsRecvCounters map[metrics.CtrPairKey]metrics.CtrPair
 }
 
-func NewDispatcher(ia addr.IA, ring *ringbuf.Ring, ss iface.SessionSelector) *egressDispatcher {
+func NewDispatcher(ia addr.IA, ring *ringbuf.Ring, ss iface.SessionSelector,
+	scheduler *qos.Scheduler) *egressDispatcher {
+
 	return &egressDispatcher{
 		Logger:           log.New("ia", ia.String()),
 		ring:             ring,
 		sessionSelector:  ss,
+		scheduler:        scheduler,
 		pktsRecvCounters: make(map[metrics.CtrPairKey]metrics.CtrPair),
 	}
 }
@@ -48,7 +61,8 @@ func (ed *egressDispatcher) Run() {
 	ed.Info("EgressDispatcher: starting")
 	bufs := make(ringbuf.EntryList, iface.EgressBufPkts)
 	for {
-		n, _ := ed.ring.Read(bufs, true)
+		// Only block if no packets are waiting for the rate limits.
+		n, _ := ed.ring.Read(bufs, ed.scheduler.Pending() == 0)
 		if n < 0 {
 			break
 		}
@@ -62,10 +76,15 @@ func (ed *egressDispatcher) Run() {
 				ed.Debug("EgressDispatcher: unable to find session")
 				continue
 			}
-			sess.Ring().Write(ringbuf.EntryList{buf}, true)
+			ed.scheduler.Enqueue(sess, buf)
 			ed.updateMetrics(sess.IA().IAInt(), sess.ID(), len(buf))
 		}
+		ed.scheduler.Send(time.Now())
+		if n == 0 {
+			time.Sleep(sendInterval)
+		}
 	}
+	ed.scheduler.Close()
 	ed.Info("EgressDispatcher: stopping")
 }
 
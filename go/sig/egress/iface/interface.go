@@ -28,6 +28,7 @@ import (
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/spath/spathmeta"
 	"github.com/scionproto/scion/go/sig/egress/siginfo"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
 func Init() {
@@ -75,6 +76,19 @@ type Session interface {
 type RemoteInfo struct {
 	Sig      *siginfo.Sig
 	SessPath *SessPath
+	// Stripes are the paths the frames are striped over in multipath mode,
+	// including SessPath. If it is empty, all frames are sent over SessPath.
+	Stripes []Stripe
+	// Key is the key that protects the frames sent to Sig. It is nil if no
+	// key is established.
+	Key *sigcrypto.Key
+}
+
+// Stripe is a path that frames are striped over. Weight is the share of the
+// frames sent over the path, relative to the weights of the other stripes.
+type Stripe struct {
+	SessPath *SessPath
+	Weight   int
 }
 
 // Copy created a deep copy of the object.
@@ -82,13 +96,25 @@ func (r *RemoteInfo) Copy() *RemoteInfo {
 	if r == nil {
 		return nil
 	}
+	var stripes []Stripe
+	if len(r.Stripes) > 0 {
+		stripes = make([]Stripe, 0, len(r.Stripes))
+		for _, s := range r.Stripes {
+			stripes = append(stripes, Stripe{SessPath: s.SessPath.Copy(), Weight: s.Weight})
+		}
+	}
 	return &RemoteInfo{
 		Sig:      r.Sig.Copy(),
 		SessPath: r.SessPath.Copy(),
+		Stripes:  stripes,
+		Key:      r.Key,
 	}
 }
 
 func (r *RemoteInfo) String() string {
+	if len(r.Stripes) > 0 {
+		return fmt.Sprintf("Sig: %s Path: %s Stripes: %d", r.Sig, r.SessPath, len(r.Stripes))
+	}
 	return fmt.Sprintf("Sig: %s Path: %s", r.Sig, r.SessPath)
 }
 
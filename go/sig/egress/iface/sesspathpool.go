@@ -16,8 +16,11 @@ package iface
 
 import (
 	"math"
+	"sort"
 	"time"
 
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/spath/spathmeta"
 )
@@ -66,6 +69,65 @@ func (spp SessPathPool) Get(exclude snet.PathFingerprint) *SessPath {
 	return res.SessPath
 }
 
+// GetDisjoint returns up to n paths that do not share any interface with each
+// other, starting with first. Paths that failed recently, that are close to
+// expiry, or whose interfaces are not known are not considered. The other
+// paths are preferred by lowest RTT, paths without RTT measurement last.
+func (spp SessPathPool) GetDisjoint(first *SessPath, n int) []*SessPath {
+	if first == nil || n < 1 {
+		return nil
+	}
+	res := []*SessPath{first}
+	used := make(map[pathIntf]struct{})
+	for _, intf := range first.Path().Interfaces() {
+		used[pathIntf{ia: intf.IA(), id: intf.ID()}] = struct{}{}
+	}
+	var candidates []*SessPathStats
+	for k, v := range spp {
+		if k == first.Key() || v.failCount > 0 || v.SessPath.IsCloseToExpiry() {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ri, rj := candidates[i].rtt, candidates[j].rtt
+		if ri != rj {
+			return rj == 0 || (ri != 0 && ri < rj)
+		}
+		return candidates[i].SessPath.Key() < candidates[j].SessPath.Key()
+	})
+Candidates:
+	for _, c := range candidates {
+		if len(res) == n {
+			break
+		}
+		intfs := c.SessPath.Path().Interfaces()
+		if len(intfs) == 0 {
+			continue
+		}
+		for _, intf := range intfs {
+			if _, ok := used[pathIntf{ia: intf.IA(), id: intf.ID()}]; ok {
+				continue Candidates
+			}
+		}
+		for _, intf := range intfs {
+			used[pathIntf{ia: intf.IA(), id: intf.ID()}] = struct{}{}
+		}
+		res = append(res, c.SessPath)
+	}
+	return res
+}
+
+// RTT returns the smoothed round trip time measured on the path, or 0 if there
+// is no measurement.
+func (spp SessPathPool) RTT(key snet.PathFingerprint) time.Duration {
+	sp := spp[key]
+	if sp == nil {
+		return 0
+	}
+	return sp.rtt
+}
+
 func (spp SessPathPool) GetByKey(key snet.PathFingerprint) *SessPath {
 	res := spp[key]
 	if res == nil {
@@ -100,6 +162,16 @@ func (spp SessPathPool) Update(aps spathmeta.AppPathSet) {
 // Reply is called when a probe reply arrives.
 // 'sent' is the time when the original probe was sent.
 func (spp SessPathPool) Reply(path *SessPath, sent time.Time) {
+	sp := spp[path.Key()]
+	if sp == nil {
+		return
+	}
+	rtt := time.Since(sent)
+	if sp.rtt == 0 {
+		sp.rtt = rtt
+		return
+	}
+	sp.rtt = (7*sp.rtt + rtt) / 8
 }
 
 // Timeout is called when a reply to a probe is not received in time.
@@ -127,6 +199,13 @@ type SessPathStats struct {
 	SessPath  *SessPath
 	lastFail  time.Time
 	failCount uint16
+	// rtt is the smoothed round trip time of the probes sent over the path.
+	rtt time.Duration
+}
+
+type pathIntf struct {
+	ia addr.IA
+	id common.IFIDType
 }
 
 func newSessPathStats(key snet.PathFingerprint, path snet.Path) *SessPathStats {
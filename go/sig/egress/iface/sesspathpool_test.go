@@ -0,0 +1,108 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iface
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/spath"
+	"github.com/scionproto/scion/go/lib/spath/spathmeta"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func TestSessPathPoolGetDisjoint(t *testing.T) {
+	ia110 := xtest.MustParseIA("1-ff00:0:110")
+	ia111 := xtest.MustParseIA("1-ff00:0:111")
+	ia112 := xtest.MustParseIA("1-ff00:0:112")
+	expiry := time.Now().Add(time.Hour)
+	// a and b share the link 110#1, c and d are disjoint from a.
+	a := &testPath{expiry: expiry, intfs: []testIntf{{ia110, 1}, {ia111, 2}}}
+	b := &testPath{expiry: expiry, intfs: []testIntf{{ia110, 1}, {ia111, 3}}}
+	c := &testPath{expiry: expiry, intfs: []testIntf{{ia110, 4}, {ia112, 5}}}
+	d := &testPath{expiry: expiry, intfs: []testIntf{{ia110, 6}, {ia111, 7}}}
+	expiring := &testPath{expiry: time.Now(), intfs: []testIntf{{ia110, 8}, {ia111, 9}}}
+	unknown := &testPath{expiry: expiry}
+
+	spp := NewSessPathPool()
+	spp.Update(spathmeta.AppPathSet{
+		"a": a, "b": b, "c": c, "d": d, "expiring": expiring, "unknown": unknown,
+	})
+	first := spp.GetByKey("a")
+	// d is faster than c.
+	spp.Reply(spp.GetByKey("c"), time.Now().Add(-20*time.Millisecond))
+	spp.Reply(spp.GetByKey("d"), time.Now().Add(-10*time.Millisecond))
+
+	assert.Equal(t, []*SessPath{first}, spp.GetDisjoint(first, 1))
+	assert.Equal(t, []*SessPath{first, spp.GetByKey("d")}, spp.GetDisjoint(first, 2))
+	assert.Equal(t, []*SessPath{first, spp.GetByKey("d"), spp.GetByKey("c")},
+		spp.GetDisjoint(first, 5))
+
+	// Paths that failed recently are not used.
+	spp.Timeout(spp.GetByKey("d"), time.Now())
+	assert.Equal(t, []*SessPath{first, spp.GetByKey("c")}, spp.GetDisjoint(first, 5))
+	assert.Nil(t, spp.GetDisjoint(nil, 5))
+}
+
+func TestSessPathPoolReply(t *testing.T) {
+	spp := NewSessPathPool()
+	spp.Update(spathmeta.AppPathSet{"a": &testPath{}})
+	path := spp.GetByKey("a")
+	assert.Zero(t, spp.RTT("a"))
+
+	spp.Reply(path, time.Now().Add(-80*time.Millisecond))
+	first := spp.RTT("a")
+	assert.True(t, first >= 80*time.Millisecond, first)
+	// Later samples are smoothed.
+	spp.Reply(path, time.Now())
+	assert.True(t, spp.RTT("a") < first && spp.RTT("a") >= 7*first/8, spp.RTT("a"))
+}
+
+type testIntf struct {
+	ia addr.IA
+	id common.IFIDType
+}
+
+func (i testIntf) IA() addr.IA         { return i.ia }
+func (i testIntf) ID() common.IFIDType { return i.id }
+
+type testPath struct {
+	expiry time.Time
+	intfs  []testIntf
+}
+
+func (p *testPath) UnderlayNextHop() *net.UDPAddr { return nil }
+func (p *testPath) Path() *spath.Path             { return nil }
+func (p *testPath) Destination() addr.IA          { return addr.IA{} }
+func (p *testPath) Metadata() snet.PathMetadata   { return p }
+func (p *testPath) Copy() snet.Path               { return p }
+func (p *testPath) MTU() uint16                   { return 1472 }
+func (p *testPath) Expiry() time.Time             { return p.expiry }
+
+func (p *testPath) StaticInfo() *snet.PathStaticInfo { return nil }
+
+func (p *testPath) Interfaces() []snet.PathInterface {
+	var intfs []snet.PathInterface
+	for _, intf := range p.intfs {
+		intfs = append(intfs, intf)
+	}
+	return intfs
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos
+
+import (
+	"time"
+
+	"github.com/scionproto/scion/go/lib/sigjson"
+)
+
+// TokenBucket is a token bucket with one token per byte. The tokens can go
+// below zero if a packet larger than the remaining tokens is sent, such that
+// packets larger than the burst are not blocked forever. A nil TokenBucket
+// does not limit the traffic. It is not safe for concurrent use.
+type TokenBucket struct {
+	// rate is the number of tokens added per second.
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a full token bucket for the rate limit. If limit is
+// nil, nil is returned.
+func NewTokenBucket(limit *sigjson.RateLimit, now time.Time) *TokenBucket {
+	if limit == nil {
+		return nil
+	}
+	burst := float64(limit.BurstBytes())
+	return &TokenBucket{
+		rate:   limit.Rate.BytesPerSecond(),
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// Allow returns whether a packet can be sent at the given time.
+func (b *TokenBucket) Allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	return b.tokens > 0
+}
+
+// Take removes the tokens for a packet of n bytes.
+func (b *TokenBucket) Take(n int) {
+	if b == nil {
+		return
+	}
+	b.tokens -= float64(n)
+}
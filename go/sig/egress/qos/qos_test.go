@@ -0,0 +1,173 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qos_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/sigjson"
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/egress/iface"
+	"github.com/scionproto/scion/go/sig/egress/iface/mock_iface"
+	"github.com/scionproto/scion/go/sig/egress/qos"
+)
+
+var ia = xtest.MustParseIA("1-ff00:0:110")
+
+// 8kbps are 1000 bytes per second.
+var limit = &sigjson.RateLimit{Rate: sigjson.Bandwidth(8), Burst: 1000}
+
+func TestTokenBucket(t *testing.T) {
+	var unlimited *qos.TokenBucket
+	assert.True(t, unlimited.Allow(time.Now()))
+	unlimited.Take(1500)
+
+	now := time.Now()
+	b := qos.NewTokenBucket(limit, now)
+	assert.True(t, b.Allow(now))
+	b.Take(1500)
+	assert.False(t, b.Allow(now))
+	assert.False(t, b.Allow(now.Add(400*time.Millisecond)))
+	assert.True(t, b.Allow(now.Add(600*time.Millisecond)))
+	// The tokens never exceed the burst.
+	assert.True(t, b.Allow(now.Add(10*time.Second)))
+	b.Take(1000)
+	assert.False(t, b.Allow(now.Add(10*time.Second)))
+}
+
+func TestSchedulerPassThrough(t *testing.T) {
+	iface.Init()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	def := newSession(ctrl, 0)
+	s := qos.NewScheduler(ia)
+	s.Configure(nil, []qos.Class{{Session: def}})
+	s.Enqueue(def, packet(t, 100))
+	assert.Equal(t, 0, s.Pending())
+	assert.Equal(t, 1, drain(def))
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	iface.Init()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	def := newSession(ctrl, 0)
+	voip := newSession(ctrl, 1)
+	s := qos.NewScheduler(ia)
+	s.Configure(limit, []qos.Class{{Session: def}, {Session: voip, Priority: 10}})
+	for i := 0; i < 3; i++ {
+		s.Enqueue(def, packet(t, 600))
+	}
+	for i := 0; i < 2; i++ {
+		s.Enqueue(voip, packet(t, 600))
+	}
+	assert.Equal(t, 5, s.Pending())
+
+	now := time.Now()
+	s.Send(now)
+	assert.Equal(t, 2, drain(voip), "higher priority is sent first")
+	assert.Equal(t, 0, drain(def))
+	assert.Equal(t, 3, s.Pending())
+
+	s.Send(now.Add(time.Second))
+	assert.Equal(t, 2, drain(def))
+	assert.Equal(t, 1, s.Pending())
+}
+
+func TestSchedulerClassLimit(t *testing.T) {
+	iface.Init()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	def := newSession(ctrl, 0)
+	voip := newSession(ctrl, 1)
+	s := qos.NewScheduler(ia)
+	s.Configure(nil, []qos.Class{{Session: def}, {Session: voip, Priority: 10, Limit: limit}})
+	for i := 0; i < 3; i++ {
+		s.Enqueue(voip, packet(t, 600))
+		s.Enqueue(def, packet(t, 600))
+	}
+
+	s.Send(time.Now())
+	assert.Equal(t, 2, drain(voip))
+	assert.Equal(t, 3, drain(def), "limited class must not block lower priorities")
+	assert.Equal(t, 1, s.Pending())
+}
+
+func TestSchedulerDrop(t *testing.T) {
+	iface.Init()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	def := newSession(ctrl, 0)
+	bulk := newSession(ctrl, 1)
+	s := qos.NewScheduler(ia)
+	s.Configure(limit, []qos.Class{{Session: def}, {Session: bulk}})
+	for i := 0; i < qos.QueueCap+1; i++ {
+		s.Enqueue(bulk, packet(t, 100))
+	}
+	s.Enqueue(def, packet(t, 100))
+	assert.Equal(t, qos.QueueCap+1, s.Pending(), "full queue must drop the packet")
+	assert.Equal(t, iface.EgressFreePktsCap-qos.QueueCap-1, freeBufs())
+
+	// Removing the session drops its queued packets.
+	s.Configure(limit, []qos.Class{{Session: def}})
+	assert.Equal(t, 1, s.Pending())
+	assert.Equal(t, iface.EgressFreePktsCap-1, freeBufs())
+}
+
+func newSession(ctrl *gomock.Controller, id sig_mgmt.SessionType) *mock_iface.MockSession {
+	ring := ringbuf.New(512, nil, "test")
+	sess := mock_iface.NewMockSession(ctrl)
+	sess.EXPECT().IA().AnyTimes().Return(ia)
+	sess.EXPECT().ID().AnyTimes().Return(id)
+	sess.EXPECT().Ring().AnyTimes().Return(ring)
+	return sess
+}
+
+// packet takes a buffer of the given length from the free buffer pool.
+func packet(t *testing.T, length int) common.RawBytes {
+	bufs := make(ringbuf.EntryList, 1)
+	n, _ := iface.EgressFreePkts.Read(bufs, true)
+	require.Equal(t, 1, n)
+	return bufs[0].(common.RawBytes)[:length]
+}
+
+// drain returns the number of packets in the ring of the session, and
+// releases them.
+func drain(sess iface.Session) int {
+	bufs := make(ringbuf.EntryList, 512)
+	n, _ := sess.Ring().Read(bufs, false)
+	iface.EgressFreePkts.Write(bufs[:n], true)
+	return n
+}
+
+// freeBufs returns the number of buffers in the free buffer pool.
+func freeBufs() int {
+	bufs := make(ringbuf.EntryList, iface.EgressFreePktsCap)
+	n, _ := iface.EgressFreePkts.Read(bufs, false)
+	iface.EgressFreePkts.Write(bufs[:n], true)
+	return n
+}
@@ -0,0 +1,228 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package qos rate limits and prioritizes the egress traffic to a remote AS.
+//
+// The packets of each session are queued. Queued packets are sent in strict
+// priority order: a session is only served if all sessions with a higher
+// priority have no queued packets, or are held back by their own rate limit.
+// The rate limit of the remote AS holds back all sessions. If the queue of a
+// session is full, new packets of the session are dropped.
+package qos
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/ringbuf"
+	"github.com/scionproto/scion/go/lib/sigjson"
+	"github.com/scionproto/scion/go/sig/egress/iface"
+	"github.com/scionproto/scion/go/sig/internal/metrics"
+)
+
+// QueueCap is the number of packets that can be queued per session.
+const QueueCap = 256
+
+// Class is a session with its priority and rate limit.
+type Class struct {
+	Session  iface.Session
+	Priority int
+	// Limit is the rate limit of the session. If it is nil, the session is
+	// only limited by the rate limit of the remote AS.
+	Limit *sigjson.RateLimit
+}
+
+// Scheduler queues the packets of the sessions to a remote AS, and sends
+// them to the sessions within the configured rate limits. If no rate limit is
+// configured, packets are passed to the sessions directly. The configuration
+// can be changed while the scheduler is in use. Enqueue and Send must only be
+// called by a single goroutine.
+type Scheduler struct {
+	ia addr.IA
+
+	mu    sync.Mutex
+	limit *sigjson.RateLimit
+	// bucket is the token bucket of the remote AS.
+	bucket *TokenBucket
+	// queues are sorted by descending priority.
+	queues  []*queue
+	byID    map[sig_mgmt.SessionType]*queue
+	limited bool
+	pending int
+	// out holds the packets that are written to the sessions by Send.
+	out []outPkt
+}
+
+type queue struct {
+	class   Class
+	bucket  *TokenBucket
+	pkts    []common.RawBytes
+	dropped prometheus.Counter
+}
+
+type outPkt struct {
+	sess iface.Session
+	buf  common.RawBytes
+}
+
+// NewScheduler creates a scheduler for the remote AS without sessions.
+func NewScheduler(ia addr.IA) *Scheduler {
+	return &Scheduler{
+		ia:   ia,
+		byID: make(map[sig_mgmt.SessionType]*queue),
+	}
+}
+
+// Configure sets the rate limit of the remote AS and the sessions. The queued
+// packets of sessions that are no longer configured are dropped. The token
+// buckets are only reset if the rate limit changed.
+func (s *Scheduler) Configure(limit *sigjson.RateLimit, classes []Class) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if !rateLimitEqual(s.limit, limit) {
+		s.limit = limit
+		s.bucket = NewTokenBucket(limit, now)
+	}
+	s.limited = limit != nil
+	old := s.byID
+	s.byID = make(map[sig_mgmt.SessionType]*queue, len(classes))
+	s.queues = make([]*queue, 0, len(classes))
+	for _, class := range classes {
+		id := class.Session.ID()
+		q, ok := old[id]
+		if ok {
+			delete(old, id)
+			if !rateLimitEqual(q.class.Limit, class.Limit) {
+				q.bucket = NewTokenBucket(class.Limit, now)
+			}
+			q.class = class
+		} else {
+			q = &queue{
+				class:  class,
+				bucket: NewTokenBucket(class.Limit, now),
+				dropped: metrics.EgressPktsRateLimit.WithLabelValues(s.ia.String(),
+					id.String()),
+			}
+		}
+		s.limited = s.limited || class.Limit != nil
+		s.byID[id] = q
+		s.queues = append(s.queues, q)
+	}
+	sort.SliceStable(s.queues, func(i, j int) bool {
+		return s.queues[i].class.Priority > s.queues[j].class.Priority
+	})
+	for _, q := range old {
+		s.pending -= len(q.pkts)
+		release(q.pkts)
+	}
+}
+
+// Enqueue queues the packet for the session. If the queue of the session is
+// full, the packet is dropped.
+func (s *Scheduler) Enqueue(sess iface.Session, buf common.RawBytes) {
+	s.mu.Lock()
+	q, ok := s.byID[sess.ID()]
+	if !ok || (!s.limited && s.pending == 0) {
+		s.mu.Unlock()
+		write(sess, buf)
+		return
+	}
+	defer s.mu.Unlock()
+	if len(q.pkts) >= QueueCap {
+		q.dropped.Inc()
+		release([]common.RawBytes{buf})
+		return
+	}
+	q.pkts = append(q.pkts, buf)
+	s.pending++
+}
+
+// Pending returns the number of queued packets.
+func (s *Scheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// Send sends the queued packets that are within the rate limits at the given
+// time to their sessions.
+func (s *Scheduler) Send(now time.Time) {
+	s.mu.Lock()
+	s.out = s.out[:0]
+Top:
+	for _, q := range s.queues {
+		for len(q.pkts) > 0 {
+			if !s.bucket.Allow(now) {
+				break Top
+			}
+			if !q.bucket.Allow(now) {
+				// Sessions with a lower priority can still send.
+				break
+			}
+			buf := q.pkts[0]
+			q.pkts[0] = nil
+			q.pkts = q.pkts[1:]
+			s.bucket.Take(len(buf))
+			q.bucket.Take(len(buf))
+			s.out = append(s.out, outPkt{sess: q.class.Session, buf: buf})
+		}
+	}
+	s.pending -= len(s.out)
+	s.mu.Unlock()
+	// Writing to the session rings can block, thus it is done without holding
+	// the lock.
+	for i, pkt := range s.out {
+		write(pkt.sess, pkt.buf)
+		s.out[i] = outPkt{}
+	}
+}
+
+// Close drops all queued packets.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, q := range s.queues {
+		release(q.pkts)
+		q.pkts = nil
+	}
+	s.pending = 0
+}
+
+func write(sess iface.Session, buf common.RawBytes) {
+	if n, _ := sess.Ring().Write(ringbuf.EntryList{buf}, true); n < 0 {
+		// The session was closed.
+		release([]common.RawBytes{buf})
+	}
+}
+
+// release returns the buffers to the free buffer pool.
+func release(bufs []common.RawBytes) {
+	for _, buf := range bufs {
+		iface.EgressFreePkts.Write(ringbuf.EntryList{buf}, true)
+	}
+}
+
+func rateLimitEqual(a, b *sigjson.RateLimit) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -16,11 +16,15 @@
 package selector
 
 import (
+	"sync/atomic"
+
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pktcls"
 	"github.com/scionproto/scion/go/sig/egress/iface"
 )
 
 var _ iface.SessionSelector = (*SingleSession)(nil)
+var _ iface.SessionSelector = (*ClassSelector)(nil)
 
 // SingleSession implements iface.SessionSelector, returning the contained
 // session on ChooseSess.
@@ -31,3 +35,47 @@ type SingleSession struct {
 func (ss *SingleSession) ChooseSess(b common.RawBytes) iface.Session {
 	return ss.Session
 }
+
+// ClassSession associates a traffic class with the session that is used for
+// the packets of the class.
+type ClassSession struct {
+	Class   *pktcls.Class
+	Session iface.Session
+}
+
+// ClassSelector implements iface.SessionSelector. On ChooseSess, it returns the
+// session of the first class the packet matches, or the default session if the
+// packet matches no class. The classes can be replaced while the selector is
+// in use.
+type ClassSelector struct {
+	Default iface.Session
+	classes atomic.Value
+}
+
+// NewClassSelector creates a selector without classes that returns the
+// default session for all packets.
+func NewClassSelector(def iface.Session) *ClassSelector {
+	cs := &ClassSelector{Default: def}
+	cs.classes.Store([]ClassSession(nil))
+	return cs
+}
+
+// SetClasses replaces the classes of the selector. The classes are evaluated in
+// order.
+func (cs *ClassSelector) SetClasses(classes []ClassSession) {
+	cs.classes.Store(append([]ClassSession(nil), classes...))
+}
+
+func (cs *ClassSelector) ChooseSess(b common.RawBytes) iface.Session {
+	classes := cs.classes.Load().([]ClassSession)
+	if len(classes) == 0 {
+		return cs.Default
+	}
+	pkt := pktcls.NewPacket(b)
+	for _, c := range classes {
+		if c.Class.Eval(pkt) {
+			return c.Session
+		}
+	}
+	return cs.Default
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/pktcls"
+	"github.com/scionproto/scion/go/sig/egress/iface"
+	"github.com/scionproto/scion/go/sig/egress/iface/mock_iface"
+	"github.com/scionproto/scion/go/sig/egress/selector"
+)
+
+func TestClassSelectorChooseSess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	def := mock_iface.NewMockSession(ctrl)
+	voip := mock_iface.NewMockSession(ctrl)
+	bulk := mock_iface.NewMockSession(ctrl)
+	_, bulkNet, _ := net.ParseCIDR("10.0.0.0/8")
+	classes := []selector.ClassSession{
+		{
+			Class:   pktcls.NewClass("voip", pktcls.NewCondIPv4(&pktcls.IPv4MatchDSCP{DSCP: 46})),
+			Session: voip,
+		},
+		{
+			Class: pktcls.NewClass("bulk",
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{Net: bulkNet})),
+			Session: bulk,
+		},
+	}
+
+	tests := map[string]struct {
+		Classes  []selector.ClassSession
+		Packet   common.RawBytes
+		Expected iface.Session
+	}{
+		"no classes": {
+			Packet:   ipv4Packet(t, net.IP{10, 0, 0, 1}, 46<<2),
+			Expected: def,
+		},
+		"first matching class": {
+			Classes:  classes,
+			Packet:   ipv4Packet(t, net.IP{10, 0, 0, 1}, 46<<2),
+			Expected: voip,
+		},
+		"second class": {
+			Classes:  classes,
+			Packet:   ipv4Packet(t, net.IP{10, 0, 0, 1}, 0),
+			Expected: bulk,
+		},
+		"no matching class": {
+			Classes:  classes,
+			Packet:   ipv4Packet(t, net.IP{192, 0, 2, 1}, 0),
+			Expected: def,
+		},
+		"not IPv4": {
+			Classes:  classes,
+			Packet:   common.RawBytes{0x60, 0, 0, 0},
+			Expected: def,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := selector.NewClassSelector(def)
+			s.SetClasses(test.Classes)
+			assert.Equal(t, test.Expected, s.ChooseSess(test.Packet))
+		})
+	}
+}
+
+func ipv4Packet(t *testing.T, src net.IP, tos uint8) common.RawBytes {
+	buf := gopacket.NewSerializeBuffer()
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		TOS:      tos,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    src,
+		DstIP:    net.IP{192, 0, 2, 2},
+	}
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, ip,
+		gopacket.Payload([]byte{1, 2, 3, 4}))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
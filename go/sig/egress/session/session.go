@@ -29,6 +29,7 @@ import (
 	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/pathpol"
 	"github.com/scionproto/scion/go/lib/pktdisp"
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/lib/snet"
@@ -60,6 +61,16 @@ type Session struct {
 	pktDispStop    chan struct{}
 	pktDispStopped chan struct{}
 	workerStopped  chan struct{}
+	// maxPaths is the number of paths the frames are striped over. Values
+	// below 2 disable multipath. It is accessed atomically.
+	maxPaths int32
+	// lastReply is the time in Unix nanoseconds of the last PollRep that
+	// answered a poll of the session monitor. It is accessed atomically.
+	lastReply int64
+	// announced holds the IP prefixes that the remote SIG announces, changes
+	// are signaled on announcedC.
+	announced  atomic.Value
+	announcedC chan struct{}
 }
 
 func NewSession(dstIA addr.IA, sessId sig_mgmt.SessionType, logger log.Logger,
@@ -67,13 +78,16 @@ func NewSession(dstIA addr.IA, sessId sig_mgmt.SessionType, logger log.Logger,
 
 	var err error
 	s := &Session{
-		logger: logger.New("sessId", sessId),
-		ia:     dstIA,
-		SessId: sessId,
-		pool:   pool,
+		logger:   logger.New("sessId", sessId),
+		ia:       dstIA,
+		SessId:   sessId,
+		pool:     pool,
+		maxPaths: 1,
 	}
 	s.currRemote.Store((*iface.RemoteInfo)(nil))
 	s.healthy.Store(false)
+	s.announced.Store([]*net.IPNet(nil))
+	s.announcedC = make(chan struct{}, 1)
 	s.ring = ringbuf.New(64, nil, fmt.Sprintf("egress_%s_%s", dstIA, sessId))
 	// Not using a fixed local port, as this is for outgoing data only.
 	s.conn, err = sigcmn.Network.Listen(context.Background(), "udp",
@@ -96,6 +110,48 @@ func (s *Session) Logger() log.Logger {
 	return s.logger
 }
 
+// SetMaxPaths sets the number of disjoint paths the frames of the session are
+// striped over. Values below 2 disable multipath.
+func (s *Session) SetMaxPaths(n int) {
+	atomic.StoreInt32(&s.maxPaths, int32(n))
+}
+
+// MaxPaths returns the number of paths the frames of the session are striped
+// over.
+func (s *Session) MaxPaths() int {
+	return int(atomic.LoadInt32(&s.maxPaths))
+}
+
+// LastReply returns the time of the last PollRep from the remote SIG that
+// answered a poll of the session monitor. It is zero if no reply was received.
+func (s *Session) LastReply() time.Time {
+	nanos := atomic.LoadInt64(&s.lastReply)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// Announced returns the IP prefixes that the remote SIG currently announces.
+// Remote SIGs only announce their prefixes to the default session.
+func (s *Session) Announced() []*net.IPNet {
+	return s.announced.Load().([]*net.IPNet)
+}
+
+// AnnouncedChanged returns a channel that signals that the prefixes announced
+// by the remote SIG changed.
+func (s *Session) AnnouncedChanged() <-chan struct{} {
+	return s.announcedC
+}
+
+func (s *Session) setAnnounced(nets []*net.IPNet) {
+	s.announced.Store(nets)
+	select {
+	case s.announcedC <- struct{}{}:
+	default:
+	}
+}
+
 func (s *Session) Start() {
 	go func() {
 		defer log.HandlePanic()
@@ -168,8 +224,16 @@ type PathPool struct {
 
 var _ iface.PathPool = (*PathPool)(nil)
 
-func NewPathPool(dst addr.IA) (*PathPool, error) {
-	pool, err := sigcmn.PathMgr.Watch(context.TODO(), sigcmn.IA, dst)
+// NewPathPool creates a pool of the paths to dst. If policy is not nil, the
+// pool only contains the paths that satisfy the policy.
+func NewPathPool(dst addr.IA, policy *pathpol.Policy) (*PathPool, error) {
+	var pool *pathmgr.SyncPaths
+	var err error
+	if policy != nil {
+		pool, err = sigcmn.PathMgr.WatchFilter(context.TODO(), sigcmn.IA, dst, policy)
+	} else {
+		pool, err = sigcmn.PathMgr.Watch(context.TODO(), sigcmn.IA, dst)
+	}
 	if err != nil {
 		return nil, common.NewBasicError("Unable to register watch", err)
 	}
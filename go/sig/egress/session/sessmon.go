@@ -16,20 +16,24 @@
 package session
 
 import (
+	"bytes"
 	"context"
+	"net"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
-	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/sigdisp"
 	"github.com/scionproto/scion/go/sig/egress/iface"
 	"github.com/scionproto/scion/go/sig/egress/siginfo"
 	"github.com/scionproto/scion/go/sig/internal/metrics"
 	"github.com/scionproto/scion/go/sig/internal/sigcmn"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
 const (
@@ -38,6 +42,12 @@ const (
 	tout          = 1 * time.Second
 	writeTout     = 100 * time.Millisecond
 	pathExpiryLen = 10 * time.Second
+	// The prefixes announced by the remote SIG are withdrawn if they are not
+	// refreshed for this long.
+	announceTimeout = 10 * time.Second
+	// maxStripeWeight is the weight of the stripe with the lowest RTT. The
+	// weights of the other stripes are scaled down proportionally to their RTT.
+	maxStripeWeight = 100
 )
 
 // sessMonitor is responsible for monitoring a session, polling remote SIGs, and switching
@@ -60,6 +70,19 @@ type sessMonitor struct {
 	updateMsgId sig_mgmt.MsgIdType
 	// the last time a PollRep was received.
 	lastReply time.Time
+	// the paths that are probed in multipath mode, keyed by the id of the
+	// outstanding PollReq.
+	stripeProbes map[sig_mgmt.MsgIdType]*iface.SessPath
+	// the ephemeral key pair that is sent in the PollReqs if the frames are
+	// protected, and the time it was generated.
+	keyPair     *sigcrypto.KeyPair
+	keyPairTime time.Time
+	// the public keys the key of the remote Info was derived from.
+	keyPubs [2][]byte
+	// the prefixes the remote SIG announced, and the time they were last
+	// refreshed.
+	announced     []*net.IPNet
+	announcedTime time.Time
 }
 
 func newSessMonitor(sess *Session) *sessMonitor {
@@ -71,6 +94,7 @@ func newSessMonitor(sess *Session) *sessMonitor {
 		sess:         sess,
 		pool:         sess.pool,
 		sessPathPool: iface.NewSessPathPool(),
+		stripeProbes: make(map[sig_mgmt.MsgIdType]*iface.SessPath),
 	}
 }
 
@@ -102,7 +126,10 @@ Top:
 		case <-reqTick.C:
 			sm.updatePaths()
 			sm.updateRemote()
+			sm.updateKeyPair()
 			sm.sendReq()
+			sm.updateStripes()
+			sm.expireAnnounced()
 		case rpld := <-regc:
 			sm.handleRep(rpld)
 		case <-pathExpiryTick.C:
@@ -225,6 +252,12 @@ func (sm *sessMonitor) updateRemote() {
 func (sm *sessMonitor) updateSessSnap() {
 	// Copy the remote to avoid capturing the object in the session.
 	remote := sm.smRemote.Copy()
+	// The stripes are only valid for the path they were selected for. They are
+	// selected again on the next tick.
+	if len(remote.Stripes) > 0 && (remote.SessPath == nil ||
+		remote.Stripes[0].SessPath.Key() != remote.SessPath.Key()) {
+		remote.Stripes = nil
+	}
 	// XXX(roosd): Data traffic should never be sent to a SVC address if avoidable.
 	if remote.Sig.Host.Equal(addr.SvcSIG) {
 		old := sm.sess.Remote()
@@ -271,9 +304,17 @@ func (sm *sessMonitor) sendReq() {
 		return
 	}
 	sm.updateMsgId = sig_mgmt.MsgIdType(time.Now().UnixNano())
+	sm.sendPoll(sm.updateMsgId, sm.smRemote.SessPath)
+}
+
+// sendPoll sends a PollReq with the given id to the remote SIG over path.
+func (sm *sessMonitor) sendPoll(id sig_mgmt.MsgIdType, path *iface.SessPath) {
 	mgmtAddr := sigcmn.GetMgmtAddr()
-	spld, err := sig_mgmt.NewPld(sm.updateMsgId, sig_mgmt.NewPollReq(&mgmtAddr,
-		sm.sess.SessId))
+	req := sig_mgmt.NewPollReq(&mgmtAddr, sm.sess.SessId)
+	if sm.keyPair != nil {
+		req.PubKey = sm.keyPair.Public
+	}
+	spld, err := sig_mgmt.NewPld(id, req)
 	if err != nil {
 		sm.logger.Error("sessMonitor: Error creating SIGCtrl payload", "err", err)
 		return
@@ -283,7 +324,7 @@ func (sm *sessMonitor) sendReq() {
 		sm.logger.Error("sessMonitor: Error creating Ctrl payload", "err", err)
 		return
 	}
-	scpld, err := cpld.SignedPld(context.TODO(), infra.NullSigner)
+	scpld, err := cpld.SignedPld(context.TODO(), sigcmn.Signer)
 	if err != nil {
 		sm.logger.Error("sessMonitor: Error creating signed Ctrl payload", "err", err)
 		return
@@ -293,10 +334,7 @@ func (sm *sessMonitor) sendReq() {
 		sm.logger.Error("sessMonitor: Error packing signed Ctrl payload", "err", err)
 		return
 	}
-	raddr := sm.smRemote.Sig.CtrlSnetAddr(
-		sm.smRemote.SessPath.Path().Path(),
-		sm.smRemote.SessPath.Path().UnderlayNextHop(),
-	)
+	raddr := sm.smRemote.Sig.CtrlSnetAddr(path.Path().Path(), path.Path().UnderlayNextHop())
 	// XXX(kormat): if this blocks, both the sessMon and egress worker
 	// goroutines will block. Can't just use SetWriteDeadline, as both
 	// goroutines write to it.
@@ -307,6 +345,140 @@ func (sm *sessMonitor) sendReq() {
 	metrics.SessionProbes.WithLabelValues(sm.sess.IA().String(), sm.sess.SessId.String()).Inc()
 }
 
+// updateKeyPair generates a new ephemeral key pair if the frames are protected
+// and the current key pair is due for renewal. The remote SIG derives a new key
+// when it receives the new public key.
+func (sm *sessMonitor) updateKeyPair() {
+	if sigcmn.Encryption == "" {
+		return
+	}
+	if sm.keyPair != nil && time.Since(sm.keyPairTime) < sigcrypto.RekeyInterval {
+		return
+	}
+	keyPair, err := sigcrypto.NewKeyPair()
+	if err != nil {
+		sm.logger.Error("sessMonitor: Unable to generate key pair", "err", err)
+		return
+	}
+	sm.keyPair, sm.keyPairTime = keyPair, time.Now()
+}
+
+// updateKey derives the key of the session from the public key in a PollRep
+// that answers the last PollReq. It returns true if the key changed.
+func (sm *sessMonitor) updateKey(peerPub []byte) bool {
+	if sm.keyPair == nil {
+		return false
+	}
+	if len(peerPub) == 0 {
+		sm.logger.Error("sessMonitor: Remote SIG does not protect frames", "remote", sm.smRemote)
+		return false
+	}
+	if sm.smRemote.Key != nil && bytes.Equal(sm.keyPubs[0], sm.keyPair.Public) &&
+		bytes.Equal(sm.keyPubs[1], peerPub) {
+		return false
+	}
+	sess := sigcrypto.Session{Src: sigcmn.IA, Dst: sm.sess.IA(), ID: sm.sess.SessId}
+	key, err := sm.keyPair.DeriveKey(sigcmn.Encryption, peerPub, sess, true)
+	if err != nil {
+		sm.logger.Error("sessMonitor: Unable to derive key", "err", err)
+		return false
+	}
+	sm.smRemote.Key = key
+	sm.keyPubs = [2][]byte{sm.keyPair.Public, append([]byte(nil), peerPub...)}
+	sm.logger.Info("sessMonitor: New key established", "remote", sm.smRemote)
+	return true
+}
+
+// updateStripes selects the paths the frames are striped over in multipath
+// mode, and probes them to measure their RTT. Probes that are not answered in
+// time count as failures of the path, which excludes it from striping until
+// the failure expires.
+func (sm *sessMonitor) updateStripes() {
+	now := time.Now()
+	for id, path := range sm.stripeProbes {
+		if now.Sub(id.Time()) > tout {
+			sm.sessPathPool.Timeout(path, id.Time())
+			delete(sm.stripeProbes, id)
+		}
+	}
+	var stripes []iface.Stripe
+	maxPaths := sm.sess.MaxPaths()
+	if maxPaths > 1 && sm.smRemote.SessPath != nil && sm.sess.Healthy() {
+		paths := sm.sessPathPool.GetDisjoint(sm.smRemote.SessPath, maxPaths)
+		// The current path is already probed by sendReq.
+		for _, path := range paths[1:] {
+			id := sm.newStripeProbeId()
+			sm.stripeProbes[id] = path
+			sm.sendPoll(id, path)
+		}
+		stripes = sm.weighStripes(paths)
+	}
+	if stripesEqual(stripes, sm.smRemote.Stripes) {
+		return
+	}
+	sm.smRemote.Stripes = stripes
+	sm.updateSessSnap()
+	metrics.SessionStripes.WithLabelValues(sm.sess.IA().String(),
+		sm.sess.SessId.String()).Set(float64(len(stripes)))
+}
+
+// newStripeProbeId returns a message id that is not used by any outstanding
+// probe.
+func (sm *sessMonitor) newStripeProbeId() sig_mgmt.MsgIdType {
+	id := sig_mgmt.MsgIdType(time.Now().UnixNano())
+	for {
+		if _, ok := sm.stripeProbes[id]; !ok && id != sm.updateMsgId {
+			return id
+		}
+		id++
+	}
+}
+
+// weighStripes assigns weights to the paths that are inversely proportional to
+// their RTT. Paths without RTT measurement are left out, except for the
+// current path of the session, which is the first path. If less than two
+// paths remain, striping is disabled and nil is returned.
+func (sm *sessMonitor) weighStripes(paths []*iface.SessPath) []iface.Stripe {
+	var minRTT time.Duration
+	for _, path := range paths {
+		rtt := sm.sessPathPool.RTT(path.Key())
+		if rtt != 0 && (minRTT == 0 || rtt < minRTT) {
+			minRTT = rtt
+		}
+	}
+	stripes := make([]iface.Stripe, 0, len(paths))
+	for i, path := range paths {
+		rtt := sm.sessPathPool.RTT(path.Key())
+		if rtt == 0 {
+			if i == 0 {
+				stripes = append(stripes, iface.Stripe{SessPath: path, Weight: maxStripeWeight})
+			}
+			continue
+		}
+		weight := int(maxStripeWeight * int64(minRTT) / int64(rtt))
+		if weight < 1 {
+			weight = 1
+		}
+		stripes = append(stripes, iface.Stripe{SessPath: path, Weight: weight})
+	}
+	if len(stripes) < 2 {
+		return nil
+	}
+	return stripes
+}
+
+func stripesEqual(a, b []iface.Stripe) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Weight != b[i].Weight || a[i].SessPath.Key() != b[i].SessPath.Key() {
+			return false
+		}
+	}
+	return true
+}
+
 func (sm *sessMonitor) handleRep(rpld *sigdisp.RegPld) {
 	pollRep, ok := rpld.P.(*sig_mgmt.PollRep)
 	if !ok {
@@ -322,6 +494,13 @@ func (sm *sessMonitor) handleRep(rpld *sigdisp.RegPld) {
 	metrics.SessionProbeReplies.WithLabelValues(sm.sess.IA().String(),
 		sm.sess.SessId.String()).Inc()
 
+	// Replies to the probes of the stripes only update the path statistics.
+	if path, ok := sm.stripeProbes[rpld.Id]; ok {
+		delete(sm.stripeProbes, rpld.Id)
+		sm.sessPathPool.Reply(path, rpld.Id.Time())
+		return
+	}
+
 	// Inform SessPathPool that a reply has arrived.
 	if sm.smRemote.SessPath != nil {
 		sm.sessPathPool.Reply(sm.smRemote.SessPath, rpld.Id.Time())
@@ -331,6 +510,7 @@ func (sm *sessMonitor) handleRep(rpld *sigdisp.RegPld) {
 	// the last poll we sent.
 	if sm.updateMsgId == rpld.Id {
 		sm.lastReply = time.Now()
+		atomic.StoreInt64(&sm.sess.lastReply, sm.lastReply.UnixNano())
 		// Update sessmon's remote.
 		sm.smRemote.Sig = &siginfo.Sig{
 			IA:          sm.smRemote.Sig.IA,
@@ -338,6 +518,10 @@ func (sm *sessMonitor) handleRep(rpld *sigdisp.RegPld) {
 			CtrlL4Port:  int(pollRep.Addr.Ctrl.Port),
 			EncapL4Port: int(pollRep.Addr.Data.Port),
 		}
+		keyChanged := sm.updateKey(pollRep.PubKey)
+		if sm.sess.SessId == 0 {
+			sm.updateAnnounced(pollRep.Prefixes)
+		}
 		// Update session's remote, if needed.
 		sessRemote := sm.sess.Remote()
 		if sessRemote == nil || !sm.smRemote.Sig.Equal(sessRemote.Sig) {
@@ -346,6 +530,8 @@ func (sm *sessMonitor) handleRep(rpld *sigdisp.RegPld) {
 				"msgId", rpld.Id, "remote", sm.smRemote)
 			metrics.SessionRemoteSwitched.WithLabelValues(sm.sess.IA().String(),
 				sm.sess.SessId.String()).Inc()
+		} else if keyChanged {
+			sm.updateSessSnap()
 		}
 		sm.setHealth(true)
 
@@ -362,6 +548,52 @@ func (sm *sessMonitor) handleRep(rpld *sigdisp.RegPld) {
 	}
 }
 
+// updateAnnounced updates the prefixes announced by the remote SIG from the
+// prefixes in a PollRep.
+func (sm *sessMonitor) updateAnnounced(prefixes []*sig_mgmt.Prefix) {
+	nets := make([]*net.IPNet, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		ipnet, err := prefix.IPNet()
+		if err != nil {
+			sm.logger.Error("sessMonitor: Ignoring invalid announced prefix",
+				"prefix", prefix, "err", err)
+			continue
+		}
+		nets = append(nets, ipnet)
+	}
+	sort.Slice(nets, func(i, j int) bool { return nets[i].String() < nets[j].String() })
+	sm.announcedTime = time.Now()
+	if netsEqual(nets, sm.announced) {
+		return
+	}
+	sm.announced = nets
+	sm.sess.setAnnounced(nets)
+	sm.logger.Info("sessMonitor: Announced prefixes changed", "prefixes", nets)
+}
+
+// expireAnnounced withdraws the prefixes announced by the remote SIG if they
+// were not refreshed in time.
+func (sm *sessMonitor) expireAnnounced() {
+	if len(sm.announced) == 0 || time.Since(sm.announcedTime) < announceTimeout {
+		return
+	}
+	sm.logger.Info("sessMonitor: Announced prefixes expired", "prefixes", sm.announced)
+	sm.announced = nil
+	sm.sess.setAnnounced(nil)
+}
+
+func netsEqual(a, b []*net.IPNet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
 func (sm *sessMonitor) setHealth(healthy bool) {
 	sm.sess.healthy.Store(healthy)
 	var healthVal float64
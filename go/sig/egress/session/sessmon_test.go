@@ -0,0 +1,165 @@
+// Copyright 2020 Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/log"
+)
+
+func TestUpdateAnnounced(t *testing.T) {
+	tests := map[string]struct {
+		Announced []string
+		Prefixes  []*sig_mgmt.Prefix
+		Expected  []string
+		Changed   bool
+	}{
+		"first announcement": {
+			Prefixes: []*sig_mgmt.Prefix{
+				newPrefix(t, "198.51.100.0/24"),
+				newPrefix(t, "192.0.2.0/24"),
+			},
+			Expected: []string{"192.0.2.0/24", "198.51.100.0/24"},
+			Changed:  true,
+		},
+		"unchanged": {
+			Announced: []string{"192.0.2.0/24", "198.51.100.0/24"},
+			Prefixes: []*sig_mgmt.Prefix{
+				newPrefix(t, "198.51.100.0/24"),
+				newPrefix(t, "192.0.2.0/24"),
+			},
+			Expected: []string{"192.0.2.0/24", "198.51.100.0/24"},
+			Changed:  false,
+		},
+		"withdrawn": {
+			Announced: []string{"192.0.2.0/24", "198.51.100.0/24"},
+			Prefixes:  []*sig_mgmt.Prefix{newPrefix(t, "192.0.2.0/24")},
+			Expected:  []string{"192.0.2.0/24"},
+			Changed:   true,
+		},
+		"invalid prefix ignored": {
+			Prefixes: []*sig_mgmt.Prefix{
+				{IP: []byte{192, 0, 2}, PrefixLen: 24},
+				newPrefix(t, "2001:db8::/32"),
+			},
+			Expected: []string{"2001:db8::/32"},
+			Changed:  true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sm := newTestSessMonitor(t, tc.Announced, time.Now().Add(-announceTimeout/2))
+			sm.updateAnnounced(tc.Prefixes)
+			assert.Equal(t, tc.Expected, netStrings(sm.announced))
+			assert.Equal(t, tc.Expected, netStrings(sm.sess.Announced()))
+			assert.Equal(t, tc.Changed, changed(sm.sess))
+			assert.WithinDuration(t, time.Now(), sm.announcedTime, time.Second)
+		})
+	}
+}
+
+func TestExpireAnnounced(t *testing.T) {
+	tests := map[string]struct {
+		Announced []string
+		Age       time.Duration
+		Expected  []string
+		Changed   bool
+	}{
+		"fresh": {
+			Announced: []string{"192.0.2.0/24"},
+			Age:       announceTimeout / 2,
+			Expected:  []string{"192.0.2.0/24"},
+			Changed:   false,
+		},
+		"expired": {
+			Announced: []string{"192.0.2.0/24"},
+			Age:       2 * announceTimeout,
+			Expected:  []string{},
+			Changed:   true,
+		},
+		"nothing announced": {
+			Age:      2 * announceTimeout,
+			Expected: []string{},
+			Changed:  false,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sm := newTestSessMonitor(t, tc.Announced, time.Now().Add(-tc.Age))
+			sm.expireAnnounced()
+			assert.Equal(t, tc.Expected, netStrings(sm.announced))
+			assert.Equal(t, tc.Expected, netStrings(sm.sess.Announced()))
+			assert.Equal(t, tc.Changed, changed(sm.sess))
+		})
+	}
+}
+
+// newTestSessMonitor creates a session monitor whose remote SIG announced the
+// networks at the given time.
+func newTestSessMonitor(t *testing.T, announced []string,
+	announcedTime time.Time) *sessMonitor {
+
+	var nets []*net.IPNet
+	for _, s := range announced {
+		nets = append(nets, parseNet(t, s))
+	}
+	sess := &Session{announcedC: make(chan struct{}, 1)}
+	sess.announced.Store(nets)
+	return &sessMonitor{
+		logger:        log.Root(),
+		sess:          sess,
+		announced:     nets,
+		announcedTime: announcedTime,
+	}
+}
+
+// changed returns whether a change of the announced networks was signaled.
+func changed(sess *Session) bool {
+	select {
+	case <-sess.AnnouncedChanged():
+		return true
+	default:
+		return false
+	}
+}
+
+func netStrings(nets []*net.IPNet) []string {
+	res := make([]string, 0, len(nets))
+	for _, ipnet := range nets {
+		res = append(res, ipnet.String())
+	}
+	return res
+}
+
+func newPrefix(t *testing.T, s string) *sig_mgmt.Prefix {
+	t.Helper()
+
+	return sig_mgmt.NewPrefix(parseNet(t, s))
+}
+
+func parseNet(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+
+	_, ipnet, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return ipnet
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/sig/egress/iface"
+)
+
+// Status is a snapshot of the state of a session.
+type Status struct {
+	ID      sig_mgmt.SessionType
+	Healthy bool
+	// MaxPaths is the number of paths the frames are striped over.
+	MaxPaths int
+	// Remote is the remote SIG and the path the frames are sent to. It is nil
+	// if no remote SIG was found yet.
+	Remote *RemoteStatus `json:",omitempty"`
+	// LastReply is the time of the last poll reply from the remote SIG.
+	LastReply time.Time
+	// Announced are the IP prefixes that the remote SIG announces.
+	Announced []string `json:",omitempty"`
+}
+
+// RemoteStatus is a snapshot of the remote SIG of a session.
+type RemoteStatus struct {
+	Sig  string
+	Path *PathStatus `json:",omitempty"`
+	// Stripes are the paths the frames are striped over in multipath mode.
+	Stripes []StripeStatus `json:",omitempty"`
+	// Encrypted is true if a key to protect the frames is established.
+	Encrypted bool
+}
+
+// PathStatus describes a path of a session.
+type PathStatus struct {
+	Fingerprint string
+	Path        string
+	Expiry      time.Time
+	MTU         uint16
+}
+
+// StripeStatus describes a path that frames are striped over.
+type StripeStatus struct {
+	Path   *PathStatus
+	Weight int
+}
+
+// Status returns a snapshot of the state of the session.
+func (s *Session) Status() Status {
+	status := Status{
+		ID:        s.SessId,
+		Healthy:   s.Healthy(),
+		MaxPaths:  s.MaxPaths(),
+		Remote:    newRemoteStatus(s.Remote()),
+		LastReply: s.LastReply(),
+	}
+	for _, ipnet := range s.Announced() {
+		status.Announced = append(status.Announced, ipnet.String())
+	}
+	return status
+}
+
+func newRemoteStatus(remote *iface.RemoteInfo) *RemoteStatus {
+	if remote == nil {
+		return nil
+	}
+	status := &RemoteStatus{
+		Sig:       remote.Sig.String(),
+		Path:      newPathStatus(remote.SessPath),
+		Encrypted: remote.Key != nil,
+	}
+	for _, stripe := range remote.Stripes {
+		status.Stripes = append(status.Stripes, StripeStatus{
+			Path:   newPathStatus(stripe.SessPath),
+			Weight: stripe.Weight,
+		})
+	}
+	return status
+}
+
+func newPathStatus(sessPath *iface.SessPath) *PathStatus {
+	if sessPath == nil {
+		return nil
+	}
+	status := &PathStatus{
+		Fingerprint: sessPath.Key().String(),
+		Path:        fmt.Sprint(sessPath.Path()),
+	}
+	if md := sessPath.Path().Metadata(); md != nil {
+		status.Expiry = md.Expiry()
+		status.MTU = md.MTU()
+	}
+	return status
+}
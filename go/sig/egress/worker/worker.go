@@ -34,6 +34,7 @@ import (
 	"github.com/scionproto/scion/go/sig/egress/siginfo"
 	"github.com/scionproto/scion/go/sig/internal/metrics"
 	"github.com/scionproto/scion/go/sig/internal/sigcmn"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
 //   SIG Frame Header, used to encapsulate SIG to SIG traffic. The sequence
@@ -69,11 +70,15 @@ type worker struct {
 	writer        SCIONWriter
 	currSig       *siginfo.Sig
 	currPathEntry snet.Path
+	currKey       *sigcrypto.Key
 	frameSentCtrs metrics.CtrPair
 
 	epoch uint16
 	seq   uint32
 	pkts  ringbuf.EntryList
+	// stripeWeights are the current weights of the smooth weighted round robin
+	// over the stripes in multipath mode.
+	stripeWeights map[snet.PathFingerprint]int
 
 	// TODO(sustrik): This is used for testing only. The code should be refactored
 	// in such a way that it's not needed.
@@ -206,7 +211,18 @@ func (w *worker) write(f *frame) error {
 	}
 
 	f.writeHdr(w.sess.ID(), w.epoch, seq)
-	bytesWritten, err := w.writer.WriteTo(f.raw(), snetAddr)
+	raw := f.raw()
+	if sigcmn.Encryption != "" {
+		if w.currKey == nil {
+			metrics.FramesWithoutKey.Inc()
+			return nil
+		}
+		var err error
+		if raw, err = w.currKey.Seal(raw); err != nil {
+			return common.NewBasicError("Unable to protect frame", err)
+		}
+	}
+	bytesWritten, err := w.writer.WriteTo(raw, snetAddr)
 	if err != nil {
 		return common.NewBasicError("Egress write error", err)
 	}
@@ -217,17 +233,18 @@ func (w *worker) write(f *frame) error {
 
 func (w *worker) resetFrame(f *frame) {
 	var mtu uint16 = common.MinMTU
-	var addrLen, pathLen uint16
+	var addrLen, pathLen, overhead uint16
 	remote := w.sess.Remote()
 	if remote != nil {
 		w.currSig = remote.Sig
+		w.currKey = remote.Key
 		if w.currSig != nil {
 			addrLen = uint16(spkt.AddrHdrLen(w.currSig.Host,
 				addr.HostFromIP(sigcmn.DataAddr)))
 		}
 		w.currPathEntry = nil
-		if remote.SessPath != nil {
-			w.currPathEntry = remote.SessPath.Path()
+		if sessPath := w.nextPath(remote); sessPath != nil {
+			w.currPathEntry = sessPath.Path()
 		}
 		if w.currPathEntry != nil {
 			if md := w.currPathEntry.Metadata(); md != nil {
@@ -236,8 +253,36 @@ func (w *worker) resetFrame(f *frame) {
 			pathLen = uint16(len(w.currPathEntry.Path().Raw))
 		}
 	}
+	if sigcmn.Encryption != "" {
+		overhead = sigcrypto.Overhead
+	}
 	// FIXME(kormat): to do this properly, need to account for any ext headers.
-	f.reset(mtu - spkt.CmnHdrLen - addrLen - pathLen - l4.UDPLen)
+	f.reset(mtu - spkt.CmnHdrLen - addrLen - pathLen - l4.UDPLen - overhead)
+}
+
+// nextPath returns the path for the next frame. In multipath mode, the frames
+// are distributed over the stripes with smooth weighted round robin, otherwise
+// the session path is used.
+func (w *worker) nextPath(remote *iface.RemoteInfo) *iface.SessPath {
+	if len(remote.Stripes) == 0 {
+		return remote.SessPath
+	}
+	// Drop the weights of old stripes once in a while.
+	if w.stripeWeights == nil || len(w.stripeWeights) > 2*len(remote.Stripes) {
+		w.stripeWeights = make(map[snet.PathFingerprint]int, len(remote.Stripes))
+	}
+	var best *iface.SessPath
+	total := 0
+	for _, stripe := range remote.Stripes {
+		key := stripe.SessPath.Key()
+		w.stripeWeights[key] += stripe.Weight
+		total += stripe.Weight
+		if best == nil || w.stripeWeights[key] > w.stripeWeights[best.Key()] {
+			best = stripe.SessPath
+		}
+	}
+	w.stripeWeights[best.Key()] -= total
+	return best
 }
 
 type frame struct {
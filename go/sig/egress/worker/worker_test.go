@@ -173,3 +173,47 @@ func TestParsing(t *testing.T) {
 		tester.Run()
 	})
 }
+
+func TestNextPath(t *testing.T) {
+	p1 := iface.NewSessPath("p1", nil)
+	p2 := iface.NewSessPath("p2", nil)
+	p3 := iface.NewSessPath("p3", nil)
+
+	tests := map[string]struct {
+		Remote   *iface.RemoteInfo
+		Expected []*iface.SessPath
+	}{
+		"single path": {
+			Remote:   &iface.RemoteInfo{SessPath: p1},
+			Expected: []*iface.SessPath{p1, p1, p1},
+		},
+		"equal weights": {
+			Remote: &iface.RemoteInfo{
+				SessPath: p1,
+				Stripes:  []iface.Stripe{{SessPath: p1, Weight: 10}, {SessPath: p2, Weight: 10}},
+			},
+			Expected: []*iface.SessPath{p1, p2, p1, p2},
+		},
+		"weighted": {
+			Remote: &iface.RemoteInfo{
+				SessPath: p1,
+				Stripes: []iface.Stripe{
+					{SessPath: p1, Weight: 50},
+					{SessPath: p2, Weight: 25},
+					{SessPath: p3, Weight: 25},
+				},
+			},
+			Expected: []*iface.SessPath{p1, p2, p3, p1, p1, p2, p3, p1},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := &worker{}
+			var chosen []*iface.SessPath
+			for range test.Expected {
+				chosen = append(chosen, w.nextPath(test.Remote))
+			}
+			assert.Equal(t, test.Expected, chosen)
+		})
+	}
+}
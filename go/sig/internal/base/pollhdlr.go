@@ -17,19 +17,42 @@ package base
 
 import (
 	"context"
+	"net"
+	"sync/atomic"
 
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
 	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
-	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/sigdisp"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/sig/internal/sigcmn"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
-func PollReqHdlr() {
+// announced holds the prefixes that are announced to the remote SIGs.
+var announced atomic.Value
+
+// Announce sets the IP prefixes that are announced to the remote SIGs in the
+// replies to the polls of their default sessions.
+func Announce(nets []*net.IPNet) {
+	prefixes := make([]*sig_mgmt.Prefix, 0, len(nets))
+	for _, ipnet := range nets {
+		prefixes = append(prefixes, sig_mgmt.NewPrefix(ipnet))
+	}
+	announced.Store(prefixes)
+}
+
+func announcedPrefixes() []*sig_mgmt.Prefix {
+	prefixes, _ := announced.Load().([]*sig_mgmt.Prefix)
+	return prefixes
+}
+
+// PollReqHdlr replies to the poll requests of remote SIGs. If keys is not nil,
+// the frames of the sessions are protected, and the key exchange that is
+// initiated by the poll request is completed.
+func PollReqHdlr(keys *sigcrypto.Store) {
 	log.Info("PollReqHdlr: starting")
 	for rpld := range sigdisp.Dispatcher.PollReqC {
 		req, ok := rpld.P.(*sig_mgmt.PollReq)
@@ -40,7 +63,26 @@ func PollReqHdlr() {
 		}
 		addr := sig_mgmt.NewAddr(addr.HostFromIP(sigcmn.CtrlAddr), uint16(sigcmn.CtrlPort),
 			addr.HostFromIP(sigcmn.DataAddr), uint16(sigcmn.DataPort))
-		spld, err := sig_mgmt.NewPld(rpld.Id, sig_mgmt.NewPollRep(addr, req.Session))
+		rep := sig_mgmt.NewPollRep(addr, req.Session)
+		if req.Session == 0 {
+			rep.Prefixes = announcedPrefixes()
+		}
+		if keys != nil {
+			if len(req.PubKey) == 0 {
+				log.Error("PollReqHdlr: Remote SIG does not protect frames",
+					"src", rpld.Addr, "session", req.Session)
+				continue
+			}
+			pubKey, err := keys.Exchange(rpld.Addr.IA, req.Addr.Data.UDP(), req.Session,
+				req.PubKey)
+			if err != nil {
+				log.Error("PollReqHdlr: Key exchange failed", "src", rpld.Addr,
+					"session", req.Session, "err", err)
+				continue
+			}
+			rep.PubKey = pubKey
+		}
+		spld, err := sig_mgmt.NewPld(rpld.Id, rep)
 		if err != nil {
 			log.Error("PollReqHdlr: Error creating SIGCtrl payload", "err", err)
 			break
@@ -50,7 +92,7 @@ func PollReqHdlr() {
 			log.Error("PollReqHdlr: Error creating Ctrl payload", "err", err)
 			break
 		}
-		scpld, err := cpld.SignedPld(context.TODO(), infra.NullSigner)
+		scpld, err := cpld.SignedPld(context.TODO(), sigcmn.Signer)
 		if err != nil {
 			log.Error("PollReqHdlr: Error creating signed Ctrl payload", "err", err)
 			break
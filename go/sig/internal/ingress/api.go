@@ -24,9 +24,17 @@ import (
 	"github.com/scionproto/scion/go/lib/fatal"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/sig/internal/sigcmn"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
-func Init(tunIO io.ReadWriteCloser) {
+// dispatcher is the ingress dispatcher that is started by Init.
+var dispatcher *Dispatcher
+
+// Init starts the ingress dispatcher. reorderWindow is the number of frames
+// that are reordered per session and epoch, 0 disables reordering. If keys is
+// not nil, only frames that are protected with the key of their session are
+// accepted.
+func Init(tunIO io.ReadWriteCloser, reorderWindow int, keys *sigcrypto.Store) {
 	fatal.Check()
 	conn, err := sigcmn.Network.Listen(context.Background(), "udp",
 		&net.UDPAddr{IP: sigcmn.DataAddr, Port: sigcmn.DataPort}, addr.SvcNone)
@@ -35,6 +43,9 @@ func Init(tunIO io.ReadWriteCloser) {
 		fatal.Fatal(err)
 	}
 	d := NewDispatcher(tunIO, conn)
+	d.ReorderWindow = reorderWindow
+	d.Keys = keys
+	dispatcher = d
 	go func() {
 		defer log.HandlePanic()
 		if err := d.Run(); err != nil {
@@ -43,3 +54,12 @@ func Init(tunIO io.ReadWriteCloser) {
 		}
 	}()
 }
+
+// Status returns a snapshot of the state of the ingress workers. It returns
+// nil if the ingress dispatcher is not started.
+func Status() []WorkerStatus {
+	if dispatcher == nil {
+		return nil
+	}
+	return dispatcher.Status()
+}
@@ -18,6 +18,8 @@ package ingress
 import (
 	"fmt"
 	"io"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/addr"
@@ -28,6 +30,7 @@ import (
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/lib/sock/reliable"
 	"github.com/scionproto/scion/go/sig/internal/metrics"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
 const (
@@ -41,10 +44,17 @@ const (
 // source ISD-AS -> source host Addr -> Sess Id and hands it off to the
 // appropriate Worker, starting a new one if none currently exists.
 type Dispatcher struct {
+	// workersMtx protects the workers map. Only the dispatcher modifies the
+	// map, thus it reads the map without holding the lock.
+	workersMtx         sync.RWMutex
 	workers            map[string]*Worker
 	extConn            *snet.Conn
 	tunIO              io.ReadWriteCloser
 	framesRecvCounters map[metrics.CtrPairKey]metrics.CtrPair
+	// ReorderWindow is passed on to the workers.
+	ReorderWindow int
+	// Keys is passed on to the workers.
+	Keys *sigcrypto.Store
 }
 
 func NewDispatcher(tio io.ReadWriteCloser, conn *snet.Conn) *Dispatcher {
@@ -103,7 +113,11 @@ func (d *Dispatcher) dispatch(frame *FrameBuf, src *snet.UDPAddr) {
 	worker, ok := d.workers[dispatchStr]
 	if !ok {
 		worker = NewWorker(src, frame.sessId, d.tunIO)
+		worker.ReorderWindow = d.ReorderWindow
+		worker.Keys = d.Keys
+		d.workersMtx.Lock()
 		d.workers[dispatchStr] = worker
+		d.workersMtx.Unlock()
 		go func() {
 			defer log.HandlePanic()
 			worker.Run()
@@ -118,7 +132,9 @@ func (d *Dispatcher) cleanup() {
 	for key := range d.workers {
 		worker := d.workers[key]
 		if worker.markedForCleanup {
+			d.workersMtx.Lock()
 			delete(d.workers, key)
+			d.workersMtx.Unlock()
 			go func() {
 				defer log.HandlePanic()
 				worker.Stop()
@@ -129,6 +145,24 @@ func (d *Dispatcher) cleanup() {
 	}
 }
 
+// Status returns a snapshot of the state of all workers, sorted by remote and
+// session. It is safe to call while the dispatcher is running.
+func (d *Dispatcher) Status() []WorkerStatus {
+	d.workersMtx.RLock()
+	status := make([]WorkerStatus, 0, len(d.workers))
+	for _, worker := range d.workers {
+		status = append(status, worker.Status())
+	}
+	d.workersMtx.RUnlock()
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Remote != status[j].Remote {
+			return status[i].Remote < status[j].Remote
+		}
+		return status[i].SessionID < status[j].SessionID
+	})
+	return status
+}
+
 func (d *Dispatcher) updateMetrics(remoteIA addr.IAInt, sessId sig_mgmt.SessionType, read int) {
 	key := metrics.CtrPairKey{RemoteIA: remoteIA, SessId: sessId}
 	counters, ok := d.framesRecvCounters[key]
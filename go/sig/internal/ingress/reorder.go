@@ -0,0 +1,152 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"sort"
+	"time"
+
+	"github.com/scionproto/scion/go/sig/internal/metrics"
+)
+
+const (
+	// reorderTimeout is the maximum time a frame is held back waiting for
+	// the frames with lower sequence numbers.
+	reorderTimeout = 50 * time.Millisecond
+	// reorderPollInterval is the interval at which held back frames are
+	// checked for expiry if no new frames arrive.
+	reorderPollInterval = 5 * time.Millisecond
+)
+
+type pendingFrame struct {
+	frame   *FrameBuf
+	arrived time.Time
+}
+
+// reorderBuffer restores the order of the frames of an epoch before they are
+// inserted into the reassembly list. This is needed if the remote SIG stripes
+// the frames over several paths. Frames that arrive ahead of the next expected
+// sequence number are held back until the missing frames arrive. The missing
+// frames are considered lost if a frame arrives that is more than window
+// frames ahead, or if a frame has been held back for longer than
+// reorderTimeout.
+type reorderBuffer struct {
+	window  int
+	rlist   *ReassemblyList
+	next    int
+	pending map[int]pendingFrame
+}
+
+func newReorderBuffer(window int, rlist *ReassemblyList) *reorderBuffer {
+	return &reorderBuffer{
+		window:  window,
+		rlist:   rlist,
+		next:    -1,
+		pending: make(map[int]pendingFrame),
+	}
+}
+
+// Insert inserts the frame into the buffer, and passes all frames that are in
+// order on to the reassembly list.
+func (b *reorderBuffer) Insert(frame *FrameBuf, now time.Time) {
+	seqNr := frame.seqNr
+	if b.next < 0 {
+		b.next = seqNr
+	}
+	if seqNr < b.next {
+		// The frame was already considered lost.
+		metrics.FramesTooOld.Inc()
+		frame.Release()
+		return
+	}
+	if _, ok := b.pending[seqNr]; ok {
+		metrics.FramesDuplicated.Inc()
+		frame.Release()
+		return
+	}
+	if seqNr != b.next {
+		metrics.FramesReordered.Inc()
+	}
+	b.pending[seqNr] = pendingFrame{frame: frame, arrived: now}
+	if seqNr-b.next >= b.window {
+		b.skipTo(seqNr - b.window + 1)
+	}
+	b.drain()
+	b.Expire(now)
+}
+
+// Expire gives up on the missing frames before frames that have been held back
+// for longer than reorderTimeout.
+func (b *reorderBuffer) Expire(now time.Time) {
+	for {
+		oldest := -1
+		for seqNr, p := range b.pending {
+			if now.Sub(p.arrived) > reorderTimeout && (oldest < 0 || seqNr < oldest) {
+				oldest = seqNr
+			}
+		}
+		if oldest < 0 {
+			return
+		}
+		b.skipTo(oldest)
+		b.drain()
+	}
+}
+
+// Pending returns whether frames are held back.
+func (b *reorderBuffer) Pending() bool {
+	return len(b.pending) > 0
+}
+
+// Release releases all held back frames.
+func (b *reorderBuffer) Release() {
+	for seqNr, p := range b.pending {
+		p.frame.Release()
+		delete(b.pending, seqNr)
+	}
+}
+
+// skipTo passes all held back frames before seqNr on to the reassembly list,
+// and continues with seqNr as the next expected frame.
+func (b *reorderBuffer) skipTo(seqNr int) {
+	var skipped []int
+	for s := range b.pending {
+		if s < seqNr {
+			skipped = append(skipped, s)
+		}
+	}
+	sort.Ints(skipped)
+	for _, s := range skipped {
+		b.rlist.Insert(b.pending[s].frame)
+		delete(b.pending, s)
+	}
+	if seqNr > b.next {
+		b.next = seqNr
+	}
+}
+
+// drain passes the consecutive frames starting at the next expected sequence
+// number on to the reassembly list.
+func (b *reorderBuffer) drain() {
+	for {
+		p, ok := b.pending[b.next]
+		if !ok {
+			return
+		}
+		delete(b.pending, b.next)
+		b.rlist.Insert(p.frame)
+		b.next++
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/snet"
+	"github.com/scionproto/scion/go/lib/xtest"
+)
+
+func newReorderWorker(window int, mt *MockTun) *Worker {
+	addr := &snet.UDPAddr{
+		IA:   xtest.MustParseIA("1-ff00:0:300"),
+		Host: &net.UDPAddr{IP: net.IP{192, 168, 1, 1}, Port: 80},
+	}
+	w := NewWorker(addr, 1, mt)
+	w.ReorderWindow = window
+	return w
+}
+
+func TestReorder(t *testing.T) {
+	t.Run("frames of a packet out of order", func(t *testing.T) {
+		mt := &MockTun{}
+		w := newReorderWorker(4, mt)
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 1, 0, 1,
+			0, 3, 101, 102, 103, 0, 0, 0})
+		mt.AssertPacket(t, []byte{101, 102, 103})
+		// The second half of the packet arrives first.
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 3, 0, 0,
+			57, 58, 0, 0, 0, 0, 0, 0})
+		mt.AssertDone(t)
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 2, 0, 1,
+			0, 8, 51, 52, 53, 54, 55, 56})
+		mt.AssertPacket(t, []byte{51, 52, 53, 54, 55, 56, 57, 58})
+		mt.AssertDone(t)
+	})
+	t.Run("frame beyond the window", func(t *testing.T) {
+		mt := &MockTun{}
+		w := newReorderWorker(2, mt)
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 1, 0, 1,
+			0, 3, 101, 102, 103, 0, 0, 0})
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 3, 0, 1,
+			0, 3, 201, 202, 203, 0, 0, 0})
+		mt.AssertPacket(t, []byte{101, 102, 103})
+		mt.AssertDone(t)
+		// Frame 2 is considered lost.
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 4, 0, 1,
+			0, 3, 61, 62, 63, 0, 0, 0})
+		mt.AssertPacket(t, []byte{201, 202, 203})
+		mt.AssertPacket(t, []byte{61, 62, 63})
+		mt.AssertDone(t)
+		// A late frame is dropped.
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 2, 0, 1,
+			0, 3, 71, 72, 73, 0, 0, 0})
+		mt.AssertDone(t)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		mt := &MockTun{}
+		w := newReorderWorker(8, mt)
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 1, 0, 1,
+			0, 3, 101, 102, 103, 0, 0, 0})
+		SendFrame(t, w, []byte{1, 0, 1, 0, 0, 3, 0, 1,
+			0, 3, 201, 202, 203, 0, 0, 0})
+		mt.AssertPacket(t, []byte{101, 102, 103})
+		w.expireReordered(time.Now())
+		mt.AssertDone(t)
+		w.expireReordered(time.Now().Add(2 * reorderTimeout))
+		mt.AssertPacket(t, []byte{201, 202, 203})
+		mt.AssertDone(t)
+	})
+}
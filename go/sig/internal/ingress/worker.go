@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sync/atomic"
 	"time"
 
 	"github.com/scionproto/scion/go/lib/common"
@@ -27,6 +28,7 @@ import (
 	"github.com/scionproto/scion/go/lib/ringbuf"
 	"github.com/scionproto/scion/go/lib/snet"
 	"github.com/scionproto/scion/go/sig/internal/metrics"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 )
 
 const (
@@ -50,6 +52,25 @@ type Worker struct {
 	markedForCleanup bool
 	sentCtrs         metrics.CtrPair
 	tunIO            io.ReadWriteCloser
+	// ReorderWindow is the number of frames that are reordered per epoch. If it
+	// is 0, the frames are inserted into the reassembly lists as they arrive.
+	ReorderWindow int
+	reorderBufs   map[int]*reorderBuffer
+	// Keys holds the keys of the sessions. If it is set, frames that are not
+	// protected with the key of the session are dropped.
+	Keys     *sigcrypto.Store
+	sessKeys *sigcrypto.SessionKeys
+	stats    *workerStats
+}
+
+// workerStats are the counters of a worker. They are accessed atomically.
+type workerStats struct {
+	framesRecv    uint64
+	framesDropped uint64
+	pktsSent      uint64
+	bytesSent     uint64
+	// lastFrame is the time in Unix nanoseconds the last frame was received.
+	lastFrame int64
 }
 
 func NewWorker(remote *snet.UDPAddr, sessId sig_mgmt.SessionType,
@@ -68,6 +89,7 @@ func NewWorker(remote *snet.UDPAddr, sessId sig_mgmt.SessionType,
 				sessId.String()),
 		},
 		tunIO: tunIO,
+		stats: &workerStats{},
 	}
 	return worker
 }
@@ -83,16 +105,24 @@ func (w *Worker) Run() {
 	for {
 		// This might block indefinitely, thus cleanup will be deferred. However,
 		// this is not an issue, since if there is nothing to read we also don't need
-		// to do any cleanup.
-		n, _ := w.Ring.Read(frames, true)
+		// to do any cleanup. If frames are held back for reordering, the read
+		// must not block so that they can expire.
+		pending := w.reorderPending()
+		n, _ := w.Ring.Read(frames, !pending)
 		if n < 0 {
 			break
 		}
+		if n == 0 {
+			time.Sleep(reorderPollInterval)
+		}
 		for i := 0; i < n; i++ {
 			frame := frames[i].(*FrameBuf)
 			w.processFrame(frame)
 			frames[i] = nil
 		}
+		if pending {
+			w.expireReordered(time.Now())
+		}
 		if time.Since(lastCleanup) >= rlistCleanUpInterval {
 			w.cleanup()
 			lastCleanup = time.Now()
@@ -105,6 +135,13 @@ func (w *Worker) Run() {
 // packets to the wire and then adding the frame to the corresponding reassembly
 // list if needed.
 func (w *Worker) processFrame(frame *FrameBuf) {
+	atomic.AddUint64(&w.stats.framesRecv, 1)
+	atomic.StoreInt64(&w.stats.lastFrame, time.Now().UnixNano())
+	if w.Keys != nil && !w.openFrame(frame) {
+		atomic.AddUint64(&w.stats.framesDropped, 1)
+		frame.Release()
+		return
+	}
 	epoch := int(binary.BigEndian.Uint16(frame.raw[1:3]))
 	seqNr := int(frame.raw[3])<<16 | int(frame.raw[4])<<8 | int(frame.raw[5])
 	index := int(binary.BigEndian.Uint16(frame.raw[6:8]))
@@ -121,7 +158,42 @@ func (w *Worker) processFrame(frame *FrameBuf) {
 	frame.completePktsProcessed = index == 0
 	// Add to frame buf reassembly list.
 	rlist := w.getRlist(epoch)
-	rlist.Insert(frame)
+	if w.ReorderWindow <= 0 {
+		rlist.Insert(frame)
+		return
+	}
+	if w.reorderBufs == nil {
+		w.reorderBufs = make(map[int]*reorderBuffer)
+	}
+	buf, ok := w.reorderBufs[epoch]
+	if !ok {
+		buf = newReorderBuffer(w.ReorderWindow, rlist)
+		w.reorderBufs[epoch] = buf
+	}
+	buf.Insert(frame, time.Now())
+}
+
+// openFrame authenticates and decrypts the frame with the key of the session.
+// It returns false if the frame must be dropped.
+func (w *Worker) openFrame(frame *FrameBuf) bool {
+	if w.sessKeys == nil {
+		w.sessKeys = w.Keys.Get(w.Remote.IA, w.Remote.Host, w.SessId)
+		if w.sessKeys == nil {
+			metrics.FramesUnauthenticated.Inc()
+			return false
+		}
+	}
+	opened, err := w.sessKeys.Open(frame.raw[:frame.frameLen])
+	if err != nil {
+		if err == sigcrypto.ErrReplayed {
+			metrics.FramesReplayed.Inc()
+		} else {
+			metrics.FramesUnauthenticated.Inc()
+		}
+		return false
+	}
+	frame.frameLen = len(opened)
+	return true
 }
 
 func (w *Worker) getRlist(epoch int) *ReassemblyList {
@@ -134,6 +206,21 @@ func (w *Worker) getRlist(epoch int) *ReassemblyList {
 	return rlist
 }
 
+func (w *Worker) reorderPending() bool {
+	for _, buf := range w.reorderBufs {
+		if buf.Pending() {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Worker) expireReordered(now time.Time) {
+	for _, buf := range w.reorderBufs {
+		buf.Expire(now)
+	}
+}
+
 func (w *Worker) cleanup() {
 	for epoch := range w.rlists {
 		rlist := w.rlists[epoch]
@@ -142,8 +229,13 @@ func (w *Worker) cleanup() {
 			// Remove the reassembly list from the map and then release all frames
 			// back to the bufpool.
 			delete(w.rlists, epoch)
+			buf := w.reorderBufs[epoch]
+			delete(w.reorderBufs, epoch)
 			go func() {
 				defer log.HandlePanic()
+				if buf != nil {
+					buf.Release()
+				}
 				rlist.removeAll()
 			}()
 		} else {
@@ -162,5 +254,40 @@ func (w *Worker) send(packet common.RawBytes) error {
 	}
 	w.sentCtrs.Pkts.Inc()
 	w.sentCtrs.Bytes.Add(float64(bytesWritten))
+	atomic.AddUint64(&w.stats.pktsSent, 1)
+	atomic.AddUint64(&w.stats.bytesSent, uint64(bytesWritten))
 	return nil
 }
+
+// WorkerStatus is a snapshot of the state of an ingress worker.
+type WorkerStatus struct {
+	Remote    string
+	SessionID sig_mgmt.SessionType
+	// FramesRecv is the number of frames received from the remote SIG.
+	FramesRecv uint64
+	// FramesDropped is the number of received frames that were dropped
+	// because they could not be authenticated.
+	FramesDropped uint64
+	// PktsSent and BytesSent count the packets written to the local network.
+	PktsSent  uint64
+	BytesSent uint64
+	// LastFrame is the time the last frame was received.
+	LastFrame time.Time
+}
+
+// Status returns a snapshot of the state of the worker. It is safe to call
+// while the worker is running.
+func (w *Worker) Status() WorkerStatus {
+	status := WorkerStatus{
+		Remote:        w.Remote.String(),
+		SessionID:     w.SessId,
+		FramesRecv:    atomic.LoadUint64(&w.stats.framesRecv),
+		FramesDropped: atomic.LoadUint64(&w.stats.framesDropped),
+		PktsSent:      atomic.LoadUint64(&w.stats.pktsSent),
+		BytesSent:     atomic.LoadUint64(&w.stats.bytesSent),
+	}
+	if nanos := atomic.LoadInt64(&w.stats.lastFrame); nanos != 0 {
+		status.LastFrame = time.Unix(0, nanos)
+	}
+	return status
+}
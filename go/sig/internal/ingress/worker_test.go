@@ -125,3 +125,27 @@ func TestParsing(t *testing.T) {
 	mt.AssertPacket(t, []byte{201, 202, 203})
 	mt.AssertDone(t)
 }
+
+func TestWorkerStatus(t *testing.T) {
+	addr := &snet.UDPAddr{
+		IA: xtest.MustParseIA("1-ff00:0:300"),
+		Host: &net.UDPAddr{
+			IP:   net.IP{192, 168, 1, 1},
+			Port: 80,
+		},
+	}
+	w := NewWorker(addr, 1, &MockTun{})
+	status := w.Status()
+	assert.Equal(t, uint64(0), status.FramesRecv)
+	assert.True(t, status.LastFrame.IsZero())
+
+	SendFrame(t, w, []byte{1, 0, 1, 0, 0, 1, 0, 1,
+		0, 3, 101, 102, 103, 0, 0, 0,
+		0, 3, 201, 202, 203, 0, 0, 0})
+	status = w.Status()
+	assert.Equal(t, addr.String(), status.Remote)
+	assert.Equal(t, uint64(1), status.FramesRecv)
+	assert.Equal(t, uint64(0), status.FramesDropped)
+	assert.Equal(t, uint64(2), status.PktsSent)
+	assert.False(t, status.LastFrame.IsZero())
+}
@@ -47,6 +47,10 @@ var (
 	FramesDiscarded       prometheus.Counter
 	FramesTooOld          prometheus.Counter
 	FramesDuplicated      prometheus.Counter
+	FramesReordered       prometheus.Counter
+	FramesUnauthenticated prometheus.Counter
+	FramesReplayed        prometheus.Counter
+	FramesWithoutKey      prometheus.Counter
 	SessionTimedOut       *prometheus.CounterVec
 	SessionPathSwitched   *prometheus.CounterVec
 	SessionOldPollReplies *prometheus.CounterVec
@@ -55,10 +59,12 @@ var (
 	SessionProbeRTT       *prometheus.HistogramVec
 	SessionPaths          *prometheus.GaugeVec
 	SessionMTU            *prometheus.GaugeVec
+	SessionStripes        *prometheus.GaugeVec
 	SessionHealth         *prometheus.GaugeVec
 	SessionRemoteSwitched *prometheus.CounterVec
 
-	EgressRxQueueFull *prometheus.CounterVec
+	EgressRxQueueFull   *prometheus.CounterVec
+	EgressPktsRateLimit *prometheus.CounterVec
 )
 
 // Version number of loaded config, atomic
@@ -97,6 +103,13 @@ func init() {
 	FramesDiscarded = newC("frames_discarded_total", "Number of frames discarded.")
 	FramesTooOld = newC("frames_too_old_total", "Number of frames that are too old.")
 	FramesDuplicated = newC("frames_duplicated_total", "Number of duplicate frames.")
+	FramesReordered = newC("frames_reordered_total",
+		"Number of frames that arrived ahead of the next expected frame.")
+	FramesUnauthenticated = newC("frames_unauthenticated_total",
+		"Number of frames that failed authentication.")
+	FramesReplayed = newC("frames_replayed_total", "Number of replayed frames.")
+	FramesWithoutKey = newC("frames_without_key_total",
+		"Number of egress frames dropped because no frame key is established.")
 	SessionTimedOut = newCVec("session_timeout", "Number of pollreq timeouts", iaLabels)
 	SessionPathSwitched = newCVec("session_switch_path", "Number of path switches",
 		append(iaLabels, "reason"))
@@ -109,6 +122,8 @@ func init() {
 		iaLabels, prom.DefaultLatencyBuckets)
 	SessionPaths = newGVec("session_paths", "Number of available paths", iaLabels)
 	SessionMTU = newGVec("session_mtu", "MTU used by the session", iaLabels)
+	SessionStripes = newGVec("session_stripes",
+		"Number of paths the frames are striped over (0: multipath disabled)", iaLabels)
 	SessionHealth = newGVec("session_health", "Session health (1: healthy or 0: unhealthy)",
 		iaLabels)
 	SessionRemoteSwitched = newCVec("session_switch_remote",
@@ -116,6 +131,9 @@ func init() {
 
 	EgressRxQueueFull = newCVec("egress_recv_queue_full_total",
 		"Egress packets dropped due to full queues.", []string{"dst_isd_as"})
+	EgressPktsRateLimit = newCVec("egress_pkts_rate_limited_total",
+		"Egress packets dropped because the rate limit queue of the session is full.",
+		iaLabels)
 
 	// Add handler for ConfigVersion
 	http.HandleFunc("/configversion", func(w http.ResponseWriter, _ *http.Request) {
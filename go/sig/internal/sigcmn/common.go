@@ -23,10 +23,14 @@ import (
 	"github.com/scionproto/scion/go/dispatcher/dispatcher"
 	"github.com/scionproto/scion/go/lib/addr"
 	"github.com/scionproto/scion/go/lib/common"
+	"github.com/scionproto/scion/go/lib/ctrl"
 	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/drkey/client"
 	"github.com/scionproto/scion/go/lib/env"
+	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/pathmgr"
+	"github.com/scionproto/scion/go/lib/sciond"
 	"github.com/scionproto/scion/go/lib/sciond/fake"
 	"github.com/scionproto/scion/go/lib/serrors"
 	"github.com/scionproto/scion/go/lib/snet"
@@ -55,6 +59,11 @@ var (
 	DataAddr   net.IP
 	DataPort   int
 	CtrlConn   *snet.Conn
+	// Signer signs the control messages that are sent to remote SIGs.
+	Signer ctrl.Signer = infra.NullSigner
+	// Encryption is the AEAD that protects the frames. If it is empty, the
+	// frames are sent in the clear.
+	Encryption string
 )
 
 func Init(cfg sigconfig.SigConf, sdCfg env.SCIONDClient, features env.Features) error {
@@ -97,6 +106,9 @@ func initNetwork(cfg sigconfig.SigConf,
 func initNetworkWithFakeSCIOND(cfg sigconfig.SigConf,
 	sdCfg env.SCIONDClient, features env.Features) (*snet.SCIONNetwork, pathmgr.Resolver, error) {
 
+	if cfg.SCMPAuth {
+		return nil, nil, serrors.New("SCMP authentication requires the SCION Daemon")
+	}
 	sciondConn, err := fake.NewFromFile(sdCfg.FakeData)
 	if err != nil {
 		return nil, nil, serrors.WrapStr("unable to initialize fake SCIOND service", err)
@@ -121,12 +133,16 @@ func initNetworkWithRealSCIOND(cfg sigconfig.SigConf,
 	var retErr error
 	for tries := 0; time.Now().Before(deadline); tries++ {
 		resolver, err := snetmigrate.ResolverFromSD(sdCfg.Address, sdCfg.PathCount)
+		var scmpHandler snet.SCMPHandler
+		if err == nil {
+			scmpHandler, err = newSCMPHandler(cfg, sdCfg, resolver)
+		}
 		if err == nil {
 			return &snet.SCIONNetwork{
 				LocalIA: cfg.IA,
 				Dispatcher: &snet.DefaultPacketDispatcherService{
 					Dispatcher:  Dispatcher,
-					SCMPHandler: snet.NewSCMPHandler(resolver),
+					SCMPHandler: scmpHandler,
 					Version2:    features.HeaderV2,
 				},
 			}, resolver, nil
@@ -138,6 +154,28 @@ func initNetworkWithRealSCIOND(cfg sigconfig.SigConf,
 	return nil, nil, retErr
 }
 
+// newSCMPHandler creates the SCMP handler that forwards revocations to the
+// resolver. If SCMP authentication is enabled, the keys to verify SCMP errors
+// are fetched from the control service that is announced by SCIOND.
+func newSCMPHandler(cfg sigconfig.SigConf, sdCfg env.SCIONDClient,
+	resolver pathmgr.Resolver) (snet.SCMPHandler, error) {
+
+	if !cfg.SCMPAuth {
+		return snet.NewSCMPHandler(resolver), nil
+	}
+	ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+	defer cancelF()
+	sciondConn, err := sciond.NewService(sdCfg.Address).Connect(ctx)
+	if err != nil {
+		return nil, serrors.WrapStr("connecting to SCIOND", err)
+	}
+	keys, err := client.NewSCMPKeys(ctx, sciondConn)
+	if err != nil {
+		return nil, err
+	}
+	return snet.NewSCMPHandler(resolver, snet.WithSCMPAuth(keys)), nil
+}
+
 func newDispatcher(cfg sigconfig.SigConf) (reliable.Dispatcher, error) {
 	if cfg.DispatcherBypass == "" {
 		log.Info("Regular SCION dispatcher", "addr", cfg.DispatcherBypass)
@@ -0,0 +1,178 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigcrypto
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// replayWindowSize is the number of sequence numbers below the highest one
+// that are still accepted. It must be a multiple of 64.
+const replayWindowSize = 1024
+
+var (
+	// ErrExpired indicates that the key is expired.
+	ErrExpired = serrors.New("key expired")
+	// ErrReplayed indicates that the epoch and sequence number of the frame
+	// have already been used with the key, or that they are too old.
+	ErrReplayed = serrors.New("frame replayed")
+	// ErrInvalidFrame indicates that the frame could not be authenticated.
+	ErrInvalidFrame = serrors.New("frame authentication failed")
+)
+
+// Key is a frame key. It is safe for concurrent use.
+type Key struct {
+	aead   cipher.AEAD
+	expiry time.Time
+
+	mu     sync.Mutex
+	epochs map[uint16]*replayWindow
+}
+
+func newKey(aead cipher.AEAD, expiry time.Time) *Key {
+	return &Key{
+		aead:   aead,
+		expiry: expiry,
+		epochs: make(map[uint16]*replayWindow),
+	}
+}
+
+// Expired returns whether the key can no longer be used at the given time.
+func (k *Key) Expired(now time.Time) bool {
+	return now.After(k.expiry)
+}
+
+// Seal encrypts the frame in place. The frame starts with the frame header,
+// and should have a capacity of at least Overhead bytes beyond its length. The
+// protected frame is returned. Seal fails if the epoch and sequence number of
+// the frame were already used with the key.
+func (k *Key) Seal(frame []byte) ([]byte, error) {
+	if len(frame) < HdrLen {
+		return nil, serrors.New("frame too short", "len", len(frame))
+	}
+	if k.Expired(time.Now()) {
+		return nil, ErrExpired
+	}
+	epoch, seq := counter(frame)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	w := k.window(epoch)
+	if !w.check(seq) {
+		return nil, ErrReplayed
+	}
+	w.update(seq)
+	var nonce [12]byte
+	copy(nonce[:], frame[:6])
+	return k.aead.Seal(frame[:HdrLen], nonce[:], frame[HdrLen:], frame[:HdrLen]), nil
+}
+
+// Open authenticates and decrypts the frame in place, and returns the frame
+// with the decrypted payload. Frames with an epoch and sequence number that
+// were already accepted by the key are rejected.
+func (k *Key) Open(frame []byte) ([]byte, error) {
+	if len(frame) < HdrLen+Overhead {
+		return nil, ErrInvalidFrame
+	}
+	if k.Expired(time.Now()) {
+		return nil, ErrExpired
+	}
+	epoch, seq := counter(frame)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	w, ok := k.epochs[epoch]
+	if ok && !w.check(seq) {
+		return nil, ErrReplayed
+	}
+	var nonce [12]byte
+	copy(nonce[:], frame[:6])
+	payload, err := k.aead.Open(frame[HdrLen:HdrLen], nonce[:], frame[HdrLen:],
+		frame[:HdrLen])
+	if err != nil {
+		return nil, ErrInvalidFrame
+	}
+	// Only frames that are authentic can advance the window.
+	k.window(epoch).update(seq)
+	return frame[:HdrLen+len(payload)], nil
+}
+
+func (k *Key) window(epoch uint16) *replayWindow {
+	w, ok := k.epochs[epoch]
+	if !ok {
+		w = &replayWindow{}
+		k.epochs[epoch] = w
+	}
+	return w
+}
+
+// counter returns the epoch and the sequence number of the frame.
+func counter(frame []byte) (uint16, uint32) {
+	epoch := binary.BigEndian.Uint16(frame[1:3])
+	seq := uint32(frame[3])<<16 | uint32(frame[4])<<8 | uint32(frame[5])
+	return epoch, seq
+}
+
+// replayWindow keeps track of the sequence numbers of an epoch that were
+// already used.
+type replayWindow struct {
+	used    bool
+	highest uint32
+	bitmap  [replayWindowSize / 64]uint64
+}
+
+// check returns true if seq was not used yet and is not too old.
+func (w *replayWindow) check(seq uint32) bool {
+	if !w.used || seq > w.highest {
+		return true
+	}
+	if w.highest-seq >= replayWindowSize {
+		return false
+	}
+	return !w.isSet(seq)
+}
+
+// update marks seq as used.
+func (w *replayWindow) update(seq uint32) {
+	switch {
+	case !w.used || (seq > w.highest && seq-w.highest >= replayWindowSize):
+		w.bitmap = [replayWindowSize / 64]uint64{}
+		w.used, w.highest = true, seq
+	case seq > w.highest:
+		for s := w.highest + 1; s <= seq; s++ {
+			w.clear(s)
+		}
+		w.highest = seq
+	}
+	w.set(seq)
+}
+
+func (w *replayWindow) isSet(seq uint32) bool {
+	i := seq % replayWindowSize
+	return w.bitmap[i/64]&(1<<(i%64)) != 0
+}
+
+func (w *replayWindow) set(seq uint32) {
+	i := seq % replayWindowSize
+	w.bitmap[i/64] |= 1 << (i % 64)
+}
+
+func (w *replayWindow) clear(seq uint32) {
+	i := seq % replayWindowSize
+	w.bitmap[i/64] &^= 1 << (i % 64)
+}
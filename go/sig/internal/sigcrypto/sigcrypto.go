@@ -0,0 +1,160 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sigcrypto protects SIG frames with an AEAD.
+//
+// The egress SIG of a session (the initiator) and the ingress SIG (the
+// responder) establish a frame key with an ephemeral X25519 key exchange. The
+// public keys are carried in the poll request and reply of the session, which
+// are signed with the AS certificates of both SIGs. The frame key is derived
+// from the shared secret with HKDF-SHA256 and is bound to the ISD-ASes, the
+// session and the public keys of both SIGs.
+//
+// The frame header is authenticated, the rest of the frame is encrypted and
+// authenticated. The nonce is built from the session ID, the epoch and the
+// sequence number in the frame header. A key never protects the same epoch and
+// sequence number twice. On the ingress side, the same check rejects replayed
+// frames.
+package sigcrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+const (
+	// AESGCM protects the frames with AES-256-GCM.
+	AESGCM = "aes-gcm"
+	// ChaCha20Poly1305 protects the frames with ChaCha20-Poly1305.
+	ChaCha20Poly1305 = "chacha20-poly1305"
+
+	// HdrLen is the length of the frame header that is authenticated but not
+	// encrypted.
+	HdrLen = 8
+	// Overhead is the number of bytes that the protection adds to a frame.
+	Overhead = 16
+	// PubKeyLen is the length of the public keys that are exchanged.
+	PubKeyLen = 32
+
+	// RekeyInterval is the interval after which the initiator starts a new
+	// key exchange.
+	RekeyInterval = 10 * time.Minute
+	// KeyLifetime is the time a frame key can be used after it has been
+	// established. It is well below the time after which the epoch of the
+	// frames repeats.
+	KeyLifetime = time.Hour
+
+	keyLen   = 32
+	keyLabel = "SIG frame key"
+)
+
+// Session identifies the session a frame key is established for.
+type Session struct {
+	// Src is the ISD-AS of the egress SIG.
+	Src addr.IA
+	// Dst is the ISD-AS of the ingress SIG.
+	Dst addr.IA
+	// ID is the session ID.
+	ID sig_mgmt.SessionType
+}
+
+// KeyPair is an ephemeral X25519 key pair.
+type KeyPair struct {
+	// Public is the public key that is sent to the peer.
+	Public  []byte
+	private []byte
+}
+
+// NewKeyPair generates a new ephemeral key pair.
+func NewKeyPair() (*KeyPair, error) {
+	private := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, private); err != nil {
+		return nil, serrors.WrapStr("generating private key", err)
+	}
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, serrors.WrapStr("computing public key", err)
+	}
+	return &KeyPair{Public: public, private: private}, nil
+}
+
+// DeriveKey derives the frame key of the session from the local key pair and
+// the public key of the peer. Initiator indicates whether the local SIG is the
+// egress SIG of the session.
+func (kp *KeyPair) DeriveKey(alg string, peerPub []byte, sess Session,
+	initiator bool) (*Key, error) {
+
+	if len(peerPub) != PubKeyLen {
+		return nil, serrors.New("invalid public key length", "expected", PubKeyLen,
+			"actual", len(peerPub))
+	}
+	secret, err := curve25519.X25519(kp.private, peerPub)
+	if err != nil {
+		return nil, serrors.WrapStr("computing shared secret", err)
+	}
+	initPub, respPub := kp.Public, peerPub
+	if !initiator {
+		initPub, respPub = peerPub, kp.Public
+	}
+	info := make([]byte, 0, len(keyLabel)+17+2*PubKeyLen)
+	info = append(info, keyLabel...)
+	info = appendUint64(info, uint64(sess.Src.IAInt()))
+	info = appendUint64(info, uint64(sess.Dst.IAInt()))
+	info = append(info, byte(sess.ID))
+	info = append(info, initPub...)
+	info = append(info, respPub...)
+	raw := make([]byte, keyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), raw); err != nil {
+		return nil, serrors.WrapStr("deriving key", err)
+	}
+	aead, err := newAEAD(alg, raw)
+	if err != nil {
+		return nil, err
+	}
+	return newKey(aead, time.Now().Add(KeyLifetime)), nil
+}
+
+func newAEAD(alg string, key []byte) (cipher.AEAD, error) {
+	switch alg {
+	case AESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, serrors.New("unsupported algorithm", "alg", alg)
+	}
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var raw [8]byte
+	binary.BigEndian.PutUint64(raw[:], v)
+	return append(b, raw[:]...)
+}
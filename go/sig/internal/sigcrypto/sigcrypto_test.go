@@ -0,0 +1,154 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigcrypto_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/go/lib/xtest"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
+)
+
+var (
+	srcIA = xtest.MustParseIA("1-ff00:0:110")
+	dstIA = xtest.MustParseIA("1-ff00:0:111")
+	host  = &net.UDPAddr{IP: net.IP{192, 0, 2, 1}, Port: 30056}
+)
+
+// exchange runs a key exchange for session 1 and returns the egress key.
+func exchange(t *testing.T, store *sigcrypto.Store) *sigcrypto.Key {
+	kp, err := sigcrypto.NewKeyPair()
+	require.NoError(t, err)
+	respPub, err := store.Exchange(srcIA, host, 1, kp.Public)
+	require.NoError(t, err)
+	key, err := kp.DeriveKey(store.Algorithm, respPub,
+		sigcrypto.Session{Src: srcIA, Dst: dstIA, ID: 1}, true)
+	require.NoError(t, err)
+	return key
+}
+
+// frame returns a frame of session 1 with the given sequence number and
+// room for the overhead.
+func frame(seq byte, payload ...byte) []byte {
+	f := make([]byte, 0, sigcrypto.HdrLen+len(payload)+sigcrypto.Overhead)
+	f = append(f, 1, 0, 7, 0, 0, seq, 0, 1)
+	return append(f, payload...)
+}
+
+func TestSealOpen(t *testing.T) {
+	for _, alg := range []string{sigcrypto.AESGCM, sigcrypto.ChaCha20Poly1305} {
+		t.Run(alg, func(t *testing.T) {
+			store := &sigcrypto.Store{Algorithm: alg, IA: dstIA}
+			egress := exchange(t, store)
+			ingress := store.Get(srcIA, host, 1)
+			require.NotNil(t, ingress)
+
+			sealed, err := egress.Seal(frame(1, 0, 3, 101, 102, 103))
+			require.NoError(t, err)
+			assert.Len(t, sealed, sigcrypto.HdrLen+5+sigcrypto.Overhead)
+			opened, err := ingress.Open(append([]byte(nil), sealed...))
+			require.NoError(t, err)
+			assert.Equal(t, frame(1, 0, 3, 101, 102, 103), opened)
+
+			// Replayed frames are rejected.
+			_, err = ingress.Open(append([]byte(nil), sealed...))
+			assert.Equal(t, sigcrypto.ErrReplayed, err)
+			// A key never seals the same epoch and sequence number twice.
+			_, err = egress.Seal(frame(1, 0, 3, 101, 102, 103))
+			assert.Equal(t, sigcrypto.ErrReplayed, err)
+
+			// Modified headers are detected.
+			sealed, err = egress.Seal(frame(2, 0, 3, 101, 102, 103))
+			require.NoError(t, err)
+			sealed[7] = 2
+			_, err = ingress.Open(sealed)
+			assert.Equal(t, sigcrypto.ErrInvalidFrame, err)
+		})
+	}
+}
+
+func TestOpenReordered(t *testing.T) {
+	store := &sigcrypto.Store{Algorithm: sigcrypto.AESGCM, IA: dstIA}
+	egress := exchange(t, store)
+	ingress := store.Get(srcIA, host, 1)
+	var sealed [][]byte
+	for seq := byte(1); seq <= 4; seq++ {
+		f, err := egress.Seal(frame(seq, 0, 1, 42))
+		require.NoError(t, err)
+		sealed = append(sealed, f)
+	}
+	for _, i := range []int{1, 3, 0, 2} {
+		_, err := ingress.Open(sealed[i])
+		assert.NoError(t, err, "frame %d", i+1)
+	}
+}
+
+func TestStoreKeyChange(t *testing.T) {
+	store := &sigcrypto.Store{Algorithm: sigcrypto.AESGCM, IA: dstIA}
+	kp, err := sigcrypto.NewKeyPair()
+	require.NoError(t, err)
+	first, err := store.Exchange(srcIA, host, 1, kp.Public)
+	require.NoError(t, err)
+	again, err := store.Exchange(srcIA, host, 1, kp.Public)
+	require.NoError(t, err)
+	assert.Equal(t, first, again, "same initiator key must not change the key")
+
+	old, err := kp.DeriveKey(sigcrypto.AESGCM, first,
+		sigcrypto.Session{Src: srcIA, Dst: dstIA, ID: 1}, true)
+	require.NoError(t, err)
+	inFlight, err := old.Seal(frame(1, 0, 1, 42))
+	require.NoError(t, err)
+
+	curr := exchange(t, store)
+	ingress := store.Get(srcIA, host, 1)
+	sealed, err := curr.Seal(frame(2, 0, 1, 43))
+	require.NoError(t, err)
+	opened, err := ingress.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, frame(2, 0, 1, 43), opened)
+	// Frames protected with the previous key are still accepted.
+	opened, err = ingress.Open(inFlight)
+	require.NoError(t, err)
+	assert.Equal(t, frame(1, 0, 1, 42), opened)
+
+	assert.Nil(t, store.Get(srcIA, host, 2))
+}
+
+func TestStoreReplayedExchange(t *testing.T) {
+	store := &sigcrypto.Store{Algorithm: sigcrypto.AESGCM, IA: dstIA}
+	kp, err := sigcrypto.NewKeyPair()
+	require.NoError(t, err)
+	_, err = store.Exchange(srcIA, host, 1, kp.Public)
+	require.NoError(t, err)
+	curr := exchange(t, store)
+
+	// A replayed poll request with the previous initiator key must not roll
+	// back the key.
+	_, err = store.Exchange(srcIA, host, 1, kp.Public)
+	assert.Error(t, err)
+	sealed, err := curr.Seal(frame(1, 0, 1, 42))
+	require.NoError(t, err)
+	opened, err := store.Get(srcIA, host, 1).Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, frame(1, 0, 1, 42), opened)
+
+	// The initiator key of another session is not affected.
+	_, err = store.Exchange(srcIA, host, 2, kp.Public)
+	assert.NoError(t, err)
+}
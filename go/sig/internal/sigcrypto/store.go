@@ -0,0 +1,154 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigcrypto
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/ctrl/sig_mgmt"
+	"github.com/scionproto/scion/go/lib/serrors"
+)
+
+// keyChangeGrace is the time the previous key of a session is accepted after
+// the key changed.
+const keyChangeGrace = 10 * time.Second
+
+// Store holds the frame keys of the sessions that remote SIGs established with
+// the local SIG. It is safe for concurrent use.
+type Store struct {
+	// Algorithm is the AEAD that protects the frames.
+	Algorithm string
+	// IA is the ISD-AS of the local SIG.
+	IA addr.IA
+
+	mu       sync.RWMutex
+	sessions map[string]*SessionKeys
+}
+
+// Exchange completes the key exchange that the remote SIG, which sends the
+// frames of the session from host, initiated with initPub. It returns the
+// public key of the local SIG that must be sent back in the poll reply. If
+// initPub is the same as in the previous exchange, the established key is kept.
+// An initPub that was used in an earlier exchange of the session is rejected,
+// such that replayed poll requests cannot roll back the key.
+func (s *Store) Exchange(remote addr.IA, host *net.UDPAddr, id sig_mgmt.SessionType,
+	initPub []byte) ([]byte, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*SessionKeys)
+	}
+	key := storeKey(remote, host, id)
+	keys, ok := s.sessions[key]
+	if !ok {
+		keys = &SessionKeys{}
+		s.sessions[key] = keys
+	}
+	return keys.exchange(s.Algorithm, Session{Src: remote, Dst: s.IA, ID: id}, initPub)
+}
+
+// Get returns the keys of the session that the remote SIG sends from host, or
+// nil if no key exchange was completed for the session.
+func (s *Store) Get(remote addr.IA, host *net.UDPAddr, id sig_mgmt.SessionType) *SessionKeys {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sessions[storeKey(remote, host, id)]
+}
+
+func storeKey(remote addr.IA, host *net.UDPAddr, id sig_mgmt.SessionType) string {
+	return fmt.Sprintf("%s/%s/%s", remote, host, id)
+}
+
+// SessionKeys are the keys of a session that was established by a remote SIG.
+// The previous key is accepted for a short time after the key changed, such
+// that frames that are in flight are not dropped. It is safe for concurrent
+// use.
+type SessionKeys struct {
+	mu        sync.RWMutex
+	initPub   []byte
+	respPub   []byte
+	curr      *Key
+	prev      *Key
+	prevUntil time.Time
+	// seen holds the initiator public keys of the previous exchanges, and
+	// the time they were replaced. They are kept for KeyLifetime.
+	seen map[string]time.Time
+}
+
+func (k *SessionKeys) exchange(alg string, sess Session, initPub []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.curr != nil && bytes.Equal(k.initPub, initPub) {
+		return k.respPub, nil
+	}
+	now := time.Now()
+	for pub, replaced := range k.seen {
+		if now.Sub(replaced) > KeyLifetime {
+			delete(k.seen, pub)
+		}
+	}
+	if _, ok := k.seen[string(initPub)]; ok {
+		return nil, serrors.New("initiator public key already used")
+	}
+	kp, err := NewKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	key, err := kp.DeriveKey(alg, initPub, sess, false)
+	if err != nil {
+		return nil, err
+	}
+	if k.initPub != nil {
+		if k.seen == nil {
+			k.seen = make(map[string]time.Time)
+		}
+		k.seen[string(k.initPub)] = now
+	}
+	k.initPub = append([]byte(nil), initPub...)
+	k.respPub = kp.Public
+	k.curr, k.prev = key, k.curr
+	k.prevUntil = now.Add(keyChangeGrace)
+	return k.respPub, nil
+}
+
+// Open authenticates and decrypts the frame in place with the current key of
+// the session, or with the previous key shortly after the key changed.
+func (k *SessionKeys) Open(frame []byte) ([]byte, error) {
+	k.mu.RLock()
+	curr, prev, prevUntil := k.curr, k.prev, k.prevUntil
+	k.mu.RUnlock()
+	if curr == nil {
+		return nil, ErrInvalidFrame
+	}
+	if prev == nil || time.Now().After(prevUntil) {
+		return curr.Open(frame)
+	}
+	// A failed attempt to open the frame overwrites it. Thus, the current key
+	// is tried on a copy while the previous key is still accepted.
+	opened, err := curr.Open(append([]byte(nil), frame...))
+	if err == nil {
+		return frame[:copy(frame, opened)], nil
+	}
+	if err == ErrReplayed {
+		return nil, err
+	}
+	return prev.Open(frame)
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sigcrypto
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/addr"
+	"github.com/scionproto/scion/go/lib/infra"
+	"github.com/scionproto/scion/go/lib/log"
+	"github.com/scionproto/scion/go/lib/serrors"
+	cstrust "github.com/scionproto/scion/go/pkg/cs/trust"
+	"github.com/scionproto/scion/go/pkg/trust"
+	"github.com/scionproto/scion/go/pkg/trust/compat"
+)
+
+// LoadTrustMaterial loads the TRCs and the certificate chains of the remote
+// ASes from the certs directory in cfgDir into the database.
+func LoadTrustMaterial(cfgDir string, db trust.DB) error {
+	certsDir := filepath.Join(cfgDir, "certs")
+	loaded, err := trust.LoadTRCs(context.Background(), certsDir, db)
+	if err != nil {
+		return serrors.WrapStr("loading TRCs", err)
+	}
+	log.Info("TRCs loaded", "files", loaded.Loaded)
+	for f, r := range loaded.Ignored {
+		log.Info("Ignoring non-TRC", "file", f, "reason", r)
+	}
+	loaded, err = trust.LoadChains(context.Background(), certsDir, db)
+	if err != nil {
+		return serrors.WrapStr("loading certificate chains", err)
+	}
+	log.Info("Certificate chains loaded", "files", loaded.Loaded)
+	for f, r := range loaded.Ignored {
+		log.Info("Ignoring non-certificate chain", "file", f, "reason", r)
+	}
+	return nil
+}
+
+// NewSigner creates a signer for the control messages of the SIG that uses the
+// AS key and certificate chain in the crypto/as directory in cfgDir.
+func NewSigner(ia addr.IA, db trust.DB, cfgDir string) (cstrust.RenewingSigner, error) {
+	ctx, cancelF := context.WithTimeout(context.Background(), time.Second)
+	defer cancelF()
+	gen := &cstrust.CachingSignerGen{
+		SignerGen: trust.SignerGen{
+			IA: ia,
+			DB: cstrust.CryptoLoader{
+				Dir: filepath.Join(cfgDir, "crypto/as"),
+				DB:  db,
+			},
+			KeyRing: cstrust.LoadingRing{
+				Dir: filepath.Join(cfgDir, "crypto/as"),
+			},
+		},
+		Interval: 5 * time.Second,
+	}
+	if _, err := gen.Generate(ctx); err != nil {
+		return cstrust.RenewingSigner{}, err
+	}
+	return cstrust.RenewingSigner{SignerGen: gen}, nil
+}
+
+// NewVerifier creates a verifier for the control messages of remote SIGs. The
+// verifier only uses the crypto material in the database, it never fetches
+// missing crypto material over the network.
+func NewVerifier(db trust.DB) infra.Verifier {
+	return compat.Verifier{
+		Verifier: trust.Verifier{
+			Engine: trust.FetchingProvider{
+				DB:       db,
+				Recurser: noRecurser{},
+			},
+		},
+	}
+}
+
+// noRecurser does not allow any recursive requests.
+type noRecurser struct{}
+
+func (noRecurser) AllowRecursion(net.Addr) error {
+	return trust.ErrRecursionNotAllowed
+}
@@ -19,6 +19,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -31,6 +32,7 @@ import (
 	libconfig "github.com/scionproto/scion/go/lib/config"
 	"github.com/scionproto/scion/go/lib/env"
 	"github.com/scionproto/scion/go/lib/fatal"
+	"github.com/scionproto/scion/go/lib/infra"
 	"github.com/scionproto/scion/go/lib/log"
 	"github.com/scionproto/scion/go/lib/prom"
 	"github.com/scionproto/scion/go/lib/serrors"
@@ -38,11 +40,13 @@ import (
 	"github.com/scionproto/scion/go/lib/sigjson"
 	"github.com/scionproto/scion/go/pkg/service"
 	sigconfig "github.com/scionproto/scion/go/pkg/sig/config"
+	"github.com/scionproto/scion/go/pkg/storage"
 	"github.com/scionproto/scion/go/sig/egress"
 	"github.com/scionproto/scion/go/sig/internal/base"
 	"github.com/scionproto/scion/go/sig/internal/ingress"
 	"github.com/scionproto/scion/go/sig/internal/metrics"
 	"github.com/scionproto/scion/go/sig/internal/sigcmn"
+	"github.com/scionproto/scion/go/sig/internal/sigcrypto"
 	"github.com/scionproto/scion/go/sig/internal/xnet"
 )
 
@@ -93,7 +97,12 @@ func realMain() int {
 			log.Info("reloadOnSIGHUP: reload done", "success", success)
 		},
 	)
-	sigdisp.Init(sigcmn.CtrlConn, false)
+	verifier, keys, err := setupEncryption()
+	if err != nil {
+		log.Error("Frame encryption initialization failed", "err", err)
+		return 1
+	}
+	sigdisp.Init(sigcmn.CtrlConn, false, verifier)
 	// Parse sig config
 	if loadConfig(cfg.Sig.SIGConfig) != true {
 		log.Error("SIG configuration loading failed")
@@ -102,15 +111,18 @@ func realMain() int {
 	// Reply to probes from other SIGs.
 	go func() {
 		defer log.HandlePanic()
-		base.PollReqHdlr()
+		base.PollReqHdlr(keys)
 	}()
 	egress.Init(tunIO)
-	ingress.Init(tunIO)
+	ingress.Init(tunIO, cfg.Sig.ReorderWindow, keys)
 
 	// Start HTTP endpoints.
 	statusPages := service.StatusPages{
-		"info":   service.NewInfoHandler(),
-		"config": service.NewConfigHandler(cfg),
+		"info":    service.NewInfoHandler(),
+		"config":  service.NewConfigHandler(cfg),
+		"status":  statusHandler,
+		"egress":  egressHandler,
+		"ingress": ingressHandler,
 	}
 	if err := statusPages.Register(http.DefaultServeMux, cfg.Sig.ID); err != nil {
 		log.Error("registering status pages", "err", err)
@@ -150,6 +162,33 @@ func validateConfig() error {
 	return nil
 }
 
+// setupEncryption initializes the protection of the frames if it is enabled. It
+// returns the verifier for the control messages of the remote SIGs, and the
+// store for the keys of the ingress sessions. Both are nil if encryption is
+// disabled.
+func setupEncryption() (infra.Verifier, *sigcrypto.Store, error) {
+	if cfg.Sig.Encryption == "" {
+		log.Info("Frame encryption disabled")
+		return nil, nil, nil
+	}
+	db, err := storage.NewTrustStorage(cfg.TrustDB)
+	if err != nil {
+		return nil, nil, serrors.WrapStr("initializing trust database", err)
+	}
+	if err := sigcrypto.LoadTrustMaterial(cfg.Sig.ConfigDir, db); err != nil {
+		return nil, nil, err
+	}
+	signer, err := sigcrypto.NewSigner(cfg.Sig.IA, db, cfg.Sig.ConfigDir)
+	if err != nil {
+		return nil, nil, serrors.WrapStr("initializing signer", err)
+	}
+	sigcmn.Signer = signer
+	sigcmn.Encryption = cfg.Sig.Encryption
+	log.Info("Frame encryption enabled", "encryption", cfg.Sig.Encryption)
+	keys := &sigcrypto.Store{Algorithm: cfg.Sig.Encryption, IA: cfg.Sig.IA}
+	return sigcrypto.NewVerifier(db), keys, nil
+}
+
 func setupTun() (io.ReadWriteCloser, error) {
 	if err := checkPerms(); err != nil {
 		return nil, serrors.WrapStr("Permissions checks failed", err)
@@ -215,6 +254,11 @@ func loadConfig(path string) bool {
 	if !ok {
 		return false
 	}
+	announce := make([]*net.IPNet, 0, len(cfg.Announce))
+	for _, ipnet := range cfg.Announce {
+		announce = append(announce, ipnet.IPNet())
+	}
+	base.Announce(announce)
 	atomic.StoreUint64(&metrics.ConfigVersion, cfg.ConfigVersion)
 	return true
 }
@@ -0,0 +1,105 @@
+// Copyright 2020 ETH Zurich, Anapaya Systems
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/scionproto/scion/go/lib/sigjson"
+	"github.com/scionproto/scion/go/sig/egress"
+	"github.com/scionproto/scion/go/sig/internal/ingress"
+)
+
+// egressHandler serves the state of the remote ASes as JSON.
+func egressHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, egress.Status())
+}
+
+// ingressHandler serves the state of the ingress workers as JSON.
+func ingressHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, ingress.Status())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, "unable to marshal status", http.StatusInternalServerError)
+	}
+}
+
+// statusHandler serves an overview of the state of the egress sessions and the
+// ingress workers.
+func statusHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	now := time.Now()
+	var out strings.Builder
+	out.WriteString("Egress:\n")
+	for _, as := range egress.Status() {
+		fmt.Fprintf(&out, "  %s healthy: %v nets: [%s]\n", as.IA, as.Healthy,
+			strings.Join(as.Nets, " "))
+		if as.RateLimit != nil {
+			fmt.Fprintf(&out, "    rate limit: %s\n", rateLimit(as.RateLimit))
+		}
+		for _, sess := range as.Sessions {
+			class := sess.Class
+			if class == "" {
+				class = "default"
+			}
+			fmt.Fprintf(&out, "    session %s (%s) priority: %d healthy: %v last reply: %s\n",
+				sess.ID, class, sess.Priority, sess.Healthy, since(now, sess.LastReply))
+			if sess.RateLimit != nil {
+				fmt.Fprintf(&out, "      rate limit: %s\n", rateLimit(sess.RateLimit))
+			}
+			if sess.Remote == nil {
+				out.WriteString("      remote: none\n")
+				continue
+			}
+			fmt.Fprintf(&out, "      remote: %s encrypted: %v\n", sess.Remote.Sig,
+				sess.Remote.Encrypted)
+			if sess.Remote.Path != nil {
+				fmt.Fprintf(&out, "      path: %s %s\n", sess.Remote.Path.Fingerprint,
+					sess.Remote.Path.Path)
+			}
+			for _, stripe := range sess.Remote.Stripes {
+				fmt.Fprintf(&out, "      stripe: %s weight: %d\n", stripe.Path.Fingerprint,
+					stripe.Weight)
+			}
+		}
+	}
+	out.WriteString("Ingress:\n")
+	for _, worker := range ingress.Status() {
+		fmt.Fprintf(&out, "  %s session %s frames: %d dropped: %d packets: %d "+
+			"last frame: %s\n", worker.Remote, worker.SessionID, worker.FramesRecv,
+			worker.FramesDropped, worker.PktsSent, since(now, worker.LastFrame))
+	}
+	fmt.Fprint(w, out.String())
+}
+
+func since(now, t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s ago", now.Sub(t).Round(time.Millisecond))
+}
+
+func rateLimit(l *sigjson.RateLimit) string {
+	return fmt.Sprintf("%s burst: %dB", l.Rate, l.BurstBytes())
+}
@@ -29,6 +29,7 @@ SCION_SERVICE_NAMES = (
     "control_service",
     "border_routers",
     "colibri_service",
+    "hidden_path_service",
 )
 
 BR_CONFIG_NAME = 'br.toml'
@@ -36,6 +37,7 @@ BS_CONFIG_NAME = 'bs.toml'
 CS_CONFIG_NAME = 'cs.toml'
 PS_CONFIG_NAME = 'ps.toml'
 CO_CONFIG_NAME = 'co.toml'
+HPS_CONFIG_NAME = 'hps.toml'
 SD_CONFIG_NAME = 'sd.toml'
 DISP_CONFIG_NAME = 'disp.toml'
 SIG_CONFIG_NAME = 'sig.toml'
@@ -86,6 +86,7 @@ class DockerGenerator(object):
         self._dispatcher_conf(topo_id, topo, base)
         self._br_conf(topo_id, topo, base)
         self._control_service_conf(topo_id, topo, base)
+        self._hidden_path_service_conf(topo_id, topo, base)
         self._sciond_conf(topo_id, base)
 
     def _gen_sig(self):
@@ -195,6 +196,30 @@ class DockerGenerator(object):
             }
             self.dc_conf['services']['scion_%s' % k] = entry
 
+    def _hidden_path_service_conf(self, topo_id, topo, base):
+        for k, v in topo.get("hidden_path_service", {}).items():
+            entry = {
+                'image': docker_image(self.args, 'hidden_path_srv'),
+                'container_name': self.prefix + k,
+                'depends_on': [
+                    'scion_disp_%s' % k,
+                    sciond_svc_name(topo_id),
+                ],
+                'environment': {
+                    'SU_EXEC_USERSPEC': self.user_spec,
+                },
+                'network_mode': 'service:scion_disp_%s' % k,
+                'volumes': [
+                    *DOCKER_USR_VOL,
+                    self._cache_vol(),
+                    self._certs_vol(),
+                    '%s:/share/conf:ro' % os.path.join(base, k),
+                    self._disp_vol(k),
+                ],
+                'command': []
+            }
+            self.dc_conf['services']['scion_%s' % k] = entry
+
     def _dispatcher_conf(self, topo_id, topo, base):
         image = 'dispatcher'
         base_entry = {
@@ -208,7 +233,8 @@ class DockerGenerator(object):
                 *DOCKER_USR_VOL,
             ]
         }
-        keys = list(topo.get("border_routers", {})) + list(topo.get("control_service", {}))
+        keys = (list(topo.get("border_routers", {})) + list(topo.get("control_service", {})) +
+                list(topo.get("hidden_path_service", {})))
         for disp_id in keys:
             entry = copy.deepcopy(base_entry)
             net_key = disp_id
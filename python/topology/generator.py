@@ -52,6 +52,8 @@ def add_arguments(parser):
                         to be built manually e.g. when running acceptance tests)')
     parser.add_argument('-qos', '--colibri', action='store_true',
                         help='Generate COLIBRI service')
+    parser.add_argument('--hidden-paths', action='store_true',
+                        help='Generate a hidden path service per AS and a hidden path group')
     return parser
 
 
@@ -17,6 +17,7 @@
 =============================================
 """
 # Stdlib
+import json
 import os
 import toml
 import yaml
@@ -41,6 +42,7 @@ from python.topology.common import (
     SD_API_PORT,
     SD_CONFIG_NAME,
     CO_CONFIG_NAME,
+    HPS_CONFIG_NAME,
 )
 
 from python.topology.net import socket_address_str, NetworkDescription
@@ -51,11 +53,13 @@ from python.topology.prometheus import (
     SCIOND_PROM_PORT,
     DISP_PROM_PORT,
     CO_PROM_PORT,
+    HPS_PROM_PORT,
 )
 from python.topology.topo import DEFAULT_LINK_BW
 
 CS_QUIC_PORT = 30352
 CO_QUIC_PORT = 30357
+HPS_QUIC_PORT = 30354
 
 
 class GoGenArgs(ArgsTopoDicts):
@@ -227,6 +231,66 @@ class GoGenerator(object):
             }
         }
 
+    def generate_hps(self):
+        if not self.args.hidden_paths:
+            return
+        group = self._build_hp_group()
+        group_file = 'HPGCfg_%s.json' % group['GroupID'].replace(':', '_')
+        for topo_id, topo in self.args.topo_dicts.items():
+            for elem_id, elem in topo.get("hidden_path_service", {}).items():
+                base = topo_id.base_dir(self.args.output_dir)
+                hps_conf = self._build_hps_conf(topo_id, base, elem_id, elem, group_file)
+                write_file(os.path.join(base, elem_id, HPS_CONFIG_NAME), toml.dumps(hps_conf))
+                write_file(os.path.join(base, elem_id, group_file),
+                           json.dumps(group, indent=4, sort_keys=True))
+
+    def _build_hp_group(self):
+        """
+        Creates a single hidden path group that is owned by the first non-core
+        AS. All non-core ASes are writers, all ASes are readers, and the owner
+        is the registry.
+        """
+        ias = sorted(self.args.topo_dicts, key=str)
+        non_core = [ia for ia in ias
+                    if 'core' not in self.args.topo_dicts[ia].get('attributes', [])]
+        owner = non_core[0] if non_core else ias[0]
+        return {
+            'GroupID': '%s-1' % owner.as_str(),
+            'Version': 1,
+            'Owner': str(owner),
+            'Writers': [str(ia) for ia in (non_core or [owner])],
+            'Readers': [str(ia) for ia in ias],
+            'Registries': [str(owner)],
+        }
+
+    def _build_hps_conf(self, topo_id, base, name, infra_elem, group_file):
+        config_dir = '/share/conf' if self.args.docker else os.path.join(base, name)
+        sd_ip = sciond_ip(self.args.docker, topo_id, self.args.networks)
+        raw_entry = {
+            'general': {
+                'id': name,
+                'config_dir': config_dir,
+                'reconnect_to_dispatcher': True,
+            },
+            'log': self._log_entry(name),
+            'trust_db': {
+                'connection': os.path.join(self.db_dir, '%s.trust.db' % name),
+            },
+            'path_db': {
+                'connection': os.path.join(self.db_dir, '%s.path.db' % name),
+            },
+            'sciond_connection': {
+                'address': socket_address_str(sd_ip, SD_API_PORT),
+            },
+            'hidden_path': {
+                'group_config_files': [os.path.join(config_dir, group_file)],
+            },
+            'tracing': self._tracing_entry(),
+            'metrics': self._metrics_entry(infra_elem, HPS_PROM_PORT),
+            'quic': self._quic_conf_entry(HPS_QUIC_PORT, self.args.svcfrac, infra_elem),
+        }
+        return raw_entry
+
     def generate_sciond(self):
         for topo_id, topo in self.args.topo_dicts.items():
             base = topo_id.base_dir(self.args.output_dir)
@@ -274,7 +338,9 @@ class GoGenerator(object):
             elem_dir = os.path.join(topo_id.base_dir(self.args.output_dir), elem)
             disp_conf = self._build_disp_conf(elem, topo_id)
             write_file(os.path.join(elem_dir, DISP_CONFIG_NAME), toml.dumps(disp_conf))
-            for k in list(topo.get("border_routers", {})) + list(topo.get("control_service", {})):
+            keys = (list(topo.get("border_routers", {})) + list(topo.get("control_service", {})) +
+                    list(topo.get("hidden_path_service", {})))
+            for k in keys:
                 disp_id = 'disp_%s' % k
                 elem_dir = os.path.join(topo_id.base_dir(self.args.output_dir), disp_id)
                 disp_conf = self._build_disp_conf(disp_id, topo_id)
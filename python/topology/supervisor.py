@@ -29,6 +29,7 @@ from python.topology.common import (
     COMMON_DIR,
     CS_CONFIG_NAME,
     DISP_CONFIG_NAME,
+    HPS_CONFIG_NAME,
     SD_CONFIG_NAME,
 )
 
@@ -58,6 +59,7 @@ class SupervisorGenerator(object):
         entries = []
         entries.extend(self._br_entries(topo, "bin/border", base))
         entries.extend(self._control_service_entries(topo, base))
+        entries.extend(self._hidden_path_service_entries(topo, base))
         return entries
 
     def _br_entries(self, topo, cmd, base):
@@ -76,6 +78,13 @@ class SupervisorGenerator(object):
                 entries.append((k, ["bin/cs", "--config", conf]))
         return entries
 
+    def _hidden_path_service_entries(self, topo, base):
+        entries = []
+        for k, v in topo.get("hidden_path_service", {}).items():
+            conf = os.path.join(base, k, HPS_CONFIG_NAME)
+            entries.append((k, ["bin/hidden_path_srv", "--config", conf]))
+        return entries
+
     def _sciond_entry(self, name, conf_dir):
         return self._common_entry(
             name, ["bin/sciond", "--config", os.path.join(conf_dir, SD_CONFIG_NAME)])
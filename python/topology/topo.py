@@ -56,6 +56,7 @@ DEFAULT_BEACON_SERVERS = 1
 DEFAULT_GRACE_PERIOD = 18000
 DEFAULT_CONTROL_SERVERS = 1
 DEFAULT_COLIBRI_SERVERS = 1
+DEFAULT_HIDDEN_PATH_SERVERS = 1
 
 UNDERLAY_4 = 'UDP/IPv4'
 UNDERLAY_6 = 'UDP/IPv6'
@@ -153,6 +154,8 @@ class TopoGenerator(object):
         srvs = [("control_servers", DEFAULT_CONTROL_SERVERS, "cs")]
         if self.args.colibri:
             srvs.append(("colibri_servers", DEFAULT_COLIBRI_SERVERS, "co"))
+        if self.args.hidden_paths:
+            srvs.append(("hidden_path_servers", DEFAULT_HIDDEN_PATH_SERVERS, "hps"))
         for conf_key, def_num, nick in srvs:
             self._register_srv_entry(topo_id, as_conf, conf_key, def_num, nick)
 
@@ -263,6 +266,9 @@ class TopoGenerator(object):
         srvs = [("control_servers", DEFAULT_CONTROL_SERVERS, "cs", "control_service")]
         if self.args.colibri:
             srvs.append(("colibri_servers", DEFAULT_COLIBRI_SERVERS, "co", "colibri_service"))
+        if self.args.hidden_paths:
+            srvs.append(("hidden_path_servers", DEFAULT_HIDDEN_PATH_SERVERS, "hps",
+                         "hidden_path_service"))
         for conf_key, def_num, nick, topo_key in srvs:
             self._gen_srv_entry(topo_id, as_conf, conf_key, def_num, nick, topo_key)
 
@@ -287,6 +293,8 @@ class TopoGenerator(object):
             return 30252
         if nick == "co":
             return 30257
+        if nick == "hps":
+            return 30254
         print('Invalid nick: %s' % nick)
         sys.exit(1)
 